@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func createAbsenceCommand(t *core.Track) *cobra.Command {
+	var duration time.Duration
+	var note string
+
+	createAbsence := &cobra.Command{
+		Use:   "absence KIND FROM [TO]",
+		Short: "Create a new absence, like vacation",
+		Long: `Create a new absence, like vacation
+
+Absences are from the first to the last day, both inclusive.
+KIND is one of (vacation|sick|holiday).
+
+Absences are not records, and are not counted for any project.
+Full-day absences neither break nor extend streaks.`,
+		Aliases: []string{"a"},
+		Args:    util.WrappedArgs(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind, err := core.ParseAbsenceKind(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to create absence: %s", err)
+			}
+			from, err := util.ParseDate(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to create absence: %s", err)
+			}
+			to := from
+			if len(args) > 2 {
+				to, err = util.ParseDate(args[2])
+				if err != nil {
+					return fmt.Errorf("failed to create absence: %s", err)
+				}
+			}
+			absence, err := core.NewAbsence(kind, from, to, duration, note)
+			if err != nil {
+				return fmt.Errorf("failed to create absence: %s", err)
+			}
+
+			absences, err := t.LoadAbsences()
+			if err != nil {
+				return fmt.Errorf("failed to create absence: %s", err)
+			}
+			if err := absences.Add(absence); err != nil {
+				return fmt.Errorf("failed to create absence: %s", err)
+			}
+			if err := t.SaveAbsences(&absences); err != nil {
+				return fmt.Errorf("failed to create absence: %s", err)
+			}
+
+			out.Success("Created %s absence %s", absence.Kind, formatAbsencePeriod(&absence))
+			return nil
+		},
+	}
+	createAbsence.Flags().DurationVarP(&duration, "duration", "d", 0, "Time absent per day, like 4h. Default: full days")
+	createAbsence.Flags().StringVarP(&note, "note", "n", "", "Note of the absence")
+
+	return createAbsence
+}
+
+func listAbsencesCommand(t *core.Track) *cobra.Command {
+	listAbsences := &cobra.Command{
+		Use:     "absences",
+		Short:   "Lists all absences",
+		Aliases: []string{"a"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			absences, err := t.LoadAbsences()
+			if err != nil {
+				return fmt.Errorf("failed to list absences: %s", err)
+			}
+			f := t.Config.Formatter()
+			for _, abs := range absences.Absences {
+				dur := "full"
+				if !abs.IsFullDay() {
+					dur = f.Duration(abs.Duration)
+				}
+				line := fmt.Sprintf("%-8s %-23s %5s  %s", abs.Kind, formatAbsencePeriod(&abs), dur, abs.Note)
+				out.Print("%s\n", strings.TrimRight(line, " "))
+			}
+			return nil
+		},
+	}
+
+	return listAbsences
+}
+
+func deleteAbsenceCommand(t *core.Track, dryRun *bool) *cobra.Command {
+	deleteAbsence := &cobra.Command{
+		Use:     "absence DATE",
+		Short:   "Delete the absence that includes a day",
+		Aliases: []string{"a"},
+		Args:    util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			date, err := util.ParseDate(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to delete absence: %s", err)
+			}
+			absences, err := t.LoadAbsences()
+			if err != nil {
+				return fmt.Errorf("failed to delete absence: %s", err)
+			}
+			absence, err := absences.Remove(date)
+			if err != nil {
+				return fmt.Errorf("failed to delete absence: %s", err)
+			}
+			if *dryRun {
+				out.Success("Deleted %s absence %s - dry-run", absence.Kind, formatAbsencePeriod(&absence))
+				return nil
+			}
+			if err := t.SaveAbsences(&absences); err != nil {
+				return fmt.Errorf("failed to delete absence: %s", err)
+			}
+			out.Success("Deleted %s absence %s", absence.Kind, formatAbsencePeriod(&absence))
+			return nil
+		},
+	}
+
+	return deleteAbsence
+}
+
+func formatAbsencePeriod(abs *core.Absence) string {
+	if abs.To == "" {
+		return abs.From
+	}
+	return abs.From + " - " + abs.To
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbsence(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"create", "absence", "vacation", "2001-02-03", "2001-02-10"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"create", "absence", "sick", "2001-02-05"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for overlapping absence")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"list", "absences"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"report", "absences", "--start", "2001-01-01"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"delete", "absence", "2001-02-05"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	absences, err := track.LoadAbsences()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(absences.Absences))
+}
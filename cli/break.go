@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func createBreakCommand(t *core.Track) *cobra.Command {
+	createBreak := &cobra.Command{
+		Use:   "break DATE TIME_RANGE [NOTE...]",
+		Short: "Create a new break between records, like lunch",
+		Long: `Create a new break between records, like lunch
+
+Breaks are not records, and are not counted for any project.
+They are shown in the status and in day and week reports, to explain gaps between records.
+Breaks must not overlap records or other breaks.`,
+		Aliases: []string{"b"},
+		Args:    util.WrappedArgs(cobra.MinimumNArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			date, err := util.ParseDate(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to create break: %s", err)
+			}
+			start, end, err := util.ParseTimeRange(args[1], date)
+			if err != nil {
+				return fmt.Errorf("failed to create break: %s", err)
+			}
+			if end.IsZero() {
+				return fmt.Errorf("failed to create break: breaks must have an end")
+			}
+			brk, err := core.NewBreak(start, end, strings.Join(args[2:], " "))
+			if err != nil {
+				return fmt.Errorf("failed to create break: %s", err)
+			}
+
+			records, err := t.RecordsOverlapping(start, end)
+			if err != nil {
+				return fmt.Errorf("failed to create break: %s", err)
+			}
+			if len(records) > 0 {
+				return fmt.Errorf("failed to create break: overlaps record in '%s' at %s", records[0].Project, records[0].Start.Format(util.DateTimeFormat))
+			}
+
+			breaks, err := t.LoadBreaks()
+			if err != nil {
+				return fmt.Errorf("failed to create break: %s", err)
+			}
+			if err := breaks.Add(brk); err != nil {
+				return fmt.Errorf("failed to create break: %s", err)
+			}
+			if err := t.SaveBreaks(&breaks); err != nil {
+				return fmt.Errorf("failed to create break: %s", err)
+			}
+
+			out.Success("Created break %s", formatBreak(&brk, t.Config.Formatter()))
+			return nil
+		},
+	}
+
+	return createBreak
+}
+
+func listBreaksCommand(t *core.Track) *cobra.Command {
+	options := filterOptions{}
+
+	listBreaks := &cobra.Command{
+		Use:   "breaks",
+		Short: "Lists all breaks",
+		Long: `Lists all breaks
+
+With flags --start and --end, only breaks of a date range are listed.`,
+		Aliases: []string{"b"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start, end, err := parseStartEnd(&options)
+			if err != nil {
+				return fmt.Errorf("failed to list breaks: %s", err)
+			}
+			breaks, err := t.LoadBreaks()
+			if err != nil {
+				return fmt.Errorf("failed to list breaks: %s", err)
+			}
+			f := t.Config.Formatter()
+			for _, brk := range breaks.Between(start, end) {
+				out.Print("%s\n", formatBreak(&brk, f))
+			}
+			return nil
+		},
+	}
+	listBreaks.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	listBreaks.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+
+	return listBreaks
+}
+
+func deleteBreakCommand(t *core.Track, dryRun *bool) *cobra.Command {
+	deleteBreak := &cobra.Command{
+		Use:     "break DATE TIME",
+		Short:   "Delete the break that includes a time",
+		Aliases: []string{"b"},
+		Args:    util.WrappedArgs(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tm, err := util.ParseDateTime(strings.Join(args, " "))
+			if err != nil {
+				return fmt.Errorf("failed to delete break: %s", err)
+			}
+			breaks, err := t.LoadBreaks()
+			if err != nil {
+				return fmt.Errorf("failed to delete break: %s", err)
+			}
+			brk, err := breaks.Remove(tm)
+			if err != nil {
+				return fmt.Errorf("failed to delete break: %s", err)
+			}
+			if *dryRun {
+				out.Success("Deleted break %s - dry-run", formatBreak(&brk, t.Config.Formatter()))
+				return nil
+			}
+			if err := t.SaveBreaks(&breaks); err != nil {
+				return fmt.Errorf("failed to delete break: %s", err)
+			}
+			out.Success("Deleted break %s", formatBreak(&brk, t.Config.Formatter()))
+			return nil
+		},
+	}
+
+	return deleteBreak
+}
+
+func formatBreak(brk *core.Break, f util.Formatter) string {
+	line := fmt.Sprintf(
+		"%s %s - %s (%s)  %s",
+		f.Date(brk.Start), f.Time(brk.Start), f.Time(brk.End),
+		f.Duration(brk.Duration(util.NoTime, util.NoTime)), brk.Note,
+	)
+	return strings.TrimRight(line, " ")
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreak(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"create", "project", "test"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"create", "record", "test", "2001-02-03", "09:00-12:00", "note"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"create", "break", "2001-02-03", "12:00-12:45", "lunch"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"create", "break", "2001-02-03", "11:30-12:15"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for break overlapping a record")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"list", "breaks", "--start", "2001-01-01"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"report", "day", "2001-02-03"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"delete", "break", "2001-02-03", "12:30"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	breaks, err := track.LoadBreaks()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(breaks.Breaks))
+}
@@ -2,9 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/daemon"
 	"github.com/mlange-42/track/out"
 	"github.com/mlange-42/track/util"
 	"github.com/spf13/cobra"
@@ -18,19 +21,116 @@ func formatCmdTree(command *cobra.Command) string {
 	return str
 }
 
+// isLoopbackAddr checks if a listen address only accepts connections from the local machine,
+// like "localhost:8422" or "127.0.0.1:8422". Addresses without a host, like ":8422", listen on all interfaces.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// startRecord starts a record, through the daemon if it is running
+func startRecord(t *core.Track, project *core.Project, note string, tags map[string]string, start time.Time) (core.Record, error) {
+	if err := t.CheckWritable(); err != nil {
+		return core.Record{}, err
+	}
+	if client := daemon.Connect(t); client != nil {
+		record, err := client.Start(project.Name, project.Category, note, tags, start)
+		if err != nil {
+			return core.Record{}, err
+		}
+		return *record, nil
+	}
+	return t.StartRecord(project, note, tags, start)
+}
+
+// stopRecord stops the running record, through the daemon if it is running
+func stopRecord(t *core.Track, end time.Time) (*core.Record, error) {
+	if err := t.CheckWritable(); err != nil {
+		return nil, err
+	}
+	if client := daemon.Connect(t); client != nil {
+		return client.Stop(end)
+	}
+	return t.StopRecord(end)
+}
+
+// pauseRecord inserts a pause into the running record, through the daemon if it is running.
+// The pause is open if end is zero
+func pauseRecord(t *core.Track, open *core.Record, note string, start, end time.Time) (*core.Record, error) {
+	if err := t.CheckWritable(); err != nil {
+		return nil, err
+	}
+	if client := daemon.Connect(t); client != nil {
+		return client.Pause(note, start, end)
+	}
+	if _, err := open.InsertPause(start, end, note); err != nil {
+		return nil, err
+	}
+	if err := t.SaveRecord(open, true); err != nil {
+		return nil, err
+	}
+	if end.IsZero() {
+		t.EmitEvent(core.Event{Type: core.EventPause, Time: start, Record: *open})
+	}
+	return open, nil
+}
+
+// switchRecord stops the running record, if any, and starts a new one in a single transaction,
+// through the daemon if it is running
+func switchRecord(t *core.Track, project *core.Project, note string, tags map[string]string, tm time.Time) (*core.Record, core.Record, error) {
+	if err := t.CheckWritable(); err != nil {
+		return nil, core.Record{}, err
+	}
+	if client := daemon.Connect(t); client != nil {
+		stopped, record, err := client.Switch(project.Name, project.Category, note, tags, tm)
+		if err != nil {
+			return nil, core.Record{}, err
+		}
+		return stopped, *record, nil
+	}
+	return t.SwitchRecord(project, note, tags, tm)
+}
+
+// printDryRunRecords lists the records that would be affected by an action in dry-run mode
+func printDryRunRecords(action string, records []core.Record) {
+	for _, r := range records {
+		out.Print(
+			"Would %s record %s (%s) from '%s'\n",
+			action, r.Start.Format(util.DateTimeFormat),
+			util.FormatDuration(r.Duration(util.NoTime, util.NoTime)), r.Project,
+		)
+	}
+}
+
 type filterOptions struct {
 	projects        []string
 	tags            []string
+	excludeProjects []string
+	excludeTags     []string
+	categories      []string
+	emptyNote       bool
+	untagged        bool
 	start           string
 	end             string
 	includeArchived bool
 }
 
-func createFilters(options *filterOptions, projects map[string]core.Project, filterProjects bool) (core.FilterFunctions, error) {
+func createFilters(syntax *core.Syntax, options *filterOptions, projects map[string]core.Project, filterProjects bool) (core.FilterFunctions, error) {
 	filters := []core.FilterFunction{}
 
 	if filterProjects && len(options.projects) > 0 {
-		filters = append(filters, core.FilterByProjects(options.projects))
+		names, err := core.ResolveProjectNames(options.projects, projects)
+		if err != nil {
+			return core.FilterFunctions{}, err
+		}
+		filters = append(filters, core.FilterByProjects(names))
 	}
 
 	if !options.includeArchived {
@@ -42,22 +142,110 @@ func createFilters(options *filterOptions, projects map[string]core.Project, fil
 		i := 0
 		for _, tag := range options.tags {
 			k, v := core.ParseTag(tag)
-			tags[i] = util.NewPair(k, v)
+			tags[i] = util.NewPair(syntax.NormalizeTag(k), v)
 			i++
 		}
 		filters = append(filters, core.FilterByTagsAny(tags))
 	}
 
+	if len(options.excludeProjects) > 0 {
+		names, err := core.ResolveProjectNames(options.excludeProjects, projects)
+		if err != nil {
+			return core.FilterFunctions{}, err
+		}
+		filters = append(filters, core.FilterExcludeProjects(withDescendants(names, projects)))
+	}
+
+	if len(options.excludeTags) > 0 {
+		tags := make([]util.Pair[string, string], len(options.excludeTags))
+		for i, tag := range options.excludeTags {
+			k, v := core.ParseTag(tag)
+			tags[i] = util.NewPair(syntax.NormalizeTag(k), v)
+		}
+		filters = append(filters, core.FilterExcludeTags(tags))
+	}
+
+	if len(options.categories) > 0 {
+		filters = append(filters, core.FilterByCategories(options.categories))
+	}
+
+	if options.emptyNote {
+		filters = append(filters, core.FilterByEmptyNote())
+	}
+	if options.untagged {
+		filters = append(filters, core.FilterUntagged())
+	}
+
 	startTime, endTime, err := parseStartEnd(options)
 	if err != nil {
 		return core.FilterFunctions{}, err
 	}
 
 	var ff = core.NewFilter(filters, startTime, endTime)
+	// Archived projects are already handled by the filter functions, also for async loading
+	ff.IncludeArchived = true
+	// Notes are not in the report cache, so it can't be used for these filters
+	ff.NeedsRecords = options.emptyNote || options.untagged
 
 	return ff, nil
 }
 
+// withDescendants returns the given projects and all their descendants
+func withDescendants(names []string, projects map[string]core.Project) []string {
+	roots := make(map[string]bool, len(names))
+	for _, name := range names {
+		roots[name] = true
+	}
+	result := []string{}
+	for name := range projects {
+		for _, ancestor := range strings.Split(core.ProjectPathName(name, projects), "/") {
+			if roots[ancestor] {
+				result = append(result, name)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// newAggregateReporter creates a Reporter that does not retain records, for reports that only require aggregated times.
+// Uses the report cache if enabled in the config.
+func newAggregateReporter(
+	t *core.Track, proj []string,
+	filters core.FilterFunctions, includeArchived bool,
+	start, end time.Time,
+) (*core.Reporter, error) {
+	if t.Config.ReportCache {
+		return core.NewCachedReporter(t, proj, filters, includeArchived, start, end)
+	}
+	return core.NewStreamingReporter(t, proj, filters, includeArchived, start, end)
+}
+
+// warnRecordErrors prints a warning for each record file that was skipped by a Reporter.
+// Writes to stderr only, as some reports write binary or SVG data to stdout.
+func warnRecordErrors(errs []error) {
+	for _, err := range errs {
+		fmt.Fprintf(out.StdErr, "Warning: skipped %s\n", err)
+	}
+}
+
+// completeTags provides shell completion for tag flags, based on all tags in use
+func completeTags(t *core.Track) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		stats, err := t.TagStatistics(core.FilterFunctions{})
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		tags := make([]string, 0, len(stats))
+		for _, st := range stats {
+			if strings.HasPrefix(st.Name, toComplete) {
+				tags = append(tags, st.Name)
+			}
+		}
+		return tags, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
 func parseStartEnd(options *filterOptions) (time.Time, time.Time, error) {
 	var err error
 	var startTime time.Time
@@ -34,3 +34,60 @@ func setupTestCommand() (*core.Track, error) {
 
 	return &track, nil
 }
+
+func TestWithDescendants(t *testing.T) {
+	projects := map[string]core.Project{
+		"internal": {Name: "internal"},
+		"meetings": {Name: "meetings", Parent: "internal"},
+		"standup":  {Name: "standup", Parent: "meetings"},
+		"client":   {Name: "client"},
+	}
+	assert.ElementsMatch(t, []string{"internal", "meetings", "standup"}, withDescendants([]string{"internal"}, projects))
+	assert.ElementsMatch(t, []string{"meetings", "standup", "client"}, withDescendants([]string{"meetings", "client"}, projects))
+}
+
+func TestCreateFiltersExclude(t *testing.T) {
+	projects := map[string]core.Project{
+		"internal": {Name: "internal"},
+		"meetings": {Name: "meetings", Parent: "internal"},
+		"client":   {Name: "client"},
+	}
+	options := filterOptions{
+		excludeProjects: []string{"internal"},
+		excludeTags:     []string{"private"},
+		includeArchived: true,
+	}
+	filters, err := createFilters(core.DefaultSyntax(), &options, projects, true)
+	assert.Nil(t, err)
+
+	assert.False(t, core.Filter(&core.Record{Project: "meetings"}, filters))
+	assert.False(t, core.Filter(&core.Record{Project: "client", Tags: map[string]string{"private": ""}}, filters))
+	assert.True(t, core.Filter(&core.Record{Project: "client", Tags: map[string]string{"public": ""}}, filters))
+
+	options.excludeProjects = []string{"unknown"}
+	_, err = createFilters(core.DefaultSyntax(), &options, projects, true)
+	assert.NotNil(t, err)
+}
+
+func TestCreateFiltersNeedsRecords(t *testing.T) {
+	options := filterOptions{includeArchived: true}
+	filters, err := createFilters(core.DefaultSyntax(), &options, map[string]core.Project{}, true)
+	assert.Nil(t, err)
+	assert.False(t, filters.NeedsRecords, "Report cache should be usable")
+
+	for _, options := range []filterOptions{{emptyNote: true}, {untagged: true}} {
+		filters, err := createFilters(core.DefaultSyntax(), &options, map[string]core.Project{}, true)
+		assert.Nil(t, err)
+		assert.True(t, filters.NeedsRecords, "Report cache should not be used with %+v", options)
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	assert.True(t, isLoopbackAddr("127.0.0.1:8422"))
+	assert.True(t, isLoopbackAddr("localhost:8422"))
+	assert.True(t, isLoopbackAddr("[::1]:8422"))
+	assert.False(t, isLoopbackAddr(":8422"))
+	assert.False(t, isLoopbackAddr("0.0.0.0:8422"))
+	assert.False(t, isLoopbackAddr("192.168.1.2:8422"))
+	assert.False(t, isLoopbackAddr("invalid"))
+}
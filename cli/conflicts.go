@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func conflictsCommand(t *core.Track) *cobra.Command {
+	var resolve bool
+	var prefer string
+
+	conflicts := &cobra.Command{
+		Use:   "conflicts",
+		Short: "List and resolve conflicting copies of records created by file sync tools",
+		Long: `List and resolve conflicting copies of records created by file sync tools
+
+Detects conflicting copies of record files created by tools like Dropbox, Nextcloud or Syncthing.
+
+With flag --resolve, both versions of each record are merged:
+the later end time is used, and pauses, tags and links are united.
+Project and note are taken from the version that changed them, if known from the record history.
+Otherwise, you are prompted to select a version, unless flag --prefer is given.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if prefer != "" && prefer != "ours" && prefer != "theirs" {
+				return fmt.Errorf("failed to resolve conflicts: --prefer must be one of 'ours', 'theirs'. Got '%s'", prefer)
+			}
+			conflicts, err := t.FindConflicts()
+			if err != nil {
+				return fmt.Errorf("failed to find conflicts: %s", err)
+			}
+			if len(conflicts) == 0 {
+				out.Success("No conflicts found")
+				return nil
+			}
+
+			if !resolve {
+				for _, c := range conflicts {
+					out.Print("%s\n", c.Path)
+				}
+				return fmt.Errorf("found %d conflicting copies of records", len(conflicts))
+			}
+
+			choose := promptMergeChoice
+			if prefer != "" {
+				choose = func(field, ours, theirs string) (string, error) {
+					if prefer == "theirs" {
+						return theirs, nil
+					}
+					return ours, nil
+				}
+			}
+
+			for _, c := range conflicts {
+				ours, theirs, base, err := t.LoadConflict(c)
+				if err != nil {
+					return fmt.Errorf("failed to resolve conflict %s: %s", c.String(), err)
+				}
+				merged, err := core.MergeRecords(t.Syntax(), base, ours, theirs, choose)
+				if err != nil {
+					return fmt.Errorf("failed to resolve conflict %s: %s", c.String(), err)
+				}
+				if err := t.ResolveConflict(c, &merged); err != nil {
+					return fmt.Errorf("failed to resolve conflict %s: %s", c.String(), err)
+				}
+			}
+			out.Success("Resolved %d conflicting copies of records", len(conflicts))
+			return nil
+		},
+	}
+
+	conflicts.Flags().BoolVar(&resolve, "resolve", false, "Merge conflicting copies into the original records")
+	conflicts.Flags().StringVar(&prefer, "prefer", "", "Version to use for fields changed in both versions, instead of prompting: 'ours' or 'theirs'")
+
+	return conflicts
+}
+
+// promptMergeChoice asks the user to select one of two conflicting values
+func promptMergeChoice(field, ours, theirs string) (string, error) {
+	out.Print("Conflicting %s\n  (o)urs:   %s\n  (t)heirs: %s\n", field, ours, theirs)
+	for {
+		answer, err := out.Scan("Select version (o/t): ")
+		if err != nil {
+			return "", ErrUserAbort
+		}
+		switch answer {
+		case "o":
+			return ours, nil
+		case "t":
+			return theirs, nil
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConflicts(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	record := core.Record{
+		Project: "test",
+		Note:    "theirs",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	err = track.SaveRecord(&record, false)
+	if err != nil {
+		t.Fatal("error saving record")
+	}
+	original := track.RecordPath(record.Start)
+	err = os.Rename(original, filepath.Join(filepath.Dir(original), "04-05 (conflicted copy 2001-02-03).trk"))
+	if err != nil {
+		t.Fatal("error renaming record")
+	}
+	record.Note = "ours"
+	err = track.SaveRecord(&record, false)
+	if err != nil {
+		t.Fatal("error saving record")
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"conflicts"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for conflicts")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"conflicts", "--resolve", "--prefer", "theirs"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	loaded, err := track.LoadRecord(record.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "theirs", loaded.Note)
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"conflicts"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/mlange-42/track/core"
@@ -25,6 +26,8 @@ func createCommand(t *core.Track) *cobra.Command {
 	create.AddCommand(createWorkspaceCommand(t))
 	create.AddCommand(createProjectCommand(t))
 	create.AddCommand(createRecordCommand(t))
+	create.AddCommand(createAbsenceCommand(t))
+	create.AddCommand(createBreakCommand(t))
 	create.Long += "\n\n" + formatCmdTree(create)
 	return create
 }
@@ -35,6 +38,8 @@ func createProjectCommand(t *core.Track) *cobra.Command {
 	var color uint8
 	var fgColor uint8
 	var symbol string
+	var estimate time.Duration
+	var category string
 
 	createProject := &cobra.Command{
 		Use:     "project PROJECT",
@@ -52,7 +57,15 @@ func createProjectCommand(t *core.Track) *cobra.Command {
 			}
 
 			requiredTags = util.Unique(requiredTags)
+			if estimate < 0 {
+				return fmt.Errorf("failed to create project: --estimate must not be negative")
+			}
+			if err := t.Config.CheckCategory(category); err != nil {
+				return fmt.Errorf("failed to create project: %s", err)
+			}
 			project := core.NewProject(name, parent, symbol, requiredTags, fgColor, color)
+			project.Estimate = estimate
+			project.Category = category
 
 			if err := t.CheckParents(project); err != nil {
 				return fmt.Errorf("failed to create project: %s", err)
@@ -72,6 +85,8 @@ func createProjectCommand(t *core.Track) *cobra.Command {
 	createProject.Flags().Uint8VarP(&color, "color", "c", 0, "Background color for the project, as color index 0..256.\nSee: $ track list colors")
 	createProject.Flags().Uint8VarP(&fgColor, "fg-color", "f", 15, "Foreground color for the project, as color index 0..256.\nSee: $ track list colors")
 	createProject.Flags().StringVarP(&symbol, "symbol", "s", "", "Symbol for the project. Defaults to the first letter of the name")
+	createProject.Flags().DurationVar(&estimate, "estimate", 0, "Estimated total effort of the project, including sub-projects, like 40h")
+	createProject.Flags().StringVar(&category, "category", "", "Default category of records in the project, like meeting")
 
 	return createProject
 }
@@ -99,22 +114,19 @@ func createWorkspaceCommand(t *core.Track) *cobra.Command {
 }
 
 func createRecordCommand(t *core.Track) *cobra.Command {
+	var category string
+
 	createRecord := &cobra.Command{
 		Use:     "record PROJECT DATE TIME_RANGE [NOTE...]",
 		Short:   "Create a new record for a project",
 		Aliases: []string{"p"},
 		Args:    util.WrappedArgs(cobra.MinimumNArgs(3)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			project := args[0]
-
-			if !t.ProjectExists(project) {
-				return fmt.Errorf("failed to create record: project '%s' does not exist", project)
-			}
-
-			proj, err := t.LoadProject(project)
+			proj, err := t.ResolveProject(args[0])
 			if err != nil {
 				return fmt.Errorf("failed to create record: %w", err)
 			}
+			project := proj.Name
 
 			if proj.Archived {
 				return fmt.Errorf("failed to create record: project '%s' is archived", proj.Name)
@@ -130,25 +142,28 @@ func createRecordCommand(t *core.Track) *cobra.Command {
 				return fmt.Errorf("failed to create record: %w", err)
 			}
 
-			if start.After(end) {
+			if !end.After(start) {
 				return fmt.Errorf("failed to create record: start must be before end")
 			}
 
-			records, err := t.LoadAllRecordsFiltered(core.NewFilter([]core.FilterFunction{}, start, end))
+			records, err := t.RecordsOverlapping(start, end)
 			if err != nil {
 				return fmt.Errorf("failed to create record: %w", err)
 			}
 
 			if len(records) > 0 {
-				return fmt.Errorf("failed to create record: time range overlaps with existing record(s)")
+				return fmt.Errorf("failed to create record: %w with existing record(s)", core.ErrOverlap)
 			}
 
 			note := strings.Join(args[3:], " ")
-			tags, err := core.ExtractTagsSlice(args[3:])
+			tags, err := t.Syntax().ExtractTagsSlice(args[3:])
 			if err != nil {
 				return fmt.Errorf("failed to create record: %w", err)
 			}
 
+			if category != "" {
+				proj.Category = category
+			}
 			record, err := t.NewRecord(&proj, note, tags, start, end)
 			if err != nil {
 				return fmt.Errorf("failed to create record: %w", err)
@@ -162,5 +177,7 @@ func createRecordCommand(t *core.Track) *cobra.Command {
 		},
 	}
 
+	createRecord.Flags().StringVar(&category, "category", "", "Category of the record, like meeting. Defaults to the project's category")
+
 	return createRecord
 }
@@ -126,7 +126,7 @@ func TestCreateRecord(t *testing.T) {
 	assert.Equal(t, records[0].Note, "note", "Note should be 'note'")
 
 	cmd.SetArgs([]string{"create", "record", "test", "today", "10:00-1h", "note"})
-	assert.NotNil(t, cmd.Execute(), "should fail with record time overlap error")
+	assert.ErrorIs(t, cmd.Execute(), core.ErrOverlap, "should fail with record time overlap error")
 
 	cmd.SetArgs([]string{"create", "record", "test", "2015-10-21", "16:29-19:28", "back to the future"})
 	if err := cmd.Execute(); err != nil {
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/daemon"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func daemonCommand(t *core.Track) *cobra.Command {
+	var interval time.Duration
+	var once bool
+	var desktop bool
+	var useDBus bool
+
+	daemonCom := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run a background process that watches the tracking state",
+		Long: `Run a background process that watches the tracking state
+
+The daemon periodically checks for records that run longer than 'maxRecordDuration' from the config,
+which typically means that you forgot to stop tracking.
+If 'autoStop' is enabled in the config, such records are stopped at the cut-off time,
+and tagged with "` + t.Config.TagPrefix + core.AutoStoppedTag + `".
+Records with an open pause longer than 'maxPauseDuration' from the config are stopped at the start of the pause.
+If 'stopReminder' is set in the config, the daemon reminds about records still running past that time of day.
+With 'startReminder', it reminds to start tracking if no record was started by that time on a working day.
+If retention rules are set by config entry 'retention', they are applied once per day.
+
+While the daemon is running, commands start, stop and switch are dispatched to it through a Unix domain socket
+in the track directory, or a named pipe on Windows. This way, the daemon centralizes file access, and runs event hooks of integrations.
+
+With flag --dbus, the daemon additionally exposes start, stop, pause, resume and status
+on the D-Bus session bus as service ` + daemon.DBusName + `, and emits signal ` + daemon.DBusSignal + ` on state changes.
+
+The daemon runs until it is interrupted. Use flag --once to run the checks only once, e.g. from a cron job.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return fmt.Errorf("failed to run daemon: argument --interval must be > 0")
+			}
+
+			notify := func(title, message string) error {
+				out.Warn("%s %s\n", time.Now().Format(util.TimeFormat), message)
+				if desktop {
+					return util.Notify(title, message)
+				}
+				return nil
+			}
+			d := daemon.New(t, interval, notify)
+
+			if once {
+				if err := d.RunOnce(time.Now()); err != nil {
+					return fmt.Errorf("failed to run daemon: %s", err)
+				}
+				return nil
+			}
+
+			listener, err := daemon.Listen(t)
+			if err != nil {
+				return fmt.Errorf("failed to run daemon: %s", err)
+			}
+			go func() {
+				if err := d.Serve(listener); err != nil {
+					out.Err("failed to serve daemon socket: %s\n", err)
+				}
+			}()
+
+			if useDBus {
+				conn, err := dbus.ConnectSessionBus()
+				if err != nil {
+					listener.Close()
+					return fmt.Errorf("failed to connect to D-Bus: %s", err)
+				}
+				defer conn.Close()
+				if err := d.ServeDBus(conn); err != nil {
+					listener.Close()
+					return fmt.Errorf("failed to serve D-Bus: %s", err)
+				}
+			}
+
+			stop := make(chan struct{})
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt)
+			go func() {
+				<-signals
+				close(stop)
+			}()
+
+			out.Success("Started daemon, checking every %s, listening on %s\n", interval, listener.Addr())
+			d.Run(stop)
+			return listener.Close()
+		},
+	}
+
+	daemonCom.Flags().DurationVarP(&interval, "interval", "i", time.Minute, "Interval between checks")
+	daemonCom.Flags().BoolVar(&once, "once", false, "Run the checks only once")
+	daemonCom.Flags().BoolVar(&useDBus, "dbus", false, "Expose the control API on the D-Bus session bus (Linux)")
+	daemonCom.Flags().BoolVarP(&desktop, "desktop", "d", false, "Show desktop notifications in addition to terminal output")
+
+	return daemonCom
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/daemon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaemonOnce(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	track.Config.MaxRecordDuration = time.Hour
+	track.Config.AutoStop = true
+
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	err = track.SaveProject(project, false)
+	if err != nil {
+		t.Fatal("error saving project")
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"start", "test", "Note", "--ago", "2h"})
+	err = cmd.Execute()
+	if err != nil {
+		t.Fatal("error executing command")
+	}
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"daemon", "--once"})
+	err = cmd.Execute()
+	if err != nil {
+		t.Fatal("error executing command")
+	}
+
+	open, err := track.OpenRecord()
+	if err != nil {
+		t.Fatal("error loading open record")
+	}
+	assert.Nil(t, open, "Record should be stopped")
+}
+
+func TestDaemonDispatch(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.RemoveAll(track.RootDir)
+
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	err = track.SaveProject(project, false)
+	if err != nil {
+		t.Fatal("error saving project")
+	}
+
+	daemonTrack, err := core.NewTrack(&track.RootDir)
+	assert.Nil(t, err)
+	events := []core.EventType{}
+	daemonTrack.SetEventHook("test", func(e core.Event) { events = append(events, e.Type) })
+
+	d := daemon.New(&daemonTrack, time.Minute, func(title, message string) error { return nil })
+	listener, err := daemon.Listen(&daemonTrack)
+	assert.Nil(t, err)
+	defer listener.Close()
+	go func() { _ = d.Serve(listener) }()
+
+	for _, args := range [][]string{
+		{"start", "test", "Note", "--ago", "2h"},
+		{"switch", "test", "--force", "--ago", "1h"},
+		{"stop"},
+	} {
+		cmd := RootCommand(track, "")
+		cmd.SetArgs(args)
+		assert.Nil(t, cmd.Execute())
+	}
+
+	assert.Equal(t, []core.EventType{core.EventStart, core.EventStop, core.EventStart, core.EventStop}, events)
+}
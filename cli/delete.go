@@ -26,6 +26,8 @@ func deleteCommand(t *core.Track) *cobra.Command {
 
 	delete.AddCommand(deleteRecordCommand(t, &dryRun))
 	delete.AddCommand(deleteProjectCommand(t, &dryRun))
+	delete.AddCommand(deleteAbsenceCommand(t, &dryRun))
+	delete.AddCommand(deleteBreakCommand(t, &dryRun))
 
 	delete.Long += "\n\n" + formatCmdTree(delete)
 	return delete
@@ -41,6 +43,11 @@ func deleteRecordCommand(t *core.Track, dryRun *bool) *cobra.Command {
 		Aliases: []string{"r"},
 		Args:    util.WrappedArgs(cobra.ExactArgs(2)),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if !*dryRun {
+				if err := t.CheckWritable(); err != nil {
+					return fmt.Errorf("failed to delete record: %s", err)
+				}
+			}
 			timeString := strings.Join(args, " ")
 			tm, err := util.ParseDateTime(timeString)
 			if err != nil {
@@ -91,6 +98,11 @@ func deleteProjectCommand(t *core.Track, dryRun *bool) *cobra.Command {
 		Aliases: []string{"p"},
 		Args:    util.WrappedArgs(cobra.ExactArgs(1)),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if !*dryRun {
+				if err := t.CheckWritable(); err != nil {
+					return fmt.Errorf("failed to delete project: %s", err)
+				}
+			}
 			name := args[0]
 
 			projects, err := t.LoadAllProjects()
@@ -120,6 +132,20 @@ func deleteProjectCommand(t *core.Track, dryRun *bool) *cobra.Command {
 				return fmt.Errorf("failed to delete project: aborted by user")
 			}
 
+			if *dryRun {
+				filters := core.NewFilter(
+					[]core.FilterFunction{
+						core.FilterByProjects([]string{pNode.Value.Name}),
+					}, util.NoTime, util.NoTime,
+				)
+				filters.IncludeArchived = true
+				records, err := t.LoadAllRecordsFiltered(filters)
+				if err != nil {
+					return fmt.Errorf("failed to delete project: %s", err)
+				}
+				printDryRunRecords("delete", records)
+			}
+
 			cnt, err := t.DeleteProject(&pNode.Value, true, *dryRun)
 			if err != nil {
 				return fmt.Errorf("failed to delete project: %s (deleted %d records)", err, cnt)
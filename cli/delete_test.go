@@ -75,3 +75,41 @@ func TestDelete(t *testing.T) {
 
 	assert.False(t, track.ProjectExists("test"), "project should not exist")
 }
+
+func TestDeleteReadOnly(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	err = track.SaveProject(project, false)
+	if err != nil {
+		t.Fatal("error saving project")
+	}
+
+	record := core.Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	err = track.SaveRecord(&record, false)
+	if err != nil {
+		t.Fatal("error saving record")
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"--read-only", "delete", "record", "--force", "2001-02-03", "04:05"})
+	err = cmd.Execute()
+	assert.ErrorContains(t, err, core.ErrReadOnly.Error())
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"--read-only", "delete", "--dry", "project", "--force", "test"})
+	err = cmd.Execute()
+	assert.Nil(t, err)
+
+	_, err = track.LoadRecord(record.Start)
+	assert.Nil(t, err, "record should still exist")
+	assert.True(t, track.ProjectExists("test"), "project should still exist")
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func doctorCommand(t *core.Track) *cobra.Command {
+	var dryRun bool
+
+	doctor := &cobra.Command{
+		Use:   "doctor",
+		Short: "Repair common inconsistencies of records in the current workspace",
+		Long: `Repair common inconsistencies of records in the current workspace
+
+Fixes pauses that are out of order, overlapping, or outside of their record,
+e.g. after editing record files by hand:
+  * pauses that end before their start get start and end swapped,
+  * pauses are sorted by their start,
+  * pauses are clamped to the time span of their record,
+  * overlapping pauses are merged, and their notes are joined,
+  * pauses that are empty after clamping are removed.
+
+Lists all changes. All records are repaired all-or-nothing.
+Locked records are left unchanged.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := t.RepairRecords(dryRun)
+			if err != nil {
+				return fmt.Errorf("failed to repair records: %s", err)
+			}
+			for _, repair := range result.Repairs {
+				out.Print("%s %s\n", repair.Record.Start.Format(util.DateTimeFormat), repair.Record.Project)
+				for _, change := range repair.Changes {
+					out.Print("  - %s\n", change)
+				}
+			}
+			if result.Locked > 0 {
+				out.Warn("Left %d locked record(s) unchanged\n", result.Locked)
+			}
+			dry := ""
+			if dryRun {
+				dry = " - dry-run"
+			}
+			out.Success("Checked %d record(s), repaired %d%s", result.Checked, len(result.Repairs), dry)
+			return nil
+		},
+	}
+	doctor.Flags().BoolVar(&dryRun, "dry", false, "Dry run: do not actually change any files")
+
+	return doctor
+}
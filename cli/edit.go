@@ -10,6 +10,7 @@ import (
 
 	"github.com/mlange-42/track/core"
 	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/tui"
 	"github.com/mlange-42/track/util"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -49,6 +50,8 @@ See file .track/config.yml to configure the editor to be used.`,
 	edit.AddCommand(editRecordCommand(t, &dryRun))
 	edit.AddCommand(editDayCommand(t, &dryRun))
 	edit.AddCommand(editConfigCommand(t, &dryRun))
+	edit.AddCommand(editTagCommand(t, &dryRun))
+	edit.AddCommand(editInteractiveCommand(t, &dryRun))
 
 	edit.Long += "\n\n" + formatCmdTree(edit)
 	return edit
@@ -70,6 +73,11 @@ Uses the current date if only a time is given.`,
 		Aliases: []string{"r"},
 		Args:    util.WrappedArgs(cobra.MaximumNArgs(2)),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if !*dryRun {
+				if err := t.CheckWritable(); err != nil {
+					return fmt.Errorf("failed to edit record: %s", err)
+				}
+			}
 			var err error
 			tm := util.NoTime
 			switch len(args) {
@@ -119,6 +127,9 @@ Uses the current date if only a time is given.`,
 func editProjectCommand(t *core.Track, dryRun *bool) *cobra.Command {
 	var archive bool
 	var rename string
+	var rate float64
+	var rateFrom string
+	var currency string
 
 	editProject := &cobra.Command{
 		Use:   "project PROJECT",
@@ -130,6 +141,11 @@ See file .track/config.yml to configure the editor to be used.`,
 		Aliases: []string{"p"},
 		Args:    util.WrappedArgs(cobra.ExactArgs(1)),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if !*dryRun {
+				if err := t.CheckWritable(); err != nil {
+					return fmt.Errorf("failed to edit project: %s", err)
+				}
+			}
 			name := args[0]
 			project, err := t.LoadProject(name)
 			if err != nil {
@@ -164,6 +180,25 @@ See file .track/config.yml to configure the editor to be used.`,
 				changed = true
 			}
 
+			if cmd.Flags().Changed("rate") {
+				if rate < 0 {
+					return fmt.Errorf("failed to edit project: --rate must not be negative")
+				}
+				from := util.ToDate(time.Now())
+				if rateFrom != "" {
+					from, err = util.ParseDate(rateFrom)
+					if err != nil {
+						return fmt.Errorf("failed to edit project: %s", err)
+					}
+				}
+				newRate := core.NewRate(from, rate, currency)
+				project.SetRate(newRate)
+				out.Success("Set rate of project '%s' to %.2f %s from %s\n", project.Name, rate, newRate.Currency, newRate.From)
+				changed = true
+			} else if cmd.Flags().Changed("rate-from") || cmd.Flags().Changed("currency") {
+				return fmt.Errorf("failed to edit project: flags --rate-from and --currency require --rate")
+			}
+
 			if changed {
 				if !*dryRun {
 					if err := t.SaveProject(project, true); err != nil {
@@ -189,6 +224,9 @@ See file .track/config.yml to configure the editor to be used.`,
 	}
 	editProject.Flags().BoolVarP(&archive, "archive", "a", false, "Archive or un-archive a project. Use like '-a=false'")
 	editProject.Flags().StringVarP(&rename, "rename", "n", "", "Rename a project. Also changes the project name in all associated records")
+	editProject.Flags().Float64Var(&rate, "rate", 0, "Set an hourly rate, valid from the date given by --rate-from")
+	editProject.Flags().StringVar(&rateFrom, "rate-from", "", "First day the rate given by --rate is valid. Default today")
+	editProject.Flags().StringVar(&currency, "currency", "", "Currency of the rate given by --rate, like EUR. Default the project's currency")
 
 	return editProject
 }
@@ -205,6 +243,11 @@ See file .track/config.yml to configure the editor to be used.`,
 		Aliases: []string{"c"},
 		Args:    util.WrappedArgs(cobra.NoArgs),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if !*dryRun {
+				if err := t.CheckWritable(); err != nil {
+					return fmt.Errorf("failed to edit config: %s", err)
+				}
+			}
 			err := editConfig(t, *dryRun)
 			if err != nil {
 				if err == ErrUserAbort {
@@ -225,6 +268,86 @@ See file .track/config.yml to configure the editor to be used.`,
 	return editConfig
 }
 
+func editTagCommand(t *core.Track, dryRun *bool) *cobra.Command {
+
+	editTag := &cobra.Command{
+		Use:   "tag TAG NEW_NAME",
+		Short: "Rename a tag in all records",
+		Long: `Rename a tag in all records
+
+Renames the tag in the notes of all records. Tag values are preserved.`,
+		Aliases: []string{"t"},
+		Args:    util.WrappedArgs(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !*dryRun {
+				if err := t.CheckWritable(); err != nil {
+					return fmt.Errorf("failed to rename tag: %s", err)
+				}
+			}
+			oldName, newName := args[0], args[1]
+
+			if *dryRun {
+				filters := core.NewFilter(
+					[]core.FilterFunction{
+						core.FilterByTagsAny([]util.Pair[string, string]{util.NewPair(oldName, "")}),
+					}, util.NoTime, util.NoTime,
+				)
+				filters.IncludeArchived = true
+				records, err := t.LoadAllRecordsFiltered(filters)
+				if err != nil {
+					return fmt.Errorf("failed to rename tag: %s", err)
+				}
+				printDryRunRecords("change", records)
+				out.Success("Renamed tag '%s' to '%s' (%d records) - dry-run", oldName, newName, len(records))
+				return nil
+			}
+
+			count, err := t.RenameTag(oldName, newName)
+			if err != nil {
+				return fmt.Errorf("failed to rename tag: %s (changed %d records)", err, count)
+			}
+			out.Success("Renamed tag '%s' to '%s' (%d records)", oldName, newName, count)
+			return nil
+		},
+	}
+
+	return editTag
+}
+
+func editInteractiveCommand(t *core.Track, dryRun *bool) *cobra.Command {
+	interactive := &cobra.Command{
+		Use:   "interactive [DATE]",
+		Short: "Browse and edit records in an interactive terminal editor",
+		Long: `Browse and edit records in an interactive terminal editor
+
+Shows the records of a day or week. Select a record to edit its time, project, note and pauses.
+Changes are validated while editing, and saved only if they are valid.`,
+		Aliases: []string{"i"},
+		Args:    util.WrappedArgs(cobra.MaximumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			date := util.ToDate(time.Now())
+			if len(args) > 0 {
+				date, err = util.ParseDate(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to edit records: %s", err)
+				}
+			}
+			editor, err := tui.NewEditor(t, date)
+			if err != nil {
+				return fmt.Errorf("failed to edit records: %s", err)
+			}
+			editor.DryRun = *dryRun
+			if err := tui.Run(editor); err != nil {
+				return fmt.Errorf("failed to edit records: %s", err)
+			}
+			return nil
+		},
+	}
+
+	return interactive
+}
+
 func editDayCommand(t *core.Track, dryRun *bool) *cobra.Command {
 
 	editDay := &cobra.Command{
@@ -237,6 +360,11 @@ See file .track/config.yml to configure the editor to be used.`,
 		Aliases: []string{"d"},
 		Args:    util.WrappedArgs(cobra.MaximumNArgs(2)),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if !*dryRun {
+				if err := t.CheckWritable(); err != nil {
+					return fmt.Errorf("failed to edit day: %s", err)
+				}
+			}
 			var err error
 			date := util.ToDate(time.Now())
 			if len(args) > 0 {
@@ -270,14 +398,14 @@ func editRecord(t *core.Track, tm time.Time, dryRun bool) error {
 	}
 
 	return edit(t, &record,
-		fmt.Sprintf("%s Record %s\n\n", core.CommentPrefix, record.Start.Format(util.DateTimeFormat)),
-		core.CommentPrefix,
+		fmt.Sprintf("%s Record %s\n\n", t.Config.CommentPrefix, record.Start.Format(util.DateTimeFormat)),
+		t.Config.CommentPrefix,
 		func(r *core.Record) ([]byte, error) {
-			str := core.SerializeRecord(r, util.NoTime)
+			str := t.Syntax().SerializeRecord(r, util.NoTime)
 			return []byte(str), nil
 		},
 		func(b []byte) error {
-			newRecord, err := core.DeserializeRecord(string(b), record.Start)
+			newRecord, err := t.Syntax().DeserializeRecord(string(b), record.Start)
 			if err != nil {
 				return err
 			}
@@ -317,8 +445,7 @@ func editRecord(t *core.Track, tm time.Time, dryRun bool) error {
 
 func editDay(t *core.Track, date time.Time, dryRun bool) error {
 	date = util.ToDate(date)
-	dateBefore := date.Add(-24 * time.Hour)
-	dateAfter := date.Add(24 * time.Hour)
+	dateAfter := date.AddDate(0, 0, 1)
 
 	records, err := t.LoadDateRecordsExact(date)
 	if err != nil {
@@ -327,6 +454,11 @@ func editDay(t *core.Track, date time.Time, dryRun bool) error {
 		}
 		return err
 	}
+	for _, rec := range records {
+		if rec.Locked {
+			return fmt.Errorf("record %s is locked. Unlock it with 'track unlock'", rec.Start.Format(util.DateTimeFormat))
+		}
+	}
 
 	projects, err := t.LoadAllProjects()
 	if err != nil {
@@ -334,12 +466,12 @@ func editDay(t *core.Track, date time.Time, dryRun bool) error {
 	}
 
 	return edit(t, records,
-		fmt.Sprintf("%[1]s Records for %s\n%[1]s Clear file to abort\n\n", core.CommentPrefix, date.Format(util.DateFormat)),
-		core.CommentPrefix,
+		fmt.Sprintf("%[1]s Records for %s\n%[1]s Clear file to abort\n\n", t.Config.CommentPrefix, date.Format(util.DateFormat)),
+		t.Config.CommentPrefix,
 		func(records []core.Record) ([]byte, error) {
 			str := ""
 			for i, rec := range records {
-				str += core.SerializeRecord(&rec, date)
+				str += t.Syntax().SerializeRecord(&rec, date)
 				if i < len(records)-1 {
 					str += "\n--------------------\n\n"
 				}
@@ -353,13 +485,13 @@ func editDay(t *core.Track, date time.Time, dryRun bool) error {
 			newRecords := []core.Record{}
 
 			for i, line := range lines {
-				if strings.HasPrefix(line, "----") || i == len(lines)-1 {
+				if strings.HasPrefix(line, core.RecordSeparator) || i == len(lines)-1 {
 					endIdx := i
 					if i == len(lines)-1 {
 						endIdx = len(lines)
 					}
 					str := strings.Join(lines[prevIdx:endIdx], "\n")
-					rec, err := core.DeserializeRecord(str, date)
+					rec, err := t.Syntax().DeserializeRecord(str, date)
 					if err != nil {
 						return err
 					}
@@ -376,18 +508,18 @@ func editDay(t *core.Track, date time.Time, dryRun bool) error {
 				if oldFirst.Start.Before(date) {
 					if newFirst.Start.Before(oldFirst.Start) {
 						return fmt.Errorf(
-							"can't extend a start time on the day before (%s / %s). Try 'track edit day %s'",
+							"can't extend a start time on a previous day (%s / %s). Try 'track edit day %s'",
 							newFirst.Start.Format(util.TimeFormat),
 							oldFirst.Start.Format(util.TimeFormat),
-							dateBefore.Format(util.DateFormat),
+							util.ToDate(oldFirst.Start).Format(util.DateFormat),
 						)
 					}
 				} else {
 					if newFirst.Start.Before(date) {
 						return fmt.Errorf(
-							"can't move a start time to the day before (%s). Try 'track edit day %s'",
+							"can't move a start time to a previous day (%s). Try 'track edit day %s'",
 							newFirst.Start.Format(util.TimeFormat),
-							dateBefore.Format(util.DateFormat),
+							util.ToDate(newFirst.Start).Format(util.DateFormat),
 						)
 					}
 				}
@@ -397,10 +529,12 @@ func editDay(t *core.Track, date time.Time, dryRun bool) error {
 				}
 				if oldLast.End.After(dateAfter) {
 					if !newLast.End.IsZero() && newLast.End.After(oldLast.End) {
+						// The last day the record reaches into, not counting an end at midnight
+						endDay := util.ToDate(oldLast.End.Add(-time.Nanosecond))
 						return fmt.Errorf(
-							"can't extend an end time on the day after (%s). Try 'track edit day %s'",
+							"can't extend an end time on a following day (%s). Try 'track edit day %s'",
 							newLast.Start.Format(util.TimeFormat),
-							dateAfter.Format(util.DateFormat),
+							endDay.Format(util.DateFormat),
 						)
 					}
 				}
@@ -411,7 +545,7 @@ func editDay(t *core.Track, date time.Time, dryRun bool) error {
 				for i, rec := range newRecords {
 					project, ok := projects[rec.Project]
 					if !ok {
-						return fmt.Errorf("project '%s' does not exist (%s)", rec.Project, rec.Start.Format(util.TimeFormat))
+						return fmt.Errorf("%w (%s)", &core.ProjectNotFoundError{Name: rec.Project}, rec.Start.Format(util.TimeFormat))
 					}
 					if err := rec.Check(&project); err != nil {
 						return err
@@ -425,7 +559,7 @@ func editDay(t *core.Track, date time.Time, dryRun bool) error {
 						)
 					}
 					if rec.Start.Before(prevEnd) {
-						return fmt.Errorf("records overlap (%s / %s)", prevStart.Format(util.TimeFormat), rec.Start.Format(util.TimeFormat))
+						return fmt.Errorf("%w (%s / %s)", core.ErrOverlap, prevStart.Format(util.TimeFormat), rec.Start.Format(util.TimeFormat))
 					}
 					if rec.End.IsZero() {
 						if i != len(newRecords)-1 {
@@ -458,17 +592,17 @@ func editDay(t *core.Track, date time.Time, dryRun bool) error {
 
 			}
 
-			if !dryRun {
-				for _, rec := range records {
-					t.DeleteRecord(&rec)
-				}
-
-				for _, rec := range newRecords {
-					t.SaveRecord(&rec, false)
-				}
+			if dryRun {
+				return nil
 			}
-
-			return nil
+			tx := t.Begin()
+			for i := range records {
+				tx.Delete(&records[i])
+			}
+			for i := range newRecords {
+				tx.Save(&newRecords[i], false)
+			}
+			return tx.Commit()
 		})
 }
 
@@ -522,6 +656,9 @@ func editConfig(t *core.Track, dryRun bool) error {
 				return err
 			}
 
+			if err := t.CheckTagPrefix(newConfig.TagPrefix); err != nil {
+				return err
+			}
 			if !dryRun {
 				if err = newConfig.Save(t.ConfigPath()); err != nil {
 					return err
@@ -609,6 +746,21 @@ func renameProject(t *core.Track, p *core.Project, name string, dryRun bool) (in
 			return 0, 0, fmt.Errorf("project with name '%s' already exists", name)
 		}
 	}
+	filters := core.NewFilter(
+		[]core.FilterFunction{core.FilterByProjects([]string{p.Name})},
+		util.NoTime, util.NoTime,
+	)
+	filters.IncludeArchived = true
+	records, err := t.LoadAllRecordsFiltered(filters)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, rec := range records {
+		if rec.Locked {
+			return 0, 0, fmt.Errorf("record %s is locked", rec.Start.Format(util.DateTimeFormat))
+		}
+	}
+
 	projectCount := 0
 	for _, project := range allProjects {
 		if project.Parent == p.Name {
@@ -623,21 +775,11 @@ func renameProject(t *core.Track, p *core.Project, name string, dryRun bool) (in
 		}
 	}
 
-	filters := core.NewFilter(
-		[]core.FilterFunction{core.FilterByProjects([]string{p.Name})},
-		util.NoTime, util.NoTime,
-	)
-	fn, results, _ := t.AllRecordsFiltered(filters, false)
-	go fn()
-
 	recordCount := 0
-	for rec := range results {
-		if rec.Err != nil {
-			return 0, projectCount, rec.Err
-		}
-		rec.Record.Project = name
+	for _, rec := range records {
+		rec.Project = name
 		if !dryRun {
-			err := t.SaveRecord(&rec.Record, true)
+			err := t.SaveRecord(&rec, true)
 			if err != nil {
 				return recordCount, projectCount, err
 			}
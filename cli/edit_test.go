@@ -167,3 +167,50 @@ func TestEditRecordDay(t *testing.T) {
 		t.Fatalf("error executing command: %s", err.Error())
 	}
 }
+
+func TestEditTag(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	record := core.Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+		Note:    "Test note with +tag=1",
+		Tags:    map[string]string{"tag": "1"},
+	}
+	err = track.SaveRecord(&record, false)
+	if err != nil {
+		t.Fatal("error saving record")
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"edit", "tag", "tag", "other", "--dry"})
+	err = cmd.Execute()
+	if err != nil {
+		t.Fatalf("error executing command: %s", err.Error())
+	}
+
+	rec, err := track.LoadRecord(record.Start)
+	if err != nil {
+		t.Fatal("error loading record")
+	}
+	assert.Equal(t, "Test note with +tag=1", rec.Note, "Record should not be changed in dry-run")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"edit", "tag", "tag", "other"})
+	err = cmd.Execute()
+	if err != nil {
+		t.Fatalf("error executing command: %s", err.Error())
+	}
+
+	rec, err = track.LoadRecord(record.Start)
+	if err != nil {
+		t.Fatal("error loading record")
+	}
+	assert.Equal(t, "Test note with +other=1", rec.Note, "Wrong note")
+	assert.Equal(t, map[string]string{"other": "1"}, rec.Tags, "Wrong tags")
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/mlange-42/track/core"
 	"github.com/mlange-42/track/out"
@@ -23,6 +24,7 @@ func exportCommand(t *core.Track) *cobra.Command {
 	}
 
 	export.AddCommand(exportRecordsCommand(t))
+	export.AddCommand(exportEnvCommand(t))
 
 	export.Long += "\n\n" + formatCmdTree(export)
 	return export
@@ -48,7 +50,7 @@ The default export format is CSV.`,
 				return fmt.Errorf("failed to export records: %s", err)
 			}
 
-			filters, err := createFilters(&options, projects, true)
+			filters, err := createFilters(t.Syntax(), &options, projects, true)
 			if err != nil {
 				return fmt.Errorf("failed to export records: %s", err)
 			}
@@ -63,7 +65,7 @@ The default export format is CSV.`,
 			} else if yaml {
 				writer = records.YAMLRenderer{Results: results}
 			} else {
-				writer = records.CsvRenderer{Separator: ",", Results: results}
+				writer = records.CsvRenderer{Separator: ",", Formatter: t.Config.Formatter(), Results: results}
 			}
 
 			writer.Render(io)
@@ -74,6 +76,11 @@ The default export format is CSV.`,
 
 	records.Flags().StringSliceVarP(&options.projects, "projects", "p", []string{}, "Projects to include (comma-separated). All projects if not specified")
 	records.Flags().StringSliceVarP(&options.tags, "tags", "t", []string{}, "Tags to include (comma-separated). Includes records with any of the given tags")
+	_ = records.RegisterFlagCompletionFunc("tags", completeTags(t))
+	records.Flags().StringSliceVar(&options.excludeProjects, "exclude-projects", []string{}, "Projects to exclude (comma-separated), including their sub-projects")
+	records.Flags().StringSliceVar(&options.excludeTags, "exclude-tags", []string{}, "Tags to exclude (comma-separated). Excludes records with any of the given tags")
+	_ = records.RegisterFlagCompletionFunc("exclude-tags", completeTags(t))
+	records.Flags().StringSliceVar(&options.categories, "categories", []string{}, "Categories to include (comma-separated). Includes records with any of the given categories")
 	records.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
 	records.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
 
@@ -84,3 +91,52 @@ The default export format is CSV.`,
 
 	return records
 }
+
+func exportEnvCommand(t *core.Track) *cobra.Command {
+	var dotEnv bool
+
+	env := &cobra.Command{
+		Use:   "env",
+		Short: "Export the running record as environment variables",
+		Long: `Export the running record as environment variables
+
+Emits shell statements for evaluation, like in prompts and scripts:
+
+  eval "$(track export env)"
+
+With flag --dotenv, emits a dotenv file instead.
+
+Variables are:
+
+* TRACK_ACTIVE        - "true" if a record is running, "false" otherwise
+* TRACK_PROJECT       - project of the record
+* TRACK_START         - start of the record, in RFC 3339 format
+* TRACK_ELAPSED       - time of the record in seconds, excluding pauses
+* TRACK_PAUSED        - "true" if the record is paused, "false" otherwise
+* TRACK_PAUSE_ELAPSED - time of the current pause in seconds
+* TRACK_CATEGORY      - category of the record
+* TRACK_TAGS          - tags of the record, comma-separated
+* TRACK_NOTE          - note of the record
+
+Variables are empty if no record is running.`,
+		Aliases: []string{"e"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			record, err := t.OpenRecord()
+			if err != nil {
+				return fmt.Errorf("failed to export environment: %s", err)
+			}
+			for _, v := range core.RecordEnv(record, time.Now()) {
+				if dotEnv {
+					out.Print("%s\n", v.DotEnvString())
+				} else {
+					out.Print("%s\n", v.ShellString())
+				}
+			}
+			return nil
+		},
+	}
+	env.Flags().BoolVar(&dotEnv, "dotenv", false, "Export in dotenv format")
+
+	return env
+}
@@ -60,8 +60,8 @@ func TestExport(t *testing.T) {
 	}
 
 	got := string(outStr)
-	expected := `start,end,project,total,work,pause,note,tags
-2001-02-03 04:05,2001-02-03 05:05,test,01:00,00:55,00:05,"Test note with +tag=1",tag=1
+	expected := `start,end,project,total,work,pause,note,tags,links
+2001-02-03 04:05,2001-02-03 05:05,test,01:00,00:55,00:05,"Test note with +tag=1",tag=1,
 `
 	assert.Equal(t, expected, got, "unexpected CSV output")
 
@@ -81,3 +81,42 @@ func TestExport(t *testing.T) {
 		t.Fatal("error executing command")
 	}
 }
+
+func TestExportEnv(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	err = track.SaveProject(project, false)
+	if err != nil {
+		t.Fatal("error saving project")
+	}
+
+	buffer := bytes.NewBufferString("")
+	stdOut := out.StdOut
+	out.StdOut = buffer
+	defer func() { out.StdOut = stdOut }()
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"export", "env"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+	assert.Contains(t, buffer.String(), "export TRACK_ACTIVE='false'\n")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"start", "test", "Note", "+dev"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	buffer.Reset()
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"export", "env", "--dotenv"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+	assert.Contains(t, buffer.String(), "TRACK_ACTIVE=\"true\"\n")
+	assert.Contains(t, buffer.String(), "TRACK_PROJECT=\"test\"\n")
+	assert.Contains(t, buffer.String(), "TRACK_TAGS=\"dev\"\n")
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func historyCommand(t *core.Track) *cobra.Command {
+	var revert int
+
+	history := &cobra.Command{
+		Use:   "history DATE TIME",
+		Short: "Show the history of a record, or revert it to an earlier version",
+		Long: `Show the history of a record, or revert it to an earlier version
+
+Lists all saved versions of the record, oldest first.
+Versions are only stored if config entry history is enabled.
+
+With flag --revert, the record is reverted to the version with the given number.`,
+		Args: util.WrappedArgs(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tm, err := util.ParseDateTime(strings.Join(args, " "))
+			if err != nil {
+				return fmt.Errorf("failed to show record history: %s", err)
+			}
+
+			if revert > 0 {
+				record, err := t.RevertRecord(tm, revert)
+				if err != nil {
+					return fmt.Errorf("failed to revert record: %s", err)
+				}
+				out.Success("Reverted record %s in '%s' to version %d", record.Start.Format(util.DateTimeFormat), record.Project, revert)
+				return nil
+			}
+
+			versions, err := t.RecordHistory(tm)
+			if err != nil {
+				return fmt.Errorf("failed to show record history: %s", err)
+			}
+			if len(versions) == 0 {
+				return fmt.Errorf("failed to show record history: no history for record %s", tm.Format(util.DateTimeFormat))
+			}
+			for _, v := range versions {
+				if v.Deleted {
+					out.Print("%3d  %s  deleted\n", v.Version, v.Saved.Format(util.DateTimeFormat))
+					continue
+				}
+				r := v.Record
+				end := "?"
+				if !r.End.IsZero() {
+					end = r.End.Format(util.TimeFormat)
+				}
+				note := strings.SplitN(r.Note, "\n", 2)[0]
+				out.Print(
+					"%3d  %s  %-15s %s - %s (%s)  %s\n",
+					v.Version, v.Saved.Format(util.DateTimeFormat), r.Project,
+					r.Start.Format(util.TimeFormat), end,
+					util.FormatDuration(r.Duration(util.NoTime, util.NoTime)), note,
+				)
+			}
+			return nil
+		},
+	}
+
+	history.Flags().IntVar(&revert, "revert", 0, "Revert the record to the version with the given number")
+
+	return history
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+	track.Config.History = true
+
+	record := core.Record{
+		Project: "test",
+		Note:    "first",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	err = track.SaveRecord(&record, false)
+	if err != nil {
+		t.Fatal("error saving record")
+	}
+	record.Note = "second"
+	err = track.SaveRecord(&record, true)
+	if err != nil {
+		t.Fatal("error saving record")
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"history", "2001-02-03", "04:05"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"history", "2001-02-03", "04:05", "--revert", "1"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	loaded, err := track.LoadRecord(record.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "first", loaded.Note)
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"history", "2001-02-04", "04:05"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for record without history")
+}
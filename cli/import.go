@@ -0,0 +1,523 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/ics"
+	"github.com/mlange-42/track/integration"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func importCommand(t *core.Track) *cobra.Command {
+	importCom := &cobra.Command{
+		Use:     "import",
+		Short:   "Import resources",
+		Long:    `Import resources`,
+		Aliases: []string{"im"},
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	importCom.AddCommand(importHolidaysCommand(t))
+	importCom.AddCommand(importEventsCommand(t))
+	importCom.AddCommand(importActivityWatchCommand(t))
+	importCom.AddCommand(importWakaTimeCommand(t))
+	importCom.AddCommand(importGitCommand(t))
+
+	importCom.Long += "\n\n" + formatCmdTree(importCom)
+	return importCom
+}
+
+func importHolidaysCommand(t *core.Track) *cobra.Command {
+	options := filterOptions{}
+	var dryRun bool
+
+	holidays := &cobra.Command{
+		Use:   "holidays FILE",
+		Short: "Import public holidays from an ICS calendar",
+		Long: `Import public holidays from an ICS calendar
+
+Imports the events of an iCalendar file as holiday absences, with the event title as note.
+Use '-' as FILE to read from standard input.
+Holiday calendars for most countries and regions are available online.
+
+Days that already have an absence are skipped.
+Holidays are not records, and are not counted for any project.
+Like all full-day absences, they neither break nor extend streaks.`,
+		Aliases: []string{"h"},
+		Args:    util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTime, endTime, err := parseStartEnd(&options)
+			if err != nil {
+				return fmt.Errorf("failed to import holidays: %s", err)
+			}
+			events, err := readCalendar(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to import holidays: %s", err)
+			}
+			absences, err := t.LoadAbsences()
+			if err != nil {
+				return fmt.Errorf("failed to import holidays: %s", err)
+			}
+
+			imported, skipped := 0, 0
+			for _, e := range events {
+				for _, day := range e.Days() {
+					if (!startTime.IsZero() && day.Before(startTime)) || (!endTime.IsZero() && !day.Before(endTime)) {
+						continue
+					}
+					holiday, err := core.NewAbsence(core.AbsenceHoliday, day, day, 0, e.Summary)
+					if err != nil {
+						return fmt.Errorf("failed to import holidays: %s", err)
+					}
+					if err := absences.Add(holiday); err != nil {
+						skipped++
+						continue
+					}
+					imported++
+				}
+			}
+
+			if dryRun {
+				out.Success("Imported %d holiday(s), skipped %d - dry-run", imported, skipped)
+				return nil
+			}
+			if err := t.SaveAbsences(&absences); err != nil {
+				return fmt.Errorf("failed to import holidays: %s", err)
+			}
+			out.Success("Imported %d holiday(s), skipped %d", imported, skipped)
+			return nil
+		},
+	}
+	holidays.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	holidays.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+	holidays.Flags().BoolVar(&dryRun, "dry", false, "Dry run: do not actually change any files")
+
+	return holidays
+}
+
+func importEventsCommand(t *core.Track) *cobra.Command {
+	options := filterOptions{}
+	var project string
+	var mapping map[string]string
+	var category string
+	var dryRun bool
+
+	events := &cobra.Command{
+		Use:   "events FILE",
+		Short: "Import calendar events from an ICS calendar as records",
+		Long: `Import calendar events from an ICS calendar as records
+
+Imports the events of an iCalendar file as records, with the event title as note.
+Tags in event titles are extracted, like for any note.
+Use '-' as FILE to read from standard input.
+
+Events are imported into the project given by --project.
+With --map, events with a calendar category are imported into the mapped project instead, like --map Standup=MyProject.
+Events without a project are skipped.
+
+All-day events, cancelled events and events that have not ended yet are skipped.
+Events that overlap existing records, or other imported events, are skipped, too.
+Thus, a calendar can be imported repeatedly.
+All records are imported all-or-nothing.`,
+		Aliases: []string{"e"},
+		Args:    util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTime, endTime, err := parseStartEnd(&options)
+			if err != nil {
+				return fmt.Errorf("failed to import events: %s", err)
+			}
+			if err := t.Config.CheckCategory(category); err != nil {
+				return fmt.Errorf("failed to import events: %s", err)
+			}
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to import events: %s", err)
+			}
+			names := map[string]string{}
+			for cat, proj := range mapping {
+				if names[cat], err = core.ResolveProjectName(proj, projects); err != nil {
+					return fmt.Errorf("failed to import events: %s", err)
+				}
+			}
+			if project != "" {
+				if project, err = core.ResolveProjectName(project, projects); err != nil {
+					return fmt.Errorf("failed to import events: %s", err)
+				}
+			}
+
+			calendar, err := readCalendar(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to import events: %s", err)
+			}
+
+			now := time.Now()
+			tx := t.Begin()
+			imported := []core.Record{}
+			skipped := 0
+			for _, e := range calendar {
+				if e.AllDay || e.Status == "CANCELLED" || !e.End.After(e.Start) || e.End.After(now) ||
+					(!startTime.IsZero() && e.Start.Before(startTime)) || (!endTime.IsZero() && e.End.After(endTime)) {
+					skipped++
+					continue
+				}
+				proj, ok := projects[eventProject(&e, names, project)]
+				if !ok || proj.Archived {
+					skipped++
+					continue
+				}
+				overlapping, err := t.RecordsOverlapping(e.Start, e.End)
+				if err != nil {
+					return fmt.Errorf("failed to import events: %s", err)
+				}
+				if len(overlapping) > 0 || overlapsAny(imported, e.Start, e.End) {
+					skipped++
+					continue
+				}
+
+				note := strings.TrimSpace(e.Summary)
+				tags, err := t.Syntax().ExtractTags(note)
+				if err != nil {
+					return fmt.Errorf("failed to import event '%s': %s", e.Summary, err)
+				}
+				record := core.Record{
+					Project:  proj.Name,
+					Start:    e.Start,
+					End:      e.End,
+					Note:     note,
+					Tags:     tags,
+					Pause:    []core.Pause{},
+					Category: proj.Category,
+				}
+				if category != "" {
+					record.Category = category
+				}
+				if err := record.Check(&proj); err != nil {
+					return fmt.Errorf("failed to import event '%s': %s", e.Summary, err)
+				}
+				imported = append(imported, record)
+				tx.Save(&record, false)
+			}
+
+			dry := ""
+			if dryRun {
+				tx.Rollback()
+				dry = " - dry-run"
+			} else if err := tx.Commit(); err != nil {
+				return fmt.Errorf("failed to import events: %s", err)
+			}
+			for _, rec := range imported {
+				out.Print("%s %s  %s\n", rec.Start.Format(util.DateTimeFormat), rec.Project, rec.Note)
+			}
+			out.Success("Imported %d event(s), skipped %d%s", len(imported), skipped, dry)
+			return nil
+		},
+	}
+	events.Flags().StringVarP(&project, "project", "p", "", "Project for events without a mapped category")
+	events.Flags().StringToStringVarP(&mapping, "map", "m", map[string]string{}, "Projects for event categories, like Standup=MyProject")
+	events.Flags().StringVar(&category, "category", "", "Category of the imported records, like meeting. Defaults to the project's category")
+	events.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	events.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+	events.Flags().BoolVar(&dryRun, "dry", false, "Dry run: do not actually change any files")
+
+	return events
+}
+
+func importActivityWatchCommand(t *core.Track) *cobra.Command {
+	options := filterOptions{}
+	var dryRun bool
+
+	activityWatch := &cobra.Command{
+		Use:   "activitywatch",
+		Short: "Propose records from ActivityWatch window events",
+		Long: `Propose records from ActivityWatch window events
+
+Reads the window events of ActivityWatch in the given period, and maps them to projects
+by the rules in file 'integrations/activitywatch.yml' of the workspace.
+Proposed records only fill untracked gaps between existing records.
+By default, the current day is used.
+
+Use --dry to only list the proposed records, without saving them.
+All records are imported all-or-nothing.`,
+		Aliases: []string{"aw"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTime, endTime, err := parseImportPeriod(&options)
+			if err != nil {
+				return fmt.Errorf("failed to import from ActivityWatch: %s", err)
+			}
+
+			aw, err := integration.NewActivityWatch(t)
+			if err != nil {
+				return fmt.Errorf("failed to import from ActivityWatch: %s", err)
+			}
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to import from ActivityWatch: %s", err)
+			}
+			events, err := aw.Events(startTime, endTime)
+			if err != nil {
+				return fmt.Errorf("failed to import from ActivityWatch: %s", err)
+			}
+			records, err := t.RecordsOverlapping(startTime, endTime)
+			if err != nil {
+				return fmt.Errorf("failed to import from ActivityWatch: %s", err)
+			}
+
+			suggested := aw.Suggest(events, records, startTime, endTime)
+			if err := saveProposedRecords(t.Begin(), t.Syntax(), projects, suggested, dryRun); err != nil {
+				return fmt.Errorf("failed to import from ActivityWatch: %s", err)
+			}
+			out.Success("Imported %d record(s) from %d event(s)%s", len(suggested), len(events), dryRunSuffix(dryRun))
+			return nil
+		},
+	}
+	activityWatch.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00). Defaults to today")
+	activityWatch.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00). Defaults to now")
+	activityWatch.Flags().BoolVar(&dryRun, "dry", false, "Dry run: only list proposed records, do not actually change any files")
+
+	return activityWatch
+}
+
+func importWakaTimeCommand(t *core.Track) *cobra.Command {
+	options := filterOptions{}
+	var dryRun bool
+
+	wakaTime := &cobra.Command{
+		Use:   "wakatime",
+		Short: "Create records from WakaTime coding time",
+		Long: `Create records from WakaTime coding time
+
+Reads the coding durations of WakaTime in the given period, and maps WakaTime projects to projects
+by file 'integrations/wakatime.yml' of the workspace.
+The API key is read from environment variable ` + integration.WakaTimeKeyEnvVar + `.
+Records are only created for untracked gaps between existing records.
+By default, the current day is used.
+
+Use --dry to only list the proposed records, without saving them.
+All records are imported all-or-nothing.`,
+		Aliases: []string{"w"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTime, endTime, err := parseImportPeriod(&options)
+			if err != nil {
+				return fmt.Errorf("failed to import from WakaTime: %s", err)
+			}
+			wt, err := integration.NewWakaTime(t)
+			if err != nil {
+				return fmt.Errorf("failed to import from WakaTime: %s", err)
+			}
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to import from WakaTime: %s", err)
+			}
+			durations, err := wt.Durations(startTime, endTime)
+			if err != nil {
+				return fmt.Errorf("failed to import from WakaTime: %s", err)
+			}
+			records, err := t.RecordsOverlapping(startTime, endTime)
+			if err != nil {
+				return fmt.Errorf("failed to import from WakaTime: %s", err)
+			}
+
+			suggested := wt.Suggest(durations, records, startTime, endTime)
+			if err := saveProposedRecords(t.Begin(), t.Syntax(), projects, suggested, dryRun); err != nil {
+				return fmt.Errorf("failed to import from WakaTime: %s", err)
+			}
+			out.Success("Imported %d record(s) from %d coding duration(s)%s", len(suggested), len(durations), dryRunSuffix(dryRun))
+			return nil
+		},
+	}
+	wakaTime.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00). Defaults to today")
+	wakaTime.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00). Defaults to now")
+	wakaTime.Flags().BoolVar(&dryRun, "dry", false, "Dry run: only list proposed records, do not actually change any files")
+
+	return wakaTime
+}
+
+func importGitCommand(t *core.Track) *cobra.Command {
+	options := filterOptions{}
+	var annotateOnly bool
+	var dryRun bool
+
+	git := &cobra.Command{
+		Use:   "git",
+		Short: "Annotate and propose records from git commits",
+		Long: `Annotate and propose records from git commits
+
+Scans the git repositories configured in file 'integrations/git.yml' of the workspace
+for commits in the given period, to help reconstructing forgotten days.
+Requires the git command line tool.
+
+Records of a repository's project get tags for issue keys in the branch names of commits made during the record,
+like +PROJ-123 for branch feature/PROJ-123-login.
+For untracked gaps between existing records, new records are proposed, assuming some working time before each commit.
+By default, the current day is used.
+
+Use --dry to only list the annotated and proposed records, without saving them.
+All records are saved all-or-nothing.`,
+		Aliases: []string{"g"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTime, endTime, err := parseImportPeriod(&options)
+			if err != nil {
+				return fmt.Errorf("failed to import from git: %s", err)
+			}
+			git, err := integration.NewGit(t)
+			if err != nil {
+				return fmt.Errorf("failed to import from git: %s", err)
+			}
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to import from git: %s", err)
+			}
+			commits, err := git.Commits(startTime, endTime)
+			if err != nil {
+				return fmt.Errorf("failed to import from git: %s", err)
+			}
+			records, err := t.RecordsOverlapping(startTime, endTime)
+			if err != nil {
+				return fmt.Errorf("failed to import from git: %s", err)
+			}
+
+			annotated, err := git.Annotate(records, commits)
+			if err != nil {
+				return fmt.Errorf("failed to import from git: %s", err)
+			}
+			suggested := []core.Record{}
+			if !annotateOnly {
+				suggested = git.Suggest(commits, records, startTime, endTime)
+			}
+
+			tx := t.Begin()
+			for i := range annotated {
+				tx.Save(&annotated[i], true)
+			}
+			if err := saveProposedRecords(tx, t.Syntax(), projects, suggested, dryRun); err != nil {
+				return fmt.Errorf("failed to import from git: %s", err)
+			}
+			for _, rec := range annotated {
+				out.Print("%s %s  %s (annotated)\n", rec.Start.Format(util.DateTimeFormat), rec.Project, rec.Note)
+			}
+			out.Success("Annotated %d and imported %d record(s) from %d commit(s)%s",
+				len(annotated), len(suggested), len(commits), dryRunSuffix(dryRun))
+			return nil
+		},
+	}
+	git.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00). Defaults to today")
+	git.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00). Defaults to now")
+	git.Flags().BoolVar(&annotateOnly, "annotate", false, "Only annotate existing records, do not propose new records")
+	git.Flags().BoolVar(&dryRun, "dry", false, "Dry run: only list annotated and proposed records, do not actually change any files")
+
+	return git
+}
+
+// parseImportPeriod parses the period for importing records from activity trackers.
+// Starts today by default, and ends now at the latest.
+func parseImportPeriod(options *filterOptions) (time.Time, time.Time, error) {
+	startTime, endTime, err := parseStartEnd(options)
+	if err != nil {
+		return startTime, endTime, err
+	}
+	now := time.Now()
+	if startTime.IsZero() {
+		startTime = util.ToDate(now)
+	}
+	if endTime.IsZero() || endTime.After(now) {
+		endTime = now
+	}
+	if !endTime.After(startTime) {
+		return startTime, endTime, fmt.Errorf("end must be after start")
+	}
+	return startTime, endTime, nil
+}
+
+// saveProposedRecords checks and saves records proposed by activity trackers, all-or-nothing, and lists them.
+// Commits the transaction together with any changes staged before. With dryRun, records are only listed.
+func saveProposedRecords(tx *core.Transaction, syntax *core.Syntax, projects map[string]core.Project, records []core.Record, dryRun bool) error {
+	for i := range records {
+		record := &records[i]
+		project, ok := projects[record.Project]
+		if !ok {
+			tx.Rollback()
+			return fmt.Errorf("no project '%s'", record.Project)
+		}
+		tags, err := syntax.ExtractTags(record.Note)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		record.Tags = tags
+		record.Pause = []core.Pause{}
+		record.Category = project.Category
+		if err := record.Check(&project); err != nil {
+			tx.Rollback()
+			return err
+		}
+		tx.Save(record, false)
+	}
+
+	if dryRun {
+		tx.Rollback()
+	} else if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		out.Print("%s - %s %s  %s\n",
+			rec.Start.Format(util.DateTimeFormat), rec.End.Format(util.TimeFormat),
+			rec.Project, rec.Note)
+	}
+	return nil
+}
+
+// dryRunSuffix returns a suffix for success messages in dry-run mode
+func dryRunSuffix(dryRun bool) string {
+	if dryRun {
+		return " - dry-run"
+	}
+	return ""
+}
+
+// eventProject returns the project of the first mapped category of an event, or the default project
+func eventProject(e *ics.Event, mapping map[string]string, project string) string {
+	for _, cat := range e.Categories {
+		if p, ok := mapping[cat]; ok {
+			return p
+		}
+	}
+	return project
+}
+
+// overlapsAny checks if any of the records overlaps the time span from start to end
+func overlapsAny(records []core.Record, start, end time.Time) bool {
+	for _, rec := range records {
+		if rec.Start.Before(end) && start.Before(rec.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// readCalendar reads the events of an ICS file, or from standard input for path '-'
+func readCalendar(path string) ([]ics.Event, error) {
+	var reader io.Reader
+	if path == "-" {
+		reader = out.StdIn
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader = file
+	}
+	return ics.Parse(reader)
+}
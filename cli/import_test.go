@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const holidayCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:1
+DTSTART;VALUE=DATE:20010101
+DTEND;VALUE=DATE:20010102
+SUMMARY:New Year
+END:VEVENT
+BEGIN:VEVENT
+UID:2
+DTSTART;VALUE=DATE:20011224
+DTEND;VALUE=DATE:20011227
+SUMMARY:Christmas
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestImportHolidays(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	file := filepath.Join(track.RootDir, "holidays.ics")
+	err = os.WriteFile(file, []byte(holidayCalendar), 0644)
+	assert.Nil(t, err)
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"create", "absence", "vacation", "2001-12-24"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"import", "holidays", file, "--dry"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	absences, err := track.LoadAbsences()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(absences.Absences))
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"import", "holidays", file, "--start", "2001-02-01"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	absences, err = track.LoadAbsences()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(absences.Absences))
+	assert.Equal(t, "2001-12-25", absences.Absences[1].From)
+	assert.Equal(t, "Christmas", absences.Absences[1].Note)
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"import", "holidays", filepath.Join(track.RootDir, "missing.ics")})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for missing file")
+}
+
+const eventCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:1
+DTSTART:20010105T090000
+DTEND:20010105T093000
+SUMMARY:Standup +team
+CATEGORIES:Standup
+END:VEVENT
+BEGIN:VEVENT
+UID:2
+DTSTART:20010105T140000
+DTEND:20010105T150000
+SUMMARY:Review
+END:VEVENT
+BEGIN:VEVENT
+UID:3
+DTSTART;VALUE=DATE:20010106
+DTEND;VALUE=DATE:20010107
+SUMMARY:Offsite
+END:VEVENT
+BEGIN:VEVENT
+UID:4
+DTSTART:20010108T100000
+DTEND:20010108T110000
+SUMMARY:Cancelled
+STATUS:CANCELLED
+END:VEVENT
+BEGIN:VEVENT
+UID:5
+DTSTART:20010105T143000
+DTEND:20010105T153000
+SUMMARY:Overlapping
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestImportEvents(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	file := filepath.Join(track.RootDir, "events.ics")
+	err = os.WriteFile(file, []byte(eventCalendar), 0644)
+	assert.Nil(t, err)
+
+	for _, project := range []string{"meetings", "team"} {
+		cmd := RootCommand(track, "")
+		cmd.SetArgs([]string{"create", "project", project})
+		err = cmd.Execute()
+		assert.Nil(t, err, "error executing command")
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"import", "events", file, "--project", "meetings", "--dry"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	records, err := track.LoadAllRecords()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(records))
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"import", "events", file, "--project", "meetings", "--map", "Standup=team", "--category", "meeting"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	records, err = track.LoadAllRecords()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, "team", records[0].Project)
+	assert.Equal(t, "Standup +team", records[0].Note)
+	assert.Equal(t, map[string]string{"team": ""}, records[0].Tags)
+	assert.Equal(t, "meeting", records[0].Category)
+	assert.Equal(t, "meetings", records[1].Project)
+	assert.Equal(t, time.Hour, records[1].End.Sub(records[1].Start))
+
+	// Importing again skips events that overlap the imported records
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"import", "events", file, "--project", "meetings"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	records, err = track.LoadAllRecords()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(records))
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"import", "events", file, "--project", "missing"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for missing project")
+}
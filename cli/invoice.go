@@ -0,0 +1,346 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/render/timesheet"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+)
+
+func invoiceCommand(t *core.Track) *cobra.Command {
+	invoice := &cobra.Command{
+		Use:   "invoice",
+		Short: "Create and manage invoices",
+		Long: `Create and manage invoices
+
+Invoices are kept in the invoice registry of the workspace, with their number,
+the invoiced period and records, and their paid status.
+Records that are already invoiced can't be invoiced again.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	invoice.AddCommand(invoiceCreateCommand(t))
+	invoice.AddCommand(invoiceListCommand(t))
+	invoice.AddCommand(invoicePaidCommand(t))
+	invoice.AddCommand(invoiceUnpaidCommand(t))
+	invoice.AddCommand(invoiceCancelCommand(t))
+	invoice.AddCommand(invoicePdfCommand(t))
+
+	invoice.Long += "\n\n" + formatCmdTree(invoice)
+	return invoice
+}
+
+func invoiceCreateCommand(t *core.Track) *cobra.Command {
+	options := filterOptions{}
+	var dryRun bool
+
+	create := &cobra.Command{
+		Use:   "create PROJECT",
+		Short: "Create an invoice for a project",
+		Long: `Create an invoice for a project
+
+Invoices all records of the project and its sub-projects in the given period
+that are not yet invoiced. Running records can't be invoiced.
+Invoice numbers are sequential per year, like 2023-0001.
+
+Invoiced records are locked, so that they can't be changed or deleted.`,
+		Aliases: []string{"c"},
+		Args:    util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, err := t.LoadProject(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to create invoice: %s", err)
+			}
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to create invoice: %s", err)
+			}
+			startTime, endTime, err := parseStartEnd(&options)
+			if err != nil {
+				return fmt.Errorf("failed to create invoice: %s", err)
+			}
+			filters := core.NewFilter([]core.FilterFunction{}, startTime, endTime)
+			filters.IncludeArchived = true
+
+			records, err := t.ProjectRecords(project.Name, filters)
+			if err != nil {
+				return fmt.Errorf("failed to create invoice: %s", err)
+			}
+			invoices, err := t.LoadInvoices()
+			if err != nil {
+				return fmt.Errorf("failed to create invoice: %s", err)
+			}
+			uninvoiced := invoices.Uninvoiced(records)
+			if skipped := len(records) - len(uninvoiced); skipped > 0 {
+				out.Warn("Skipping %d record(s) that are already invoiced\n", skipped)
+			}
+
+			lastDay := endTime
+			if !lastDay.IsZero() {
+				lastDay = lastDay.Add(-24 * time.Hour)
+			}
+			now := time.Now()
+			invoice, err := invoices.Create(project.Name, startTime, lastDay, uninvoiced, now)
+			if err != nil {
+				return fmt.Errorf("failed to create invoice: %s", err)
+			}
+
+			total := core.Earnings{}
+			for _, e := range core.NewEarnings(projects, uninvoiced, util.NoTime, util.NoTime, now, t.Config.Currency) {
+				total.Add(e)
+			}
+			invoice.Amounts = total.Amounts
+
+			if dryRun {
+				out.Print("%s", formatInvoice(invoice, t.Config.Formatter()))
+				out.Success("Created invoice %s - dry-run", invoice.Number)
+				return nil
+			}
+			if err := t.SaveInvoices(&invoices); err != nil {
+				return fmt.Errorf("failed to create invoice: %s", err)
+			}
+			out.Print("%s", formatInvoice(invoice, t.Config.Formatter()))
+			out.Success("Created invoice %s\n", invoice.Number)
+
+			locked, err := t.SetRecordsLocked(uninvoiced, true)
+			if err != nil {
+				return fmt.Errorf("failed to lock invoiced records: %s", err)
+			}
+			out.Success("Locked %d record(s)", locked)
+			return nil
+		},
+	}
+
+	create.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	create.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+	create.Flags().BoolVar(&dryRun, "dry", false, "Dry run: show the invoice without saving it")
+
+	return create
+}
+
+func invoiceListCommand(t *core.Track) *cobra.Command {
+	var unpaid bool
+
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "List invoices",
+		Aliases: []string{"l"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			invoices, err := t.LoadInvoices()
+			if err != nil {
+				return fmt.Errorf("failed to list invoices: %s", err)
+			}
+			f := t.Config.Formatter()
+			for i := range invoices.Invoices {
+				invoice := &invoices.Invoices[i]
+				if unpaid && invoice.IsPaid() {
+					continue
+				}
+				out.Print("%s", formatInvoice(invoice, f))
+			}
+			return nil
+		},
+	}
+
+	list.Flags().BoolVarP(&unpaid, "unpaid", "u", false, "List only unpaid invoices")
+
+	return list
+}
+
+func invoicePaidCommand(t *core.Track) *cobra.Command {
+	var date string
+
+	paid := &cobra.Command{
+		Use:   "paid NUMBER",
+		Short: "Mark an invoice as paid",
+		Args:  util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paidAt := time.Now()
+			if date != "" {
+				var err error
+				paidAt, err = util.ParseDate(date)
+				if err != nil {
+					return fmt.Errorf("failed to mark invoice as paid: %s", err)
+				}
+			}
+			if err := setInvoicePaid(t, args[0], paidAt); err != nil {
+				return fmt.Errorf("failed to mark invoice as paid: %s", err)
+			}
+			out.Success("Marked invoice %s as paid on %s", args[0], paidAt.Format(util.DateFormat))
+			return nil
+		},
+	}
+
+	paid.Flags().StringVarP(&date, "date", "d", "", "Date of payment. Default today")
+
+	return paid
+}
+
+func invoiceUnpaidCommand(t *core.Track) *cobra.Command {
+	unpaid := &cobra.Command{
+		Use:   "unpaid NUMBER",
+		Short: "Mark an invoice as unpaid",
+		Args:  util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setInvoicePaid(t, args[0], time.Time{}); err != nil {
+				return fmt.Errorf("failed to mark invoice as unpaid: %s", err)
+			}
+			out.Success("Marked invoice %s as unpaid", args[0])
+			return nil
+		},
+	}
+
+	return unpaid
+}
+
+func invoiceCancelCommand(t *core.Track) *cobra.Command {
+	cancel := &cobra.Command{
+		Use:   "cancel NUMBER",
+		Short: "Cancel an invoice",
+		Long: `Cancel an invoice
+
+Removes the invoice from the registry, and unlocks its records,
+so that they can be changed and invoiced again.`,
+		Args: util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			invoices, err := t.LoadInvoices()
+			if err != nil {
+				return fmt.Errorf("failed to cancel invoice: %s", err)
+			}
+			invoice, err := invoices.Get(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to cancel invoice: %s", err)
+			}
+			records := make([]core.Record, 0, len(invoice.Records))
+			for _, start := range invoice.Records {
+				record, err := t.LoadRecord(start)
+				if err != nil {
+					out.Warn("Record %s of the invoice not found\n", start.Format(util.DateTimeFormat))
+					continue
+				}
+				records = append(records, record)
+			}
+
+			if err := invoices.Cancel(args[0]); err != nil {
+				return fmt.Errorf("failed to cancel invoice: %s", err)
+			}
+			if err := t.SaveInvoices(&invoices); err != nil {
+				return fmt.Errorf("failed to cancel invoice: %s", err)
+			}
+			out.Success("Cancelled invoice %s\n", args[0])
+
+			unlocked, err := t.SetRecordsLocked(records, false)
+			if err != nil {
+				return fmt.Errorf("failed to unlock records: %s", err)
+			}
+			out.Success("Unlocked %d record(s)", unlocked)
+			return nil
+		},
+	}
+
+	return cancel
+}
+
+func invoicePdfCommand(t *core.Track) *cobra.Command {
+	var name string
+
+	pdf := &cobra.Command{
+		Use:   "pdf NUMBER",
+		Short: "Generate an invoice in PDF format",
+		Long: `Generate an invoice in PDF format
+
+The invoice contains a table with work time and amount per day,
+totals for the invoice, and a signature line.
+Amounts are calculated from the rates of the invoiced projects.`,
+		Args: util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			invoices, err := t.LoadInvoices()
+			if err != nil {
+				return fmt.Errorf("failed to generate invoice: %s", err)
+			}
+			invoice, err := invoices.Get(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to generate invoice: %s", err)
+			}
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate invoice: %s", err)
+			}
+			records := make([]core.Record, 0, len(invoice.Records))
+			for _, start := range invoice.Records {
+				record, err := t.LoadRecord(start)
+				if err != nil {
+					// Not to stdout, which holds the PDF
+					fmt.Fprintf(out.StdErr, "Record %s of the invoice not found\n", start.Format(util.DateTimeFormat))
+					continue
+				}
+				records = append(records, record)
+			}
+
+			renderer := timesheet.InvoicePdfRenderer{
+				Invoice:   invoice,
+				Records:   records,
+				Projects:  projects,
+				Currency:  t.Config.Currency,
+				Formatter: t.Config.Formatter(),
+				Name:      name,
+			}
+			if err := renderer.Render(out.StdOut); err != nil {
+				return fmt.Errorf("failed to generate invoice: %s", err)
+			}
+			return nil
+		},
+	}
+
+	pdf.Flags().StringVar(&name, "name", "", "Name of the issuer of the invoice")
+
+	return pdf
+}
+
+func setInvoicePaid(t *core.Track, number string, paid time.Time) error {
+	invoices, err := t.LoadInvoices()
+	if err != nil {
+		return err
+	}
+	if err := invoices.SetPaid(number, paid); err != nil {
+		return err
+	}
+	return t.SaveInvoices(&invoices)
+}
+
+func formatInvoice(invoice *core.Invoice, f util.Formatter) string {
+	from, to := invoice.From, invoice.To
+	if from == "" {
+		from = "..."
+	}
+	if to == "" {
+		to = "..."
+	}
+	status := "unpaid"
+	if invoice.IsPaid() {
+		status = "paid " + f.Date(invoice.Paid)
+	}
+
+	currencies := maps.Keys(invoice.Amounts)
+	sort.Strings(currencies)
+	amounts := make([]string, len(currencies))
+	for i, c := range currencies {
+		amounts[i] = strings.TrimSpace(fmt.Sprintf("%.2f %s", invoice.Amounts[c], c))
+	}
+
+	return fmt.Sprintf(
+		"%s  %-15s %s - %s  %3d record(s)  %s  %s  (%s)\n",
+		invoice.Number, invoice.Project, from, to, len(invoice.Records),
+		f.Duration(invoice.Time), strings.Join(amounts, ", "), status,
+	)
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvoice(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	err = track.SaveProject(core.NewProject("test", "", "t", []string{}, 0, 0), false)
+	if err != nil {
+		t.Fatal("error saving project")
+	}
+	record := core.Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	err = track.SaveRecord(&record, false)
+	if err != nil {
+		t.Fatal("error saving record")
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"invoice", "create", "test", "--start", "2001-02-01", "--end", "2001-02-28"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"invoice", "create", "test"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for already invoiced records")
+
+	invoices, err := track.LoadInvoices()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(invoices.Invoices))
+	number := invoices.Invoices[0].Number
+
+	record, err = track.LoadRecord(record.Start)
+	assert.Nil(t, err)
+	assert.True(t, record.Locked, "invoiced record should be locked")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"invoice", "paid", number, "--date", "2001-03-15"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"invoice", "list", "--unpaid"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	buffer := bytes.NewBufferString("")
+	stdOut := out.StdOut
+	out.StdOut = buffer
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"invoice", "pdf", number, "--name", "Jane Doe"})
+	err = cmd.Execute()
+	out.StdOut = stdOut
+	assert.Nil(t, err, "error executing command")
+	assert.True(t, strings.HasPrefix(buffer.String(), "%PDF-1.4\n"), "expected a PDF document")
+	assert.Contains(t, buffer.String(), "(Invoice "+number+") Tj")
+	assert.Contains(t, buffer.String(), "(Signature \\(Jane Doe\\)) Tj")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"invoice", "pdf", "0000-0000"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for unknown invoice")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"invoice", "cancel", number})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	invoices, err = track.LoadInvoices()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(invoices.Invoices))
+
+	record, err = track.LoadRecord(record.Start)
+	assert.Nil(t, err)
+	assert.False(t, record.Locked, "record of cancelled invoice should be unlocked")
+}
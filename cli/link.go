@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func linkCommand(t *core.Track) *cobra.Command {
+	linkCom := &cobra.Command{
+		Use:   "link TARGET [NOTE...]",
+		Short: "Attach a link to an URL or a file to the running or last record",
+		Long: `Attach a link to an URL or a file to the running or last record
+
+Links can be used to refer to pull requests, documents or meeting invites.
+Everything after the target is considered a note for the link.
+
+Links of any record can also be edited with 'track edit record'.`,
+		Args: util.WrappedArgs(cobra.MinimumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			record, err := t.LatestRecord()
+			if err != nil {
+				return fmt.Errorf("failed to attach link: %s", err)
+			}
+			if record == nil {
+				return fmt.Errorf("failed to attach link: no records")
+			}
+
+			if err := record.AddLink(args[0], strings.Join(args[1:], " ")); err != nil {
+				return fmt.Errorf("failed to attach link: %s", err)
+			}
+			if err := t.SaveRecord(record, true); err != nil {
+				return fmt.Errorf("failed to attach link: %s", err)
+			}
+
+			out.Success("Attached link to record in '%s' at %s", record.Project, record.Start.Format(util.DateTimeFormat))
+			return nil
+		},
+	}
+
+	return linkCom
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLink(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"link", "https://example.com"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error without records")
+
+	record := core.Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	err = track.SaveRecord(&record, false)
+	if err != nil {
+		t.Fatal("error saving record")
+	}
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"link", "https://example.com/pull/1", "Pull", "request"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	latest, err := track.LatestRecord()
+	assert.Nil(t, err, "error loading record")
+	assert.Equal(t, []core.Link{{Target: "https://example.com/pull/1", Note: "Pull request"}}, latest.Links)
+}
+
+func TestLinkAlias(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	cmd, _, err := RootCommand(track, "").Find([]string{"l"})
+	assert.Nil(t, err)
+	assert.Equal(t, "list", cmd.Name(), "Shortcut 'l' should stay with list")
+}
@@ -31,6 +31,8 @@ func listCommand(t *core.Track) *cobra.Command {
 	list.AddCommand(listRecordsCommand(t))
 	list.AddCommand(listColorsCommand(t))
 	list.AddCommand(listTagsCommand(t))
+	list.AddCommand(listAbsencesCommand(t))
+	list.AddCommand(listBreaksCommand(t))
 
 	list.Long += "\n\n" + formatCmdTree(list)
 	return list
@@ -134,7 +136,9 @@ func listWorkspacesCommand(t *core.Track) *cobra.Command {
 }
 
 func listRecordsCommand(t *core.Track) *cobra.Command {
-	var includeArchived bool
+	options := filterOptions{}
+	var sortBy string
+	var descending bool
 
 	listProjects := &cobra.Command{
 		Use:   "records [DATE]",
@@ -142,43 +146,84 @@ func listRecordsCommand(t *core.Track) *cobra.Command {
 		Long: `List all records for a date
 
 The date can either be a date in default formatting, like "2022-12-31",
-or a word like "yesterday" or  "today" (the default).`,
+or a word like "yesterday" or  "today" (the default).
+
+With flags --start and --end, records of a date range are listed instead.
+Flags --empty-note and --untagged list only records without a note or without tags,
+e.g. to find and annotate records that were started in a hurry.
+
+Records are sorted by start time by default.
+Flag --sort sorts by (start|end|duration|project) instead, and flag --desc reverses the order.`,
 		Aliases:    []string{"r"},
 		Args:       util.WrappedArgs(cobra.MaximumNArgs(1)),
 		ArgAliases: []string{"date"},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			date := util.ToDate(time.Now())
-			var err error
-			if len(args) > 0 {
-				date, err = util.ParseDate(args[0])
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to load records: %s", err)
+			}
+			filters, err := createFilters(t.Syntax(), &options, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to load records: %s", err)
+			}
+			field, err := core.ParseSortField(sortBy)
+			if err != nil {
+				return fmt.Errorf("failed to load records: %s", err)
+			}
+			filters.Sort = core.SortOrder{Field: field, Descending: descending}
+
+			var records []core.Record
+			if options.start != "" || options.end != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("failed to load records: can't use a date together with --start or --end")
+				}
+				var errs []error
+				records, errs, err = t.LoadAllRecordsFilteredLenient(filters)
 				if err != nil {
 					return fmt.Errorf("failed to load records: %s", err)
 				}
-			}
+				warnRecordErrors(errs)
+			} else {
+				date := util.ToDate(time.Now())
+				if len(args) > 0 {
+					date, err = util.ParseDate(args[0])
+					if err != nil {
+						return fmt.Errorf("failed to load records: %s", err)
+					}
+				}
 
-			records, err := t.LoadDateRecordsExact(date)
-			if err != nil {
-				if err == core.ErrNoRecords {
-					out.Warn("no records for %s", date.Format(util.DateFormat))
-					return nil
+				records, err = t.LoadDateRecordsExact(date)
+				if err != nil {
+					if err == core.ErrNoRecords {
+						out.Warn("no records for %s", date.Format(util.DateFormat))
+						return nil
+					}
+					return fmt.Errorf("failed to load records: %s", err)
 				}
-				return fmt.Errorf("failed to load records: %s", err)
+				core.SortRecords(records, filters.Sort)
 			}
 
-			projects, err := t.LoadAllProjects()
-			if err != nil {
-				return fmt.Errorf("failed to export records: %s", err)
-			}
+			count := 0
 			for _, record := range records {
-				project := projects[record.Project]
-				if includeArchived || !project.Archived {
-					printRecord(record, project)
+				if core.Filter(&record, filters) {
+					printRecord(record, projects[record.Project])
+					count++
 				}
 			}
+			if count == 0 {
+				out.Warn("no records found")
+			}
 			return nil
 		},
 	}
-	listProjects.Flags().BoolVarP(&includeArchived, "archived", "a", false, "Include records from archived projects")
+	listProjects.Flags().BoolVarP(&options.includeArchived, "archived", "a", false, "Include records from archived projects")
+	listProjects.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00). Lists a date range instead of a single date")
+	listProjects.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00). Lists a date range instead of a single date")
+	listProjects.Flags().BoolVar(&options.emptyNote, "empty-note", false, "Only list records with an empty note")
+	listProjects.Flags().BoolVar(&options.untagged, "untagged", false, "Only list records without tags")
+	listProjects.Flags().StringSliceVar(&options.categories, "categories", []string{}, "Categories to include (comma-separated). Includes records with any of the given categories")
+	listProjects.Flags().StringVar(&sortBy, "sort", string(core.SortByStart), "Sort records by (start|end|duration|project)")
+	listProjects.Flags().BoolVar(&descending, "desc", false, "Sort records in descending order")
 
 	return listProjects
 }
@@ -202,8 +247,11 @@ func listTagsCommand(t *core.Track) *cobra.Command {
 	var includeArchived bool
 
 	listTags := &cobra.Command{
-		Use:     "tags",
-		Short:   "Lists all tags",
+		Use:   "tags",
+		Short: "Lists all tags",
+		Long: `Lists all tags
+
+Columns are: tag name, number of records, total work time, date of first and last use, and tag values.`,
 		Aliases: []string{"t"},
 		Args:    util.WrappedArgs(cobra.NoArgs),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -251,12 +299,22 @@ func printRecord(r core.Record, project core.Project) {
 			note += " ..."
 		}
 	}
+	if r.Category != "" {
+		note = fmt.Sprintf("[%s] %s", r.Category, note)
+	}
 	out.Print(
 		"%s%s %s %s %s - %s (%5s + %5s)  %s\n", name, fill,
 		project.Render.Sprintf(" %s ", project.Symbol),
 		date, start, end, util.FormatDuration(dur, false), util.FormatDuration(pause, false),
 		note,
 	)
+	for _, l := range r.Links {
+		if l.Note == "" {
+			out.Print("%18s %s %s\n", "", core.LinkPrefix, l.Target)
+		} else {
+			out.Print("%18s %s %s (%s)\n", "", core.LinkPrefix, l.Target, l.Note)
+		}
+	}
 }
 
 func printColorChart() {
@@ -309,39 +367,24 @@ func printTags(t *core.Track, includeArchived bool) error {
 		return err
 	}
 
-	tags := map[string]int{}
-	values := map[string]map[string]bool{}
-
 	filters := []core.FilterFunction{}
 	if !includeArchived {
 		filters = append(filters, core.FilterByArchived(false, projects))
 	}
 
-	fn, results, _ := t.AllRecordsFiltered(core.NewFilter(filters, util.NoTime, util.NoTime), false)
-
-	go fn()
-	for res := range results {
-		if res.Err != nil {
-			return res.Err
-		}
-		for tag, value := range res.Record.Tags {
-			if v, ok := tags[tag]; ok {
-				tags[tag] = v + 1
-				values[tag][value] = true
-			} else {
-				tags[tag] = 1
-				values[tag] = map[string]bool{value: true}
-			}
-		}
+	stats, err := t.TagStatistics(core.NewFilter(filters, util.NoTime, util.NoTime))
+	if err != nil {
+		return err
 	}
 
-	keys := maps.Keys(tags)
-	sort.Strings(keys)
-
-	for _, tag := range keys {
-		v := maps.Keys(values[tag])
+	for _, st := range stats {
+		v := maps.Keys(st.Values)
 		sort.Strings(v)
-		out.Print("%16s %4d", tag, tags[tag])
+		out.Print(
+			"%16s %4d %7s  %s - %s", st.Name, st.Count,
+			util.FormatDuration(st.Duration, false),
+			st.First.Format(util.DateFormat), st.Last.Format(util.DateFormat),
+		)
 		if len(v) > 1 || (len(v) > 0 && v[0] != "") {
 			out.Print(" [%s]", strings.Join(v, " "))
 		}
@@ -231,3 +231,54 @@ func TestListRecords(t *testing.T) {
 	assert.Contains(t, got[1], "2001-02-03 06:05 - 07:05", "Wrong time range")
 	assert.Contains(t, got[1], "Test note with +tag and +foo=baz", "Wrong note")
 }
+
+func TestListRecordsHygiene(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	err = track.SaveProject(project, false)
+	if err != nil {
+		t.Fatal("error saving project")
+	}
+
+	records := []core.Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 4, 5, 0), End: util.DateTime(2001, 2, 3, 5, 5, 0), Note: "Note with +tag", Tags: map[string]string{"tag": ""}},
+		{Project: "test", Start: util.DateTime(2001, 2, 4, 4, 5, 0), End: util.DateTime(2001, 2, 4, 5, 5, 0), Note: "Untagged note"},
+		{Project: "test", Start: util.DateTime(2001, 2, 5, 4, 5, 0), End: util.DateTime(2001, 2, 5, 5, 5, 0)},
+	}
+	for i := range records {
+		if err := track.SaveRecord(&records[i], false); err != nil {
+			t.Fatal("error saving record")
+		}
+	}
+
+	tt := []struct {
+		args  []string
+		lines int
+	}{
+		{[]string{"--start", "2001-02-01"}, 3},
+		{[]string{"--start", "2001-02-01", "--untagged"}, 2},
+		{[]string{"--start", "2001-02-01", "--empty-note"}, 1},
+		{[]string{"--start", "2001-02-04", "--end", "2001-02-04"}, 1},
+		{[]string{"2001-02-03", "--untagged"}, 0},
+	}
+	for _, test := range tt {
+		cmd := RootCommand(track, "")
+		cmd.SetArgs(append([]string{"list", "records"}, test.args...))
+
+		buffer := bytes.NewBufferString("")
+		out.StdOut = buffer
+		err = cmd.Execute()
+		assert.Nil(t, err, "error executing command with %v", test.args)
+		assert.Equal(t, test.lines, strings.Count(buffer.String(), "2001-02-"), "Wrong number of records for %v", test.args)
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"list", "records", "2001-02-03", "--start", "2001-02-01"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for date and start")
+}
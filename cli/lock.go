@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func lockCommand(t *core.Track) *cobra.Command {
+	lock := &cobra.Command{
+		Use:   "lock DATE TIME",
+		Short: "Lock a record",
+		Long: `Lock a record
+
+Locked records can't be changed or deleted, until they are unlocked.
+Records are also locked by 'invoice create' and by 'report timesheet --submit'.`,
+		Args: util.WrappedArgs(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			record, err := setRecordLocked(t, strings.Join(args, " "), true)
+			if err != nil {
+				return fmt.Errorf("failed to lock record: %s", err)
+			}
+			out.Success("Locked record %s in '%s'", record.Start.Format(util.DateTimeFormat), record.Project)
+			return nil
+		},
+	}
+
+	return lock
+}
+
+func unlockCommand(t *core.Track) *cobra.Command {
+	unlock := &cobra.Command{
+		Use:   "unlock DATE TIME",
+		Short: "Unlock a record",
+		Long: `Unlock a record
+
+Unlocked records can be changed and deleted again.
+To unlock all records of an invoice, use 'invoice cancel'.`,
+		Args: util.WrappedArgs(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			record, err := setRecordLocked(t, strings.Join(args, " "), false)
+			if err != nil {
+				return fmt.Errorf("failed to unlock record: %s", err)
+			}
+			out.Success("Unlocked record %s in '%s'", record.Start.Format(util.DateTimeFormat), record.Project)
+			return nil
+		},
+	}
+
+	return unlock
+}
+
+func setRecordLocked(t *core.Track, timeString string, locked bool) (core.Record, error) {
+	tm, err := util.ParseDateTime(timeString)
+	if err != nil {
+		return core.Record{}, err
+	}
+	record, err := t.LoadRecord(tm)
+	if err != nil {
+		return core.Record{}, err
+	}
+	if locked && !record.HasEnded() {
+		return core.Record{}, fmt.Errorf("record is still running")
+	}
+	records := []core.Record{record}
+	if _, err := t.SetRecordsLocked(records, locked); err != nil {
+		return core.Record{}, err
+	}
+	return records[0], nil
+}
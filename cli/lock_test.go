@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockUnlock(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	record := core.Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	err = track.SaveRecord(&record, false)
+	if err != nil {
+		t.Fatal("error saving record")
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"lock", "2001-02-03", "04:05"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"delete", "record", "2001-02-03", "04:05", "--force"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for deleting locked record")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"unlock", "2001-02-03", "04:05"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"delete", "record", "2001-02-03", "04:05", "--force"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func mergeCommand(t *core.Track) *cobra.Command {
+	var prefer string
+	var dryRun bool
+
+	merge := &cobra.Command{
+		Use:   "merge DIR",
+		Short: "Merge another track directory into the current workspace",
+		Long: `Merge another track directory into the current workspace
+
+Merges projects and records from the current workspace of another track directory,
+like after using two machines without sync. The other directory is only read.
+
+Projects that are missing are added. Projects with the same name are kept unchanged.
+Records that are missing are added, and identical records are skipped.
+Records with the same start time are merged like with 'track conflicts --resolve':
+the later end time is used, and pauses, tags and links are united.
+For different projects or notes, you are prompted to select a version, unless flag --prefer is given.
+Locked records are kept unchanged, and running records of the other directory are skipped.`,
+		Args: util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if prefer != "" && prefer != "ours" && prefer != "theirs" {
+				return fmt.Errorf("failed to merge: --prefer must be one of 'ours', 'theirs'. Got '%s'", prefer)
+			}
+			other, err := core.OpenReadOnly(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to merge: %s", err)
+			}
+
+			choose := promptMergeChoice
+			if prefer != "" || dryRun {
+				choose = func(field, ours, theirs string) (string, error) {
+					if prefer == "theirs" {
+						return theirs, nil
+					}
+					return ours, nil
+				}
+			}
+
+			result, err := t.MergeFrom(other, choose, dryRun)
+			if err != nil {
+				return fmt.Errorf("failed to merge: %s", err)
+			}
+
+			if result.Locked > 0 {
+				out.Warn("Kept %d locked record(s) unchanged\n", result.Locked)
+			}
+			if result.Running > 0 {
+				out.Warn("Skipped %d running record(s)\n", result.Running)
+			}
+			dry := ""
+			if dryRun {
+				dry = " - dry-run"
+			}
+			out.Success(
+				"Merged %s: %d project(s) added, %d record(s) added, %d merged, %d identical%s",
+				args[0], result.Projects, result.Added, result.Merged, result.Identical, dry,
+			)
+			return nil
+		},
+	}
+	merge.Flags().StringVar(&prefer, "prefer", "", "Version to use for fields changed in both versions, instead of prompting: 'ours' or 'theirs'")
+	merge.Flags().BoolVar(&dryRun, "dry", false, "Dry run: do not actually change any files")
+
+	return merge
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.RemoveAll(track.RootDir)
+
+	other, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.RemoveAll(other.RootDir)
+
+	err = other.SaveProject(core.NewProject("test", "", "T", []string{}, 0, 15), false)
+	assert.Nil(t, err)
+	record := core.Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	err = other.SaveRecord(&record, false)
+	assert.Nil(t, err)
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"merge", other.RootDir, "--prefer", "mine"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for invalid --prefer")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"merge", other.RootDir, "--dry"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+	_, err = track.LoadRecord(record.Start)
+	assert.NotNil(t, err, "dry run must not add records")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"merge", other.RootDir})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	_, err = track.LoadProject("test")
+	assert.Nil(t, err)
+	_, err = track.LoadRecord(record.Start)
+	assert.Nil(t, err)
+}
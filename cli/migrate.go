@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func migrateCommand(t *core.Track) *cobra.Command {
+	migrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade record files of the current workspace to the current file format",
+		Long: `Upgrade record files of the current workspace to the current file format
+
+Record files in older formats are upgraded in place.
+Files that are already in the current format are not changed.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := t.MigrateRecords()
+			if err != nil {
+				return fmt.Errorf("failed to migrate records: %s", err)
+			}
+			out.Success(
+				"Migrated %d of %d record files to format version %d",
+				result.Migrated, result.Checked, core.RecordFormatVersion,
+			)
+			return nil
+		},
+	}
+
+	return migrate
+}
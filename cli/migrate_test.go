@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	start := util.DateTime(2001, 2, 3, 4, 5, 0)
+	err = util.CreateDir(track.RecordDir(start))
+	if err != nil {
+		t.Fatal("error creating record directory")
+	}
+	err = os.WriteFile(track.RecordPath(start), []byte("04:05 - 05:05\n    test\n"), 0600)
+	if err != nil {
+		t.Fatal("error writing record file")
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"migrate"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	content, err := os.ReadFile(track.RecordPath(start))
+	assert.Nil(t, err, "error reading record file")
+	version, _, err := core.RecordFormat(string(content))
+	assert.Nil(t, err)
+	assert.Equal(t, core.RecordFormatVersion, version)
+}
@@ -42,6 +42,11 @@ If there is no project with the same name as the parent of the project, the pare
 		Aliases: []string{"p"},
 		Args:    util.WrappedArgs(cobra.ExactArgs(2)),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if !*dryRun {
+				if err := t.CheckWritable(); err != nil {
+					return fmt.Errorf("failed to move project: %s", err)
+				}
+			}
 			name := args[0]
 			workspace := args[1]
 
@@ -90,11 +95,17 @@ If there is no project with the same name as the parent of the project, the pare
 					core.FilterByProjects([]string{project.Name}),
 				}, util.NoTime, util.NoTime,
 			)
+			filters.IncludeArchived = true
 
 			records, err := t.LoadAllRecordsFiltered(filters)
 			if err != nil {
 				return fmt.Errorf("failed to move project: %s", err)
 			}
+			for _, rec := range records {
+				if rec.Locked {
+					return fmt.Errorf("failed to move project: record %s is locked", rec.Start.Format(util.DateTimeFormat))
+				}
+			}
 
 			t.Config.Workspace = workspace
 
@@ -120,6 +131,7 @@ If there is no project with the same name as the parent of the project, the pare
 			}
 
 			if *dryRun {
+				printDryRunRecords("move", records)
 				out.Success("Moved project '%s' to workspace '%s' (%d records) - dry-run", name, workspace, len(records))
 			} else {
 				out.Success("Moved project '%s' to workspace '%s' (%d records)", name, workspace, len(records))
@@ -69,12 +69,7 @@ func pauseCommand(t *core.Track) *cobra.Command {
 			}
 
 			note := strings.Join(args, " ")
-			_, err = open.InsertPause(startTime, endTime, note)
-			if err != nil {
-				return fmt.Errorf("failed to insert pause: %s", err)
-			}
-
-			err = t.SaveRecord(open, true)
+			open, err = pauseRecord(t, open, note, startTime, endTime)
 			if err != nil {
 				return fmt.Errorf("failed to pause record: %s", err)
 			}
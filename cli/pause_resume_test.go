@@ -124,3 +124,45 @@ func TestResumeLast(t *testing.T) {
 	}
 
 }
+
+func TestPauseResumeEvents(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.RemoveAll(track.RootDir)
+
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	err = track.SaveProject(project, false)
+	if err != nil {
+		t.Fatal("error saving project")
+	}
+
+	events := []core.EventType{}
+	track.SetEventHook("test", func(e core.Event) { events = append(events, e.Type) })
+
+	for _, args := range [][]string{
+		{"start", "test", "--ago", "60m"},
+		{"pause", "--ago", "50m"},
+		{"resume", "--ago", "40m"},
+		{"pause", "--duration", "5m"},
+		{"stop", "--ago", "10m"},
+		{"resume", "--last"},
+	} {
+		cmd := RootCommand(track, "")
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("error executing command %v: %s", args, err)
+		}
+	}
+
+	expected := []core.EventType{core.EventStart, core.EventPause, core.EventResume, core.EventStop, core.EventResume}
+	if len(events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Fatalf("expected events %v, got %v", expected, events)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func purgeCommand(t *core.Track) *cobra.Command {
+	var exportPath string
+	var noExport bool
+	var force bool
+	var dryRun bool
+
+	purge := &cobra.Command{
+		Use:   "purge PROJECT",
+		Short: "Export and permanently delete all data of a project",
+		Long: `Export and permanently delete all data of a project
+
+Permanently deletes a project with all sub-projects, all their records including locked ones,
+their records in the trash and in the record history, and their invoices.
+This is intended for data deletion requests of clients.
+
+Before deletion, all data is exported as YAML to the file given by flag --export.
+The file must not exist yet. Nothing is deleted if the export fails.
+Use flag --no-export to skip the export.
+
+A deletion report is printed after the data was deleted.
+Purged data can't be restored.`,
+		Args: util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if exportPath == "" && !noExport {
+				return fmt.Errorf("failed to purge project: flag --export or --no-export is required")
+			}
+			if !dryRun {
+				if err := t.CheckWritable(); err != nil {
+					return fmt.Errorf("failed to purge project: %s", err)
+				}
+			}
+			// No fuzzy matching of project names for irreversible deletion
+			name := args[0]
+			if !t.ProjectExists(name) {
+				return fmt.Errorf("failed to purge project: no project named '%s'", name)
+			}
+
+			if dryRun {
+				data, err := t.CollectProjectData(name)
+				if err != nil {
+					return fmt.Errorf("failed to purge project: %s", err)
+				}
+				report := data.Report(util.NoTime)
+				out.Print("%s", formatPurgeReport(&report, t.Config.Formatter()))
+				return nil
+			}
+
+			if !force && !confirm(
+				fmt.Sprintf(
+					"Really permanently delete project '%s' with all sub-projects, records, history and invoices? (yes!/n): ",
+					name,
+				),
+				"yes!",
+			) {
+				return fmt.Errorf("failed to purge project: aborted by user")
+			}
+
+			var report core.PurgeReport
+			var err error
+			if noExport {
+				report, err = t.PurgeProject(name, nil)
+			} else {
+				var file *os.File
+				file, err = os.OpenFile(exportPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+				if err != nil {
+					return fmt.Errorf("failed to purge project: %s", err)
+				}
+				report, err = t.PurgeProject(name, file)
+				if closeErr := file.Close(); err == nil {
+					err = closeErr
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("failed to purge project: %s", err)
+			}
+
+			out.Print("%s", formatPurgeReport(&report, t.Config.Formatter()))
+			if !noExport {
+				out.Print("Exported to   %s\n", exportPath)
+			}
+			return nil
+		},
+	}
+	purge.Flags().StringVarP(&exportPath, "export", "o", "", "File to export the project data to before deletion")
+	purge.Flags().BoolVar(&noExport, "no-export", false, "Delete without exporting the project data")
+	purge.Flags().BoolVarP(&force, "force", "F", false, "Don't prompt for confirmation.")
+	purge.Flags().BoolVar(&dryRun, "dry", false, "Dry run: only show what would be deleted")
+
+	return purge
+}
+
+// formatPurgeReport formats a deletion report. A report without time of deletion is formatted as a dry-run
+func formatPurgeReport(r *core.PurgeReport, f util.Formatter) string {
+	sb := strings.Builder{}
+	if r.Purged.IsZero() {
+		fmt.Fprintln(&sb, "Would permanently delete - dry-run")
+	} else {
+		fmt.Fprintf(&sb, "Permanently deleted on %s\n", f.DateTime(r.Purged))
+	}
+	fmt.Fprintf(&sb, "Projects      %s\n", strings.Join(r.Projects, ", "))
+	fmt.Fprintf(&sb, "Records       %d", r.Records)
+	if r.Records > 0 {
+		fmt.Fprintf(&sb, " (%s), %s - %s", f.Duration(r.Time), f.Date(r.First), f.Date(r.Last))
+	}
+	fmt.Fprintln(&sb)
+	fmt.Fprintf(&sb, "Trash         %d record(s)\n", r.Trash)
+	fmt.Fprintf(&sb, "History       %d record(s)\n", r.History)
+	invoices := "-"
+	if len(r.Invoices) > 0 {
+		invoices = strings.Join(r.Invoices, ", ")
+	}
+	fmt.Fprintf(&sb, "Invoices      %s\n", invoices)
+	return sb.String()
+}
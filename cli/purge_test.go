@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurge(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.RemoveAll(track.RootDir)
+
+	err = track.SaveProject(core.NewProject("test", "", "T", []string{}, 0, 15), false)
+	assert.Nil(t, err)
+	record := core.Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	err = track.SaveRecord(&record, false)
+	assert.Nil(t, err)
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"purge", "test", "--force"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for missing export flag")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"purge", "tes", "--no-export", "--force"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for inexact project name")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"purge", "test", "--no-export", "--dry"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+	assert.True(t, track.ProjectExists("test"))
+
+	export := filepath.Join(track.RootDir, "export.yml")
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"purge", "test", "--export", export, "--force"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	assert.False(t, track.ProjectExists("test"))
+	_, err = track.LoadRecord(record.Start)
+	assert.NotNil(t, err)
+	content, err := os.ReadFile(export)
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "project: test")
+}
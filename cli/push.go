@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/integration"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func pushCommand(t *core.Track) *cobra.Command {
+	options := filterOptions{}
+	var dryRun bool
+	var status bool
+
+	push := &cobra.Command{
+		Use:   "push",
+		Short: "Push records as time entries to external services",
+		Long: `Push records as time entries to external services
+
+Pushes finished records of the current workspace.
+Records that were pushed before are only updated if they changed since.
+Integrations are configured by files in directory 'integrations' of the workspace.
+
+With flag --status, nothing is pushed, and the sync status of each record is listed instead.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+	push.PersistentFlags().BoolVar(&dryRun, "dry", false, "Dry run: do not actually push any records")
+	push.PersistentFlags().BoolVar(&status, "status", false, "List the sync status of records instead of pushing them")
+	push.PersistentFlags().StringSliceVarP(&options.projects, "projects", "p", []string{}, "Projects to include (comma-separated). All projects if not specified")
+	push.PersistentFlags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	push.PersistentFlags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+
+	push.AddCommand(pushHarvestCommand(t, &options, &dryRun, &status))
+	push.AddCommand(pushTempoCommand(t, &options, &dryRun, &status))
+	push.AddCommand(pushRedmineCommand(t, &options, &dryRun, &status))
+
+	push.Long += "\n\n" + formatCmdTree(push)
+	return push
+}
+
+func pushHarvestCommand(t *core.Track, options *filterOptions, dryRun *bool, status *bool) *cobra.Command {
+	harvest := &cobra.Command{
+		Use:   "harvest",
+		Short: "Push records to Harvest",
+		Long: `Push records to Harvest
+
+Pushes records as time entries to Harvest, using the project and task mapping
+from file 'integrations/harvest.yml' of the workspace.
+The personal access token is read from environment variable ` + integration.HarvestTokenEnvVar + `.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pusher, err := integration.NewHarvest(t)
+			if err != nil {
+				return fmt.Errorf("failed to push records: %s", err)
+			}
+			return pushRecords(t, pusher, options, *dryRun, *status)
+		},
+	}
+
+	return harvest
+}
+
+func pushTempoCommand(t *core.Track, options *filterOptions, dryRun *bool, status *bool) *cobra.Command {
+	tempo := &cobra.Command{
+		Use:   "tempo",
+		Short: "Push records to Tempo",
+		Long: `Push records to Tempo
+
+Pushes records as worklogs to Tempo, the time tracking add-on for Jira,
+using the issue mapping and work attributes from file 'integrations/tempo.yml' of the workspace.
+The API token is read from environment variable ` + integration.TempoTokenEnvVar + `.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pusher, err := integration.NewTempo(t)
+			if err != nil {
+				return fmt.Errorf("failed to push records: %s", err)
+			}
+			return pushRecords(t, pusher, options, *dryRun, *status)
+		},
+	}
+
+	return tempo
+}
+
+func pushRedmineCommand(t *core.Track, options *filterOptions, dryRun *bool, status *bool) *cobra.Command {
+	redmine := &cobra.Command{
+		Use:   "redmine",
+		Short: "Push records to Redmine",
+		Long: `Push records to Redmine
+
+Pushes records as time entries to Redmine. Issues are derived from tags like +rm-1234,
+and activities from tags like +activity=dev, using the activity mapping
+from file 'integrations/redmine.yml' of the workspace.
+The API key is read from environment variable ` + integration.RedmineKeyEnvVar + `.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pusher, err := integration.NewRedmine(t)
+			if err != nil {
+				return fmt.Errorf("failed to push records: %s", err)
+			}
+			return pushRecords(t, pusher, options, *dryRun, *status)
+		},
+	}
+
+	return redmine
+}
+
+// pushRecords pushes records matching the filter options with a Pusher, and reports the result.
+// With status, only the sync status of the records is listed.
+func pushRecords(t *core.Track, pusher integration.Pusher, options *filterOptions, dryRun bool, status bool) error {
+	projects, err := t.LoadAllProjects()
+	if err != nil {
+		return fmt.Errorf("failed to push records: %s", err)
+	}
+	filters, err := createFilters(t.Syntax(), options, projects, true)
+	if err != nil {
+		return fmt.Errorf("failed to push records: %s", err)
+	}
+
+	if status {
+		return printSyncStatus(t, pusher, filters)
+	}
+
+	result, err := integration.Push(t, pusher, filters, dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to push records: %s (created %d, updated %d)", err, result.Created, result.Updated)
+	}
+	suffix := ""
+	if dryRun {
+		suffix = " - dry-run"
+	}
+	out.Success(
+		"Pushed records to %s: %d created, %d updated, %d unchanged, %d skipped%s",
+		pusher.Name(), result.Created, result.Updated, result.Unchanged, result.Skipped, suffix,
+	)
+	return nil
+}
+
+// printSyncStatus lists the sync status of records matching the filters
+func printSyncStatus(t *core.Track, pusher integration.Pusher, filters core.FilterFunctions) error {
+	status, err := integration.Status(t, pusher, filters)
+	if err != nil {
+		return fmt.Errorf("failed to get sync status: %s", err)
+	}
+	for _, st := range status {
+		line := fmt.Sprintf("%s %-8s %s", st.Record.Start.Format(util.DateTimeFormat), st.Status, st.Record.Project)
+		if st.RemoteID != "" {
+			line += fmt.Sprintf(" (%s)", st.RemoteID)
+		}
+		if st.Reason != "" {
+			line += fmt.Sprintf(": %s", st.Reason)
+		}
+		out.Print("%s\n", line)
+	}
+	return nil
+}
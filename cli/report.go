@@ -20,15 +20,34 @@ func reportCommand(t *core.Track) *cobra.Command {
 
 	report.PersistentFlags().StringSliceVarP(&options.projects, "projects", "p", []string{}, "Projects to include (comma-separated). All projects if not specified")
 	report.PersistentFlags().StringSliceVarP(&options.tags, "tags", "t", []string{}, "Tags to include (comma-separated). Includes records with any of the given tags")
+	_ = report.RegisterFlagCompletionFunc("tags", completeTags(t))
+	report.PersistentFlags().StringSliceVar(&options.excludeProjects, "exclude-projects", []string{}, "Projects to exclude (comma-separated), including their sub-projects")
+	report.PersistentFlags().StringSliceVar(&options.excludeTags, "exclude-tags", []string{}, "Tags to exclude (comma-separated). Excludes records with any of the given tags")
+	_ = report.RegisterFlagCompletionFunc("exclude-tags", completeTags(t))
+	report.PersistentFlags().StringSliceVar(&options.categories, "categories", []string{}, "Categories to include (comma-separated). Includes records with any of the given categories")
 	report.PersistentFlags().BoolVarP(&options.includeArchived, "archived", "a", false, "Include records from archived projects")
 
 	report.AddCommand(timelineReportCommand(t, &options))
 	report.AddCommand(projectsReportCommand(t, &options))
 	report.AddCommand(tagsReportCommand(t, &options))
+	report.AddCommand(categoriesReportCommand(t, &options))
 	report.AddCommand(chartReportCommand(t, &options))
 	report.AddCommand(weekReportCommand(t, &options))
 	report.AddCommand(dayReportCommand(t, &options))
 	report.AddCommand(treemapReportCommand(t, &options))
+	report.AddCommand(plotReportCommand(t, &options))
+	report.AddCommand(statsReportCommand(t, &options))
+	report.AddCommand(burnDownReportCommand(t, &options))
+	report.AddCommand(earningsReportCommand(t, &options))
+	report.AddCommand(timesheetReportCommand(t, &options))
+	report.AddCommand(absencesReportCommand(t, &options))
+	report.AddCommand(expectedReportCommand(t, &options))
+	report.AddCommand(teamReportCommand(t, &options))
+	report.AddCommand(pausesReportCommand(t, &options))
+	report.AddCommand(forecastReportCommand(t, &options))
+	report.AddCommand(anomaliesReportCommand(t, &options))
+	report.AddCommand(compareReportCommand(t, &options))
+	report.AddCommand(diffReportCommand(t, &options))
 
 	report.Long += "\n\n" + formatCmdTree(report)
 	return report
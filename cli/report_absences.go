@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func absencesReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	absences := &cobra.Command{
+		Use:   "absences",
+		Short: "Shows absence days per kind",
+		Long: `Shows absence days per kind
+
+Shows the number of full and partial absence days per kind, and the time of partial absences.
+Days are calendar days.
+
+Reports for the current year if no start and end dates are given.
+Create absences with 'track create absence'.`,
+		Aliases: []string{"a"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTime, endTime, err := parseStartEnd(options)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			today := util.ToDate(time.Now())
+			if startTime.IsZero() {
+				startTime = util.Date(today.Year(), 1, 1)
+			}
+			if endTime.IsZero() {
+				endTime = util.Date(startTime.Year()+1, 1, 1)
+			}
+			if !endTime.After(startTime) {
+				return fmt.Errorf("failed to generate report: end date must not be before start date")
+			}
+
+			absences, err := t.LoadAbsences()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			days := absences.Days(startTime, endTime)
+			out.Print("%s", renderAbsences(days, startTime, endTime, t.Config.Formatter()))
+			return nil
+		},
+	}
+	absences.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00). Defaults to the start of the current year")
+	absences.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00). Defaults to the end of the start date's year")
+
+	return absences
+}
+
+// absenceTotals are the summed absence days of a kind
+type absenceTotals struct {
+	FullDays    int
+	PartialDays int
+	PartialTime time.Duration
+}
+
+func (a *absenceTotals) add(day *core.AbsenceDay) {
+	if day.Duration == 0 {
+		a.FullDays++
+		return
+	}
+	a.PartialDays++
+	a.PartialTime += day.Duration
+}
+
+func renderAbsences(days []core.AbsenceDay, start, end time.Time, f util.Formatter) string {
+	totals := map[core.AbsenceKind]*absenceTotals{}
+	total := absenceTotals{}
+	for i := range days {
+		day := &days[i]
+		t, ok := totals[day.Kind]
+		if !ok {
+			t = &absenceTotals{}
+			totals[day.Kind] = t
+		}
+		t.add(day)
+		total.add(day)
+	}
+
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "Absences %s - %s\n\n", f.Date(start), f.Date(end.AddDate(0, 0, -1)))
+	fmt.Fprintf(&sb, "%-10s %6s %8s %8s\n", "kind", "days", "partial", "time")
+	write := func(name string, t *absenceTotals) {
+		partialTime := "-"
+		if t.PartialTime > 0 {
+			partialTime = f.Duration(t.PartialTime)
+		}
+		fmt.Fprintf(&sb, "%-10s %6d %8d %8s\n", name, t.FullDays, t.PartialDays, partialTime)
+	}
+	for _, kind := range core.AbsenceKinds {
+		t, ok := totals[kind]
+		if !ok {
+			t = &absenceTotals{}
+		}
+		write(string(kind), t)
+	}
+	write("total", &total)
+	return sb.String()
+}
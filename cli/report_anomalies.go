@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func anomaliesReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	opts := core.DefaultAnomalyOptions()
+
+	anomalies := &cobra.Command{
+		Use:   "anomalies",
+		Short: "Flags outliers and inconsistencies in records",
+		Long: `Flags outliers and inconsistencies in records
+
+A quick review of the data quality, e.g. before invoicing. Reports
+  - records much longer than the typical (median) duration of their project,
+  - days with an implausible total time,
+  - long days without pauses or gaps between records,
+  - overlapping records.
+
+Typical durations are determined from the records in the time range,
+for projects with at least 5 records.
+
+Reports for the current month if no start and end dates are given.`,
+		Aliases: []string{"an"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTime, endTime, err := parseStartEnd(options)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			now := time.Now()
+			if startTime.IsZero() {
+				startTime = util.Date(now.Year(), now.Month(), 1)
+			}
+			if endTime.IsZero() {
+				endTime = util.Date(startTime.Year(), startTime.Month(), 1).AddDate(0, 1, 0)
+			}
+			if !endTime.After(startTime) {
+				return fmt.Errorf("failed to generate report: end date must not be before start date")
+			}
+
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			filters, err := createFilters(t.Syntax(), options, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			filters = core.NewFilter(filters.Functions, startTime, endTime)
+
+			reporter, err := core.NewReporter(
+				t, options.projects, filters,
+				options.includeArchived, startTime, endTime,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			warnRecordErrors(reporter.Errors)
+
+			found := core.FindAnomalies(reporter.Records, opts, now)
+			out.Print("%s", renderAnomalies(found, startTime, endTime, t.Config.Formatter()))
+			return nil
+		},
+	}
+	anomalies.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00). Defaults to the start of the current month")
+	anomalies.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00). Defaults to the end of the start date's month")
+	anomalies.Flags().Float64Var(&opts.DurationFactor, "factor", opts.DurationFactor, "Flag records longer than this factor times the typical duration of their project")
+	anomalies.Flags().DurationVar(&opts.MaxDayTotal, "max-day", opts.MaxDayTotal, "Flag days with a total time of more than this")
+	anomalies.Flags().DurationVar(&opts.BreakAfter, "break-after", opts.BreakAfter, "Flag days with at least this total time without pauses. Zero to disable")
+
+	return anomalies
+}
+
+func renderAnomalies(anomalies []core.Anomaly, start, end time.Time, f util.Formatter) string {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "Anomalies %s - %s\n\n", f.Date(start), f.Date(end.AddDate(0, 0, -1)))
+	if len(anomalies) == 0 {
+		fmt.Fprintln(&sb, "No anomalies found")
+		return sb.String()
+	}
+
+	for _, a := range anomalies {
+		label := a.Date.Weekday().String()[:2] + " " + f.Date(a.Date)
+		fmt.Fprintf(&sb, "%-13s %s  ", label, color.Yellow.Sprintf("%-11s", a.Kind))
+		switch a.Kind {
+		case core.AnomalyLongRecord:
+			fmt.Fprintf(
+				&sb, "%s %s: %s, %.1fx the typical %s",
+				formatRecordSpan(a.Record, f), a.Record.Project,
+				f.Duration(a.Duration), float64(a.Duration)/float64(a.Typical), f.Duration(a.Typical),
+			)
+		case core.AnomalyOverlap:
+			fmt.Fprintf(
+				&sb, "%s %s overlaps %s %s",
+				formatRecordSpan(a.Record, f), a.Record.Project,
+				formatRecordSpan(a.Other, f), a.Other.Project,
+			)
+		case core.AnomalyLongDay:
+			fmt.Fprintf(&sb, "%s tracked", f.Duration(a.Duration))
+		case core.AnomalyNoBreak:
+			fmt.Fprintf(&sb, "%s tracked without pauses", f.Duration(a.Duration))
+		}
+		fmt.Fprintln(&sb)
+	}
+	fmt.Fprintf(&sb, "\n%d anomalies\n", len(anomalies))
+	return sb.String()
+}
+
+// formatRecordSpan formats the start and end time of a record, with an open end for running records
+func formatRecordSpan(r *core.Record, f util.Formatter) string {
+	if !r.HasEnded() {
+		return fmt.Sprintf("%s - ?", f.Time(r.Start))
+	}
+	return fmt.Sprintf("%s - %s", f.Time(r.Start), f.Time(r.End))
+}
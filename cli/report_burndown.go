@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func burnDownReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	var width int
+
+	burnDown := &cobra.Command{
+		Use:   "burndown PROJECT",
+		Short: "Burn-down of a project's tracked time against its estimate",
+		Long: `Burn-down of a project's tracked time against its estimate
+
+Shows the cumulative tracked time and the remaining time per day,
+from the first to the last day with records of the project or its sub-projects.
+
+Set the estimate of a project with flag --estimate of 'create project',
+or with entry estimate in the project file.`,
+		Aliases: []string{"b"},
+		Args:    util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, err := t.LoadProject(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			filters, err := createFilters(t.Syntax(), options, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			startTime, endTime, err := parseStartEnd(options)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			filters.Start, filters.End = startTime, endTime
+
+			records, err := t.ProjectRecords(project.Name, filters)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			burnDown, err := core.NewBurnDown(&project, records, time.Now())
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+
+			out.Print("%s", renderBurnDown(&burnDown, width, t.Config.Formatter()))
+			return nil
+		},
+	}
+	burnDown.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	burnDown.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+	burnDown.Flags().IntVarP(&width, "width", "w", 40, "Width of the remaining time bars")
+
+	return burnDown
+}
+
+func renderBurnDown(b *core.BurnDown, width int, f util.Formatter) string {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "Project '%s', estimate %s\n\n", b.Project, f.Duration(b.Estimate))
+	if len(b.Dates) == 0 {
+		fmt.Fprintln(&sb, "No records")
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "%-13s  %8s  %9s\n", "", "tracked", "remaining")
+	estimate := b.Estimate.Hours()
+	for i, date := range b.Dates {
+		remaining := b.Remaining(i)
+		fmt.Fprintf(
+			&sb, "%s %s  %8s  %9s  ",
+			date.Weekday().String()[:2], f.Date(date),
+			f.Duration(b.Tracked[i]), formatSignedDuration(remaining, f),
+		)
+		if remaining < 0 {
+			fmt.Fprintln(&sb, color.Red.Sprint(util.Bar(-remaining.Hours(), estimate, width)))
+		} else {
+			fmt.Fprintln(&sb, util.Bar(remaining.Hours(), estimate, width))
+		}
+	}
+
+	total := b.Total()
+	fmt.Fprintf(&sb, "\n%.1f%% of the estimate tracked", 100*total.Hours()/estimate)
+	if total > b.Estimate {
+		fmt.Fprintf(&sb, ", exceeded by %s", f.Duration(total-b.Estimate))
+	}
+	fmt.Fprintln(&sb)
+	return sb.String()
+}
+
+// formatSignedDuration formats a duration that may be negative
+func formatSignedDuration(d time.Duration, f util.Formatter) string {
+	if d < 0 {
+		return "-" + f.Duration(-d)
+	}
+	return f.Duration(d)
+}
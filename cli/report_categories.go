@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+)
+
+// noCategoryName is shown for records without a category
+const noCategoryName = "(none)"
+
+func categoriesReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	categories := &cobra.Command{
+		Use:   "categories",
+		Short: "Shows the time and share per record category",
+		Long: `Shows the time and share per record category
+
+Categories, like work or meeting, are independent of projects.
+Lists all categories from config entry categories, and all other categories in use.
+Records without a category are listed as ` + noCategoryName + `.`,
+		Aliases: []string{"cat"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err.Error())
+			}
+
+			filters, err := createFilters(t.Syntax(), options, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err.Error())
+			}
+
+			startTime, endTime, err := parseStartEnd(options)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err.Error())
+			}
+			reporter, err := newAggregateReporter(
+				t, options.projects, filters,
+				options.includeArchived, startTime, endTime,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err.Error())
+			}
+			warnRecordErrors(reporter.Errors)
+
+			out.Print("%s", formatCategories(reporter.CategoryTime, t.Config.Categories, t.Config.Formatter()))
+			return nil
+		},
+	}
+	categories.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	categories.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+
+	return categories
+}
+
+// formatCategories formats the time and share per category, sorted by time.
+// Configured categories are listed even without any time.
+func formatCategories(times map[string]time.Duration, configured []string, f util.Formatter) string {
+	times = maps.Clone(times)
+	for _, c := range configured {
+		if _, ok := times[c]; !ok {
+			times[c] = 0
+		}
+	}
+	if dur, ok := times[""]; ok && dur == 0 {
+		delete(times, "")
+	}
+
+	var total time.Duration
+	for _, dur := range times {
+		total += dur
+	}
+	names := maps.Keys(times)
+	sort.Slice(names, func(i, j int) bool {
+		if times[names[i]] != times[names[j]] {
+			return times[names[i]] > times[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	sb := strings.Builder{}
+	for _, cat := range names {
+		name := cat
+		if name == "" {
+			name = noCategoryName
+		}
+		if utf8.RuneCountInString(name) > 16 {
+			name = string([]rune(name)[:15]) + "."
+		}
+		share := 0.0
+		if total > 0 {
+			share = float64(times[cat]) / float64(total)
+		}
+		bar := util.Bar(share, 1, 30)
+		fmt.Fprintf(&sb, "%-16s %6s %5.1f%%  %s\n", name, f.Duration(times[cat], false), share*100, bar)
+	}
+	fmt.Fprintf(&sb, "%-16s %6s\n", "total", f.Duration(total, false))
+	return sb.String()
+}
@@ -47,7 +47,7 @@ func chartReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
 			filterStart := start.Add(-time.Hour * 24)
 			filterEnd := start.Add(time.Hour * 24)
 
-			filters, err := createFilters(options, projects, false)
+			filters, err := createFilters(t.Syntax(), options, projects, false)
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err)
 			}
@@ -57,6 +57,7 @@ func chartReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err)
 			}
+			warnRecordErrors(reporter.Errors)
 			var active string
 			rec, err := t.OpenRecord()
 			if err != nil {
@@ -138,7 +139,7 @@ func renderDayChart(t *core.Track, reporter *core.Reporter, active string, start
 	}
 
 	out.Print("                    |%s : %s/cell\n",
-		startDate.Format(util.DateFormat),
+		t.Config.Formatter().Date(startDate),
 		time.Duration(1e9*(int(time.Hour)/(bph*1e9))).String(),
 	)
 
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+)
+
+func compareReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	var date string
+	var yearAgo bool
+
+	compare := &cobra.Command{
+		Use:   "compare (week|month|quarter|year)",
+		Short: "Compares the time per project and tag of the current period to a previous period",
+		Long: `Compares the time per project and tag of the current period to a previous period
+
+Shows the time in both periods, and the absolute and percentage change.
+By default, the current period is compared to the preceding period, like this week to last week.
+With flag --year-ago, it is compared to the same period one year ago, like this month to the same month last year.
+
+Flag --date selects the period containing the given date, instead of the current period.
+Weeks start at config entry weekStart, quarters and years at config entry fiscalYearStart.`,
+		Aliases: []string{"cmp"},
+		Args:    util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := util.ToDate(time.Now())
+			if date != "" {
+				var err error
+				if now, err = util.ParseDate(date); err != nil {
+					return fmt.Errorf("failed to generate report: %s", err)
+				}
+			}
+			start, end, err := periodRange(t, args[0], now)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			var prevStart, prevEnd time.Time
+			if yearAgo {
+				prevStart, prevEnd, err = periodRange(t, args[0], start.AddDate(-1, 0, 0))
+			} else {
+				prevStart, prevEnd, err = periodRange(t, args[0], start.AddDate(0, 0, -1))
+			}
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			filters, err := createFilters(t.Syntax(), options, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+
+			reporters := [2]*core.Reporter{}
+			for i, period := range [][2]time.Time{{start, end}, {prevStart, prevEnd}} {
+				// Copy the filter functions, as the reporter appends to them
+				periodFilters := core.NewFilter(append([]core.FilterFunction{}, filters.Functions...), period[0], period[1])
+				reporters[i], err = newAggregateReporter(
+					t, options.projects, periodFilters,
+					options.includeArchived, period[0], period[1],
+				)
+				if err != nil {
+					return fmt.Errorf("failed to generate report: %s", err)
+				}
+				warnRecordErrors(reporters[i].Errors)
+			}
+
+			comparison := reporters[0].Compare(reporters[1])
+			f := t.Config.Formatter()
+			out.Print(
+				"Comparing %s - %s to %s - %s\n\n",
+				f.Date(start), f.Date(end.AddDate(0, 0, -1)),
+				f.Date(prevStart), f.Date(prevEnd.AddDate(0, 0, -1)),
+			)
+			out.Print("%s", renderComparison(&comparison, t.Config.TagPrefix, f))
+			return nil
+		},
+	}
+	compare.Flags().StringVarP(&date, "date", "d", "", "Date in the current period. Defaults to today")
+	compare.Flags().BoolVarP(&yearAgo, "year-ago", "y", false, "Compare to the same period one year ago, instead of the preceding period")
+
+	return compare
+}
+
+// periodRange returns the start and end (exclusive) of the period containing the given date
+func periodRange(t *core.Track, period string, date time.Time) (time.Time, time.Time, error) {
+	fiscalStart := time.Month(t.Config.FiscalYearStart)
+	switch period {
+	case "week", "w":
+		start := util.WeekStart(date, t.Config.FirstWeekday())
+		return start, start.AddDate(0, 0, 7), nil
+	case "month", "m":
+		start := util.Date(date.Year(), date.Month(), 1)
+		return start, start.AddDate(0, 1, 0), nil
+	case "quarter", "q":
+		start := util.QuarterStart(date, fiscalStart)
+		return start, start.AddDate(0, 3, 0), nil
+	case "year", "y":
+		start := util.FiscalYearStart(date, fiscalStart)
+		return start, start.AddDate(1, 0, 0), nil
+	}
+	return util.NoTime, util.NoTime, fmt.Errorf("invalid period '%s'", period)
+}
+
+// renderComparison renders the times and changes per project and tag, and in total
+func renderComparison(c *core.Comparison, tagPrefix string, f util.Formatter) string {
+	projects := maps.Keys(c.Projects)
+	sort.Strings(projects)
+	tags := maps.Keys(c.Tags)
+	sort.Strings(tags)
+
+	width := 8
+	for _, name := range append(append([]string{}, projects...), tags...) {
+		if len(name)+1 > width {
+			width = len(name) + 1
+		}
+	}
+
+	sb := strings.Builder{}
+	writeRow := func(name string, d core.Delta) {
+		fmt.Fprintf(&sb, "%-*s %8s %8s %s\n", width, name, f.Duration(d.Previous), f.Duration(d.Current), formatDelta(d, f))
+	}
+
+	fmt.Fprintf(&sb, "%-*s %8s %8s %9s %8s\n", width, "Projects", "previous", "current", "change", "%")
+	for _, name := range projects {
+		writeRow(name, c.Projects[name])
+	}
+	writeRow("total", c.Total)
+
+	if len(tags) > 0 {
+		fmt.Fprint(&sb, "\nTags\n")
+		for _, name := range tags {
+			writeRow(tagPrefix+name, c.Tags[name])
+		}
+	}
+	return sb.String()
+}
+
+// formatDelta formats the absolute and percentage change of a delta, colored by its sign
+func formatDelta(d core.Delta, f util.Formatter) string {
+	diff := d.Diff()
+	sign := "+"
+	if diff < 0 {
+		sign = "-"
+		diff = -diff
+	}
+	percent := "-"
+	if p, ok := d.Percent(); ok {
+		percent = fmt.Sprintf("%+.1f%%", p)
+	} else if d.Current > 0 {
+		percent = "new"
+	}
+	text := fmt.Sprintf("%9s %8s", sign+f.Duration(diff), percent)
+	switch {
+	case d.Diff() > 0:
+		return color.Green.Sprint(text)
+	case d.Diff() < 0:
+		return color.Red.Sprint(text)
+	}
+	return text
+}
@@ -23,7 +23,9 @@ func weekReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
 
 Reports for the current week if no date is given, or for the past 7 days with flag --7days.
 
-If called with a date, reports for the week containing the date, or for the 7 days starting with the date with flag --7days.`,
+If called with a date, reports for the week containing the date, or for the 7 days starting with the date with flag --7days.
+
+The first day of calendar weeks is determined by config entry weekStart.`,
 		Aliases: []string{"w"},
 		Args:    util.WrappedArgs(cobra.MaximumNArgs(1)),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -36,13 +38,13 @@ If called with a date, reports for the week containing the date, or for the 7 da
 					return fmt.Errorf("failed to generate report: %s", err)
 				}
 				if !exact {
-					start = util.Monday(start)
+					start = util.WeekStart(start, t.Config.FirstWeekday())
 				}
 			} else {
 				if exact {
 					start = start.Add(-6 * 24 * time.Hour)
 				} else {
-					start = util.Monday(start)
+					start = util.WeekStart(start, t.Config.FirstWeekday())
 				}
 			}
 
@@ -128,7 +130,7 @@ func renderSchedule(t *core.Track, start time.Time, options *filterOptions, week
 		return err
 	}
 
-	filters, err := createFilters(options, projects, false)
+	filters, err := createFilters(t.Syntax(), options, projects, false)
 	if err != nil {
 		return err
 	}
@@ -138,10 +140,17 @@ func renderSchedule(t *core.Track, start time.Time, options *filterOptions, week
 	if err != nil {
 		return err
 	}
+	warnRecordErrors(reporter.Errors)
+
+	breaks, err := t.LoadBreaks()
+	if err != nil {
+		return err
+	}
 
 	renderer := schedule.TextRenderer{
 		Track:         t,
 		Reporter:      reporter,
+		Breaks:        breaks.Between(start, filterEnd),
 		StartDate:     start,
 		Weekly:        week,
 		BlocksPerHour: bph,
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func diffReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	other := filterOptions{}
+
+	diff := &cobra.Command{
+		Use:   "diff",
+		Short: "Lists the records matched by the report filters or by other filters, but not by both",
+		Long: `Lists the records matched by the report filters or by other filters, but not by both
+
+Evaluates two filter sets over the same period:
+the report filters, like --projects and --tags, and the other filters, like --other-projects and --other-tags.
+Lists the records matched by only one of them, e.g. to verify what an export or invoice includes,
+compared to a broader report.
+
+Like for other reports, projects include their sub-projects.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			other.start, other.end = options.start, options.end
+
+			first, err := createDiffFilters(t.Syntax(), options, projects)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			second, err := createDiffFilters(t.Syntax(), &other, projects)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+
+			d, err := t.DiffFilters(first, second, first.Start, first.End)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+
+			printDiffRecords("Only matched by the report filters", d.OnlyFirst, projects)
+			out.Print("\n")
+			printDiffRecords("Only matched by the other filters", d.OnlySecond, projects)
+			out.Print("\nMatched by both: %d record(s)\n", d.Both)
+			return nil
+		},
+	}
+	diff.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	diff.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+
+	diff.Flags().StringSliceVar(&other.projects, "other-projects", []string{}, "Other projects to include (comma-separated). All projects if not specified")
+	diff.Flags().StringSliceVar(&other.tags, "other-tags", []string{}, "Other tags to include (comma-separated). Includes records with any of the given tags")
+	_ = diff.RegisterFlagCompletionFunc("other-tags", completeTags(t))
+	diff.Flags().StringSliceVar(&other.excludeProjects, "other-exclude-projects", []string{}, "Other projects to exclude (comma-separated), including their sub-projects")
+	diff.Flags().StringSliceVar(&other.excludeTags, "other-exclude-tags", []string{}, "Other tags to exclude (comma-separated). Excludes records with any of the given tags")
+	_ = diff.RegisterFlagCompletionFunc("other-exclude-tags", completeTags(t))
+	diff.Flags().StringSliceVar(&other.categories, "other-categories", []string{}, "Other categories to include (comma-separated). Includes records with any of the given categories")
+	diff.Flags().BoolVar(&other.includeArchived, "other-archived", false, "Include records from archived projects for the other filters")
+
+	return diff
+}
+
+// createDiffFilters creates filters like createFilters, with projects including their sub-projects
+func createDiffFilters(syntax *core.Syntax, options *filterOptions, projects map[string]core.Project) (core.FilterFunctions, error) {
+	filters, err := createFilters(syntax, options, projects, false)
+	if err != nil {
+		return filters, err
+	}
+	if len(options.projects) > 0 {
+		names, err := core.ResolveProjectNames(options.projects, projects)
+		if err != nil {
+			return filters, err
+		}
+		filters.Functions = append(filters.Functions, core.FilterByProjects(withDescendants(names, projects)))
+	}
+	return filters, nil
+}
+
+// printDiffRecords prints records under a title with their number and total time
+func printDiffRecords(title string, records []core.Record, projects map[string]core.Project) {
+	var total time.Duration
+	for _, r := range records {
+		total += r.Duration(util.NoTime, util.NoTime)
+	}
+	out.Print("%s: %d record(s), %s\n", title, len(records), util.FormatDuration(total, false))
+	for _, r := range records {
+		printRecord(r, projects[r.Project])
+	}
+}
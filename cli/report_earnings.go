@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+)
+
+func earningsReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	var currency string
+	var exchange map[string]string
+
+	earnings := &cobra.Command{
+		Use:   "earnings",
+		Short: "Shows time and earnings per project",
+		Long: `Shows time and earnings per project
+
+Earnings are calculated with the hourly rate valid on the day of each record.
+Projects without rates use the rates of their closest ancestor with rates.
+Set rates with flags --rate and --rate-from of 'edit project'.
+
+Time on days without a valid rate is listed as unrated.
+
+Earnings are reported per currency. Rates without a currency use the currency of their project,
+or the default currency given by config entry currency.
+
+With flag --currency, all earnings are converted into the given currency.
+Exchange rates are taken from config entry exchangeRates, and from flag --exchange.
+They give the value of one unit of each currency in a common reference currency, like
+
+  --currency EUR --exchange USD=0.92,GBP=1.15`,
+		Aliases: []string{"n"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			filters, err := createFilters(t.Syntax(), options, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			startTime, endTime, err := parseStartEnd(options)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			reporter, err := core.NewReporter(
+				t, options.projects, filters,
+				options.includeArchived, startTime, endTime,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			warnRecordErrors(reporter.Errors)
+
+			earnings := core.NewEarnings(
+				reporter.AllProjects, reporter.Records,
+				startTime, endTime, time.Now(), t.Config.Currency,
+			)
+
+			if currency != "" {
+				rates := map[string]float64{}
+				for c, r := range t.Config.ExchangeRates {
+					rates[strings.ToUpper(c)] = r
+				}
+				for c, r := range exchange {
+					rate, err := strconv.ParseFloat(r, 64)
+					if err != nil {
+						return fmt.Errorf("failed to generate report: invalid exchange rate '%s' for %s", r, c)
+					}
+					rates[strings.ToUpper(c)] = rate
+				}
+				if err := convertEarnings(earnings, strings.ToUpper(currency), rates); err != nil {
+					return fmt.Errorf("failed to generate report: %s", err)
+				}
+			}
+
+			out.Print("%s", renderEarnings(earnings, t.Config.Formatter()))
+			return nil
+		},
+	}
+	earnings.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	earnings.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+
+	earnings.Flags().StringVar(&currency, "currency", "", "Convert all earnings into this currency")
+	earnings.Flags().StringToStringVar(&exchange, "exchange", map[string]string{}, "Exchange rates for --currency, like USD=0.92,GBP=1.15.\nOverrides config entry exchangeRates")
+
+	return earnings
+}
+
+// convertEarnings converts the earnings of all projects into a single currency
+func convertEarnings(earnings map[string]*core.Earnings, currency string, exchangeRates map[string]float64) error {
+	for _, e := range earnings {
+		amount, err := core.Convert(e.Amounts, currency, exchangeRates)
+		if err != nil {
+			return err
+		}
+		e.Amounts = map[string]float64{currency: amount}
+	}
+	return nil
+}
+
+func renderEarnings(earnings map[string]*core.Earnings, f util.Formatter) string {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "%-16s %8s %8s %12s\n", "project", "time", "unrated", "amount")
+
+	names := maps.Keys(earnings)
+	sort.Strings(names)
+	total := core.Earnings{}
+	for _, name := range names {
+		e := earnings[name]
+		total.Add(e)
+
+		if utf8.RuneCountInString(name) > 16 {
+			name = string([]rune(name)[:15]) + "."
+		}
+		writeEarnings(&sb, name, e, f)
+	}
+	writeEarnings(&sb, "total", &total, f)
+	return sb.String()
+}
+
+// writeEarnings writes one line per currency of the earnings, or a single line if there are no amounts
+func writeEarnings(sb *strings.Builder, name string, e *core.Earnings, f util.Formatter) {
+	unrated := "-"
+	if e.Unrated > 0 {
+		unrated = f.Duration(e.Unrated)
+	}
+	fmt.Fprintf(sb, "%-16s %8s %8s", name, f.Duration(e.Time), unrated)
+
+	currencies := maps.Keys(e.Amounts)
+	sort.Strings(currencies)
+	if len(currencies) == 0 {
+		fmt.Fprintf(sb, " %12.2f\n", 0.0)
+		return
+	}
+	for i, c := range currencies {
+		if i > 0 {
+			fmt.Fprintf(sb, "%-16s %8s %8s", "", "", "")
+		}
+		fmt.Fprintf(sb, " %12.2f", e.Amounts[c])
+		if c != "" {
+			fmt.Fprintf(sb, " %s", c)
+		}
+		fmt.Fprintln(sb)
+	}
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func expectedReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	var weekly bool
+
+	expected := &cobra.Command{
+		Use:   "expected",
+		Short: "Compares tracked time to the work schedule",
+		Long: `Compares tracked time to the work schedule
+
+Compares the tracked time per day or week to the expected working time,
+set per weekday by config entry workSchedule. Shortfalls are shown in red, surplus in green.
+
+Days with full-day absences, like vacation or public holidays, have no expected time.
+Partial absences reduce the expected time by their duration.
+
+Reports for the current week if no start and end dates are given.
+Future days are not included.`,
+		Aliases: []string{"e"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schedule := t.Config.Schedule()
+			if len(schedule) == 0 {
+				return fmt.Errorf("failed to generate report: no work schedule. Set config entry workSchedule")
+			}
+			startTime, endTime, err := parseStartEnd(options)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			now := time.Now()
+			tomorrow := util.ToDate(now).AddDate(0, 0, 1)
+			if startTime.IsZero() {
+				startTime = util.WeekStart(now, t.Config.FirstWeekday())
+			}
+			if endTime.IsZero() || endTime.After(tomorrow) {
+				endTime = tomorrow
+			}
+			if !endTime.After(startTime) {
+				return fmt.Errorf("failed to generate report: end date must not be before start date")
+			}
+
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			filters, err := createFilters(t.Syntax(), options, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			reporter, err := core.NewReporter(
+				t, options.projects, filters,
+				options.includeArchived, startTime, endTime,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			warnRecordErrors(reporter.Errors)
+			absences, err := t.LoadAbsences()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+
+			daily := core.DailyTimes(reporter.Records, now)
+			days := core.ExpectedTimes(daily, schedule, &absences, startTime, endTime)
+			if weekly {
+				days = weeklyExpectedTimes(days, t.Config.FirstWeekday())
+			}
+			out.Print("%s", renderExpected(days, startTime, endTime, weekly, t.Config.Formatter()))
+			return nil
+		},
+	}
+	expected.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00). Defaults to the start of the current week")
+	expected.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00). Defaults to today")
+	expected.Flags().BoolVarP(&weekly, "weekly", "w", false, "Sum up by week instead of by day")
+
+	return expected
+}
+
+// weeklyExpectedTimes sums up expected and tracked times by week, dated by the first day of each week
+func weeklyExpectedTimes(days []core.ExpectedDay, first time.Weekday) []core.ExpectedDay {
+	weeks := []core.ExpectedDay{}
+	for _, day := range days {
+		start := util.WeekStart(day.Date, first)
+		if len(weeks) == 0 || !weeks[len(weeks)-1].Date.Equal(start) {
+			weeks = append(weeks, core.ExpectedDay{Date: start})
+		}
+		week := &weeks[len(weeks)-1]
+		week.Expected += day.Expected
+		week.Tracked += day.Tracked
+	}
+	return weeks
+}
+
+func renderExpected(days []core.ExpectedDay, start, end time.Time, weekly bool, f util.Formatter) string {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "Expected vs tracked %s - %s\n\n", f.Date(start), f.Date(end.AddDate(0, 0, -1)))
+	fmt.Fprintf(&sb, "%-13s %8s %8s %8s\n", "", "expected", "tracked", "balance")
+
+	total := core.ExpectedDay{}
+	for _, day := range days {
+		label := day.Date.Weekday().String()[:2] + " " + f.Date(day.Date)
+		if weekly {
+			label = "Wk " + f.Date(day.Date)
+		}
+		fmt.Fprintf(&sb, "%-13s %s", label, formatExpectedRow(&day, f))
+		if day.Absence != "" {
+			fmt.Fprintf(&sb, "  %s", day.Absence)
+		}
+		fmt.Fprintln(&sb)
+
+		total.Expected += day.Expected
+		total.Tracked += day.Tracked
+	}
+	fmt.Fprintf(&sb, "%-13s %s\n", "total", formatExpectedRow(&total, f))
+	return sb.String()
+}
+
+// formatExpectedRow formats the expected, tracked and balance columns, with shortfalls in red and surplus in green
+func formatExpectedRow(day *core.ExpectedDay, f util.Formatter) string {
+	balance := day.Balance()
+	balanceStr := fmt.Sprintf("%8s", formatSignedDuration(balance, f))
+	if balance > 0 {
+		balanceStr = color.Green.Sprintf("%8s", "+"+f.Duration(balance))
+	} else if balance < 0 {
+		balanceStr = color.Red.Sprint(balanceStr)
+	}
+	return fmt.Sprintf("%8s %8s %s", f.Duration(day.Expected), f.Duration(day.Tracked), balanceStr)
+}
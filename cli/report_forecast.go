@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+)
+
+func forecastReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	var history int
+	var target time.Duration
+
+	forecast := &cobra.Command{
+		Use:   "forecast (week|month)",
+		Short: "Forecast of the total time per project at the end of the current week or month",
+		Long: `Forecast of the total time per project at the end of the current week or month
+
+Projects the time of the remaining days of the period from the current pace,
+i.e. the average time per elapsed day, and from the historical average time per weekday.
+The historical averages are taken from the given number of previous periods.
+The current pace gets more weight the more of the period has elapsed.
+
+The range is an approximate 80% confidence band, based on the day-to-day variation of the history.
+
+The forecast total is compared to a target, given by flag --target.
+Defaults to the expected time according to config entry workSchedule and absences, if there is a work schedule.`,
+		Aliases: []string{"f"},
+		Args:    util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if history < 0 {
+				return fmt.Errorf("failed to generate report: history must not be negative")
+			}
+			now := time.Now()
+			var start, end, historyStart time.Time
+			switch args[0] {
+			case "week", "w":
+				start = util.WeekStart(now, t.Config.FirstWeekday())
+				end = start.AddDate(0, 0, 7)
+				historyStart = start.AddDate(0, 0, -7*history)
+			case "month", "m":
+				start = util.Date(now.Year(), now.Month(), 1)
+				end = start.AddDate(0, 1, 0)
+				historyStart = start.AddDate(0, -history, 0)
+			default:
+				return fmt.Errorf("failed to generate report: invalid period '%s'", args[0])
+			}
+
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			filters, err := createFilters(t.Syntax(), options, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			reporter, err := core.NewReporter(
+				t, options.projects, filters,
+				options.includeArchived, historyStart, end,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			warnRecordErrors(reporter.Errors)
+
+			if !cmd.Flags().Changed("target") {
+				if schedule := t.Config.Schedule(); len(schedule) > 0 {
+					absences, err := t.LoadAbsences()
+					if err != nil {
+						return fmt.Errorf("failed to generate report: %s", err)
+					}
+					for _, day := range core.ExpectedTimes(nil, schedule, &absences, start, end) {
+						target += day.Expected
+					}
+				}
+			}
+
+			fc := core.NewForecast(reporter.Records, start, end, historyStart, now)
+			out.Print("%s", renderForecast(&fc, target, t.Config.Formatter()))
+			return nil
+		},
+	}
+	forecast.Flags().IntVar(&history, "history", 4, "Number of previous periods for historical averages")
+	forecast.Flags().DurationVar(&target, "target", 0, "Target total time of the period, like 40h.\nDefaults to the expected time according to the work schedule")
+
+	return forecast
+}
+
+func renderForecast(fc *core.Forecast, target time.Duration, f util.Formatter) string {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "Forecast %s - %s\n", f.Date(fc.Start), f.Date(fc.End.AddDate(0, 0, -1)))
+	fmt.Fprintf(&sb, "%d of %d days elapsed, history of %d days\n\n", fc.ElapsedDays, fc.ElapsedDays+fc.RemainingDays, fc.HistoryDays)
+
+	if len(fc.Projects) == 0 {
+		fmt.Fprintln(&sb, "No records")
+		return sb.String()
+	}
+
+	width := 5
+	names := maps.Keys(fc.Projects)
+	sort.Strings(names)
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+
+	fmt.Fprintf(&sb, "%-*s %8s %8s %8s %8s  %s\n", width, "", "tracked", "pace/d", "hist/d", "forecast", "range")
+	for _, name := range names {
+		p := fc.Projects[name]
+		fmt.Fprintf(&sb, "%-*s %s\n", width, name, formatForecastRow(&p, f))
+	}
+	fmt.Fprintf(&sb, "%-*s %s\n", width, "total", formatForecastRow(&fc.Total, f))
+
+	if target > 0 {
+		var outcome string
+		switch {
+		case target <= fc.Total.Low:
+			outcome = color.Green.Sprint("likely reached")
+		case target > fc.Total.High:
+			outcome = color.Red.Sprint("likely missed")
+		default:
+			outcome = color.Yellow.Sprint("within range")
+		}
+		fmt.Fprintf(&sb, "\nTarget %s: %s\n", f.Duration(target), outcome)
+	}
+	return sb.String()
+}
+
+// formatForecastRow formats the columns of a forecast, without the label
+func formatForecastRow(p *core.ProjectForecast, f util.Formatter) string {
+	return fmt.Sprintf(
+		"%8s %8s %8s %8s  %s - %s",
+		f.Duration(p.Tracked), f.Duration(p.Pace), f.Duration(p.Average),
+		f.Duration(p.Expected), f.Duration(p.Low), f.Duration(p.High),
+	)
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func pausesReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	var minPause time.Duration
+	var maxPause time.Duration
+
+	pauses := &cobra.Command{
+		Use:   "pauses",
+		Short: "Shows pause times per day",
+		Long: `Shows pause times per day
+
+Shows the number of pauses, the total and the longest pause time per day, for days with pauses.
+Pauses spanning midnight are split by day.
+Below, the average and the longest pause of the time range are shown.
+
+With flags --min-pause and --max-pause, only records with a total pause time in the given range are included.
+
+Reports for the current week if no start and end dates are given.`,
+		Aliases: []string{"pa"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTime, endTime, err := parseStartEnd(options)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			now := time.Now()
+			if startTime.IsZero() {
+				startTime = util.WeekStart(now, t.Config.FirstWeekday())
+			}
+			if endTime.IsZero() {
+				endTime = startTime.AddDate(0, 0, 7)
+			}
+			if !endTime.After(startTime) {
+				return fmt.Errorf("failed to generate report: end date must not be before start date")
+			}
+			if maxPause > 0 && maxPause < minPause {
+				return fmt.Errorf("failed to generate report: --max-pause must not be smaller than --min-pause")
+			}
+
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			filters, err := createFilters(t.Syntax(), options, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			needsRecords := filters.NeedsRecords
+			if minPause > 0 || maxPause > 0 {
+				filters.Functions = append(filters.Functions, core.FilterByPauseDuration(minPause, maxPause))
+				// Pauses are not in the report cache
+				needsRecords = true
+			}
+			filters = core.NewFilter(filters.Functions, startTime, endTime)
+			filters.NeedsRecords = needsRecords
+
+			reporter, err := core.NewReporter(
+				t, options.projects, filters,
+				options.includeArchived, startTime, endTime,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			warnRecordErrors(reporter.Errors)
+
+			stats := core.NewPauseStats(reporter.Records, startTime, endTime, now)
+			out.Print("%s", renderPauses(&stats, startTime, endTime, t.Config.Formatter()))
+			return nil
+		},
+	}
+	pauses.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00). Defaults to the start of the current week")
+	pauses.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00). Defaults to the end of the start date's week")
+	pauses.Flags().DurationVar(&minPause, "min-pause", 0, "Only include records with at least this total pause time, like 30m")
+	pauses.Flags().DurationVar(&maxPause, "max-pause", 0, "Only include records with at most this total pause time, like 1h")
+
+	return pauses
+}
+
+func renderPauses(stats *core.PauseStats, start, end time.Time, f util.Formatter) string {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "Pauses %s - %s\n\n", f.Date(start), f.Date(end.AddDate(0, 0, -1)))
+	fmt.Fprintf(&sb, "%-13s %6s %8s %8s\n", "", "pauses", "total", "longest")
+
+	for _, day := range stats.Days {
+		label := day.Date.Weekday().String()[:2] + " " + f.Date(day.Date)
+		fmt.Fprintf(&sb, "%-13s %6d %8s %8s\n", label, day.Count, f.Duration(day.Total), f.Duration(day.Longest))
+	}
+	longest := stats.Longest.End.Sub(stats.Longest.Start)
+	fmt.Fprintf(&sb, "%-13s %6d %8s %8s\n\n", "total", stats.Count, f.Duration(stats.Total), f.Duration(longest))
+
+	fmt.Fprintf(&sb, "%-13s %s\n", "Average", f.Duration(stats.Average()))
+	if stats.Count > 0 {
+		p := &stats.Longest
+		fmt.Fprintf(
+			&sb, "%-13s %s (%s - %s, %s)",
+			"Longest", f.Duration(longest), f.DateTime(p.Start), f.Time(p.End), stats.LongestRecord.Project,
+		)
+		if p.Note != "" {
+			fmt.Fprintf(&sb, " %s", p.Note)
+		}
+		fmt.Fprintln(&sb)
+	}
+	return sb.String()
+}
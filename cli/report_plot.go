@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+	"time"
+
+	gcolor "github.com/gookit/color"
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/render"
+	"github.com/mlange-42/track/render/chart"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func plotReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	var opt chart.Options
+	var png bool
+
+	kinds := make([]string, len(chart.Kinds))
+	for i, k := range chart.Kinds {
+		kinds[i] = string(k)
+	}
+
+	plot := &cobra.Command{
+		Use:   "plot (" + strings.Join(kinds, "|") + ")",
+		Short: "Generates charts of time tracking in SVG or PNG format",
+		Long: `Generates charts of time tracking in SVG or PNG format
+
+Chart types are:
+  bars       - stacked bars of the time per day and project
+  pie        - pie chart of the total time per project
+  cumulative - lines of the cumulative time per project, and in total
+
+The chart is written to stdout. Redirect it into a file, like
+
+  track report plot bars -s 2023-01-01 > chart.svg`,
+		Aliases:   []string{"g"},
+		Args:      util.WrappedArgs(cobra.ExactArgs(1)),
+		ValidArgs: kinds,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opt.Kind = chart.Kind(args[0])
+			valid := false
+			for _, k := range chart.Kinds {
+				valid = valid || k == opt.Kind
+			}
+			if !valid {
+				return fmt.Errorf("failed to generate report: invalid chart type '%s'", args[0])
+			}
+
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			filters, err := createFilters(t.Syntax(), options, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			startTime, endTime, err := parseStartEnd(options)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			reporter, err := core.NewReporter(
+				t, options.projects, filters,
+				options.includeArchived, startTime, endTime,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			warnRecordErrors(reporter.Errors)
+
+			data := plotData(reporter)
+			var renderer render.Renderer
+			if png {
+				renderer = chart.PngRenderer{Data: data, Options: opt}
+			} else {
+				renderer = chart.SvgRenderer{Data: data, Options: opt}
+			}
+			if err := renderer.Render(out.StdOut); err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			return nil
+		},
+	}
+	plot.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	plot.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+
+	plot.Flags().BoolVar(&png, "png", false, "Generate PNG instead of SVG output")
+	plot.Flags().IntVar(&opt.Width, "w", 800, "Width of output")
+	plot.Flags().IntVar(&opt.Height, "h", 400, "Height of output")
+	plot.Flags().StringVar(&opt.Title, "title", "", "Title of the chart")
+
+	return plot
+}
+
+// plotData collects the time per day and project from the reporter's records
+func plotData(r *core.Reporter) *chart.Data {
+	data := chart.Data{
+		Values: map[string][]time.Duration{},
+		Format: r.Track.Config.Formatter(),
+	}
+	if r.TimeRange.Start.IsZero() {
+		return &data
+	}
+
+	start := util.ToDate(r.TimeRange.Start)
+	end := start
+	for end.Before(r.TimeRange.End) {
+		data.Dates = append(data.Dates, end)
+		end = end.AddDate(0, 0, 1)
+	}
+
+	for i := range r.Records {
+		rec := &r.Records[i]
+		values, ok := data.Values[rec.Project]
+		if !ok {
+			values = make([]time.Duration, len(data.Dates))
+			data.Values[rec.Project] = values
+			data.Projects = append(data.Projects, rec.Project)
+		}
+		distributeRecord(rec, data.Dates, end, r.TimeRange, func(d int, dur time.Duration) {
+			values[d] += dur
+		})
+	}
+
+	totals := make(map[string]time.Duration, len(data.Projects))
+	for _, p := range data.Projects {
+		totals[p] = data.Total(p)
+	}
+	sort.Slice(data.Projects, func(i, j int) bool {
+		pi, pj := data.Projects[i], data.Projects[j]
+		if totals[pi] != totals[pj] {
+			return totals[pi] > totals[pj]
+		}
+		return pi < pj
+	})
+	data.Colors = projectColors(r.AllProjects, data.Projects)
+
+	return &data
+}
+
+// projectColors determines the chart colors of the given projects, from their background colors.
+// Projects with the default color 0 get colors from the chart palette.
+func projectColors(projects map[string]core.Project, names []string) map[string]color.RGBA {
+	colors := make(map[string]color.RGBA, len(names))
+	for i, p := range names {
+		if c := projects[p].Color; c != 0 {
+			rgb := gcolor.C256ToRgb(c)
+			colors[p] = color.RGBA{rgb[0], rgb[1], rgb[2], 255}
+		} else {
+			colors[p] = chart.Palette[i%len(chart.Palette)]
+		}
+	}
+	return colors
+}
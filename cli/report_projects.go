@@ -13,9 +13,24 @@ import (
 )
 
 func projectsReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	var vega bool
+	var shares bool
+	var bars bool
+	var root string
+	var depth int
+
 	projects := &cobra.Command{
-		Use:     "projects",
-		Short:   "Shows the project tree with time statistics",
+		Use:   "projects",
+		Short: "Shows the project tree with time statistics",
+		Long: `Shows the project tree with time statistics
+
+For each project, shows the total time including sub-projects, and the project's own time in parentheses.
+With flag --shares, also shows the share of the total time including sub-projects in the total time of the report.
+Flag --bars additionally shows the shares as bars.
+
+Flag --root shows only the subtree of the given project.
+Flag --depth limits the tree to the given number of levels below the root.
+The time of deeper projects is included in the own time of their ancestor at the last shown level.`,
 		Aliases: []string{"p"},
 		Args:    util.WrappedArgs(cobra.NoArgs),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -24,7 +39,7 @@ func projectsReportCommand(t *core.Track, options *filterOptions) *cobra.Command
 				return fmt.Errorf("failed to generate report: %s", err.Error())
 			}
 
-			filters, err := createFilters(options, projects, false)
+			filters, err := createFilters(t.Syntax(), options, projects, false)
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err.Error())
 			}
@@ -33,15 +48,26 @@ func projectsReportCommand(t *core.Track, options *filterOptions) *cobra.Command
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err.Error())
 			}
-			reporter, err := core.NewReporter(
+			reporter, err := newAggregateReporter(
 				t, options.projects, filters,
 				options.includeArchived, startTime, endTime,
 			)
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err.Error())
 			}
+			warnRecordErrors(reporter.Errors)
+			if err := reporter.Summarize(root, depth); err != nil {
+				return fmt.Errorf("failed to generate report: %s", err.Error())
+			}
 
-			tree, err := t.ToProjectTree(reporter.Projects)
+			if vega {
+				if err := vegaProjects(reporter).Render(out.StdOut); err != nil {
+					return fmt.Errorf("failed to generate report: %s", err.Error())
+				}
+				return nil
+			}
+
+			tree, err := reporter.Tree()
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err.Error())
 			}
@@ -53,6 +79,8 @@ func projectsReportCommand(t *core.Track, options *filterOptions) *cobra.Command
 			if rec != nil {
 				active = rec.Project
 			}
+			total := reporter.TotalTime[tree.Root.Value.Name]
+			format := t.Config.Formatter()
 			formatter := util.NewTreeFormatter(
 				func(t *core.ProjectNode, indent int) string {
 					fillLen := 16 - (indent + utf8.RuneCountInString(t.Value.Name))
@@ -73,20 +101,37 @@ func projectsReportCommand(t *core.Track, options *filterOptions) *cobra.Command
 					str += " "
 					str += t.Value.Render.Sprintf(" %s ", t.Value.Symbol)
 
-					return fmt.Sprintf(
+					str = fmt.Sprintf(
 						"%s %6s (%6s)", str,
-						util.FormatDuration(reporter.TotalTime[t.Value.Name], false),
-						util.FormatDuration(reporter.ProjectTime[t.Value.Name], false),
+						format.Duration(reporter.TotalTime[t.Value.Name], false),
+						format.Duration(reporter.ProjectTime[t.Value.Name], false),
 					)
+					if shares || bars {
+						share := 0.0
+						if total > 0 {
+							share = float64(reporter.TotalTime[t.Value.Name]) / float64(total)
+						}
+						str += fmt.Sprintf(" %5.1f%%", share*100)
+						if bar := util.Bar(share, 1, 20); bars && bar != "" {
+							str += " " + bar
+						}
+					}
+					return str
 				},
 				2,
 			)
-			out.Print(formatter.FormatTree(tree))
+			out.Print("%s", formatter.FormatTree(tree))
 			return nil
 		},
 	}
 	projects.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
 	projects.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
 
+	projects.Flags().StringVar(&root, "root", "", "Show only the subtree of the given project")
+	projects.Flags().IntVar(&depth, "depth", 0, "Maximum depth of the tree below the root. Unlimited if 0")
+	projects.Flags().BoolVar(&shares, "shares", false, "Show the share of each project in the total time")
+	projects.Flags().BoolVar(&bars, "bars", false, "Show the share of each project in the total time as bars")
+	projects.Flags().BoolVar(&vega, "vega", false, "Report as Vega-Lite JSON spec, with embedded data")
+
 	return projects
 }
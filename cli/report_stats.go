@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+)
+
+func statsReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	stats := &cobra.Command{
+		Use:   "stats",
+		Short: "Shows statistics of work patterns",
+		Long: `Shows statistics of work patterns
+
+Statistics are:
+  * Average time of day of the first start and the last end per day
+  * Longest period of work, without pauses or gaps between records
+  * Breaks, i.e. pauses and gaps between records on the same day
+  * Distribution of record durations per project, with percentiles p50 (median), p90 and p99
+  * Current and best streak of consecutive days with tracked time,
+    and of days meeting the daily goal set by config entry dailyGoal
+
+Average end times after midnight are shown as hours > 24.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			filters, err := createFilters(t.Syntax(), options, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			startTime, endTime, err := parseStartEnd(options)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			reporter, err := core.NewReporter(
+				t, options.projects, filters,
+				options.includeArchived, startTime, endTime,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			warnRecordErrors(reporter.Errors)
+
+			now := time.Now()
+			stats := core.NewWorkStats(reporter.Records, now)
+			out.Print("%s", renderWorkStats(&stats, t.Config.Formatter()))
+			if stats.Days > 0 {
+				absences, err := t.LoadAbsences()
+				if err != nil {
+					return fmt.Errorf("failed to generate report: %s", err)
+				}
+				daily := core.DailyTimes(reporter.Records, now)
+				out.Print("\n%s\n", formatStreaks(daily, &absences, t.Config.DailyGoal, now, t.Config.Formatter()))
+			}
+			return nil
+		},
+	}
+	stats.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	stats.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+
+	return stats
+}
+
+func renderWorkStats(stats *core.WorkStats, f util.Formatter) string {
+	sb := strings.Builder{}
+	if stats.Days == 0 {
+		fmt.Fprintln(&sb, "No records")
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "Days with records %7d\n", stats.Days)
+	fmt.Fprintf(&sb, "Average start     %7s\n", f.Duration(stats.AverageStart))
+	fmt.Fprintf(&sb, "Average end       %7s\n", f.Duration(stats.AverageEnd))
+	streak := stats.LongestStreak
+	fmt.Fprintf(
+		&sb, "Longest streak    %7s  (%s - %s)\n",
+		f.Duration(streak.End.Sub(streak.Start)), f.DateTime(streak.Start), f.Time(streak.End),
+	)
+	fmt.Fprintf(
+		&sb, "Breaks            %7d  median %s, mean %s\n",
+		stats.Breaks.Count, f.Duration(stats.Breaks.Median), f.Duration(stats.Breaks.Mean),
+	)
+
+	fmt.Fprintf(&sb, "\n%-16s %4s %7s %7s %7s %7s %7s", "Record durations", "n", "total", "mean", "p50", "p90", "p99")
+	for _, bin := range core.DurationBins {
+		fmt.Fprintf(&sb, " %6s", "<"+f.Duration(bin))
+	}
+	fmt.Fprintf(&sb, " %6s\n", ">"+f.Duration(core.DurationBins[len(core.DurationBins)-1]))
+
+	projects := maps.Keys(stats.Projects)
+	sort.Strings(projects)
+	for _, p := range projects {
+		dist := stats.Projects[p]
+		name := p
+		if utf8.RuneCountInString(name) > 16 {
+			name = string([]rune(name)[:15]) + "."
+		}
+		fmt.Fprintf(
+			&sb, "%-16s %4d %7s %7s %7s %7s %7s", name, dist.Count, f.Duration(dist.Total), f.Duration(dist.Mean),
+			f.Duration(dist.Median), f.Duration(dist.P90), f.Duration(dist.P99),
+		)
+		for _, count := range dist.Histogram {
+			fmt.Fprintf(&sb, " %6d", count)
+		}
+		fmt.Fprintln(&sb)
+	}
+	return sb.String()
+}
@@ -28,9 +28,15 @@ type tagValueStats struct {
 }
 
 func tagsReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	var vega bool
+
 	tagsReport := &cobra.Command{
-		Use:     "tags",
-		Short:   "Shows tags with time statistics",
+		Use:   "tags",
+		Short: "Shows tags with time statistics",
+		Long: `Shows tags with time statistics
+
+Hierarchical tags, like "+meeting/standup", are rolled up into their parent tags.
+I.e. the statistics of "meeting" include all records tagged with "meeting/standup".`,
 		Aliases: []string{"t"},
 		Args:    util.WrappedArgs(cobra.NoArgs),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -39,7 +45,7 @@ func tagsReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
 				return fmt.Errorf("failed to generate report: %s", err.Error())
 			}
 
-			filters, err := createFilters(options, projects, false)
+			filters, err := createFilters(t.Syntax(), options, projects, false)
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err.Error())
 			}
@@ -55,6 +61,7 @@ func tagsReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err.Error())
 			}
+			warnRecordErrors(reporter.Errors)
 
 			tags := map[string]bool{}
 			for _, tag := range options.tags {
@@ -71,7 +78,7 @@ func tagsReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
 			for _, rec := range reporter.Records {
 				dur := rec.Duration(util.NoTime, util.NoTime)
 				pause := rec.PauseDuration(util.NoTime, util.NoTime)
-				for tag, value := range rec.Tags {
+				for tag, value := range core.ExpandTagHierarchy(rec.Tags) {
 					if _, ok := tags[tag]; ok || len(tags) == 0 {
 						if _, ok := allTags[tag]; !ok {
 							allTags[tag] = &tagStats{
@@ -103,9 +110,18 @@ func tagsReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
 				}
 			}
 
+			if vega {
+				if err := vegaTags(allTags, valueStats).Render(out.StdOut); err != nil {
+					return fmt.Errorf("failed to generate report: %s", err.Error())
+				}
+				return nil
+			}
+
 			keys := maps.Keys(allTags)
 			sort.Strings(keys)
 
+			format := t.Config.Formatter()
+
 			for _, tag := range keys {
 				stats := allTags[tag]
 				fillLen := 15 - utf8.RuneCountInString(tag)
@@ -122,8 +138,8 @@ func tagsReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
 				out.Print(
 					"%s %3d  %6s (%5s)", str,
 					stats.Count,
-					util.FormatDuration(stats.Work, false),
-					util.FormatDuration(stats.Pause, false),
+					format.Duration(stats.Work, false),
+					format.Duration(stats.Pause, false),
 				)
 				if !valueStats {
 					values := stats.Values
@@ -159,8 +175,8 @@ func tagsReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
 						out.Print(
 							"  %s %3d  %6s (%5s)\n", str,
 							vStats.Count,
-							util.FormatDuration(vStats.Work, false),
-							util.FormatDuration(vStats.Pause, false),
+							format.Duration(vStats.Work, false),
+							format.Duration(vStats.Pause, false),
 						)
 					}
 				}
@@ -171,5 +187,7 @@ func tagsReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
 	tagsReport.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
 	tagsReport.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
 
+	tagsReport.Flags().BoolVar(&vega, "vega", false, "Report as Vega-Lite JSON spec, with embedded data")
+
 	return tagsReport
 }
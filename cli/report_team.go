@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+)
+
+func teamReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	team := &cobra.Command{
+		Use:   "team",
+		Short: "Shows time per project and team member",
+		Long: `Shows time per project and team member
+
+Combines the records of all team members, with one column per member.
+Team members are set by config entry team, which maps names to track directories,
+like on a shared network drive or repository. Team members' directories are only read.
+
+Projects are matched by their exact name. Time is excluding sub-projects.`,
+		Aliases: []string{"tm"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			members, err := t.OpenTeam()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			projects, err := teamProjects(t, members)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			// Archived projects are excluded per member, by the reporters
+			filterOpts := *options
+			filterOpts.includeArchived = true
+			filters, err := createFilters(t.Syntax(), &filterOpts, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			startTime, endTime, err := parseStartEnd(options)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			reporter, err := core.NewTeamReporter(
+				members, options.projects, filters,
+				options.includeArchived, startTime, endTime,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err)
+			}
+			out.Print("%s", renderTeam(reporter, t.Config.Formatter()))
+			return nil
+		},
+	}
+	team.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	team.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+
+	return team
+}
+
+// teamProjects returns the projects of the track and of all team members, by name.
+// Projects of the track take precedence over members' projects with the same name.
+func teamProjects(t *core.Track, members map[string]*core.Track) (map[string]core.Project, error) {
+	projects, err := t.LoadAllProjects()
+	if err != nil {
+		return nil, err
+	}
+	for name, member := range members {
+		memberProjects, err := member.LoadAllProjects()
+		if err != nil {
+			return nil, fmt.Errorf("team member '%s': %s", name, err)
+		}
+		for projName, p := range memberProjects {
+			if _, ok := projects[projName]; !ok {
+				projects[projName] = p
+			}
+		}
+	}
+	return projects, nil
+}
+
+func renderTeam(r *core.TeamReporter, f util.Formatter) string {
+	truncate := func(name string, width int) string {
+		if utf8.RuneCountInString(name) > width {
+			return string([]rune(name)[:width-1]) + "."
+		}
+		return name
+	}
+
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "%-16s", "project")
+	for _, m := range r.Members {
+		fmt.Fprintf(&sb, " %8s", truncate(m, 8))
+	}
+	fmt.Fprintf(&sb, " %8s\n", "total")
+
+	projects := maps.Keys(r.ProjectTime)
+	sort.Strings(projects)
+	for _, p := range projects {
+		perMember := r.ProjectTime[p]
+		fmt.Fprintf(&sb, "%-16s", truncate(p, 16))
+		var total time.Duration
+		for _, m := range r.Members {
+			fmt.Fprintf(&sb, " %8s", f.Duration(perMember[m]))
+			total += perMember[m]
+		}
+		fmt.Fprintf(&sb, " %8s\n", f.Duration(total))
+	}
+
+	fmt.Fprintf(&sb, "%-16s", "total")
+	for _, m := range r.Members {
+		fmt.Fprintf(&sb, " %8s", f.Duration(r.MemberTime[m]))
+	}
+	fmt.Fprintf(&sb, " %8s\n", f.Duration(r.Total()))
+	return sb.String()
+}
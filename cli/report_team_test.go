@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamReport(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	member, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(member.RootDir)
+
+	for i, name := range []string{"coding", "meetings"} {
+		project := core.NewProject(name, "", "t", []string{}, 15, 0)
+		if err := member.SaveProject(project, false); err != nil {
+			t.Fatal("error saving project")
+		}
+		record := core.Record{
+			Project: name,
+			Start:   util.DateTime(2001, 2, 3, 8+i, 0, 0),
+			End:     util.DateTime(2001, 2, 3, 8+i, 30, 0),
+		}
+		if err := member.SaveRecord(&record, false); err != nil {
+			t.Fatal("error saving record")
+		}
+	}
+	track.Config.Team = map[string]string{"alice": member.RootDir}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"report", "team", "--start", "2001-02-01", "--end", "2001-02-28", "--exclude-projects", "meetings"})
+
+	buffer := bytes.NewBufferString("")
+	out.StdOut = buffer
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	outStr, err := io.ReadAll(buffer)
+	if err != nil {
+		t.Fatal("error reading output")
+	}
+	assert.Contains(t, string(outStr), "coding", "included project missing")
+	assert.NotContains(t, string(outStr), "meetings", "excluded project in output")
+}
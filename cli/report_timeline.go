@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/mlange-42/track/core"
 	"github.com/mlange-42/track/out"
@@ -14,22 +15,45 @@ import (
 	"golang.org/x/exp/maps"
 )
 
-var timelineModes = map[string]func(*core.Reporter, bool, bool) string{
-	"days":   timelineDays,
-	"weeks":  timelineWeeks,
-	"months": timelineMonths,
-	"d":      timelineDays,
-	"w":      timelineWeeks,
-	"m":      timelineMonths,
+// timelineOutput is the output mode of timeline reports
+type timelineOutput uint8
+
+const (
+	timelineText timelineOutput = iota
+	timelineCsv
+	timelineCsvTable
+	timelineChart
+	timelineVega
+)
+
+var timelineModes = map[string]func(*core.Reporter, timelineOutput) string{
+	"days":     timelineDays,
+	"weeks":    timelineWeeks,
+	"months":   timelineMonths,
+	"quarters": timelineQuarters,
+	"years":    timelineYears,
+	"d":        timelineDays,
+	"w":        timelineWeeks,
+	"m":        timelineMonths,
+	"q":        timelineQuarters,
+	"y":        timelineYears,
 }
 
 func timelineReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
 	var csv bool
 	var table bool
+	var chart bool
+	var vega bool
 
 	timeline := &cobra.Command{
-		Use:     "timeline (days|weeks|months)",
-		Short:   "Timeline reports of time tracking",
+		Use:   "timeline (days|weeks|months|quarters|years)",
+		Short: "Timeline reports of time tracking",
+		Long: `Timeline reports of time tracking
+
+Quarters and years are fiscal quarters and years, starting with the month given by config entry fiscalYearStart.
+
+With flag --chart, the report shows a bar chart of the time per period, scaled to the terminal width,
+followed by the share of each project and a sparkline of its time per period.`,
 		Aliases: []string{"l"},
 		Args:    util.WrappedArgs(cobra.ExactArgs(1)),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -44,7 +68,7 @@ func timelineReportCommand(t *core.Track, options *filterOptions) *cobra.Command
 				return fmt.Errorf("failed to generate report: %s", err)
 			}
 
-			filters, err := createFilters(options, projects, false)
+			filters, err := createFilters(t.Syntax(), options, projects, false)
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err)
 			}
@@ -65,8 +89,20 @@ func timelineReportCommand(t *core.Track, options *filterOptions) *cobra.Command
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err)
 			}
-
-			out.Print(timelineFunc(reporter, csv, table))
+			warnRecordErrors(reporter.Errors)
+
+			output := timelineText
+			if csv {
+				output = timelineCsv
+				if table {
+					output = timelineCsvTable
+				}
+			} else if chart {
+				output = timelineChart
+			} else if vega {
+				output = timelineVega
+			}
+			out.Print("%s", timelineFunc(reporter, output))
 			return nil
 		},
 	}
@@ -75,66 +111,53 @@ func timelineReportCommand(t *core.Track, options *filterOptions) *cobra.Command
 
 	timeline.Flags().BoolVar(&csv, "csv", false, "Report in CSV format")
 	timeline.Flags().BoolVar(&table, "table", false, "For report in CSV format, reports one column per project")
+	timeline.Flags().BoolVar(&chart, "chart", false, "Report as bar chart, with the share and a sparkline per project")
+
+	timeline.Flags().BoolVar(&vega, "vega", false, "Report as Vega-Lite JSON spec, with embedded data")
+
+	timeline.MarkFlagsMutuallyExclusive("csv", "chart", "vega")
 
 	return timeline
 }
 
-func timelineDays(r *core.Reporter, csv bool, table bool) string {
+func timelineDays(r *core.Reporter, output timelineOutput) string {
 	startDate := util.ToDate(r.TimeRange.Start)
-	if table {
-		return timelineTable(r, startDate, time.Hour*24)
-	}
-	return timeline(r, startDate, time.Hour*24, 30*time.Minute, csv)
+	return timeline(r, startDate, time.Hour*24, 30*time.Minute, output)
 }
 
-func timelineWeeks(r *core.Reporter, csv bool, table bool) string {
-	startDate := util.ToDate(r.TimeRange.Start)
-	weekDay := (int(startDate.Weekday()) + 6) % 7
-	startDate = startDate.Add(time.Duration(-weekDay * 24 * int(time.Hour)))
-	if table {
-		return timelineTable(r, startDate, time.Hour*24*7)
-	}
-	return timeline(r, startDate, time.Hour*24*7, 2*time.Hour, csv)
+func timelineWeeks(r *core.Reporter, output timelineOutput) string {
+	startDate := util.WeekStart(util.ToDate(r.TimeRange.Start), r.Track.Config.FirstWeekday())
+	return timeline(r, startDate, time.Hour*24*7, 2*time.Hour, output)
 }
 
-func timelineMonths(r *core.Reporter, csv bool, table bool) string {
-	y1, m1, _ := r.TimeRange.Start.Date()
-	y2, m2, _ := r.TimeRange.End.Date()
-	numBins := (y2-y1)*12 + int(m2) - int(m1) + 1
+func timelineMonths(r *core.Reporter, output timelineOutput) string {
+	startDate := util.Date(r.TimeRange.Start.Year(), r.TimeRange.Start.Month(), 1)
+	return timelinePeriods(r, startDate, 1, 8*time.Hour, output)
+}
 
-	dates := make([]time.Time, numBins)
-	year, month := y1, m1
-	for i := range dates {
-		dates[i] = util.Date(year, month, 1)
-		month++
-		if month > 12 {
-			year++
-			month = 1
-		}
-	}
+func timelineQuarters(r *core.Reporter, output timelineOutput) string {
+	startDate := util.QuarterStart(r.TimeRange.Start, time.Month(r.Track.Config.FiscalYearStart))
+	return timelinePeriods(r, startDate, 3, 24*time.Hour, output)
+}
 
-	values := make([]time.Duration, numBins)
-	projectValues := make(map[string][]time.Duration)
-	for p := range r.Projects {
-		projectValues[p] = make([]time.Duration, numBins)
-	}
-	for _, rec := range r.Records {
-		y2, m2, _ := rec.Start.Date()
-		d := (y2-y1)*12 + int(m2) - int(m1)
-		dur := rec.Duration(r.TimeRange.Start, r.TimeRange.End)
-		values[d] += dur
-		projectValues[rec.Project][d] += dur
-	}
-	if table {
-		return renderTimelineTable(dates, values, projectValues)
-	}
-	if csv {
-		return renderTimelineCsv(dates, values)
+func timelineYears(r *core.Reporter, output timelineOutput) string {
+	startDate := util.FiscalYearStart(r.TimeRange.Start, time.Month(r.Track.Config.FiscalYearStart))
+	return timelinePeriods(r, startDate, 12, 80*time.Hour, output)
+}
+
+// timelinePeriods creates a timeline with bins of the given number of months
+func timelinePeriods(r *core.Reporter, startDate time.Time, months int, perBox time.Duration, output timelineOutput) string {
+	dates := []time.Time{}
+	binsEnd := startDate
+	for !binsEnd.After(r.TimeRange.End) {
+		dates = append(dates, binsEnd)
+		binsEnd = binsEnd.AddDate(0, months, 0)
 	}
-	return renderTimeline(dates, values, 8*time.Hour)
+	return renderTimelineOutput(r, dates, binsEnd, perBox, output)
 }
 
-func timeline(r *core.Reporter, startDate time.Time, delta time.Duration, perBox time.Duration, csv bool) string {
+// timeline creates a timeline with bins of a fixed duration
+func timeline(r *core.Reporter, startDate time.Time, delta time.Duration, perBox time.Duration, output timelineOutput) string {
 	minDate := startDate
 	maxDate := util.ToDate(r.TimeRange.End.Add(delta))
 	numBins := int(maxDate.Sub(minDate).Hours() / delta.Hours())
@@ -145,53 +168,78 @@ func timeline(r *core.Reporter, startDate time.Time, delta time.Duration, perBox
 		dates[i] = currDate
 		currDate = currDate.Add(delta)
 	}
+	return renderTimelineOutput(r, dates, currDate, perBox, output)
+}
 
-	values := make([]time.Duration, numBins)
-	for _, rec := range r.Records {
-		// TODO: split if over increment
-		d := int(rec.Start.Sub(minDate).Hours() / delta.Hours())
-		values[d] = values[d] + rec.Duration(r.TimeRange.Start, r.TimeRange.End)
+// renderTimelineOutput distributes the reporter's records to the bins, and renders them in the given output mode
+func renderTimelineOutput(r *core.Reporter, dates []time.Time, binsEnd time.Time, perBox time.Duration, output timelineOutput) string {
+	values := make([]time.Duration, len(dates))
+	projectValues := make(map[string][]time.Duration)
+	for p := range r.Projects {
+		projectValues[p] = make([]time.Duration, len(dates))
 	}
-	if csv {
-		return renderTimelineCsv(dates, values)
+	for i := range r.Records {
+		rec := &r.Records[i]
+		distributeRecord(rec, dates, binsEnd, r.TimeRange, func(d int, dur time.Duration) {
+			values[d] += dur
+			projectValues[rec.Project][d] += dur
+		})
 	}
-	return renderTimeline(dates, values, perBox)
-}
-
-func timelineTable(r *core.Reporter, startDate time.Time, delta time.Duration) string {
-	minDate := startDate
-	maxDate := util.ToDate(r.TimeRange.End.Add(delta))
-	numBins := int(maxDate.Sub(minDate).Hours() / delta.Hours())
 
-	dates := make([]time.Time, numBins)
-	currDate := minDate
-	for i := range dates {
-		dates[i] = currDate
-		currDate = currDate.Add(delta)
+	format := r.Track.Config.Formatter()
+	switch output {
+	case timelineCsvTable:
+		return renderTimelineTable(dates, values, projectValues, format)
+	case timelineCsv:
+		return renderTimelineCsv(dates, values, format)
+	case timelineChart:
+		return renderTimelineChart(r, dates, values, projectValues, format)
+	case timelineVega:
+		sb := strings.Builder{}
+		_ = vegaTimeline(r, dates, projectValues).Render(&sb)
+		return sb.String()
 	}
+	return renderTimeline(dates, values, perBox, format)
+}
 
-	values := make([]time.Duration, numBins)
-	projectValues := make(map[string][]time.Duration)
-	for p := range r.Projects {
-		projectValues[p] = make([]time.Duration, numBins)
+// distributeRecord calls fn with the record's duration in each bin it overlaps.
+// Bins start at the given dates, the last bin ends at binsEnd.
+// Durations are clipped to the given time range.
+func distributeRecord(rec *core.Record, dates []time.Time, binsEnd time.Time, tRange core.TimeRange, fn func(bin int, dur time.Duration)) {
+	first := sort.Search(len(dates), func(i int) bool {
+		return binEnd(dates, i, binsEnd).After(rec.Start)
+	})
+	for i := first; i < len(dates); i++ {
+		if rec.HasEnded() && !dates[i].Before(rec.End) {
+			break
+		}
+		start, end := dates[i], binEnd(dates, i, binsEnd)
+		if !tRange.Start.IsZero() && start.Before(tRange.Start) {
+			start = tRange.Start
+		}
+		if !tRange.End.IsZero() && end.After(tRange.End) {
+			end = tRange.End
+		}
+		if dur := rec.Duration(start, end); dur > 0 {
+			fn(i, dur)
+		}
 	}
+}
 
-	for _, rec := range r.Records {
-		// TODO: split if over increment
-		d := int(rec.Start.Sub(minDate).Hours() / delta.Hours())
-		dur := rec.Duration(r.TimeRange.Start, r.TimeRange.End)
-		values[d] += dur
-		projectValues[rec.Project][d] += dur
+// binEnd returns the end of the bin with the given index
+func binEnd(dates []time.Time, index int, binsEnd time.Time) time.Time {
+	if index+1 < len(dates) {
+		return dates[index+1]
 	}
-	return renderTimelineTable(dates, values, projectValues)
+	return binsEnd
 }
 
-func renderTimeline(dates []time.Time, values []time.Duration, perBox time.Duration) string {
+func renderTimeline(dates []time.Time, values []time.Duration, perBox time.Duration, f util.Formatter) string {
 	sb := strings.Builder{}
 	for i := range dates {
 		d := dates[i]
 		v := values[i]
-		fmt.Fprintf(&sb, "%s %s  %s  ", d.Weekday().String()[:2], d.Format(util.DateFormat), util.FormatDuration(v))
+		fmt.Fprintf(&sb, "%s %s  %s  ", d.Weekday().String()[:2], f.Date(d), f.Duration(v))
 
 		boxes := float64(v) / float64(perBox)
 		for i := 0; i < int(boxes); i++ {
@@ -209,20 +257,20 @@ func renderTimeline(dates []time.Time, values []time.Duration, perBox time.Durat
 	return sb.String()
 }
 
-func renderTimelineCsv(dates []time.Time, values []time.Duration) string {
+func renderTimelineCsv(dates []time.Time, values []time.Duration, f util.Formatter) string {
 	sb := strings.Builder{}
 
 	fmt.Fprintf(&sb, "date,weekday,duration\n")
 	for i := range dates {
 		d := dates[i]
 		v := values[i]
-		fmt.Fprintf(&sb, "%s,%s,%s\n", d.Format(util.DateFormat), d.Weekday().String()[:2], util.FormatDuration(v))
+		fmt.Fprintf(&sb, "%s,%s,%s\n", f.Date(d), d.Weekday().String()[:2], f.Duration(v))
 	}
 
 	return sb.String()
 }
 
-func renderTimelineTable(dates []time.Time, values []time.Duration, projectValues map[string][]time.Duration) string {
+func renderTimelineTable(dates []time.Time, values []time.Duration, projectValues map[string][]time.Duration, f util.Formatter) string {
 	sb := strings.Builder{}
 
 	projects := maps.Keys(projectValues)
@@ -232,13 +280,88 @@ func renderTimelineTable(dates []time.Time, values []time.Duration, projectValue
 	for i := range dates {
 		d := dates[i]
 		v := values[i]
-		fmt.Fprintf(&sb, "%s,%s,%s", d.Format(util.DateFormat), d.Weekday().String()[:2], util.FormatDuration(v))
+		fmt.Fprintf(&sb, "%s,%s,%s", f.Date(d), d.Weekday().String()[:2], f.Duration(v))
 		for _, p := range projects {
 			vp := projectValues[p][i]
-			fmt.Fprintf(&sb, ",%s", util.FormatDuration(vp))
+			fmt.Fprintf(&sb, ",%s", f.Duration(vp))
 		}
 		fmt.Fprintf(&sb, "\n")
 	}
 
 	return sb.String()
 }
+
+// renderTimelineChart renders a bar chart of the time per period, scaled to the terminal width,
+// followed by the share of each project and a sparkline of its time per period
+func renderTimelineChart(r *core.Reporter, dates []time.Time, values []time.Duration, projectValues map[string][]time.Duration, f util.Formatter) string {
+	width := 80
+	if w, _, err := util.TerminalSize(); err == nil && w > 0 {
+		width = w
+	}
+
+	sb := strings.Builder{}
+
+	labels := make([]string, len(dates))
+	labelWidth := 0
+	var max, total time.Duration
+	for i, d := range dates {
+		labels[i] = fmt.Sprintf("%s %s  %s", d.Weekday().String()[:2], f.Date(d), f.Duration(values[i]))
+		if w := utf8.RuneCountInString(labels[i]); w > labelWidth {
+			labelWidth = w
+		}
+		if values[i] > max {
+			max = values[i]
+		}
+		total += values[i]
+	}
+	barWidth := width - labelWidth - 3
+	for i := range dates {
+		fmt.Fprintf(&sb, "%s%s  %s\n",
+			labels[i], strings.Repeat(" ", labelWidth-utf8.RuneCountInString(labels[i])),
+			util.Bar(float64(values[i]), float64(max), barWidth))
+	}
+
+	projects := maps.Keys(projectValues)
+	sort.Slice(projects, func(i, j int) bool {
+		ti, tj := r.ProjectTime[projects[i]], r.ProjectTime[projects[j]]
+		if ti != tj {
+			return ti > tj
+		}
+		return projects[i] < projects[j]
+	})
+
+	// name, symbol, duration, share and spaces between
+	shareWidth := width - 16 - 4 - 7 - 7 - len(dates) - 5
+	if shareWidth < 10 {
+		shareWidth = 10
+	}
+
+	fmt.Fprint(&sb, "\n")
+	for _, p := range projects {
+		projTotal := time.Duration(0)
+		spark := make([]float64, len(dates))
+		for i, v := range projectValues[p] {
+			projTotal += v
+			spark[i] = float64(v)
+		}
+		if projTotal == 0 {
+			continue
+		}
+		share := 0.0
+		if total > 0 {
+			share = float64(projTotal) / float64(total)
+		}
+		project := r.Projects[p]
+		name := p
+		if utf8.RuneCountInString(name) > 16 {
+			name = string([]rune(name)[:15]) + "."
+		}
+		bar := util.Bar(share, 1, shareWidth)
+		fmt.Fprintf(&sb, "%-16s %s %6s %5.1f%%  %s%s  %s\n",
+			name, project.Render.Sprintf(" %s ", project.Symbol), f.Duration(projTotal), share*100,
+			bar, strings.Repeat(" ", shareWidth-utf8.RuneCountInString(bar)),
+			util.Sparkline(spark))
+	}
+
+	return sb.String()
+}
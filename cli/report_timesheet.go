@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/render/timesheet"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func timesheetReportCommand(t *core.Track, options *filterOptions) *cobra.Command {
+	var title string
+	var name string
+	var submit bool
+
+	timesheetCom := &cobra.Command{
+		Use:   "timesheet",
+		Short: "Generates a timesheet with one row per day in PDF format",
+		Long: `Generates a timesheet with one row per day in PDF format
+
+The timesheet contains a table with first start, last end, pause and work time per day,
+totals for the period, and a signature line.
+
+Reports for the current month if no start and end dates are given.
+
+With flag --submit, all records of the timesheet are locked after generating it,
+so that they can't be changed or deleted.`,
+		Aliases: []string{"s"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err.Error())
+			}
+
+			filters, err := createFilters(t.Syntax(), options, projects, false)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err.Error())
+			}
+
+			startTime, endTime, err := parseStartEnd(options)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err.Error())
+			}
+			today := util.ToDate(time.Now())
+			if startTime.IsZero() {
+				startTime = util.Date(today.Year(), today.Month(), 1)
+			}
+			if endTime.IsZero() {
+				endTime = util.Date(startTime.Year(), startTime.Month()+1, 1)
+			}
+			if !endTime.After(startTime) {
+				return fmt.Errorf("failed to generate report: end date must not be before start date")
+			}
+			filters = core.NewFilter(filters.Functions, startTime, endTime)
+
+			reporter, err := core.NewReporter(
+				t, options.projects, filters,
+				options.includeArchived, startTime, endTime,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err.Error())
+			}
+			warnRecordErrors(reporter.Errors)
+
+			renderer := timesheet.PdfRenderer{
+				Reporter:  reporter,
+				StartDate: startTime,
+				EndDate:   endTime,
+				Title:     title,
+				Name:      name,
+			}
+			err = renderer.Render(out.StdOut)
+			if err != nil {
+				return fmt.Errorf("failed to generate report: %s", err.Error())
+			}
+
+			if submit {
+				records, err := t.LoadAllRecordsFiltered(filters)
+				if err != nil {
+					return fmt.Errorf("failed to lock records: %s", err.Error())
+				}
+				locked, err := t.SetRecordsLocked(records, true)
+				if err != nil {
+					return fmt.Errorf("failed to lock records: %s", err.Error())
+				}
+				// Not to stdout, which holds the PDF
+				fmt.Fprintf(out.StdErr, "Locked %d record(s)\n", locked)
+			}
+			return nil
+		},
+	}
+	timesheetCom.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00). Defaults to the start of the current month")
+	timesheetCom.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00). Defaults to the end of the start date's month")
+
+	timesheetCom.Flags().StringVar(&title, "title", "Timesheet", "Title of the timesheet")
+	timesheetCom.Flags().StringVar(&name, "name", "", "Name of the person the timesheet is for")
+	timesheetCom.Flags().BoolVar(&submit, "submit", false, "Submit the timesheet: lock all its records")
+
+	return timesheetCom
+}
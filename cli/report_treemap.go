@@ -26,7 +26,7 @@ func treemapReportCommand(t *core.Track, options *filterOptions) *cobra.Command
 				return fmt.Errorf("failed to generate report: %s", err.Error())
 			}
 
-			filters, err := createFilters(options, projects, false)
+			filters, err := createFilters(t.Syntax(), options, projects, false)
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err.Error())
 			}
@@ -35,13 +35,14 @@ func treemapReportCommand(t *core.Track, options *filterOptions) *cobra.Command
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err.Error())
 			}
-			reporter, err := core.NewReporter(
+			reporter, err := newAggregateReporter(
 				t, options.projects, filters,
 				options.includeArchived, startTime, endTime,
 			)
 			if err != nil {
 				return fmt.Errorf("failed to generate report: %s", err.Error())
 			}
+			warnRecordErrors(reporter.Errors)
 
 			var renderer render.Renderer
 			if csv {
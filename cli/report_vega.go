@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/render/vegalite"
+	"github.com/mlange-42/track/util"
+	"golang.org/x/exp/maps"
+)
+
+// vegaProjects creates a Vega-Lite bar chart of the time per project
+func vegaProjects(r *core.Reporter) *vegalite.Spec {
+	names := maps.Keys(r.Projects)
+	sort.Strings(names)
+
+	rows := make([]vegalite.Row, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, vegalite.Row{
+			"project":    name,
+			"parent":     r.Projects[name].Parent,
+			"hours":      vegalite.Hours(r.ProjectTime[name]),
+			"totalHours": vegalite.Hours(r.TotalTime[name]),
+		})
+	}
+
+	spec := vegalite.New("Time per project", "bar", rows)
+	spec.Encoding = vegalite.Encoding{
+		X: &vegalite.Field{Field: "hours", Type: vegalite.Quantitative, Title: "Hours"},
+		Y: &vegalite.Field{Field: "project", Type: vegalite.Nominal, Title: "Project", Sort: "-x"},
+		Color: &vegalite.Field{
+			Field: "project", Type: vegalite.Nominal, Title: "Project",
+			Scale: vegalite.ColorScale(projectColors(r.AllProjects, names)),
+		},
+		Tooltip: []vegalite.Field{
+			{Field: "project", Type: vegalite.Nominal, Title: "Project"},
+			{Field: "parent", Type: vegalite.Nominal, Title: "Parent"},
+			{Field: "hours", Type: vegalite.Quantitative, Title: "Hours"},
+			{Field: "totalHours", Type: vegalite.Quantitative, Title: "Hours incl. children"},
+		},
+	}
+	return spec
+}
+
+// vegaTags creates a Vega-Lite bar chart of the time per tag, or per value of a single tag
+func vegaTags(tags map[string]*tagStats, perValue bool) *vegalite.Spec {
+	keys := maps.Keys(tags)
+	sort.Strings(keys)
+
+	rows := []vegalite.Row{}
+	for _, tag := range keys {
+		stats := tags[tag]
+		if !perValue {
+			rows = append(rows, vegalite.Row{
+				"tag":        tag,
+				"records":    stats.Count,
+				"hours":      vegalite.Hours(stats.Work),
+				"pauseHours": vegalite.Hours(stats.Pause),
+			})
+			continue
+		}
+		values := maps.Keys(stats.Values)
+		sort.Strings(values)
+		for _, v := range values {
+			vStats := stats.Values[v]
+			rows = append(rows, vegalite.Row{
+				"tag":        tag,
+				"value":      v,
+				"records":    vStats.Count,
+				"hours":      vegalite.Hours(vStats.Work),
+				"pauseHours": vegalite.Hours(vStats.Pause),
+			})
+		}
+	}
+
+	category, title := "tag", "Tag"
+	if perValue {
+		category, title = "value", "Value"
+	}
+	spec := vegalite.New("Time per "+category, "bar", rows)
+	spec.Encoding = vegalite.Encoding{
+		X: &vegalite.Field{Field: "hours", Type: vegalite.Quantitative, Title: "Hours"},
+		Y: &vegalite.Field{Field: category, Type: vegalite.Nominal, Title: title, Sort: "-x"},
+		Tooltip: []vegalite.Field{
+			{Field: category, Type: vegalite.Nominal, Title: title},
+			{Field: "records", Type: vegalite.Quantitative, Title: "Records"},
+			{Field: "hours", Type: vegalite.Quantitative, Title: "Hours"},
+			{Field: "pauseHours", Type: vegalite.Quantitative, Title: "Pause hours"},
+		},
+	}
+	return spec
+}
+
+// vegaTimeline creates a Vega-Lite stacked bar chart of the time per period and project
+func vegaTimeline(r *core.Reporter, dates []time.Time, projectValues map[string][]time.Duration) *vegalite.Spec {
+	names := maps.Keys(projectValues)
+	sort.Strings(names)
+
+	rows := []vegalite.Row{}
+	for i, date := range dates {
+		for _, name := range names {
+			dur := projectValues[name][i]
+			if dur <= 0 {
+				continue
+			}
+			rows = append(rows, vegalite.Row{
+				"period":  date.Format(util.DateFormat),
+				"project": name,
+				"hours":   vegalite.Hours(dur),
+			})
+		}
+	}
+
+	spec := vegalite.New("Time per period", "bar", rows)
+	spec.Encoding = vegalite.Encoding{
+		X: &vegalite.Field{Field: "period", Type: vegalite.Ordinal, Title: "Period start"},
+		Y: &vegalite.Field{Field: "hours", Type: vegalite.Quantitative, Title: "Hours", Aggregate: "sum"},
+		Color: &vegalite.Field{
+			Field: "project", Type: vegalite.Nominal, Title: "Project",
+			Scale: vegalite.ColorScale(projectColors(r.AllProjects, names)),
+		},
+		Tooltip: []vegalite.Field{
+			{Field: "period", Type: vegalite.Ordinal, Title: "Period start"},
+			{Field: "project", Type: vegalite.Nominal, Title: "Project"},
+			{Field: "hours", Type: vegalite.Quantitative, Title: "Hours"},
+		},
+	}
+	return spec
+}
@@ -22,10 +22,25 @@ func resumeCommand(t *core.Track) *cobra.Command {
 		Short: "Resume a paused or stopped project",
 		Long: `Resume a paused or stopped project
 
-The note argument provides a note for the pause when resuming a stopped record`,
+The note argument provides a note for the pause when resuming a stopped record.
+
+If the record was paused for longer than 'maxPauseDuration' from the config,
+it is stopped at the start of the pause instead. Use --last to resume it anyway.`,
 		Aliases: []string{"re"},
 		Args:    util.WrappedArgs(cobra.ArbitraryArgs),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			stopped, err := t.StopLongPause(time.Now())
+			if err != nil {
+				return fmt.Errorf("failed to resume: %s", err.Error())
+			}
+			if stopped != nil && !useLast {
+				out.Warn(
+					"Stopped record in '%s' at %s, as it was paused for more than %s. To resume it anyway, use --last\n",
+					stopped.Project, stopped.End.Format(util.DateTimeFormat), util.FormatDuration(t.Config.MaxPauseDuration, false),
+				)
+				return nil
+			}
+
 			open, err := t.OpenRecord()
 			if err != nil {
 				return fmt.Errorf("failed to resume: %s", err.Error())
@@ -46,6 +61,7 @@ The note argument provides a note for the pause when resuming a stopped record`,
 				if skip {
 					skipped = fmt.Sprintf(" (skipped %s pause)", util.FormatDuration(pause))
 				}
+				t.EmitEvent(core.Event{Type: core.EventResume, Time: time.Now(), Record: *open})
 				out.Success("Resume record in '%s'%s", open.Project, skipped)
 				return nil
 			}
@@ -69,6 +85,7 @@ The note argument provides a note for the pause when resuming a stopped record`,
 			if skip {
 				skipped = fmt.Sprintf(" (skipped %s pause)", util.FormatDuration(pause))
 			}
+			t.EmitEvent(core.Event{Type: core.EventResume, Time: time.Now(), Record: *last})
 			out.Success("Resume record in '%s'%s", last.Project, skipped)
 			return nil
 		},
@@ -110,9 +127,6 @@ func resumeOpenRecord(t *core.Track, open *core.Record, atTime string, ago time.
 func resumeLastRecord(t *core.Track, last *core.Record, args []string, atTime string, ago time.Duration, skip bool) (time.Duration, error) {
 	project := last.Project
 
-	if !t.ProjectExists(project) {
-		return 0, fmt.Errorf("project '%s' does not exist", project)
-	}
 	proj, err := t.LoadProject(project)
 	if err != nil {
 		return 0, err
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func retentionCommand(t *core.Track) *cobra.Command {
+	var dryRun bool
+
+	retention := &cobra.Command{
+		Use:   "retention",
+		Short: "Delete and anonymize old records according to the retention rules",
+		Long: `Delete and anonymize old records according to the retention rules
+
+Retention rules are set by config entry retention, with ages in months:
+  * deleteAfter:     delete records permanently
+  * anonymizeAfter:  remove notes, tags, links and pause notes
+  * stripNotesAfter: reduce notes to their tags, and remove link and pause notes
+
+Deleted records are not moved to the trash.
+The history of changed records is deleted, as it contains the original data.
+Locked records are left unchanged.
+
+A running daemon applies the retention rules once per day.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !t.Config.Retention.IsEnabled() {
+				return fmt.Errorf("failed to apply retention rules: no rules. Set config entry retention")
+			}
+			result, err := t.ApplyRetention(time.Now(), dryRun)
+			if err != nil {
+				return fmt.Errorf("failed to apply retention rules: %s", err)
+			}
+			if result.Locked > 0 {
+				out.Warn("Left %d locked record(s) unchanged\n", result.Locked)
+			}
+			dry := ""
+			if dryRun {
+				dry = " - dry-run"
+			}
+			out.Success(
+				"Applied retention rules: %d record(s) deleted, %d anonymized, %d with stripped notes%s",
+				result.Deleted, result.Anonymized, result.Stripped, dry,
+			)
+			return nil
+		},
+	}
+	retention.Flags().BoolVar(&dryRun, "dry", false, "Dry run: do not actually change any files")
+
+	return retention
+}
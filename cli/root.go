@@ -2,11 +2,15 @@ package cli
 
 import (
 	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/integration"
+	"github.com/mlange-42/track/out"
 	"github.com/spf13/cobra"
 )
 
 // RootCommand sets up the CLI
 func RootCommand(t *core.Track, version string) *cobra.Command {
+	var readOnly bool
+
 	root := &cobra.Command{
 		Use:   "track",
 		Short: "Track is a time tracking command line tool",
@@ -36,6 +40,10 @@ Subcommands
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if readOnly {
+				t.ReadOnly = true
+			}
+			registerEventHooks(t)
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
@@ -43,6 +51,8 @@ Subcommands
 		},
 	}
 
+	root.PersistentFlags().BoolVar(&readOnly, "read-only", false, "Read-only mode: reject all changes to records, projects and config")
+
 	root.AddCommand(statusCommand(t))
 	root.AddCommand(listCommand(t))
 	root.AddCommand(createCommand(t))
@@ -55,10 +65,47 @@ Subcommands
 	root.AddCommand(editCommand(t))
 	root.AddCommand(deleteCommand(t))
 	root.AddCommand(exportCommand(t))
+	root.AddCommand(importCommand(t))
 	root.AddCommand(workspaceCommand(t))
 	root.AddCommand(moveCommand(t))
+	root.AddCommand(daemonCommand(t))
+	root.AddCommand(searchCommand(t))
+	root.AddCommand(linkCommand(t))
+	root.AddCommand(migrateCommand(t))
+	root.AddCommand(verifyCommand(t))
+	root.AddCommand(doctorCommand(t))
+	root.AddCommand(usageCommand(t))
+	root.AddCommand(trashCommand(t))
+	root.AddCommand(historyCommand(t))
+	root.AddCommand(conflictsCommand(t))
+	root.AddCommand(syncCommand(t))
+	root.AddCommand(serveCommand(t))
+	root.AddCommand(mergeCommand(t))
+	root.AddCommand(purgeCommand(t))
+	root.AddCommand(retentionCommand(t))
+	root.AddCommand(pushCommand(t))
+	root.AddCommand(invoiceCommand(t))
+	root.AddCommand(lockCommand(t))
+	root.AddCommand(unlockCommand(t))
 
 	root.Long += "\n\n" + formatCmdTree(root)
 
 	return root
 }
+
+// registerEventHooks registers the event handlers of configured integrations as event hooks.
+// Failures are only reported as warnings, as they must not prevent tracking.
+func registerEventHooks(t *core.Track) {
+	handlers, err := integration.EventHandlers(t)
+	if err != nil {
+		out.Warn("failed to set up integrations: %s\n", err)
+	}
+	for _, h := range handlers {
+		h := h
+		t.SetEventHook(h.Name(), func(e core.Event) {
+			if err := h.HandleEvent(e); err != nil {
+				out.Warn("%s integration: %s\n", h.Name(), err)
+			}
+		})
+	}
+}
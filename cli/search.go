@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gookit/color"
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func searchCommand(t *core.Track) *cobra.Command {
+	options := filterOptions{}
+	var limit int
+
+	search := &cobra.Command{
+		Use:   "search QUERY...",
+		Short: "Search records by words in their notes",
+		Long: `Search records by words in their notes
+
+Finds records that contain all words of the query in their note or pause notes.
+Search is case-insensitive, and words match as prefixes (e.g. "meet" matches "meeting").
+Results are ranked by the number of matches, latest records first for equal rank.
+
+For each result, the record's date, time and project are shown, followed by the matching lines.`,
+		Args: util.WrappedArgs(cobra.MinimumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projects, err := t.LoadAllProjects()
+			if err != nil {
+				return fmt.Errorf("failed to search records: %s", err)
+			}
+
+			filters, err := createFilters(t.Syntax(), &options, projects, true)
+			if err != nil {
+				return fmt.Errorf("failed to search records: %s", err)
+			}
+
+			results, err := t.SearchRecords(strings.Join(args, " "), filters, limit)
+			if err != nil {
+				return fmt.Errorf("failed to search records: %s", err)
+			}
+			if len(results) == 0 {
+				out.Warn("no records found")
+				return nil
+			}
+
+			for _, res := range results {
+				printSearchResult(&res, projects[res.Record.Project])
+			}
+			return nil
+		},
+	}
+
+	search.Flags().StringSliceVarP(&options.projects, "projects", "p", []string{}, "Projects to include (comma-separated). All projects if not specified")
+	search.Flags().StringSliceVarP(&options.tags, "tags", "t", []string{}, "Tags to include (comma-separated). Includes records with any of the given tags")
+	_ = search.RegisterFlagCompletionFunc("tags", completeTags(t))
+	search.Flags().StringVarP(&options.start, "start", "s", "", "Start date (start at 00:00)")
+	search.Flags().StringVarP(&options.end, "end", "e", "", "End date (inclusive: end at 24:00)")
+	search.Flags().BoolVarP(&options.includeArchived, "archived", "a", false, "Include records from archived projects")
+	search.Flags().IntVarP(&limit, "limit", "n", 0, "Maximum number of results. All results if not specified")
+
+	return search
+}
+
+func printSearchResult(res *core.SearchResult, project core.Project) {
+	r := &res.Record
+	end := util.NoTimeString
+	if r.HasEnded() {
+		end = r.End.Format(util.TimeFormat)
+	}
+	out.Print(
+		"%s %s %s - %s %s\n",
+		project.Render.Sprintf(" %s ", project.Symbol),
+		r.Start.Format(util.DateFormat), r.Start.Format(util.TimeFormat), end,
+		color.Bold.Sprintf("%s", r.Project),
+	)
+	for _, line := range res.Lines {
+		out.Print("    %s\n", line)
+	}
+}
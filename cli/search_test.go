@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	err = track.SaveProject(project, false)
+	if err != nil {
+		t.Fatal("error saving project")
+	}
+
+	record := core.Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+		Note:    "Planning\nTeam meeting",
+	}
+	err = track.SaveRecord(&record, false)
+	if err != nil {
+		t.Fatal("error saving record")
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"search", "meeting"})
+
+	buffer := bytes.NewBufferString("")
+	out.StdOut = buffer
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	outStr, err := io.ReadAll(buffer)
+	if err != nil {
+		t.Fatal("error reading output")
+	}
+	assert.Contains(t, string(outStr), "2001-02-03 04:05 - 05:05", "date context missing")
+	assert.Contains(t, string(outStr), "    Team meeting\n", "matching line missing")
+	assert.NotContains(t, string(outStr), "Planning", "non-matching line in output")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"search"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for missing query")
+}
+
+func TestSearchAlias(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	cmd, _, err := RootCommand(track, "").Find([]string{"s"})
+	assert.Nil(t, err)
+	assert.Equal(t, "status", cmd.Name(), "Shortcut 's' should stay with status")
+}
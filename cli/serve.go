@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/mlange-42/track/web"
+	"github.com/spf13/cobra"
+)
+
+const webTokenEnvVar = "TRACK_WEB_TOKEN"
+
+func serveCommand(t *core.Track) *cobra.Command {
+	var addr string
+	var token string
+	var origins []string
+	var openAPI bool
+
+	serve := &cobra.Command{
+		Use:   "serve",
+		Short: "Run a web UI and API for the current workspace",
+		Long: `Run a web UI and API for the current workspace
+
+Serves a web UI for viewing reports and starting and stopping records from a browser,
+and the underlying JSON API below path ` + web.APIPath + `.
+The server runs until it is interrupted.
+
+API requests must provide a token as bearer token,
+given by flag --token or by environment variable ` + webTokenEnvVar + `.
+The token is entered in the web UI, and stored by the browser.
+
+By default, the server only listens on the local machine.
+To make it accessible from other devices, give an address like ":8422" by flag --addr.
+A token is required for such addresses.
+
+Cross-origin requests to the API are allowed from origins given by flag --allow-origin.
+Requests from other origins are rejected, and POST requests must have content type application/json.
+
+The OpenAPI document of the API is served without authentication at ` + web.APIPath + web.OpenAPIFile + `,
+for generating client SDKs. With flag --openapi, it is printed instead of running the server.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if openAPI {
+				data, err := json.MarshalIndent(web.OpenAPI(), "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to create OpenAPI document: %s", err)
+				}
+				out.Print("%s\n", data)
+				return nil
+			}
+			if token == "" {
+				token = os.Getenv(webTokenEnvVar)
+			}
+			if token == "" {
+				if !isLoopbackAddr(addr) {
+					return fmt.Errorf("failed to run web server: a token is required for address '%s', which is not restricted to the local machine", addr)
+				}
+				out.Warn("Running web server without a token\n")
+			}
+			srv := &http.Server{
+				Addr:              addr,
+				Handler:           web.NewServer(t, web.Options{Token: token, AllowedOrigins: origins}),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt)
+			go func() {
+				<-signals
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = srv.Shutdown(ctx)
+			}()
+
+			out.Success("Started web server for workspace '%s' on %s\n", t.Workspace(), addr)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to run web server: %s", err)
+			}
+			return nil
+		},
+	}
+
+	serve.Flags().StringVarP(&addr, "addr", "a", "127.0.0.1:8422", "Address to listen on. Use e.g. ':8422' for all interfaces, which requires a token")
+	serve.Flags().StringVar(&token, "token", "", "Token for authentication of API requests")
+	serve.Flags().StringSliceVar(&origins, "allow-origin", []string{}, "Origins allowed for cross-origin requests (comma-separated), like http://localhost:3000. '*' for all")
+	serve.Flags().BoolVar(&openAPI, "openapi", false, "Print the OpenAPI document of the API and exit")
+
+	return serve
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -15,31 +16,50 @@ func startCommand(t *core.Track) *cobra.Command {
 	var copy bool
 	var atTime string
 	var ago time.Duration
+	var category string
 
 	start := &cobra.Command{
-		Use:   "start PROJECT [NOTE...]",
+		Use:   "start [PROJECT [NOTE...]]",
 		Short: "Start a record for a project",
 		Long: fmt.Sprintf(`Start a record for a project
 		
 Everything after the project name is considered a note for the record.
-Notes can contain tags, denoted by the prefix "%s", like "%stag"`, core.TagPrefix, core.TagPrefix),
+Notes can contain tags, denoted by the prefix "%s", like "%stag"
+
+Without arguments, the project and tags are taken from the nearest file '%s'
+in the current directory or its parents, like:
+
+  project: MyProject
+  tags: [dev, client=acme]`, t.Config.TagPrefix, t.Config.TagPrefix, core.DirectoryConfigFile),
 		Aliases: []string{"+"},
-		Args:    util.WrappedArgs(cobra.MinimumNArgs(1)),
+		Args:    util.WrappedArgs(cobra.ArbitraryArgs),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			project := args[0]
-
-			if !t.ProjectExists(project) {
-				return fmt.Errorf("failed to start record: project '%s' does not exist", project)
+			if err := t.Config.CheckCategory(category); err != nil {
+				return fmt.Errorf("failed to start record: %s", err)
 			}
-
-			if copy && len(args) > 1 {
-				return fmt.Errorf("failed to start record: can't use note arguments with flag --copy")
+			projectName, noteArgs := "", []string{}
+			if len(args) > 0 {
+				projectName, noteArgs = args[0], args[1:]
+			} else {
+				dirConf, err := detectProject()
+				if err != nil {
+					return fmt.Errorf("failed to start record: %s", err)
+				}
+				projectName = dirConf.Project
+				if !copy {
+					noteArgs = strings.Fields(dirConf.Note(t.Config.TagPrefix))
+				}
 			}
-
-			proj, err := t.LoadProject(project)
+			proj, err := t.ResolveProject(projectName)
 			if err != nil {
 				return fmt.Errorf("failed to start record: %s", err)
 			}
+			project := proj.Name
+
+			if copy && len(noteArgs) > 0 {
+				return fmt.Errorf("failed to start record: can't use note arguments with flag --copy")
+			}
+
 			if proj.Archived {
 				return fmt.Errorf("failed to start record: project '%s' is archived", proj.Name)
 			}
@@ -81,18 +101,23 @@ Notes can contain tags, denoted by the prefix "%s", like "%stag"`, core.TagPrefi
 				if latest != nil {
 					note = latest.Note
 					tags = latest.Tags
+					proj.Category = latest.Category
 				} else {
 					return fmt.Errorf("failed to create record with copy: no previous record in '%s'", project)
 				}
 			} else {
-				note = strings.Join(args[1:], " ")
-				tags, err = core.ExtractTagsSlice(args[1:])
+				note = strings.Join(noteArgs, " ")
+				tags, err = t.Syntax().ExtractTagsSlice(noteArgs)
 				if err != nil {
 					return fmt.Errorf("failed to create record: %s", err.Error())
 				}
 			}
 
-			record, err := t.StartRecord(&proj, note, tags, startTime)
+			if category != "" {
+				proj.Category = category
+			}
+
+			record, err := startRecord(t, &proj, note, tags, startTime)
 			if err != nil {
 				return fmt.Errorf("failed to create record: %s", err.Error())
 			}
@@ -102,7 +127,8 @@ Notes can contain tags, denoted by the prefix "%s", like "%stag"`, core.TagPrefi
 		},
 	}
 
-	start.Flags().BoolVarP(&copy, "copy", "c", false, "Copy note and tags from the last record of the project.")
+	start.Flags().BoolVarP(&copy, "copy", "c", false, "Copy note, tags and category from the last record of the project.")
+	start.Flags().StringVar(&category, "category", "", "Category of the record, like meeting. Defaults to the project's category")
 
 	start.Flags().StringVar(&atTime, "at", "", "Start the record at a different time than now.")
 	start.Flags().DurationVar(&ago, "ago", 0*time.Second, "Start the record at a different time than now, given as a duration.")
@@ -111,3 +137,19 @@ Notes can contain tags, denoted by the prefix "%s", like "%stag"`, core.TagPrefi
 
 	return start
 }
+
+// detectProject detects the project and tags for the current directory, see core.DetectProject
+func detectProject() (*core.DirectoryConfig, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	conf, err := core.DetectProject(cwd)
+	if err != nil {
+		return nil, err
+	}
+	if conf == nil {
+		return nil, fmt.Errorf("no project given, and no file %s in the current directory or its parents", core.DirectoryConfigFile)
+	}
+	return conf, nil
+}
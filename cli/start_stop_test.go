@@ -2,6 +2,7 @@ package cli
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/mlange-42/track/core"
@@ -58,3 +59,94 @@ func TestStartStop(t *testing.T) {
 	assert.Equal(t, all[0].Project, "test", "Wrong record project")
 	assert.Equal(t, all[1].Project, "test2", "Wrong record project")
 }
+
+func TestStartCategory(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	project.Category = "work"
+	err = track.SaveProject(project, false)
+	if err != nil {
+		t.Fatal("error saving project")
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"start", "test", "--category", "unknown"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for unknown category")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"start", "test", "--ago", "60m"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"switch", "test", "Standup", "--ago", "30m", "--force", "--category", "meeting"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"report", "categories"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	all, err := track.LoadAllRecords()
+	assert.Nil(t, err, "error loading records")
+	assert.Equal(t, 2, len(all), "Wrong number of records")
+	assert.Equal(t, "work", all[0].Category, "Wrong category from project")
+	assert.Equal(t, "meeting", all[1].Category, "Wrong category from flag")
+
+	cmd, _, err = RootCommand(track, "").Find([]string{"report", "c"})
+	assert.Nil(t, err)
+	assert.Equal(t, "chart", cmd.Name(), "Shortcut 'report c' should stay with chart")
+	cmd, _, err = RootCommand(track, "").Find([]string{"report", "cat"})
+	assert.Nil(t, err)
+	assert.Equal(t, "categories", cmd.Name())
+}
+
+func TestStartDetectProject(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	err = track.SaveProject(project, false)
+	if err != nil {
+		t.Fatal("error saving project")
+	}
+
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	defer func() { _ = os.Chdir(wd) }()
+
+	dir := filepath.Join(track.RootDir, "project")
+	sub := filepath.Join(dir, "src")
+	assert.Nil(t, os.MkdirAll(sub, 0755))
+	assert.Nil(t, os.Chdir(sub))
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"start"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error without project file")
+
+	err = os.WriteFile(filepath.Join(dir, core.DirectoryConfigFile), []byte("project: test\ntags: [dev]\n"), 0644)
+	assert.Nil(t, err)
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"start", "--ago", "10m"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	rec, err := track.OpenRecord()
+	assert.Nil(t, err)
+	assert.NotNil(t, rec)
+	assert.Equal(t, "test", rec.Project)
+	assert.Equal(t, "+dev", rec.Note)
+	assert.Equal(t, map[string]string{"dev": ""}, rec.Tags)
+}
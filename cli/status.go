@@ -40,6 +40,8 @@ Columns of the status are:
 * total - Recorded time today since the last break longer than --max-break
 * break - Break time today since the last break longer than --max-break
 * today - Total recorded time since midnight
+
+Lists today's breaks below the table, see command 'create break'.
 `,
 		Aliases: []string{"s", "?"},
 		Args:    util.WrappedArgs(cobra.MaximumNArgs(1)),
@@ -87,7 +89,7 @@ Columns of the status are:
 			} else {
 				out.Success("Record %s\n", info.Start.Format(util.DateTimeFormat))
 			}
-			out.Print(core.SerializeRecord(info.Record, time.Now()))
+			out.Print(t.Syntax().SerializeRecord(info.Record, time.Now()))
 			out.Print("+------------------+-------+-------+-------+-------+\n")
 			out.Print("|          project |  curr | total | break | today |\n")
 			out.Print(
@@ -102,6 +104,27 @@ Columns of the status are:
 				out.Print(" (paused for %s)", util.FormatDuration(info.CurrPause))
 			}
 			out.Print("\n+------------------+-------+-------+-------+-------+")
+
+			records, err := t.LoadAllRecords()
+			if err != nil {
+				return fmt.Errorf("failed to show status: %s", err)
+			}
+			absences, err := t.LoadAbsences()
+			if err != nil {
+				return fmt.Errorf("failed to show status: %s", err)
+			}
+			now := time.Now()
+
+			breaks, err := t.LoadBreaks()
+			if err != nil {
+				return fmt.Errorf("failed to show status: %s", err)
+			}
+			today := util.ToDate(now)
+			for _, brk := range breaks.Between(today, today.AddDate(0, 0, 1)) {
+				out.Print("\nBreak %s", formatBreak(&brk, t.Config.Formatter()))
+			}
+
+			out.Print("\n%s", formatStreaks(core.DailyTimes(records, now), &absences, t.Config.DailyGoal, now, t.Config.Formatter()))
 			return nil
 		},
 	}
@@ -212,3 +235,16 @@ func getStatus(t *core.Track, proj string, maxBreak time.Duration) (statusInfo,
 		TotalTime: totalTime,
 	}, nil
 }
+
+// formatStreaks formats the current and best streak of days with tracked time,
+// and of days meeting the daily goal if it is set
+func formatStreaks(daily map[time.Time]time.Duration, absences *core.Absences, goal time.Duration, now time.Time, f util.Formatter) string {
+	skip := absences.FullDays()
+	streaks := core.NewStreaks(daily, 0, now, skip)
+	str := fmt.Sprintf("Streak %d days (best %d)", streaks.Current.Days, streaks.Best.Days)
+	if goal > 0 {
+		streaks = core.NewStreaks(absences.WithCredits(daily), goal, now, skip)
+		str += fmt.Sprintf(", goal %s: %d days (best %d)", f.Duration(goal), streaks.Current.Days, streaks.Best.Days)
+	}
+	return str
+}
@@ -41,27 +41,26 @@ func stopCommand(t *core.Track) *cobra.Command {
 				return fmt.Errorf("failed to stop record: aborted by user")
 			}
 
+			if deleteRecord {
+				// Delete directly, as stopping might split the record at midnight
+				err = t.DeleteRecord(open)
+				if err != nil {
+					return fmt.Errorf("failed to delete record: %s", err)
+				}
+				out.Success("Deleted record %s from '%s'", open.Start.Format(util.DateTimeFormat), open.Project)
+				return nil
+			}
+
 			stopTime, err := getStopTime(open, ago, atTime)
 			if err != nil {
 				return fmt.Errorf("failed to stop record: %s", err)
 			}
 
-			record, err := t.StopRecord(stopTime)
+			record, err := stopRecord(t, stopTime)
 			if err != nil {
 				return fmt.Errorf("failed to stop record: %s", err)
 			}
 			out.Success("Stopped record in '%s' at %s", record.Project, record.End.Format(util.TimeFormat))
-
-			if !deleteRecord {
-				return nil
-			}
-
-			out.Print("\n")
-			err = t.DeleteRecord(record)
-			if err != nil {
-				return fmt.Errorf("failed to delete record: %s", err)
-			}
-			out.Success("Deleted record %s from '%s'", record.Start.Format(util.DateTimeFormat), record.Project)
 			return nil
 		},
 	}
@@ -16,6 +16,7 @@ func switchCommand(t *core.Track) *cobra.Command {
 	var force bool
 	var atTime string
 	var ago time.Duration
+	var category string
 
 	switchCom := &cobra.Command{
 		Use:   "switch PROJECT [NOTE...]",
@@ -23,24 +24,23 @@ func switchCommand(t *core.Track) *cobra.Command {
 		Long: fmt.Sprintf(`Start a record and stop any running record
 
 Everything after the project name is considered a note for the record.
-Notes can contain tags, denoted by the prefix "%s", like "%stag"`, core.TagPrefix, core.TagPrefix),
+Notes can contain tags, denoted by the prefix "%s", like "%stag"`, t.Config.TagPrefix, t.Config.TagPrefix),
 		Aliases: []string{"sw"},
 		Args:    util.WrappedArgs(cobra.MinimumNArgs(1)),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			project := args[0]
-
-			if !t.ProjectExists(project) {
-				return fmt.Errorf("failed to start record: project '%s' does not exist", project)
+			if err := t.Config.CheckCategory(category); err != nil {
+				return fmt.Errorf("failed to start record: %s", err)
+			}
+			proj, err := t.ResolveProject(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to start record: %s", err)
 			}
+			project := proj.Name
 
 			if copy && len(args) > 1 {
 				return fmt.Errorf("failed to start record: can't use note arguments with flag --copy")
 			}
 
-			proj, err := t.LoadProject(project)
-			if err != nil {
-				return fmt.Errorf("failed to start record: %s", err)
-			}
 			if proj.Archived {
 				return fmt.Errorf("failed to start record: project '%s' is archived", proj.Name)
 			}
@@ -51,22 +51,15 @@ Notes can contain tags, denoted by the prefix "%s", like "%stag"`, core.TagPrefi
 				return fmt.Errorf("failed to start record: %s", err)
 			}
 			if open != nil {
+				if !force && open.Project == project {
+					return fmt.Errorf("already working on project '%s'. Use --force to start a new record anyway", project)
+				}
+
 				var err error
 				startStopTime, err = getStopTime(open, ago, atTime)
 				if err != nil {
 					return fmt.Errorf("failed to stop record: %s", err)
 				}
-
-				record, err := t.StopRecord(startStopTime)
-				if err != nil {
-					return fmt.Errorf("failed to create record: %s", err.Error())
-				}
-
-				if !force && record.Project == project {
-					return fmt.Errorf("already working on project '%s'. Use --force to start a new record anyway", project)
-				}
-
-				out.Success("Stopped record in '%s' at %s\n", record.Project, record.End.Format(util.TimeFormat))
 			} else {
 				latest, err := t.LatestRecord()
 				if err != nil {
@@ -96,27 +89,36 @@ Notes can contain tags, denoted by the prefix "%s", like "%stag"`, core.TagPrefi
 				if latest != nil {
 					note = latest.Note
 					tags = latest.Tags
+					proj.Category = latest.Category
 				} else {
 					return fmt.Errorf("failed to create record with copy: no previous record in '%s'", project)
 				}
 			} else {
 				note = strings.Join(args[1:], " ")
-				tags, err = core.ExtractTagsSlice(args[1:])
+				tags, err = t.Syntax().ExtractTagsSlice(args[1:])
 				if err != nil {
 					return fmt.Errorf("failed to start record: %s", err.Error())
 				}
 			}
 
-			record, err := t.StartRecord(&proj, note, tags, startStopTime)
+			if category != "" {
+				proj.Category = category
+			}
+
+			stopped, record, err := switchRecord(t, &proj, note, tags, startStopTime)
 			if err != nil {
 				return fmt.Errorf("failed to create record: %s", err.Error())
 			}
 
+			if stopped != nil {
+				out.Success("Stopped record in '%s' at %s\n", stopped.Project, stopped.End.Format(util.TimeFormat))
+			}
 			out.Success("Started record in '%s' at %s", project, record.Start.Format(util.TimeFormat))
 			return nil
 		},
 	}
-	switchCom.Flags().BoolVarP(&copy, "copy", "c", false, "Copy note and tags from the last record of the project.")
+	switchCom.Flags().BoolVarP(&copy, "copy", "c", false, "Copy note, tags and category from the last record of the project.")
+	switchCom.Flags().StringVar(&category, "category", "", "Category of the record, like meeting. Defaults to the project's category")
 
 	switchCom.Flags().BoolVarP(&force, "force", "f", false, "Force start of a new record if the project is already running")
 	switchCom.Flags().StringVar(&atTime, "at", "", "Switch at a different time than now.")
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/remote"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+const syncTokenEnvVar = "TRACK_SYNC_TOKEN"
+
+func syncCommand(t *core.Track) *cobra.Command {
+	var token string
+
+	sync := &cobra.Command{
+		Use:   "sync",
+		Short: "Synchronize records between devices",
+		Long: `Synchronize records between devices
+
+Run a sync server on one device, and sync clients on the others.
+Records of the current workspace are exchanged incrementally, based on their modification times.
+For records changed on both sides, the later change wins.
+
+Server and clients are protected by a shared token,
+given by flag --token or by environment variable ` + syncTokenEnvVar + `.
+The token is required.
+
+Pushed and pulled records are validated before they are applied.
+They must not overlap other records or change locked records.
+Records of projects that don't exist on the receiving side are skipped with a warning,
+and are exchanged again by the next sync, e.g. after creating the project.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+	sync.PersistentFlags().StringVar(&token, "token", "", "Token for authentication between client and server")
+
+	sync.AddCommand(syncServerCommand(t, &token))
+	sync.AddCommand(syncClientCommand(t, &token))
+
+	sync.Long += "\n\n" + formatCmdTree(sync)
+	return sync
+}
+
+func syncServerCommand(t *core.Track, token *string) *cobra.Command {
+	var addr string
+
+	server := &cobra.Command{
+		Use:     "server",
+		Short:   "Run a sync server for the current workspace",
+		Long:    "Run a sync server for the current workspace. The server runs until it is interrupted.",
+		Aliases: []string{"s"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := syncToken(*token)
+			if token == "" {
+				return fmt.Errorf("failed to run sync server: a token is required, given by flag --token or by environment variable %s", syncTokenEnvVar)
+			}
+			srv := &http.Server{
+				Addr:              addr,
+				Handler:           remote.NewServer(t, token),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt)
+			go func() {
+				<-signals
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = srv.Shutdown(ctx)
+			}()
+
+			out.Success("Started sync server for workspace '%s' on %s\n", t.Workspace(), addr)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to run sync server: %s", err)
+			}
+			return nil
+		},
+	}
+
+	server.Flags().StringVarP(&addr, "addr", "a", ":8421", "Address to listen on")
+
+	return server
+}
+
+func syncClientCommand(t *core.Track, token *string) *cobra.Command {
+	client := &cobra.Command{
+		Use:     "client URL",
+		Short:   "Synchronize the current workspace with a sync server",
+		Aliases: []string{"c"},
+		Args:    util.WrappedArgs(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := syncToken(*token)
+			if token == "" {
+				return fmt.Errorf("failed to sync: a token is required, given by flag --token or by environment variable %s", syncTokenEnvVar)
+			}
+			result, err := remote.Sync(t, remote.NewClient(args[0], token))
+			if err != nil {
+				return fmt.Errorf("failed to sync: %s", err)
+			}
+			for _, rejected := range result.RejectedLocally {
+				out.Warn("Skipped pulled record %s: %s\n", rejected.ID, rejected.Reason)
+			}
+			for _, rejected := range result.RejectedByServer {
+				out.Warn("Skipped pushed record %s: %s on the server\n", rejected.ID, rejected.Reason)
+			}
+			out.Success("Synchronized with %s: %d change(s) pulled, %d change(s) pushed", args[0], result.Pulled, result.Pushed)
+			return nil
+		},
+	}
+
+	return client
+}
+
+// syncToken returns the given token, or the token from environment variable TRACK_SYNC_TOKEN if it is empty
+func syncToken(token string) string {
+	if token != "" {
+		return token
+	}
+	return os.Getenv(syncTokenEnvVar)
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func trashCommand(t *core.Track) *cobra.Command {
+	trash := &cobra.Command{
+		Use:   "trash",
+		Short: "List, restore and purge deleted records",
+		Long: `List, restore and purge deleted records
+
+Deleted records are kept in the trash of the workspace for the number of days
+given by config entry trashDays.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	trash.AddCommand(trashListCommand(t))
+	trash.AddCommand(trashRestoreCommand(t))
+	trash.AddCommand(trashPurgeCommand(t))
+
+	trash.Long += "\n\n" + formatCmdTree(trash)
+	return trash
+}
+
+func trashListCommand(t *core.Track) *cobra.Command {
+	list := &cobra.Command{
+		Use:     "list",
+		Short:   "List deleted records",
+		Aliases: []string{"l"},
+		Args:    util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := t.DeletedRecords()
+			if err != nil {
+				return fmt.Errorf("failed to list deleted records: %s", err)
+			}
+			for _, e := range entries {
+				out.Print(
+					"%s (%s)  %-15s deleted %s\n",
+					e.Record.Start.Format(util.DateTimeFormat),
+					util.FormatDuration(e.Record.Duration(util.NoTime, util.NoTime)),
+					e.Record.Project,
+					e.Deleted.Format(util.DateTimeFormat),
+				)
+			}
+			return nil
+		},
+	}
+
+	return list
+}
+
+func trashRestoreCommand(t *core.Track) *cobra.Command {
+	restore := &cobra.Command{
+		Use:     "restore DATE TIME",
+		Short:   "Restore a deleted record",
+		Long:    "Restore a deleted record. Restores the most recently deleted record with the given start time.",
+		Aliases: []string{"r"},
+		Args:    util.WrappedArgs(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tm, err := util.ParseDateTime(strings.Join(args, " "))
+			if err != nil {
+				return fmt.Errorf("failed to restore record: %s", err)
+			}
+			record, err := t.RestoreDeleted(tm)
+			if err != nil {
+				return fmt.Errorf("failed to restore record: %s", err)
+			}
+			out.Success("Restored record %s in '%s'", record.Start.Format(util.DateTimeFormat), record.Project)
+			return nil
+		},
+	}
+
+	return restore
+}
+
+func trashPurgeCommand(t *core.Track) *cobra.Command {
+	var days int
+
+	purge := &cobra.Command{
+		Use:   "purge",
+		Short: "Permanently delete records from the trash",
+		Long: `Permanently delete records from the trash
+
+Purges all records from the trash, or only those deleted more than --days days ago.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if days < 0 {
+				return fmt.Errorf("failed to purge trash: --days must not be negative")
+			}
+			cnt, err := t.PurgeTrash(time.Duration(days) * 24 * time.Hour)
+			if err != nil {
+				return fmt.Errorf("failed to purge trash: %s", err)
+			}
+			out.Success("Purged %d record(s) from the trash", cnt)
+			return nil
+		},
+	}
+
+	purge.Flags().IntVarP(&days, "days", "d", 0, "Only purge records deleted more than this number of days ago")
+
+	return purge
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrash(t *testing.T) {
+	track, err := setupTestCommand()
+	if err != nil {
+		t.Fatal("error setting up test")
+	}
+	defer os.Remove(track.RootDir)
+
+	record := core.Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	err = track.SaveRecord(&record, false)
+	if err != nil {
+		t.Fatal("error saving record")
+	}
+	err = track.DeleteRecord(&record)
+	if err != nil {
+		t.Fatal("error deleting record")
+	}
+
+	cmd := RootCommand(track, "")
+	cmd.SetArgs([]string{"trash", "list"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"trash", "restore", "2001-02-03", "04:05"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	_, err = track.LoadRecord(record.Start)
+	assert.Nil(t, err, "record should be restored")
+
+	err = track.DeleteRecord(&record)
+	if err != nil {
+		t.Fatal("error deleting record")
+	}
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"trash", "purge"})
+	err = cmd.Execute()
+	assert.Nil(t, err, "error executing command")
+
+	cmd = RootCommand(track, "")
+	cmd.SetArgs([]string{"trash", "restore", "2001-02-03", "04:05"})
+	err = cmd.Execute()
+	assert.NotNil(t, err, "expected error for purged record")
+}
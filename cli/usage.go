@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+)
+
+func usageCommand(t *core.Track) *cobra.Command {
+	usage := &cobra.Command{
+		Use:   "usage",
+		Short: "Show statistics of the data directory of the current workspace",
+		Long: `Show statistics of the data directory of the current workspace
+
+Shows the number of records, per year and in total, the oldest and newest record,
+the size of records, trash and record histories, and the health of the report cache.
+
+Stale cache entries are updated by the next report that uses them.
+Conflicting copies of record files are resolved with 'track conflicts'.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stats, err := t.UsageStats(time.Now())
+			if err != nil {
+				return fmt.Errorf("failed to collect usage statistics: %s", err)
+			}
+			out.Print("%s", formatUsage(t, &stats))
+			return nil
+		},
+	}
+
+	return usage
+}
+
+func formatUsage(t *core.Track, stats *core.UsageStats) string {
+	f := t.Config.Formatter()
+	sb := strings.Builder{}
+
+	fmt.Fprintf(&sb, "%-12s %s (%s)\n", "Workspace", t.Workspace(), t.WorkspaceDir(t.Workspace()))
+	fmt.Fprintf(&sb, "%-12s %d on %d days\n", "Records", stats.Records, stats.Days)
+	if stats.Records > 0 {
+		fmt.Fprintf(&sb, "%-12s %s\n", "Oldest", f.DateTime(stats.Oldest))
+		fmt.Fprintf(&sb, "%-12s %s\n", "Newest", f.DateTime(stats.Newest))
+	}
+	fmt.Fprintf(&sb, "%-12s %d\n", "Trash", stats.Trash)
+	fmt.Fprintf(&sb, "%-12s %d\n", "Conflicts", stats.Conflicts)
+
+	fmt.Fprintf(&sb, "\nSize\n")
+	fmt.Fprintf(&sb, "  %-10s %10s\n", "records", formatSize(stats.RecordsSize))
+	fmt.Fprintf(&sb, "  %-10s %10s\n", "trash", formatSize(stats.TrashSize))
+	fmt.Fprintf(&sb, "  %-10s %10s\n", "history", formatSize(stats.HistorySize))
+	fmt.Fprintf(&sb, "  %-10s %10s\n", "cache", formatSize(stats.CacheSize))
+	fmt.Fprintf(&sb, "  %-10s %10s\n", "total", formatSize(stats.Size))
+
+	if len(stats.RecordsPerYear) > 0 {
+		fmt.Fprintf(&sb, "\nRecords per year\n")
+		years := maps.Keys(stats.RecordsPerYear)
+		sort.Ints(years)
+		for _, year := range years {
+			fmt.Fprintf(&sb, "  %-10d %10d\n", year, stats.RecordsPerYear[year])
+		}
+	}
+
+	state := "disabled"
+	if t.Config.ReportCache {
+		state = "enabled"
+		if !stats.Cache.IsHealthy() {
+			state = color.Yellow.Sprint("enabled, with stale entries")
+		}
+	}
+	fmt.Fprintf(&sb, "\nReport cache (%s)\n", state)
+	fmt.Fprintf(&sb, "  %-10s %10d\n", "valid", stats.Cache.Valid)
+	fmt.Fprintf(&sb, "  %-10s %10d\n", "stale", stats.Cache.Stale)
+	fmt.Fprintf(&sb, "  %-10s %10d\n", "missing", stats.Cache.Missing)
+
+	return sb.String()
+}
+
+// formatSize formats a size in bytes, using binary units
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
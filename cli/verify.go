@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"github.com/spf13/cobra"
+)
+
+func verifyCommand(t *core.Track) *cobra.Command {
+	var update bool
+
+	verify := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify record files of the current workspace against their checksums",
+		Long: `Verify record files of the current workspace against their checksums
+
+Lists record files that were modified or deleted outside of track, or that have no checksum.
+Checksums are stored when config entry checksums is enabled.
+
+With flag --update, the checksums of all record files are stored, accepting their current content.`,
+		Args: util.WrappedArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if update {
+				if err := t.UpdateChecksums(); err != nil {
+					return fmt.Errorf("failed to update checksums: %s", err)
+				}
+				out.Success("Updated checksums of all record files")
+				return nil
+			}
+
+			issues, err := t.VerifyRecords()
+			if err != nil {
+				return fmt.Errorf("failed to verify records: %s", err)
+			}
+			if len(issues) == 0 {
+				out.Success("All record files match their checksums")
+				return nil
+			}
+			for _, issue := range issues {
+				out.Print("%-12s %s\n", issue.Problem, issue.Path)
+			}
+			return fmt.Errorf("failed to verify records: %d record files with problems", len(issues))
+		},
+	}
+
+	verify.Flags().BoolVar(&update, "update", false, "Store checksums of all record files, accepting their current content")
+
+	return verify
+}
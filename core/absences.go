@@ -0,0 +1,288 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"gopkg.in/yaml.v3"
+)
+
+// AbsenceKind is the kind of an absence, like vacation
+type AbsenceKind string
+
+// Absence kinds
+const (
+	// AbsenceVacation is for vacation days
+	AbsenceVacation AbsenceKind = "vacation"
+	// AbsenceSick is for sick leave
+	AbsenceSick AbsenceKind = "sick"
+	// AbsenceHoliday is for public holidays
+	AbsenceHoliday AbsenceKind = "holiday"
+)
+
+// AbsenceKinds are all absence kinds
+var AbsenceKinds = []AbsenceKind{AbsenceVacation, AbsenceSick, AbsenceHoliday}
+
+// ParseAbsenceKind parses an absence kind
+func ParseAbsenceKind(text string) (AbsenceKind, error) {
+	for _, k := range AbsenceKinds {
+		if string(k) == strings.ToLower(text) {
+			return k, nil
+		}
+	}
+	names := make([]string, len(AbsenceKinds))
+	for i, k := range AbsenceKinds {
+		names[i] = string(k)
+	}
+	return "", fmt.Errorf("unknown absence kind '%s'. Must be one of (%s)", text, strings.Join(names, "|"))
+}
+
+// Absence is an absence from work over one or more days.
+// Absences are not records, and are thus not counted for any project.
+type Absence struct {
+	// Kind of the absence
+	Kind AbsenceKind `yaml:"kind"`
+	// First day of the absence, like 2023-01-31
+	From string `yaml:"from"`
+	// Last day of the absence, empty for single-day absences
+	To string `yaml:"to,omitempty"`
+	// Time absent per day, zero for full days
+	Duration time.Duration `yaml:"duration,omitempty"`
+	// Note of the absence
+	Note string `yaml:"note,omitempty"`
+	from time.Time
+	to   time.Time
+}
+
+type tempAbsence struct {
+	Kind     AbsenceKind   `yaml:"kind"`
+	From     string        `yaml:"from"`
+	To       string        `yaml:"to,omitempty"`
+	Duration time.Duration `yaml:"duration,omitempty"`
+	Note     string        `yaml:"note,omitempty"`
+}
+
+// UnmarshalYAML un-marshals an absence, and checks its kind and dates
+func (a *Absence) UnmarshalYAML(value *yaml.Node) error {
+	var tmp tempAbsence
+	if err := value.Decode(&tmp); err != nil {
+		return err
+	}
+	kind, err := ParseAbsenceKind(string(tmp.Kind))
+	if err != nil {
+		return err
+	}
+	from, err := util.ParseDate(tmp.From)
+	if err != nil {
+		return fmt.Errorf("invalid absence date '%s': %s", tmp.From, err)
+	}
+	to := from
+	if tmp.To != "" {
+		to, err = util.ParseDate(tmp.To)
+		if err != nil {
+			return fmt.Errorf("invalid absence date '%s': %s", tmp.To, err)
+		}
+	}
+	abs, err := NewAbsence(kind, from, to, tmp.Duration, tmp.Note)
+	if err != nil {
+		return err
+	}
+	*a = abs
+	return nil
+}
+
+// NewAbsence creates a new absence from the first to the last day.
+// Argument duration is the time absent per day, or zero for full days.
+func NewAbsence(kind AbsenceKind, from, to time.Time, duration time.Duration, note string) (Absence, error) {
+	from, to = util.ToDate(from), util.ToDate(to)
+	if to.Before(from) {
+		return Absence{}, fmt.Errorf("absence ends before it starts (%s - %s)", from.Format(util.DateFormat), to.Format(util.DateFormat))
+	}
+	if duration < 0 || duration > 24*time.Hour {
+		return Absence{}, fmt.Errorf("invalid absence duration %s", duration)
+	}
+	abs := Absence{
+		Kind:     kind,
+		From:     from.Format(util.DateFormat),
+		Duration: duration,
+		Note:     note,
+		from:     from,
+		to:       to,
+	}
+	if !to.Equal(from) {
+		abs.To = to.Format(util.DateFormat)
+	}
+	return abs, nil
+}
+
+// FromDate returns the first day of the absence
+func (a *Absence) FromDate() time.Time {
+	return a.from
+}
+
+// ToDate returns the last day of the absence
+func (a *Absence) ToDate() time.Time {
+	return a.to
+}
+
+// Contains checks if the absence includes the given day
+func (a *Absence) Contains(day time.Time) bool {
+	day = util.ToDate(day)
+	return !day.Before(a.from) && !day.After(a.to)
+}
+
+// IsFullDay checks if the absence is for full days
+func (a *Absence) IsFullDay() bool {
+	return a.Duration == 0
+}
+
+// Absences are all absences of a workspace
+type Absences struct {
+	Absences []Absence `yaml:"absences"`
+}
+
+// LoadAbsences loads the absences of the current workspace.
+// Returns no absences if there are none yet.
+func (t *Track) LoadAbsences() (Absences, error) {
+	data, err := t.FileSystem().ReadFile(t.AbsencesPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Absences{Absences: []Absence{}}, nil
+		}
+		return Absences{}, err
+	}
+	var abs Absences
+	if err := yaml.Unmarshal(data, &abs); err != nil {
+		return Absences{}, fmt.Errorf("invalid absences file: %s", err)
+	}
+	if abs.Absences == nil {
+		abs.Absences = []Absence{}
+	}
+	return abs, nil
+}
+
+// SaveAbsences saves the absences of the current workspace, replacing the file atomically
+func (t *Track) SaveAbsences(abs *Absences) error {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(abs)
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf("%s Absences\n\n%s", YamlCommentPrefix, data)
+
+	path := t.AbsencesPath()
+	tempPath := path + ".tmp"
+	if err := t.FileSystem().WriteFile(tempPath, []byte(content), 0600); err != nil {
+		return err
+	}
+	return t.FileSystem().Rename(tempPath, path)
+}
+
+// Add adds an absence. Fails if it overlaps an existing absence.
+func (a *Absences) Add(absence Absence) error {
+	for _, other := range a.Absences {
+		if !absence.from.After(other.to) && !absence.to.Before(other.from) {
+			return fmt.Errorf("absence overlaps %s absence from %s", other.Kind, other.From)
+		}
+	}
+	a.Absences = append(a.Absences, absence)
+	sort.SliceStable(a.Absences, func(i, j int) bool { return a.Absences[i].from.Before(a.Absences[j].from) })
+	return nil
+}
+
+// Remove removes the absence that includes the given day
+func (a *Absences) Remove(day time.Time) (Absence, error) {
+	for i, abs := range a.Absences {
+		if abs.Contains(day) {
+			a.Absences = append(a.Absences[:i], a.Absences[i+1:]...)
+			return abs, nil
+		}
+	}
+	return Absence{}, fmt.Errorf("no absence on %s", day.Format(util.DateFormat))
+}
+
+// On returns the absence that includes the given day.
+// Returns false if there is no absence on that day.
+func (a *Absences) On(day time.Time) (Absence, bool) {
+	for _, abs := range a.Absences {
+		if abs.Contains(day) {
+			return abs, true
+		}
+	}
+	return Absence{}, false
+}
+
+// Credit returns the time credited for an absence on the given day.
+// Full-day absences are credited with the given full-day time, partial absences with their duration, but not more than a full day.
+func (a *Absences) Credit(day time.Time, fullDay time.Duration) time.Duration {
+	abs, ok := a.On(day)
+	if !ok {
+		return 0
+	}
+	if abs.IsFullDay() || abs.Duration > fullDay {
+		return fullDay
+	}
+	return abs.Duration
+}
+
+// WithCredits returns a copy of daily times, with the time of partial absences added.
+// Full-day absences are not added, see FullDays.
+func (a *Absences) WithCredits(daily map[time.Time]time.Duration) map[time.Time]time.Duration {
+	result := make(map[time.Time]time.Duration, len(daily))
+	for day, dur := range daily {
+		result[day] = dur
+	}
+	for _, abs := range a.Absences {
+		if abs.IsFullDay() {
+			continue
+		}
+		for day := abs.from; !day.After(abs.to); day = day.AddDate(0, 0, 1) {
+			result[day] += abs.Duration
+		}
+	}
+	return result
+}
+
+// FullDays returns the set of days with full-day absences
+func (a *Absences) FullDays() map[time.Time]bool {
+	days := map[time.Time]bool{}
+	for _, abs := range a.Absences {
+		if !abs.IsFullDay() {
+			continue
+		}
+		for day := abs.from; !day.After(abs.to); day = day.AddDate(0, 0, 1) {
+			days[day] = true
+		}
+	}
+	return days
+}
+
+// AbsenceDay is a single day of an absence
+type AbsenceDay struct {
+	Date time.Time
+	Kind AbsenceKind
+	// Time absent, zero for a full day
+	Duration time.Duration
+}
+
+// Days returns all absence days between start and end, in chronological order.
+// The end is exclusive. Start and end may be zero for no limit.
+func (a *Absences) Days(start, end time.Time) []AbsenceDay {
+	days := []AbsenceDay{}
+	for _, abs := range a.Absences {
+		for day := abs.from; !day.After(abs.to); day = day.AddDate(0, 0, 1) {
+			if (!start.IsZero() && day.Before(util.ToDate(start))) || (!end.IsZero() && !day.Before(end)) {
+				continue
+			}
+			days = append(days, AbsenceDay{Date: day, Kind: abs.Kind, Duration: abs.Duration})
+		}
+	}
+	sort.SliceStable(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days
+}
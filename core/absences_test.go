@@ -0,0 +1,98 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAbsences(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 0, 0, 0, 0, time.Local)
+	}
+
+	_, err := ParseAbsenceKind("foo")
+	assert.NotNil(t, err)
+	kind, err := ParseAbsenceKind("Sick")
+	assert.Nil(t, err)
+	assert.Equal(t, AbsenceSick, kind)
+
+	_, err = NewAbsence(AbsenceVacation, day(5), day(4), 0, "")
+	assert.NotNil(t, err, "Error expected for absence ending before start")
+
+	vacation, err := NewAbsence(AbsenceVacation, day(2), day(6), 0, "")
+	assert.Nil(t, err)
+	sick, err := NewAbsence(AbsenceSick, day(9), day(9), 4*time.Hour, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "", sick.To)
+
+	absences := Absences{}
+	assert.Nil(t, absences.Add(sick))
+	assert.Nil(t, absences.Add(vacation))
+	assert.Equal(t, AbsenceVacation, absences.Absences[0].Kind, "Absences not sorted")
+
+	overlap, err := NewAbsence(AbsenceHoliday, day(6), day(6), 0, "")
+	assert.Nil(t, err)
+	assert.NotNil(t, absences.Add(overlap), "Error expected for overlapping absences")
+
+	abs, ok := absences.On(day(4).Add(12 * time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, AbsenceVacation, abs.Kind)
+	_, ok = absences.On(day(7))
+	assert.False(t, ok)
+
+	assert.Equal(t, 8*time.Hour, absences.Credit(day(3), 8*time.Hour))
+	assert.Equal(t, 4*time.Hour, absences.Credit(day(9), 8*time.Hour))
+	assert.Equal(t, time.Duration(0), absences.Credit(day(8), 8*time.Hour))
+
+	daily := absences.WithCredits(map[time.Time]time.Duration{day(9): 4 * time.Hour})
+	assert.Equal(t, map[time.Time]time.Duration{day(9): 8 * time.Hour}, daily)
+	assert.Equal(t, 5, len(absences.FullDays()))
+
+	days := absences.Days(day(5), day(10))
+	assert.Equal(t, []AbsenceDay{
+		{Date: day(5), Kind: AbsenceVacation},
+		{Date: day(6), Kind: AbsenceVacation},
+		{Date: day(9), Kind: AbsenceSick, Duration: 4 * time.Hour},
+	}, days)
+
+	_, err = absences.Remove(day(7))
+	assert.NotNil(t, err)
+	removed, err := absences.Remove(day(3))
+	assert.Nil(t, err)
+	assert.Equal(t, AbsenceVacation, removed.Kind)
+	assert.Equal(t, 1, len(absences.Absences))
+
+	var loaded Absence
+	err = yaml.Unmarshal([]byte("kind: holiday\nfrom: 2023-01-06\n"), &loaded)
+	assert.Nil(t, err)
+	assert.Equal(t, day(6), loaded.FromDate())
+	assert.Equal(t, day(6), loaded.ToDate())
+	err = yaml.Unmarshal([]byte("kind: party\nfrom: 2023-01-06\n"), &loaded)
+	assert.NotNil(t, err, "Error expected for unknown kind")
+}
+
+func TestLoadSaveAbsences(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	absences, err := track.LoadAbsences()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(absences.Absences))
+
+	vacation, err := NewAbsence(AbsenceVacation, time.Date(2023, 1, 2, 0, 0, 0, 0, time.Local), time.Date(2023, 1, 6, 0, 0, 0, 0, time.Local), 0, "Ski trip")
+	assert.Nil(t, err)
+	assert.Nil(t, absences.Add(vacation))
+	assert.Nil(t, track.SaveAbsences(&absences))
+
+	loaded, err := track.LoadAbsences()
+	assert.Nil(t, err)
+	assert.Equal(t, absences, loaded)
+}
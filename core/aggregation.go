@@ -0,0 +1,64 @@
+package core
+
+import "time"
+
+// AggregationFunc is a custom aggregation of a Reporter, like the time per ticket.
+// It returns the key to aggregate a record under, and the value to add for the record.
+// Records with an empty key are skipped.
+//
+// Records are clipped to the time range of the report,
+// so that the record's Duration(util.NoTime, util.NoTime) is the time within the report.
+type AggregationFunc func(r Record) (key string, value time.Duration)
+
+// namedAggregation is a custom aggregation with a name, to prevent duplicate registration
+type namedAggregation struct {
+	name string
+	fn   AggregationFunc
+}
+
+// SetAggregation registers a custom aggregation for reports, or replaces the aggregation with the same name.
+// Reporters evaluate all registered aggregations alongside the built-in ones, see Reporter.Aggregations.
+//
+// Reporters created with NewCachedReporter don't use the report cache if there are custom aggregations.
+func (t *Track) SetAggregation(name string, fn AggregationFunc) {
+	for i := range t.aggregations {
+		if t.aggregations[i].name == name {
+			t.aggregations[i].fn = fn
+			return
+		}
+	}
+	t.aggregations = append(t.aggregations, namedAggregation{name: name, fn: fn})
+}
+
+// clipRecord returns a copy of a record, with its start, end and pauses clipped to a time range.
+// Zero bounds are open. Running records and pauses stay open if they don't reach beyond the range.
+func clipRecord(r *Record, start, end time.Time, now time.Time) Record {
+	c := *r
+	if !start.IsZero() && c.Start.Before(start) {
+		c.Start = start
+	}
+	if !end.IsZero() && (c.End.After(end) || (c.End.IsZero() && now.After(end))) {
+		c.End = end
+	}
+	if len(r.Pause) == 0 {
+		return c
+	}
+
+	c.Pause = make([]Pause, 0, len(r.Pause))
+	for _, p := range r.Pause {
+		if !p.End.IsZero() && !p.End.After(c.Start) {
+			continue
+		}
+		if !c.End.IsZero() && !p.Start.Before(c.End) {
+			continue
+		}
+		if p.Start.Before(c.Start) {
+			p.Start = c.Start
+		}
+		if !c.End.IsZero() && (p.End.IsZero() || p.End.After(c.End)) {
+			p.End = c.End
+		}
+		c.Pause = append(c.Pause, p)
+	}
+	return c
+}
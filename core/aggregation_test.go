@@ -0,0 +1,84 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReporterAggregations(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err)
+	assert.Nil(t, track.SaveProject(NewProject("test", "", "T", []string{}, 0, 15), false))
+
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 2, 22, 0, 0), End: util.DateTime(2001, 2, 3, 2, 0, 0), Note: "+ticket=T-1", Tags: map[string]string{"ticket": "T-1"}},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 10, 0, 0), Note: "+ticket=T-2", Tags: map[string]string{"ticket": "T-2"},
+			Pause: []Pause{{Start: util.DateTime(2001, 2, 3, 9, 0, 0), End: util.DateTime(2001, 2, 3, 9, 30, 0)}}},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 11, 0, 0), End: util.DateTime(2001, 2, 3, 12, 0, 0), Note: "+ticket=T-1", Tags: map[string]string{"ticket": "T-1"}},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 13, 0, 0), End: util.DateTime(2001, 2, 3, 14, 0, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false))
+	}
+
+	track.SetAggregation("ticket", func(r Record) (string, time.Duration) {
+		return "wrong", 0
+	})
+	track.SetAggregation("ticket", func(r Record) (string, time.Duration) {
+		return r.Tags["ticket"], r.Duration(util.NoTime, util.NoTime)
+	})
+	track.SetAggregation("count", func(r Record) (string, time.Duration) {
+		return "records", time.Second
+	})
+
+	start, end := util.Date(2001, 2, 3), util.Date(2001, 2, 4)
+	for _, newReporter := range []func(*Track, []string, FilterFunctions, bool, time.Time, time.Time) (*Reporter, error){
+		NewReporter, NewStreamingReporter, NewCachedReporter,
+	} {
+		reporter, err := newReporter(&track, []string{}, NewFilter([]FilterFunction{}, start, end), false, start, end)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]map[string]time.Duration{
+			"ticket": {"T-1": 3 * time.Hour, "T-2": 90 * time.Minute},
+			"count":  {"records": 4 * time.Second},
+		}, reporter.Aggregations)
+	}
+}
+
+func TestClipRecord(t *testing.T) {
+	now := util.DateTime(2001, 2, 3, 20, 0, 0)
+	record := Record{
+		Start: util.DateTime(2001, 2, 3, 8, 0, 0),
+		End:   util.DateTime(2001, 2, 3, 16, 0, 0),
+		Pause: []Pause{
+			{Start: util.DateTime(2001, 2, 3, 9, 0, 0), End: util.DateTime(2001, 2, 3, 10, 0, 0)},
+			{Start: util.DateTime(2001, 2, 3, 11, 0, 0), End: util.DateTime(2001, 2, 3, 13, 0, 0)},
+			{Start: util.DateTime(2001, 2, 3, 14, 0, 0), End: util.DateTime(2001, 2, 3, 15, 0, 0)},
+		},
+	}
+	start, end := util.DateTime(2001, 2, 3, 12, 0, 0), util.DateTime(2001, 2, 3, 14, 30, 0)
+
+	clipped := clipRecord(&record, start, end, now)
+	assert.Equal(t, start, clipped.Start)
+	assert.Equal(t, end, clipped.End)
+	assert.Equal(t, []Pause{
+		{Start: start, End: util.DateTime(2001, 2, 3, 13, 0, 0)},
+		{Start: util.DateTime(2001, 2, 3, 14, 0, 0), End: end},
+	}, clipped.Pause)
+	assert.Equal(t, record.Duration(start, end), clipped.Duration(util.NoTime, util.NoTime))
+	assert.Equal(t, 3, len(record.Pause))
+
+	record.End = time.Time{}
+	clipped = clipRecord(&record, start, util.DateTime(2001, 2, 4, 0, 0, 0), now)
+	assert.True(t, clipped.End.IsZero())
+
+	clipped = clipRecord(&record, util.NoTime, util.NoTime, now)
+	assert.Equal(t, record, clipped)
+}
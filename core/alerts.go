@@ -0,0 +1,94 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AutoStoppedTag is the tag added to records that were stopped automatically
+const AutoStoppedTag = "auto-stopped"
+
+// LongRunningRecord returns the open record if it runs for longer than
+// the configured MaxRecordDuration at the given time, including pauses.
+// Returns a nil reference if there is no such record, or if the check is disabled.
+func (t *Track) LongRunningRecord(now time.Time) (*Record, error) {
+	if t.Config.MaxRecordDuration <= 0 {
+		return nil, nil
+	}
+	open, err := t.OpenRecord()
+	if err != nil {
+		return nil, err
+	}
+	if open == nil {
+		return nil, nil
+	}
+	if now.Sub(open.Start) <= t.Config.MaxRecordDuration {
+		return nil, nil
+	}
+	return open, nil
+}
+
+// AutoStopRecord stops the open record at the cut-off time given by
+// its start time and the configured MaxRecordDuration.
+// The record is tagged with AutoStoppedTag.
+func (t *Track) AutoStopRecord() (*Record, error) {
+	if t.Config.MaxRecordDuration <= 0 {
+		return nil, fmt.Errorf("auto-stop requires a positive maxRecordDuration")
+	}
+	open, err := t.OpenRecord()
+	if err != nil {
+		return nil, err
+	}
+	if open == nil {
+		return nil, ErrNoOpenRecord
+	}
+
+	if err := t.tagAutoStopped(open); err != nil {
+		return nil, err
+	}
+
+	return t.StopRecord(open.Start.Add(t.Config.MaxRecordDuration))
+}
+
+// StopLongPause stops the open record at the start of its open pause, if the pause lasts longer than
+// the configured MaxPauseDuration at the given time. Such pauses almost always mean that the user
+// went home without stopping. The record is tagged with AutoStoppedTag.
+//
+// Returns a nil reference if there is no such record, or if the rule is disabled.
+func (t *Track) StopLongPause(now time.Time) (*Record, error) {
+	if t.Config.MaxPauseDuration <= 0 {
+		return nil, nil
+	}
+	open, err := t.OpenRecord()
+	if err != nil {
+		return nil, err
+	}
+	if open == nil {
+		return nil, nil
+	}
+	pause, ok := open.CurrentPause()
+	if !ok || now.Sub(pause.Start) <= t.Config.MaxPauseDuration {
+		return nil, nil
+	}
+
+	if err := t.tagAutoStopped(open); err != nil {
+		return nil, err
+	}
+
+	// Stopping at the start of an open pause removes the pause
+	return t.StopRecord(pause.Start)
+}
+
+// tagAutoStopped adds AutoStoppedTag to the note and tags of a record, and saves it
+func (t *Track) tagAutoStopped(record *Record) error {
+	if _, ok := record.Tags[AutoStoppedTag]; ok {
+		return nil
+	}
+	if record.Tags == nil {
+		record.Tags = map[string]string{}
+	}
+	record.Tags[AutoStoppedTag] = ""
+	record.Note = strings.TrimSpace(fmt.Sprintf("%s %s%s", record.Note, t.Config.TagPrefix, AutoStoppedTag))
+	return t.SaveRecord(record, true)
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLongRunningRecord(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	track.Config.MaxRecordDuration = 10 * time.Hour
+
+	project := NewProject("test", "", "t", []string{}, 15, 0)
+
+	rec, err := track.LongRunningRecord(time.Now())
+	assert.Nil(t, err, "Error checking for long-running record")
+	assert.Nil(t, rec, "No record expected")
+
+	start := time.Now().Round(time.Minute).Add(-12 * time.Hour)
+	_, err = track.StartRecord(&project, "Note", map[string]string{}, start)
+	assert.Nil(t, err, "Error starting record")
+
+	rec, err = track.LongRunningRecord(start.Add(9 * time.Hour))
+	assert.Nil(t, err, "Error checking for long-running record")
+	assert.Nil(t, rec, "No record expected before cut-off")
+
+	rec, err = track.LongRunningRecord(start.Add(11 * time.Hour))
+	assert.Nil(t, err, "Error checking for long-running record")
+	assert.NotNil(t, rec, "Record expected after cut-off")
+
+	track.Config.MaxRecordDuration = 0
+	rec, err = track.LongRunningRecord(start.Add(11 * time.Hour))
+	assert.Nil(t, err, "Error checking for long-running record")
+	assert.Nil(t, rec, "No record expected when disabled")
+	_, err = track.AutoStopRecord()
+	assert.NotNil(t, err, "Expected error when disabled")
+
+	track.Config.MaxRecordDuration = 10 * time.Hour
+	stopped, err := track.AutoStopRecord()
+	assert.Nil(t, err, "Error auto-stopping record")
+	assert.Equal(t, start.Add(10*time.Hour), stopped.End, "Wrong end time")
+
+	latest, err := track.LatestRecord()
+	assert.Nil(t, err, "Error loading record")
+	assert.Equal(t, "Note +auto-stopped", latest.Note, "Wrong note")
+	assert.Equal(t, map[string]string{AutoStoppedTag: ""}, latest.Tags, "Wrong tags")
+	assert.True(t, latest.HasEnded(), "Record should be stopped")
+
+	_, err = track.AutoStopRecord()
+	assert.NotNil(t, err, "Expected error without running record")
+}
+
+func TestStopLongPause(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+
+	project := NewProject("test", "", "t", []string{}, 15, 0)
+	start := time.Date(2023, 5, 4, 9, 0, 0, 0, time.Local)
+	rec, err := track.StartRecord(&project, "Note", map[string]string{}, start)
+	assert.Nil(t, err, "Error starting record")
+	_, err = rec.InsertPause(start.Add(8*time.Hour), time.Time{}, "")
+	assert.Nil(t, err, "Error inserting pause")
+	assert.Nil(t, track.SaveRecord(&rec, true), "Error saving record")
+
+	stopped, err := track.StopLongPause(start.Add(24 * time.Hour))
+	assert.Nil(t, err, "Error checking for long pause")
+	assert.Nil(t, stopped, "No record expected when disabled")
+
+	track.Config.MaxPauseDuration = 4 * time.Hour
+	stopped, err = track.StopLongPause(start.Add(11 * time.Hour))
+	assert.Nil(t, err, "Error checking for long pause")
+	assert.Nil(t, stopped, "No record expected before cut-off")
+
+	stopped, err = track.StopLongPause(start.Add(24 * time.Hour))
+	assert.Nil(t, err, "Error stopping record")
+	assert.NotNil(t, stopped, "Record expected after cut-off")
+
+	latest, err := track.LatestRecord()
+	assert.Nil(t, err, "Error loading record")
+	assert.Equal(t, start.Add(8*time.Hour), latest.End, "Wrong end time")
+	assert.Equal(t, 0, len(latest.Pause), "Expected the open pause to be removed")
+	assert.Equal(t, "Note +auto-stopped", latest.Note, "Wrong note")
+
+	stopped, err = track.StopLongPause(start.Add(48 * time.Hour))
+	assert.Nil(t, err, "Error checking for long pause")
+	assert.Nil(t, stopped, "No record expected without running record")
+}
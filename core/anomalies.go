@@ -0,0 +1,173 @@
+package core
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// AnomalyKind is the kind of an anomaly in records
+type AnomalyKind string
+
+// Kinds of anomalies
+const (
+	// AnomalyLongRecord is a record much longer than the typical duration of its project
+	AnomalyLongRecord AnomalyKind = "long record"
+	// AnomalyLongDay is a day with an implausible total time
+	AnomalyLongDay AnomalyKind = "long day"
+	// AnomalyNoBreak is a long day without pauses or gaps between records
+	AnomalyNoBreak AnomalyKind = "no break"
+	// AnomalyOverlap is a record that overlaps an earlier record
+	AnomalyOverlap AnomalyKind = "overlap"
+)
+
+// minTypicalRecords is the minimum number of records of a project to determine its typical record duration
+const minTypicalRecords = 5
+
+// AnomalyOptions are the thresholds for detecting anomalies
+type AnomalyOptions struct {
+	// Records longer than this factor times the median duration of their project are anomalies
+	DurationFactor float64
+	// Days with a total time of more than this are anomalies
+	MaxDayTotal time.Duration
+	// Days with a total time of at least this, but without pauses or gaps between records, are anomalies.
+	// Zero to disable
+	BreakAfter time.Duration
+}
+
+// DefaultAnomalyOptions returns the default thresholds for detecting anomalies
+func DefaultAnomalyOptions() AnomalyOptions {
+	return AnomalyOptions{
+		DurationFactor: 3,
+		MaxDayTotal:    16 * time.Hour,
+		BreakAfter:     6 * time.Hour,
+	}
+}
+
+// Anomaly is an outlier or inconsistency in records
+type Anomaly struct {
+	Kind AnomalyKind
+	// Day of the anomaly
+	Date time.Time
+	// Record of the anomaly. Nil for anomalies of entire days
+	Record *Record
+	// Duration of the record, or total time of the day
+	Duration time.Duration
+	// Typical record duration of the project, for AnomalyLongRecord
+	Typical time.Duration
+	// The overlapped record, for AnomalyOverlap
+	Other *Record
+}
+
+// FindAnomalies finds outliers and inconsistencies in records, sorted by date:
+//
+//   - Records longer than DurationFactor times the median duration of their project,
+//     for projects with at least 5 records.
+//   - Days with a total time of more than MaxDayTotal.
+//   - Days with a total time of at least BreakAfter, without pauses or gaps between records.
+//   - Records that overlap an earlier record.
+//
+// Running records and pauses are considered to end at the given time.
+func FindAnomalies(records []Record, opts AnomalyOptions, now time.Time) []Anomaly {
+	sorted := make([]*Record, len(records))
+	for i := range records {
+		sorted[i] = &records[i]
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	anomalies := []Anomaly{}
+
+	durations := map[string][]time.Duration{}
+	for _, r := range sorted {
+		durations[r.Project] = append(durations[r.Project], r.Duration(util.NoTime, now))
+	}
+	typical := map[string]time.Duration{}
+	for project, d := range durations {
+		if len(d) >= minTypicalRecords {
+			typical[project] = NewDistribution(d).Median
+		}
+	}
+
+	type dayStats struct {
+		Total  time.Duration
+		Breaks bool
+		End    time.Time
+	}
+	days := map[time.Time]*dayStats{}
+	// The record with the latest end so far, for detecting overlaps
+	var latest *Record
+	var latestEnd time.Time
+	for _, r := range sorted {
+		end := r.End
+		if !r.HasEnded() {
+			end = now
+		}
+		if median, ok := typical[r.Project]; ok && opts.DurationFactor > 0 {
+			if dur := r.Duration(util.NoTime, now); float64(dur) > opts.DurationFactor*float64(median) {
+				anomalies = append(anomalies, Anomaly{
+					Kind:     AnomalyLongRecord,
+					Date:     util.ToDate(r.Start),
+					Record:   r,
+					Duration: dur,
+					Typical:  median,
+				})
+			}
+		}
+		if latest != nil && r.Start.Before(latestEnd) {
+			anomalies = append(anomalies, Anomaly{
+				Kind:     AnomalyOverlap,
+				Date:     util.ToDate(r.Start),
+				Record:   r,
+				Duration: r.Duration(util.NoTime, now),
+				Other:    latest,
+			})
+		}
+		if latest == nil || end.After(latestEnd) {
+			latest, latestEnd = r, end
+		}
+
+		r.forEachDay(util.NoTime, util.NoTime, now, func(day time.Time, dur time.Duration) {
+			stats, ok := days[day]
+			if !ok {
+				stats = &dayStats{}
+				days[day] = stats
+			} else if r.Start.After(stats.End) {
+				stats.Breaks = true
+			}
+			stats.Total += dur
+			if r.PauseDuration(day, day.AddDate(0, 0, 1)) > 0 {
+				stats.Breaks = true
+			}
+			if end.After(stats.End) {
+				stats.End = end
+			}
+		})
+	}
+
+	dates := make([]time.Time, 0, len(days))
+	for day := range days {
+		dates = append(dates, day)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	for _, day := range dates {
+		stats := days[day]
+		if opts.MaxDayTotal > 0 && stats.Total > opts.MaxDayTotal {
+			anomalies = append(anomalies, Anomaly{
+				Kind:     AnomalyLongDay,
+				Date:     day,
+				Duration: stats.Total,
+			})
+		}
+		if opts.BreakAfter > 0 && stats.Total >= opts.BreakAfter && !stats.Breaks {
+			anomalies = append(anomalies, Anomaly{
+				Kind:     AnomalyNoBreak,
+				Date:     day,
+				Duration: stats.Total,
+			})
+		}
+	}
+
+	sort.SliceStable(anomalies, func(i, j int) bool { return anomalies[i].Date.Before(anomalies[j].Date) })
+	return anomalies
+}
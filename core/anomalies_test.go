@@ -0,0 +1,53 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindAnomalies(t *testing.T) {
+	records := []Record{}
+	for day := 1; day <= 5; day++ {
+		records = append(records, Record{Project: "a", Start: util.DateTime(2001, 2, day, 9, 0, 0), End: util.DateTime(2001, 2, day, 10, 0, 0)})
+	}
+	records = append(records,
+		// Long record, and long day without breaks
+		Record{Project: "a", Start: util.DateTime(2001, 2, 6, 8, 0, 0), End: util.DateTime(2001, 2, 6, 15, 0, 0)},
+		// Long day, with a pause
+		Record{Project: "b", Start: util.DateTime(2001, 2, 7, 1, 0, 0), End: util.DateTime(2001, 2, 7, 19, 0, 0),
+			Pause: []Pause{{Start: util.DateTime(2001, 2, 7, 12, 0, 0), End: util.DateTime(2001, 2, 7, 12, 30, 0)}}},
+		// Overlap
+		Record{Project: "b", Start: util.DateTime(2001, 2, 7, 18, 0, 0), End: util.DateTime(2001, 2, 7, 18, 30, 0)},
+		// Long day with a gap between records
+		Record{Project: "b", Start: util.DateTime(2001, 2, 8, 8, 0, 0), End: util.DateTime(2001, 2, 8, 12, 0, 0)},
+		Record{Project: "b", Start: util.DateTime(2001, 2, 8, 13, 0, 0), End: util.DateTime(2001, 2, 8, 17, 0, 0)},
+	)
+
+	anomalies := FindAnomalies(records, DefaultAnomalyOptions(), util.DateTime(2001, 3, 1, 0, 0, 0))
+
+	kinds := []AnomalyKind{}
+	for _, a := range anomalies {
+		kinds = append(kinds, a.Kind)
+	}
+	assert.Equal(t, []AnomalyKind{AnomalyLongRecord, AnomalyNoBreak, AnomalyOverlap, AnomalyLongDay}, kinds, "Wrong anomalies")
+
+	long := anomalies[0]
+	assert.Equal(t, util.Date(2001, 2, 6), long.Date, "Wrong date")
+	assert.Equal(t, 7*time.Hour, long.Duration, "Wrong duration")
+	assert.Equal(t, time.Hour, long.Typical, "Wrong typical duration")
+
+	overlap := anomalies[2]
+	assert.Equal(t, &records[7], overlap.Record, "Wrong overlapping record")
+	assert.Equal(t, &records[6], overlap.Other, "Wrong overlapped record")
+
+	assert.Equal(t, 18*time.Hour, anomalies[3].Duration, "Wrong day total")
+
+	opts := DefaultAnomalyOptions()
+	opts.DurationFactor = 0
+	opts.BreakAfter = 0
+	anomalies = FindAnomalies(records, opts, util.DateTime(2001, 3, 1, 0, 0, 0))
+	assert.Equal(t, 2, len(anomalies), "Wrong number of anomalies")
+}
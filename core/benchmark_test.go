@@ -0,0 +1,135 @@
+package core
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// setupBenchmark creates a Track with a generated dataset of the given number of years and records per day
+func setupBenchmark(b *testing.B, years int, perDay int) *Track {
+	b.Helper()
+
+	dir, err := os.MkdirTemp("", "track-test")
+	if err != nil {
+		b.Fatal("error creating temporary directory")
+	}
+	b.Cleanup(func() { os.RemoveAll(dir) })
+
+	track, err := NewTrack(&dir)
+	if err != nil {
+		b.Fatal("error creating Track instance")
+	}
+	project := NewProject("test", "", "T", []string{}, 0, 15)
+	if err := track.SaveProject(project, false); err != nil {
+		b.Fatal("error saving project")
+	}
+
+	step := 10 * time.Hour / time.Duration(perDay)
+	start := util.Date(2000, 1, 1)
+	end := start.AddDate(years, 0, 0)
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		curr := day.Add(8 * time.Hour)
+		for i := 0; i < perDay; i++ {
+			rec := timedRecord(curr, step*3/4, rand.Intn(3), rand.Intn(3))
+			rec.Tags, _ = ExtractTags(rec.Note)
+			if err := track.SaveRecord(&rec, false); err != nil {
+				b.Fatal("error saving record")
+			}
+			curr = curr.Add(step)
+		}
+	}
+
+	b.ResetTimer()
+	return &track
+}
+
+func BenchmarkLoadYear(b *testing.B) {
+	track := setupBenchmark(b, 1, 8)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := track.LoadAllRecords(); err != nil {
+			b.Fatal("error loading records")
+		}
+	}
+}
+
+func BenchmarkLoadYearReversed(b *testing.B) {
+	track := setupBenchmark(b, 1, 8)
+
+	for i := 0; i < b.N; i++ {
+		fn, results, _ := track.AllRecordsFiltered(FilterFunctions{}, true)
+		go fn()
+		for range results {
+		}
+	}
+}
+
+func BenchmarkLoadMonthOfYears(b *testing.B) {
+	track := setupBenchmark(b, 3, 8)
+	filters := NewFilter([]FilterFunction{}, util.Date(2001, 6, 1), util.Date(2001, 7, 1))
+
+	for i := 0; i < b.N; i++ {
+		if _, err := track.LoadAllRecordsFiltered(filters); err != nil {
+			b.Fatal("error loading records")
+		}
+	}
+}
+
+func BenchmarkFilterTagsYear(b *testing.B) {
+	track := setupBenchmark(b, 1, 8)
+	filters := NewFilter(
+		[]FilterFunction{
+			FilterByTagsAny([]util.Pair[string, string]{util.NewPair("amet", "")}),
+		}, util.NoTime, util.NoTime,
+	)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := track.LoadAllRecordsFiltered(filters); err != nil {
+			b.Fatal("error loading records")
+		}
+	}
+}
+
+func BenchmarkLoadRecordsPage(b *testing.B) {
+	track := setupBenchmark(b, 1, 8)
+
+	for i := 0; i < b.N; i++ {
+		if _, _, err := track.LoadRecordsPage(FilterFunctions{}, "", 50); err != nil {
+			b.Fatal("error loading page")
+		}
+	}
+}
+
+func BenchmarkReporterYear(b *testing.B) {
+	track := setupBenchmark(b, 1, 8)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := NewReporter(track, []string{}, FilterFunctions{}, false, util.NoTime, util.NoTime); err != nil {
+			b.Fatal("error creating reporter")
+		}
+	}
+}
+
+func BenchmarkStreamingReporterYear(b *testing.B) {
+	track := setupBenchmark(b, 1, 8)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := NewStreamingReporter(track, []string{}, FilterFunctions{}, false, util.NoTime, util.NoTime); err != nil {
+			b.Fatal("error creating reporter")
+		}
+	}
+}
+
+func BenchmarkCachedReporterYear(b *testing.B) {
+	track := setupBenchmark(b, 1, 8)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := NewCachedReporter(track, []string{}, FilterFunctions{}, false, util.NoTime, util.NoTime); err != nil {
+			b.Fatal("error creating reporter")
+		}
+	}
+}
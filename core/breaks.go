@@ -0,0 +1,145 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"gopkg.in/yaml.v3"
+)
+
+// Break is a break between records, like lunch or a commute.
+// Breaks are not records, and are thus not counted for any project.
+// They explain gaps between records in day reports and the status.
+type Break struct {
+	// Start of the break
+	Start time.Time `yaml:"start"`
+	// End of the break
+	End time.Time `yaml:"end"`
+	// Note of the break, like "lunch"
+	Note string `yaml:"note,omitempty"`
+}
+
+// NewBreak creates a new break
+func NewBreak(start, end time.Time, note string) (Break, error) {
+	b := Break{Start: start, End: end, Note: strings.TrimSpace(note)}
+	if err := b.Check(); err != nil {
+		return Break{}, err
+	}
+	return b, nil
+}
+
+// Check checks the time span and note of a break
+func (b *Break) Check() error {
+	if !b.End.After(b.Start) {
+		return fmt.Errorf("break must end after its start (%s - %s)", b.Start.Format(util.DateTimeFormat), b.End.Format(util.DateTimeFormat))
+	}
+	if strings.ContainsAny(b.Note, "\n\r") {
+		return fmt.Errorf("break note must be single-line")
+	}
+	return nil
+}
+
+// Duration returns the duration of the break, clipped to the given time span.
+// Min and max may be zero for no limit.
+func (b *Break) Duration(min, max time.Time) time.Duration {
+	return util.DurationClip(b.Start, b.End, min, max)
+}
+
+// Breaks are all breaks of a workspace
+type Breaks struct {
+	Breaks []Break `yaml:"breaks"`
+}
+
+// LoadBreaks loads the breaks of the current workspace.
+// Returns no breaks if there are none yet.
+func (t *Track) LoadBreaks() (Breaks, error) {
+	data, err := t.FileSystem().ReadFile(t.BreaksPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Breaks{Breaks: []Break{}}, nil
+		}
+		return Breaks{}, err
+	}
+	var breaks Breaks
+	if err := yaml.Unmarshal(data, &breaks); err != nil {
+		return Breaks{}, fmt.Errorf("invalid breaks file: %s", err)
+	}
+	if breaks.Breaks == nil {
+		breaks.Breaks = []Break{}
+	}
+	for i := range breaks.Breaks {
+		if err := breaks.Breaks[i].Check(); err != nil {
+			return Breaks{}, fmt.Errorf("invalid breaks file: %s", err)
+		}
+	}
+	return breaks, nil
+}
+
+// SaveBreaks saves the breaks of the current workspace, replacing the file atomically
+func (t *Track) SaveBreaks(breaks *Breaks) error {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(breaks)
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf("%s Breaks\n\n%s", YamlCommentPrefix, data)
+
+	path := t.BreaksPath()
+	tempPath := path + ".tmp"
+	if err := t.FileSystem().WriteFile(tempPath, []byte(content), 0600); err != nil {
+		return err
+	}
+	return t.FileSystem().Rename(tempPath, path)
+}
+
+// Add adds a break. Fails if it overlaps an existing break.
+func (b *Breaks) Add(br Break) error {
+	for _, other := range b.Breaks {
+		if br.Start.Before(other.End) && other.Start.Before(br.End) {
+			return fmt.Errorf("break overlaps break at %s", other.Start.Format(util.DateTimeFormat))
+		}
+	}
+	b.Breaks = append(b.Breaks, br)
+	sort.SliceStable(b.Breaks, func(i, j int) bool { return b.Breaks[i].Start.Before(b.Breaks[j].Start) })
+	return nil
+}
+
+// Remove removes the break that includes the given time
+func (b *Breaks) Remove(tm time.Time) (Break, error) {
+	for i, br := range b.Breaks {
+		if !tm.Before(br.Start) && tm.Before(br.End) {
+			b.Breaks = append(b.Breaks[:i], b.Breaks[i+1:]...)
+			return br, nil
+		}
+	}
+	return Break{}, fmt.Errorf("no break at %s", tm.Format(util.DateTimeFormat))
+}
+
+// Between returns all breaks that overlap the time span from start to end, in chronological order.
+// Start and end may be zero for no limit.
+func (b *Breaks) Between(start, end time.Time) []Break {
+	result := []Break{}
+	for _, br := range b.Breaks {
+		if (!start.IsZero() && !br.End.After(start)) || (!end.IsZero() && !br.Start.Before(end)) {
+			continue
+		}
+		result = append(result, br)
+	}
+	return result
+}
+
+// TotalTime returns the total time of all breaks, clipped to the time span from start to end.
+// Start and end may be zero for no limit.
+func (b *Breaks) TotalTime(start, end time.Time) time.Duration {
+	total := time.Duration(0)
+	for _, br := range b.Between(start, end) {
+		total += br.Duration(start, end)
+	}
+	return total
+}
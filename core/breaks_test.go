@@ -0,0 +1,74 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaks(t *testing.T) {
+	day := time.Date(2023, 5, 2, 0, 0, 0, 0, time.Local)
+	at := func(hour, min int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+	}
+
+	_, err := NewBreak(at(12, 0), at(12, 0), "lunch")
+	assert.NotNil(t, err, "Expected error for empty break")
+	_, err = NewBreak(at(12, 0), at(13, 0), "lunch\nbreak")
+	assert.NotNil(t, err, "Expected error for multi-line note")
+
+	lunch, err := NewBreak(at(12, 0), at(12, 45), " lunch ")
+	assert.Nil(t, err)
+	assert.Equal(t, "lunch", lunch.Note)
+	commute, err := NewBreak(at(7, 30), at(8, 0), "commute")
+	assert.Nil(t, err)
+
+	breaks := Breaks{Breaks: []Break{}}
+	assert.Nil(t, breaks.Add(lunch))
+	assert.Nil(t, breaks.Add(commute))
+	assert.Equal(t, []Break{commute, lunch}, breaks.Breaks, "Breaks must be sorted")
+
+	overlap, err := NewBreak(at(12, 30), at(13, 0), "")
+	assert.Nil(t, err)
+	assert.NotNil(t, breaks.Add(overlap), "Expected error for overlapping break")
+
+	assert.Equal(t, []Break{lunch}, breaks.Between(at(9, 0), at(24, 0)))
+	assert.Equal(t, []Break{commute, lunch}, breaks.Between(time.Time{}, time.Time{}))
+	assert.Equal(t, 75*time.Minute, breaks.TotalTime(day, day.AddDate(0, 0, 1)))
+	assert.Equal(t, 45*time.Minute, breaks.TotalTime(at(7, 45), at(12, 30)))
+
+	_, err = breaks.Remove(at(10, 0))
+	assert.NotNil(t, err, "Expected error for no break")
+	removed, err := breaks.Remove(at(12, 10))
+	assert.Nil(t, err)
+	assert.Equal(t, lunch, removed)
+	assert.Equal(t, []Break{commute}, breaks.Breaks)
+}
+
+func TestLoadSaveBreaks(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	breaks, err := track.LoadBreaks()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(breaks.Breaks))
+
+	start := time.Date(2023, 5, 2, 12, 0, 0, 0, time.Local)
+	lunch, err := NewBreak(start, start.Add(45*time.Minute), "lunch")
+	assert.Nil(t, err)
+	assert.Nil(t, breaks.Add(lunch))
+	assert.Nil(t, track.SaveBreaks(&breaks))
+
+	loaded, err := track.LoadBreaks()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(loaded.Breaks))
+	assert.True(t, lunch.Start.Equal(loaded.Breaks[0].Start))
+	assert.True(t, lunch.End.Equal(loaded.Breaks[0].End))
+	assert.Equal(t, "lunch", loaded.Breaks[0].Note)
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// BulkEditFunc changes a record in a bulk edit. Returns whether the record was changed.
+type BulkEditFunc = func(r *Record) (bool, error)
+
+// BulkEdit applies fn to all records that match the filters, and saves the records that were changed.
+//
+// The edit is all-or-nothing. All records are changed in memory first,
+// and nothing is saved if fn fails for any record, or if any changed record is invalid.
+// Changed records are invalid if they are locked, if their start time was changed,
+// if they were moved to a project that does not exist, if they fail Record.Check,
+// or if they overlap other records.
+// Changed records are saved in a single Transaction,
+// so the original records are restored if saving fails.
+// Records of archived projects are only edited if FilterFunctions.IncludeArchived is set.
+//
+// Returns the number of changed records.
+func (t *Track) BulkEdit(filters FilterFunctions, fn BulkEditFunc) (int, error) {
+	if err := t.CheckWritable(); err != nil {
+		return 0, err
+	}
+	if err := t.RecoverJournal(); err != nil {
+		return 0, err
+	}
+
+	records, err := t.LoadAllRecordsFiltered(filters)
+	if err != nil {
+		return 0, err
+	}
+	projects, err := t.LoadAllProjects()
+	if err != nil {
+		return 0, err
+	}
+
+	changed := []Record{}
+	for i := range records {
+		original := &records[i]
+		record := original.copy()
+		ok, err := fn(&record)
+		if err != nil {
+			return 0, fmt.Errorf("record %s: %s", original.Start.Format(util.DateTimeFormat), err)
+		}
+		if !ok {
+			continue
+		}
+		if !record.Start.Equal(original.Start) {
+			return 0, fmt.Errorf("record %s: start time can't be changed in bulk edits", original.Start.Format(util.DateTimeFormat))
+		}
+		if changesLocked(original, &record) {
+			return 0, fmt.Errorf("record %s: %w", original.Start.Format(util.DateTimeFormat), ErrRecordLocked)
+		}
+		project, ok := projects[record.Project]
+		if !ok && record.Project != original.Project {
+			return 0, fmt.Errorf("record %s: %w", original.Start.Format(util.DateTimeFormat), &ProjectNotFoundError{Name: record.Project})
+		}
+		if err := record.Check(&project); err != nil {
+			return 0, fmt.Errorf("record %s: %s", original.Start.Format(util.DateTimeFormat), err)
+		}
+		if !record.End.Equal(original.End) {
+			if err := t.checkOverlap(&record); err != nil {
+				return 0, err
+			}
+		}
+		changed = append(changed, record)
+	}
+
+	tx := t.Begin()
+	for i := range changed {
+		tx.Save(&changed[i], true)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(changed), nil
+}
+
+// checkOverlap checks if a record overlaps stored records, except a stored version of the record itself.
+// Used where start times can't be changed, like in bulk edits, so only the stored records need to be considered.
+func (t *Track) checkOverlap(record *Record) error {
+	end := record.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if !end.After(record.Start) {
+		return nil
+	}
+	overlapping, err := t.RecordsOverlapping(record.Start, end)
+	if err != nil {
+		return err
+	}
+	for _, other := range overlapping {
+		if !other.Start.Equal(record.Start) {
+			return fmt.Errorf("record %s: %w with record %s",
+				record.Start.Format(util.DateTimeFormat), ErrOverlap, other.Start.Format(util.DateTimeFormat))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkEdit(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+	for _, name := range []string{"test", "other"} {
+		assert.Nil(t, track.SaveProject(NewProject(name, "", name[:1], []string{}, 0, 0), false), "Error saving project")
+	}
+
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0), Tags: map[string]string{}, Pause: []Pause{}},
+		{Project: "test", Start: util.DateTime(2001, 2, 4, 8, 0, 0), End: util.DateTime(2001, 2, 4, 9, 0, 0), Tags: map[string]string{}, Pause: []Pause{}},
+		{Project: "other", Start: util.DateTime(2001, 2, 5, 8, 0, 0), End: util.DateTime(2001, 2, 5, 9, 0, 0), Tags: map[string]string{}, Pause: []Pause{}},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false), "Error saving record")
+	}
+	filters := NewFilter([]FilterFunction{FilterByProjects([]string{"test"})}, util.NoTime, util.NoTime)
+
+	// Errors leave all records unchanged
+	calls := 0
+	_, err = track.BulkEdit(filters, func(r *Record) (bool, error) {
+		calls++
+		if calls == 2 {
+			return false, fmt.Errorf("test error")
+		}
+		r.Project = "other"
+		return true, nil
+	})
+	assert.NotNil(t, err, "Expected error")
+	_, err = track.BulkEdit(filters, func(r *Record) (bool, error) {
+		r.Project = "missing"
+		return true, nil
+	})
+	assert.NotNil(t, err, "Expected error for missing project")
+	_, err = track.BulkEdit(filters, func(r *Record) (bool, error) {
+		r.Start = r.Start.Add(1)
+		return true, nil
+	})
+	assert.NotNil(t, err, "Expected error for changed start time")
+	_, err = track.BulkEdit(filters, func(r *Record) (bool, error) {
+		r.End = r.Start.Add(-time.Hour)
+		return true, nil
+	})
+	assert.NotNil(t, err, "Expected error for end before start")
+	_, err = track.BulkEdit(filters, func(r *Record) (bool, error) {
+		r.End = r.Start.Add(25 * time.Hour)
+		return true, nil
+	})
+	assert.True(t, errors.Is(err, ErrOverlap), "Expected error for overlapping records")
+
+	loaded, err := track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, records, loaded, "Records should be unchanged")
+
+	count, err := track.BulkEdit(filters, func(r *Record) (bool, error) {
+		r.Project = "other"
+		r.Tags["moved"] = ""
+		r.Note = "+moved"
+		return true, nil
+	})
+	assert.Nil(t, err, "Error in bulk edit")
+	assert.Equal(t, 2, count, "Wrong number of changed records")
+	assert.False(t, util.FileExists(track.JournalPath()), "Journal should be removed")
+
+	loaded, err = track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, "other", loaded[i].Project, "Wrong project")
+		assert.Equal(t, map[string]string{"moved": ""}, loaded[i].Tags, "Wrong tags")
+	}
+	assert.Equal(t, records[2], loaded[2], "Record should be unchanged")
+}
+
+func TestRecoverJournal(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+
+	original := Record{Project: "test", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0), Tags: map[string]string{}, Pause: []Pause{}}
+	assert.Nil(t, track.SaveRecord(&original, false), "Error saving record")
+
+	// Simulate a bulk edit that was interrupted after saving a record
+	assert.Nil(t, track.writeJournal([]journalEntry{{Record: original, Format: FileFormatText}}), "Error writing journal")
+	changed := original.copy()
+	changed.Project = "other"
+	assert.Nil(t, track.SaveRecord(&changed, true), "Error saving record")
+
+	assert.Nil(t, track.RecoverJournal(), "Error recovering journal")
+	assert.False(t, util.FileExists(track.JournalPath()), "Journal should be removed")
+
+	loaded, err := track.LoadRecord(original.Start)
+	assert.Nil(t, err, "Error loading record")
+	assert.Equal(t, original, loaded, "Record should be restored")
+
+	assert.Nil(t, track.RecoverJournal(), "Error recovering without journal")
+}
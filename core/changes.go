@@ -0,0 +1,392 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// maxChangeClockSkew is the maximum time by which changes from other devices may be in the future
+const maxChangeClockSkew = 5 * time.Minute
+
+// ErrInvalidChange is an error for a change from another device that is rejected by ApplyChanges
+var ErrInvalidChange = errors.New("invalid change")
+
+// RecordChange is a change of a record, for incremental synchronization
+type RecordChange struct {
+	// ID of the record, see RecordID
+	ID string `json:"id"`
+	// Time of the last modification or of the deletion
+	Modified time.Time `json:"modified"`
+	// Whether the record was deleted
+	Deleted bool `json:"deleted,omitempty"`
+	// The changed record. Nil for deletions
+	Record *Record `json:"record,omitempty"`
+}
+
+// RejectedChange is a change that was skipped by ApplyChanges, as the record's project does not exist
+type RejectedChange struct {
+	// ID of the record, see RecordID
+	ID string `json:"id"`
+	// Reason for the rejection
+	Reason string `json:"reason"`
+}
+
+// RecordID returns the ID of the record starting at the given time, resembling the record's relative path.
+// Records are unique by start time within a workspace.
+func RecordID(start time.Time) string {
+	return start.Format(recordCursorFormat)
+}
+
+// ParseRecordID returns the start time of the record with the given ID
+func ParseRecordID(id string) (time.Time, error) {
+	return time.ParseInLocation(recordCursorFormat, id, time.Local)
+}
+
+// recordModTime returns the modification time of the file of the record starting at the given time.
+// Returns false if there is no such record.
+func (t *Track) recordModTime(start time.Time) (time.Time, bool, error) {
+	format, err := t.findRecordFile(start)
+	if err != nil || format == "" {
+		return time.Time{}, false, err
+	}
+	info, err := t.FileSystem().Stat(t.recordFilePath(start, format))
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return info.ModTime(), true, nil
+}
+
+// deletion is an entry in the deletion log of a workspace
+type deletion struct {
+	// ID of the deleted record, see RecordID
+	ID string `json:"id"`
+	// Time of deletion
+	Deleted time.Time `json:"deleted"`
+}
+
+// logDeletion appends the deletion of the record starting at the given time to the append-only deletion log.
+// The log is kept independent of the trash, so that deletions can be synchronized even if the trash is disabled.
+func (t *Track) logDeletion(start time.Time, deleted time.Time) error {
+	line, err := json.Marshal(&deletion{ID: RecordID(start), Deleted: deleted})
+	if err != nil {
+		return err
+	}
+	return t.FileSystem().AppendFile(t.DeletionsPath(), append(line, '\n'), 0600)
+}
+
+// deletions returns the latest deletion of each record from the deletion log, in the order of the log
+func (t *Track) deletions() ([]deletion, error) {
+	file, err := t.FileSystem().ReadFile(t.DeletionsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []deletion{}, nil
+		}
+		return nil, err
+	}
+
+	entries := []deletion{}
+	index := map[string]int{}
+	scanner := bufio.NewScanner(bytes.NewReader(file))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry deletion
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("invalid entry in deletion log: %s", err)
+		}
+		entry.Deleted = entry.Deleted.Local()
+		if i, ok := index[entry.ID]; ok {
+			if entry.Deleted.After(entries[i].Deleted) {
+				entries[i] = entry
+			}
+			continue
+		}
+		index[entry.ID] = len(entries)
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RecordChanges returns all changes of records in the current workspace after the given time.
+// Changes are derived from the modification times of record files, and from the deletion log for deletions.
+// Deletions of records that were re-created since are not reported.
+// For records in JSON Lines day files, all records of a modified day are reported.
+func (t *Track) RecordChanges(since time.Time) ([]RecordChange, error) {
+	changes := []RecordChange{}
+
+	var changesErr error
+	err := t.walkDays(FilterFunctions{}, false, func(date time.Time) bool {
+		records, err := t.readDayRecords(date)
+		if err != nil {
+			changesErr = err
+			return false
+		}
+		for i := range records {
+			modified, ok, err := t.recordModTime(records[i].Start)
+			if err != nil {
+				changesErr = err
+				return false
+			}
+			if !ok || !modified.After(since) {
+				continue
+			}
+			changes = append(changes, RecordChange{
+				ID:       RecordID(records[i].Start),
+				Modified: modified,
+				Record:   &records[i],
+			})
+		}
+		return true
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return changes, err
+	}
+	if changesErr != nil {
+		return changes, changesErr
+	}
+
+	deleted, err := t.deletions()
+	if err != nil {
+		return changes, err
+	}
+	for _, entry := range deleted {
+		if !entry.Deleted.After(since) {
+			continue
+		}
+		start, err := ParseRecordID(entry.ID)
+		if err != nil {
+			return changes, fmt.Errorf("invalid entry in deletion log: %s", err)
+		}
+		_, exists, err := t.recordModTime(start)
+		if err != nil {
+			return changes, err
+		}
+		if exists {
+			continue
+		}
+		changes = append(changes, RecordChange{
+			ID:       entry.ID,
+			Modified: entry.Deleted,
+			Deleted:  true,
+		})
+	}
+
+	return changes, nil
+}
+
+// ApplyChanges applies changes of records, e.g. from another device.
+//
+// A change is only applied if it is newer than the local version of the record (last writer wins).
+// The modification times of changed record files are set to the time of the change,
+// so that applied changes are not reported as local changes by RecordChanges.
+//
+// All changes are validated before any change is applied, see checkChange.
+// Changes of records in projects that don't exist are skipped, and returned as rejected changes.
+// Locked records can't be changed or deleted.
+// Changed records must not overlap each other, or records that remain after the changes.
+// This way, records with a changed start time can be applied as a new record and a deletion, in any order.
+// Fails with an error wrapping ErrInvalidChange for the first invalid change.
+//
+// Changes are applied all-or-nothing, in a single Transaction, with deletions before saves.
+//
+// Returns the number of applied changes, and the rejected changes.
+func (t *Track) ApplyChanges(changes []RecordChange) (int, []RejectedChange, error) {
+	if err := t.CheckWritable(); err != nil {
+		return 0, nil, err
+	}
+	if err := t.RecoverJournal(); err != nil {
+		return 0, nil, err
+	}
+	projects, err := t.LoadAllProjects()
+	if err != nil {
+		return 0, nil, err
+	}
+	now := time.Now()
+	var rejected []RejectedChange
+	accepted := make([]*RecordChange, 0, len(changes))
+	for i := range changes {
+		if err := checkChange(&changes[i], projects, now); err != nil {
+			var notFound *ProjectNotFoundError
+			if errors.As(err, &notFound) {
+				rejected = append(rejected, RejectedChange{ID: changes[i].ID, Reason: err.Error()})
+				continue
+			}
+			return 0, nil, fmt.Errorf("%w %s: %s", ErrInvalidChange, changes[i].ID, err)
+		}
+		accepted = append(accepted, &changes[i])
+	}
+
+	tx := t.Begin()
+	saves := []*RecordChange{}
+	// Stored records deleted by the changes, by start time in nanoseconds
+	removed := map[int64]bool{}
+	for _, change := range accepted {
+		var start time.Time
+		if change.Deleted {
+			start, _ = ParseRecordID(change.ID)
+		} else {
+			start = change.Record.Start
+		}
+		modified, exists, err := t.recordModTime(start)
+		if err != nil {
+			return 0, nil, err
+		}
+		if exists && !change.Modified.After(modified) {
+			continue
+		}
+
+		if change.Deleted {
+			if !exists || removed[start.UnixNano()] {
+				continue
+			}
+			record, err := t.loadRecordAt(start)
+			if err != nil {
+				return 0, nil, err
+			}
+			if record.Locked {
+				return 0, nil, fmt.Errorf("%w %s: %s", ErrInvalidChange, change.ID, ErrRecordLocked)
+			}
+			tx.deleteAt(&record, change.Modified)
+			removed[start.UnixNano()] = true
+			continue
+		}
+
+		if exists {
+			local, err := t.loadRecordAt(start)
+			if err != nil {
+				return 0, nil, err
+			}
+			if SerializeRecord(&local, util.NoTime) == SerializeRecord(change.Record, util.NoTime) {
+				continue
+			}
+			if changesLocked(&local, change.Record) {
+				return 0, nil, fmt.Errorf("%w %s: %s", ErrInvalidChange, change.ID, ErrRecordLocked)
+			}
+		}
+		saves = append(saves, change)
+	}
+	if err := t.checkChangesOverlap(saves, removed); err != nil {
+		return 0, nil, err
+	}
+
+	// Modification times to set after saving, by file path
+	modTimes := map[string]time.Time{}
+	for _, change := range saves {
+		tx.Save(change.Record, true)
+
+		path := t.recordFilePath(change.Record.Start, t.Config.RecordFileFormat)
+		modTime := change.Modified
+		if t.Config.RecordFileFormat == FileFormatJSON {
+			// Don't hide newer changes of other records in the same day file
+			if info, err := t.FileSystem().Stat(path); err == nil && info.ModTime().After(modTime) {
+				modTime = info.ModTime()
+			}
+		}
+		if modTime.After(modTimes[path]) {
+			modTimes[path] = modTime
+		}
+	}
+	counter := tx.Len()
+	if err := tx.Commit(); err != nil {
+		return 0, nil, err
+	}
+	for path, modTime := range modTimes {
+		if err := t.FileSystem().Chtimes(path, modTime, modTime); err != nil {
+			return counter, rejected, err
+		}
+	}
+	return counter, rejected, nil
+}
+
+// checkChangesOverlap checks if changed records overlap each other,
+// or stored records that are neither removed nor replaced by the changes.
+// Removed records are given by start time in nanoseconds.
+func (t *Track) checkChangesOverlap(changes []*RecordChange, removed map[int64]bool) error {
+	end := func(r *Record) time.Time {
+		if r.End.IsZero() {
+			return time.Now()
+		}
+		return r.End
+	}
+
+	replaced := map[int64]bool{}
+	for _, change := range changes {
+		replaced[change.Record.Start.UnixNano()] = true
+	}
+	for _, change := range changes {
+		record := change.Record
+		if !end(record).After(record.Start) {
+			continue
+		}
+		overlapping, err := t.RecordsOverlapping(record.Start, end(record))
+		if err != nil {
+			return err
+		}
+		for _, other := range overlapping {
+			key := other.Start.UnixNano()
+			if removed[key] || replaced[key] {
+				continue
+			}
+			return fmt.Errorf("%w %s: %s with record %s",
+				ErrInvalidChange, change.ID, ErrOverlap, other.Start.Format(util.DateTimeFormat))
+		}
+	}
+
+	sorted := make([]*RecordChange, len(changes))
+	copy(sorted, changes)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Record.Start.Before(sorted[j].Record.Start) })
+	var latest *Record
+	for _, change := range sorted {
+		record := change.Record
+		if latest != nil && !record.Start.Equal(latest.Start) && record.Start.Before(end(latest)) {
+			return fmt.Errorf("%w %s: %s with record %s",
+				ErrInvalidChange, change.ID, ErrOverlap, latest.Start.Format(util.DateTimeFormat))
+		}
+		if latest == nil || end(record).After(end(latest)) {
+			latest = record
+		}
+	}
+	return nil
+}
+
+// checkChange checks a change from another device, independent of the local records.
+// The record of the change is converted to local time.
+//
+// The time of the change must not be in the future, beyond a small clock skew.
+// Changed records must match the change's ID, their project must exist, and they must pass Record.Check.
+func checkChange(change *RecordChange, projects map[string]Project, now time.Time) error {
+	if change.Modified.After(now.Add(maxChangeClockSkew)) {
+		return fmt.Errorf("modification time %s is in the future", change.Modified.Format(time.RFC3339))
+	}
+	if change.Deleted {
+		if _, err := ParseRecordID(change.ID); err != nil {
+			return err
+		}
+		return nil
+	}
+	if change.Record == nil {
+		return fmt.Errorf("no record")
+	}
+	change.Record.toLocal()
+	if RecordID(change.Record.Start) != change.ID {
+		return fmt.Errorf("record starts at %s", change.Record.Start.Format(util.DateTimeFormat))
+	}
+	project, ok := projects[change.Record.Project]
+	if !ok {
+		return &ProjectNotFoundError{Name: change.Record.Project}
+	}
+	return change.Record.Check(&project)
+}
@@ -0,0 +1,243 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordChanges(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	start := time.Now().Add(-time.Second)
+
+	record1 := Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	record2 := Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 4, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 4, 5, 5, 0),
+	}
+	assert.Nil(t, track.SaveRecord(&record1, false))
+	assert.Nil(t, track.SaveRecord(&record2, false))
+
+	changes, err := track.RecordChanges(start)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, "2001/02/03/04-05", changes[0].ID)
+
+	changes, err = track.RecordChanges(time.Now().Add(time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(changes))
+
+	assert.Nil(t, track.DeleteRecord(&record2))
+	changes, err = track.RecordChanges(start)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(changes))
+	assert.True(t, changes[1].Deleted)
+	assert.Equal(t, "2001/02/04/04-05", changes[1].ID)
+
+	// Deletions are reported without the trash
+	track.Config.TrashDays = 0
+	assert.Nil(t, track.DeleteRecord(&record1))
+	changes, err = track.RecordChanges(start)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(changes))
+	assert.True(t, changes[0].Deleted && changes[1].Deleted)
+
+	// Deletions of re-created records are not reported
+	assert.Nil(t, track.SaveRecord(&record2, false))
+	changes, err = track.RecordChanges(start)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, "2001/02/03/04-05", changes[1].ID)
+	assert.True(t, changes[1].Deleted)
+	assert.False(t, changes[0].Deleted)
+}
+
+func TestApplyChanges(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	assert.Nil(t, track.SaveProject(NewProject("test", "", "t", []string{}, 15, 0), false))
+
+	record := Record{
+		Project: "test",
+		Note:    "local",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	assert.Nil(t, track.SaveRecord(&record, false))
+
+	remote := record
+	remote.Note = "remote"
+
+	old := time.Now().Add(-time.Hour)
+	cnt, _, err := track.ApplyChanges([]RecordChange{{ID: RecordID(remote.Start), Modified: old, Record: &remote}})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, cnt, "Older change should not be applied")
+
+	modified := time.Now().Add(time.Minute)
+	cnt, _, err = track.ApplyChanges([]RecordChange{{ID: RecordID(remote.Start), Modified: modified, Record: &remote}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cnt)
+
+	loaded, err := track.LoadRecord(record.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "remote", loaded.Note)
+
+	changes, err := track.RecordChanges(modified)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(changes), "Applied change should not be reported as local change")
+
+	deleted := modified.Add(time.Minute)
+	cnt, _, err = track.ApplyChanges([]RecordChange{{ID: RecordID(remote.Start), Modified: deleted, Deleted: true}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cnt)
+
+	_, err = track.LoadRecord(record.Start)
+	assert.NotNil(t, err, "Record should be deleted")
+
+	track.ReadOnly = true
+	_, _, err = track.ApplyChanges([]RecordChange{})
+	assert.ErrorIs(t, err, ErrReadOnly)
+}
+
+func TestApplyChangesInvalid(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+	assert.Nil(t, track.SaveProject(NewProject("test", "", "t", []string{}, 15, 0), false))
+
+	existing := Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 0, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 0, 0),
+	}
+	locked := Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 4, 4, 0, 0),
+		End:     util.DateTime(2001, 2, 4, 5, 0, 0),
+		Locked:  true,
+	}
+	assert.Nil(t, track.SaveRecord(&existing, false))
+	assert.Nil(t, track.SaveRecord(&locked, false))
+
+	modified := time.Now().Add(time.Minute)
+	change := func(r Record) RecordChange {
+		return RecordChange{ID: RecordID(r.Start), Modified: modified, Record: &r}
+	}
+	valid := Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 5, 4, 0, 0),
+		End:     util.DateTime(2001, 2, 5, 5, 0, 0),
+	}
+	unknownProject := valid
+	unknownProject.Project = "unknown"
+	endBeforeStart := valid
+	endBeforeStart.End = valid.Start.Add(-time.Hour)
+	overlapping := valid
+	overlapping.Start, overlapping.End = existing.Start.Add(30*time.Minute), existing.End.Add(30*time.Minute)
+	unlocked := locked
+	unlocked.Locked, unlocked.Note = false, "changed"
+	future := change(valid)
+	future.Modified = time.Now().Add(time.Hour)
+	wrongID := change(valid)
+	wrongID.ID = RecordID(existing.Start)
+
+	for name, changes := range map[string][]RecordChange{
+		"end before start": {change(valid), change(endBeforeStart)},
+		"overlap":          {change(overlapping)},
+		"locked":           {change(unlocked)},
+		"locked deletion":  {{ID: RecordID(locked.Start), Modified: modified, Deleted: true}},
+		"future":           {future},
+		"ID mismatch":      {wrongID},
+		"missing record":   {{ID: RecordID(valid.Start), Modified: modified}},
+		"invalid ID":       {{ID: "invalid", Modified: modified, Deleted: true}},
+	} {
+		_, _, err := track.ApplyChanges(changes)
+		assert.ErrorIs(t, err, ErrInvalidChange, "Expected invalid change for %s", name)
+	}
+
+	_, err = track.LoadRecord(valid.Start)
+	assert.NotNil(t, err, "No change should be applied if any is invalid")
+	loaded, err := track.LoadRecord(locked.Start)
+	assert.Nil(t, err)
+	assert.True(t, loaded.Locked, "Locked record should not be changed")
+
+	// Changes of records in unknown projects are skipped, without failing other changes
+	cnt, rejected, err := track.ApplyChanges([]RecordChange{change(valid), change(unknownProject)})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cnt)
+	assert.Equal(t, []RejectedChange{{ID: RecordID(unknownProject.Start), Reason: "project 'unknown' does not exist"}}, rejected)
+}
+
+func TestApplyChangesMovedStart(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+	assert.Nil(t, track.SaveProject(NewProject("test", "", "t", []string{}, 15, 0), false))
+
+	record := Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 0, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 0, 0),
+	}
+	other := Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 6, 0, 0),
+		End:     util.DateTime(2001, 2, 3, 7, 0, 0),
+	}
+	assert.Nil(t, track.SaveRecord(&record, false))
+	assert.Nil(t, track.SaveRecord(&other, false))
+
+	modified := time.Now().Add(time.Minute)
+	moved := record
+	moved.Start = record.Start.Add(30 * time.Minute)
+	moved.End = record.End.Add(30 * time.Minute)
+
+	// The moved record overlaps its old version, which is deleted by the same batch
+	cnt, _, err := track.ApplyChanges([]RecordChange{
+		{ID: RecordID(moved.Start), Modified: modified, Record: &moved},
+		{ID: RecordID(record.Start), Modified: modified, Deleted: true},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, cnt)
+
+	_, err = track.LoadRecord(record.Start)
+	assert.NotNil(t, err, "Old record should be deleted")
+	_, err = track.LoadRecord(moved.Start)
+	assert.Nil(t, err, "Moved record should be saved")
+
+	// Changed records must not overlap each other
+	first := Record{Project: "test", Start: util.DateTime(2001, 2, 4, 4, 0, 0), End: util.DateTime(2001, 2, 4, 6, 0, 0)}
+	second := Record{Project: "test", Start: util.DateTime(2001, 2, 4, 5, 0, 0), End: util.DateTime(2001, 2, 4, 7, 0, 0)}
+	_, _, err = track.ApplyChanges([]RecordChange{
+		{ID: RecordID(first.Start), Modified: modified, Record: &first},
+		{ID: RecordID(second.Start), Modified: modified, Record: &second},
+	})
+	assert.ErrorIs(t, err, ErrInvalidChange)
+
+	// A rejected batch leaves no change behind
+	movedAgain := moved
+	movedAgain.Start, movedAgain.End = other.Start.Add(-30*time.Minute), other.End.Add(-30*time.Minute)
+	_, _, err = track.ApplyChanges([]RecordChange{
+		{ID: RecordID(moved.Start), Modified: modified.Add(time.Minute), Deleted: true},
+		{ID: RecordID(movedAgain.Start), Modified: modified.Add(time.Minute), Record: &movedAgain},
+	})
+	assert.ErrorIs(t, err, ErrInvalidChange)
+	_, err = track.LoadRecord(moved.Start)
+	assert.Nil(t, err, "Deletion of a rejected batch should not be applied")
+}
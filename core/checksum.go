@@ -0,0 +1,261 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// checksumFile is the name of the file with checksums of the record files in a day directory
+	checksumFile = "checksums.sum"
+	// checksumKeyEnvVar is the environment variable for the key for HMAC checksums
+	checksumKeyEnvVar = "TRACK_CHECKSUM_KEY"
+
+	checksumSHA256 = "sha256"
+	checksumHMAC   = "hmac-sha256"
+)
+
+// ErrChecksumMismatch is an error for a record file that was modified outside of track
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ChecksumIssue describes a record file that failed checksum verification
+type ChecksumIssue struct {
+	Path    string
+	Problem string
+}
+
+// Problems of record files found by checksum verification
+const (
+	ChecksumModified = "modified"
+	ChecksumMissing  = "no checksum"
+	ChecksumDeleted  = "deleted"
+)
+
+// checksum calculates the checksum of the content of a file.
+// Uses HMAC-SHA256 if a key is given by environment variable TRACK_CHECKSUM_KEY, and SHA256 otherwise.
+func checksum(data []byte) string {
+	if key, ok := os.LookupEnv(checksumKeyEnvVar); ok && key != "" {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write(data)
+		return checksumHMAC + ":" + hex.EncodeToString(mac.Sum(nil))
+	}
+	sum := sha256.Sum256(data)
+	return checksumSHA256 + ":" + hex.EncodeToString(sum[:])
+}
+
+// readChecksums reads the checksums of a day directory, by file name
+func (t *Track) readChecksums(dir string) (map[string]string, error) {
+	sums := map[string]string{}
+	file, err := t.FileSystem().ReadFile(filepath.Join(dir, checksumFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sums, nil
+		}
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(file))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "  ", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid line in %s: '%s'", checksumFile, line)
+		}
+		sums[parts[1]] = parts[0]
+	}
+	return sums, scanner.Err()
+}
+
+// writeChecksums writes the checksums of a day directory. Removes the file if there are no checksums.
+func (t *Track) writeChecksums(dir string, sums map[string]string) error {
+	path := filepath.Join(dir, checksumFile)
+	if len(sums) == 0 {
+		err := t.FileSystem().Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := bytes.Buffer{}
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s  %s\n", sums[name], name)
+	}
+	return t.FileSystem().WriteFile(path, buf.Bytes(), 0600)
+}
+
+// updateChecksum sets the checksum of a record file, or removes it if data is nil
+func (t *Track) updateChecksum(path string, data []byte) error {
+	dir, name := filepath.Split(path)
+	sums, err := t.readChecksums(dir)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		delete(sums, name)
+	} else {
+		sums[name] = checksum(data)
+	}
+	return t.writeChecksums(dir, sums)
+}
+
+// verifyChecksum checks the content of a record file against its stored checksum.
+// Files without a stored checksum are not checked.
+func (t *Track) verifyChecksum(path string, data []byte) error {
+	dir, name := filepath.Split(path)
+	sums, err := t.readChecksums(dir)
+	if err != nil {
+		return err
+	}
+	if sum, ok := sums[name]; ok && sum != checksum(data) {
+		return fmt.Errorf("%w: file %s was modified", ErrChecksumMismatch, path)
+	}
+	return nil
+}
+
+// readRecordData reads a record file, and verifies its checksum if enabled in the config
+func (t *Track) readRecordData(path string) ([]byte, error) {
+	data, err := t.FileSystem().ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if t.Config.Checksums {
+		if err := t.verifyChecksum(path, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// writeRecordData writes a record file, and updates its checksum if enabled in the config
+func (t *Track) writeRecordData(path string, data []byte) error {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	tempPath := path + tempFileExt
+	if err := t.FileSystem().WriteFile(tempPath, data, 0600); err != nil {
+		return err
+	}
+	if err := t.FileSystem().Rename(tempPath, path); err != nil {
+		return err
+	}
+	if t.Config.Checksums {
+		return t.updateChecksum(path, data)
+	}
+	// Remove a stale checksum
+	return t.updateChecksum(path, nil)
+}
+
+// removeRecordData removes a record file and its checksum
+func (t *Track) removeRecordData(path string) error {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	if err := t.FileSystem().Remove(path); err != nil {
+		return err
+	}
+	return t.updateChecksum(path, nil)
+}
+
+// VerifyRecords checks all record files of the current workspace against their stored checksums.
+// Returns a list of files that were modified or deleted, or that have no checksum.
+func (t *Track) VerifyRecords() ([]ChecksumIssue, error) {
+	issues := []ChecksumIssue{}
+	var verifyErr error
+	err := t.walkDays(FilterFunctions{}, false, func(date time.Time) bool {
+		dir := t.RecordDir(date)
+		sums, err := t.readChecksums(dir)
+		if err != nil {
+			verifyErr = err
+			return false
+		}
+		files, err := t.FileSystem().ReadDir(dir)
+		if err != nil {
+			verifyErr = err
+			return false
+		}
+		for _, file := range files {
+			if file.IsDir() || recordFileFormat(file.Name()) == "" {
+				continue
+			}
+			path := filepath.Join(dir, file.Name())
+			sum, ok := sums[file.Name()]
+			delete(sums, file.Name())
+			if !ok {
+				issues = append(issues, ChecksumIssue{path, ChecksumMissing})
+				continue
+			}
+			data, err := t.FileSystem().ReadFile(path)
+			if err != nil {
+				verifyErr = err
+				return false
+			}
+			if sum != checksum(data) {
+				issues = append(issues, ChecksumIssue{path, ChecksumModified})
+			}
+		}
+		for name := range sums {
+			issues = append(issues, ChecksumIssue{filepath.Join(dir, name), ChecksumDeleted})
+		}
+		return true
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return issues, err
+	}
+	return issues, verifyErr
+}
+
+// UpdateChecksums stores the checksums of all record files of the current workspace,
+// accepting their current content.
+func (t *Track) UpdateChecksums() error {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	var updateErr error
+	err := t.walkDays(FilterFunctions{}, false, func(date time.Time) bool {
+		dir := t.RecordDir(date)
+		files, err := t.FileSystem().ReadDir(dir)
+		if err != nil {
+			updateErr = err
+			return false
+		}
+		sums := map[string]string{}
+		for _, file := range files {
+			if file.IsDir() || recordFileFormat(file.Name()) == "" {
+				continue
+			}
+			data, err := t.FileSystem().ReadFile(filepath.Join(dir, file.Name()))
+			if err != nil {
+				updateErr = err
+				return false
+			}
+			sums[file.Name()] = checksum(data)
+		}
+		if err := t.writeChecksums(dir, sums); err != nil {
+			updateErr = err
+			return false
+		}
+		return true
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return updateErr
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecksums(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	track.Config.Checksums = true
+
+	start := util.DateTime(2001, 2, 3, 8, 0, 0)
+	records := []Record{
+		{Project: "test", Start: start, End: start.Add(time.Hour)},
+		{Project: "test", Start: start.Add(2 * time.Hour), End: start.Add(3 * time.Hour)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false))
+	}
+
+	issues, err := track.VerifyRecords()
+	assert.Nil(t, err)
+	assert.Empty(t, issues)
+
+	_, err = track.LoadRecord(start)
+	assert.Nil(t, err)
+
+	path := track.RecordPath(start)
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(path, content[:len(content)-3], 0600))
+
+	_, err = track.LoadRecord(start)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	issues, err = track.VerifyRecords()
+	assert.Nil(t, err)
+	assert.Equal(t, []ChecksumIssue{{path, ChecksumModified}}, issues)
+
+	assert.Nil(t, track.UpdateChecksums())
+	issues, err = track.VerifyRecords()
+	assert.Nil(t, err)
+	assert.Empty(t, issues)
+
+	assert.Nil(t, track.DeleteRecord(&records[1]))
+	assert.Nil(t, track.DeleteRecord(&records[0]))
+	assert.False(t, util.FileExists(track.RecordDir(start)))
+}
+
+func TestChecksumHMAC(t *testing.T) {
+	data := []byte("08:00 - 09:00\n    test\n")
+	plain := checksum(data)
+
+	t.Setenv(checksumKeyEnvVar, "secret")
+	keyed := checksum(data)
+	assert.NotEqual(t, plain, keyed)
+	assert.Equal(t, keyed, checksum(data))
+
+	t.Setenv(checksumKeyEnvVar, "other")
+	assert.NotEqual(t, keyed, checksum(data))
+}
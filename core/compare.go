@@ -0,0 +1,74 @@
+package core
+
+import "time"
+
+// Delta is the time of a project or tag in two periods
+type Delta struct {
+	Current  time.Duration
+	Previous time.Duration
+}
+
+// Diff returns the absolute change from the previous to the current period
+func (d Delta) Diff() time.Duration {
+	return d.Current - d.Previous
+}
+
+// Percent returns the relative change from the previous to the current period, in percent.
+// Returns false if there is no time in the previous period.
+func (d Delta) Percent() (float64, bool) {
+	if d.Previous == 0 {
+		return 0, false
+	}
+	return 100 * float64(d.Current-d.Previous) / float64(d.Previous), true
+}
+
+// Comparison compares the times per project and tag of two periods
+type Comparison struct {
+	// Time per project, including sub-projects
+	Projects map[string]Delta
+	// Time per tag
+	Tags map[string]Delta
+	// Total time
+	Total Delta
+}
+
+// Compare compares the times of the reporter, as the current period,
+// to those of another reporter for the previous period.
+// Projects and tags without time in both periods are omitted.
+func (r *Reporter) Compare(previous *Reporter) Comparison {
+	c := Comparison{
+		Projects: map[string]Delta{},
+		Tags:     map[string]Delta{},
+	}
+
+	root := r.ProjectsTree.Root.Value.Name
+	addTimes(c.Projects, r.TotalTime, previous.TotalTime)
+	delete(c.Projects, root)
+	addTimes(c.Tags, r.TagTime, previous.TagTime)
+
+	for _, dur := range r.ProjectTime {
+		c.Total.Current += dur
+	}
+	for _, dur := range previous.ProjectTime {
+		c.Total.Previous += dur
+	}
+	return c
+}
+
+// addTimes adds the non-zero times of two periods to deltas
+func addTimes(deltas map[string]Delta, current, previous map[string]time.Duration) {
+	for name, dur := range current {
+		if dur > 0 {
+			d := deltas[name]
+			d.Current = dur
+			deltas[name] = d
+		}
+	}
+	for name, dur := range previous {
+		if dur > 0 {
+			d := deltas[name]
+			d.Previous = dur
+			deltas[name] = d
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReporterCompare(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err)
+
+	assert.Nil(t, track.SaveProject(NewProject("parent", "", "P", []string{}, 0, 15), false))
+	assert.Nil(t, track.SaveProject(NewProject("child", "parent", "C", []string{}, 0, 15), false))
+	assert.Nil(t, track.SaveProject(NewProject("other", "", "O", []string{}, 0, 15), false))
+
+	records := []Record{
+		{Project: "child", Start: util.DateTime(2001, 2, 1, 8, 0, 0), End: util.DateTime(2001, 2, 1, 12, 0, 0), Note: "+dev", Tags: map[string]string{"dev": ""}},
+		{Project: "other", Start: util.DateTime(2001, 2, 2, 8, 0, 0), End: util.DateTime(2001, 2, 2, 9, 0, 0)},
+		{Project: "child", Start: util.DateTime(2001, 2, 8, 8, 0, 0), End: util.DateTime(2001, 2, 8, 13, 0, 0), Note: "+dev", Tags: map[string]string{"dev": ""}},
+		{Project: "parent", Start: util.DateTime(2001, 2, 9, 8, 0, 0), End: util.DateTime(2001, 2, 9, 9, 0, 0), Note: "+meet", Tags: map[string]string{"meet": ""}},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false))
+	}
+
+	newReporter := func(start, end time.Time) *Reporter {
+		reporter, err := NewStreamingReporter(&track, []string{}, NewFilter([]FilterFunction{}, start, end), false, start, end)
+		assert.Nil(t, err)
+		return reporter
+	}
+	previous := newReporter(util.Date(2001, 2, 1), util.Date(2001, 2, 8))
+	current := newReporter(util.Date(2001, 2, 8), util.Date(2001, 2, 15))
+
+	c := current.Compare(previous)
+	assert.Equal(t, map[string]Delta{
+		"parent": {Current: 6 * time.Hour, Previous: 4 * time.Hour},
+		"child":  {Current: 5 * time.Hour, Previous: 4 * time.Hour},
+		"other":  {Current: 0, Previous: time.Hour},
+	}, c.Projects)
+	assert.Equal(t, map[string]Delta{
+		"dev":  {Current: 5 * time.Hour, Previous: 4 * time.Hour},
+		"meet": {Current: time.Hour, Previous: 0},
+	}, c.Tags)
+	assert.Equal(t, Delta{Current: 6 * time.Hour, Previous: 5 * time.Hour}, c.Total)
+
+	assert.Equal(t, time.Hour, c.Total.Diff())
+	percent, ok := c.Total.Percent()
+	assert.True(t, ok)
+	assert.InDelta(t, 20.0, percent, 0.001)
+	_, ok = c.Tags["meet"].Percent()
+	assert.False(t, ok)
+	percent, _ = c.Projects["other"].Percent()
+	assert.Equal(t, -100.0, percent)
+}
@@ -3,12 +3,13 @@ package core
 import (
 	"errors"
 	"fmt"
-	"os"
 	"runtime"
 	"strings"
 	"time"
 	"unicode/utf8"
 
+	"github.com/mlange-42/track/util"
+	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
 )
 
@@ -33,8 +34,71 @@ type Config struct {
 	RecordCell string `yaml:"recordCell"`
 	// Character for pause cells in day and week reports
 	PauseCell string `yaml:"pauseCell"`
+	// Character for break cells in day and week reports
+	BreakCell string `yaml:"breakCell"`
+	// Duration of a running record after which the daemon warns about it. Zero to disable
+	MaxRecordDuration time.Duration `yaml:"maxRecordDuration"`
+	// Whether the daemon stops records running longer than MaxRecordDuration
+	AutoStop bool `yaml:"autoStop"`
+	// Duration of an open pause after which the record is stopped at the start of the pause. Zero to disable
+	MaxPauseDuration time.Duration `yaml:"maxPauseDuration"`
+	// Time of day after which the daemon reminds about a running record, with overrides per weekday
+	StopReminder Reminder `yaml:"stopReminder"`
+	// Time of day by which the daemon reminds to start tracking on working days, with overrides per weekday
+	StartReminder Reminder `yaml:"startReminder"`
+	// Whether to cache aggregated times of past days for reports
+	ReportCache bool `yaml:"reportCache"`
+	// Handling of records spanning midnight. One of MidnightKeep, MidnightSplit
+	MidnightPolicy string `yaml:"midnightPolicy"`
+	// First month of the fiscal year, 1-12
+	FiscalYearStart int `yaml:"fiscalYearStart"`
+	// First day of the week, like "monday" or "sunday"
+	WeekStart string `yaml:"weekStart"`
+	// Date format for reports and exports. A preset like "iso", or a Go date layout
+	DateFormat string `yaml:"dateFormat"`
+	// Clock for reports and exports, "24h" or "12h"
+	Clock string `yaml:"clock"`
+	// Duration format for reports and exports, "clock", "decimal" or "industrial"
+	DurationFormat string `yaml:"durationFormat"`
+	// Format for saving record files. One of FileFormatText, FileFormatYAML, FileFormatJSON
+	RecordFileFormat string `yaml:"recordFormat"`
+	// Whether to reject malformed record files that would otherwise be read with a loss of data
+	StrictParsing bool `yaml:"strictParsing"`
+	// Whether to store checksums of record files, and verify them when loading
+	Checksums bool `yaml:"checksums"`
+	// Number of days deleted records are kept in the trash. Zero to delete records permanently
+	TrashDays int `yaml:"trashDays"`
+	// Whether to keep a history of all saved versions of records
+	History bool `yaml:"history"`
+	// Rules for deleting and anonymizing old records
+	Retention Retention `yaml:"retention"`
+	// Prefix of tags in record notes, like "+" or "#"
+	TagPrefix string `yaml:"tagPrefix"`
+	// Prefix of comments in record files. Must differ from TagPrefix
+	CommentPrefix string `yaml:"commentPrefix"`
+	// Normalization of tags when parsing notes and loading records
+	TagNormalization TagNormalization `yaml:"tagNormalization"`
+	// Allowed categories of records, like work or meeting. Empty to allow any category
+	Categories []string `yaml:"categories"`
+	// Daily working time goal, for goal streaks. Zero to disable
+	DailyGoal time.Duration `yaml:"dailyGoal"`
+	// Expected working time per weekday, like "monday: 8h", for comparing against tracked time
+	WorkSchedule map[string]time.Duration `yaml:"workSchedule,omitempty"`
+	// Track directories of team members by name, for combined team reports
+	Team map[string]string `yaml:"team,omitempty"`
+	// Default currency of rates, like EUR or USD
+	Currency string `yaml:"currency"`
+	// Value of one unit of each currency in a common reference currency, for converting earnings
+	ExchangeRates map[string]float64 `yaml:"exchangeRates,omitempty"`
 }
 
+const (
+	// MidnightKeep keeps records spanning midnight, and clips them by day in reports
+	MidnightKeep = "keep"
+	// MidnightSplit splits records spanning midnight into one record per day when stopping them
+	MidnightSplit = "split"
+)
+
 // defaultConfig creates a Config with default values
 func defaultConfig() Config {
 	var editor string
@@ -45,19 +109,45 @@ func defaultConfig() Config {
 	}
 
 	return Config{
-		Workspace:        defaultWorkspace,
-		TextEditor:       editor,
-		MaxBreakDuration: 2 * time.Hour,
-		EmptyCell:        ".",
-		RecordCell:       ":",
-		PauseCell:        "-",
+		Workspace:         defaultWorkspace,
+		TextEditor:        editor,
+		MaxBreakDuration:  2 * time.Hour,
+		EmptyCell:         ".",
+		RecordCell:        ":",
+		PauseCell:         "-",
+		BreakCell:         "~",
+		MaxRecordDuration: 10 * time.Hour,
+		AutoStop:          false,
+		MaxPauseDuration:  0,
+		ReportCache:       false,
+		MidnightPolicy:    MidnightKeep,
+		FiscalYearStart:   1,
+		WeekStart:         "monday",
+		DateFormat:        "iso",
+		Clock:             "24h",
+		DurationFormat:    string(util.DurationClock),
+		RecordFileFormat:  FileFormatText,
+		StrictParsing:     false,
+		Checksums:         false,
+		TrashDays:         30,
+		History:           false,
+		TagPrefix:         DefaultTagPrefix,
+		CommentPrefix:     DefaultCommentPrefix,
+		Categories:        []string{"work", "meeting", "admin", "learning"},
+		DailyGoal:         0,
+		Currency:          "",
 	}
 }
 
 // LoadConfig loads the track config, or creates and saves default settings
 // if it does not exist.
 func LoadConfig(path string) (Config, error) {
-	conf, err := tryLoadConfig(path)
+	return loadConfig(OSFileSystem{}, path)
+}
+
+// loadConfig loads the track config from a FileSystem, like LoadConfig
+func loadConfig(fsys FileSystem, path string) (Config, error) {
+	conf, err := tryLoadConfig(fsys, path)
 	if err == nil {
 		return conf, nil
 	}
@@ -67,7 +157,7 @@ func LoadConfig(path string) (Config, error) {
 
 	conf = defaultConfig()
 
-	err = conf.Save(path)
+	err = conf.save(fsys, path)
 	if err != nil {
 		return Config{}, fmt.Errorf("could not save config file: %s", err)
 	}
@@ -75,13 +165,13 @@ func LoadConfig(path string) (Config, error) {
 	return conf, nil
 }
 
-func tryLoadConfig(path string) (Config, error) {
-	file, err := os.ReadFile(path)
+func tryLoadConfig(fsys FileSystem, path string) (Config, error) {
+	file, err := fsys.ReadFile(path)
 	if err != nil {
 		return Config{}, ErrNoConfig
 	}
 
-	var conf Config
+	conf := defaultConfig()
 
 	if err := yaml.Unmarshal(file, &conf); err != nil {
 		return Config{}, err
@@ -96,28 +186,91 @@ func tryLoadConfig(path string) (Config, error) {
 
 // Save saves the given Config to it's default location
 func (conf *Config) Save(path string) error {
+	return conf.save(OSFileSystem{}, path)
+}
+
+// save saves the given Config to a FileSystem
+func (conf *Config) save(fsys FileSystem, path string) error {
 	if err := conf.Check(); err != nil {
 		return err
 	}
 
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	bytes, err := yaml.Marshal(&conf)
 	if err != nil {
 		return err
 	}
 
-	bytes, err := yaml.Marshal(&conf)
+	content := fmt.Sprintf("%s Track config\n\n%s", YamlCommentPrefix, bytes)
+	return fsys.WriteFile(path, []byte(content), 0600)
+}
+
+// loadConfig loads the config of the Track, or creates and saves default settings
+// if it does not exist.
+func (t *Track) loadConfig() (Config, error) {
+	return loadConfig(t.FileSystem(), t.ConfigPath())
+}
+
+// SaveConfig saves the Track's Config
+func (t *Track) SaveConfig() error {
+	return t.Config.save(t.FileSystem(), t.ConfigPath())
+}
+
+// FirstWeekday returns the configured first day of the week.
+// Falls back to monday for invalid values.
+func (conf *Config) FirstWeekday() time.Weekday {
+	day, err := util.ParseWeekday(conf.WeekStart)
 	if err != nil {
-		return err
+		return time.Monday
 	}
+	return day
+}
 
-	_, err = fmt.Fprintf(file, "%s Track config\n\n", YamlCommentPrefix)
-	if err != nil {
-		return err
+// Schedule returns the expected working time per weekday, from config entry WorkSchedule.
+// Ignores invalid weekdays.
+func (conf *Config) Schedule() map[time.Weekday]time.Duration {
+	schedule := make(map[time.Weekday]time.Duration, len(conf.WorkSchedule))
+	for name, dur := range conf.WorkSchedule {
+		day, err := util.ParseWeekday(name)
+		if err != nil {
+			continue
+		}
+		schedule[day] = dur
+	}
+	return schedule
+}
+
+// IsWorkday checks if a weekday is a working day, i.e. a day with an expected working time in WorkSchedule.
+// Without a work schedule, Monday to Friday are working days.
+func (conf *Config) IsWorkday(day time.Weekday) bool {
+	if len(conf.WorkSchedule) == 0 {
+		return day != time.Saturday && day != time.Sunday
 	}
+	return conf.Schedule()[day] > 0
+}
 
-	_, err = file.Write(bytes)
+// CheckCategory checks if a category is allowed by config entry Categories.
+// The empty category, for records without a category, is always allowed.
+func (conf *Config) CheckCategory(category string) error {
+	if category == "" {
+		return nil
+	}
+	if err := CheckCategoryName(category); err != nil {
+		return err
+	}
+	if len(conf.Categories) > 0 && !slices.Contains(conf.Categories, category) {
+		return fmt.Errorf("unknown category '%s'. Must be one of (%s)", category, strings.Join(conf.Categories, "|"))
+	}
+	return nil
+}
 
-	return err
+// Formatter returns a formatter for dates, times and durations in reports and exports.
+// Falls back to the default formatter for invalid values.
+func (conf *Config) Formatter() util.Formatter {
+	f, err := util.NewFormatter(conf.DateFormat, conf.Clock, conf.DurationFormat)
+	if err != nil {
+		return util.DefaultFormatter
+	}
+	return f
 }
 
 // Check checks the config for consistency
@@ -132,5 +285,66 @@ func (conf *Config) Check() error {
 	if utf8.RuneCountInString(conf.PauseCell) != 1 {
 		return fmt.Errorf("config entry PauseCell must be a string of length 1. Got '%s'.\n%s", conf.PauseCell, versionHint)
 	}
+	if utf8.RuneCountInString(conf.BreakCell) != 1 {
+		return fmt.Errorf("config entry BreakCell must be a string of length 1. Got '%s'.\n%s", conf.BreakCell, versionHint)
+	}
+	if conf.MaxRecordDuration < 0 {
+		return fmt.Errorf("config entry MaxRecordDuration must not be negative. Got '%s'", conf.MaxRecordDuration)
+	}
+	if conf.MaxPauseDuration < 0 {
+		return fmt.Errorf("config entry MaxPauseDuration must not be negative. Got '%s'", conf.MaxPauseDuration)
+	}
+	if err := conf.StopReminder.Check(); err != nil {
+		return fmt.Errorf("config entry StopReminder is invalid: %s", err)
+	}
+	if err := conf.StartReminder.Check(); err != nil {
+		return fmt.Errorf("config entry StartReminder is invalid: %s", err)
+	}
+	if conf.MidnightPolicy != MidnightKeep && conf.MidnightPolicy != MidnightSplit {
+		return fmt.Errorf("config entry MidnightPolicy must be one of '%s', '%s'. Got '%s'", MidnightKeep, MidnightSplit, conf.MidnightPolicy)
+	}
+	if conf.FiscalYearStart < 1 || conf.FiscalYearStart > 12 {
+		return fmt.Errorf("config entry FiscalYearStart must be a month in range 1-12. Got %d", conf.FiscalYearStart)
+	}
+	if _, err := util.ParseWeekday(conf.WeekStart); err != nil {
+		return fmt.Errorf("config entry WeekStart must be a weekday. Got '%s'", conf.WeekStart)
+	}
+	if _, err := util.NewFormatter(conf.DateFormat, conf.Clock, conf.DurationFormat); err != nil {
+		return fmt.Errorf("config entries DateFormat, Clock or DurationFormat are invalid: %s", err)
+	}
+	if conf.TrashDays < 0 {
+		return fmt.Errorf("config entry TrashDays must not be negative. Got %d", conf.TrashDays)
+	}
+	if conf.Retention.DeleteAfter < 0 || conf.Retention.AnonymizeAfter < 0 || conf.Retention.StripNotesAfter < 0 {
+		return fmt.Errorf("config entries of Retention must not be negative")
+	}
+	if err := CheckPrefixes(conf.TagPrefix, conf.CommentPrefix); err != nil {
+		return fmt.Errorf("config entries TagPrefix or CommentPrefix are invalid: %s", err)
+	}
+	if err := conf.TagNormalization.Check(); err != nil {
+		return fmt.Errorf("config entry TagNormalization is invalid: %s", err)
+	}
+	for i, cat := range conf.Categories {
+		if err := CheckCategoryName(cat); err != nil {
+			return fmt.Errorf("config entry Categories is invalid: %s", err)
+		}
+		if slices.Contains(conf.Categories[:i], cat) {
+			return fmt.Errorf("config entry Categories is invalid: duplicate category '%s'", cat)
+		}
+	}
+	if conf.DailyGoal < 0 {
+		return fmt.Errorf("config entry DailyGoal must not be negative. Got '%s'", conf.DailyGoal)
+	}
+	for name, dur := range conf.WorkSchedule {
+		if _, err := util.ParseWeekday(name); err != nil {
+			return fmt.Errorf("config entry WorkSchedule must have weekdays as keys. Got '%s'", name)
+		}
+		if dur < 0 || dur > 24*time.Hour {
+			return fmt.Errorf("config entry WorkSchedule must have durations between 0 and 24h. Got '%s' for %s", dur, name)
+		}
+	}
+	if conf.RecordFileFormat != FileFormatText && conf.RecordFileFormat != FileFormatYAML && conf.RecordFileFormat != FileFormatJSON {
+		return fmt.Errorf("config entry RecordFormat must be one of '%s', '%s', '%s'. Got '%s'", FileFormatText, FileFormatYAML, FileFormatJSON, conf.RecordFileFormat)
+	}
 	return nil
 }
@@ -0,0 +1,301 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// ErrMergeConflict is an error for conflicting changes that can't be merged automatically
+var ErrMergeConflict = errors.New("merge conflict")
+
+// Patterns of conflicting copies created by file sync tools.
+// The first group is the original name without extension, the second group the extension.
+var conflictPatterns = []*regexp.Regexp{
+	// Syncthing: 04-05.sync-conflict-20230101-120000-ABCDEFG.trk
+	regexp.MustCompile(`^(.+?)\.sync-conflict-[^.]*(\.[^.]+)?$`),
+	// Dropbox and Nextcloud: 04-05 (User's conflicted copy 2023-01-01).trk
+	regexp.MustCompile(`^(.+?) \([^)]*[Cc]onflicted [Cc]opy[^)]*\)(\.[^.]+)?$`),
+}
+
+// RecordConflict is a conflicting copy of a record file, created by a file sync tool
+type RecordConflict struct {
+	// Path of the conflicting copy
+	Path string
+	// Path of the original record file
+	Original string
+	// Start time of the record
+	Start time.Time
+	// Format of the record file
+	Format string
+}
+
+// MergeChooser selects a value for a field that was changed in both versions of a record.
+// It is called with the field name and both values, and returns the value to use.
+type MergeChooser func(field, ours, theirs string) (string, error)
+
+// conflictOriginal returns the name of the original file for the name of a conflicting copy.
+// Returns false if the name is not a conflicting copy.
+func conflictOriginal(name string) (string, bool) {
+	if !strings.Contains(strings.ToLower(name), "conflict") {
+		return "", false
+	}
+	for _, pattern := range conflictPatterns {
+		if match := pattern.FindStringSubmatch(name); match != nil {
+			return match[1] + match[2], true
+		}
+	}
+	return "", false
+}
+
+// FindConflicts finds all conflicting copies of record files in the current workspace
+func (t *Track) FindConflicts() ([]RecordConflict, error) {
+	conflicts := []RecordConflict{}
+	var findErr error
+	err := t.walkDays(FilterFunctions{}, false, func(date time.Time) bool {
+		dir := t.RecordDir(date)
+		files, err := t.FileSystem().ReadDir(dir)
+		if err != nil {
+			findErr = err
+			return false
+		}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+			original, ok := conflictOriginal(file.Name())
+			if !ok {
+				continue
+			}
+			format := recordFileFormat(original)
+			if format == "" {
+				continue
+			}
+			start := date
+			if format != FileFormatJSON {
+				start, err = fileToTime(date, original)
+				if err != nil {
+					findErr = fmt.Errorf("invalid conflicting copy %s: %s", file.Name(), err)
+					return false
+				}
+			}
+			conflicts = append(conflicts, RecordConflict{
+				Path:     filepath.Join(dir, file.Name()),
+				Original: filepath.Join(dir, original),
+				Start:    start,
+				Format:   format,
+			})
+		}
+		return true
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return conflicts, err
+	}
+	return conflicts, findErr
+}
+
+// LoadConflict loads both versions of a conflicting record, and their common ancestor if available.
+//
+// The common ancestor is taken from the record's history, if config entry History is enabled.
+// It is the latest version saved before both files were last modified.
+// Returns nil as base if no common ancestor is available.
+//
+// Conflicting copies of JSON Lines day files are not supported.
+func (t *Track) LoadConflict(c RecordConflict) (ours Record, theirs Record, base *Record, err error) {
+	if c.Format == FileFormatJSON {
+		return Record{}, Record{}, nil, fmt.Errorf("conflicts in %s files must be resolved manually", jsonDayFile)
+	}
+	ours, err = t.readRecordFile(c.Original, c.Format, c.Start)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Original was deleted, so we don't have our version
+			ours, err = t.readRecordFile(c.Path, c.Format, c.Start)
+			return ours, ours, nil, err
+		}
+		return
+	}
+	theirs, err = t.readRecordFile(c.Path, c.Format, c.Start)
+	if err != nil {
+		return
+	}
+
+	versions, err := t.RecordHistory(c.Start)
+	if err != nil {
+		return
+	}
+	oursInfo, err := t.FileSystem().Stat(c.Original)
+	if err != nil {
+		return
+	}
+	theirsInfo, err := t.FileSystem().Stat(c.Path)
+	if err != nil {
+		return
+	}
+	limit := oursInfo.ModTime()
+	if theirsInfo.ModTime().Before(limit) {
+		limit = theirsInfo.ModTime()
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Saved.Before(limit) && !versions[i].Deleted {
+			base = &versions[i].Record
+			break
+		}
+	}
+	return
+}
+
+// ResolveConflict saves the merged version of a conflicting record, and removes the conflicting copy
+func (t *Track) ResolveConflict(c RecordConflict, merged *Record) error {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	if err := t.SaveRecord(merged, true); err != nil {
+		return err
+	}
+	return t.FileSystem().Remove(c.Path)
+}
+
+// MergeRecords merges two conflicting versions of a record, with an optional common ancestor as base.
+//
+//   - Of the end times, the later one is used. A stopped record is preferred over a running one.
+//   - Pauses, tags and links are united.
+//   - Project, category and note are taken from the version that changed them, compared to base.
+//     If both changed them, or if there is no base, argument choose is used to select a value.
+//     Without choose, ErrMergeConflict is returned.
+//
+// Tags are extracted from the merged note with the given Syntax.
+func MergeRecords(syntax *Syntax, base *Record, ours, theirs Record, choose MergeChooser) (Record, error) {
+	merged := ours.copy()
+
+	var err error
+	merged.Project, err = mergeField("project", base, ours.Project, theirs.Project, func(r *Record) string { return r.Project }, choose)
+	if err != nil {
+		return Record{}, err
+	}
+	merged.Category, err = mergeField("category", base, ours.Category, theirs.Category, func(r *Record) string { return r.Category }, choose)
+	if err != nil {
+		return Record{}, err
+	}
+	merged.Note, err = mergeField("note", base, ours.Note, theirs.Note, func(r *Record) string { return r.Note }, choose)
+	if err != nil {
+		return Record{}, err
+	}
+
+	switch {
+	case ours.End.IsZero():
+		merged.End = theirs.End
+	case theirs.End.IsZero():
+		merged.End = ours.End
+	case theirs.End.After(ours.End):
+		merged.End = theirs.End
+	}
+
+	merged.Pause = mergePauses(ours.Pause, theirs.Pause, merged.End)
+
+	tags, err := syntax.ExtractTags(merged.Note)
+	if err != nil {
+		return Record{}, err
+	}
+	merged.Tags = tags
+	for _, r := range []*Record{&ours, &theirs} {
+		keys := make([]string, 0, len(r.Tags))
+		for k := range r.Tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, ok := merged.Tags[k]; ok {
+				continue
+			}
+			v := r.Tags[k]
+			merged.Tags[k] = v
+			token := syntax.TagPrefix + k
+			if v != "" {
+				token += "=" + v
+			}
+			if merged.Note == "" {
+				merged.Note = token
+			} else {
+				merged.Note += " " + token
+			}
+		}
+	}
+
+	for _, l := range theirs.Links {
+		if err := merged.AddLink(l.Target, l.Note); err != nil {
+			return Record{}, err
+		}
+	}
+
+	return merged, nil
+}
+
+// mergeField merges a string field of two conflicting versions of a record
+func mergeField(field string, base *Record, ours, theirs string, get func(r *Record) string, choose MergeChooser) (string, error) {
+	if ours == theirs {
+		return ours, nil
+	}
+	if base != nil {
+		if get(base) == ours {
+			return theirs, nil
+		}
+		if get(base) == theirs {
+			return ours, nil
+		}
+	}
+	if choose == nil {
+		return "", fmt.Errorf("%w: field %s changed in both versions", ErrMergeConflict, field)
+	}
+	return choose(field, ours, theirs)
+}
+
+// mergePauses unites the pauses of two versions of a record.
+// Overlapping pauses are joined, and open pauses are closed at the end of the record.
+func mergePauses(ours, theirs []Pause, end time.Time) []Pause {
+	all := make([]Pause, 0, len(ours)+len(theirs))
+	all = append(all, ours...)
+	all = append(all, theirs...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Start.Before(all[j].Start) })
+
+	merged := []Pause{}
+	for _, p := range all {
+		if !end.IsZero() && (p.End.IsZero() || p.End.After(end)) {
+			p.End = end
+		}
+		if len(merged) == 0 {
+			merged = append(merged, p)
+			continue
+		}
+		last := &merged[len(merged)-1]
+		if !last.End.IsZero() && last.End.Before(p.Start) {
+			merged = append(merged, p)
+			continue
+		}
+		if last.End.IsZero() {
+			continue
+		}
+		if p.End.IsZero() || p.End.After(last.End) {
+			last.End = p.End
+		}
+		if p.Note != "" && !strings.Contains(last.Note, p.Note) {
+			if last.Note == "" {
+				last.Note = p.Note
+			} else {
+				last.Note += "; " + p.Note
+			}
+		}
+	}
+	return merged
+}
+
+// String returns a short description of a conflict
+func (c *RecordConflict) String() string {
+	return fmt.Sprintf("%s (%s)", c.Start.Format(util.DateTimeFormat), filepath.Base(c.Path))
+}
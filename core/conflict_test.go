@@ -0,0 +1,130 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConflictOriginal(t *testing.T) {
+	tt := []struct {
+		name     string
+		original string
+		ok       bool
+	}{
+		{"04-05.trk", "", false},
+		{"04-05.sync-conflict-20230101-120000-ABCDEFG.trk", "04-05.trk", true},
+		{"records.sync-conflict-20230101-120000-ABCDEFG.jsonl", "records.jsonl", true},
+		{"04-05 (John's conflicted copy 2023-01-01).trk", "04-05.trk", true},
+		{"04-05 (conflicted copy 2023-01-01 120000).yml", "04-05.yml", true},
+	}
+	for _, test := range tt {
+		original, ok := conflictOriginal(test.name)
+		assert.Equal(t, test.ok, ok, test.name)
+		assert.Equal(t, test.original, original, test.name)
+	}
+	assert.Equal(t, "", recordFileFormat("04-05.sync-conflict-20230101-120000-ABCDEFG.trk"))
+}
+
+func TestMergeRecords(t *testing.T) {
+	base := Record{
+		Project: "test",
+		Note:    "Note with +foo",
+		Tags:    map[string]string{"foo": ""},
+		Start:   util.DateTime(2001, 2, 3, 8, 0, 0),
+		End:     util.DateTime(2001, 2, 3, 9, 0, 0),
+		Pause:   []Pause{},
+	}
+	ours := base.copy()
+	ours.End = util.DateTime(2001, 2, 3, 10, 0, 0)
+	ours.Pause = []Pause{
+		{Start: util.DateTime(2001, 2, 3, 8, 15, 0), End: util.DateTime(2001, 2, 3, 8, 30, 0)},
+	}
+	ours.Tags["bar"] = "1"
+
+	theirs := base.copy()
+	theirs.Note = "Changed note with +baz"
+	theirs.Tags = map[string]string{"baz": ""}
+	theirs.Pause = []Pause{
+		{Start: util.DateTime(2001, 2, 3, 8, 20, 0), End: util.DateTime(2001, 2, 3, 8, 40, 0), Note: "coffee"},
+		{Start: util.DateTime(2001, 2, 3, 9, 0, 0), End: util.DateTime(2001, 2, 3, 9, 10, 0)},
+	}
+
+	merged, err := MergeRecords(DefaultSyntax(), &base, ours, theirs, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "test", merged.Project)
+	assert.Equal(t, ours.End, merged.End)
+	assert.Equal(t, "Changed note with +baz +bar=1 +foo", merged.Note)
+	assert.Equal(t, map[string]string{"foo": "", "bar": "1", "baz": ""}, merged.Tags)
+	assert.Equal(t, []Pause{
+		{Start: util.DateTime(2001, 2, 3, 8, 15, 0), End: util.DateTime(2001, 2, 3, 8, 40, 0), Note: "coffee"},
+		{Start: util.DateTime(2001, 2, 3, 9, 0, 0), End: util.DateTime(2001, 2, 3, 9, 10, 0)},
+	}, merged.Pause)
+
+	_, err = MergeRecords(DefaultSyntax(), nil, ours, theirs, nil)
+	assert.ErrorIs(t, err, ErrMergeConflict)
+
+	merged, err = MergeRecords(DefaultSyntax(), nil, ours, theirs, func(field, o, th string) (string, error) { return o, nil })
+	assert.Nil(t, err)
+	assert.Equal(t, "Note with +foo +bar=1 +baz", merged.Note)
+
+	running := base.copy()
+	running.End = util.NoTime
+	running.Pause = []Pause{{Start: util.DateTime(2001, 2, 3, 8, 50, 0)}}
+	merged, err = MergeRecords(DefaultSyntax(), &base, running, base, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, base.End, merged.End)
+	assert.Equal(t, []Pause{{Start: util.DateTime(2001, 2, 3, 8, 50, 0), End: base.End}}, merged.Pause)
+}
+
+func TestResolveConflict(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	theirs := Record{
+		Project: "test",
+		Note:    "Note",
+		Start:   util.DateTime(2001, 2, 3, 8, 0, 0),
+		End:     util.DateTime(2001, 2, 3, 10, 0, 0),
+	}
+	assert.Nil(t, track.SaveRecord(&theirs, false))
+	original := track.RecordPath(theirs.Start)
+	conflictPath := filepath.Join(filepath.Dir(original), "08-00.sync-conflict-20010203-100000-ABCDEFG.trk")
+	assert.Nil(t, os.Rename(original, conflictPath))
+
+	ours := theirs
+	ours.End = util.DateTime(2001, 2, 3, 9, 0, 0)
+	assert.Nil(t, track.SaveRecord(&ours, false))
+
+	records, err := track.LoadDateRecords(theirs.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(records), "Conflicting copy should be ignored")
+
+	conflicts, err := track.FindConflicts()
+	assert.Nil(t, err)
+	assert.Equal(t, []RecordConflict{{
+		Path:     conflictPath,
+		Original: original,
+		Start:    theirs.Start,
+		Format:   FileFormatText,
+	}}, conflicts)
+
+	o, th, base, err := track.LoadConflict(conflicts[0])
+	assert.Nil(t, err)
+	assert.Nil(t, base)
+	merged, err := MergeRecords(DefaultSyntax(), base, o, th, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, track.ResolveConflict(conflicts[0], &merged))
+
+	loaded, err := track.LoadRecord(theirs.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, theirs.End, loaded.End)
+	assert.False(t, util.FileExists(conflictPath))
+}
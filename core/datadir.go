@@ -0,0 +1,125 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// xdgDirName is the name of Track's directories in the XDG base directories
+	xdgDirName = "track"
+	// dataDirConfigFile is the name of the file in the XDG config directory, with the location of the data directory
+	dataDirConfigFile   = "config.yml"
+	xdgDataHomeEnvVar   = "XDG_DATA_HOME"
+	xdgConfigHomeEnvVar = "XDG_CONFIG_HOME"
+)
+
+// DataDirConfig is the content of the file at DataDirConfigPath
+type DataDirConfig struct {
+	// Location of the data directory. May start with "~" and contain environment variables
+	DataDir string `yaml:"dataDir"`
+}
+
+// DataDirConfigPath returns the path of the file for configuring the location of the data directory.
+// This is `$XDG_CONFIG_HOME/track/config.yml`, or `~/.config/track/config.yml` if XDG_CONFIG_HOME is not set.
+func DataDirConfigPath() (string, error) {
+	if dir, ok := os.LookupEnv(xdgConfigHomeEnvVar); ok && dir != "" {
+		return filepath.Join(dir, xdgDirName, dataDirConfigFile), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", xdgDirName, dataDirConfigFile), nil
+}
+
+// DataDir returns the data directory of Track. In order of precedence, it is
+//
+//   - the directory in environment variable TRACK_PATH
+//   - the directory in option `dataDir` of the file at DataDirConfigPath
+//   - `~/.track`, if it exists
+//   - `$XDG_DATA_HOME/track`, if XDG_DATA_HOME is set
+//   - `~/.local/share/track`, if it exists
+//   - `~/.track` otherwise
+//
+// Configured directories may start with "~" and contain environment variables.
+// Symbolic links are resolved, so that the data directory is the same
+// regardless of the link it is accessed through.
+func DataDir() (string, error) {
+	if path, ok := os.LookupEnv(trackPathEnvVar); ok && path != "" {
+		return cleanDataDir(path)
+	}
+
+	confPath, err := DataDirConfigPath()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(confPath)
+	if err == nil {
+		conf := DataDirConfig{}
+		if err := yaml.Unmarshal(data, &conf); err != nil {
+			return "", fmt.Errorf("invalid file %s: %s", confPath, err)
+		}
+		if conf.DataDir != "" {
+			return cleanDataDir(conf.DataDir)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	legacy := filepath.Join(home, rootDirName)
+	if _, err := os.Stat(legacy); err == nil {
+		return cleanDataDir(legacy)
+	}
+	if dir, ok := os.LookupEnv(xdgDataHomeEnvVar); ok && dir != "" {
+		return cleanDataDir(filepath.Join(dir, xdgDirName))
+	}
+	xdg := filepath.Join(home, ".local", "share", xdgDirName)
+	if _, err := os.Stat(xdg); err == nil {
+		return cleanDataDir(xdg)
+	}
+	return legacy, nil
+}
+
+// cleanDataDir expands "~" and environment variables in a data directory, and makes it absolute.
+//
+// Symbolic links are resolved if the directory exists.
+// If it can't be resolved, e.g. because a network drive is not available,
+// the absolute path is returned, and errors surface when the directory is accessed.
+// Fails if the path exists, but is not a directory.
+func cleanDataDir(path string) (string, error) {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return path, nil
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return path, nil
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("data directory '%s' is not a directory", path)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataDir(t *testing.T) {
+	home, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(home)
+	home, err = filepath.EvalSymlinks(home)
+	assert.Nil(t, err)
+
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(trackPathEnvVar, "")
+	t.Setenv(xdgConfigHomeEnvVar, "")
+	t.Setenv(xdgDataHomeEnvVar, "")
+
+	dir, err := DataDir()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(home, rootDirName), dir, "Default should be ~/.track")
+
+	xdg := filepath.Join(home, ".local", "share", "track")
+	assert.Nil(t, os.MkdirAll(xdg, 0755))
+	dir, err = DataDir()
+	assert.Nil(t, err)
+	assert.Equal(t, xdg, dir, "Existing XDG default should be used")
+
+	t.Setenv(xdgDataHomeEnvVar, filepath.Join(home, "data"))
+	dir, err = DataDir()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(home, "data", "track"), dir, "XDG_DATA_HOME should be used")
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(home, rootDirName), 0755))
+	dir, err = DataDir()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(home, rootDirName), dir, "Existing ~/.track should take precedence")
+
+	target := filepath.Join(home, "target")
+	assert.Nil(t, os.MkdirAll(target, 0755))
+	assert.Nil(t, os.Symlink(target, filepath.Join(home, "link")))
+
+	confPath, err := DataDirConfigPath()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(home, ".config", "track", "config.yml"), confPath)
+	assert.Nil(t, os.MkdirAll(filepath.Dir(confPath), 0755))
+	assert.Nil(t, os.WriteFile(confPath, []byte("dataDir: ~/link\n"), 0644))
+	dir, err = DataDir()
+	assert.Nil(t, err)
+	assert.Equal(t, target, dir, "Configured directory should be used, with symlinks resolved")
+
+	t.Setenv(xdgConfigHomeEnvVar, filepath.Join(home, "conf"))
+	dir, err = DataDir()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(home, rootDirName), dir, "Config should only be read from XDG_CONFIG_HOME")
+
+	t.Setenv("TRACK_TEST_DIR", filepath.Join(home, "env"))
+	t.Setenv(trackPathEnvVar, "$TRACK_TEST_DIR/track")
+	dir, err = DataDir()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(home, "env", "track"), dir, "TRACK_PATH should take precedence")
+
+	file := filepath.Join(home, "file")
+	assert.Nil(t, os.WriteFile(file, []byte{}, 0644))
+	t.Setenv(trackPathEnvVar, file)
+	_, err = DataDir()
+	assert.NotNil(t, err, "Expected error for a file as data directory")
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DirectoryConfigFile is the name of files in project directories, with the project and tags for records started there
+const DirectoryConfigFile = ".track.yml"
+
+// DirectoryConfig is the content of a DirectoryConfigFile
+type DirectoryConfig struct {
+	// Default project for records started in the directory and its sub-directories
+	Project string `yaml:"project"`
+	// Default tags, without prefix, like "dev" or "client=acme"
+	Tags []string `yaml:"tags,omitempty"`
+	// Path of the file the config was read from
+	Path string `yaml:"-"`
+}
+
+// DetectProject searches for a DirectoryConfigFile in the given directory and its parents,
+// and returns the config from the nearest one.
+//
+// Returns a nil reference if there is no such file.
+func DetectProject(cwd string) (*DirectoryConfig, error) {
+	dir, err := filepath.Abs(cwd)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		path := filepath.Join(dir, DirectoryConfigFile)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			conf := DirectoryConfig{Path: path}
+			if err := yaml.Unmarshal(data, &conf); err != nil {
+				return nil, fmt.Errorf("invalid file %s: %s", path, err)
+			}
+			if err := conf.Check(); err != nil {
+				return nil, fmt.Errorf("invalid file %s: %s", path, err)
+			}
+			return &conf, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// Check checks the directory config for a project and for valid tags
+func (c *DirectoryConfig) Check() error {
+	if c.Project == "" {
+		return fmt.Errorf("missing project")
+	}
+	for _, tag := range c.Tags {
+		if tag == "" || strings.IndexFunc(tag, unicode.IsSpace) >= 0 {
+			return fmt.Errorf("invalid tag '%s'", tag)
+		}
+	}
+	return nil
+}
+
+// Note returns a record note with the default tags of the directory, like "+dev +client=acme"
+func (c *DirectoryConfig) Note(tagPrefix string) string {
+	tags := make([]string, len(c.Tags))
+	for i, tag := range c.Tags {
+		tags[i] = tagPrefix + strings.TrimPrefix(tag, tagPrefix)
+	}
+	return strings.Join(tags, " ")
+}
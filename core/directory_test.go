@@ -0,0 +1,44 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectProject(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	sub := filepath.Join(dir, "project", "src", "pkg")
+	assert.Nil(t, os.MkdirAll(sub, 0755))
+
+	conf, err := DetectProject(sub)
+	assert.Nil(t, err)
+	assert.Nil(t, conf)
+
+	file := filepath.Join(dir, "project", DirectoryConfigFile)
+	assert.Nil(t, os.WriteFile(file, []byte("project: track\ntags: [dev, client=acme]\n"), 0644))
+
+	conf, err = DetectProject(sub)
+	assert.Nil(t, err)
+	assert.NotNil(t, conf)
+	assert.Equal(t, "track", conf.Project)
+	assert.Equal(t, file, conf.Path)
+	assert.Equal(t, "+dev +client=acme", conf.Note(DefaultTagPrefix))
+
+	tags, err := ExtractTags(conf.Note(DefaultTagPrefix))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"dev": "", "client": "acme"}, tags)
+
+	assert.Nil(t, os.WriteFile(file, []byte("tags: [dev]\n"), 0644))
+	_, err = DetectProject(sub)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, os.WriteFile(file, []byte("project: track\ntags: [\"a b\"]\n"), 0644))
+	_, err = DetectProject(sub)
+	assert.NotNil(t, err)
+}
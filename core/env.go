@@ -0,0 +1,83 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// EnvVar is an environment variable describing the running record, see RecordEnv
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// RecordEnv returns environment variables describing the running record, for prompts and scripts:
+//
+//   - TRACK_ACTIVE: "true" if a record is running, "false" otherwise
+//   - TRACK_PROJECT: project of the record
+//   - TRACK_START: start of the record, in RFC 3339 format
+//   - TRACK_ELAPSED: time of the record in seconds, excluding pauses
+//   - TRACK_PAUSED: "true" if the record is paused, "false" otherwise
+//   - TRACK_PAUSE_ELAPSED: time of the current pause in seconds
+//   - TRACK_CATEGORY: category of the record
+//   - TRACK_TAGS: tags of the record, comma-separated, like "dev,client=acme"
+//   - TRACK_NOTE: note of the record
+//
+// All variables are empty, except TRACK_ACTIVE and TRACK_PAUSED, if the record is nil or has ended.
+func RecordEnv(r *Record, now time.Time) []EnvVar {
+	active := r != nil && !r.HasEnded()
+	if !active {
+		return []EnvVar{
+			{"TRACK_ACTIVE", "false"},
+			{"TRACK_PROJECT", ""},
+			{"TRACK_START", ""},
+			{"TRACK_ELAPSED", ""},
+			{"TRACK_PAUSED", "false"},
+			{"TRACK_PAUSE_ELAPSED", ""},
+			{"TRACK_CATEGORY", ""},
+			{"TRACK_TAGS", ""},
+			{"TRACK_NOTE", ""},
+		}
+	}
+
+	tags := make([]string, 0, len(r.Tags))
+	for tag, value := range r.Tags {
+		if value == "" {
+			tags = append(tags, tag)
+		} else {
+			tags = append(tags, tag+"="+value)
+		}
+	}
+	sort.Strings(tags)
+
+	seconds := func(d time.Duration) string {
+		return strconv.FormatInt(int64(d/time.Second), 10)
+	}
+	return []EnvVar{
+		{"TRACK_ACTIVE", "true"},
+		{"TRACK_PROJECT", r.Project},
+		{"TRACK_START", r.Start.Format(time.RFC3339)},
+		{"TRACK_ELAPSED", seconds(r.Duration(util.NoTime, now))},
+		{"TRACK_PAUSED", strconv.FormatBool(r.IsPaused())},
+		{"TRACK_PAUSE_ELAPSED", seconds(r.CurrentPauseDuration(util.NoTime, now))},
+		{"TRACK_CATEGORY", r.Category},
+		{"TRACK_TAGS", strings.Join(tags, ",")},
+		{"TRACK_NOTE", r.Note},
+	}
+}
+
+// ShellString formats the variable as a shell export statement, like "export TRACK_PROJECT='MyProject'"
+func (v EnvVar) ShellString() string {
+	return fmt.Sprintf("export %s='%s'", v.Name, strings.ReplaceAll(v.Value, "'", `'\''`))
+}
+
+// DotEnvString formats the variable as a line of a dotenv file, like `TRACK_PROJECT="MyProject"`
+func (v EnvVar) DotEnvString() string {
+	value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`).Replace(v.Value)
+	return fmt.Sprintf(`%s="%s"`, v.Name, value)
+}
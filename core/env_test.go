@@ -0,0 +1,47 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordEnv(t *testing.T) {
+	now := util.DateTime(2001, 2, 3, 10, 0, 0)
+
+	env := RecordEnv(nil, now)
+	assert.Equal(t, EnvVar{"TRACK_ACTIVE", "false"}, env[0])
+	assert.Equal(t, EnvVar{"TRACK_PROJECT", ""}, env[1])
+
+	record := Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 8, 0, 0),
+		Note:    "Work on +dev +client=acme\nit's done",
+		Tags:    map[string]string{"dev": "", "client": "acme"},
+		Pause: []Pause{
+			{Start: util.DateTime(2001, 2, 3, 8, 30, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0)},
+			{Start: util.DateTime(2001, 2, 3, 9, 50, 0)},
+		},
+	}
+	env = RecordEnv(&record, now)
+	vars := map[string]string{}
+	for _, v := range env {
+		vars[v.Name] = v.Value
+	}
+	assert.Equal(t, "true", vars["TRACK_ACTIVE"])
+	assert.Equal(t, "test", vars["TRACK_PROJECT"])
+	assert.Equal(t, "4800", vars["TRACK_ELAPSED"])
+	assert.Equal(t, "true", vars["TRACK_PAUSED"])
+	assert.Equal(t, "600", vars["TRACK_PAUSE_ELAPSED"])
+	assert.Equal(t, "client=acme,dev", vars["TRACK_TAGS"])
+
+	note := EnvVar{"TRACK_NOTE", record.Note}
+	assert.Equal(t, `export TRACK_NOTE='Work on +dev +client=acme
+it'\''s done'`, note.ShellString())
+	assert.Equal(t, `TRACK_NOTE="Work on +dev +client=acme\nit's done"`, note.DotEnvString())
+
+	record.End = now
+	env = RecordEnv(&record, now)
+	assert.Equal(t, EnvVar{"TRACK_ACTIVE", "false"}, env[0])
+}
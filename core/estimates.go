@@ -0,0 +1,81 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// BurnDown compares the cumulative tracked time of a project against its estimate
+type BurnDown struct {
+	Project  string
+	Estimate time.Duration
+	// Days from the first to the last day with tracked time
+	Dates []time.Time
+	// Cumulative tracked time at the end of each day
+	Tracked []time.Duration
+}
+
+// NewBurnDown creates a burn-down of a project with an estimate from the project's records.
+// Running records are considered to end at the given time.
+func NewBurnDown(project *Project, records []Record, now time.Time) (BurnDown, error) {
+	if project.Estimate <= 0 {
+		return BurnDown{}, fmt.Errorf("project '%s' has no estimate", project.Name)
+	}
+	burn := BurnDown{Project: project.Name, Estimate: project.Estimate}
+
+	daily := DailyTimes(records, now)
+	if len(daily) == 0 {
+		return burn, nil
+	}
+	var first, last time.Time
+	for day := range daily {
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+	}
+
+	cumulative := time.Duration(0)
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		cumulative += daily[day]
+		burn.Dates = append(burn.Dates, day)
+		burn.Tracked = append(burn.Tracked, cumulative)
+	}
+	return burn, nil
+}
+
+// Total returns the total tracked time
+func (b *BurnDown) Total() time.Duration {
+	if len(b.Tracked) == 0 {
+		return 0
+	}
+	return b.Tracked[len(b.Tracked)-1]
+}
+
+// Remaining returns the remaining time of the estimate at the end of the day with the given index.
+// Negative if the estimate is exceeded.
+func (b *BurnDown) Remaining(index int) time.Duration {
+	return b.Estimate - b.Tracked[index]
+}
+
+// ProjectRecords loads all records of a project and its descendants
+func (t *Track) ProjectRecords(project string, filters FilterFunctions) ([]Record, error) {
+	projects, err := t.LoadAllProjects()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := projects[project]; !ok {
+		return nil, &ProjectNotFoundError{Name: project}
+	}
+	tree, err := t.ToProjectTree(projects)
+	if err != nil {
+		return nil, err
+	}
+	// Copy, to not modify the backing array of the caller's filters
+	fns := make([]FilterFunction, len(filters.Functions), len(filters.Functions)+1)
+	copy(fns, filters.Functions)
+	filters.Functions = append(fns, FilterByProjectSubtree(project, tree))
+	return t.LoadAllRecordsFiltered(filters)
+}
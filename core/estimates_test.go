@@ -0,0 +1,83 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBurnDown(t *testing.T) {
+	date := func(d, h int) time.Time {
+		return time.Date(2023, 1, d, h, 0, 0, 0, time.Local)
+	}
+	project := NewProject("test", "", "t", []string{}, 15, 0)
+
+	_, err := NewBurnDown(&project, []Record{}, date(5, 0))
+	assert.NotNil(t, err, "Error expected for project without estimate")
+
+	project.Estimate = 10 * time.Hour
+	records := []Record{
+		{Project: "test", Start: date(2, 8), End: date(2, 12)},
+		{Project: "test", Start: date(4, 8), End: date(4, 16)},
+	}
+	burn, err := NewBurnDown(&project, records, date(5, 0))
+	assert.Nil(t, err)
+
+	assert.Equal(t, []time.Time{date(2, 0), date(3, 0), date(4, 0)}, burn.Dates)
+	assert.Equal(t, []time.Duration{4 * time.Hour, 4 * time.Hour, 12 * time.Hour}, burn.Tracked)
+	assert.Equal(t, 6*time.Hour, burn.Remaining(0))
+	assert.Equal(t, -2*time.Hour, burn.Remaining(2))
+	assert.Equal(t, 12*time.Hour, burn.Total())
+
+	burn, err = NewBurnDown(&project, []Record{}, date(5, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(burn.Dates))
+	assert.Equal(t, time.Duration(0), burn.Total())
+}
+
+func TestProjectRecords(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	for _, p := range []Project{
+		NewProject("parent", "", "p", []string{}, 15, 0),
+		NewProject("child", "parent", "c", []string{}, 15, 0),
+		NewProject("other", "", "o", []string{}, 15, 0),
+	} {
+		assert.Nil(t, track.SaveProject(p, false), "Error saving project")
+	}
+
+	start := time.Date(2023, 1, 2, 8, 0, 0, 0, time.Local)
+	for i, p := range []string{"parent", "child", "other"} {
+		rec := Record{Project: p, Start: start.Add(time.Duration(i) * time.Hour), End: start.Add(time.Duration(i)*time.Hour + 30*time.Minute)}
+		assert.Nil(t, track.SaveRecord(&rec, false), "Error saving record")
+	}
+
+	records, err := track.ProjectRecords("parent", NewFilter([]FilterFunction{}, time.Time{}, time.Time{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(records))
+
+	records, err = track.ProjectRecords("child", NewFilter([]FilterFunction{}, time.Time{}, time.Time{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(records))
+
+	_, err = track.ProjectRecords("missing", NewFilter([]FilterFunction{}, time.Time{}, time.Time{}))
+	assert.NotNil(t, err)
+
+	// Filters with spare capacity share their backing array with derived filters
+	shared := NewFilter(make([]FilterFunction, 0, 4), time.Time{}, time.Time{})
+	derived := shared
+	derived.Functions = append(shared.Functions, FilterByProjects([]string{"other"}))
+	records, err = track.ProjectRecords("parent", shared)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(records))
+	records, err = track.LoadAllRecordsFiltered(derived)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(records), "Derived filters should not be modified")
+}
@@ -0,0 +1,54 @@
+package core
+
+import "time"
+
+// EventType is the type of a tracking event
+type EventType string
+
+const (
+	// EventStart is emitted when a record is started
+	EventStart EventType = "start"
+	// EventStop is emitted when a record is stopped
+	EventStop EventType = "stop"
+	// EventPause is emitted when a running record is paused
+	EventPause EventType = "pause"
+	// EventResume is emitted when a paused or stopped record is resumed
+	EventResume EventType = "resume"
+)
+
+// Event is a change of the tracking state
+type Event struct {
+	Type EventType
+	// Time of the event, like the start time for EventStart
+	Time time.Time
+	// The affected record, in the state after the event
+	Record Record
+}
+
+// EventHook is called on tracking events.
+// Hooks are responsible for handling their own errors, as they must not affect tracking.
+type EventHook func(e Event)
+
+// namedHook is an event hook with a name, to prevent duplicate registration
+type namedHook struct {
+	name string
+	hook EventHook
+}
+
+// SetEventHook registers an event hook, or replaces the hook with the same name
+func (t *Track) SetEventHook(name string, hook EventHook) {
+	for i := range t.hooks {
+		if t.hooks[i].name == name {
+			t.hooks[i].hook = hook
+			return
+		}
+	}
+	t.hooks = append(t.hooks, namedHook{name: name, hook: hook})
+}
+
+// EmitEvent calls all registered event hooks, in the order of their registration
+func (t *Track) EmitEvent(e Event) {
+	for _, h := range t.hooks {
+		h.hook(e)
+	}
+}
@@ -0,0 +1,38 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventHooks(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	events := []EventType{}
+	track.SetEventHook("test", func(e Event) { events = append(events, "ignored") })
+	track.SetEventHook("test", func(e Event) {
+		events = append(events, e.Type)
+		assert.Equal(t, "test", e.Record.Project)
+	})
+
+	project := NewProject("test", "", "t", []string{}, 15, 0)
+	start := time.Now().Round(time.Minute).Add(-2 * time.Hour)
+	_, err = track.StartRecord(&project, "Note", map[string]string{}, start)
+	assert.Nil(t, err, "Error starting record")
+
+	track.EmitEvent(Event{Type: EventPause, Record: Record{Project: "test"}})
+
+	rec, err := track.StopRecord(start.Add(time.Hour))
+	assert.Nil(t, err, "Error stopping record")
+	assert.Equal(t, start.Add(time.Hour), rec.End)
+
+	assert.Equal(t, []EventType{EventStart, EventPause, EventStop}, events)
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// ExpectedDay compares the expected working time of a day to the tracked time
+type ExpectedDay struct {
+	// The day
+	Date time.Time
+	// Expected working time, according to the work schedule and absences
+	Expected time.Duration
+	// Tracked time
+	Tracked time.Duration
+	// Kind of the absence on the day, empty if there is none
+	Absence AbsenceKind
+}
+
+// Balance returns the tracked minus the expected time.
+// Negative for a shortfall, positive for a surplus.
+func (d *ExpectedDay) Balance() time.Duration {
+	return d.Tracked - d.Expected
+}
+
+// ExpectedTimes compares tracked to expected times for each day from start to end, with the end exclusive.
+// Days with full-day absences, like public holidays, have no expected time.
+// Partial absences reduce the expected time by their duration.
+func ExpectedTimes(daily map[time.Time]time.Duration, schedule map[time.Weekday]time.Duration, absences *Absences, start, end time.Time) []ExpectedDay {
+	days := []ExpectedDay{}
+	for day := util.ToDate(start); day.Before(end); day = day.AddDate(0, 0, 1) {
+		expected := schedule[day.Weekday()]
+		var kind AbsenceKind
+		if abs, ok := absences.On(day); ok {
+			kind = abs.Kind
+			expected -= absences.Credit(day, expected)
+		}
+		days = append(days, ExpectedDay{
+			Date:     day,
+			Expected: expected,
+			Tracked:  daily[day],
+			Absence:  kind,
+		})
+	}
+	return days
+}
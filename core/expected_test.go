@@ -0,0 +1,46 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectedTimes(t *testing.T) {
+	schedule := map[time.Weekday]time.Duration{
+		time.Monday:    8 * time.Hour,
+		time.Tuesday:   8 * time.Hour,
+		time.Wednesday: 8 * time.Hour,
+		time.Thursday:  8 * time.Hour,
+		time.Friday:    4 * time.Hour,
+	}
+	daily := map[time.Time]time.Duration{
+		util.Date(2023, 1, 2): 9 * time.Hour,
+		util.Date(2023, 1, 3): 6 * time.Hour,
+		util.Date(2023, 1, 7): 2 * time.Hour,
+	}
+
+	absences := Absences{}
+	holiday, err := NewAbsence(AbsenceHoliday, util.Date(2023, 1, 4), util.Date(2023, 1, 4), 0, "")
+	assert.Nil(t, err)
+	assert.Nil(t, absences.Add(holiday))
+	partial, err := NewAbsence(AbsenceSick, util.Date(2023, 1, 5), util.Date(2023, 1, 5), 3*time.Hour, "")
+	assert.Nil(t, err)
+	assert.Nil(t, absences.Add(partial))
+
+	days := ExpectedTimes(daily, schedule, &absences, util.Date(2023, 1, 2), util.Date(2023, 1, 9))
+	assert.Equal(t, 7, len(days))
+
+	expected := []time.Duration{8 * time.Hour, 8 * time.Hour, 0, 5 * time.Hour, 4 * time.Hour, 0, 0}
+	balance := []time.Duration{time.Hour, -2 * time.Hour, 0, -5 * time.Hour, -4 * time.Hour, 2 * time.Hour, 0}
+	for i, day := range days {
+		assert.Equal(t, util.Date(2023, 1, 2+i), day.Date)
+		assert.Equal(t, expected[i], day.Expected, "expected time on day %d", i)
+		assert.Equal(t, balance[i], day.Balance(), "balance on day %d", i)
+	}
+	assert.Equal(t, AbsenceHoliday, days[2].Absence)
+	assert.Equal(t, AbsenceSick, days[3].Absence)
+	assert.Equal(t, AbsenceKind(""), days[0].Absence)
+}
@@ -0,0 +1,462 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// memoryRootDir is the root directory of a Track created by NewMemoryTrack
+const memoryRootDir = "/track"
+
+// FileSystem is the storage backend of a Track.
+// Paths are in the format of the operating system, like for package os.
+//
+// OSFileSystem stores in the file system of the operating system,
+// MemoryFileSystem stores in memory.
+// ReadOnlyFileSystem wraps another FileSystem and rejects all writes.
+type FileSystem interface {
+	// ReadFile reads the content of a file
+	ReadFile(name string) ([]byte, error)
+	// WriteFile writes a file, creating or truncating it. The parent directory must exist.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// AppendFile appends to a file, creating it if it does not exist. The parent directory must exist.
+	AppendFile(name string, data []byte, perm fs.FileMode) error
+	// ReadDir reads a directory, with entries sorted by name
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// Stat returns file info for a file or directory
+	Stat(name string) (fs.FileInfo, error)
+	// MkdirAll creates a directory and all missing parents
+	MkdirAll(path string, perm fs.FileMode) error
+	// Remove removes a file or an empty directory
+	Remove(name string) error
+	// Rename renames or moves a file or directory, replacing an existing file
+	Rename(oldpath, newpath string) error
+	// Chtimes changes the access and modification times of a file
+	Chtimes(name string, atime time.Time, mtime time.Time) error
+}
+
+// OSFileSystem is a FileSystem that uses the file system of the operating system
+type OSFileSystem struct{}
+
+// ReadFile reads the content of a file
+func (OSFileSystem) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+
+// WriteFile writes a file, creating or truncating it
+func (OSFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+
+// AppendFile appends to a file, creating it if it does not exist
+func (OSFileSystem) AppendFile(name string, data []byte, perm fs.FileMode) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ReadDir reads a directory, with entries sorted by name
+func (OSFileSystem) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+
+// Stat returns file info for a file or directory
+func (OSFileSystem) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+
+// MkdirAll creates a directory and all missing parents
+func (OSFileSystem) MkdirAll(path string, perm fs.FileMode) error { return os.MkdirAll(path, perm) }
+
+// Remove removes a file or an empty directory
+func (OSFileSystem) Remove(name string) error { return os.Remove(name) }
+
+// Rename renames or moves a file or directory
+func (OSFileSystem) Rename(oldpath, newpath string) error { return os.Rename(oldpath, newpath) }
+
+// Chtimes changes the access and modification times of a file
+func (OSFileSystem) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+// MemoryFileSystem is a FileSystem that stores in memory, e.g. for tests.
+// It is safe for concurrent use.
+type MemoryFileSystem struct {
+	mutex sync.RWMutex
+	files map[string]*memoryFile
+}
+
+// memoryFile is a file or directory in a MemoryFileSystem
+type memoryFile struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// NewMemoryFileSystem creates an empty MemoryFileSystem
+func NewMemoryFileSystem() *MemoryFileSystem {
+	return &MemoryFileSystem{files: map[string]*memoryFile{}}
+}
+
+// info returns the file info of the file at a cleaned path
+func (m *MemoryFileSystem) info(path string, file *memoryFile) fs.FileInfo {
+	return memoryFileInfo{name: filepath.Base(path), file: *file}
+}
+
+// dirExists checks if a directory exists at a cleaned path.
+// The root directory, and volume names on Windows, always exist.
+func (m *MemoryFileSystem) dirExists(path string) bool {
+	if path == filepath.Dir(path) {
+		return true
+	}
+	dir, ok := m.files[path]
+	return ok && dir.mode.IsDir()
+}
+
+// ReadFile reads the content of a file
+func (m *MemoryFileSystem) ReadFile(name string) ([]byte, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	name = filepath.Clean(name)
+	file, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if file.mode.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fmt.Errorf("is a directory")}
+	}
+	data := make([]byte, len(file.data))
+	copy(data, file.data)
+	return data, nil
+}
+
+// WriteFile writes a file, creating or truncating it
+func (m *MemoryFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return m.write(name, data, perm, false)
+}
+
+// AppendFile appends to a file, creating it if it does not exist
+func (m *MemoryFileSystem) AppendFile(name string, data []byte, perm fs.FileMode) error {
+	return m.write(name, data, perm, true)
+}
+
+func (m *MemoryFileSystem) write(name string, data []byte, perm fs.FileMode, appendData bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	name = filepath.Clean(name)
+	if !m.dirExists(filepath.Dir(name)) {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	file, ok := m.files[name]
+	if !ok {
+		file = &memoryFile{mode: perm.Perm()}
+		m.files[name] = file
+	} else if file.mode.IsDir() {
+		return &fs.PathError{Op: "open", Path: name, Err: fmt.Errorf("is a directory")}
+	}
+	if !appendData {
+		file.data = nil
+	}
+	file.data = append(file.data, data...)
+	file.modTime = time.Now()
+	return nil
+}
+
+// ReadDir reads a directory, with entries sorted by name
+func (m *MemoryFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	name = filepath.Clean(name)
+	if !m.dirExists(name) {
+		if _, ok := m.files[name]; ok {
+			return nil, &fs.PathError{Op: "readdirent", Path: name, Err: fmt.Errorf("not a directory")}
+		}
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	entries := []fs.DirEntry{}
+	for path, file := range m.files {
+		if path != name && filepath.Dir(path) == name {
+			entries = append(entries, fs.FileInfoToDirEntry(m.info(path, file)))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Stat returns file info for a file or directory
+func (m *MemoryFileSystem) Stat(name string) (fs.FileInfo, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	name = filepath.Clean(name)
+	file, ok := m.files[name]
+	if !ok {
+		if name == filepath.Dir(name) {
+			return memoryFileInfo{name: name, file: memoryFile{mode: fs.ModeDir | 0755}}, nil
+		}
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return m.info(name, file), nil
+}
+
+// MkdirAll creates a directory and all missing parents
+func (m *MemoryFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	path = filepath.Clean(path)
+	missing := []string{}
+	for dir := path; dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		file, ok := m.files[dir]
+		if ok {
+			if !file.mode.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: dir, Err: fmt.Errorf("not a directory")}
+			}
+			break
+		}
+		missing = append(missing, dir)
+	}
+	for _, dir := range missing {
+		m.files[dir] = &memoryFile{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	}
+	return nil
+}
+
+// Remove removes a file or an empty directory
+func (m *MemoryFileSystem) Remove(name string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	name = filepath.Clean(name)
+	file, ok := m.files[name]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if file.mode.IsDir() {
+		for path := range m.files {
+			if path != name && filepath.Dir(path) == name {
+				return &fs.PathError{Op: "remove", Path: name, Err: fmt.Errorf("directory not empty")}
+			}
+		}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+// Rename renames or moves a file or directory, replacing an existing file
+func (m *MemoryFileSystem) Rename(oldpath, newpath string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	file, ok := m.files[oldpath]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+	if !m.dirExists(filepath.Dir(newpath)) {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+	if existing, ok := m.files[newpath]; ok && existing.mode.IsDir() {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrExist}
+	}
+	delete(m.files, oldpath)
+	m.files[newpath] = file
+	if file.mode.IsDir() {
+		prefix := oldpath + string(filepath.Separator)
+		for path, f := range m.files {
+			if strings.HasPrefix(path, prefix) {
+				delete(m.files, path)
+				m.files[filepath.Join(newpath, strings.TrimPrefix(path, prefix))] = f
+			}
+		}
+	}
+	return nil
+}
+
+// Chtimes changes the access and modification times of a file
+func (m *MemoryFileSystem) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	name = filepath.Clean(name)
+	file, ok := m.files[name]
+	if !ok {
+		return &fs.PathError{Op: "chtimes", Path: name, Err: fs.ErrNotExist}
+	}
+	file.modTime = mtime
+	return nil
+}
+
+// memoryFileInfo is the fs.FileInfo of a file in a MemoryFileSystem
+type memoryFileInfo struct {
+	name string
+	file memoryFile
+}
+
+func (i memoryFileInfo) Name() string       { return i.name }
+func (i memoryFileInfo) Size() int64        { return int64(len(i.file.data)) }
+func (i memoryFileInfo) Mode() fs.FileMode  { return i.file.mode }
+func (i memoryFileInfo) ModTime() time.Time { return i.file.modTime }
+func (i memoryFileInfo) IsDir() bool        { return i.file.mode.IsDir() }
+func (i memoryFileInfo) Sys() interface{}   { return nil }
+
+// ReadOnlyFileSystem is a FileSystem that reads from another FileSystem, and rejects all writes.
+// Writes fail with an error wrapping fs.ErrPermission.
+type ReadOnlyFileSystem struct {
+	// The FileSystem to read from
+	FS FileSystem
+}
+
+// ReadFile reads the content of a file
+func (r ReadOnlyFileSystem) ReadFile(name string) ([]byte, error) { return r.FS.ReadFile(name) }
+
+// WriteFile fails for a read-only FileSystem
+func (r ReadOnlyFileSystem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return readOnlyError("write", name)
+}
+
+// AppendFile fails for a read-only FileSystem
+func (r ReadOnlyFileSystem) AppendFile(name string, data []byte, perm fs.FileMode) error {
+	return readOnlyError("write", name)
+}
+
+// ReadDir reads a directory, with entries sorted by name
+func (r ReadOnlyFileSystem) ReadDir(name string) ([]fs.DirEntry, error) { return r.FS.ReadDir(name) }
+
+// Stat returns file info for a file or directory
+func (r ReadOnlyFileSystem) Stat(name string) (fs.FileInfo, error) { return r.FS.Stat(name) }
+
+// MkdirAll fails for a read-only FileSystem
+func (r ReadOnlyFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	return readOnlyError("mkdir", path)
+}
+
+// Remove fails for a read-only FileSystem
+func (r ReadOnlyFileSystem) Remove(name string) error { return readOnlyError("remove", name) }
+
+// Rename fails for a read-only FileSystem
+func (r ReadOnlyFileSystem) Rename(oldpath, newpath string) error {
+	return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrPermission}
+}
+
+// Chtimes fails for a read-only FileSystem
+func (r ReadOnlyFileSystem) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return readOnlyError("chtimes", name)
+}
+
+func readOnlyError(op, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: fs.ErrPermission}
+}
+
+// FileSystem returns the Track's FileSystem, or the OSFileSystem if none is set.
+// Packages that store files in the Track's directories should access them through it.
+func (t *Track) FileSystem() FileSystem {
+	if t.FS == nil {
+		return OSFileSystem{}
+	}
+	return t.FS
+}
+
+// fileExists checks if a file exists
+func (t *Track) fileExists(path string) bool {
+	info, err := t.FileSystem().Stat(path)
+	if err != nil {
+		return false
+	}
+	return !info.IsDir()
+}
+
+// dirExists checks if a directory exists
+func (t *Track) dirExists(path string) bool {
+	info, err := t.FileSystem().Stat(path)
+	if err != nil {
+		return false
+	}
+	return info.IsDir()
+}
+
+// dirIsEmpty checks if a directory is empty
+func (t *Track) dirIsEmpty(path string) (bool, error) {
+	if !t.dirExists(path) {
+		return false, fmt.Errorf("is not a directory: %s", path)
+	}
+	content, err := t.FileSystem().ReadDir(path)
+	if err != nil {
+		return false, err
+	}
+	return len(content) == 0, nil
+}
+
+// createDir creates directories recursively
+func (t *Track) createDir(path string) error {
+	return t.FileSystem().MkdirAll(path, 0755)
+}
+
+// findLatest finds the "latest" file or directory in a directory, by name.
+// Returns util.ErrNoFiles if there is none.
+func (t *Track) findLatest(path string, isDir bool) (string, string, error) {
+	files, err := t.FileSystem().ReadDir(path)
+	if err != nil {
+		return "", "", err
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		if files[i].IsDir() == isDir {
+			return filepath.Join(path, files[i].Name()), files[i].Name(), nil
+		}
+	}
+	return "", "", util.ErrNoFiles
+}
+
+// walkDir walks the file tree rooted at root, like filepath.WalkDir
+func (t *Track) walkDir(root string, fn fs.WalkDirFunc) error {
+	info, err := t.FileSystem().Stat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = t.walkDirEntry(root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if errors.Is(err, fs.SkipDir) {
+		return nil
+	}
+	return err
+}
+
+func (t *Track) walkDirEntry(path string, entry fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, entry, nil); err != nil || !entry.IsDir() {
+		if errors.Is(err, fs.SkipDir) && entry.IsDir() {
+			err = nil
+		}
+		return err
+	}
+	entries, err := t.FileSystem().ReadDir(path)
+	if err != nil {
+		err = fn(path, entry, err)
+		if err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				err = nil
+			}
+			return err
+		}
+	}
+	for _, e := range entries {
+		if err := t.walkDirEntry(filepath.Join(path, e.Name()), e, fn); err != nil {
+			if errors.Is(err, fs.SkipDir) {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryFileSystem(t *testing.T) {
+	fsys := NewMemoryFileSystem()
+	dir := filepath.Join("/", "a", "b")
+	path := filepath.Join(dir, "test.txt")
+
+	assert.True(t, os.IsNotExist(fsys.WriteFile(path, []byte("test"), 0600)), "Expected error for missing directory")
+	assert.Nil(t, fsys.MkdirAll(dir, 0755), "Error creating directory")
+	assert.Nil(t, fsys.MkdirAll(dir, 0755), "Error creating existing directory")
+
+	assert.Nil(t, fsys.WriteFile(path, []byte("test"), 0600), "Error writing file")
+	assert.Nil(t, fsys.AppendFile(path, []byte("\nmore"), 0600), "Error appending to file")
+	data, err := fsys.ReadFile(path)
+	assert.Nil(t, err, "Error reading file")
+	assert.Equal(t, "test\nmore", string(data), "Wrong file content")
+
+	info, err := fsys.Stat(path)
+	assert.Nil(t, err, "Error getting file info")
+	assert.Equal(t, "test.txt", info.Name(), "Wrong file name")
+	assert.Equal(t, int64(9), info.Size(), "Wrong file size")
+	assert.False(t, info.IsDir(), "File is not a directory")
+
+	modTime := util.DateTime(2001, 2, 3, 8, 0, 0)
+	assert.Nil(t, fsys.Chtimes(path, modTime, modTime), "Error changing times")
+	info, err = fsys.Stat(path)
+	assert.Nil(t, err, "Error getting file info")
+	assert.Equal(t, modTime, info.ModTime(), "Wrong modification time")
+
+	assert.Nil(t, fsys.WriteFile(filepath.Join(dir, "a.txt"), []byte{}, 0600), "Error writing file")
+	assert.Nil(t, fsys.MkdirAll(filepath.Join(dir, "c"), 0755), "Error creating directory")
+	entries, err := fsys.ReadDir(dir)
+	assert.Nil(t, err, "Error reading directory")
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"a.txt", "c", "test.txt"}, names, "Wrong directory entries")
+	assert.True(t, entries[1].IsDir(), "Entry should be a directory")
+
+	assert.NotNil(t, fsys.Remove(dir), "Expected error for removing non-empty directory")
+	assert.NotNil(t, fsys.MkdirAll(filepath.Join(path, "d"), 0755), "Expected error for directory in a file")
+
+	renamed := filepath.Join("/", "a", "x")
+	assert.Nil(t, fsys.Rename(dir, renamed), "Error renaming directory")
+	_, err = fsys.Stat(path)
+	assert.True(t, errors.Is(err, fs.ErrNotExist), "File should be moved")
+	data, err = fsys.ReadFile(filepath.Join(renamed, "test.txt"))
+	assert.Nil(t, err, "Error reading moved file")
+	assert.Equal(t, "test\nmore", string(data), "Wrong file content")
+
+	assert.Nil(t, fsys.Remove(filepath.Join(renamed, "c")), "Error removing directory")
+	assert.Nil(t, fsys.Remove(filepath.Join(renamed, "a.txt")), "Error removing file")
+	assert.True(t, os.IsNotExist(fsys.Remove(filepath.Join(renamed, "a.txt"))), "Expected error for missing file")
+
+	var walked []string
+	err = (&Track{FS: fsys}).walkDir("/", func(path string, d fs.DirEntry, err error) error {
+		walked = append(walked, path)
+		return err
+	})
+	assert.Nil(t, err, "Error walking directory")
+	for i := range walked {
+		walked[i] = filepath.ToSlash(walked[i])
+	}
+	assert.Equal(t, []string{"/", "/a", "/a/x", "/a/x/test.txt"}, walked, "Wrong walk")
+}
+
+func TestMemoryTrack(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+	assert.True(t, track.WorkspaceExists("default"), "Default workspace should exist")
+	_, err = os.Stat(track.RootDir)
+	assert.True(t, os.IsNotExist(err), "Nothing should be written to the file system")
+
+	assert.Nil(t, track.SaveProject(NewProject("test", "", "T", []string{}, 0, 0), false), "Error saving project")
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0), Tags: map[string]string{}, Pause: []Pause{}},
+		{Project: "test", Start: util.DateTime(2001, 2, 4, 8, 0, 0), End: util.DateTime(2001, 2, 4, 9, 0, 0), Tags: map[string]string{}, Pause: []Pause{}},
+		{Project: "test", Start: util.DateTime(2001, 2, 4, 10, 0, 0), End: time.Time{}, Tags: map[string]string{}, Pause: []Pause{}},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false), "Error saving record")
+	}
+
+	loaded, err := track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, records, loaded, "Wrong records")
+
+	open, err := track.OpenRecord()
+	assert.Nil(t, err, "Error getting open record")
+	assert.Equal(t, &records[2], open, "Wrong open record")
+
+	assert.Nil(t, track.DeleteRecord(&records[0]), "Error deleting record")
+	deleted, err := track.DeletedRecords()
+	assert.Nil(t, err, "Error loading trash")
+	assert.Equal(t, 1, len(deleted), "Wrong number of deleted records")
+	assert.False(t, track.dirExists(track.RecordDir(records[0].Start)), "Empty day directory should be removed")
+
+	reporter, err := NewReporter(&track, []string{}, FilterFunctions{}, false, util.NoTime, util.NoTime)
+	assert.Nil(t, err, "Error creating reporter")
+	assert.Equal(t, 2, len(reporter.Records), "Wrong number of records")
+
+	assert.Nil(t, track.CreateWorkspace("other"), "Error creating workspace")
+	assert.NotNil(t, track.SwitchWorkspace("other"), "Expected error for running record")
+	_, err = track.StopRecord(util.DateTime(2001, 2, 4, 11, 0, 0))
+	assert.Nil(t, err, "Error stopping record")
+	assert.Nil(t, track.SwitchWorkspace("other"), "Error switching workspace")
+	workspaces, err := track.AllWorkspaces()
+	assert.Nil(t, err, "Error listing workspaces")
+	assert.Equal(t, []string{"default", "other"}, workspaces, "Wrong workspaces")
+
+	reopened, err := NewTrackWithFS(track.RootDir, track.FS)
+	assert.Nil(t, err, "Error opening Track instance")
+	assert.Equal(t, "other", reopened.Workspace(), "Config should be saved")
+}
+
+func TestReadOnlyFileSystem(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+	assert.Nil(t, track.SaveProject(NewProject("test", "", "T", []string{}, 0, 0), false), "Error saving project")
+	record := Record{Project: "test", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0)}
+	assert.Nil(t, track.SaveRecord(&record, false), "Error saving record")
+
+	track.FS = ReadOnlyFileSystem{FS: track.FS}
+	loaded, err := track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 1, len(loaded), "Wrong number of records")
+
+	record = Record{Project: "test", Start: util.DateTime(2001, 2, 4, 8, 0, 0), End: util.DateTime(2001, 2, 4, 9, 0, 0)}
+	err = track.SaveRecord(&record, false)
+	assert.True(t, errors.Is(err, fs.ErrPermission), "Expected permission error, got %v", err)
+	assert.True(t, errors.Is(track.FS.Remove(track.RecordPath(loaded[0].Start)), fs.ErrPermission), "Expected permission error")
+	assert.True(t, errors.Is(track.FS.Rename(track.RootDir, "/other"), fs.ErrPermission), "Expected permission error")
+
+	loaded, err = track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 1, len(loaded), "Nothing should be written")
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"strings"
 	"time"
 
 	"github.com/mlange-42/track/util"
@@ -15,6 +16,17 @@ type FilterFunctions struct {
 	Functions []FilterFunction
 	Start     time.Time
 	End       time.Time
+	// IncludeArchived includes records of archived projects
+	// in Track.LoadAllRecordsFiltered and in reporters.
+	// Excluded by default.
+	IncludeArchived bool
+	// Sort order of records from Track.LoadAllRecordsFiltered and in a Reporter's Records.
+	// Sorted by start time, ascending, by default.
+	Sort SortOrder
+	// NeedsRecords must be set if any of the Functions depends on fields of records
+	// other than project, category, tags and time, like the note or pauses.
+	// The report cache only holds these fields, so it is not used by reporters then.
+	NeedsRecords bool
 }
 
 // NewFilter creates a FilterFunctions struct
@@ -51,6 +63,19 @@ func FilterByProjects(projects []string) FilterFunction {
 	}
 }
 
+// FilterByProjectSubtree returns a function for filtering by a project and all its descendants in the project tree.
+//
+// If the project is not in the tree, only records of the project itself are kept.
+func FilterByProjectSubtree(root string, tree *ProjectTree) FilterFunction {
+	names := []string{root}
+	if desc, ok := tree.Descendants(root); ok {
+		for _, d := range desc {
+			names = append(names, d.Value.Name)
+		}
+	}
+	return FilterByProjects(names)
+}
+
 // FilterByTime returns a function for filtering by time
 //
 // Keeps all records that are partially included in the given time span.
@@ -75,7 +100,9 @@ func FilterByArchived(archived bool, projects map[string]Project) FilterFunction
 	}
 }
 
-// FilterByTagsAny returns a function for filtering by tags
+// FilterByTagsAny returns a function for filtering by tags.
+//
+// Hierarchical tags match all their descendants. E.g. tag "meeting" matches "meeting/standup".
 func FilterByTagsAny(tags []util.Pair[string, string]) FilterFunction {
 	tg := map[string]map[string]bool{}
 	for _, kv := range tags {
@@ -96,12 +123,21 @@ func FilterByTagsAny(tags []util.Pair[string, string]) FilterFunction {
 					return true
 				}
 			}
+			for _, anc := range TagAncestors(t) {
+				if values, ok := tg[anc]; ok {
+					if _, ok := values[""]; ok {
+						return true
+					}
+				}
+			}
 		}
 		return false
 	}
 }
 
 // FilterByTagsAll returns a function for filtering by tags
+//
+// Hierarchical tags match all their descendants. E.g. tag "meeting" matches "meeting/standup".
 func FilterByTagsAll(tags []util.Pair[string, string]) FilterFunction {
 	return func(r *Record) bool {
 		for _, kv := range tags {
@@ -111,6 +147,10 @@ func FilterByTagsAll(tags []util.Pair[string, string]) FilterFunction {
 					found = true
 					break
 				}
+				if kv.Value == "" && IsTagOrDescendant(t2, kv.Key) {
+					found = true
+					break
+				}
 			}
 			if !found {
 				return false
@@ -119,3 +159,66 @@ func FilterByTagsAll(tags []util.Pair[string, string]) FilterFunction {
 		return true
 	}
 }
+
+// FilterExcludeProjects returns a function for filtering out records of the given projects.
+//
+// Only the given projects are excluded, not their descendants.
+func FilterExcludeProjects(projects []string) FilterFunction {
+	include := FilterByProjects(projects)
+	return func(r *Record) bool {
+		return !include(r)
+	}
+}
+
+// FilterExcludeTags returns a function for filtering out records with any of the given tags.
+//
+// Hierarchical tags exclude all their descendants. E.g. tag "meeting" excludes "meeting/standup".
+func FilterExcludeTags(tags []util.Pair[string, string]) FilterFunction {
+	include := FilterByTagsAny(tags)
+	return func(r *Record) bool {
+		return !include(r)
+	}
+}
+
+// FilterByCategories returns a function for filtering records by their category.
+//
+// Keeps records with any of the given categories.
+func FilterByCategories(categories []string) FilterFunction {
+	cats := make(map[string]bool, len(categories))
+	for _, c := range categories {
+		cats[c] = true
+	}
+	return func(r *Record) bool {
+		return cats[r.Category]
+	}
+}
+
+// FilterByPauseDuration returns a function for filtering by the total pause time of records.
+//
+// Keeps records with a total pause time between min and max, both inclusive.
+// A zero max means no upper limit. Open pauses are counted until now.
+//
+// Depends on pauses, so FilterFunctions.NeedsRecords must be set when using it.
+func FilterByPauseDuration(min, max time.Duration) FilterFunction {
+	return func(r *Record) bool {
+		dur := r.PauseDuration(util.NoTime, util.NoTime)
+		return dur >= min && (max == 0 || dur <= max)
+	}
+}
+
+// FilterByEmptyNote returns a function for filtering records with an empty note.
+// Notes consisting only of whitespace are considered empty.
+//
+// Depends on the note, so FilterFunctions.NeedsRecords must be set when using it.
+func FilterByEmptyNote() FilterFunction {
+	return func(r *Record) bool {
+		return strings.TrimSpace(r.Note) == ""
+	}
+}
+
+// FilterUntagged returns a function for filtering records without any tags
+func FilterUntagged() FilterFunction {
+	return func(r *Record) bool {
+		return len(r.Tags) == 0
+	}
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// FilterDiff holds the records of a period matched by only one of two filter sets
+type FilterDiff struct {
+	// Records matched by the first filter set, but not by the second
+	OnlyFirst []Record
+	// Records matched by the second filter set, but not by the first
+	OnlySecond []Record
+	// Number of records matched by both filter sets
+	Both int
+}
+
+// DiffFilters evaluates two filter sets over the records between start and end,
+// and returns the records that are matched by one of them, but not by the other.
+// E.g., to verify what an export or invoice includes, compared to a broader report.
+// Records are sorted by start time.
+func (t *Track) DiffFilters(first, second FilterFunctions, start, end time.Time) (FilterDiff, error) {
+	filters := NewFilter([]FilterFunction{}, start, end)
+	filters.IncludeArchived = true
+	records, err := t.LoadAllRecordsFiltered(filters)
+	if err != nil {
+		return FilterDiff{}, err
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Start.Before(records[j].Start) })
+
+	diff := FilterDiff{}
+	for i := range records {
+		inFirst := Filter(&records[i], first)
+		inSecond := Filter(&records[i], second)
+		switch {
+		case inFirst && inSecond:
+			diff.Both++
+		case inFirst:
+			diff.OnlyFirst = append(diff.OnlyFirst, records[i])
+		case inSecond:
+			diff.OnlySecond = append(diff.OnlySecond, records[i])
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffFilters(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err)
+
+	assert.Nil(t, track.SaveProject(NewProject("client", "", "C", []string{}, 0, 15), false))
+	assert.Nil(t, track.SaveProject(NewProject("internal", "", "I", []string{}, 0, 15), false))
+
+	records := []Record{
+		{Project: "client", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0), Note: "+billable", Tags: map[string]string{"billable": ""}},
+		{Project: "client", Start: util.DateTime(2001, 2, 3, 10, 0, 0), End: util.DateTime(2001, 2, 3, 11, 0, 0)},
+		{Project: "internal", Start: util.DateTime(2001, 2, 3, 12, 0, 0), End: util.DateTime(2001, 2, 3, 13, 0, 0), Note: "+billable", Tags: map[string]string{"billable": ""}},
+		{Project: "client", Start: util.DateTime(2001, 2, 5, 8, 0, 0), End: util.DateTime(2001, 2, 5, 9, 0, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false))
+	}
+
+	start, end := util.Date(2001, 2, 3), util.Date(2001, 2, 4)
+	invoice := NewFilter([]FilterFunction{FilterByTagsAny([]util.Pair[string, string]{util.NewPair("billable", "")})}, start, end)
+	report := NewFilter([]FilterFunction{FilterByProjects([]string{"client"})}, start, end)
+
+	diff, err := track.DiffFilters(invoice, report, start, end)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, diff.Both)
+	assert.Equal(t, 1, len(diff.OnlyFirst))
+	assert.Equal(t, "internal", diff.OnlyFirst[0].Project)
+	assert.Equal(t, 1, len(diff.OnlySecond))
+	assert.Equal(t, util.DateTime(2001, 2, 3, 10, 0, 0), diff.OnlySecond[0].Start)
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"testing"
+	"time"
 
 	"github.com/mlange-42/track/util"
 )
@@ -216,14 +217,210 @@ func TestFilters(t *testing.T) {
 				}: true,
 			},
 		},
+		{
+			title: "filter by any hierarchical tags",
+			filters: []func(r *Record) bool{
+				FilterByTagsAny([]util.Pair[string, string]{
+					{Key: "A", Value: ""}, {Key: "B/b", Value: ""},
+				}),
+			},
+			records: map[*Record]bool{
+				{
+					Tags: map[string]string{"A/a": ""},
+				}: true,
+				{
+					Tags: map[string]string{"A/a/x": "1"},
+				}: true,
+				{
+					Tags: map[string]string{"AA": ""},
+				}: false,
+				{
+					Tags: map[string]string{"B": ""},
+				}: false,
+				{
+					Tags: map[string]string{"B/b/x": ""},
+				}: true,
+			},
+		},
+		{
+			title: "filter by all hierarchical tags",
+			filters: []func(r *Record) bool{
+				FilterByTagsAll([]util.Pair[string, string]{
+					{Key: "A", Value: ""}, {Key: "B", Value: ""},
+				}),
+			},
+			records: map[*Record]bool{
+				{
+					Tags: map[string]string{"A/a": ""},
+				}: false,
+				{
+					Tags: map[string]string{"A/a": "", "B/b": ""},
+				}: true,
+				{
+					Tags: map[string]string{"A": "", "BB": ""},
+				}: false,
+			},
+		},
+		{
+			title: "exclude projects",
+			filters: []func(r *Record) bool{
+				FilterExcludeProjects([]string{"A", "B"}),
+			},
+			records: map[*Record]bool{
+				{
+					Project: "A",
+				}: false,
+				{
+					Project: "C",
+				}: true,
+			},
+		},
+		{
+			title: "exclude hierarchical tags",
+			filters: []func(r *Record) bool{
+				FilterExcludeTags([]util.Pair[string, string]{
+					{Key: "A", Value: ""}, {Key: "B", Value: "b"},
+				}),
+			},
+			records: map[*Record]bool{
+				{
+					Tags: map[string]string{},
+				}: true,
+				{
+					Tags: map[string]string{"A/a": ""},
+				}: false,
+				{
+					Tags: map[string]string{"B": "b"},
+				}: false,
+				{
+					Tags: map[string]string{"B": "c", "C": ""},
+				}: true,
+			},
+		},
+		{
+			title: "filter by empty note",
+			filters: []func(r *Record) bool{
+				FilterByEmptyNote(),
+			},
+			records: map[*Record]bool{
+				{
+					Note: "",
+				}: true,
+				{
+					Note: " \n ",
+				}: true,
+				{
+					Note: "Note",
+				}: false,
+			},
+		},
+		{
+			title: "filter untagged",
+			filters: []func(r *Record) bool{
+				FilterUntagged(),
+			},
+			records: map[*Record]bool{
+				{
+					Tags: map[string]string{},
+				}: true,
+				{}: true,
+				{
+					Tags: map[string]string{"A": ""},
+				}: false,
+			},
+		},
+		{
+			title: "filter by pause duration",
+			filters: []func(r *Record) bool{
+				FilterByPauseDuration(30*time.Minute, time.Hour),
+			},
+			records: map[*Record]bool{
+				{
+					Pause: []Pause{},
+				}: false,
+				{
+					Pause: []Pause{
+						{Start: util.DateTime(2001, 2, 3, 12, 0, 0), End: util.DateTime(2001, 2, 3, 12, 20, 0)},
+						{Start: util.DateTime(2001, 2, 3, 15, 0, 0), End: util.DateTime(2001, 2, 3, 15, 10, 0)},
+					},
+				}: true,
+				{
+					Pause: []Pause{
+						{Start: util.DateTime(2001, 2, 3, 12, 0, 0), End: util.DateTime(2001, 2, 3, 13, 30, 0)},
+					},
+				}: false,
+			},
+		},
+		{
+			title: "filter by categories",
+			filters: []func(r *Record) bool{
+				FilterByCategories([]string{"meeting", "admin"}),
+			},
+			records: map[*Record]bool{
+				{Category: "meeting"}: true,
+				{Category: "admin"}:   true,
+				{Category: "work"}:    false,
+				{}:                    false,
+			},
+		},
+		{
+			title: "filter by minimum pause duration",
+			filters: []func(r *Record) bool{
+				FilterByPauseDuration(30*time.Minute, 0),
+			},
+			records: map[*Record]bool{
+				{
+					Pause: []Pause{
+						{Start: util.DateTime(2001, 2, 3, 12, 0, 0), End: util.DateTime(2001, 2, 3, 18, 0, 0)},
+					},
+				}: true,
+			},
+		},
 	}
 
 	for _, test := range tt {
 		for rec, expOk := range test.records {
-			ok := Filter(rec, FilterFunctions{test.filters, util.NoTime, util.NoTime})
+			ok := Filter(rec, FilterFunctions{Functions: test.filters})
 			if ok != expOk {
 				t.Fatalf("error when %s: expected %t, got %t for %v", test.title, expOk, ok, rec)
 			}
 		}
 	}
 }
+
+func TestFilterByProjectSubtree(t *testing.T) {
+	track := Track{
+		Config: Config{
+			Workspace: "default",
+		},
+	}
+	projects := map[string]Project{
+		"p1":   {Name: "p1"},
+		"p1a":  {Name: "p1a", Parent: "p1"},
+		"p1a1": {Name: "p1a1", Parent: "p1a"},
+		"p2":   {Name: "p2"},
+	}
+	tree, err := track.ToProjectTree(projects)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter := FilterByProjectSubtree("p1", tree)
+	for name, expOk := range map[string]bool{"p1": true, "p1a": true, "p1a1": true, "p2": false} {
+		if ok := filter(&Record{Project: name}); ok != expOk {
+			t.Fatalf("error when filtering subtree of p1: expected %t, got %t for %s", expOk, ok, name)
+		}
+	}
+
+	filter = FilterByProjectSubtree("p1a", tree)
+	for name, expOk := range map[string]bool{"p1": false, "p1a": true, "p1a1": true, "p2": false} {
+		if ok := filter(&Record{Project: name}); ok != expOk {
+			t.Fatalf("error when filtering subtree of p1a: expected %t, got %t for %s", expOk, ok, name)
+		}
+	}
+
+	filter = FilterByProjectSubtree("unknown", tree)
+	if filter(&Record{Project: "p1"}) || !filter(&Record{Project: "unknown"}) {
+		t.Fatal("error when filtering subtree of a project not in the tree")
+	}
+}
@@ -0,0 +1,193 @@
+package core
+
+import (
+	"math"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// forecastZ is the number of standard deviations of the confidence bands of forecasts,
+// for an approximate 80% confidence interval
+const forecastZ = 1.2816
+
+// Forecast projects the total time per project at the end of a period, like a week or month
+type Forecast struct {
+	// Start of the period
+	Start time.Time
+	// End of the period, exclusive
+	End time.Time
+	// Number of days of the period up to and including today
+	ElapsedDays int
+	// Number of days of the period after today
+	RemainingDays int
+	// Number of days of history used for historical averages
+	HistoryDays int
+	// Forecasts per project
+	Projects map[string]ProjectForecast
+	// Forecast of the total over all projects
+	Total ProjectForecast
+}
+
+// ProjectForecast is the forecast of a project's total time at the end of a period
+type ProjectForecast struct {
+	// Time tracked in the period so far
+	Tracked time.Duration
+	// Average tracked time per elapsed day of the period
+	Pace time.Duration
+	// Historical average time per day
+	Average time.Duration
+	// Forecast total time at the end of the period
+	Expected time.Duration
+	// Lower bound of the confidence band. Never less than Tracked
+	Low time.Duration
+	// Upper bound of the confidence band
+	High time.Duration
+}
+
+// NewForecast forecasts the total time per project at the end of the period from start to end, with end exclusive.
+// Argument records must contain the records from historyStart to now.
+// The days from historyStart to start are the history for historical averages.
+//
+// Today counts as elapsed. The time of the remaining days is projected from the current pace,
+// i.e. the average time per elapsed day, and from the historical average time per weekday.
+// The current pace gets more weight the more of the period has elapsed.
+// Confidence bands are based on the variance of historical daily times per weekday,
+// or on the variance of the elapsed days if there is no history.
+func NewForecast(records []Record, start, end, historyStart, now time.Time) Forecast {
+	start, end, historyStart = util.ToDate(start), util.ToDate(end), util.ToDate(historyStart)
+	today := util.ToDate(now)
+
+	history := daysBetween(historyStart, start)
+	elapsed := []time.Time{}
+	remaining := []time.Time{}
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if day.After(today) {
+			remaining = append(remaining, day)
+		} else {
+			elapsed = append(elapsed, day)
+		}
+	}
+
+	total := map[time.Time]time.Duration{}
+	daily := map[string]map[time.Time]time.Duration{}
+	for i := range records {
+		rec := &records[i]
+		rec.forEachDay(historyStart, end, now, func(day time.Time, dur time.Duration) {
+			times, ok := daily[rec.Project]
+			if !ok {
+				times = map[time.Time]time.Duration{}
+				daily[rec.Project] = times
+			}
+			times[day] += dur
+			total[day] += dur
+		})
+	}
+
+	forecast := Forecast{
+		Start:         start,
+		End:           end,
+		ElapsedDays:   len(elapsed),
+		RemainingDays: len(remaining),
+		HistoryDays:   len(history),
+		Projects:      map[string]ProjectForecast{},
+		Total:         forecastTimes(total, history, elapsed, remaining),
+	}
+	for project, times := range daily {
+		forecast.Projects[project] = forecastTimes(times, history, elapsed, remaining)
+	}
+	return forecast
+}
+
+// forecastTimes forecasts the total of daily times at the end of a period
+func forecastTimes(daily map[time.Time]time.Duration, history, elapsed, remaining []time.Time) ProjectForecast {
+	f := ProjectForecast{}
+	for _, day := range elapsed {
+		f.Tracked += daily[day]
+	}
+	if len(elapsed) > 0 {
+		f.Pace = f.Tracked / time.Duration(len(elapsed))
+	}
+
+	// Historical mean and variance per weekday, in hours
+	var weekdays [7][]float64
+	historyTotal := time.Duration(0)
+	for _, day := range history {
+		weekdays[day.Weekday()] = append(weekdays[day.Weekday()], daily[day].Hours())
+		historyTotal += daily[day]
+	}
+	if len(history) > 0 {
+		f.Average = historyTotal / time.Duration(len(history))
+	}
+
+	var histMean, histVar float64
+	hasHistory := len(history) > 0
+	for _, day := range remaining {
+		values := weekdays[day.Weekday()]
+		if len(values) == 0 {
+			// Weekday not in history, use the overall average
+			histMean += f.Average.Hours()
+			continue
+		}
+		mean, variance := meanVariance(values)
+		histMean += mean
+		histVar += variance
+	}
+
+	// The current pace gets more weight the more of the period has elapsed
+	weight := 1.0
+	if hasHistory {
+		weight = float64(len(elapsed)) / float64(len(elapsed)+len(remaining))
+	}
+	remainingHours := weight*f.Pace.Hours()*float64(len(remaining)) + (1-weight)*histMean
+
+	variance := histVar
+	if !hasHistory && len(elapsed) > 0 {
+		values := make([]float64, len(elapsed))
+		for i, day := range elapsed {
+			values[i] = daily[day].Hours()
+		}
+		_, dayVar := meanVariance(values)
+		variance = dayVar * float64(len(remaining))
+	}
+	band := forecastZ * math.Sqrt(variance)
+
+	f.Expected = f.Tracked + hoursToDuration(remainingHours)
+	f.Low = f.Expected - hoursToDuration(band)
+	if f.Low < f.Tracked {
+		f.Low = f.Tracked
+	}
+	f.High = f.Expected + hoursToDuration(band)
+	return f
+}
+
+// daysBetween returns all days from start to end, with end exclusive
+func daysBetween(start, end time.Time) []time.Time {
+	days := []time.Time{}
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// meanVariance calculates the mean and the population variance of values
+func meanVariance(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	sq := 0.0
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, sq / float64(len(values))
+}
+
+// hoursToDuration converts hours to a duration, rounded to minutes
+func hoursToDuration(hours float64) time.Duration {
+	return (time.Duration(hours * float64(time.Hour))).Round(time.Minute)
+}
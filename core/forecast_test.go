@@ -0,0 +1,58 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewForecast(t *testing.T) {
+	records := []Record{}
+	// Two weeks of history, with 2h per work day
+	for day := util.Date(2000, 12, 18); day.Before(util.Date(2001, 1, 1)); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+		records = append(records, Record{Project: "a", Start: day.Add(8 * time.Hour), End: day.Add(10 * time.Hour)})
+	}
+	// Monday to Wednesday with 4h per day
+	for day := util.Date(2001, 1, 1); day.Before(util.Date(2001, 1, 4)); day = day.AddDate(0, 0, 1) {
+		records = append(records, Record{Project: "b", Start: day.Add(8 * time.Hour), End: day.Add(12 * time.Hour)})
+	}
+
+	start, end := util.Date(2001, 1, 1), util.Date(2001, 1, 8)
+	now := util.DateTime(2001, 1, 3, 20, 0, 0)
+
+	fc := NewForecast(records, start, end, util.Date(2000, 12, 18), now)
+	assert.Equal(t, 3, fc.ElapsedDays, "Wrong number of elapsed days")
+	assert.Equal(t, 4, fc.RemainingDays, "Wrong number of remaining days")
+	assert.Equal(t, 14, fc.HistoryDays, "Wrong number of history days")
+	assert.Equal(t, 2, len(fc.Projects), "Wrong number of projects")
+
+	total := fc.Total
+	assert.Equal(t, 12*time.Hour, total.Tracked, "Wrong tracked time")
+	assert.Equal(t, 4*time.Hour, total.Pace, "Wrong pace")
+	assert.Equal(t, 10*time.Hour/7, total.Average, "Wrong historical average")
+	// Remaining: 3/7 * 4 days * 4h (pace) + 4/7 * (2h + 2h + 0h + 0h) (history)
+	expected := 12*time.Hour + hoursToDuration(64.0/7.0)
+	assert.Equal(t, expected, total.Expected, "Wrong forecast")
+	assert.Equal(t, expected, total.Low, "No variance in history")
+	assert.Equal(t, expected, total.High, "No variance in history")
+
+	b := fc.Projects["b"]
+	assert.Equal(t, 12*time.Hour, b.Tracked, "Wrong tracked time")
+	assert.Equal(t, time.Duration(0), b.Average, "Wrong historical average")
+
+	// Without history, projected from the pace only
+	records = records[10:]
+	records[1].End = records[1].End.Add(time.Hour)
+	fc = NewForecast(records, start, end, start, now)
+	total = fc.Total
+	assert.Equal(t, 0, fc.HistoryDays, "Wrong number of history days")
+	assert.Equal(t, 13*time.Hour, total.Tracked, "Wrong tracked time")
+	assert.Equal(t, total.Tracked+4*total.Pace, total.Expected, "Wrong forecast")
+	assert.True(t, total.Low < total.Expected && total.Low >= total.Tracked, "Wrong lower bound")
+	assert.True(t, total.High > total.Expected, "Wrong upper bound")
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+const (
+	historyDirName = ".history"
+	historyFileExt = ".jsonl"
+)
+
+// RecordVersion is a saved version of a record in the record's history
+type RecordVersion struct {
+	// Number of the version, starting at 1
+	Version int `json:"-"`
+	// Time the version was saved
+	Saved time.Time `json:"saved"`
+	// Whether the record was deleted
+	Deleted bool `json:"deleted,omitempty"`
+	// The record as saved
+	Record Record `json:"record"`
+}
+
+// historyPath returns the path of the history log of the record starting at the given time
+func (t *Track) historyPath(tm time.Time) string {
+	return filepath.Join(
+		t.HistoryDir(),
+		fmt.Sprintf("%04d", tm.Year()),
+		fmt.Sprintf("%02d", int(tm.Month())),
+		fmt.Sprintf("%02d", tm.Day()),
+		tm.Format(util.FileTimeFormat)+historyFileExt,
+	)
+}
+
+// appendHistory appends a version of a record to the record's append-only history log
+func (t *Track) appendHistory(record *Record, deleted bool) error {
+	path := t.historyPath(record.Start)
+	if err := t.createDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	line, err := json.Marshal(&RecordVersion{Saved: time.Now(), Deleted: deleted, Record: *record})
+	if err != nil {
+		return err
+	}
+
+	return t.FileSystem().AppendFile(path, append(line, '\n'), 0600)
+}
+
+// RecordHistory returns all saved versions of the record starting at the given time, oldest first.
+// Versions are only stored if config entry history is enabled.
+func (t *Track) RecordHistory(start time.Time) ([]RecordVersion, error) {
+	return t.readHistoryFile(t.historyPath(start))
+}
+
+// readHistoryFile reads all versions from a record history log
+func (t *Track) readHistoryFile(path string) ([]RecordVersion, error) {
+	file, err := t.FileSystem().ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []RecordVersion{}, nil
+		}
+		return nil, err
+	}
+
+	versions := []RecordVersion{}
+	scanner := bufio.NewScanner(bytes.NewReader(file))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var version RecordVersion
+		if err := json.Unmarshal(line, &version); err != nil {
+			return nil, fmt.Errorf("invalid entry in record history: %s", err)
+		}
+		version.Version = len(versions) + 1
+		version.Saved = version.Saved.Local()
+		version.Record.toLocal()
+		versions = append(versions, version)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// RevertRecord restores a version of the record starting at the given time from the record's history.
+// Reverting itself is stored as a new version.
+func (t *Track) RevertRecord(start time.Time, version int) (Record, error) {
+	if err := t.CheckWritable(); err != nil {
+		return Record{}, err
+	}
+	versions, err := t.RecordHistory(start)
+	if err != nil {
+		return Record{}, err
+	}
+	if version < 1 || version > len(versions) {
+		return Record{}, fmt.Errorf("no version %d in history of record %s", version, start.Format(util.DateTimeFormat))
+	}
+	v := versions[version-1]
+	if v.Deleted {
+		return Record{}, fmt.Errorf("version %d is a deletion", version)
+	}
+	record := v.Record
+	if err := t.SaveRecord(&record, true); err != nil {
+		return Record{}, err
+	}
+	return record, nil
+}
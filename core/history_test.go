@@ -0,0 +1,71 @@
+package core
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordHistory(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	record := Record{
+		Project: "test",
+		Note:    "first",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	assert.Nil(t, track.SaveRecord(&record, false))
+
+	versions, err := track.RecordHistory(record.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(versions), "History should be disabled by default")
+
+	track.Config.History = true
+
+	record.Note = "second"
+	assert.Nil(t, track.SaveRecord(&record, true))
+	record.Note = "third"
+	record.End = util.DateTime(2001, 2, 3, 6, 5, 0)
+	assert.Nil(t, track.SaveRecord(&record, true))
+
+	versions, err = track.RecordHistory(record.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(versions))
+	assert.Equal(t, 1, versions[0].Version)
+	assert.Equal(t, "second", versions[0].Record.Note)
+	assert.Equal(t, "third", versions[1].Record.Note)
+
+	reverted, err := track.RevertRecord(record.Start, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "second", reverted.Note)
+
+	loaded, err := track.LoadRecord(record.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "second", loaded.Note)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 5, 5, 0), loaded.End)
+
+	_, err = track.RevertRecord(record.Start, 5)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, track.DeleteRecord(&loaded))
+	versions, err = track.RecordHistory(record.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(versions))
+	assert.True(t, versions[3].Deleted)
+
+	_, err = track.RevertRecord(record.Start, 4)
+	assert.NotNil(t, err)
+	_, err = track.RevertRecord(record.Start, 2)
+	assert.Nil(t, err)
+	loaded, err = track.LoadRecord(record.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "third", loaded.Note)
+}
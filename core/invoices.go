@@ -0,0 +1,211 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"gopkg.in/yaml.v3"
+)
+
+// Invoice is an entry in the invoice registry
+type Invoice struct {
+	// Invoice number, like 2023-0001
+	Number string `yaml:"number"`
+	// Invoiced project, including its sub-projects
+	Project string `yaml:"project"`
+	// Time the invoice was created
+	Created time.Time `yaml:"created"`
+	// First day of the invoiced period, empty if unbounded
+	From string `yaml:"from,omitempty"`
+	// Last day of the invoiced period, empty if unbounded
+	To string `yaml:"to,omitempty"`
+	// Start times of the invoiced records, identifying them
+	Records []time.Time `yaml:"records"`
+	// Total time of the invoiced records
+	Time time.Duration `yaml:"time"`
+	// Invoiced amounts per currency
+	Amounts map[string]float64 `yaml:"amounts,omitempty"`
+	// Time the invoice was paid, zero if unpaid
+	Paid time.Time `yaml:"paid,omitempty"`
+}
+
+// IsPaid checks if the invoice is paid
+func (inv *Invoice) IsPaid() bool {
+	return !inv.Paid.IsZero()
+}
+
+// Invoices is the registry of the invoices of a workspace
+type Invoices struct {
+	Invoices []Invoice `yaml:"invoices"`
+	// Invoice numbers by start times of records
+	records map[int64]string
+}
+
+// LoadInvoices loads the invoice registry of the current workspace.
+// Returns an empty registry if there are no invoices yet.
+func (t *Track) LoadInvoices() (Invoices, error) {
+	data, err := t.FileSystem().ReadFile(t.InvoicesPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return newInvoices([]Invoice{}), nil
+		}
+		return Invoices{}, err
+	}
+	var inv Invoices
+	if err := yaml.Unmarshal(data, &inv); err != nil {
+		return Invoices{}, fmt.Errorf("invalid invoice registry: %s", err)
+	}
+	for i := range inv.Invoices {
+		in := &inv.Invoices[i]
+		in.Created = in.Created.Local()
+		if !in.Paid.IsZero() {
+			in.Paid = in.Paid.Local()
+		}
+		for j := range in.Records {
+			in.Records[j] = in.Records[j].Local()
+		}
+	}
+	return newInvoices(inv.Invoices), nil
+}
+
+// SaveInvoices saves the invoice registry of the current workspace, replacing the file atomically
+func (t *Track) SaveInvoices(inv *Invoices) error {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(inv)
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf("%s Invoice registry\n\n%s", YamlCommentPrefix, data)
+
+	path := t.InvoicesPath()
+	tempPath := path + ".tmp"
+	if err := t.FileSystem().WriteFile(tempPath, []byte(content), 0600); err != nil {
+		return err
+	}
+	return t.FileSystem().Rename(tempPath, path)
+}
+
+func newInvoices(invoices []Invoice) Invoices {
+	inv := Invoices{Invoices: invoices, records: map[int64]string{}}
+	for _, in := range invoices {
+		for _, start := range in.Records {
+			inv.records[start.UnixNano()] = in.Number
+		}
+	}
+	return inv
+}
+
+// Get returns the invoice with the given number
+func (inv *Invoices) Get(number string) (*Invoice, error) {
+	for i := range inv.Invoices {
+		if inv.Invoices[i].Number == number {
+			return &inv.Invoices[i], nil
+		}
+	}
+	return nil, fmt.Errorf("invoice '%s' not found", number)
+}
+
+// InvoiceOf returns the number of the invoice that contains the record with the given start time.
+// Returns false if the record is not invoiced.
+func (inv *Invoices) InvoiceOf(start time.Time) (string, bool) {
+	number, ok := inv.records[start.UnixNano()]
+	return number, ok
+}
+
+// Uninvoiced returns the records that are not contained in any invoice
+func (inv *Invoices) Uninvoiced(records []Record) []Record {
+	result := []Record{}
+	for _, rec := range records {
+		if _, ok := inv.records[rec.Start.UnixNano()]; !ok {
+			result = append(result, rec)
+		}
+	}
+	return result
+}
+
+// NextNumber returns the next invoice number for the year of the given time, like 2023-0001
+func (inv *Invoices) NextNumber(now time.Time) string {
+	prefix := fmt.Sprintf("%04d-", now.Year())
+	last := 0
+	for _, in := range inv.Invoices {
+		if !strings.HasPrefix(in.Number, prefix) {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(in.Number, prefix)); err == nil && n > last {
+			last = n
+		}
+	}
+	return fmt.Sprintf("%s%04d", prefix, last+1)
+}
+
+// Create adds a new invoice for the given records, with the next invoice number.
+// Arguments from and to are the invoiced period, and may be zero for no limit.
+// Fails if there are no records, if a record is still running, or if a record is already invoiced.
+func (inv *Invoices) Create(project string, from, to time.Time, records []Record, now time.Time) (*Invoice, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no records to invoice")
+	}
+
+	invoice := Invoice{
+		Number:  inv.NextNumber(now),
+		Project: project,
+		Created: now,
+		Records: make([]time.Time, 0, len(records)),
+	}
+	if !from.IsZero() {
+		invoice.From = from.Format(util.DateFormat)
+	}
+	if !to.IsZero() {
+		invoice.To = to.Format(util.DateFormat)
+	}
+
+	for _, rec := range records {
+		if !rec.HasEnded() {
+			return nil, fmt.Errorf("record %s is still running", rec.Start.Format(util.DateTimeFormat))
+		}
+		if number, ok := inv.records[rec.Start.UnixNano()]; ok {
+			return nil, fmt.Errorf("record %s is already invoiced in %s", rec.Start.Format(util.DateTimeFormat), number)
+		}
+		invoice.Records = append(invoice.Records, rec.Start)
+		invoice.Time += rec.Duration(util.NoTime, util.NoTime)
+	}
+	sort.Slice(invoice.Records, func(i, j int) bool { return invoice.Records[i].Before(invoice.Records[j]) })
+
+	inv.Invoices = append(inv.Invoices, invoice)
+	for _, start := range invoice.Records {
+		inv.records[start.UnixNano()] = invoice.Number
+	}
+	return &inv.Invoices[len(inv.Invoices)-1], nil
+}
+
+// SetPaid marks an invoice as paid at the given time, or as unpaid for zero time
+func (inv *Invoices) SetPaid(number string, paid time.Time) error {
+	invoice, err := inv.Get(number)
+	if err != nil {
+		return err
+	}
+	invoice.Paid = paid
+	return nil
+}
+
+// Cancel removes an invoice from the registry, so that its records can be invoiced again
+func (inv *Invoices) Cancel(number string) error {
+	for i, in := range inv.Invoices {
+		if in.Number != number {
+			continue
+		}
+		for _, start := range in.Records {
+			delete(inv.records, start.UnixNano())
+		}
+		inv.Invoices = append(inv.Invoices[:i], inv.Invoices[i+1:]...)
+		return nil
+	}
+	return fmt.Errorf("invoice '%s' not found", number)
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvoices(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	date := func(d, h int) time.Time {
+		return time.Date(2023, 1, d, h, 0, 0, 0, time.Local)
+	}
+	records := []Record{
+		{Project: "test", Start: date(2, 8), End: date(2, 10)},
+		{Project: "test", Start: date(3, 8), End: date(3, 9)},
+		{Project: "test", Start: date(4, 8)},
+	}
+
+	invoices, err := track.LoadInvoices()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(invoices.Invoices))
+	assert.Equal(t, "2023-0001", invoices.NextNumber(date(1, 0)))
+
+	_, err = invoices.Create("test", date(1, 0), date(5, 0), records, date(5, 0))
+	assert.NotNil(t, err, "Error expected for running record")
+	_, err = invoices.Create("test", date(1, 0), date(5, 0), []Record{}, date(5, 0))
+	assert.NotNil(t, err, "Error expected for no records")
+
+	invoice, err := invoices.Create("test", date(1, 0), time.Time{}, records[:2], date(5, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, "2023-0001", invoice.Number)
+	assert.Equal(t, "2023-01-01", invoice.From)
+	assert.Equal(t, "", invoice.To)
+	assert.Equal(t, 3*time.Hour, invoice.Time)
+	assert.False(t, invoice.IsPaid())
+
+	_, err = invoices.Create("test", time.Time{}, time.Time{}, records[1:2], date(5, 0))
+	assert.NotNil(t, err, "Error expected for already invoiced record")
+	assert.Equal(t, 1, len(invoices.Uninvoiced(records)))
+
+	assert.Nil(t, invoices.SetPaid("2023-0001", date(6, 0)))
+	assert.NotNil(t, invoices.SetPaid("2023-0002", date(6, 0)))
+
+	assert.Nil(t, track.SaveInvoices(&invoices))
+	invoices, err = track.LoadInvoices()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(invoices.Invoices))
+
+	invoice, err = invoices.Get("2023-0001")
+	assert.Nil(t, err)
+	assert.True(t, invoice.IsPaid())
+	assert.Equal(t, []time.Time{date(2, 8), date(3, 8)}, invoice.Records)
+
+	number, ok := invoices.InvoiceOf(date(3, 8))
+	assert.True(t, ok)
+	assert.Equal(t, "2023-0001", number)
+	assert.Equal(t, "2023-0002", invoices.NextNumber(date(1, 0)))
+	assert.Equal(t, "2024-0001", invoices.NextNumber(date(1, 0).AddDate(1, 0, 0)))
+
+	assert.Nil(t, invoices.Cancel("2023-0001"))
+	assert.NotNil(t, invoices.Cancel("2023-0001"))
+	_, ok = invoices.InvoiceOf(date(3, 8))
+	assert.False(t, ok)
+	assert.Equal(t, 3, len(invoices.Uninvoiced(records)))
+}
@@ -0,0 +1,36 @@
+package core
+
+import "github.com/mlange-42/track/util"
+
+// changesLocked checks if saving a record would change a stored record that is locked.
+// Changing only the lock itself is allowed.
+func changesLocked(stored, record *Record) bool {
+	if !stored.Locked {
+		return false
+	}
+	a, b := *stored, *record
+	a.Locked, b.Locked = false, false
+	return SerializeRecord(&a, util.NoTime) != SerializeRecord(&b, util.NoTime)
+}
+
+// SetRecordsLocked locks or unlocks records, and saves them.
+// Running records can't be locked.
+// Returns the number of records that were changed.
+func (t *Track) SetRecordsLocked(records []Record, locked bool) (int, error) {
+	count := 0
+	for i := range records {
+		rec := &records[i]
+		if rec.Locked == locked {
+			continue
+		}
+		if locked && !rec.HasEnded() {
+			continue
+		}
+		rec.Locked = locked
+		if err := t.SaveRecord(rec, true); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
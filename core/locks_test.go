@@ -0,0 +1,54 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordLocking(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	for _, format := range []string{FileFormatText, FileFormatYAML, FileFormatJSON} {
+		track.Config.RecordFileFormat = format
+
+		start := time.Date(2023, 1, 2, 8, 0, 0, 0, time.Local)
+		records := []Record{
+			{Project: "test", Start: start, End: start.Add(time.Hour), Note: "note"},
+			{Project: "test", Start: start.Add(2 * time.Hour)},
+		}
+		for i := range records {
+			assert.Nil(t, track.SaveRecord(&records[i], false), "Error saving record")
+		}
+
+		cnt, err := track.SetRecordsLocked(records, true)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, cnt, "Running records must not be locked (%s)", format)
+
+		locked, err := track.LoadRecord(start)
+		assert.Nil(t, err)
+		assert.True(t, locked.Locked, "Record not locked (%s)", format)
+
+		changed := locked
+		changed.Note = "changed"
+		assert.ErrorIs(t, track.SaveRecord(&changed, true), ErrRecordLocked)
+		changed.Locked = false
+		assert.ErrorIs(t, track.SaveRecord(&changed, true), ErrRecordLocked)
+		assert.ErrorIs(t, track.DeleteRecord(&locked), ErrRecordLocked)
+
+		cnt, err = track.SetRecordsLocked([]Record{locked}, false)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, cnt)
+
+		assert.Nil(t, track.SaveRecord(&changed, true), "Error saving unlocked record (%s)", format)
+		assert.Nil(t, track.DeleteRecord(&changed), "Error deleting unlocked record (%s)", format)
+		assert.Nil(t, track.DeleteRecord(&records[1]), "Error deleting record (%s)", format)
+	}
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slog"
+)
+
+// discardLogger is used by a Track without a Logger
+var discardLogger = slog.New(discardHandler{})
+
+// discardHandler is a slog.Handler that discards all log records
+type discardHandler struct{}
+
+func (discardHandler) Enabled(slog.Level) bool                    { return false }
+func (discardHandler) Handle(slog.Record) error                   { return nil }
+func (h discardHandler) WithAttrs(attrs []slog.Attr) slog.Handler { return h }
+func (h discardHandler) WithGroup(name string) slog.Handler       { return h }
+
+// logger returns the Track's Logger, or a logger that discards all records if none is set
+func (t *Track) logger() *slog.Logger {
+	if t.Logger == nil {
+		return discardLogger
+	}
+	return t.Logger
+}
+
+// ParseLogLevel parses a log level, one of debug, info, warn or error
+func ParseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level '%s', must be one of debug, info, warn, error", level)
+	}
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slog"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	level, err := ParseLogLevel("debug")
+	assert.Nil(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+
+	level, err = ParseLogLevel("WARN")
+	assert.Nil(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	_, err = ParseLogLevel("verbose")
+	assert.NotNil(t, err)
+}
+
+func TestLogging(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0)},
+		{Project: "other", Start: util.DateTime(2001, 2, 3, 10, 0, 0), End: util.DateTime(2001, 2, 3, 11, 0, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false), "Error saving record")
+	}
+
+	// Nothing is logged without a logger
+	_, err = track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+
+	buf := bytes.Buffer{}
+	track.Logger = slog.New(slog.HandlerOptions{Level: slog.LevelDebug}.NewTextHandler(&buf))
+
+	filters := NewFilter([]FilterFunction{FilterByProjects([]string{"test"})}, util.Date(2001, 2, 3), util.Date(2001, 2, 4))
+	loaded, err := track.LoadAllRecordsFiltered(filters)
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 1, len(loaded), "Wrong number of records")
+
+	log := buf.String()
+	assert.Contains(t, log, `msg="read record file" path=`+track.RecordPath(records[0].Start))
+	assert.Contains(t, log, `msg="record excluded" start="2001-02-03 10:00" project=other reason=filters`)
+}
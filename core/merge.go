@@ -0,0 +1,114 @@
+package core
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/mlange-42/track/util"
+)
+
+// MergeResult summarizes the changes of merging another track directory
+type MergeResult struct {
+	// Projects added
+	Projects int
+	// Records added
+	Added int
+	// Records skipped as they are identical to ours
+	Identical int
+	// Records with the same start as ours that were merged
+	Merged int
+	// Records with the same start as ours that were kept, as ours is locked
+	Locked int
+	// Running records skipped
+	Running int
+}
+
+// MergeFrom merges the projects and records of another track directory into the current workspace,
+// like after using two machines without sync.
+//
+//   - Projects that we don't have are added. Projects with the same name are kept unchanged.
+//   - Records that we don't have are added. Identical records are skipped.
+//   - Records with the same start time are merged with MergeRecords, using choose for conflicting fields.
+//     Locked records are kept unchanged.
+//   - Running records of the other directory are skipped.
+//
+// With dryRun, nothing is saved.
+func (t *Track) MergeFrom(other *Track, choose MergeChooser, dryRun bool) (MergeResult, error) {
+	result := MergeResult{}
+	if !dryRun {
+		if err := t.CheckWritable(); err != nil {
+			return result, err
+		}
+	}
+
+	ourProjects, err := t.LoadAllProjects()
+	if err != nil {
+		return result, err
+	}
+	theirProjects, err := other.LoadAllProjects()
+	if err != nil {
+		return result, err
+	}
+	names := []string{}
+	for name := range theirProjects {
+		if _, ok := ourProjects[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	result.Projects = len(names)
+
+	records, err := other.LoadAllRecords()
+	if err != nil {
+		return result, err
+	}
+	// Merge all records before saving anything, so that conflicts don't leave a partial merge
+	toSave := []Record{}
+	for i := range records {
+		theirs := &records[i]
+		if !theirs.HasEnded() {
+			result.Running++
+			continue
+		}
+		ours, err := t.LoadRecord(theirs.Start)
+		if err != nil {
+			if !errors.Is(err, ErrRecordNotFound) {
+				return result, err
+			}
+			toSave = append(toSave, *theirs)
+			result.Added++
+			continue
+		}
+		if SerializeRecord(&ours, util.NoTime) == SerializeRecord(theirs, util.NoTime) {
+			result.Identical++
+			continue
+		}
+		if ours.Locked {
+			result.Locked++
+			continue
+		}
+		merged, err := MergeRecords(t.Syntax(), nil, ours, *theirs, choose)
+		if err != nil {
+			return result, err
+		}
+		toSave = append(toSave, merged)
+		result.Merged++
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	for _, name := range names {
+		if err := t.SaveProject(theirProjects[name], false); err != nil {
+			return result, err
+		}
+	}
+	tx := t.Begin()
+	for i := range toSave {
+		tx.Save(&toSave[i], true)
+	}
+	if err := tx.Commit(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeFrom(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	if err != nil {
+		t.Fatal("error creating temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	oursDir, theirsDir := filepath.Join(dir, "ours"), filepath.Join(dir, "theirs")
+	ours, err := NewTrack(&oursDir)
+	if err != nil {
+		t.Fatal("error creating Track instance")
+	}
+	theirs, err := NewTrack(&theirsDir)
+	if err != nil {
+		t.Fatal("error creating Track instance")
+	}
+
+	assert.Nil(t, ours.SaveProject(NewProject("app", "", "A", []string{}, 0, 15), false))
+	assert.Nil(t, theirs.SaveProject(NewProject("app", "", "B", []string{}, 0, 15), false))
+	assert.Nil(t, theirs.SaveProject(NewProject("ops", "", "O", []string{}, 0, 15), false))
+
+	day := util.Date(2023, 1, 2)
+	at := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+
+	oursRecords := []Record{
+		{Project: "app", Start: at(8), End: at(9), Note: "same", Tags: map[string]string{}},
+		{Project: "app", Start: at(10), End: at(11), Note: "ours", Tags: map[string]string{}},
+		{Project: "app", Start: at(12), End: at(13), Note: "locked", Tags: map[string]string{}, Locked: true},
+	}
+	theirsRecords := []Record{
+		{Project: "app", Start: at(8), End: at(9), Note: "same", Tags: map[string]string{}},
+		{Project: "app", Start: at(10), End: at(12), Note: "theirs", Tags: map[string]string{}},
+		{Project: "app", Start: at(12), End: at(14), Note: "changed", Tags: map[string]string{}},
+		{Project: "ops", Start: at(15), End: at(16), Note: "new", Tags: map[string]string{}},
+		{Project: "ops", Start: at(17), Note: "running", Tags: map[string]string{}},
+	}
+	for i := range oursRecords {
+		assert.Nil(t, ours.SaveRecord(&oursRecords[i], false))
+	}
+	for i := range theirsRecords {
+		assert.Nil(t, theirs.SaveRecord(&theirsRecords[i], false))
+	}
+
+	other, err := OpenReadOnly(theirsDir)
+	assert.Nil(t, err)
+
+	_, err = ours.MergeFrom(other, nil, false)
+	assert.ErrorIs(t, err, ErrMergeConflict)
+
+	preferTheirs := func(field, ours, theirs string) (string, error) { return theirs, nil }
+	result, err := ours.MergeFrom(other, preferTheirs, true)
+	assert.Nil(t, err)
+	assert.Equal(t, MergeResult{Projects: 1, Added: 1, Identical: 1, Merged: 1, Locked: 1, Running: 1}, result)
+	_, err = ours.LoadProject("ops")
+	assert.NotNil(t, err, "dry run must not change anything")
+
+	result, err = ours.MergeFrom(other, preferTheirs, false)
+	assert.Nil(t, err)
+	assert.Equal(t, MergeResult{Projects: 1, Added: 1, Identical: 1, Merged: 1, Locked: 1, Running: 1}, result)
+
+	project, err := ours.LoadProject("app")
+	assert.Nil(t, err)
+	assert.Equal(t, "A", project.Symbol)
+	_, err = ours.LoadProject("ops")
+	assert.Nil(t, err)
+
+	rec, err := ours.LoadRecord(at(10))
+	assert.Nil(t, err)
+	assert.Equal(t, "theirs", rec.Note)
+	assert.Equal(t, at(12), rec.End)
+
+	rec, err = ours.LoadRecord(at(12))
+	assert.Nil(t, err)
+	assert.Equal(t, "locked", rec.Note)
+
+	_, err = ours.LoadRecord(at(15))
+	assert.Nil(t, err)
+	_, err = ours.LoadRecord(at(17))
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+
+	result, err = ours.MergeFrom(other, preferTheirs, false)
+	assert.Nil(t, err)
+	assert.Equal(t, MergeResult{Identical: 3, Locked: 1, Running: 1}, result)
+}
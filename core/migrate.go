@@ -0,0 +1,147 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecordFormatVersion is the current version of the record file format
+const RecordFormatVersion = 2
+
+// FormatHeaderPrefix denotes the format version header in record files
+const FormatHeaderPrefix = "#!format "
+
+// recordMigration upgrades the content of a record file by one format version.
+// The content passed to Migrate does not contain the format header.
+type recordMigration struct {
+	From        int
+	Description string
+	Migrate     func(content string) (string, error)
+}
+
+// recordMigrations are all migrations, in order of their source version.
+// Migration from version N is at index N-1.
+var recordMigrations = []recordMigration{
+	{
+		From:        1,
+		Description: "add format version header",
+		Migrate:     func(content string) (string, error) { return content, nil },
+	},
+}
+
+// MigrationResult reports the outcome of migrating record files
+type MigrationResult struct {
+	Checked  int
+	Migrated int
+}
+
+// RecordFormat returns the format version of the content of a record file, like Syntax.RecordFormat,
+// with the DefaultSyntax.
+func RecordFormat(content string) (int, string, error) {
+	return DefaultSyntax().RecordFormat(content)
+}
+
+// RecordFormat returns the format version of the content of a record file,
+// and the content without the version header.
+//
+// Files without a header have version 1.
+func (s *Syntax) RecordFormat(content string) (int, string, error) {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if !s.isHeaderComment(line) {
+			break
+		}
+		if !strings.HasPrefix(line, FormatHeaderPrefix) {
+			continue
+		}
+		version, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, FormatHeaderPrefix)))
+		if err != nil || version < 1 {
+			return 0, content, fmt.Errorf("invalid record format header '%s'", strings.TrimSpace(line))
+		}
+		lines = append(lines[:i], lines[i+1:]...)
+		return version, strings.Join(lines, "\n"), nil
+	}
+	return 1, content, nil
+}
+
+// migrateRecordContent upgrades the content of a record file to the current format version.
+// The returned content does not contain the format header.
+func (s *Syntax) migrateRecordContent(content string) (string, int, error) {
+	version, content, err := s.RecordFormat(content)
+	if err != nil {
+		return content, version, err
+	}
+	if version > RecordFormatVersion {
+		return content, version, fmt.Errorf("record format version %d is newer than supported version %d; please update track", version, RecordFormatVersion)
+	}
+	for v := version; v < RecordFormatVersion; v++ {
+		content, err = recordMigrations[v-1].Migrate(content)
+		if err != nil {
+			return content, version, fmt.Errorf("failed to migrate record from format version %d: %s", v, err)
+		}
+	}
+	return content, version, nil
+}
+
+// MigrateRecords upgrades all record files of the current workspace to the current format version, in place.
+func (t *Track) MigrateRecords() (MigrationResult, error) {
+	if err := t.CheckWritable(); err != nil {
+		return MigrationResult{}, err
+	}
+	result := MigrationResult{}
+
+	var migrateErr error
+	err := t.walkDays(FilterFunctions{}, false, func(date time.Time) bool {
+		times, err := t.listDateRecords(date)
+		if err != nil {
+			migrateErr = err
+			return false
+		}
+		for _, tm := range times {
+			result.Checked++
+			migrated, err := t.migrateRecord(tm)
+			if err != nil {
+				migrateErr = fmt.Errorf("record %s: %s", t.RecordPath(tm), err)
+				return false
+			}
+			if migrated {
+				result.Migrated++
+			}
+		}
+		return true
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return result, err
+	}
+	return result, migrateErr
+}
+
+// migrateRecord upgrades a record file to the current format version.
+// Returns whether the file was changed.
+func (t *Track) migrateRecord(tm time.Time) (bool, error) {
+	// Only files in text format are versioned
+	path := t.recordFilePath(tm, FileFormatText)
+	if !t.fileExists(path) {
+		return false, nil
+	}
+	file, err := t.readRecordData(path)
+	if err != nil {
+		return false, err
+	}
+	content, version, err := t.Syntax().migrateRecordContent(string(file))
+	if err != nil {
+		return false, err
+	}
+	if version == RecordFormatVersion {
+		return false, nil
+	}
+	record, err := t.Syntax().DeserializeRecord(content, tm)
+	if err != nil {
+		return false, err
+	}
+	return true, t.writeRecord(&record, FileFormatText)
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordFormat(t *testing.T) {
+	version, content, err := RecordFormat("# Record 2001-02-03 08:00\n#!format 2\n08:00 - 09:00\n    test\n")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, version)
+	assert.Equal(t, "# Record 2001-02-03 08:00\n08:00 - 09:00\n    test\n", content)
+
+	version, content, err = RecordFormat("# Record 2001-02-03 08:00\n08:00 - 09:00\n    test\n")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, version)
+	assert.Equal(t, "# Record 2001-02-03 08:00\n08:00 - 09:00\n    test\n", content)
+
+	_, _, err = RecordFormat("#!format x\n08:00 - 09:00\n    test\n")
+	assert.NotNil(t, err)
+}
+
+func TestMigrateRecords(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	legacy := util.DateTime(2001, 2, 3, 8, 0, 0)
+	current := util.DateTime(2001, 2, 4, 8, 0, 0)
+	future := util.DateTime(2001, 2, 5, 8, 0, 0)
+
+	assert.Nil(t, util.CreateDir(track.RecordDir(legacy)))
+	assert.Nil(t, os.WriteFile(track.RecordPath(legacy), []byte("# Record\n08:00 - 09:00\n    test\n\nNote +tag\n"), 0600))
+
+	record := Record{Project: "test", Start: current, End: current.Add(time.Hour)}
+	assert.Nil(t, track.SaveRecord(&record, false))
+
+	loaded, err := track.LoadRecord(legacy)
+	assert.Nil(t, err, "Error loading legacy record")
+	assert.Equal(t, "Note +tag", loaded.Note)
+
+	result, err := track.MigrateRecords()
+	assert.Nil(t, err, "Error migrating records")
+	assert.Equal(t, MigrationResult{Checked: 2, Migrated: 1}, result)
+
+	content, err := os.ReadFile(track.RecordPath(legacy))
+	assert.Nil(t, err)
+	version, _, err := RecordFormat(string(content))
+	assert.Nil(t, err)
+	assert.Equal(t, RecordFormatVersion, version)
+
+	migrated, err := track.LoadRecord(legacy)
+	assert.Nil(t, err, "Error loading migrated record")
+	assert.Equal(t, loaded, migrated)
+
+	result, err = track.MigrateRecords()
+	assert.Nil(t, err, "Error migrating records")
+	assert.Equal(t, MigrationResult{Checked: 2, Migrated: 0}, result)
+
+	assert.Nil(t, util.CreateDir(track.RecordDir(future)))
+	assert.Nil(t, os.WriteFile(track.RecordPath(future), []byte("#!format 99\n08:00 - 09:00\n    test\n"), 0600))
+	_, err = track.LoadRecord(future)
+	assert.NotNil(t, err, "Expected error for unsupported format version")
+}
@@ -60,3 +60,43 @@ func (t *Track) RecordPath(tm time.Time) string {
 		fmt.Sprintf("%s.trk", tm.Format(util.FileTimeFormat)),
 	)
 }
+
+// JournalPath returns the path of the journal file of bulk edits in the current workspace
+func (t *Track) JournalPath() string {
+	return filepath.Join(t.RootDir, t.Workspace(), journalFile)
+}
+
+// ReportCachePath returns the path of the report cache file of the current workspace
+func (t *Track) ReportCachePath() string {
+	return filepath.Join(t.RootDir, t.Workspace(), reportCacheFile)
+}
+
+// DeletionsPath returns the path of the deletion log of the current workspace
+func (t *Track) DeletionsPath() string {
+	return filepath.Join(t.RootDir, t.Workspace(), deletionsFile)
+}
+
+// TrashDir returns the directory for deleted records of the current workspace
+func (t *Track) TrashDir() string {
+	return filepath.Join(t.RootDir, t.Workspace(), trashDirName)
+}
+
+// HistoryDir returns the directory for record histories of the current workspace
+func (t *Track) HistoryDir() string {
+	return filepath.Join(t.RootDir, t.Workspace(), historyDirName)
+}
+
+// InvoicesPath returns the path of the invoice registry of the current workspace
+func (t *Track) InvoicesPath() string {
+	return filepath.Join(t.RootDir, t.Workspace(), invoicesFile)
+}
+
+// AbsencesPath returns the path of the absences file of the current workspace
+func (t *Track) AbsencesPath() string {
+	return filepath.Join(t.RootDir, t.Workspace(), absencesFile)
+}
+
+// BreaksPath returns the path of the breaks file of the current workspace
+func (t *Track) BreaksPath() string {
+	return filepath.Join(t.RootDir, t.Workspace(), breaksFile)
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// PauseDay is the pause time of a single day
+type PauseDay struct {
+	Date time.Time
+	// Number of pauses starting on the day, or reaching into it from the day before
+	Count int
+	// Total pause time
+	Total time.Duration
+	// Longest pause time
+	Longest time.Duration
+}
+
+// PauseStats are statistics of the pauses of records
+type PauseStats struct {
+	// Days with pauses, in chronological order
+	Days []PauseDay
+	// Number of pauses
+	Count int
+	// Total pause time
+	Total time.Duration
+	// Longest pause, clipped to the time range
+	Longest Pause
+	// Record of the longest pause
+	LongestRecord Record
+}
+
+// Average returns the average length of pauses. Returns zero if there are no pauses.
+func (s *PauseStats) Average() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// NewPauseStats calculates pause statistics of records, with pauses clipped to the given time range and by day.
+// Zero start and end mean no limit. Open pauses are considered to end at the given time.
+func NewPauseStats(records []Record, start, end, now time.Time) PauseStats {
+	stats := PauseStats{}
+	days := map[time.Time]*PauseDay{}
+
+	for i := range records {
+		rec := &records[i]
+		for _, p := range rec.Pause {
+			pStart, pEnd := p.Start, p.End
+			if pEnd.IsZero() {
+				pEnd = now
+			}
+			if !start.IsZero() && pStart.Before(start) {
+				pStart = start
+			}
+			if !end.IsZero() && pEnd.After(end) {
+				pEnd = end
+			}
+			if !pEnd.After(pStart) {
+				continue
+			}
+
+			dur := pEnd.Sub(pStart)
+			stats.Count++
+			stats.Total += dur
+			if dur > stats.Longest.End.Sub(stats.Longest.Start) {
+				stats.Longest = Pause{Start: pStart, End: pEnd, Note: p.Note}
+				stats.LongestRecord = *rec
+			}
+
+			for day := util.ToDate(pStart); day.Before(pEnd); day = day.AddDate(0, 0, 1) {
+				dayDur := util.DurationClip(pStart, pEnd, day, day.AddDate(0, 0, 1))
+				if dayDur <= 0 {
+					continue
+				}
+				d, ok := days[day]
+				if !ok {
+					d = &PauseDay{Date: day}
+					days[day] = d
+				}
+				d.Count++
+				d.Total += dayDur
+				if dayDur > d.Longest {
+					d.Longest = dayDur
+				}
+			}
+		}
+	}
+
+	stats.Days = make([]PauseDay, 0, len(days))
+	for _, d := range days {
+		stats.Days = append(stats.Days, *d)
+	}
+	sort.Slice(stats.Days, func(i, j int) bool { return stats.Days[i].Date.Before(stats.Days[j].Date) })
+
+	return stats
+}
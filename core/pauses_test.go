@@ -0,0 +1,53 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPauseStats(t *testing.T) {
+	records := []Record{
+		{
+			Project: "A",
+			Start:   util.DateTime(2001, 2, 3, 8, 0, 0),
+			End:     util.DateTime(2001, 2, 4, 2, 0, 0),
+			Pause: []Pause{
+				{Start: util.DateTime(2001, 2, 3, 12, 0, 0), End: util.DateTime(2001, 2, 3, 12, 45, 0), Note: "lunch"},
+				{Start: util.DateTime(2001, 2, 3, 23, 30, 0), End: util.DateTime(2001, 2, 4, 0, 30, 0)},
+			},
+		},
+		{
+			Project: "B",
+			Start:   util.DateTime(2001, 2, 5, 8, 0, 0),
+			Pause: []Pause{
+				{Start: util.DateTime(2001, 2, 5, 9, 0, 0)},
+			},
+		},
+	}
+	now := util.DateTime(2001, 2, 5, 9, 15, 0)
+
+	stats := NewPauseStats(records, util.NoTime, util.NoTime, now)
+	assert.Equal(t, 3, stats.Count, "Wrong number of pauses")
+	assert.Equal(t, 2*time.Hour, stats.Total, "Wrong total pause time")
+	assert.Equal(t, 40*time.Minute, stats.Average(), "Wrong average pause time")
+	assert.Equal(t, time.Hour, stats.Longest.End.Sub(stats.Longest.Start), "Wrong longest pause")
+	assert.Equal(t, "A", stats.LongestRecord.Project, "Wrong record of longest pause")
+
+	assert.Equal(t, []PauseDay{
+		{Date: util.Date(2001, 2, 3), Count: 2, Total: 75 * time.Minute, Longest: 45 * time.Minute},
+		{Date: util.Date(2001, 2, 4), Count: 1, Total: 30 * time.Minute, Longest: 30 * time.Minute},
+		{Date: util.Date(2001, 2, 5), Count: 1, Total: 15 * time.Minute, Longest: 15 * time.Minute},
+	}, stats.Days, "Wrong daily pauses")
+
+	stats = NewPauseStats(records, util.Date(2001, 2, 4), util.Date(2001, 2, 5), now)
+	assert.Equal(t, 1, stats.Count, "Wrong number of pauses")
+	assert.Equal(t, 30*time.Minute, stats.Total, "Wrong clipped pause time")
+	assert.Equal(t, util.Date(2001, 2, 4), stats.Longest.Start, "Longest pause should be clipped")
+
+	stats = NewPauseStats([]Record{}, util.NoTime, util.NoTime, now)
+	assert.Equal(t, time.Duration(0), stats.Average(), "Wrong average without pauses")
+	assert.Equal(t, 0, len(stats.Days), "Wrong number of days")
+}
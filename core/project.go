@@ -1,9 +1,14 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/gookit/color"
 	"github.com/mlange-42/track/util"
@@ -34,6 +39,25 @@ type Project struct {
 	Render       color.Style256 `yaml:"-"`
 	Symbol       string
 	Archived     bool
+	// Estimated total effort, including sub-projects. Zero for no estimate
+	Estimate time.Duration `yaml:"estimate,omitempty"`
+	// Hourly rates, with the dates they are valid from
+	Rates []Rate `yaml:"rates,omitempty"`
+	// Currency of rates without an explicit currency, like EUR or USD.
+	// Defaults to the currency in the config
+	Currency string `yaml:"currency,omitempty"`
+	// Default category of new records in the project, like meeting. Empty for no category
+	Category string `yaml:"category,omitempty"`
+}
+
+// ProjectNotFoundError is an error for a project that does not exist
+type ProjectNotFoundError struct {
+	// Name of the project
+	Name string
+}
+
+func (e *ProjectNotFoundError) Error() string {
+	return fmt.Sprintf("project '%s' does not exist", e.Name)
 }
 
 // NewProject creates a new project
@@ -59,6 +83,10 @@ type tempProject struct {
 	FgColor      uint8 `yaml:"fgColor"`
 	Symbol       string
 	Archived     bool
+	Estimate     time.Duration `yaml:"estimate,omitempty"`
+	Rates        []Rate        `yaml:"rates,omitempty"`
+	Currency     string        `yaml:"currency,omitempty"`
+	Category     string        `yaml:"category,omitempty"`
 }
 
 // GetName implements the Named interface required for the MapTree
@@ -78,6 +106,10 @@ func (p *Project) UnmarshalYAML(value *yaml.Node) error {
 	p.RequiredTags = tmp.RequiredTags
 	p.Symbol = tmp.Symbol
 	p.Archived = tmp.Archived
+	p.Estimate = tmp.Estimate
+	p.Rates = tmp.Rates
+	p.Currency = strings.ToUpper(tmp.Currency)
+	p.Category = tmp.Category
 
 	p.SetColors(tmp.FgColor, tmp.Color)
 
@@ -93,48 +125,45 @@ func (p *Project) SetColors(fgCol, col uint8) {
 
 // ProjectExists checks if a project exists on disk
 func (t *Track) ProjectExists(name string) bool {
-	return util.FileExists(t.ProjectPath(name))
+	return t.fileExists(t.ProjectPath(name))
 }
 
 // SaveProject saves a project to disk.
 // Argument `force` allows to overwrite an existing file.
 func (t *Track) SaveProject(project Project, force bool) error {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
 	path := t.ProjectPath(project.Name)
 
-	if !force && util.FileExists(path) {
+	if !force && t.fileExists(path) {
 		return fmt.Errorf("Project '%s' already exists", project.Name)
 	}
 
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
 	bytes, err := yaml.Marshal(&project)
 	if err != nil {
 		return err
 	}
 
-	_, err = fmt.Fprintf(file, "%s Project %s\n\n", YamlCommentPrefix, project.Name)
-	if err != nil {
-		return err
-	}
-
-	_, err = file.Write(bytes)
-
-	return err
+	content := fmt.Sprintf("%s Project %s\n\n%s", YamlCommentPrefix, project.Name, bytes)
+	return t.FileSystem().WriteFile(path, []byte(content), 0600)
 }
 
 // LoadProject loads a project by it's name
+//
+// Fails with a *ProjectNotFoundError if there is no project with the given name.
 func (t *Track) LoadProject(name string) (Project, error) {
 	path := t.ProjectPath(name)
-	return t.loadProjectFromFile(path)
+	project, err := t.loadProjectFromFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Project{}, &ProjectNotFoundError{Name: name}
+	}
+	return project, err
 }
 
 // loadProjectFromFile loads a project from the given path
 func (t *Track) loadProjectFromFile(path string) (Project, error) {
-	file, err := os.ReadFile(path)
+	file, err := t.FileSystem().ReadFile(path)
 	if err != nil {
 		return Project{}, err
 	}
@@ -152,7 +181,7 @@ func (t *Track) loadProjectFromFile(path string) (Project, error) {
 func (t *Track) LoadAllProjects() (map[string]Project, error) {
 	path := t.ProjectsDir()
 
-	files, err := os.ReadDir(path)
+	files, err := t.FileSystem().ReadDir(path)
 	if err != nil {
 		return nil, err
 	}
@@ -177,6 +206,11 @@ func (t *Track) LoadAllProjects() (map[string]Project, error) {
 // Argument `deleteRecords` determines whether records should be deleted
 // Argument `dryRun` can be used to dry-run deleting
 func (t *Track) DeleteProject(project *Project, deleteRecords bool, dryRun bool) (int, error) {
+	if !dryRun {
+		if err := t.CheckWritable(); err != nil {
+			return 0, err
+		}
+	}
 	counter := 0
 
 	if deleteRecords {
@@ -202,7 +236,7 @@ func (t *Track) DeleteProject(project *Project, deleteRecords bool, dryRun bool)
 	}
 
 	if !dryRun {
-		err := os.Remove(t.ProjectPath(project.Name))
+		err := t.FileSystem().Remove(t.ProjectPath(project.Name))
 		if err != nil {
 			return counter, err
 		}
@@ -266,10 +300,159 @@ func (t *Track) checkParentsRecursive(p Project, start Project, projects map[str
 	}
 	parent, ok := projects[p.Parent]
 	if !ok {
-		return fmt.Errorf("project '%s' does not exist", p.Parent)
+		return &ProjectNotFoundError{Name: p.Parent}
 	}
 	if parent.Name == start.Name {
 		return fmt.Errorf("circular parent relationship")
 	}
 	return t.checkParentsRecursive(parent, start, projects)
 }
+
+// ProjectPathName returns the hierarchical path of a project, like "clients/acme/website"
+func ProjectPathName(name string, projects map[string]Project) string {
+	path := name
+	visited := map[string]bool{name: true}
+	for {
+		p, ok := projects[name]
+		if !ok || p.Parent == "" || visited[p.Parent] {
+			return path
+		}
+		name = p.Parent
+		visited[name] = true
+		path = name + "/" + path
+	}
+}
+
+// ResolveProject loads a project by its name, or by an unambiguous abbreviation or misspelling of its name.
+// See ResolveProjectName for details.
+func (t *Track) ResolveProject(name string) (Project, error) {
+	projects, err := t.LoadAllProjects()
+	if err != nil {
+		return Project{}, err
+	}
+	resolved, err := ResolveProjectName(name, projects)
+	if err != nil {
+		return Project{}, err
+	}
+	return projects[resolved], nil
+}
+
+// ResolveProjectName resolves a project name from an exact name, a prefix or a fuzzy match.
+//
+// Matching is performed in stages, and the first stage with any matches is used:
+//  1. exact name or hierarchical path, like "website" or "clients/acme/website"
+//  2. case-insensitive prefix of the name or path, or suffix of the path, like "webs" or "acme/website"
+//  3. fuzzy match: the characters appear in the name in order, like "wbst", or a small number of typos
+//
+// Returns an error if no project or multiple projects match.
+func ResolveProjectName(name string, projects map[string]Project) (string, error) {
+	if _, ok := projects[name]; ok {
+		return name, nil
+	}
+	if name == "" {
+		return "", fmt.Errorf("empty project name")
+	}
+
+	paths := make(map[string]string, len(projects))
+	for n := range projects {
+		paths[n] = strings.ToLower(ProjectPathName(n, projects))
+	}
+	lower := strings.ToLower(name)
+
+	stages := []func(n, path string) bool{
+		func(n, path string) bool {
+			return path == lower
+		},
+		func(n, path string) bool {
+			return strings.HasPrefix(strings.ToLower(n), lower) ||
+				strings.HasPrefix(path, lower) ||
+				strings.HasSuffix(path, "/"+lower)
+		},
+		func(n, path string) bool {
+			n = strings.ToLower(n)
+			return util.IsSubsequence(lower, n) ||
+				util.Levenshtein(lower, n) <= len([]rune(n))/4
+		},
+	}
+
+	for _, match := range stages {
+		candidates := []string{}
+		for n, path := range paths {
+			if match(n, path) {
+				candidates = append(candidates, n)
+			}
+		}
+		if len(candidates) == 1 {
+			return candidates[0], nil
+		}
+		if len(candidates) > 1 {
+			sort.Strings(candidates)
+			return "", fmt.Errorf("ambiguous project '%s', could be any of: %s", name, strings.Join(candidates, ", "))
+		}
+	}
+	return "", &ProjectNotFoundError{Name: name}
+}
+
+// IsProjectPattern checks if a project argument is a glob pattern, like "clients/*"
+func IsProjectPattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// MatchProjectPattern returns the names of all projects matching a glob pattern, sorted by name.
+//
+// Patterns are matched against the hierarchical path of projects, like "clients/acme/website",
+// and patterns without a slash also against the project name.
+// Wildcard '*' does not match slashes, so "clients/*" matches the direct children of project "clients",
+// and "*/website" matches projects "website" with a top-level parent.
+//
+// Returns an error if the pattern is malformed or matches no project.
+func MatchProjectPattern(pattern string, projects map[string]Project) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid project pattern '%s': %s", pattern, err)
+	}
+	matches := []string{}
+	for n := range projects {
+		ok, _ := path.Match(pattern, ProjectPathName(n, projects))
+		if !ok && !strings.Contains(pattern, "/") {
+			ok, _ = path.Match(pattern, n)
+		}
+		if ok {
+			matches = append(matches, n)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no project matches '%s'", pattern)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// ResolveProjectNames resolves project arguments that can be names or glob patterns.
+// Names are resolved by ResolveProjectName, patterns by MatchProjectPattern.
+// The result contains each project only once, in the order of the arguments.
+func ResolveProjectNames(names []string, projects map[string]Project) ([]string, error) {
+	resolved := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		var matches []string
+		if IsProjectPattern(name) {
+			var err error
+			if matches, err = MatchProjectPattern(name, projects); err != nil {
+				return nil, err
+			}
+		} else {
+			match, err := ResolveProjectName(name, projects)
+			if err != nil {
+				return nil, err
+			}
+			matches = []string{match}
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				resolved = append(resolved, m)
+			}
+		}
+	}
+	return resolved, nil
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"errors"
 	"os"
 	"testing"
 
@@ -124,6 +125,11 @@ func TestSaveLoadProject(t *testing.T) {
 	assert.False(t, util.FileExists(track.ProjectPath("test")), "File must not exist")
 	assert.False(t, track.ProjectExists("test"), "Project must not exist")
 
+	_, err = track.LoadProject("test")
+	var notFound *ProjectNotFoundError
+	assert.True(t, errors.As(err, &notFound), "Expected project not found error")
+	assert.Equal(t, "test", notFound.Name, "Wrong project name in error")
+
 	project := NewProject("test", "", "T", []string{"a", "b"}, 0, 15)
 	err = track.SaveProject(project, false)
 	assert.Nil(t, err, "Error saving project")
@@ -190,3 +196,84 @@ func TestCheckParents(t *testing.T) {
 	assert.True(t, track.CheckParents(p2) != nil, "Expected error in parent check")
 	assert.True(t, track.CheckParents(p3) != nil, "Expected error in parent check")
 }
+
+func TestResolveProjectName(t *testing.T) {
+	projects := map[string]Project{
+		"clients": NewProject("clients", "", "c", []string{}, 0, 0),
+		"acme":    NewProject("acme", "clients", "a", []string{}, 0, 0),
+		"website": NewProject("website", "acme", "w", []string{}, 0, 0),
+		"webapp":  NewProject("webapp", "acme", "w", []string{}, 0, 0),
+		"private": NewProject("private", "", "p", []string{}, 0, 0),
+	}
+
+	assert.Equal(t, "clients/acme/website", ProjectPathName("website", projects))
+	assert.Equal(t, "clients", ProjectPathName("clients", projects))
+
+	_, err := ResolveProjectName("xyz", projects)
+	var notFound *ProjectNotFoundError
+	assert.True(t, errors.As(err, &notFound), "Expected project not found error")
+
+	tests := []struct {
+		name     string
+		expected string
+		err      bool
+	}{
+		{"website", "website", false},
+		{"clients/acme/website", "website", false},
+		{"webs", "website", false},
+		{"Webs", "website", false},
+		{"acme/webapp", "webapp", false},
+		{"wbst", "website", false},
+		{"privte", "private", false},
+		{"web", "", true},
+		{"xyz", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		name, err := ResolveProjectName(tt.name, projects)
+		if tt.err {
+			assert.NotNil(t, err, "Expected error for '%s'", tt.name)
+			continue
+		}
+		assert.Nil(t, err, "Unexpected error for '%s'", tt.name)
+		assert.Equal(t, tt.expected, name, "Wrong project for '%s'", tt.name)
+	}
+}
+
+func TestResolveProjectNames(t *testing.T) {
+	projects := map[string]Project{
+		"clients": NewProject("clients", "", "c", []string{}, 0, 0),
+		"acme":    NewProject("acme", "clients", "a", []string{}, 0, 0),
+		"globex":  NewProject("globex", "clients", "g", []string{}, 0, 0),
+		"website": NewProject("website", "acme", "w", []string{}, 0, 0),
+		"webapp":  NewProject("webapp", "acme", "w", []string{}, 0, 0),
+		"private": NewProject("private", "", "p", []string{}, 0, 0),
+	}
+
+	tests := []struct {
+		names    []string
+		expected []string
+		err      bool
+	}{
+		{[]string{"clients/*"}, []string{"acme", "globex"}, false},
+		{[]string{"*/acme/*"}, []string{"webapp", "website"}, false},
+		{[]string{"web*"}, []string{"webapp", "website"}, false},
+		{[]string{"*/acme"}, []string{"acme"}, false},
+		{[]string{"private", "clients/*", "acme"}, []string{"private", "acme", "globex"}, false},
+		{[]string{"webs"}, []string{"website"}, false},
+		{[]string{"*/website"}, nil, true},
+		{[]string{"clients/[a"}, nil, true},
+		{[]string{"xyz"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		names, err := ResolveProjectNames(tt.names, projects)
+		if tt.err {
+			assert.NotNil(t, err, "Expected error for %v", tt.names)
+			continue
+		}
+		assert.Nil(t, err, "Unexpected error for %v", tt.names)
+		assert.Equal(t, tt.expected, names, "Wrong projects for %v", tt.names)
+	}
+}
@@ -0,0 +1,252 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectData is all data belonging to a project and its sub-projects
+type ProjectData struct {
+	// The projects
+	Projects []Project `yaml:"projects"`
+	// Records of the projects
+	Records []Record `yaml:"records"`
+	// Deleted records of the projects, from the trash
+	Trash []TrashedRecord `yaml:"trash"`
+	// Versions of records that belonged to the projects, from the record history
+	History []RecordVersion `yaml:"history"`
+	// Invoices of the projects
+	Invoices []Invoice `yaml:"invoices"`
+
+	trashFiles   []string
+	historyFiles []string
+}
+
+// PurgeReport is a report of permanently deleted project data
+type PurgeReport struct {
+	// Time of the deletion
+	Purged time.Time
+	// Names of the deleted projects
+	Projects []string
+	// Number of deleted records
+	Records int
+	// Total time of the deleted records
+	Time time.Duration
+	// Start of the first and the last deleted record
+	First, Last time.Time
+	// Number of deleted records in the trash
+	Trash int
+	// Number of deleted record history files
+	History int
+	// Numbers of the deleted invoices
+	Invoices []string
+}
+
+// CollectProjectData collects all data belonging to a project and its sub-projects,
+// including locked records, records in the trash, record history and invoices.
+func (t *Track) CollectProjectData(name string) (ProjectData, error) {
+	data := ProjectData{
+		Projects: []Project{},
+		Records:  []Record{},
+		Trash:    []TrashedRecord{},
+		History:  []RecordVersion{},
+		Invoices: []Invoice{},
+	}
+
+	projects, err := t.LoadAllProjects()
+	if err != nil {
+		return data, err
+	}
+	tree, err := t.ToProjectTree(projects)
+	if err != nil {
+		return data, err
+	}
+	desc, ok := tree.Descendants(name)
+	if !ok {
+		return data, &ProjectNotFoundError{Name: name}
+	}
+	names := map[string]bool{name: true}
+	data.Projects = append(data.Projects, projects[name])
+	for _, p := range desc {
+		names[p.Value.Name] = true
+		data.Projects = append(data.Projects, p.Value)
+	}
+	sort.Slice(data.Projects, func(i, j int) bool { return data.Projects[i].Name < data.Projects[j].Name })
+
+	filters := NewFilter([]FilterFunction{FilterByProjects(maps.Keys(names))}, util.NoTime, util.NoTime)
+	filters.IncludeArchived = true
+	data.Records, err = t.LoadAllRecordsFiltered(filters)
+	if err != nil {
+		return data, err
+	}
+	if data.Records == nil {
+		data.Records = []Record{}
+	}
+
+	trash, err := t.DeletedRecords()
+	if err != nil {
+		return data, err
+	}
+	for _, entry := range trash {
+		if names[entry.Record.Project] {
+			data.Trash = append(data.Trash, entry)
+			data.trashFiles = append(data.trashFiles, filepath.Join(t.TrashDir(), entry.file))
+		}
+	}
+
+	err = t.walkDir(t.HistoryDir(), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != historyFileExt {
+			return nil
+		}
+		versions, err := t.readHistoryFile(path)
+		if err != nil {
+			return err
+		}
+		for _, v := range versions {
+			if names[v.Record.Project] {
+				data.History = append(data.History, versions...)
+				data.historyFiles = append(data.historyFiles, path)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return data, err
+	}
+
+	invoices, err := t.LoadInvoices()
+	if err != nil {
+		return data, err
+	}
+	for _, inv := range invoices.Invoices {
+		if names[inv.Project] {
+			data.Invoices = append(data.Invoices, inv)
+		}
+	}
+
+	return data, nil
+}
+
+// Report returns a report of deleting the data
+func (d *ProjectData) Report(purged time.Time) PurgeReport {
+	report := PurgeReport{
+		Purged:   purged,
+		Projects: make([]string, len(d.Projects)),
+		Records:  len(d.Records),
+		Trash:    len(d.trashFiles),
+		History:  len(d.historyFiles),
+		Invoices: make([]string, len(d.Invoices)),
+	}
+	for i, p := range d.Projects {
+		report.Projects[i] = p.Name
+	}
+	for i, inv := range d.Invoices {
+		report.Invoices[i] = inv.Number
+	}
+	for i := range d.Records {
+		rec := &d.Records[i]
+		report.Time += rec.Duration(util.NoTime, util.NoTime)
+		if report.First.IsZero() || rec.Start.Before(report.First) {
+			report.First = rec.Start
+		}
+		if rec.Start.After(report.Last) {
+			report.Last = rec.Start
+		}
+	}
+	return report
+}
+
+// PurgeProject exports all data of a project and its sub-projects as YAML,
+// and then permanently deletes it, including locked records, records in the trash,
+// record history and invoices.
+// Nothing is deleted if the export fails. Argument export may be nil to skip the export.
+//
+// Deleted data can't be restored, neither from the trash nor from the record history.
+func (t *Track) PurgeProject(name string, export io.Writer) (PurgeReport, error) {
+	if err := t.CheckWritable(); err != nil {
+		return PurgeReport{}, err
+	}
+	data, err := t.CollectProjectData(name)
+	if err != nil {
+		return PurgeReport{}, err
+	}
+	report := data.Report(time.Now())
+	if export != nil {
+		content, err := yaml.Marshal(&data)
+		if err != nil {
+			return report, fmt.Errorf("failed to export project data: %s", err)
+		}
+		header := fmt.Sprintf("%s Data of project %s, exported %s\n\n", YamlCommentPrefix, name, report.Purged.Format(util.DateTimeFormat))
+		if _, err := io.WriteString(export, header+string(content)); err != nil {
+			return report, fmt.Errorf("failed to export project data: %s", err)
+		}
+	}
+
+	for i := range data.Records {
+		rec := &data.Records[i]
+		format, err := t.findRecordFile(rec.Start)
+		if err != nil {
+			return report, err
+		}
+		if format != "" {
+			if err := t.removeRecordAt(rec.Start, format); err != nil {
+				return report, err
+			}
+			if err := t.removeEmptyDayDir(t.RecordDir(rec.Start)); err != nil {
+				return report, err
+			}
+		}
+	}
+	for _, file := range data.trashFiles {
+		if err := t.FileSystem().Remove(file); err != nil {
+			return report, err
+		}
+	}
+	for _, file := range data.historyFiles {
+		if err := t.FileSystem().Remove(file); err != nil {
+			return report, err
+		}
+	}
+
+	if len(data.Invoices) > 0 {
+		invoices, err := t.LoadInvoices()
+		if err != nil {
+			return report, err
+		}
+		for _, inv := range data.Invoices {
+			if err := invoices.Cancel(inv.Number); err != nil {
+				return report, err
+			}
+		}
+		if err := t.SaveInvoices(&invoices); err != nil {
+			return report, err
+		}
+	}
+
+	for _, p := range data.Projects {
+		if err := t.FileSystem().Remove(t.ProjectPath(p.Name)); err != nil {
+			return report, err
+		}
+	}
+
+	// Cached times may include the projects
+	if err := t.DeleteReportCache(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurgeProject(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	track.Config.History = true
+	track.Config.TrashDays = 30
+
+	assert.Nil(t, track.SaveProject(NewProject("client", "", "C", []string{}, 0, 15), false))
+	assert.Nil(t, track.SaveProject(NewProject("app", "client", "A", []string{}, 0, 15), false))
+	assert.Nil(t, track.SaveProject(NewProject("other", "", "O", []string{}, 0, 15), false))
+
+	date := func(d, h int) time.Time {
+		return time.Date(2023, 1, d, h, 0, 0, 0, time.Local)
+	}
+	records := []Record{
+		{Project: "client", Start: date(2, 8), End: date(2, 10), Note: "secret"},
+		{Project: "app", Start: date(3, 8), End: date(3, 9)},
+		{Project: "app", Start: date(4, 8), End: date(4, 9)},
+		{Project: "other", Start: date(5, 8), End: date(5, 9)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false))
+	}
+	assert.Nil(t, track.DeleteRecord(&records[2]))
+
+	invoices, err := track.LoadInvoices()
+	assert.Nil(t, err)
+	_, err = invoices.Create("client", date(1, 0), date(4, 0), records[:2], date(5, 0))
+	assert.Nil(t, err)
+	assert.Nil(t, track.SaveInvoices(&invoices))
+	_, err = track.SetRecordsLocked(records[:2], true)
+	assert.Nil(t, err)
+
+	_, err = track.CollectProjectData("nope")
+	assert.NotNil(t, err)
+
+	data, err := track.CollectProjectData("client")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(data.Projects))
+	assert.Equal(t, 2, len(data.Records))
+	assert.Equal(t, 1, len(data.Trash))
+	assert.Equal(t, 1, len(data.Invoices))
+
+	export := bytes.Buffer{}
+	report, err := track.PurgeProject("client", &export)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"app", "client"}, report.Projects)
+	assert.Equal(t, 2, report.Records)
+	assert.Equal(t, 3*time.Hour, report.Time)
+	assert.Equal(t, date(2, 8), report.First)
+	assert.Equal(t, date(3, 8), report.Last)
+	assert.Equal(t, 1, report.Trash)
+	assert.Equal(t, 3, report.History)
+	assert.Equal(t, []string{"2023-0001"}, report.Invoices)
+	assert.Contains(t, export.String(), "secret")
+
+	assert.False(t, track.ProjectExists("client"))
+	assert.False(t, track.ProjectExists("app"))
+	assert.True(t, track.ProjectExists("other"))
+
+	_, err = track.LoadRecord(date(2, 8))
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+	_, err = track.LoadRecord(date(5, 8))
+	assert.Nil(t, err)
+
+	trash, err := track.DeletedRecords()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(trash))
+	history, err := track.RecordHistory(date(2, 8))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(history))
+	history, err = track.RecordHistory(date(5, 8))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(history))
+
+	invoices, err = track.LoadInvoices()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(invoices.Invoices))
+}
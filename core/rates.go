@@ -0,0 +1,194 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"gopkg.in/yaml.v3"
+)
+
+// Rate is an hourly rate of a project, valid from a date on
+type Rate struct {
+	// First day the rate is valid, like 2023-01-31
+	From string `yaml:"from"`
+	// Amount per hour
+	Hourly float64 `yaml:"hourly"`
+	// Currency code, like EUR or USD. Defaults to the currency of the project
+	Currency string `yaml:"currency,omitempty"`
+	from     time.Time
+}
+
+type tempRate struct {
+	From     string  `yaml:"from"`
+	Hourly   float64 `yaml:"hourly"`
+	Currency string  `yaml:"currency,omitempty"`
+}
+
+// UnmarshalYAML un-marshals a rate, and checks its date
+func (r *Rate) UnmarshalYAML(value *yaml.Node) error {
+	var tmp tempRate
+	if err := value.Decode(&tmp); err != nil {
+		return err
+	}
+	from, err := util.ParseDate(tmp.From)
+	if err != nil {
+		return fmt.Errorf("invalid rate date '%s': %s", tmp.From, err)
+	}
+	if tmp.Hourly < 0 {
+		return fmt.Errorf("negative rate %f from %s", tmp.Hourly, tmp.From)
+	}
+	r.From = tmp.From
+	r.Hourly = tmp.Hourly
+	r.Currency = strings.ToUpper(tmp.Currency)
+	r.from = from
+	return nil
+}
+
+// NewRate creates a new rate. The currency may be empty to use the project's currency
+func NewRate(from time.Time, hourly float64, currency string) Rate {
+	from = util.ToDate(from)
+	return Rate{From: from.Format(util.DateFormat), Hourly: hourly, Currency: strings.ToUpper(currency), from: from}
+}
+
+// FromDate returns the first day the rate is valid
+func (r *Rate) FromDate() time.Time {
+	return r.from
+}
+
+// SetRate adds a rate to the project, replacing a rate with the same date
+func (p *Project) SetRate(rate Rate) {
+	for i, r := range p.Rates {
+		if r.from.Equal(rate.from) {
+			p.Rates[i] = rate
+			return
+		}
+	}
+	p.Rates = append(p.Rates, rate)
+	sort.Slice(p.Rates, func(i, j int) bool { return p.Rates[i].from.Before(p.Rates[j].from) })
+}
+
+// RateAt returns the rate of the project that is valid at the given date.
+// Returns false if there is no such rate.
+func (p *Project) RateAt(date time.Time) (Rate, bool) {
+	var rate Rate
+	found := false
+	for _, r := range p.Rates {
+		if r.from.After(date) {
+			continue
+		}
+		if !found || r.from.After(rate.from) {
+			rate, found = r, true
+		}
+	}
+	return rate, found
+}
+
+// ProjectRateAt returns the rate of a project that is valid at the given date.
+// Projects without rates inherit the rates of their closest ancestor that has rates.
+// Rates without a currency get the currency of the project that has the rates, or the default currency.
+// Returns false if there is no such rate.
+func ProjectRateAt(projects map[string]Project, project string, date time.Time, defaultCurrency string) (Rate, bool) {
+	visited := map[string]bool{}
+	for project != "" && !visited[project] {
+		visited[project] = true
+		p, ok := projects[project]
+		if !ok {
+			break
+		}
+		if len(p.Rates) > 0 {
+			rate, ok := p.RateAt(date)
+			if ok && rate.Currency == "" {
+				rate.Currency = p.Currency
+				if rate.Currency == "" {
+					rate.Currency = strings.ToUpper(defaultCurrency)
+				}
+			}
+			return rate, ok
+		}
+		project = p.Parent
+	}
+	return Rate{}, false
+}
+
+// Earnings are the time and earnings of a project
+type Earnings struct {
+	// Total time worked
+	Time time.Duration
+	// Time worked on days without a rate
+	Unrated time.Duration
+	// Earned amounts per currency
+	Amounts map[string]float64
+}
+
+// NewEarnings calculates time and earnings per project, using the rates valid on the days of the records.
+// Record times are clipped to start and end, which may be zero for no limit.
+// Running records are considered to end at the given time.
+// Rates without a currency, in projects without a currency, are in the default currency.
+func NewEarnings(projects map[string]Project, records []Record, start, end, now time.Time, defaultCurrency string) map[string]*Earnings {
+	earnings := map[string]*Earnings{}
+	for i := range records {
+		rec := &records[i]
+		e, ok := earnings[rec.Project]
+		if !ok {
+			e = &Earnings{Amounts: map[string]float64{}}
+			earnings[rec.Project] = e
+		}
+		rec.forEachDay(start, end, now, func(day time.Time, dur time.Duration) {
+			e.Time += dur
+			rate, ok := ProjectRateAt(projects, rec.Project, day, defaultCurrency)
+			if !ok {
+				e.Unrated += dur
+				return
+			}
+			e.Amounts[rate.Currency] += dur.Hours() * rate.Hourly
+		})
+	}
+	return earnings
+}
+
+// Add adds other earnings to the earnings
+func (e *Earnings) Add(other *Earnings) {
+	if e.Amounts == nil {
+		e.Amounts = map[string]float64{}
+	}
+	e.Time += other.Time
+	e.Unrated += other.Unrated
+	for currency, amount := range other.Amounts {
+		e.Amounts[currency] += amount
+	}
+}
+
+// Convert converts amounts in different currencies into the target currency.
+// Exchange rates give the value of one unit of each currency in a common reference currency.
+// A target currency without an exchange rate is considered to be the reference currency.
+func Convert(amounts map[string]float64, target string, exchangeRates map[string]float64) (float64, error) {
+	rates := make(map[string]float64, len(exchangeRates))
+	for c, r := range exchangeRates {
+		if r <= 0 {
+			return 0, fmt.Errorf("invalid exchange rate %f for %s", r, c)
+		}
+		rates[strings.ToUpper(c)] = r
+	}
+	target = strings.ToUpper(target)
+	to, ok := rates[target]
+	if !ok {
+		to = 1
+	}
+
+	total := 0.0
+	for currency, amount := range amounts {
+		if strings.ToUpper(currency) == target {
+			total += amount
+			continue
+		}
+		from, ok := rates[strings.ToUpper(currency)]
+		if !ok {
+			return 0, fmt.Errorf("no exchange rate for currency '%s'", currency)
+		}
+		total += amount * from / to
+	}
+	return total, nil
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestProjectRates(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 0, 0, 0, 0, time.Local)
+	}
+	project := NewProject("test", "", "t", []string{}, 15, 0)
+
+	_, ok := project.RateAt(day(1))
+	assert.False(t, ok)
+
+	project.SetRate(NewRate(day(10), 100, ""))
+	project.SetRate(NewRate(day(1), 80, "eur"))
+	project.SetRate(NewRate(day(10), 90, ""))
+
+	assert.Equal(t, 2, len(project.Rates))
+	assert.Equal(t, "2023-01-01", project.Rates[0].From)
+
+	rate, ok := project.RateAt(day(9))
+	assert.True(t, ok)
+	assert.Equal(t, 80.0, rate.Hourly)
+	assert.Equal(t, "EUR", rate.Currency)
+	rate, ok = project.RateAt(day(10))
+	assert.True(t, ok)
+	assert.Equal(t, 90.0, rate.Hourly)
+
+	bytes, err := yaml.Marshal(&project)
+	assert.Nil(t, err)
+	var loaded Project
+	assert.Nil(t, yaml.Unmarshal(bytes, &loaded))
+	assert.Equal(t, project.Rates, loaded.Rates)
+
+	err = yaml.Unmarshal([]byte("name: test\nrates:\n  - from: 01/01/2023\n    hourly: 80\n"), &loaded)
+	assert.NotNil(t, err)
+}
+
+func TestNewEarnings(t *testing.T) {
+	date := func(d, h int) time.Time {
+		return time.Date(2023, 1, d, h, 0, 0, 0, time.Local)
+	}
+	client := NewProject("client", "", "c", []string{}, 15, 0)
+	client.SetRate(NewRate(date(2, 0), 80, ""))
+	client.SetRate(NewRate(date(3, 0), 100, ""))
+	foreign := NewProject("foreign", "", "f", []string{}, 15, 0)
+	foreign.Currency = "USD"
+	foreign.SetRate(NewRate(date(1, 0), 50, ""))
+	foreign.SetRate(NewRate(date(3, 0), 40, "GBP"))
+	projects := map[string]Project{
+		"client":  client,
+		"child":   NewProject("child", "client", "h", []string{}, 15, 0),
+		"other":   NewProject("other", "", "o", []string{}, 15, 0),
+		"foreign": foreign,
+	}
+
+	records := []Record{
+		{Project: "child", Start: date(1, 8), End: date(1, 10)},
+		{Project: "child", Start: date(2, 22), End: date(3, 2)},
+		{Project: "client", Start: date(3, 8), End: date(3, 9)},
+		{Project: "other", Start: date(3, 8), End: date(3, 9)},
+		{Project: "foreign", Start: date(2, 8), End: date(2, 9)},
+		{Project: "foreign", Start: date(3, 8), End: date(3, 9)},
+	}
+
+	earnings := NewEarnings(projects, records, time.Time{}, time.Time{}, date(5, 0), "eur")
+
+	assert.Equal(t, Earnings{Time: 6 * time.Hour, Unrated: 2 * time.Hour, Amounts: map[string]float64{"EUR": 2*80 + 2*100}}, *earnings["child"])
+	assert.Equal(t, Earnings{Time: time.Hour, Amounts: map[string]float64{"EUR": 100}}, *earnings["client"])
+	assert.Equal(t, Earnings{Time: time.Hour, Unrated: time.Hour, Amounts: map[string]float64{}}, *earnings["other"])
+	assert.Equal(t, Earnings{Time: 2 * time.Hour, Amounts: map[string]float64{"USD": 50, "GBP": 40}}, *earnings["foreign"])
+
+	total := Earnings{}
+	total.Add(earnings["child"])
+	total.Add(earnings["foreign"])
+	assert.Equal(t, Earnings{Time: 8 * time.Hour, Unrated: 2 * time.Hour, Amounts: map[string]float64{"EUR": 360, "USD": 50, "GBP": 40}}, total)
+
+	earnings = NewEarnings(projects, records, date(3, 0), time.Time{}, date(5, 0), "EUR")
+	assert.Equal(t, Earnings{Time: 2 * time.Hour, Amounts: map[string]float64{"EUR": 2 * 100}}, *earnings["child"])
+}
+
+func TestConvert(t *testing.T) {
+	amounts := map[string]float64{"EUR": 100, "USD": 50}
+
+	total, err := Convert(amounts, "EUR", map[string]float64{"USD": 0.9})
+	assert.Nil(t, err)
+	assert.InDelta(t, 145.0, total, 1e-9)
+
+	total, err = Convert(amounts, "usd", map[string]float64{"usd": 0.5, "eur": 1.0})
+	assert.Nil(t, err)
+	assert.InDelta(t, 250.0, total, 1e-9)
+
+	_, err = Convert(amounts, "GBP", map[string]float64{"USD": 0.9})
+	assert.NotNil(t, err, "Error expected for missing exchange rate")
+
+	_, err = Convert(amounts, "EUR", map[string]float64{"USD": 0})
+	assert.NotNil(t, err, "Error expected for invalid exchange rate")
+}
@@ -9,20 +9,33 @@ import (
 	"github.com/mlange-42/track/util"
 )
 
-// TagPrefix denotes tags in record notes
-const TagPrefix = "+"
+// DefaultTagPrefix is the default prefix of tags in record notes
+const DefaultTagPrefix = "+"
 
-// CommentPrefix denotes comments in record files
-const CommentPrefix = "#"
+// DefaultCommentPrefix is the default prefix of comments in record files.
+// Lines with this prefix are always comments before the note of a record, including file headers.
+const DefaultCommentPrefix = "#"
 
 // YamlCommentPrefix denotes comments in YAML files
 const YamlCommentPrefix = "#"
 
+// EscapePrefix escapes note lines in record files that would otherwise be read as comments or record separators
+const EscapePrefix = "\\"
+
+// RecordSeparator separates records in multi-record files
+const RecordSeparator = "----"
+
 var (
 	// ErrNoRecords is an error for no records found for a date
 	ErrNoRecords = errors.New("no records for date")
 	// ErrRecordNotFound is an error for a particular record not found
 	ErrRecordNotFound = errors.New("record not found")
+	// ErrRecordLocked is an error for attempted changes of a locked record
+	ErrRecordLocked = errors.New("record is locked")
+	// ErrNoOpenRecord is an error for operations that require a running record when there is none
+	ErrNoOpenRecord = errors.New("no running record")
+	// ErrOverlap is an error for records that overlap other records
+	ErrOverlap = errors.New("records overlap")
 )
 
 // Record represents a time tracking record
@@ -33,6 +46,63 @@ type Record struct {
 	Note    string            `json:"note"`
 	Tags    map[string]string `json:"tags"`
 	Pause   []Pause           `json:"pause"`
+	Links   []Link            `json:"links,omitempty" yaml:",omitempty"`
+	// Category of the record, like work or meeting, independent of the project. Empty for no category
+	Category string `json:"category,omitempty" yaml:",omitempty"`
+	// Locked records refuse changes and deletion, e.g. after invoicing
+	Locked bool `json:"locked,omitempty" yaml:",omitempty"`
+}
+
+// LinkPrefix denotes links in record files
+const LinkPrefix = "@"
+
+// LockMarker denotes locked records in record files
+const LockMarker = "! locked"
+
+// CategoryPrefix denotes the category in record files
+const CategoryPrefix = "%"
+
+// CheckCategoryName checks the name of a category
+func CheckCategoryName(name string) error {
+	if name == "" || strings.ContainsAny(name, " \t\n\r,") {
+		return fmt.Errorf("invalid category '%s': must not be empty or contain whitespace or ','", name)
+	}
+	return nil
+}
+
+// Link is a reference to an URL or a file, attached to a record
+type Link struct {
+	Target string `json:"target"`
+	Note   string `json:"note,omitempty" yaml:",omitempty"`
+}
+
+// AddLink attaches a link to a record. Links that are already attached are ignored.
+func (r *Record) AddLink(target, note string) error {
+	link := Link{Target: strings.TrimSpace(target), Note: strings.TrimSpace(note)}
+	if err := link.Check(); err != nil {
+		return err
+	}
+	for _, l := range r.Links {
+		if l.Target == link.Target {
+			return nil
+		}
+	}
+	r.Links = append(r.Links, link)
+	return nil
+}
+
+// Check checks validity of a link
+func (l *Link) Check() error {
+	if l.Target == "" {
+		return fmt.Errorf("empty link target")
+	}
+	if strings.ContainsAny(l.Target, "\n\r") || strings.ContainsAny(l.Note, "\n\r") {
+		return fmt.Errorf("link target and note must be single-line")
+	}
+	if strings.Contains(l.Target, linkNoteSeparator) {
+		return fmt.Errorf("link target must not contain '%s'", linkNoteSeparator)
+	}
+	return nil
 }
 
 // Pause holds information about a pause in a record
@@ -111,6 +181,60 @@ func (r *Record) CurrentPauseDuration(min, max time.Time) time.Duration {
 	return last.Duration(min, max)
 }
 
+// SplitAtMidnight splits a record spanning midnight into one record per day.
+// Pauses spanning midnight are split as well.
+// Note, tags and links are copied to all parts.
+//
+// Returns a slice with only the record itself if it does not span midnight, or if it is still running.
+func (r *Record) SplitAtMidnight() []Record {
+	parts := []Record{}
+	curr := *r
+	for {
+		midnight := util.ToDate(curr.Start).AddDate(0, 0, 1)
+		if curr.End.IsZero() || !curr.End.After(midnight) {
+			parts = append(parts, curr)
+			return parts
+		}
+
+		first, rest := curr.copy(), curr.copy()
+		first.End = midnight
+		rest.Start = midnight
+		first.Pause, rest.Pause = []Pause{}, []Pause{}
+		for _, p := range curr.Pause {
+			if p.Start.Before(midnight) {
+				fp := p
+				if p.End.IsZero() || p.End.After(midnight) {
+					fp.End = midnight
+				}
+				first.Pause = append(first.Pause, fp)
+			}
+			if p.End.IsZero() || p.End.After(midnight) {
+				rp := p
+				if p.Start.Before(midnight) {
+					rp.Start = midnight
+				}
+				rest.Pause = append(rest.Pause, rp)
+			}
+		}
+		parts = append(parts, first)
+		curr = rest
+	}
+}
+
+// copy creates a copy of a record, with independent tags, pauses and links
+func (r *Record) copy() Record {
+	c := *r
+	c.Tags = make(map[string]string, len(r.Tags))
+	for k, v := range r.Tags {
+		c.Tags[k] = v
+	}
+	c.Pause = append([]Pause{}, r.Pause...)
+	if r.Links != nil {
+		c.Links = append([]Link{}, r.Links...)
+	}
+	return c
+}
+
 // Check checks consistency of a record
 func (r *Record) Check(project *Project) error {
 	for _, tag := range project.RequiredTags {
@@ -126,31 +250,46 @@ func (r *Record) Check(project *Project) error {
 	if !r.End.IsZero() && r.End.Before(r.Start) {
 		return fmt.Errorf("end time is before start time")
 	}
-	prevStart := util.NoTime
-	prevEnd := util.NoTime
-	for _, p := range r.Pause {
-		if p.Start.Before(r.Start) {
-			return fmt.Errorf("pause starts before record")
+	for _, l := range r.Links {
+		if err := l.Check(); err != nil {
+			return err
 		}
-		if !p.End.IsZero() && p.End.Before(p.Start) {
-			return fmt.Errorf("pause ends before its start")
+	}
+	for i := range r.Pause {
+		var prev *Pause
+		if i > 0 {
+			prev = &r.Pause[i-1]
 		}
-		if !r.End.IsZero() {
-			if p.End.IsZero() {
-				return fmt.Errorf("pause is ongoing but record is finished")
-			}
-			if p.End.After(r.End) {
-				return fmt.Errorf("pause ends after record")
-			}
+		if err := checkPause(&r.Pause[i], prev, r.Start, r.End); err != nil {
+			return err
 		}
-		if prevStart.After(p.Start) {
+	}
+	return nil
+}
+
+// checkPause checks a pause against the time span of its record, and against the previous pause, if any
+func checkPause(p *Pause, prev *Pause, start, end time.Time) error {
+	if p.Start.Before(start) {
+		return fmt.Errorf("pause starts before record")
+	}
+	if !p.End.IsZero() && p.End.Before(p.Start) {
+		return fmt.Errorf("pause ends before its start")
+	}
+	if !end.IsZero() {
+		if p.End.IsZero() {
+			return fmt.Errorf("pause is ongoing but record is finished")
+		}
+		if p.End.After(end) {
+			return fmt.Errorf("pause ends after record")
+		}
+	}
+	if prev != nil {
+		if prev.Start.After(p.Start) {
 			return fmt.Errorf("pause starts not in chronological order")
 		}
-		if prevEnd.After(p.Start) {
+		if prev.End.After(p.Start) {
 			return fmt.Errorf("pauses overlap")
 		}
-		prevStart = p.Start
-		prevEnd = p.End
 	}
 	return nil
 }
@@ -192,6 +331,26 @@ func (r *Record) EndPause(t time.Time) (Pause, error) {
 	return r.Pause[len(r.Pause)-1], nil
 }
 
+// CheckPrefixes checks prefixes of tags and comments for conflicts with each other and with the record file format
+func CheckPrefixes(tagPrefix, commentPrefix string) error {
+	if tagPrefix == "" || strings.ContainsAny(tagPrefix, " \t\n=") {
+		return fmt.Errorf("tag prefix must not be empty or contain whitespace or '='")
+	}
+	if commentPrefix == "" || strings.ContainsAny(commentPrefix, " \t\n") {
+		return fmt.Errorf("comment prefix must not be empty or contain whitespace")
+	}
+	if strings.HasPrefix(tagPrefix, commentPrefix) {
+		return fmt.Errorf("tag prefix '%s' must not start with comment prefix '%s'", tagPrefix, commentPrefix)
+	}
+	if strings.HasPrefix(commentPrefix, EscapePrefix) || strings.HasPrefix(commentPrefix, "-") {
+		return fmt.Errorf("comment prefix must not start with '%s' or '-'", EscapePrefix)
+	}
+	if strings.HasPrefix(commentPrefix, CategoryPrefix) {
+		return fmt.Errorf("comment prefix must not start with '%s'", CategoryPrefix)
+	}
+	return nil
+}
+
 // ParseTag parses a key=value pair from a tag entry.
 // Value is "" if it is a tag without a value.
 func ParseTag(tag string) (string, string) {
@@ -203,38 +362,58 @@ func ParseTag(tag string) (string, string) {
 	return parts[0], value
 }
 
-// ExtractTagsSlice extracts elements with the tag prefix from a slice of strings.
+// ExtractTagsSlice extracts elements with the tag prefix from a slice of strings, with the DefaultSyntax.
+// Use Syntax.ExtractTagsSlice for the rules of a Track.
 func ExtractTagsSlice(tokens []string) (map[string]string, error) {
+	return DefaultSyntax().ExtractTagsSlice(tokens)
+}
+
+// ExtractTags extracts elements with the tag prefix from a string, with the DefaultSyntax.
+// Use Syntax.ExtractTags for the rules of a Track.
+func ExtractTags(text string) (map[string]string, error) {
+	return DefaultSyntax().ExtractTags(text)
+}
+
+// ExtractTagsSlice extracts elements with the tag prefix from a slice of strings.
+// Tags are normalized, see TagNormalization.
+func (s *Syntax) ExtractTagsSlice(tokens []string) (map[string]string, error) {
 	result := make(map[string]string)
 	for _, token := range tokens {
-		subTokens := strings.Split(token, " ")
-		for _, subToken := range subTokens {
-			if strings.HasPrefix(subToken, TagPrefix) {
-				key, value := ParseTag(strings.TrimPrefix(subToken, TagPrefix))
-				if old, ok := result[key]; ok && value != old {
-					return nil, fmt.Errorf("tag '%s' already has value '%s'", key, value)
-				}
-				result[key] = value
-			}
+		if _, err := s.addTags(result, token); err != nil {
+			return nil, err
 		}
 	}
 	return result, nil
 }
 
 // ExtractTags extracts elements with the tag prefix from a string.
-func ExtractTags(text string) (map[string]string, error) {
+// Tags are normalized, see TagNormalization.
+func (s *Syntax) ExtractTags(text string) (map[string]string, error) {
 	result := make(map[string]string)
-	subTokens := strings.Split(text, " ")
-	for _, subToken := range subTokens {
-		if strings.HasPrefix(subToken, TagPrefix) {
-			key, value := ParseTag(strings.TrimPrefix(subToken, TagPrefix))
-			if old, ok := result[key]; ok && value != old {
-				return nil, fmt.Errorf("tag '%s' already has value '%s'", key, value)
+	if _, err := s.addTags(result, text); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// addTags adds the tags of a text to a map of tags.
+// On error, also returns the byte offset of the conflicting tag in the text.
+func (s *Syntax) addTags(tags map[string]string, text string) (int, error) {
+	offset := 0
+	for _, token := range strings.Split(text, " ") {
+		if strings.HasPrefix(token, s.TagPrefix) {
+			key, value := ParseTag(strings.TrimPrefix(token, s.TagPrefix))
+			key = s.NormalizeTag(key)
+			if key != "" {
+				if old, ok := tags[key]; ok && value != old {
+					return offset, fmt.Errorf("tag '%s' already has value '%s'", key, value)
+				}
+				tags[key] = value
 			}
-			result[key] = value
 		}
+		offset += len(token) + 1
 	}
-	return result, nil
+	return 0, nil
 }
 
 func pathToTime(y, m, d, file string) (time.Time, error) {
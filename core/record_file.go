@@ -1,66 +1,164 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/mlange-42/track/util"
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
 )
 
-// FilterResult contains a Record or an error from async filtering
+// FilterResult contains a Record or an error from async filtering.
+//
+// Errors of record files that can't be read are of type *RecordFileError.
+// Async filtering continues after these errors, while it ends after any other error.
 type FilterResult struct {
 	Record Record
 	Err    error
 }
 
-type workerResult struct {
-	Index  int
-	Record Record
-	Err    error
+// RecordFileError is an error for a record file that can't be read, e.g. because it is corrupt
+type RecordFileError struct {
+	// Path of the record file
+	Path string
+	// Date of the file's day directory
+	Date time.Time
+	// Underlying error
+	Err error
+}
+
+func (e *RecordFileError) Error() string {
+	return fmt.Sprintf("record file %s: %s", e.Path, e.Err)
+}
+
+func (e *RecordFileError) Unwrap() error {
+	return e.Err
+}
+
+// IsRecordFileError checks if an error is or wraps a *RecordFileError
+func IsRecordFileError(err error) bool {
+	var fileErr *RecordFileError
+	return errors.As(err, &fileErr)
 }
 
-type listFilterResult struct {
-	Time time.Time
-	Err  error
+type dayResult struct {
+	Records []Record
+	// Errors of record files that can't be read
+	FileErrs []error
+	Err      error
 }
 
 // NewRecord creates a new record
 func (t *Track) NewRecord(project *Project, note string, tags map[string]string, start time.Time, end time.Time) (Record, error) {
 	record := Record{
-		Project: project.Name,
-		Note:    note,
-		Tags:    tags,
-		Start:   start,
-		End:     end,
-		Pause:   []Pause{},
+		Project:  project.Name,
+		Note:     note,
+		Tags:     tags,
+		Start:    start,
+		End:      end,
+		Pause:    []Pause{},
+		Category: project.Category,
 	}
 
 	if err := record.Check(project); err != nil {
 		return record, err
 	}
+	if err := t.Config.CheckCategory(record.Category); err != nil {
+		return record, err
+	}
 
 	return record, t.SaveRecord(&record, false)
 }
 
-// StartRecord starts a new record for the given project at the given time.
+// StartRecord starts a new record for the given project at the given time, and emits EventStart.
 func (t *Track) StartRecord(project *Project, note string, tags map[string]string, start time.Time) (Record, error) {
-	return t.NewRecord(project, note, tags, start, util.NoTime)
+	record, err := t.NewRecord(project, note, tags, start, util.NoTime)
+	if err != nil {
+		return record, err
+	}
+	t.EmitEvent(Event{Type: EventStart, Time: record.Start, Record: record})
+	return record, nil
 }
 
-// StopRecord stops the currently running record at the given time, and saves it to disk.
+// StopRecord stops the currently running record at the given time, saves it to disk, and emits EventStop.
+//
+// If the config's MidnightPolicy is MidnightSplit, records spanning midnight are split into one record per day.
+// In this case, the last part is returned.
 func (t *Track) StopRecord(end time.Time) (*Record, error) {
 	record, err := t.OpenRecord()
 	if err != nil {
 		return record, err
 	}
 	if record == nil {
-		return record, fmt.Errorf("no running record")
+		return record, ErrNoOpenRecord
+	}
+
+	tx := t.Begin()
+	last := t.stageStop(tx, record, end)
+	if err := tx.Commit(); err != nil {
+		return record, err
+	}
+	t.EmitEvent(Event{Type: EventStop, Time: last.End, Record: *last})
+	return last, nil
+}
+
+// SwitchRecord stops the running record, if any, and starts a new record for the given project at the given time.
+// Both are applied in a single Transaction, so that either both or none of the changes are saved.
+// Emits EventStop and EventStart.
+//
+// Returns the stopped record, or nil if there was no running record, and the started record.
+// The stopped record is the last part if it was split at midnight, like for StopRecord.
+func (t *Track) SwitchRecord(project *Project, note string, tags map[string]string, tm time.Time) (*Record, Record, error) {
+	record := Record{
+		Project:  project.Name,
+		Note:     note,
+		Tags:     tags,
+		Start:    tm,
+		Pause:    []Pause{},
+		Category: project.Category,
+	}
+	if err := record.Check(project); err != nil {
+		return nil, record, err
+	}
+	if err := t.Config.CheckCategory(record.Category); err != nil {
+		return nil, record, err
 	}
 
+	open, err := t.OpenRecord()
+	if err != nil {
+		return nil, record, err
+	}
+
+	tx := t.Begin()
+	var stopped *Record
+	if open != nil {
+		stopped = t.stageStop(tx, open, tm)
+	}
+	tx.Save(&record, false)
+	if err := tx.Commit(); err != nil {
+		return nil, record, err
+	}
+
+	if stopped != nil {
+		t.EmitEvent(Event{Type: EventStop, Time: stopped.End, Record: *stopped})
+	}
+	t.EmitEvent(Event{Type: EventStart, Time: record.Start, Record: record})
+	return stopped, record, nil
+}
+
+// stageStop stops a running record at the given time, and stages saving it in a transaction.
+// Pauses that are open or end after the given time are removed, and the record ends at the start of the pause.
+//
+// If the config's MidnightPolicy is MidnightSplit, the record is split into one record per day,
+// and the last part is returned.
+func (t *Track) stageStop(tx *Transaction, record *Record, end time.Time) *Record {
 	record.End = end
 	for len(record.Pause) > 0 {
 		idx := len(record.Pause) - 1
@@ -72,30 +170,22 @@ func (t *Track) StopRecord(end time.Time) (*Record, error) {
 		}
 	}
 
-	err = t.SaveRecord(record, true)
-	if err != nil {
-		return record, err
+	if t.Config.MidnightPolicy == MidnightSplit {
+		parts := record.SplitAtMidnight()
+		for i := range parts {
+			// The first part replaces the original record
+			tx.Save(&parts[i], i == 0)
+		}
+		return &parts[len(parts)-1]
 	}
-	return record, nil
+
+	tx.Save(record, true)
+	return record
 }
 
 // LoadRecord loads a record by the given start time
 func (t *Track) LoadRecord(tm time.Time) (Record, error) {
-	path := t.RecordPath(tm)
-	file, err := os.ReadFile(path)
-	if err != nil {
-		if _, ok := err.(*os.PathError); ok {
-			return Record{}, ErrRecordNotFound
-		}
-		return Record{}, err
-	}
-
-	record, err := DeserializeRecord(string(file), tm)
-	if err != nil {
-		return Record{}, err
-	}
-
-	return record, nil
+	return t.loadRecordAt(tm)
 }
 
 // OpenRecord returns the open/running record if any.
@@ -121,28 +211,28 @@ func (t *Track) OpenRecord() (*Record, error) {
 // Returns a nil reference if no record is found.
 func (t *Track) LatestRecord() (*Record, error) {
 	records := t.RecordsDir()
-	yearPath, year, err := util.FindLatests(records, true)
+	yearPath, year, err := t.findLatest(records, true)
 	if err != nil {
 		if errors.Is(err, util.ErrNoFiles) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	monthPath, month, err := util.FindLatests(yearPath, true)
+	monthPath, month, err := t.findLatest(yearPath, true)
 	if err != nil {
 		if errors.Is(err, util.ErrNoFiles) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	dayPath, day, err := util.FindLatests(monthPath, true)
+	dayPath, day, err := t.findLatest(monthPath, true)
 	if err != nil {
 		if errors.Is(err, util.ErrNoFiles) {
 			return nil, nil
 		}
 		return nil, err
 	}
-	_, record, err := util.FindLatests(dayPath, false)
+	_, _, err = t.findLatest(dayPath, false)
 	if err != nil {
 		if errors.Is(err, util.ErrNoFiles) {
 			return nil, nil
@@ -150,54 +240,93 @@ func (t *Track) LatestRecord() (*Record, error) {
 		return nil, err
 	}
 
-	tm, err := pathToTime(year, month, day, record)
+	date, err := pathToTime(year, month, day, "00-00")
 	if err != nil {
 		return nil, err
 	}
-	rec, err := t.LoadRecord(tm)
+	dayRecords, err := t.readDayRecords(date)
 	if err != nil {
 		return nil, err
 	}
+	if len(dayRecords) == 0 {
+		return nil, nil
+	}
 
-	return &rec, nil
+	return &dayRecords[len(dayRecords)-1], nil
 }
 
 // FindLatestRecord loads the latest record that matches the given FilterFunction.
 // Returns a nil reference if no record is found.
 func (t *Track) FindLatestRecord(cond FilterFunction) (*Record, error) {
 	fn, results, stop := t.AllRecordsFiltered(
-		FilterFunctions{[]FilterFunction{cond}, util.NoTime, util.NoTime},
+		FilterFunctions{Functions: []FilterFunction{cond}},
 		true, // reversed order to find latest record of project
 	)
 	go fn()
+	defer stopResults(results, stop)
 
 	res := <-results
 	if res.Err != nil {
 		return nil, res.Err
 	}
-	close(stop)
 	return &res.Record, nil
 }
 
-// LoadAllRecords loads all records.
+// LoadAllRecords loads all records, including records of archived projects.
 func (t *Track) LoadAllRecords() ([]Record, error) {
-	return t.LoadAllRecordsFiltered(NewFilter([]func(*Record) bool{}, util.NoTime, util.NoTime))
+	filters := NewFilter([]func(*Record) bool{}, util.NoTime, util.NoTime)
+	filters.IncludeArchived = true
+	return t.LoadAllRecordsFiltered(filters)
 }
 
 // LoadAllRecordsFiltered loads all records, filtered by FilterFunctions.
+// Aborts at the first record file that can't be read, see LoadAllRecordsFilteredLenient.
+//
+// Records of archived projects are excluded, unless FilterFunctions.IncludeArchived is set.
+// Records are sorted by FilterFunctions.Sort, by start time in ascending order by default.
 func (t *Track) LoadAllRecordsFiltered(filters FilterFunctions) ([]Record, error) {
-	fn, results, _ := t.AllRecordsFiltered(filters, false)
+	records, _, err := t.loadAllRecordsFiltered(filters, false)
+	return records, err
+}
+
+// LoadAllRecordsFilteredLenient loads all records, like LoadAllRecordsFiltered.
+// Record files that can't be read are skipped, and their errors are returned as *RecordFileError,
+// together with all successfully loaded records.
+func (t *Track) LoadAllRecordsFilteredLenient(filters FilterFunctions) ([]Record, []error, error) {
+	return t.loadAllRecordsFiltered(filters, true)
+}
+
+func (t *Track) loadAllRecordsFiltered(filters FilterFunctions, lenient bool) ([]Record, []error, error) {
+	if !filters.IncludeArchived {
+		projects, err := t.LoadAllProjects()
+		if err != nil {
+			return nil, nil, err
+		}
+		filters.Functions = append(
+			slices.Clip(filters.Functions),
+			FilterByArchived(false, projects),
+		)
+	}
+
+	fn, results, stop := t.AllRecordsFiltered(filters, false)
 	go fn()
 
 	var records []Record
+	fileErrs := []error{}
 	for res := range results {
 		if res.Err != nil {
-			return records, res.Err
+			if lenient && IsRecordFileError(res.Err) {
+				fileErrs = append(fileErrs, res.Err)
+				continue
+			}
+			stopResults(results, stop)
+			return records, fileErrs, res.Err
 		}
 		records = append(records, res.Record)
 	}
+	SortRecords(records, filters.Sort)
 
-	return records, nil
+	return records, fileErrs, nil
 }
 
 // AllRecords is an async version of LoadAllRecords.
@@ -209,192 +338,268 @@ func (t *Track) AllRecords() (func(), chan FilterResult, chan struct{}) {
 	return t.AllRecordsFiltered(NewFilter([]func(*Record) bool{}, util.NoTime, util.NoTime), false)
 }
 
+// stopResults signals an async search to end, and waits until it has finished
+func stopResults(results chan FilterResult, stop chan struct{}) {
+	close(stop)
+	for range results {
+	}
+}
+
+// numLoadWorkers is the maximum number of day directories loaded in parallel
+const numLoadWorkers = 16
+
 // AllRecordsFiltered is an async version of LoadAllRecordsFiltered.
+// In contrast to LoadAllRecordsFiltered, it does not exclude records of archived projects.
+//
+// Day directories are loaded in parallel by a bounded pool of workers,
+// while results are emitted in chronological order (or reversed).
+//
+// With a start time in the filters, a record that starts on an earlier day, but reaches into the time range,
+// is included, no matter how many days it spans.
 //
 // Returns a function to be run as goroutine,
 // a channel for results, and a channel that can be closed
 // to signal end of the search.
 func (t *Track) AllRecordsFiltered(filters FilterFunctions, reversed bool) (func(), chan FilterResult, chan struct{}) {
-	numWorkers := 32
-	results := make(chan FilterResult, 64)
+	return t.allRecordsFiltered(context.Background(), filters, reversed)
+}
 
-	fn, listResults, stop := t.listAllRecordsFiltered(filters, reversed)
+// allRecordsFiltered is AllRecordsFiltered, with a context for tracing
+func (t *Track) allRecordsFiltered(ctx context.Context, filters FilterFunctions, reversed bool) (func(), chan FilterResult, chan struct{}) {
+	results := make(chan FilterResult, 64)
+	stop := make(chan struct{})
 
 	return func() {
 		defer close(results)
 
-		go fn()
-
-		worker := func(index int, tasks chan time.Time, ch chan workerResult) {
-			for tm := range tasks {
-				record, err := t.LoadRecord(tm)
-				ch <- workerResult{index, record, err}
-			}
-		}
-
-		process := func(index int, times []time.Time, taskChannels []chan time.Time, resChannels []chan workerResult) {
-			for i := 0; i < index; i++ {
-				taskChannels[i] <- times[i]
-			}
-			for i := 0; i < index; i++ {
+		ctx, span := t.startSpan(ctx, "track.walk")
+		defer span.End()
+		days, records := 0, 0
+		defer func() {
+			span.SetAttribute("days", days)
+			span.SetAttribute("records", records)
+		}()
+
+		// Records from days before the start date are not loaded by the day-based walk.
+		// Only the latest of them can reach into the time range, as records don't overlap.
+		var before *Record
+		if !filters.Start.IsZero() {
+			rec, err := t.recordOverlapping(ctx, util.ToDate(filters.Start))
+			if err != nil {
 				select {
 				case <-stop:
-					return
-				default:
-				}
-
-				res := <-resChannels[i]
-
-				fr := FilterResult{res.Record, res.Err}
-				if res.Err != nil {
-					results <- fr
-					return
-				}
-				if Filter(&res.Record, filters) {
-					results <- fr
+				case results <- FilterResult{Record{}, err}:
 				}
+				return
+			}
+			if rec != nil && Filter(rec, filters) {
+				before = rec
+			}
+		}
+		if before != nil && !reversed {
+			select {
+			case <-stop:
+				return
+			case results <- FilterResult{*before, nil}:
 			}
 		}
 
-		tempTimes := make([]time.Time, numWorkers)
+		// Waits for the directory walk and all workers to finish before returning
+		var wg sync.WaitGroup
+		defer wg.Wait()
 
-		taskChannels := make([]chan time.Time, numWorkers)
-		resChannels := make([]chan workerResult, numWorkers)
+		// Signals the directory walk to end when results are no longer consumed
+		done := make(chan struct{})
+		defer close(done)
 
-		index := 0
+		// Results of days in walk order, bounding the number of days in flight
+		order := make(chan chan dayResult, numLoadWorkers)
+		workers := make(chan struct{}, numLoadWorkers)
 
-		for i := 0; i < numWorkers; i++ {
-			taskChannels[i] = make(chan time.Time, 4)
-			resChannels[i] = make(chan workerResult, 4)
-			defer close(taskChannels[i])
-			go worker(i, taskChannels[i], resChannels[i])
-		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(order)
+			err := t.walkDays(filters, reversed, func(date time.Time) bool {
+				ch := make(chan dayResult, 1)
+				select {
+				case <-done:
+					return false
+				case workers <- struct{}{}:
+				}
+				select {
+				case <-done:
+					<-workers
+					return false
+				case order <- ch:
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-workers }()
+					records, fileErrs, err := t.loadDayFiltered(ctx, date, filters, reversed)
+					ch <- dayResult{records, fileErrs, err}
+				}()
+				return true
+			})
+			if err != nil {
+				ch := make(chan dayResult, 1)
+				ch <- dayResult{nil, nil, err}
+				select {
+				case <-done:
+				case order <- ch:
+				}
+			}
+		}()
 
-		for rec := range listResults {
-			if rec.Err != nil {
-				results <- FilterResult{Record{}, rec.Err}
+		for ch := range order {
+			res := <-ch
+			days++
+			if res.Err != nil {
+				select {
+				case <-stop:
+				case results <- FilterResult{Record{}, res.Err}:
+				}
 				return
 			}
-			tempTimes[index] = rec.Time
-
-			index++
-			if index >= numWorkers {
-				process(index, tempTimes, taskChannels, resChannels)
-				index = 0
+			for _, err := range res.FileErrs {
+				select {
+				case <-stop:
+					return
+				case results <- FilterResult{Record{}, err}:
+				}
+			}
+			for _, rec := range res.Records {
 				select {
 				case <-stop:
 					return
-				default:
+				case results <- FilterResult{rec, nil}:
+					records++
 				}
 			}
 		}
-		if index > 0 {
-			process(index, tempTimes, taskChannels, resChannels)
+
+		if before != nil && reversed {
+			select {
+			case <-stop:
+			case results <- FilterResult{*before, nil}:
+			}
 		}
 	}, results, stop
 }
 
-func (t *Track) listAllRecordsFiltered(filters FilterFunctions, reversed bool) (func(), chan listFilterResult, chan struct{}) {
-	results := make(chan listFilterResult, 64)
-	stop := make(chan struct{})
+// walkDays calls fn for all day directories in the time range of the filters, in chronological order (or reversed).
+// Stops walking if fn returns false.
+func (t *Track) walkDays(filters FilterFunctions, reversed bool, fn func(date time.Time) bool) error {
+	path := t.RecordsDir()
 
-	return func() {
-		defer close(results)
+	yearDirs, err := t.FileSystem().ReadDir(path)
+	if err != nil {
+		return err
+	}
+	if reversed {
+		util.Reverse(yearDirs)
+	}
 
-		path := t.RecordsDir()
+	for _, yearDir := range yearDirs {
+		if !yearDir.IsDir() {
+			continue
+		}
+		year, err := strconv.Atoi(yearDir.Name())
+		if err != nil {
+			return err
+		}
+		if (!filters.Start.IsZero() && year < filters.Start.Year()) ||
+			(!filters.End.IsZero() && year > filters.End.Year()) {
+			t.logger().Debug("skipping year outside time range", "year", year)
+			continue
+		}
 
-		yearDirs, err := os.ReadDir(path)
+		monthDirs, err := t.FileSystem().ReadDir(filepath.Join(path, yearDir.Name()))
 		if err != nil {
-			results <- listFilterResult{util.NoTime, err}
-			return
+			return err
 		}
 		if reversed {
-			util.Reverse(yearDirs)
+			util.Reverse(monthDirs)
 		}
 
-		for _, yearDir := range yearDirs {
-			if !yearDir.IsDir() {
+		for _, monthDir := range monthDirs {
+			if !monthDir.IsDir() {
 				continue
 			}
-			year, err := strconv.Atoi(yearDir.Name())
+			month, err := strconv.Atoi(monthDir.Name())
 			if err != nil {
-				results <- listFilterResult{util.NoTime, err}
-				return
-			}
-			if !filters.Start.IsZero() && year < filters.Start.Year() {
-				continue
-			}
-			if !filters.End.IsZero() && year > filters.End.Year() {
-				continue
+				return err
 			}
 
-			monthDirs, err := os.ReadDir(filepath.Join(path, yearDir.Name()))
-
-			if reversed {
-				util.Reverse(monthDirs)
-			}
+			dayDirs, err := t.FileSystem().ReadDir(filepath.Join(path, yearDir.Name(), monthDir.Name()))
 			if err != nil {
-				results <- listFilterResult{util.NoTime, err}
-				return
+				return err
+			}
+			if reversed {
+				util.Reverse(dayDirs)
 			}
 
-			for _, monthDir := range monthDirs {
-				if !monthDir.IsDir() {
+			for _, dayDir := range dayDirs {
+				if !dayDir.IsDir() {
 					continue
 				}
-				month, err := strconv.Atoi(monthDir.Name())
+				day, err := strconv.Atoi(dayDir.Name())
 				if err != nil {
-					results <- listFilterResult{util.NoTime, err}
-					return
+					return err
 				}
 
-				dayDirs, err := os.ReadDir(filepath.Join(path, yearDir.Name(), monthDir.Name()))
-				if err != nil {
-					results <- listFilterResult{util.NoTime, err}
-					return
+				date := util.Date(year, time.Month(month), day)
+				if (!filters.Start.IsZero() && date.Before(util.ToDate(filters.Start))) ||
+					(!filters.End.IsZero() && date.After(filters.End)) {
+					t.logger().Debug("skipping day outside time range", "date", date.Format(util.DateFormat))
+					continue
 				}
 
-				if reversed {
-					util.Reverse(dayDirs)
-				}
-				for _, dayDir := range dayDirs {
-					if !dayDir.IsDir() {
-						continue
-					}
-					day, err := strconv.Atoi(dayDir.Name())
-					if err != nil {
-						results <- listFilterResult{util.NoTime, err}
-						return
-					}
-
-					date := util.Date(year, time.Month(month), day)
-					if !filters.Start.IsZero() && date.Before(util.ToDate(filters.Start)) {
-						continue
-					}
-					if !filters.End.IsZero() && date.After(filters.End) {
-						continue
-					}
-
-					recs, err := t.listDateRecords(date)
-					if err != nil {
-						results <- listFilterResult{util.NoTime, err}
-						return
-					}
-
-					if reversed {
-						util.Reverse(recs)
-					}
-					for _, rec := range recs {
-						select {
-						case <-stop:
-							return
-						case results <- listFilterResult{rec, nil}:
-						}
-					}
+				if !fn(date) {
+					return nil
 				}
 			}
 		}
-	}, results, stop
+	}
+	return nil
+}
+
+// loadDayFiltered loads all records of a day directory that match the filters.
+// Files that can't be read are skipped, and their errors are returned separately.
+func (t *Track) loadDayFiltered(ctx context.Context, date time.Time, filters FilterFunctions, reversed bool) ([]Record, []error, error) {
+	recs, fileErrs, err := t.readDayRecordsLenient(ctx, date)
+	if err != nil {
+		return nil, nil, err
+	}
+	records := make([]Record, 0, len(recs))
+	for _, record := range recs {
+		if Filter(&record, filters) {
+			records = append(records, record)
+			continue
+		}
+		t.logFiltered(&record, filters)
+	}
+	if reversed {
+		util.Reverse(records)
+	}
+	return records, fileErrs, nil
+}
+
+// logFiltered logs why a record was excluded by filters
+func (t *Track) logFiltered(record *Record, filters FilterFunctions) {
+	log := t.logger()
+	if !log.Enabled(slog.LevelDebug) {
+		return
+	}
+	reason := "filters"
+	if !FilterByTime(filters.Start, filters.End)(record) {
+		reason = "time range"
+	}
+	log.Debug(
+		"record excluded",
+		"start", record.Start.Format(util.DateTimeFormat), "project", record.Project, "reason", reason,
+	)
 }
 
 // LoadDateRecords loads all records for the given date
@@ -403,49 +608,81 @@ func (t *Track) LoadDateRecords(date time.Time) ([]Record, error) {
 }
 
 // LoadDateRecordsExact loads all records for the given date,
-// including those starting the day before but ending at the given date.
+// including a record starting on a previous day but ending at the given date or later.
+// The record may start any number of days before.
 func (t *Track) LoadDateRecordsExact(date time.Time) ([]Record, error) {
 	date = util.ToDate(date)
-	dateBefore := date.Add(-24 * time.Hour)
-	dateAfter := date.Add(24 * time.Hour)
 
-	filters := FilterFunctions{
-		[]FilterFunction{FilterByTime(date, dateAfter)},
-		util.NoTime,
-		util.NoTime,
-	}
-
-	records, err := t.LoadDateRecordsFiltered(dateBefore, filters)
-	if err != nil && !errors.Is(err, ErrNoRecords) {
-		return nil, err
-	}
-	records2, err := t.LoadDateRecordsFiltered(date, filters)
-	if err != nil && !errors.Is(err, ErrNoRecords) {
+	filters := NewFilter([]FilterFunction{}, date, date.AddDate(0, 0, 1))
+	filters.IncludeArchived = true
+	records, err := t.LoadAllRecordsFiltered(filters)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
 		return nil, err
 	}
-	records = append(records, records2...)
-
 	if len(records) == 0 {
 		return nil, ErrNoRecords
 	}
 	return records, nil
 }
 
+// RecordsOverlapping returns all records that intersect the time span from start to end, sorted by start time.
+// Records that end exactly at start, or start exactly at end, are not included.
+// Running records are considered to end now.
+//
+// Only the day directories of the span are read, plus the days before it up to the latest earlier record,
+// which is included if it reaches into the span.
+func (t *Track) RecordsOverlapping(start, end time.Time) ([]Record, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("end of time span must be after start")
+	}
+	filters := NewFilter([]FilterFunction{}, start, end)
+	filters.IncludeArchived = true
+	return t.LoadAllRecordsFiltered(filters)
+}
+
+// recordOverlapping returns the latest record that starts before the given time,
+// if it ends after that time or is still running.
+// As records don't overlap, this is the only record that can span the given time.
+//
+// Returns a nil reference if there is no such record.
+func (t *Track) recordOverlapping(ctx context.Context, tm time.Time) (*Record, error) {
+	filters := FilterFunctions{
+		Functions: []FilterFunction{func(r *Record) bool { return r.Start.Before(tm) }},
+		End:       tm,
+	}
+	fn, results, stop := t.allRecordsFiltered(ctx, filters, true)
+	go fn()
+	defer stopResults(results, stop)
+
+	for res := range results {
+		if res.Err != nil {
+			// Record files that can't be read are skipped
+			if IsRecordFileError(res.Err) {
+				continue
+			}
+			if errors.Is(res.Err, os.ErrNotExist) {
+				return nil, nil
+			}
+			return nil, res.Err
+		}
+		if res.Record.HasEnded() && !res.Record.End.After(tm) {
+			return nil, nil
+		}
+		return &res.Record, nil
+	}
+	return nil, nil
+}
+
 // LoadDateRecordsFiltered loads all records for the given date,
 // filtered by FilterFunctions.
 func (t *Track) LoadDateRecordsFiltered(date time.Time, filters FilterFunctions) ([]Record, error) {
-	recs, err := t.listDateRecords(date)
+	recs, err := t.readDayRecords(date)
 	if err != nil {
 		return nil, err
 	}
 
 	records := make([]Record, 0, len(recs))
-
-	for _, tm := range recs {
-		record, err := t.LoadRecord(tm)
-		if err != nil {
-			return nil, err
-		}
+	for _, record := range recs {
 		if Filter(&record, filters) {
 			records = append(records, record)
 		}
@@ -455,103 +692,238 @@ func (t *Track) LoadDateRecordsFiltered(date time.Time, filters FilterFunctions)
 }
 
 func (t *Track) listDateRecords(date time.Time) ([]time.Time, error) {
-	subPath := t.RecordDir(date)
-
-	info, err := os.Stat(subPath)
-	if err != nil {
-		return nil, ErrNoRecords
-	}
-	if !info.IsDir() {
-		return nil, fmt.Errorf("'%s' is not a directory", info.Name())
-	}
-
-	var records []time.Time
-
-	files, err := os.ReadDir(subPath)
+	records, err := t.readDayRecords(date)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
-
-		tm, err := fileToTime(date, file.Name())
-		if err != nil {
-			return nil, err
-		}
-		records = append(records, tm)
+	times := make([]time.Time, len(records))
+	for i := range records {
+		times[i] = records[i].Start
 	}
-
-	return records, nil
+	return times, nil
 }
 
-// SaveRecord saves the given record to disk.
-// Argument `force` allows to overwrite an existing file.
+// SaveRecord saves the given record to disk, in the format given by the config.
+// Argument `force` allows to overwrite an existing record.
+// Overwriting a locked record fails with ErrRecordLocked, unless only the lock is changed.
+// An existing record in another format is replaced.
+// The saved version is appended to the record's history if config entry History is enabled.
 func (t *Track) SaveRecord(record *Record, force bool) error {
-	path := t.RecordPath(record.Start)
-	if !force && util.FileExists(path) {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	existing, err := t.findRecordFile(record.Start)
+	if err != nil {
+		return err
+	}
+	if !force && existing != "" {
 		return fmt.Errorf("record already exists")
 	}
+	if existing != "" {
+		if stored, err := t.loadRecordAt(record.Start); err == nil && changesLocked(&stored, record) {
+			return ErrRecordLocked
+		}
+	}
 	dir := t.RecordDir(record.Start)
-	err := util.CreateDir(dir)
+	err = t.createDir(dir)
 	if err != nil {
 		return err
 	}
 
-	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
+	format := t.Config.RecordFileFormat
+	if existing != "" && existing != format {
+		if err := t.removeRecordAt(record.Start, existing); err != nil {
+			return err
+		}
+	}
+	if err := t.writeRecord(record, format); err != nil {
 		return err
 	}
-	defer file.Close()
+	if t.Config.History {
+		return t.appendHistory(record, false)
+	}
+	return nil
+}
 
-	bytes := SerializeRecord(record, util.NoTime)
+// DeleteRecord deletes a record.
+// Deleting a locked record fails with ErrRecordLocked.
+//
+// The record is moved to the trash, unless config entry TrashDays is zero.
+// Records in the trash that are older than TrashDays are purged.
+func (t *Track) DeleteRecord(record *Record) error {
+	return t.deleteRecord(record, time.Now())
+}
 
-	_, err = fmt.Fprintf(file, "%s Record %s\n", CommentPrefix, record.Start.Format(util.DateTimeFormat))
+// deleteRecord deletes a record, and moves it to the trash with the given time of deletion
+func (t *Track) deleteRecord(record *Record, deleted time.Time) error {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	format, err := t.findRecordFile(record.Start)
 	if err != nil {
 		return err
 	}
-
-	_, err = file.WriteString(bytes)
-
-	return err
-}
-
-// DeleteRecord deletes a record
-func (t *Track) DeleteRecord(record *Record) error {
-	path := t.RecordPath(record.Start)
-	if !util.FileExists(path) {
+	if format == "" {
 		return fmt.Errorf("record does not exist")
 	}
-	err := os.Remove(path)
+	stored, err := t.loadRecordAt(record.Start)
+	if err != nil {
+		// E.g. for a checksum mismatch
+		stored = *record
+	}
+	if stored.Locked {
+		return ErrRecordLocked
+	}
+	if t.Config.TrashDays > 0 {
+		if err := t.trashRecord(&stored, format, deleted); err != nil {
+			return fmt.Errorf("failed to move record to trash: %s", err)
+		}
+		if _, err := t.PurgeTrash(time.Duration(t.Config.TrashDays) * 24 * time.Hour); err != nil {
+			return fmt.Errorf("failed to purge trash: %s", err)
+		}
+	}
+	err = t.removeRecordAt(record.Start, format)
 	if err != nil {
 		return err
 	}
-	dayDir := filepath.Dir(path)
-	empty, err := util.DirIsEmpty(dayDir)
+	if err := t.logDeletion(record.Start, deleted); err != nil {
+		return fmt.Errorf("failed to log deletion: %s", err)
+	}
+	if t.Config.History {
+		if err := t.appendHistory(record, true); err != nil {
+			return err
+		}
+	}
+	return t.removeEmptyDayDir(t.RecordDir(record.Start))
+}
+
+// removeEmptyDayDir removes a day directory of records if it is empty, as well as its month and year directories
+func (t *Track) removeEmptyDayDir(dayDir string) error {
+	empty, err := t.dirIsEmpty(dayDir)
 	if err != nil {
 		return err
 	}
 	if empty {
-		os.Remove(dayDir)
+		t.FileSystem().Remove(dayDir)
 		monthDir := filepath.Dir(dayDir)
-		empty, err := util.DirIsEmpty(monthDir)
+		empty, err := t.dirIsEmpty(monthDir)
 		if err != nil {
 			return err
 		}
 		if empty {
-			os.Remove(monthDir)
+			t.FileSystem().Remove(monthDir)
 			yearDir := filepath.Dir(monthDir)
-			empty, err := util.DirIsEmpty(yearDir)
+			empty, err := t.dirIsEmpty(yearDir)
 			if err != nil {
 				return err
 			}
 			if empty {
-				os.Remove(yearDir)
+				t.FileSystem().Remove(yearDir)
 
 			}
 		}
 	}
 	return nil
 }
+
+// recordCursorFormat is the format of cursors for paginated loading, resembling the record's relative path
+const recordCursorFormat = "2006/01/02/15-04"
+
+// LoadRecordsPage loads a page of records, filtered by FilterFunctions, in reverse chronological order.
+//
+// Argument cursor is empty for the first page, and the cursor returned by the previous call for the following pages.
+// Returns the records of the page, and the cursor for the next page.
+// The returned cursor is empty if there are no further records.
+func (t *Track) LoadRecordsPage(filters FilterFunctions, cursor string, limit int) ([]Record, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("page limit must be positive")
+	}
+
+	pageFilters := FilterFunctions{
+		Functions: append([]FilterFunction{}, filters.Functions...),
+		Start:     filters.Start,
+		End:       filters.End,
+	}
+	if cursor != "" {
+		before, err := time.ParseInLocation(recordCursorFormat, cursor, time.Local)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid page cursor '%s'", cursor)
+		}
+		// Restrict the directory walk to days up to the cursor
+		if pageFilters.End.IsZero() || before.Before(pageFilters.End) {
+			pageFilters.End = before
+		}
+		pageFilters.Functions = append(pageFilters.Functions, func(r *Record) bool {
+			return r.Start.Before(before)
+		})
+	}
+
+	fn, results, stop := t.AllRecordsFiltered(pageFilters, true)
+	go fn()
+
+	records := make([]Record, 0, limit)
+	for res := range results {
+		if res.Err != nil {
+			stopResults(results, stop)
+			return nil, "", res.Err
+		}
+		if len(records) == limit {
+			stopResults(results, stop)
+			return records, records[limit-1].Start.Format(recordCursorFormat), nil
+		}
+		records = append(records, res.Record)
+	}
+
+	return records, "", nil
+}
+
+// RecordsBefore returns up to n records that start before the given time,
+// in reverse chronological order, i.e. the nearest record first.
+//
+// Day directories are walked backwards from the given time,
+// and the walk ends as soon as n records are found.
+// Use it for browsing the history, together with RecordsAfter.
+func (t *Track) RecordsBefore(tm time.Time, n int) ([]Record, error) {
+	filters := FilterFunctions{
+		Functions: []FilterFunction{func(r *Record) bool { return r.Start.Before(tm) }},
+		End:       tm,
+	}
+	return t.adjacentRecords(filters, true, n)
+}
+
+// RecordsAfter returns up to n records that start at or after the given time,
+// in chronological order, i.e. the nearest record first.
+//
+// Day directories are walked forward from the given time,
+// and the walk ends as soon as n records are found.
+func (t *Track) RecordsAfter(tm time.Time, n int) ([]Record, error) {
+	filters := FilterFunctions{
+		Functions: []FilterFunction{func(r *Record) bool { return !r.Start.Before(tm) }},
+		Start:     tm,
+	}
+	return t.adjacentRecords(filters, false, n)
+}
+
+// adjacentRecords loads the first n records in walk order that match the filters
+func (t *Track) adjacentRecords(filters FilterFunctions, reversed bool, n int) ([]Record, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("number of records must be positive")
+	}
+
+	fn, results, stop := t.AllRecordsFiltered(filters, reversed)
+	go fn()
+	defer stopResults(results, stop)
+
+	records := make([]Record, 0, n)
+	for res := range results {
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		records = append(records, res.Record)
+		if len(records) == n {
+			break
+		}
+	}
+	return records, nil
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -104,6 +105,9 @@ func TestStartStopRecord(t *testing.T) {
 	track, err := NewTrack(&dir)
 	assert.Nil(t, err, "Error creating Track instance")
 
+	_, err = track.StopRecord(time.Now())
+	assert.ErrorIs(t, err, ErrNoOpenRecord, "Expected error for stopping without running record")
+
 	start := time.Now().Round(time.Minute).Add(-time.Hour)
 	record, err := track.StartRecord(&project, "", map[string]string{}, start)
 	assert.Nil(t, err, "Error starting record")
@@ -128,3 +132,458 @@ func TestStartStopRecord(t *testing.T) {
 	assert.Nil(t, err, "Error loading record")
 	assert.Equal(t, stopped, lastRecord, "Loaded record not equal to saved record")
 }
+
+func TestLoadRecordsPage(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	err = generateDataset(&track, util.Date(2001, 2, 3), 8*time.Hour, 10)
+	assert.Nil(t, err, "Error generating records")
+
+	all, err := track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	util.Reverse(all)
+
+	loaded := []Record{}
+	cursor := ""
+	pages := 0
+	for {
+		page, next, err := track.LoadRecordsPage(FilterFunctions{}, cursor, 4)
+		assert.Nil(t, err, "Error loading page")
+		loaded = append(loaded, page...)
+		pages++
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	assert.Equal(t, 3, pages, "Wrong number of pages")
+	assert.Equal(t, len(all), len(loaded), "Wrong number of records")
+	for i := range all {
+		assert.Equal(t, all[i].Start, loaded[i].Start, "Wrong record order")
+	}
+
+	page, next, err := track.LoadRecordsPage(FilterFunctions{}, "", 10)
+	assert.Nil(t, err, "Error loading page")
+	assert.Equal(t, 10, len(page), "Wrong page size")
+	assert.Equal(t, "", next, "Expected empty cursor for last page")
+
+	_, _, err = track.LoadRecordsPage(FilterFunctions{}, "foo", 10)
+	assert.NotNil(t, err, "Expected error for invalid cursor")
+	_, _, err = track.LoadRecordsPage(FilterFunctions{}, "", 0)
+	assert.NotNil(t, err, "Expected error for invalid limit")
+}
+
+func TestRecordsBeforeAfter(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	err = generateDataset(&track, util.Date(2001, 2, 3), 8*time.Hour, 10)
+	assert.Nil(t, err, "Error generating records")
+
+	all, err := track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+
+	before, err := track.RecordsBefore(all[5].Start, 3)
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, []Record{all[4], all[3], all[2]}, before, "Wrong records before")
+
+	after, err := track.RecordsAfter(all[5].Start, 3)
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, []Record{all[5], all[6], all[7]}, after, "Wrong records after")
+
+	before, err = track.RecordsBefore(all[1].Start, 3)
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, []Record{all[0]}, before, "Wrong records before")
+
+	after, err = track.RecordsAfter(all[len(all)-1].End, 3)
+	assert.Nil(t, err, "Error loading records")
+	assert.Empty(t, after, "Expected no records after")
+
+	_, err = track.RecordsBefore(all[5].Start, 0)
+	assert.NotNil(t, err, "Expected error for invalid number of records")
+}
+
+func TestAllRecordsFilteredOrder(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	err = generateDataset(&track, util.Date(2001, 12, 20), 5*time.Hour, 200)
+	assert.Nil(t, err, "Error generating records")
+
+	for _, reversed := range []bool{false, true} {
+		fn, results, _ := track.AllRecordsFiltered(FilterFunctions{}, reversed)
+		go fn()
+
+		count := 0
+		prev := util.NoTime
+		for res := range results {
+			assert.Nil(t, res.Err, "Error loading records")
+			if !prev.IsZero() {
+				assert.Equal(t, reversed, res.Record.Start.Before(prev), "Wrong record order")
+			}
+			prev = res.Record.Start
+			count++
+		}
+		assert.Equal(t, 200, count, "Wrong number of records")
+	}
+
+	fn, results, stop := track.AllRecordsFiltered(FilterFunctions{}, false)
+	go fn()
+	<-results
+	close(stop)
+	for range results {
+	}
+}
+
+func TestAllRecordsFilteredMultiDay(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	for _, name := range []string{"test", "other"} {
+		assert.Nil(t, track.SaveProject(NewProject(name, "", name[:1], []string{}, 0, 0), false), "Error saving project")
+	}
+
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 1, 8, 0, 0), End: util.DateTime(2001, 2, 1, 9, 0, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 2, 17, 0, 0), End: util.DateTime(2001, 2, 5, 9, 0, 0)},
+		{Project: "other", Start: util.DateTime(2001, 2, 5, 10, 0, 0), End: util.DateTime(2001, 2, 5, 11, 0, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false), "Error saving record")
+	}
+
+	filters := NewFilter([]FilterFunction{}, util.Date(2001, 2, 4), util.Date(2001, 2, 6))
+	loaded, err := track.LoadAllRecordsFiltered(filters)
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 2, len(loaded), "Wrong number of records")
+	assert.Equal(t, records[1].Start, loaded[0].Start, "Missing record from previous days")
+	assert.Equal(t, records[2].Start, loaded[1].Start, "Wrong record")
+
+	fn, results, _ := track.AllRecordsFiltered(filters, true)
+	go fn()
+	starts := []time.Time{}
+	for res := range results {
+		assert.Nil(t, res.Err, "Error loading records")
+		starts = append(starts, res.Record.Start)
+	}
+	assert.Equal(t, []time.Time{records[2].Start, records[1].Start}, starts, "Wrong reversed records")
+
+	filters = NewFilter([]FilterFunction{FilterByProjects([]string{"other"})}, util.Date(2001, 2, 4), util.Date(2001, 2, 6))
+	loaded, err = track.LoadAllRecordsFiltered(filters)
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 1, len(loaded), "Record from previous days should be filtered")
+
+	filters = NewFilter([]FilterFunction{}, util.Date(2001, 2, 5), util.Date(2001, 2, 6))
+	reporter, err := NewReporter(&track, []string{}, filters, false, util.Date(2001, 2, 5), util.Date(2001, 2, 6))
+	assert.Nil(t, err, "Error creating reporter")
+	assert.Equal(t, 9*time.Hour, reporter.TotalTime["test"], "Wrong clipped time")
+	assert.Equal(t, 2, len(reporter.Records), "Wrong number of records")
+}
+
+func TestLoadAllRecordsFilteredArchived(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	archived := NewProject("old", "", "O", []string{}, 0, 0)
+	archived.Archived = true
+	assert.Nil(t, track.SaveProject(archived, false), "Error saving project")
+	assert.Nil(t, track.SaveProject(NewProject("test", "", "T", []string{}, 0, 0), false), "Error saving project")
+
+	records := []Record{
+		{Project: "old", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 10, 0, 0), End: util.DateTime(2001, 2, 3, 11, 0, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false), "Error saving record")
+	}
+
+	filters := NewFilter([]FilterFunction{}, util.NoTime, util.NoTime)
+	loaded, err := track.LoadAllRecordsFiltered(filters)
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 1, len(loaded), "Records of archived projects should be excluded")
+	assert.Equal(t, "test", loaded[0].Project, "Wrong record")
+	assert.Equal(t, 0, len(filters.Functions), "Filters should not be modified")
+
+	filters.IncludeArchived = true
+	loaded, err = track.LoadAllRecordsFiltered(filters)
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 2, len(loaded), "Records of archived projects should be included")
+
+	loaded, err = track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 2, len(loaded), "Records of archived projects should be included")
+
+	reporter, err := NewReporter(&track, []string{}, filters, false, util.NoTime, util.NoTime)
+	assert.Nil(t, err, "Error creating reporter")
+	assert.Equal(t, time.Hour, reporter.TotalTime["old"], "Records of archived projects should be included")
+}
+
+func TestAllRecordsFilteredCorruptFile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	assert.Nil(t, track.SaveProject(NewProject("test", "", "T", []string{}, 0, 0), false), "Error saving project")
+
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 1, 8, 0, 0), End: util.DateTime(2001, 2, 1, 9, 0, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 2, 8, 0, 0), End: util.DateTime(2001, 2, 2, 9, 0, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false), "Error saving record")
+	}
+	corrupt := track.RecordPath(util.DateTime(2001, 2, 2, 10, 0, 0))
+	assert.Nil(t, os.WriteFile(corrupt, []byte("garbage\n"), 0644), "Error writing file")
+
+	fn, results, _ := track.AllRecordsFiltered(FilterFunctions{}, false)
+	go fn()
+	starts := []time.Time{}
+	fileErrs := []error{}
+	for res := range results {
+		if res.Err != nil {
+			fileErrs = append(fileErrs, res.Err)
+			continue
+		}
+		starts = append(starts, res.Record.Start)
+	}
+	assert.Equal(t, []time.Time{records[0].Start, records[1].Start, records[2].Start}, starts, "Wrong records")
+	assert.Equal(t, 1, len(fileErrs), "Wrong number of errors")
+
+	var fileErr *RecordFileError
+	assert.True(t, errors.As(fileErrs[0], &fileErr), "Wrong error type")
+	assert.Equal(t, corrupt, fileErr.Path, "Wrong path")
+	assert.Equal(t, util.Date(2001, 2, 2), fileErr.Date, "Wrong date")
+
+	_, err = track.LoadAllRecords()
+	assert.True(t, IsRecordFileError(err), "Loading all records should fail")
+
+	loaded, errs, err := track.LoadAllRecordsFilteredLenient(FilterFunctions{})
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 3, len(loaded), "Wrong number of records")
+	assert.Equal(t, 1, len(errs), "Wrong number of errors")
+	assert.True(t, IsRecordFileError(errs[0]), "Wrong error type")
+	_, err = track.LoadRecord(util.DateTime(2001, 2, 2, 10, 0, 0))
+	assert.True(t, errors.As(err, &fileErr), "Wrong error type for loading a corrupt record")
+	assert.Equal(t, corrupt, fileErr.Path, "Wrong path")
+
+	reporter, err := NewReporter(&track, []string{}, FilterFunctions{}, false, util.NoTime, util.NoTime)
+	assert.Nil(t, err, "Error creating reporter")
+	assert.Equal(t, 3, len(reporter.Records), "Wrong number of records")
+	assert.Equal(t, 1, len(reporter.Errors), "Wrong number of errors")
+
+	track.Config.ReportCache = true
+	filters := NewFilter([]FilterFunction{}, util.Date(2001, 2, 1), util.Date(2001, 2, 4))
+	reporter, err = NewCachedReporter(&track, []string{}, filters, false, util.Date(2001, 2, 1), util.Date(2001, 2, 4))
+	assert.Nil(t, err, "Error creating reporter")
+	assert.Equal(t, 3*time.Hour, reporter.TotalTime["test"], "Wrong total time")
+	assert.Equal(t, 1, len(reporter.Errors), "Wrong number of errors")
+
+	// Days with corrupt files are not cached
+	reporter, err = NewCachedReporter(&track, []string{}, filters, false, util.Date(2001, 2, 1), util.Date(2001, 2, 4))
+	assert.Nil(t, err, "Error creating reporter")
+	assert.Equal(t, 3*time.Hour, reporter.TotalTime["test"], "Wrong total time")
+	assert.Equal(t, 1, len(reporter.Errors), "Wrong number of errors")
+}
+
+func TestRecordsOverlapping(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 1, 8, 0, 0), End: util.DateTime(2001, 2, 1, 9, 0, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 2, 22, 0, 0), End: util.DateTime(2001, 2, 4, 2, 0, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 4, 8, 0, 0), End: util.DateTime(2001, 2, 4, 9, 0, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 4, 10, 0, 0), End: util.DateTime(2001, 2, 4, 11, 0, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false), "Error saving record")
+	}
+
+	tests := []struct {
+		start, end time.Time
+		expected   []time.Time
+	}{
+		{util.DateTime(2001, 2, 4, 1, 0, 0), util.DateTime(2001, 2, 4, 8, 30, 0), []time.Time{records[1].Start, records[2].Start}},
+		{util.DateTime(2001, 2, 3, 0, 0, 0), util.DateTime(2001, 2, 3, 12, 0, 0), []time.Time{records[1].Start}},
+		{util.DateTime(2001, 2, 4, 9, 0, 0), util.DateTime(2001, 2, 4, 10, 0, 0), []time.Time{}},
+		{util.DateTime(2001, 2, 1, 9, 0, 0), util.DateTime(2001, 2, 2, 22, 0, 0), []time.Time{}},
+		{util.DateTime(2001, 1, 1, 0, 0, 0), util.DateTime(2001, 3, 1, 0, 0, 0), []time.Time{records[0].Start, records[1].Start, records[2].Start, records[3].Start}},
+	}
+	for _, tt := range tests {
+		found, err := track.RecordsOverlapping(tt.start, tt.end)
+		assert.Nil(t, err, "Error querying records")
+		starts := []time.Time{}
+		for _, rec := range found {
+			starts = append(starts, rec.Start)
+		}
+		assert.Equal(t, tt.expected, starts, "Wrong records for %s - %s", tt.start, tt.end)
+	}
+
+	_, err = track.RecordsOverlapping(util.DateTime(2001, 2, 4, 9, 0, 0), util.DateTime(2001, 2, 4, 9, 0, 0))
+	assert.NotNil(t, err, "Expected error for empty time span")
+}
+
+func TestStopRecordSplit(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	track.Config.MidnightPolicy = MidnightSplit
+
+	project := NewProject("test", "", "t", []string{}, 15, 0)
+	_, err = track.StartRecord(&project, "", map[string]string{}, util.DateTime(2001, 2, 3, 20, 0, 0))
+	assert.Nil(t, err, "Error starting record")
+
+	stopped, err := track.StopRecord(util.DateTime(2001, 2, 5, 2, 0, 0))
+	assert.Nil(t, err, "Error stopping record")
+	assert.Equal(t, util.Date(2001, 2, 5), stopped.Start)
+
+	records, err := track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 3, len(records))
+
+	open, err := track.OpenRecord()
+	assert.Nil(t, err, "Error loading record")
+	assert.Nil(t, open)
+}
+
+func TestSwitchRecord(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+
+	project := NewProject("test", "", "t", []string{}, 15, 0)
+	other := NewProject("other", "", "o", []string{}, 15, 0)
+
+	stopped, started, err := track.SwitchRecord(&project, "", map[string]string{}, util.DateTime(2001, 2, 3, 8, 0, 0))
+	assert.Nil(t, err, "Error switching record")
+	assert.Nil(t, stopped, "Expected no stopped record")
+	assert.Equal(t, "test", started.Project)
+
+	// Failing to start leaves the running record unchanged
+	_, _, err = track.SwitchRecord(&other, "", map[string]string{}, util.DateTime(2001, 2, 3, 8, 0, 0))
+	assert.NotNil(t, err, "Expected error for existing record")
+
+	open, err := track.OpenRecord()
+	assert.Nil(t, err, "Error loading record")
+	assert.Equal(t, "test", open.Project)
+	assert.True(t, open.End.IsZero(), "Record should still be running")
+
+	stopped, started, err = track.SwitchRecord(&other, "", map[string]string{}, util.DateTime(2001, 2, 3, 9, 0, 0))
+	assert.Nil(t, err, "Error switching record")
+	assert.Equal(t, "test", stopped.Project)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 9, 0, 0), stopped.End)
+	assert.Equal(t, "other", started.Project)
+
+	records, err := track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 2, len(records))
+
+	open, err = track.OpenRecord()
+	assert.Nil(t, err, "Error loading record")
+	assert.Equal(t, "other", open.Project)
+}
+
+func TestLoadDateRecordsExact(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 1, 8, 0, 0), End: util.DateTime(2001, 2, 1, 9, 0, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 1, 20, 0, 0), End: util.DateTime(2001, 2, 4, 2, 0, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 4, 8, 0, 0), End: util.DateTime(2001, 2, 4, 9, 0, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false), "Error saving record")
+	}
+
+	loaded, err := track.LoadDateRecordsExact(util.Date(2001, 2, 3))
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 1, len(loaded))
+	assert.Equal(t, records[1].Start, loaded[0].Start)
+
+	loaded, err = track.LoadDateRecordsExact(util.Date(2001, 2, 4))
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, 2, len(loaded))
+	assert.Equal(t, records[1].Start, loaded[0].Start)
+	assert.Equal(t, records[2].Start, loaded[1].Start)
+
+	_, err = track.LoadDateRecordsExact(util.Date(2001, 2, 5))
+	assert.ErrorIs(t, err, ErrNoRecords)
+}
+
+func TestReadOnly(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	project := NewProject("test", "", "t", []string{}, 0, 0)
+	assert.Nil(t, track.SaveProject(project, false))
+
+	record := Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	assert.Nil(t, track.SaveRecord(&record, false))
+
+	track.ReadOnly = true
+
+	record.Note = "changed"
+	assert.ErrorIs(t, track.SaveRecord(&record, true), ErrReadOnly)
+	assert.ErrorIs(t, track.DeleteRecord(&record), ErrReadOnly)
+	assert.ErrorIs(t, track.SaveProject(project, true), ErrReadOnly)
+	assert.ErrorIs(t, track.CreateWorkspace("other"), ErrReadOnly)
+
+	_, err = track.RenameTag("foo", "bar")
+	assert.ErrorIs(t, err, ErrReadOnly)
+	_, err = track.MigrateRecords()
+	assert.ErrorIs(t, err, ErrReadOnly)
+
+	_, err = track.DeleteProject(&project, true, false)
+	assert.ErrorIs(t, err, ErrReadOnly)
+	cnt, err := track.DeleteProject(&project, true, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cnt)
+
+	loaded, err := track.LoadRecord(record.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "", loaded.Note)
+	assert.True(t, track.ProjectExists("test"))
+}
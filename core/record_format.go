@@ -4,15 +4,24 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/mlange-42/track/util"
+	"golang.org/x/exp/maps"
 )
 
-var builder = strings.Builder{}
+// linkNoteSeparator separates a link's target from its note in record files
+const linkNoteSeparator = " / "
 
-// SerializeRecord converts a record to a serialization string
+// SerializeRecord converts a record to a serialization string, with the DefaultSyntax.
+// Use Syntax.SerializeRecord for the rules of a Track.
 func SerializeRecord(r *Record, date time.Time) string {
-	builder.Reset()
+	return DefaultSyntax().SerializeRecord(r, date)
+}
+
+// SerializeRecord converts a record to a serialization string
+func (s *Syntax) SerializeRecord(r *Record, date time.Time) string {
+	builder := strings.Builder{}
 
 	reference := date
 	if reference.IsZero() {
@@ -30,34 +39,114 @@ func SerializeRecord(r *Record, date time.Time) string {
 			fmt.Fprintf(&builder, "\n    - %s - %s", startTime, p.End.Sub(p.Start).Round(time.Second))
 		}
 		if p.Note != "" {
-			fmt.Fprintf(&builder, " / %s", p.Note)
+			// Pause notes are single-line
+			fmt.Fprintf(&builder, " / %s", strings.Join(strings.Fields(p.Note), " "))
+		}
+	}
+	for _, l := range r.Links {
+		fmt.Fprintf(&builder, "\n    %s %s", LinkPrefix, l.Target)
+		if l.Note != "" {
+			fmt.Fprintf(&builder, "%s%s", linkNoteSeparator, l.Note)
 		}
 	}
+	if r.Category != "" {
+		fmt.Fprintf(&builder, "\n    %s %s", CategoryPrefix, r.Category)
+	}
+	if r.Locked {
+		fmt.Fprintf(&builder, "\n    %s", LockMarker)
+	}
 	fmt.Fprintf(&builder, "\n    %s", r.Project)
 
 	if len(r.Note) > 0 {
-		fmt.Fprintf(&builder, "\n\n%s", r.Note)
+		fmt.Fprintf(&builder, "\n\n%s", s.escapeNote(r.Note))
 	}
 	fmt.Fprint(&builder, "\n")
 	return builder.String()
 }
 
-// DeserializeRecord converts a serialization string to a record
+// ParseError is an error in the text of a record, with the position of the problem.
+// Returned by DeserializeRecordStrict.
+type ParseError struct {
+	// Line number, starting at 1
+	Line int
+	// Column number, starting at 1
+	Column int
+	// Underlying error
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// DeserializeRecord converts a serialization string to a record, with the DefaultSyntax.
+// Use Syntax.DeserializeRecord for the rules of a Track.
 func DeserializeRecord(str string, date time.Time) (Record, error) {
-	str = strings.TrimSpace(str)
-	lines := strings.Split(strings.ReplaceAll(str, "\r\n", "\n"), "\n")
-	index, ok := skipLines(lines, 0, true)
+	return DefaultSyntax().DeserializeRecord(str, date)
+}
+
+// DeserializeRecordStrict converts a serialization string to a record, with the DefaultSyntax.
+// Use Syntax.DeserializeRecordStrict for the rules of a Track.
+func DeserializeRecordStrict(str string, date time.Time) (Record, error) {
+	return DefaultSyntax().DeserializeRecordStrict(str, date)
+}
+
+// DeserializeRecord converts a serialization string to a record
+func (s *Syntax) DeserializeRecord(str string, date time.Time) (Record, error) {
+	return s.deserializeRecord(str, date, false)
+}
+
+// DeserializeRecordStrict converts a serialization string to a record, like DeserializeRecord.
+//
+// Rejects malformed text that DeserializeRecord reads with a loss of data,
+// like pauses or links after the project line. Errors are of type *ParseError.
+func (s *Syntax) DeserializeRecordStrict(str string, date time.Time) (Record, error) {
+	return s.deserializeRecord(str, date, true)
+}
+
+func (s *Syntax) deserializeRecord(str string, date time.Time, strict bool) (Record, error) {
+	str = strings.ReplaceAll(str, "\r\n", "\n")
+	trimmed := strings.TrimSpace(str)
+	// Line offset of leading empty lines, for error positions
+	offset := strings.Count(str[:strings.Index(str, trimmed)], "\n")
+	lines := strings.Split(trimmed, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, "\r")
+	}
+
+	// posErr adds the position to an error in strict mode
+	posErr := func(index int, column int, err error) error {
+		if !strict {
+			return err
+		}
+		return &ParseError{Line: offset + index + 1, Column: column, Err: err}
+	}
+	// indent returns the column of the first non-space character of a line
+	indent := func(index int) int {
+		return len(lines[index]) - len(strings.TrimLeft(lines[index], " \t")) + 1
+	}
+
+	index, ok := s.skipHeaderLines(lines, 0)
 	if !ok {
-		return Record{}, fmt.Errorf("invalid record: missing time range (1st line)")
+		return Record{}, posErr(len(lines)-1, 1, fmt.Errorf("invalid record: missing time range (1st line)"))
 	}
 	start, end, err := util.ParseTimeRange(lines[index], date)
-	index++
 	if err != nil {
-		return Record{}, err
+		return Record{}, posErr(index, indent(index), err)
 	}
+	if strict {
+		if err := checkStrictTimeRange(start, end); err != nil {
+			return Record{}, posErr(index, indent(index), err)
+		}
+	}
+	index++
 
 	pause := []Pause{}
-	for {
+	for index < len(lines) {
 		ln := strings.TrimSpace(lines[index])
 		if !strings.HasPrefix(ln, "- ") {
 			break
@@ -65,59 +154,170 @@ func DeserializeRecord(str string, date time.Time) (Record, error) {
 		ln = strings.TrimPrefix(ln, "- ")
 		lnParts := strings.SplitN(ln, "/", 2)
 		pStart, pEnd, err := util.ParseTimeRange(lnParts[0], date)
-		index++
 		if err != nil {
-			return Record{}, err
+			return Record{}, posErr(index, indent(index)+2, err)
 		}
 		note := ""
 		if len(lnParts) > 1 {
-			note = strings.TrimSpace(lnParts[1])
+			// Pause notes are single-line, with normalized whitespace as in SerializeRecord
+			note = strings.Join(strings.Fields(lnParts[1]), " ")
+		}
+		p := Pause{
+			Start: pStart,
+			End:   pEnd,
+			Note:  note,
+		}
+		if strict {
+			var prev *Pause
+			if len(pause) > 0 {
+				prev = &pause[len(pause)-1]
+			}
+			if err := checkStrictTimeRange(pStart, pEnd); err != nil {
+				return Record{}, posErr(index, indent(index)+2, err)
+			}
+			if err := checkPause(&p, prev, start, end); err != nil {
+				return Record{}, posErr(index, indent(index)+2, err)
+			}
+		}
+		pause = append(pause, p)
+		index++
+	}
+
+	var links []Link
+	for index < len(lines) {
+		ln := strings.TrimSpace(lines[index])
+		if !strings.HasPrefix(ln, LinkPrefix+" ") {
+			break
+		}
+		ln = strings.TrimSpace(strings.TrimPrefix(ln, LinkPrefix))
+		lnParts := strings.SplitN(ln, linkNoteSeparator, 2)
+		link := Link{Target: strings.TrimSpace(lnParts[0])}
+		if len(lnParts) > 1 {
+			link.Note = strings.TrimSpace(lnParts[1])
+		}
+		if err := link.Check(); err != nil {
+			return Record{}, posErr(index, indent(index), err)
 		}
-		pause = append(pause,
-			Pause{
-				Start: pStart,
-				End:   pEnd,
-				Note:  note,
-			},
-		)
+		links = append(links, link)
+		index++
 	}
 
-	index, ok = skipLines(lines, index, true)
+	category := ""
+	if index < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[index]), CategoryPrefix+" ") {
+		category = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[index]), CategoryPrefix))
+		if strict {
+			if err := CheckCategoryName(category); err != nil {
+				return Record{}, posErr(index, indent(index), err)
+			}
+		}
+		index++
+	}
+
+	locked := false
+	if index < len(lines) && strings.TrimSpace(lines[index]) == LockMarker {
+		locked = true
+		index++
+	}
+
+	index, ok = s.skipHeaderLines(lines, index)
 	if !ok {
-		return Record{}, fmt.Errorf("invalid record: missing project (2nd line)")
+		return Record{}, posErr(len(lines)-1, 1, fmt.Errorf("invalid record: missing project (2nd line)"))
 	}
 	projectName := strings.TrimSpace(lines[index])
+	if strict {
+		if err := checkProjectLine(projectName); err != nil {
+			return Record{}, posErr(index, indent(index), err)
+		}
+	}
 	index++
 
 	notes := []string{}
-	index, ok = skipLines(lines, index, true)
-	if ok {
-		for ok {
-			notes = append(notes, lines[index])
-			index++
-			index, ok = skipLines(lines, index, false)
+	tags := map[string]string{}
+	index, ok = s.skipLines(lines, index, true)
+	noteIndex := index
+	for ok {
+		line := s.unescapeNoteLine(lines[index])
+		if col, err := s.addTags(tags, line); err != nil {
+			return Record{}, posErr(index, col+len(lines[index])-len(line)+1, err)
 		}
+		notes = append(notes, line)
+		index++
+		index, ok = s.skipLines(lines, index, false)
 	}
-	tags, err := ExtractTagsSlice(notes)
-	if err != nil {
-		return Record{}, err
+	note := strings.TrimSpace(strings.Join(notes, "\n"))
+	if strict {
+		// Trimming can separate a tag from other whitespace than spaces, which changes the tags of the note
+		if noteTags, err := s.ExtractTagsSlice(strings.Split(note, "\n")); err != nil || !maps.Equal(noteTags, tags) {
+			return Record{}, posErr(noteIndex, 1, fmt.Errorf("tags at the start or end of the note must be separated by spaces"))
+		}
 	}
 
 	return Record{
-		Project: projectName,
-		Start:   start,
-		End:     end,
-		Note:    strings.TrimSpace(strings.Join(notes, "\n")),
-		Tags:    tags,
-		Pause:   pause,
+		Project:  projectName,
+		Start:    start,
+		End:      end,
+		Note:     note,
+		Tags:     tags,
+		Pause:    pause,
+		Links:    links,
+		Category: category,
+		Locked:   locked,
 	}, nil
 }
 
-func skipLines(lines []string, index int, skipEmpty bool) (int, bool) {
+// checkStrictTimeRange checks that the times of a range are whole minutes, as written by SerializeRecord,
+// and that the end is not before the start. A zero end is an open range
+func checkStrictTimeRange(start, end time.Time) error {
+	for _, t := range []time.Time{start, end} {
+		if t.Second() != 0 || t.Nanosecond() != 0 {
+			return fmt.Errorf("times must be whole minutes")
+		}
+	}
+	if !end.IsZero() && end.Before(start) {
+		return fmt.Errorf("end time is before start time")
+	}
+	return nil
+}
+
+// checkProjectLine checks the project line of a record for lines that are out of order, or for multiple words
+func checkProjectLine(line string) error {
+	switch {
+	case strings.HasPrefix(line, "- "):
+		return fmt.Errorf("pause must directly follow the time range or another pause")
+	case strings.HasPrefix(line, LinkPrefix+" "):
+		return fmt.Errorf("link must directly follow the pauses")
+	case strings.HasPrefix(line, CategoryPrefix+" "):
+		return fmt.Errorf("category must directly follow the pauses and links")
+	case line == LockMarker:
+		return fmt.Errorf("lock marker must directly follow the pauses, links and category")
+	case strings.IndexFunc(line, unicode.IsSpace) >= 0:
+		return fmt.Errorf("invalid project name '%s': must not contain whitespace", line)
+	}
+	return nil
+}
+
+// skipHeaderLines skips empty lines and comments before the note of a record.
+// Lines with the DefaultCommentPrefix are always comments here, to read files written with a different comment prefix.
+func (s *Syntax) skipHeaderLines(lines []string, index int) (int, bool) {
+	for index < len(lines) {
+		if !s.isHeaderComment(lines[index]) && strings.TrimSpace(lines[index]) != "" {
+			return index, true
+		}
+		index++
+	}
+	return index, false
+}
+
+// isHeaderComment checks if a line before the note of a record is a comment
+func (s *Syntax) isHeaderComment(line string) bool {
+	return strings.HasPrefix(line, s.CommentPrefix) || strings.HasPrefix(line, DefaultCommentPrefix)
+}
+
+func (s *Syntax) skipLines(lines []string, index int, skipEmpty bool) (int, bool) {
 	if index >= len(lines) {
 		return index, false
 	}
-	for (skipEmpty && strings.TrimSpace(lines[index]) == "") || strings.HasPrefix(lines[index], CommentPrefix) {
+	for (skipEmpty && strings.TrimSpace(lines[index]) == "") || strings.HasPrefix(lines[index], s.CommentPrefix) {
 		index++
 		if index >= len(lines) {
 			return index, false
@@ -125,3 +325,35 @@ func skipLines(lines []string, index int, skipEmpty bool) (int, bool) {
 	}
 	return index, true
 }
+
+// escapeNote escapes note lines that would otherwise be read as comments or record separators.
+// Lines with the DefaultCommentPrefix are escaped in any case, so that files stay valid when the comment prefix is changed back.
+func (s *Syntax) escapeNote(note string) string {
+	lines := strings.Split(strings.ReplaceAll(note, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, s.CommentPrefix) ||
+			strings.HasPrefix(line, DefaultCommentPrefix) ||
+			strings.HasPrefix(line, RecordSeparator) ||
+			strings.HasPrefix(line, EscapePrefix) {
+			lines[i] = EscapePrefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// unescapeNoteLine reverts escapeNote for a single line.
+// Only escapes written by escapeNote are removed, so that lines of notes
+// written before escaping was introduced keep a leading escape prefix.
+func (s *Syntax) unescapeNoteLine(line string) string {
+	escaped := strings.TrimPrefix(line, EscapePrefix)
+	if escaped == line {
+		return line
+	}
+	if strings.HasPrefix(escaped, s.CommentPrefix) ||
+		strings.HasPrefix(escaped, DefaultCommentPrefix) ||
+		strings.HasPrefix(escaped, RecordSeparator) ||
+		strings.HasPrefix(escaped, EscapePrefix) {
+		return escaped
+	}
+	return line
+}
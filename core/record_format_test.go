@@ -4,6 +4,7 @@ import (
 	"math/rand"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -77,6 +78,97 @@ func TestSerializeDeserialize(t *testing.T) {
     test
 
 Note with a +tag
+`,
+			expError: false,
+		},
+		{
+			title: "record with links",
+			time:  util.Date(2001, 2, 3),
+			record: Record{
+				Project: "test",
+				Start:   time.Date(2001, 2, 3, 8, 0, 0, 0, time.Local),
+				End:     time.Date(2001, 2, 3, 9, 0, 0, 0, time.Local),
+				Pause: []Pause{
+					{
+						Start: time.Date(2001, 2, 3, 8, 30, 0, 0, time.Local),
+						End:   time.Date(2001, 2, 3, 8, 40, 0, 0, time.Local),
+					},
+				},
+				Links: []Link{
+					{Target: "https://example.com/pull/1", Note: "Pull request"},
+					{Target: "docs/spec.pdf"},
+				},
+				Tags: make(map[string]string, 0),
+			},
+			text: `08:00 - 09:00
+    - 08:30 - 10m0s
+    @ https://example.com/pull/1 / Pull request
+    @ docs/spec.pdf
+    test
+`,
+			expError: false,
+		},
+		{
+			title: "locked record",
+			time:  util.Date(2001, 2, 3),
+			record: Record{
+				Project: "test",
+				Start:   time.Date(2001, 2, 3, 8, 0, 0, 0, time.Local),
+				End:     time.Date(2001, 2, 3, 9, 0, 0, 0, time.Local),
+				Pause:   make([]Pause, 0),
+				Note:    "Billed",
+				Tags:    make(map[string]string, 0),
+				Locked:  true,
+			},
+			text: `08:00 - 09:00
+    ! locked
+    test
+
+Billed
+`,
+			expError: false,
+		},
+		{
+			title: "record with category",
+			time:  util.Date(2001, 2, 3),
+			record: Record{
+				Project:  "test",
+				Start:    time.Date(2001, 2, 3, 8, 0, 0, 0, time.Local),
+				End:      time.Date(2001, 2, 3, 9, 0, 0, 0, time.Local),
+				Pause:    make([]Pause, 0),
+				Links:    []Link{{Target: "docs/spec.pdf"}},
+				Tags:     make(map[string]string, 0),
+				Category: "meeting",
+				Locked:   true,
+			},
+			text: `08:00 - 09:00
+    @ docs/spec.pdf
+    % meeting
+    ! locked
+    test
+`,
+			expError: false,
+		},
+		{
+			title: "multi-line note with escaped lines",
+			time:  util.Date(2001, 2, 3),
+			record: Record{
+				Project: "test",
+				Start:   time.Date(2001, 2, 3, 8, 0, 0, 0, time.Local),
+				End:     time.Date(2001, 2, 3, 9, 0, 0, 0, time.Local),
+				Pause:   make([]Pause, 0),
+				Note:    "+tag first line\n\n# not a comment\n---- not a separator\n\\ backslash\n  - indented",
+				Tags:    map[string]string{"tag": ""},
+			},
+			text: `08:00 - 09:00
+    test
+
++tag first line
+
+\# not a comment
+\---- not a separator
+\\ backslash
+  - indented
 `,
 			expError: false,
 		},
@@ -100,6 +192,346 @@ Note with a +tag
 	}
 }
 
+func TestDeserializeComments(t *testing.T) {
+	text := `# Record 2001-02-03 08:00
+08:00 - 09:00
+    test
+
+Note
+# Comment
+Second line`
+
+	record, err := DeserializeRecord(text, util.Date(2001, 2, 3))
+	assert.Nil(t, err, "Error deserializing record")
+	assert.Equal(t, "Note\nSecond line", record.Note, "Comment not removed from note")
+
+	_, err = DeserializeRecord("08:00 - 09:00", util.Date(2001, 2, 3))
+	assert.NotNil(t, err, "Expected error for missing project")
+}
+
+func TestDeserializeStrict(t *testing.T) {
+	date := util.Date(2001, 2, 3)
+	tt := []struct {
+		title  string
+		text   string
+		line   int
+		column int
+	}{
+		{
+			title:  "invalid time range",
+			text:   "# Comment\n08:00 - xx\n    test",
+			line:   2,
+			column: 1,
+		},
+		{
+			title:  "invalid pause",
+			text:   "08:00 - 09:00\n    - 08:30 - xx\n    test",
+			line:   2,
+			column: 7,
+		},
+		{
+			title:  "pause after link",
+			text:   "08:00 - 09:00\n    @ https://example.com\n    - 08:30 - 08:40\n    test",
+			line:   3,
+			column: 5,
+		},
+		{
+			title:  "category after lock marker",
+			text:   "08:00 - 09:00\n    ! locked\n    % meeting\n    test",
+			line:   3,
+			column: 5,
+		},
+		{
+			title:  "second time range",
+			text:   "\n08:00 - 09:00\n09:00 - 10:00\n    test",
+			line:   3,
+			column: 1,
+		},
+		{
+			title:  "sub-minute pause",
+			text:   "08:00 - 09:00\n    - 08:30 - 1ms\n    test",
+			line:   2,
+			column: 7,
+		},
+		{
+			title:  "pause after record",
+			text:   "08:00 - 09:00\n    - 08:30 - 09:30\n    test",
+			line:   2,
+			column: 7,
+		},
+		{
+			title:  "tag after carriage return",
+			text:   "08:00 - 09:00\n    test\n\n\r+tag",
+			line:   4,
+			column: 1,
+		},
+		{
+			title:  "conflicting tags",
+			text:   "08:00 - 09:00\n    test\n\nNote +a=1\nMore +b +a=2",
+			line:   5,
+			column: 9,
+		},
+	}
+
+	for _, test := range tt {
+		_, err := DeserializeRecordStrict(test.text, date)
+		var parseErr *ParseError
+		if assert.ErrorAs(t, err, &parseErr, "Expected a parse error in '%s'", test.title) {
+			assert.Equal(t, test.line, parseErr.Line, "Wrong line in '%s'", test.title)
+			assert.Equal(t, test.column, parseErr.Column, "Wrong column in '%s'", test.title)
+		}
+	}
+
+	// The lenient mode reads the project line as project
+	record, err := DeserializeRecord("08:00 - 09:00\n09:00 - 10:00\n    test", date)
+	assert.Nil(t, err, "Error deserializing record")
+	assert.Equal(t, "09:00 - 10:00", record.Project)
+
+	full := fullRecord()
+	full.Links = []Link{{Target: "https://example.com", Note: "Link"}}
+	full.Category = "meeting"
+	full.Locked = true
+	record, err = DeserializeRecordStrict(SerializeRecord(&full, date), date)
+	assert.Nil(t, err, "Error deserializing record")
+	assert.Equal(t, full, record, "Wrong record")
+}
+
+func TestStrictParsingOnLoad(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+
+	start := time.Date(2001, 2, 3, 8, 0, 0, 0, time.Local)
+	path := track.RecordPath(start)
+	assert.Nil(t, track.FS.MkdirAll(track.RecordDir(start), 0755))
+	assert.Nil(t, track.FS.WriteFile(path, []byte("# Record\n#!format 2\n08:00 - 09:00\n    - 08:10 - 08:20\n    @ https://example.com\n    - 08:30 - 08:40\n    test\n"), 0644))
+
+	record, err := track.LoadRecord(start)
+	assert.Nil(t, err, "Error loading record")
+	assert.Equal(t, "- 08:30 - 08:40", record.Project)
+
+	track.Config.StrictParsing = true
+	_, err = track.LoadRecord(start)
+	var parseErr *ParseError
+	assert.ErrorAs(t, err, &parseErr, "Expected a parse error")
+	assert.Equal(t, 6, parseErr.Line, "Wrong line")
+
+	track.Config.RecordFileFormat = FileFormatYAML
+	yamlStart := start.Add(2 * time.Hour)
+	assert.Nil(t, track.FS.WriteFile(track.recordFilePath(yamlStart, FileFormatYAML), []byte("project: test\nstart: 2001-02-03T10:00:00Z\nnotes: typo\n"), 0644))
+	_, err = track.LoadRecord(yamlStart)
+	assert.NotNil(t, err, "Expected an error for unknown fields")
+}
+
+// FuzzDeserializeRecord checks that deserializing arbitrary text never panics,
+// and that records read in strict mode survive a round trip.
+func FuzzDeserializeRecord(f *testing.F) {
+	date := util.Date(2001, 2, 3)
+	full := fullRecord()
+	full.Links = []Link{{Target: "https://example.com", Note: "Link"}}
+	full.Category = "meeting"
+	full.Locked = true
+	f.Add(SerializeRecord(&full, date))
+	f.Add("08:00 - ?\n    test")
+	f.Add("<23:00 - 01:00>\n    - 23:30 - 10m / Pause\n    test\n\n\\# Escaped\n# Comment\n+a=1 +b")
+	f.Add("08:00 - 09:00\n09:00 - 10:00\n    test")
+	f.Add("0:00-0:00 \n- 0:00-1ms \n0")
+	f.Add("0:00-0\n0\n\r+0")
+
+	f.Fuzz(func(t *testing.T, text string) {
+		lenient, lenientErr := DeserializeRecord(text, date)
+		record, err := DeserializeRecordStrict(text, date)
+		if err != nil {
+			return
+		}
+		assert.Nil(t, lenientErr, "Lenient mode must accept what strict mode accepts")
+		assert.Equal(t, record, lenient, "Modes must read the same record")
+
+		again, err := DeserializeRecordStrict(SerializeRecord(&record, date), date)
+		assert.Nil(t, err, "Error deserializing serialized record")
+		assert.Equal(t, record, again, "Wrong record after round trip")
+	})
+}
+
+// FuzzSerializeRecord checks that records with arbitrary notes survive a round trip
+func FuzzSerializeRecord(f *testing.F) {
+	f.Add("test", "Note with a +tag", "Pause", 60, 10)
+	f.Add("test", "# Not a comment\n\\escaped\n----\n+a=1", "", 600, 0)
+	f.Add("test", "", "Pause / with slash", 1, 1)
+
+	date := util.Date(2001, 2, 3)
+	f.Fuzz(func(t *testing.T, project, note, pauseNote string, minutes, pauseMinutes int) {
+		if fields := strings.Fields(project); len(fields) != 1 || fields[0] != project || strings.HasPrefix(project, DefaultCommentPrefix) ||
+			strings.HasPrefix(project, "-") || strings.HasPrefix(project, LinkPrefix) || strings.HasPrefix(project, EscapePrefix) {
+			t.Skip("invalid project name")
+		}
+		if minutes < 0 || minutes > 24*60 || pauseMinutes < 0 || pauseMinutes > minutes || strings.Contains(note, "\r") {
+			t.Skip("invalid times or note")
+		}
+		note = strings.TrimSpace(note)
+		tags, err := ExtractTags(strings.ReplaceAll(note, "\n", " "))
+		if err != nil {
+			t.Skip("conflicting tags")
+		}
+		pauseNote = strings.Join(strings.Fields(pauseNote), " ")
+
+		start := date.Add(8 * time.Hour)
+		record := Record{
+			Project: project,
+			Start:   start,
+			End:     start.Add(time.Duration(minutes) * time.Minute),
+			Note:    note,
+			Tags:    tags,
+			Pause:   []Pause{},
+		}
+		if pauseMinutes > 0 {
+			record.Pause = append(record.Pause, Pause{Start: start, End: start.Add(time.Duration(pauseMinutes) * time.Minute), Note: pauseNote})
+		}
+
+		again, err := DeserializeRecordStrict(SerializeRecord(&record, date), date)
+		assert.Nil(t, err, "Error deserializing serialized record")
+		assert.Equal(t, record.Note, again.Note, "Wrong note after round trip")
+		assert.Equal(t, record.Tags, again.Tags, "Wrong tags after round trip")
+		assert.Equal(t, record.Pause, again.Pause, "Wrong pauses after round trip")
+		assert.Equal(t, record.End, again.End, "Wrong end after round trip")
+	})
+}
+
+func TestCustomPrefixes(t *testing.T) {
+	assert.Nil(t, CheckPrefixes("#", "//"))
+	assert.NotNil(t, CheckPrefixes("#", "#"))
+	assert.NotNil(t, CheckPrefixes("", "#"))
+	assert.NotNil(t, CheckPrefixes("+", "-"))
+
+	syntax := &Syntax{TagPrefix: "#", CommentPrefix: "//"}
+	text := `# Record 2001-02-03 08:00
+#!format 2
+// Edited record
+08:00 - 09:00
+    test
+
+#meeting with #team=dev
+// Comment
+Second line +no-tag`
+
+	record, err := syntax.DeserializeRecord(text, util.Date(2001, 2, 3))
+	assert.Nil(t, err, "Error deserializing record")
+	assert.Equal(t, "#meeting with #team=dev\nSecond line +no-tag", record.Note, "Wrong note")
+	assert.Equal(t, map[string]string{"meeting": "", "team": "dev"}, record.Tags, "Wrong tags")
+
+	serialized := syntax.SerializeRecord(&record, util.Date(2001, 2, 3))
+	assert.Contains(t, serialized, "\\#meeting", "Expected escaped note line")
+	outRecord, err := syntax.DeserializeRecord(serialized, util.Date(2001, 2, 3))
+	assert.Nil(t, err, "Error deserializing record")
+	assert.Equal(t, record, outRecord, "Wrong round trip with custom prefixes")
+
+	// Files written with custom prefixes stay valid with the default prefixes
+	outRecord, err = DeserializeRecord(serialized, util.Date(2001, 2, 3))
+	assert.Nil(t, err, "Error deserializing record")
+	assert.Equal(t, record.Note, outRecord.Note, "Wrong note with default prefixes")
+	assert.Equal(t, map[string]string{"no-tag": ""}, outRecord.Tags, "Wrong tags with default prefixes")
+}
+
+func TestUnescapeUnescapedNote(t *testing.T) {
+	text := `08:00 - 09:00
+    test
+
+C:\Users\me
+\# escaped comment
+\\ escaped backslash
+`
+	record, err := DeserializeRecordStrict(text, util.Date(2001, 2, 3))
+	assert.Nil(t, err, "Error deserializing record")
+	assert.Equal(t, "C:\\Users\\me\n# escaped comment\n\\ escaped backslash", record.Note)
+
+	legacy := strings.Replace(text, "C:", "\\server", 1)
+	record, err = DeserializeRecord(legacy, util.Date(2001, 2, 3))
+	assert.Nil(t, err, "Error deserializing record")
+	assert.True(t, strings.HasPrefix(record.Note, "\\server\\Users"), "Unescaped backslash should be kept, got %q", record.Note)
+
+	again, err := DeserializeRecord(SerializeRecord(&record, util.Date(2001, 2, 3)), util.Date(2001, 2, 3))
+	assert.Nil(t, err, "Error deserializing record")
+	assert.Equal(t, record.Note, again.Note, "Wrong note after round trip")
+}
+
+func TestPrefixesPerTrack(t *testing.T) {
+	fsys := NewMemoryFileSystem()
+	custom, err := NewTrackWithFS(memoryRootDir, fsys)
+	assert.Nil(t, err, "Error creating Track instance")
+	custom.Config.TagPrefix, custom.Config.CommentPrefix = "#", "//"
+	assert.Nil(t, custom.SaveConfig(), "Error saving config")
+	custom, err = NewTrackWithFS(memoryRootDir, fsys)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	def, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+
+	start := time.Date(2023, 5, 4, 9, 0, 0, 0, time.Local)
+	var wg sync.WaitGroup
+	for _, track := range []*Track{&custom, &def} {
+		wg.Add(1)
+		go func(track *Track) {
+			defer wg.Done()
+			rec := Record{Project: "test", Start: start, End: start.Add(time.Hour), Note: "+a #b"}
+			tags, err := track.Syntax().ExtractTags(rec.Note)
+			assert.Nil(t, err)
+			rec.Tags = tags
+			assert.Nil(t, track.SaveRecord(&rec, false), "Error saving record")
+		}(track)
+	}
+	wg.Wait()
+
+	loaded, err := custom.LoadRecord(start)
+	assert.Nil(t, err, "Error loading record")
+	assert.Equal(t, map[string]string{"b": ""}, loaded.Tags, "Wrong tags with custom prefixes")
+	loaded, err = def.LoadRecord(start)
+	assert.Nil(t, err, "Error loading record")
+	assert.Equal(t, map[string]string{"a": ""}, loaded.Tags, "Wrong tags with default prefixes")
+}
+
+func TestTagPrefixChange(t *testing.T) {
+	fsys := NewMemoryFileSystem()
+	track, err := NewTrackWithFS(memoryRootDir, fsys)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	// Without records, the prefix can be changed
+	assert.Nil(t, track.CheckTagPrefix("@"))
+	track.Config.TagPrefix = "@"
+	assert.Nil(t, track.SaveConfig(), "Error saving config")
+	track, err = NewTrackWithFS(memoryRootDir, fsys)
+	assert.Nil(t, err, "Error creating Track instance with changed prefix")
+
+	start := time.Date(2023, 5, 4, 9, 0, 0, 0, time.Local)
+	rec := Record{Project: "test", Start: start, End: start.Add(time.Hour), Note: "@a", Tags: map[string]string{"a": ""}}
+	assert.Nil(t, track.SaveRecord(&rec, false), "Error saving record")
+
+	assert.Nil(t, track.CheckTagPrefix("@"))
+	assert.ErrorIs(t, track.CheckTagPrefix("+"), ErrTagPrefixChanged)
+
+	track.Config.TagPrefix = "+"
+	assert.Nil(t, track.SaveConfig(), "Error saving config")
+	_, err = NewTrackWithFS(memoryRootDir, fsys)
+	assert.ErrorIs(t, err, ErrTagPrefixChanged, "Expected error for changed prefix with existing records")
+}
+
+func TestSerializePauseNote(t *testing.T) {
+	record := Record{
+		Project: "test",
+		Start:   time.Date(2001, 2, 3, 8, 0, 0, 0, time.Local),
+		End:     time.Date(2001, 2, 3, 9, 0, 0, 0, time.Local),
+		Pause: []Pause{
+			{
+				Start: time.Date(2001, 2, 3, 8, 30, 0, 0, time.Local),
+				End:   time.Date(2001, 2, 3, 8, 40, 0, 0, time.Local),
+				Note:  "Multi-line\npause note",
+			},
+		},
+	}
+	text := SerializeRecord(&record, util.Date(2001, 2, 3))
+	outRecord, err := DeserializeRecord(text, util.Date(2001, 2, 3))
+	assert.Nil(t, err, "Error deserializing record")
+	assert.Equal(t, "Multi-line pause note", outRecord.Pause[0].Note, "Wrong pause note")
+}
+
 func BenchmarkSerialize(b *testing.B) {
 	record := fullRecord()
 	for i := 0; i < b.N; i++ {
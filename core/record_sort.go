@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// SortField is a field to sort records by
+type SortField string
+
+// Sort fields
+const (
+	// SortByStart sorts records by start time
+	SortByStart SortField = "start"
+	// SortByEnd sorts records by end time. Running records are considered to end now
+	SortByEnd SortField = "end"
+	// SortByDuration sorts records by duration, excluding pauses
+	SortByDuration SortField = "duration"
+	// SortByProject sorts records by project name
+	SortByProject SortField = "project"
+)
+
+// SortFields are all sort fields
+var SortFields = []SortField{SortByStart, SortByEnd, SortByDuration, SortByProject}
+
+// ParseSortField parses a sort field
+func ParseSortField(text string) (SortField, error) {
+	for _, f := range SortFields {
+		if string(f) == strings.ToLower(text) {
+			return f, nil
+		}
+	}
+	names := make([]string, len(SortFields))
+	for i, f := range SortFields {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown sort field '%s'. Must be one of (%s)", text, strings.Join(names, "|"))
+}
+
+// SortOrder determines the order of loaded records.
+//
+// The zero value sorts by start time, ascending.
+// Records with equal values of the sort field are sorted by start time, ascending.
+type SortOrder struct {
+	// Field to sort by. Sorts by start time if empty
+	Field SortField
+	// Sort in descending order
+	Descending bool
+}
+
+// SortRecords sorts records in the given order.
+func SortRecords(records []Record, order SortOrder) {
+	now := time.Now()
+	var less func(a, b *Record) bool
+	switch order.Field {
+	case SortByEnd:
+		end := func(r *Record) time.Time {
+			if r.End.IsZero() {
+				return now
+			}
+			return r.End
+		}
+		less = func(a, b *Record) bool { return end(a).Before(end(b)) }
+	case SortByDuration:
+		less = func(a, b *Record) bool {
+			return a.Duration(util.NoTime, now) < b.Duration(util.NoTime, now)
+		}
+	case SortByProject:
+		less = func(a, b *Record) bool { return a.Project < b.Project }
+	default:
+		less = func(a, b *Record) bool { return a.Start.Before(b.Start) }
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := &records[i], &records[j]
+		if order.Descending {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return records[i].Start.Before(records[j].Start)
+	})
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortRecords(t *testing.T) {
+	records := []Record{
+		{Project: "b", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 10, 0, 0)},
+		{Project: "a", Start: util.DateTime(2001, 2, 3, 11, 0, 0), End: util.DateTime(2001, 2, 3, 12, 0, 0)},
+		{Project: "b", Start: util.DateTime(2001, 2, 3, 12, 0, 0), End: util.DateTime(2001, 2, 3, 15, 0, 0),
+			Pause: []Pause{{Start: util.DateTime(2001, 2, 3, 13, 0, 0), End: util.DateTime(2001, 2, 3, 14, 30, 0)}}},
+		{Project: "a", Start: util.DateTime(2001, 2, 3, 7, 0, 0), End: util.DateTime(2001, 2, 3, 7, 30, 0)},
+	}
+	starts := func(records []Record) []int {
+		hours := make([]int, len(records))
+		for i, r := range records {
+			hours[i] = r.Start.Hour()
+		}
+		return hours
+	}
+
+	tt := []struct {
+		order    SortOrder
+		expected []int
+	}{
+		{SortOrder{}, []int{7, 8, 11, 12}},
+		{SortOrder{Descending: true}, []int{12, 11, 8, 7}},
+		{SortOrder{Field: SortByEnd}, []int{7, 8, 11, 12}},
+		{SortOrder{Field: SortByDuration}, []int{7, 11, 12, 8}},
+		{SortOrder{Field: SortByDuration, Descending: true}, []int{8, 12, 11, 7}},
+		{SortOrder{Field: SortByProject}, []int{7, 11, 8, 12}},
+		{SortOrder{Field: SortByProject, Descending: true}, []int{8, 12, 7, 11}},
+	}
+	for _, test := range tt {
+		SortRecords(records, test.order)
+		assert.Equal(t, test.expected, starts(records), "Wrong order for %v", test.order)
+	}
+
+	field, err := ParseSortField("Duration")
+	assert.Nil(t, err)
+	assert.Equal(t, SortByDuration, field)
+	_, err = ParseSortField("foo")
+	assert.NotNil(t, err)
+}
+
+func TestLoadAllRecordsSorted(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err)
+	assert.Nil(t, track.SaveProject(NewProject("test", "", "T", []string{}, 0, 15), false))
+
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 2, 8, 0, 0), End: util.DateTime(2001, 2, 2, 9, 0, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 11, 0, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 4, 8, 0, 0), End: util.DateTime(2001, 2, 4, 10, 0, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false))
+	}
+
+	filters := NewFilter([]FilterFunction{}, util.NoTime, util.NoTime)
+	loaded, err := track.LoadAllRecordsFiltered(filters)
+	assert.Nil(t, err)
+	assert.Equal(t, []time.Time{records[0].Start, records[1].Start, records[2].Start},
+		[]time.Time{loaded[0].Start, loaded[1].Start, loaded[2].Start})
+
+	filters.Sort = SortOrder{Field: SortByDuration, Descending: true}
+	loaded, err = track.LoadAllRecordsFiltered(filters)
+	assert.Nil(t, err)
+	assert.Equal(t, []time.Time{records[1].Start, records[2].Start, records[0].Start},
+		[]time.Time{loaded[0].Start, loaded[1].Start, loaded[2].Start})
+
+	reporter, err := NewReporter(&track, []string{}, filters, false, util.NoTime, util.NoTime)
+	assert.Nil(t, err)
+	assert.Equal(t, loaded, reporter.Records)
+}
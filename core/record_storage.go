@@ -0,0 +1,380 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"gopkg.in/yaml.v3"
+)
+
+// Record file formats
+const (
+	// FileFormatText stores each record in a file in track's own text format
+	FileFormatText = "text"
+	// FileFormatYAML stores each record in a YAML file
+	FileFormatYAML = "yaml"
+	// FileFormatJSON stores the records of a day in a JSON Lines file, with one record per line
+	FileFormatJSON = "json"
+)
+
+const (
+	textRecordExt = ".trk"
+	yamlRecordExt = ".yml"
+	jsonDayFile   = "records.jsonl"
+	tempFileExt   = ".tmp"
+)
+
+// recordFileFormat detects the format of a file in a day directory from its name.
+// Returns an empty string for files that are not record files, including conflicting copies from file sync tools.
+func recordFileFormat(name string) string {
+	if _, ok := conflictOriginal(name); ok {
+		return ""
+	}
+	switch {
+	case name == jsonDayFile:
+		return FileFormatJSON
+	case name == checksumFile:
+		return ""
+	case strings.HasSuffix(name, yamlRecordExt):
+		return FileFormatYAML
+	case strings.HasSuffix(name, tempFileExt):
+		return ""
+	default:
+		return FileFormatText
+	}
+}
+
+// recordFilePath returns the path of the file for a record in the given format.
+// For format FileFormatJSON, this is the file of the record's day.
+func (t *Track) recordFilePath(tm time.Time, format string) string {
+	switch format {
+	case FileFormatYAML:
+		return filepath.Join(t.RecordDir(tm), tm.Format(util.FileTimeFormat)+yamlRecordExt)
+	case FileFormatJSON:
+		return filepath.Join(t.RecordDir(tm), jsonDayFile)
+	default:
+		return t.RecordPath(tm)
+	}
+}
+
+// readRecordFile reads a record from a single-record file in text or YAML format
+func (t *Track) readRecordFile(path string, format string, tm time.Time) (Record, error) {
+	file, err := t.readRecordData(path)
+	if err != nil {
+		return Record{}, err
+	}
+	if format == FileFormatYAML {
+		var record Record
+		decoder := yaml.NewDecoder(bytes.NewReader(file))
+		decoder.KnownFields(t.Config.StrictParsing)
+		if err := decoder.Decode(&record); err != nil {
+			return Record{}, err
+		}
+		record.toLocal()
+		if record.Tags, err = t.Config.TagNormalization.NormalizeTags(record.Tags); err != nil {
+			return Record{}, err
+		}
+		return record, nil
+	}
+
+	content, version, err := t.Syntax().migrateRecordContent(string(file))
+	if err != nil {
+		return Record{}, err
+	}
+	if t.Config.StrictParsing {
+		if version == RecordFormatVersion {
+			// Parse the original content for correct line numbers; the format header is a comment
+			content = string(file)
+		}
+		return t.Syntax().DeserializeRecordStrict(content, tm)
+	}
+	return t.Syntax().DeserializeRecord(content, tm)
+}
+
+// readJSONDay reads all records from the JSON Lines file of a day.
+// Returns an empty slice if the file does not exist.
+func (t *Track) readJSONDay(date time.Time) ([]Record, error) {
+	file, err := t.readRecordData(t.recordFilePath(date, FileFormatJSON))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Record{}, nil
+		}
+		return nil, err
+	}
+	records := []Record{}
+	scanner := bufio.NewScanner(bytes.NewReader(file))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var record Record
+		if t.Config.StrictParsing {
+			decoder := json.NewDecoder(bytes.NewReader(line))
+			decoder.DisallowUnknownFields()
+			err = decoder.Decode(&record)
+			if err == nil && decoder.InputOffset() != int64(len(line)) {
+				err = fmt.Errorf("unexpected data after record")
+			}
+		} else {
+			err = json.Unmarshal(line, &record)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid record in %s, line %d: %s", jsonDayFile, lineNumber, err)
+		}
+		record.toLocal()
+		if record.Tags, err = t.Config.TagNormalization.NormalizeTags(record.Tags); err != nil {
+			return nil, fmt.Errorf("invalid record in %s: %s", jsonDayFile, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// writeJSONDay writes the records of a day to its JSON Lines file, sorted by start time.
+// Removes the file if there are no records.
+func (t *Track) writeJSONDay(date time.Time, records []Record) error {
+	path := t.recordFilePath(date, FileFormatJSON)
+	if len(records) == 0 {
+		err := t.removeRecordData(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Start.Before(records[j].Start) })
+
+	buf := bytes.Buffer{}
+	for i := range records {
+		line, err := json.Marshal(&records[i])
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteString("\n")
+	}
+	return t.writeRecordData(path, buf.Bytes())
+}
+
+// readDayRecords reads all records of a day directory, in all formats, sorted by start time.
+// Fails with a *RecordFileError if any record file can't be read.
+func (t *Track) readDayRecords(date time.Time) ([]Record, error) {
+	records, fileErrs, err := t.readDayRecordsLenient(context.Background(), date)
+	if err != nil {
+		return nil, err
+	}
+	if len(fileErrs) > 0 {
+		return nil, fileErrs[0]
+	}
+	return records, nil
+}
+
+// readDayRecordsLenient reads all records of a day directory, like readDayRecords.
+// Files that can't be read are skipped, and their errors are returned as *RecordFileError.
+func (t *Track) readDayRecordsLenient(ctx context.Context, date time.Time) ([]Record, []error, error) {
+	_, span := t.startSpan(ctx, "track.readDay")
+	defer span.End()
+	span.SetAttribute("date", date.Format(util.DateFormat))
+
+	subPath := t.RecordDir(date)
+
+	info, err := t.FileSystem().Stat(subPath)
+	if err != nil {
+		return nil, nil, ErrNoRecords
+	}
+	if !info.IsDir() {
+		return nil, nil, fmt.Errorf("'%s' is not a directory", info.Name())
+	}
+
+	files, err := t.FileSystem().ReadDir(subPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log := t.logger()
+	records := make([]Record, 0, len(files))
+	fileErrs := []error{}
+	addErr := func(path string, err error) {
+		log.Debug("failed to read record file", "path", path, "err", err)
+		fileErrs = append(fileErrs, &RecordFileError{Path: path, Date: date, Err: err})
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		path := filepath.Join(subPath, file.Name())
+		format := recordFileFormat(file.Name())
+		switch format {
+		case "":
+			log.Debug("skipping file", "path", path)
+			continue
+		case FileFormatJSON:
+			dayRecords, err := t.readJSONDay(date)
+			if err != nil {
+				addErr(path, err)
+				continue
+			}
+			log.Debug("read record file", "path", path, "format", format, "records", len(dayRecords))
+			records = append(records, dayRecords...)
+		default:
+			tm, err := fileToTime(date, file.Name())
+			if err != nil {
+				addErr(path, err)
+				continue
+			}
+			record, err := t.readRecordFile(path, format, tm)
+			if err != nil {
+				addErr(path, err)
+				continue
+			}
+			log.Debug("read record file", "path", path, "format", format, "records", 1)
+			records = append(records, record)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Start.Before(records[j].Start) })
+
+	span.SetAttribute("files", len(files))
+	span.SetAttribute("records", len(records))
+	span.SetAttribute("errors", len(fileErrs))
+	return records, fileErrs, nil
+}
+
+// findRecordFile finds the format of the file that contains the record starting at the given time.
+// Returns an empty string if there is no such record.
+func (t *Track) findRecordFile(tm time.Time) (string, error) {
+	for _, format := range []string{FileFormatText, FileFormatYAML} {
+		if t.fileExists(t.recordFilePath(tm, format)) {
+			return format, nil
+		}
+	}
+	records, err := t.readJSONDay(tm)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range records {
+		if r.Start.Equal(tm) {
+			return FileFormatJSON, nil
+		}
+	}
+	return "", nil
+}
+
+// loadRecordAt loads the record starting at the given time, from a file in any format
+func (t *Track) loadRecordAt(tm time.Time) (Record, error) {
+	format, err := t.findRecordFile(tm)
+	if err != nil {
+		return Record{}, err
+	}
+	switch format {
+	case "":
+		return Record{}, ErrRecordNotFound
+	case FileFormatJSON:
+		records, err := t.readJSONDay(tm)
+		if err != nil {
+			return Record{}, &RecordFileError{Path: t.recordFilePath(tm, format), Date: util.ToDate(tm), Err: err}
+		}
+		for _, r := range records {
+			if r.Start.Equal(tm) {
+				return r, nil
+			}
+		}
+		return Record{}, ErrRecordNotFound
+	default:
+		path := t.recordFilePath(tm, format)
+		record, err := t.readRecordFile(path, format, tm)
+		if err != nil {
+			return Record{}, &RecordFileError{Path: path, Date: util.ToDate(tm), Err: err}
+		}
+		return record, nil
+	}
+}
+
+// removeRecordAt removes the record starting at the given time from the file of the given format
+func (t *Track) removeRecordAt(tm time.Time, format string) error {
+	if format != FileFormatJSON {
+		return t.removeRecordData(t.recordFilePath(tm, format))
+	}
+	records, err := t.readJSONDay(tm)
+	if err != nil {
+		return err
+	}
+	remaining := make([]Record, 0, len(records))
+	for _, r := range records {
+		if !r.Start.Equal(tm) {
+			remaining = append(remaining, r)
+		}
+	}
+	return t.writeJSONDay(tm, remaining)
+}
+
+// writeRecord writes a record to a file in the given format
+func (t *Track) writeRecord(record *Record, format string) error {
+	switch format {
+	case FileFormatJSON:
+		records, err := t.readJSONDay(record.Start)
+		if err != nil {
+			return err
+		}
+		replaced := false
+		for i := range records {
+			if records[i].Start.Equal(record.Start) {
+				records[i] = *record
+				replaced = true
+			}
+		}
+		if !replaced {
+			records = append(records, *record)
+		}
+		return t.writeJSONDay(record.Start, records)
+	case FileFormatYAML:
+		data, err := yaml.Marshal(record)
+		if err != nil {
+			return err
+		}
+		content := fmt.Sprintf("%s Record %s\n\n%s", YamlCommentPrefix, record.Start.Format(util.DateTimeFormat), data)
+		return t.writeRecordData(t.recordFilePath(record.Start, format), []byte(content))
+	default:
+		content := fmt.Sprintf(
+			"%s Record %s\n%s%d\n%s",
+			DefaultCommentPrefix, record.Start.Format(util.DateTimeFormat),
+			FormatHeaderPrefix, RecordFormatVersion,
+			t.Syntax().SerializeRecord(record, util.NoTime),
+		)
+		return t.writeRecordData(t.recordFilePath(record.Start, format), []byte(content))
+	}
+}
+
+// toLocal converts all times of a record to the local time zone
+func (r *Record) toLocal() {
+	r.Start = r.Start.Local()
+	if !r.End.IsZero() {
+		r.End = r.End.Local()
+	}
+	for i := range r.Pause {
+		r.Pause[i].Start = r.Pause[i].Start.Local()
+		if !r.Pause[i].End.IsZero() {
+			r.Pause[i].End = r.Pause[i].End.Local()
+		}
+	}
+	if r.Tags == nil {
+		r.Tags = map[string]string{}
+	}
+	if r.Pause == nil {
+		r.Pause = []Pause{}
+	}
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordFileFormats(t *testing.T) {
+	for _, format := range []string{FileFormatText, FileFormatYAML, FileFormatJSON} {
+		dir, err := os.MkdirTemp("", "track-test")
+		assert.Nil(t, err, "Error creating temporary directory")
+		defer os.RemoveAll(dir)
+
+		track, err := NewTrack(&dir)
+		assert.Nil(t, err, "Error creating Track instance")
+		track.Config.RecordFileFormat = format
+
+		records := []Record{
+			{
+				Project: "test",
+				Start:   util.DateTime(2001, 2, 3, 8, 0, 0),
+				End:     util.DateTime(2001, 2, 3, 12, 0, 0),
+				Note:    "Note with +tag",
+				Tags:    map[string]string{"tag": ""},
+				Pause:   []Pause{{Start: util.DateTime(2001, 2, 3, 10, 0, 0), End: util.DateTime(2001, 2, 3, 10, 15, 0), Note: "Coffee"}},
+				Links:   []Link{{Target: "https://example.com", Note: "Example"}},
+			},
+			{
+				Project: "test",
+				Start:   util.DateTime(2001, 2, 3, 13, 0, 0),
+				Tags:    map[string]string{},
+				Pause:   []Pause{},
+			},
+		}
+		for i := range records {
+			assert.Nil(t, track.SaveRecord(&records[i], false), "Error saving record in format %s", format)
+		}
+		assert.NotNil(t, track.SaveRecord(&records[0], false), "Expected error for existing record in format %s", format)
+
+		loaded, err := track.LoadDateRecords(util.Date(2001, 2, 3))
+		assert.Nil(t, err, "Error loading records in format %s", format)
+		assert.Equal(t, 2, len(loaded))
+		for i := range records {
+			assert.Equal(t, records[i].Start, loaded[i].Start)
+			assert.True(t, records[i].End.Equal(loaded[i].End))
+			assert.Equal(t, records[i].Note, loaded[i].Note)
+			assert.Equal(t, records[i].Tags, loaded[i].Tags)
+			assert.Equal(t, records[i].Links, loaded[i].Links)
+			assert.Equal(t, records[i].Duration(util.NoTime, util.Date(2001, 2, 4)), loaded[i].Duration(util.NoTime, util.Date(2001, 2, 4)))
+		}
+
+		open, err := track.OpenRecord()
+		assert.Nil(t, err, "Error loading open record in format %s", format)
+		assert.NotNil(t, open)
+		assert.Equal(t, records[1].Start, open.Start)
+
+		assert.Nil(t, track.DeleteRecord(&records[1]), "Error deleting record in format %s", format)
+		_, err = track.LoadRecord(records[1].Start)
+		assert.ErrorIs(t, err, ErrRecordNotFound)
+
+		assert.Nil(t, track.DeleteRecord(&records[0]), "Error deleting record in format %s", format)
+		assert.False(t, util.FileExists(track.RecordDir(records[0].Start)))
+	}
+}
+
+func TestRecordFileFormatConversion(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	start := util.DateTime(2001, 2, 3, 8, 0, 0)
+	record := Record{Project: "test", Start: start, End: start.Add(time.Hour)}
+	assert.Nil(t, track.SaveRecord(&record, false))
+	other := Record{Project: "test", Start: start.Add(2 * time.Hour), End: start.Add(3 * time.Hour)}
+	assert.Nil(t, track.SaveRecord(&other, false))
+
+	track.Config.RecordFileFormat = FileFormatJSON
+	record.End = start.Add(30 * time.Minute)
+	assert.Nil(t, track.SaveRecord(&record, true))
+
+	assert.False(t, util.FileExists(track.RecordPath(start)))
+	assert.True(t, util.FileExists(filepath.Join(track.RecordDir(start), jsonDayFile)))
+
+	loaded, err := track.LoadDateRecords(start)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(loaded))
+	assert.Equal(t, 30*time.Minute, loaded[0].Duration(util.NoTime, util.NoTime))
+	assert.Equal(t, other.Start, loaded[1].Start)
+}
@@ -293,6 +293,11 @@ func TestExtractTags(t *testing.T) {
 			note:    "Note with +two=foo +tags= in it",
 			expTags: map[string]string{"two": "foo", "tags": ""},
 		},
+		{
+			title:   "plus signs without tag name",
+			note:    "Note with 1 + 1 and +=foo",
+			expTags: map[string]string{},
+		},
 	}
 
 	for _, test := range tt {
@@ -362,3 +367,83 @@ func BenchmarkExtractTagsSlice(b *testing.B) {
 		_, _ = ExtractTagsSlice(text)
 	}
 }
+
+func TestAddLink(t *testing.T) {
+	record := Record{}
+
+	err := record.AddLink("https://example.com/pull/1", "PR")
+	assert.Nil(t, err, "Error adding link")
+	err = record.AddLink(" https://example.com/pull/1 ", "")
+	assert.Nil(t, err, "Error adding duplicate link")
+	err = record.AddLink("docs/spec.pdf", "")
+	assert.Nil(t, err, "Error adding link")
+
+	assert.Equal(t, []Link{
+		{Target: "https://example.com/pull/1", Note: "PR"},
+		{Target: "docs/spec.pdf"},
+	}, record.Links, "Wrong links")
+
+	assert.NotNil(t, record.AddLink("", ""), "Expected error for empty target")
+	assert.NotNil(t, record.AddLink("a / b", ""), "Expected error for separator in target")
+	assert.NotNil(t, record.AddLink("a", "b\nc"), "Expected error for multi-line note")
+}
+
+func TestRecordSplitAtMidnight(t *testing.T) {
+	record := Record{
+		Project: "test",
+		Note:    "Note +tag",
+		Tags:    map[string]string{"tag": ""},
+		Start:   util.DateTime(2001, 2, 3, 20, 0, 0),
+		End:     util.DateTime(2001, 2, 5, 2, 0, 0),
+		Pause: []Pause{
+			{Start: util.DateTime(2001, 2, 3, 21, 0, 0), End: util.DateTime(2001, 2, 3, 22, 0, 0)},
+			{Start: util.DateTime(2001, 2, 4, 23, 0, 0), End: util.DateTime(2001, 2, 5, 1, 0, 0)},
+		},
+	}
+
+	parts := record.SplitAtMidnight()
+	assert.Equal(t, 3, len(parts))
+
+	assert.Equal(t, record.Start, parts[0].Start)
+	assert.Equal(t, util.Date(2001, 2, 4), parts[0].End)
+	assert.Equal(t, 1, len(parts[0].Pause))
+
+	assert.Equal(t, util.Date(2001, 2, 4), parts[1].Start)
+	assert.Equal(t, util.Date(2001, 2, 5), parts[1].End)
+	assert.Equal(t, []Pause{{Start: util.DateTime(2001, 2, 4, 23, 0, 0), End: util.Date(2001, 2, 5)}}, parts[1].Pause)
+
+	assert.Equal(t, util.Date(2001, 2, 5), parts[2].Start)
+	assert.Equal(t, record.End, parts[2].End)
+	assert.Equal(t, []Pause{{Start: util.Date(2001, 2, 5), End: util.DateTime(2001, 2, 5, 1, 0, 0)}}, parts[2].Pause)
+
+	project := NewProject("test", "", "t", []string{}, 15, 0)
+	total := time.Duration(0)
+	for i := range parts {
+		assert.Nil(t, parts[i].Check(&project))
+		assert.Equal(t, record.Note, parts[i].Note)
+		assert.Equal(t, record.Tags, parts[i].Tags)
+		total += parts[i].Duration(util.NoTime, util.NoTime)
+	}
+	assert.Equal(t, record.Duration(util.NoTime, util.NoTime), total)
+
+	parts[0].Tags["other"] = ""
+	assert.Equal(t, 1, len(record.Tags))
+
+	running := Record{Project: "test", Start: util.DateTime(2001, 2, 3, 20, 0, 0)}
+	assert.Equal(t, []Record{running}, running.SplitAtMidnight())
+}
+
+func TestCheckCategory(t *testing.T) {
+	conf := defaultConfig()
+	assert.Nil(t, conf.CheckCategory(""))
+	assert.Nil(t, conf.CheckCategory("meeting"))
+	assert.NotNil(t, conf.CheckCategory("unknown"))
+	assert.NotNil(t, conf.CheckCategory("two words"))
+
+	conf.Categories = nil
+	assert.Nil(t, conf.CheckCategory("unknown"))
+	assert.NotNil(t, conf.CheckCategory("a,b"))
+
+	conf.Categories = []string{"work", "work"}
+	assert.NotNil(t, conf.Check(), "Expected error for duplicate category")
+}
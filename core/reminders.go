@@ -0,0 +1,84 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// ReminderOff disables a Reminder for a weekday
+const ReminderOff = "off"
+
+// Reminder is a time of day for a daemon reminder, with overrides per weekday
+type Reminder struct {
+	// Time of day, like "18:30". Empty to disable, except for weekdays with an override
+	Time string `yaml:"time"`
+	// Time of day per weekday, like "friday: 16:00". Overrides Time. ReminderOff disables the reminder on that weekday
+	Weekdays map[string]string `yaml:"weekdays,omitempty"`
+}
+
+// IsEnabled checks if the reminder is enabled for any weekday
+func (r *Reminder) IsEnabled() bool {
+	if r.Time != "" {
+		return true
+	}
+	for _, tm := range r.Weekdays {
+		if tm != "" && tm != ReminderOff {
+			return true
+		}
+	}
+	return false
+}
+
+// At returns the time of the reminder on the day of the given date.
+// Returns false if the reminder is disabled on that day, or has an invalid time.
+func (r *Reminder) At(date time.Time) (time.Time, bool) {
+	text := r.Time
+	for name, tm := range r.Weekdays {
+		day, err := util.ParseWeekday(name)
+		if err != nil || day != date.Weekday() {
+			continue
+		}
+		text = tm
+		break
+	}
+	if text == "" || text == ReminderOff {
+		return time.Time{}, false
+	}
+	tm, err := parseTimeOfDay(text, date)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return tm, true
+}
+
+// Check checks the reminder's times and weekdays
+func (r *Reminder) Check() error {
+	if r.Time != "" {
+		if _, err := parseTimeOfDay(r.Time, util.ToDate(time.Now())); err != nil {
+			return err
+		}
+	}
+	for name, tm := range r.Weekdays {
+		if _, err := util.ParseWeekday(name); err != nil {
+			return err
+		}
+		if tm == "" || tm == ReminderOff {
+			continue
+		}
+		if _, err := parseTimeOfDay(tm, util.ToDate(time.Now())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTimeOfDay parses a time of day like "18:30", on the day of the given date
+func parseTimeOfDay(text string, date time.Time) (time.Time, error) {
+	tm, err := time.ParseInLocation(util.TimeFormat, text, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time of day '%s'", text)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), tm.Hour(), tm.Minute(), 0, 0, time.Local), nil
+}
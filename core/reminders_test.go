@@ -0,0 +1,40 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReminder(t *testing.T) {
+	r := Reminder{
+		Time: "18:30",
+		Weekdays: map[string]string{
+			"fr":     "16:00",
+			"sunday": ReminderOff,
+		},
+	}
+	assert.Nil(t, r.Check())
+	assert.True(t, r.IsEnabled())
+
+	at, ok := r.At(time.Date(2023, 5, 4, 12, 0, 0, 0, time.Local))
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2023, 5, 4, 18, 30, 0, 0, time.Local), at)
+
+	at, ok = r.At(time.Date(2023, 5, 5, 0, 0, 0, 0, time.Local))
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2023, 5, 5, 16, 0, 0, 0, time.Local), at)
+
+	_, ok = r.At(time.Date(2023, 5, 7, 0, 0, 0, 0, time.Local))
+	assert.False(t, ok)
+
+	r = Reminder{Weekdays: map[string]string{"monday": "08:00"}}
+	assert.True(t, r.IsEnabled())
+	_, ok = r.At(time.Date(2023, 5, 4, 0, 0, 0, 0, time.Local))
+	assert.False(t, ok)
+
+	assert.False(t, (&Reminder{}).IsEnabled())
+	assert.NotNil(t, (&Reminder{Time: "25:00"}).Check())
+	assert.NotNil(t, (&Reminder{Weekdays: map[string]string{"foo": "08:00"}}).Check())
+}
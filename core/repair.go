@@ -0,0 +1,158 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mlange-42/track/util"
+)
+
+// RecordRepair is a repaired record, with a description of each change
+type RecordRepair struct {
+	// The repaired record
+	Record Record
+	// Descriptions of the changes
+	Changes []string
+}
+
+// RepairResult summarizes the changes of repairing records
+type RepairResult struct {
+	// Number of checked records
+	Checked int
+	// Repaired records
+	Repairs []RecordRepair
+	// Locked records that need repairs, but were left unchanged
+	Locked int
+}
+
+// RepairPauses fixes the pause problems detected by Record.Check:
+//
+//   - Pauses that end before their start get start and end swapped.
+//   - Pauses are sorted by their start.
+//   - Pauses starting before or ending after the record are clamped to the record.
+//     Open pauses of a finished record end with the record.
+//   - Overlapping pauses are merged, and their notes are joined.
+//   - Pauses that are empty after clamping are removed.
+//
+// Returns a description of each change, or no changes if the pauses are consistent.
+func (r *Record) RepairPauses() []string {
+	changes := []string{}
+	if len(r.Pause) == 0 {
+		return changes
+	}
+	pauses := append([]Pause{}, r.Pause...)
+
+	for i := range pauses {
+		p := &pauses[i]
+		if !p.End.IsZero() && p.End.Before(p.Start) {
+			changes = append(changes, fmt.Sprintf("swapped start and end of pause %s", formatPause(p)))
+			p.Start, p.End = p.End, p.Start
+		}
+	}
+
+	if !sort.SliceIsSorted(pauses, func(i, j int) bool { return pauses[i].Start.Before(pauses[j].Start) }) {
+		sort.SliceStable(pauses, func(i, j int) bool { return pauses[i].Start.Before(pauses[j].Start) })
+		changes = append(changes, "sorted pauses by start")
+	}
+
+	clamped := make([]Pause, 0, len(pauses))
+	for _, p := range pauses {
+		orig := p
+		if p.Start.Before(r.Start) {
+			p.Start = r.Start
+		}
+		if r.HasEnded() {
+			if p.End.IsZero() || p.End.After(r.End) {
+				p.End = r.End
+			}
+			if p.Start.After(r.End) {
+				p.Start = r.End
+			}
+		}
+		if p == orig {
+			clamped = append(clamped, p)
+			continue
+		}
+		if !p.End.IsZero() && !p.End.After(p.Start) {
+			changes = append(changes, fmt.Sprintf("removed pause %s outside of the record", formatPause(&orig)))
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("clamped pause %s to %s", formatPause(&orig), formatPause(&p)))
+		clamped = append(clamped, p)
+	}
+
+	merged := make([]Pause, 0, len(clamped))
+	for _, p := range clamped {
+		if len(merged) == 0 {
+			merged = append(merged, p)
+			continue
+		}
+		prev := &merged[len(merged)-1]
+		if !prev.End.IsZero() && !p.Start.Before(prev.End) {
+			merged = append(merged, p)
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("merged overlapping pauses %s and %s", formatPause(prev), formatPause(&p)))
+		if !prev.End.IsZero() && (p.End.IsZero() || p.End.After(prev.End)) {
+			prev.End = p.End
+		}
+		switch {
+		case p.Note == "" || p.Note == prev.Note:
+		case prev.Note == "":
+			prev.Note = p.Note
+		default:
+			prev.Note += "; " + p.Note
+		}
+	}
+
+	if len(changes) > 0 {
+		r.Pause = merged
+	}
+	return changes
+}
+
+// RepairRecords repairs the pauses of all records of the current workspace, see Record.RepairPauses.
+//
+// All changes are saved all-or-nothing. Locked records are left unchanged.
+// With dryRun, nothing is saved.
+func (t *Track) RepairRecords(dryRun bool) (RepairResult, error) {
+	result := RepairResult{Repairs: []RecordRepair{}}
+	if !dryRun {
+		if err := t.CheckWritable(); err != nil {
+			return result, err
+		}
+	}
+	records, err := t.LoadAllRecords()
+	if err != nil {
+		return result, err
+	}
+
+	tx := t.Begin()
+	for i := range records {
+		rec := &records[i]
+		result.Checked++
+		changes := rec.RepairPauses()
+		if len(changes) == 0 {
+			continue
+		}
+		if rec.Locked {
+			result.Locked++
+			continue
+		}
+		result.Repairs = append(result.Repairs, RecordRepair{Record: *rec, Changes: changes})
+		tx.Save(rec, true)
+	}
+	if dryRun {
+		tx.Rollback()
+		return result, nil
+	}
+	return result, tx.Commit()
+}
+
+// formatPause formats the time span of a pause, with an open end for running pauses
+func formatPause(p *Pause) string {
+	if p.End.IsZero() {
+		return fmt.Sprintf("%s - ?", p.Start.Format(util.DateTimeFormat))
+	}
+	return fmt.Sprintf("%s - %s", p.Start.Format(util.DateTimeFormat), p.End.Format(util.DateTimeFormat))
+}
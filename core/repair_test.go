@@ -0,0 +1,121 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairPauses(t *testing.T) {
+	start := time.Date(2023, 5, 2, 8, 0, 0, 0, time.Local)
+	at := func(min int) time.Time { return start.Add(time.Duration(min) * time.Minute) }
+
+	tt := []struct {
+		title   string
+		record  Record
+		pauses  []Pause
+		changes int
+	}{
+		{
+			title:   "consistent",
+			record:  Record{Start: start, End: at(120), Pause: []Pause{{Start: at(10), End: at(20)}, {Start: at(30), End: at(40)}}},
+			pauses:  []Pause{{Start: at(10), End: at(20)}, {Start: at(30), End: at(40)}},
+			changes: 0,
+		},
+		{
+			title:   "swapped and unsorted",
+			record:  Record{Start: start, End: at(120), Pause: []Pause{{Start: at(40), End: at(30)}, {Start: at(10), End: at(20)}}},
+			pauses:  []Pause{{Start: at(10), End: at(20)}, {Start: at(30), End: at(40)}},
+			changes: 2,
+		},
+		{
+			title: "clamped and removed",
+			record: Record{Start: at(10), End: at(120), Pause: []Pause{
+				{Start: at(0), End: at(5)}, {Start: at(0), End: at(20)}, {Start: at(110), End: at(130)}, {Start: at(130), End: at(140)},
+			}},
+			pauses:  []Pause{{Start: at(10), End: at(20)}, {Start: at(110), End: at(120)}},
+			changes: 4,
+		},
+		{
+			title:   "open pause of finished record",
+			record:  Record{Start: start, End: at(120), Pause: []Pause{{Start: at(100)}}},
+			pauses:  []Pause{{Start: at(100), End: at(120)}},
+			changes: 1,
+		},
+		{
+			title: "merged",
+			record: Record{Start: start, End: at(120), Pause: []Pause{
+				{Start: at(10), End: at(30), Note: "lunch"}, {Start: at(20), End: at(40), Note: "call"}, {Start: at(35), End: at(38)},
+			}},
+			pauses:  []Pause{{Start: at(10), End: at(40), Note: "lunch; call"}},
+			changes: 2,
+		},
+		{
+			title:   "merged into open pause",
+			record:  Record{Start: start, Pause: []Pause{{Start: at(10)}, {Start: at(20), End: at(30)}}},
+			pauses:  []Pause{{Start: at(10)}},
+			changes: 1,
+		},
+	}
+
+	for _, test := range tt {
+		changes := test.record.RepairPauses()
+		assert.Equal(t, test.changes, len(changes), "Wrong number of changes in case '%s': %v", test.title, changes)
+		assert.Equal(t, test.pauses, test.record.Pause, "Wrong pauses in case '%s'", test.title)
+		assert.Nil(t, test.record.Check(&Project{}), "Record not consistent in case '%s'", test.title)
+	}
+}
+
+func TestRepairRecords(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	start := time.Date(2023, 5, 2, 8, 0, 0, 0, time.Local)
+	records := []Record{
+		{Project: "test", Start: start, End: start.Add(time.Hour)},
+		{
+			Project: "test", Start: start.Add(2 * time.Hour), End: start.Add(3 * time.Hour),
+			Pause: []Pause{{Start: start.Add(150 * time.Minute), End: start.Add(4 * time.Hour)}},
+		},
+		{
+			Project: "test", Start: start.Add(4 * time.Hour), End: start.Add(5 * time.Hour), Locked: true,
+			Pause: []Pause{{Start: start.Add(270 * time.Minute)}},
+		},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false))
+	}
+
+	result, err := track.RepairRecords(true)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result.Checked)
+	assert.Equal(t, 1, len(result.Repairs))
+	assert.Equal(t, 1, result.Locked)
+
+	rec, err := track.LoadRecord(records[1].Start)
+	assert.Nil(t, err)
+	assert.Equal(t, records[1].Pause, rec.Pause, "Dry run must not change records")
+
+	result, err = track.RepairRecords(false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(result.Repairs))
+	assert.Equal(t, records[1].Start, result.Repairs[0].Record.Start)
+
+	rec, err = track.LoadRecord(records[1].Start)
+	assert.Nil(t, err)
+	assert.Equal(t, []Pause{{Start: start.Add(150 * time.Minute), End: start.Add(3 * time.Hour)}}, rec.Pause)
+
+	rec, err = track.LoadRecord(records[2].Start)
+	assert.Nil(t, err)
+	assert.Equal(t, records[2].Pause, rec.Pause, "Locked records must not be changed")
+
+	result, err = track.RepairRecords(false)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(result.Repairs))
+}
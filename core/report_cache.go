@@ -0,0 +1,266 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// reportCacheVersion is increased on incompatible changes of the cache format
+const reportCacheVersion = 3
+
+// reportCache holds aggregated times per day
+type reportCache struct {
+	Version int `json:"version"`
+	// Fingerprint of the Syntax the tags were derived with, see Syntax.fingerprint
+	Syntax string              `json:"syntax"`
+	Days   map[string]dayCache `json:"days"`
+}
+
+// dayCache holds aggregated times of the records of a day
+type dayCache struct {
+	// Fingerprint of the day directory, to detect changed records
+	Fingerprint string       `json:"fingerprint"`
+	Groups      []cacheGroup `json:"groups"`
+}
+
+// cacheGroup holds the aggregated time of all records of a day with the same project, category and tags
+type cacheGroup struct {
+	Project  string            `json:"project"`
+	Category string            `json:"category,omitempty"`
+	Tags     map[string]string `json:"tags"`
+	Start    time.Time         `json:"start"`
+	End      time.Time         `json:"end"`
+	Duration time.Duration     `json:"duration"`
+}
+
+// record creates a record with the group's project, category, tags and time span, for filtering
+func (g *cacheGroup) record() Record {
+	return Record{
+		Project:  g.Project,
+		Category: g.Category,
+		Tags:     g.Tags,
+		Start:    g.Start.Local(),
+		End:      g.End.Local(),
+	}
+}
+
+// inRange checks if the group lies completely within the given time range
+func (g *cacheGroup) inRange(start, end time.Time) bool {
+	return (start.IsZero() || !g.Start.Before(start)) && (end.IsZero() || !g.End.After(end))
+}
+
+// DeleteReportCache deletes the report cache of the current workspace
+func (t *Track) DeleteReportCache() error {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	err := t.FileSystem().Remove(t.ReportCachePath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// aggregateCached calls fn for all records, or cached groups of records, that match the filters.
+//
+// Days that are completely within the time range, and for which a valid cache entry exists,
+// are taken from the cache. All other days are loaded from the record files.
+// Cache entries are created for completed days in the past.
+//
+// Record files that can't be read are skipped, and their errors are passed to onError.
+// Days with such files are not cached.
+func (t *Track) aggregateCached(
+	ctx context.Context, filters FilterFunctions, start, end time.Time,
+	fn func(rec *Record, dur time.Duration), onError func(err error),
+) error {
+	ctx, span := t.startSpan(ctx, "track.aggregateCached")
+	defer span.End()
+	cachedDays, loadedDays := 0, 0
+	defer func() {
+		span.SetAttribute("cachedDays", cachedDays)
+		span.SetAttribute("loadedDays", loadedDays)
+	}()
+
+	cache := t.loadReportCache()
+	changed := false
+	today := util.ToDate(time.Now())
+
+	var loadErr error
+	err := t.walkDays(filters, false, func(date time.Time) bool {
+		key := date.Format(util.DateFormat)
+		fingerprint, err := t.dayFingerprint(date)
+		if err != nil {
+			loadErr = err
+			return false
+		}
+
+		if entry, ok := cache.Days[key]; ok && entry.Fingerprint == fingerprint {
+			usable := true
+			for _, g := range entry.Groups {
+				if !g.inRange(start, end) {
+					usable = false
+					break
+				}
+			}
+			if usable {
+				for _, g := range entry.Groups {
+					rec := g.record()
+					if Filter(&rec, filters) {
+						fn(&rec, g.Duration)
+					}
+				}
+				cachedDays++
+				return true
+			}
+		}
+
+		loadedDays++
+		records, fileErrs, err := t.readDayRecordsLenient(ctx, date)
+		if err != nil {
+			if errors.Is(err, ErrNoRecords) {
+				return true
+			}
+			loadErr = err
+			return false
+		}
+		for _, err := range fileErrs {
+			onError(err)
+		}
+		for i := range records {
+			rec := &records[i]
+			if Filter(rec, filters) {
+				fn(rec, rec.Duration(start, end))
+			}
+		}
+
+		if date.Before(today) && len(fileErrs) == 0 {
+			if groups, ok := groupRecords(records); ok {
+				cache.Days[key] = dayCache{Fingerprint: fingerprint, Groups: groups}
+				changed = true
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if loadErr != nil {
+		return loadErr
+	}
+
+	if changed {
+		return t.saveReportCache(&cache)
+	}
+	return nil
+}
+
+// groupRecords aggregates records by project, category and tags.
+// Returns false if any of the records is still running.
+func groupRecords(records []Record) ([]cacheGroup, bool) {
+	groups := map[string]*cacheGroup{}
+	keys := []string{}
+	for i := range records {
+		rec := &records[i]
+		if !rec.HasEnded() {
+			return nil, false
+		}
+		key := groupKey(rec)
+		g, ok := groups[key]
+		if !ok {
+			g = &cacheGroup{
+				Project:  rec.Project,
+				Category: rec.Category,
+				Tags:     rec.Tags,
+				Start:    rec.Start,
+				End:      rec.End,
+			}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		if rec.Start.Before(g.Start) {
+			g.Start = rec.Start
+		}
+		if rec.End.After(g.End) {
+			g.End = rec.End
+		}
+		g.Duration += rec.Duration(util.NoTime, util.NoTime)
+	}
+
+	result := make([]cacheGroup, len(keys))
+	for i, key := range keys {
+		result[i] = *groups[key]
+	}
+	return result, true
+}
+
+// groupKey creates a unique key from a record's project, category and tags
+func groupKey(rec *Record) string {
+	tags := make([]string, 0, len(rec.Tags))
+	for k, v := range rec.Tags {
+		tags = append(tags, k+"="+v)
+	}
+	sort.Strings(tags)
+	return rec.Project + "\n" + rec.Category + "\n" + strings.Join(tags, "\n")
+}
+
+// dayFingerprint creates a fingerprint from the names, sizes and modification times of a day's record files
+func (t *Track) dayFingerprint(date time.Time) (string, error) {
+	files, err := t.FileSystem().ReadDir(t.RecordDir(date))
+	if err != nil {
+		return "", err
+	}
+	hash := fnv.New64a()
+	for _, file := range files {
+		info, err := file.Info()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "%s/%d/%d;", file.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return fmt.Sprintf("%016x", hash.Sum64()), nil
+}
+
+// loadReportCache loads the report cache.
+// Returns an empty cache if the file does not exist, or if it is invalid or outdated.
+// The cache is outdated if the Track's Syntax changed, as the cached tags depend on it.
+func (t *Track) loadReportCache() reportCache {
+	syntax := t.Syntax().fingerprint()
+	empty := reportCache{Version: reportCacheVersion, Syntax: syntax, Days: map[string]dayCache{}}
+
+	data, err := t.FileSystem().ReadFile(t.ReportCachePath())
+	if err != nil {
+		return empty
+	}
+	var cache reportCache
+	if err := json.Unmarshal(data, &cache); err != nil || cache.Version != reportCacheVersion || cache.Syntax != syntax || cache.Days == nil {
+		return empty
+	}
+	return cache
+}
+
+// saveReportCache saves the report cache, replacing the file atomically
+// Does nothing in read-only mode.
+func (t *Track) saveReportCache(cache *reportCache) error {
+	if t.ReadOnly {
+		return nil
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	path := t.ReportCachePath()
+	tempPath := path + ".tmp"
+	if err := t.FileSystem().WriteFile(tempPath, data, 0600); err != nil {
+		return err
+	}
+	return t.FileSystem().Rename(tempPath, path)
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedReporter(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	project := NewProject("test", "", "T", []string{}, 0, 15)
+	err = track.SaveProject(project, false)
+	assert.Nil(t, err, "Error saving project")
+
+	for i := 0; i < 10; i++ {
+		record := Record{
+			Project: "test",
+			Start:   util.DateTime(2001, 2, 3+i/4, 8+i%4, 0, 0),
+			End:     util.DateTime(2001, 2, 3+i/4, 8+i%4, 30, 0),
+			Note:    "Note with +tag",
+			Tags:    map[string]string{"tag": ""},
+		}
+		err = track.SaveRecord(&record, false)
+		assert.Nil(t, err, "Error saving record")
+	}
+
+	expected, err := NewReporter(&track, []string{}, FilterFunctions{}, false, util.NoTime, util.NoTime)
+	assert.Nil(t, err, "Error creating reporter")
+
+	// First run fills the cache, second run uses it
+	for i := 0; i < 2; i++ {
+		reporter, err := NewCachedReporter(&track, []string{}, FilterFunctions{}, false, util.NoTime, util.NoTime)
+		assert.Nil(t, err, "Error creating reporter")
+		assert.Equal(t, expected.TotalTime, reporter.TotalTime, "Wrong total times")
+		assert.Equal(t, expected.TagTime, reporter.TagTime, "Wrong tag times")
+		assert.Equal(t, expected.TimeRange, reporter.TimeRange, "Wrong time range")
+		assert.True(t, util.FileExists(track.ReportCachePath()), "Missing cache file")
+	}
+	assert.Equal(t, 5*time.Hour, expected.TotalTime["test"], "Wrong total time")
+
+	cache := track.loadReportCache()
+	assert.Equal(t, 3, len(cache.Days), "Wrong number of cached days")
+
+	// Changed records invalidate the cache entry of the day
+	record, err := track.LoadRecord(util.DateTime(2001, 2, 3, 8, 0, 0))
+	assert.Nil(t, err, "Error loading record")
+	record.End = util.DateTime(2001, 2, 3, 8, 45, 0)
+	err = track.SaveRecord(&record, true)
+	assert.Nil(t, err, "Error saving record")
+
+	reporter, err := NewCachedReporter(&track, []string{}, FilterFunctions{}, false, util.NoTime, util.NoTime)
+	assert.Nil(t, err, "Error creating reporter")
+	assert.Equal(t, 5*time.Hour+15*time.Minute, reporter.TotalTime["test"], "Wrong total time after change")
+
+	// Filters that depend on the note are evaluated on the records, not on cached groups
+	record.Note = ""
+	record.Tags = map[string]string{}
+	err = track.SaveRecord(&record, true)
+	assert.Nil(t, err, "Error saving record")
+	for i := 0; i < 2; i++ {
+		filters := FilterFunctions{Functions: []FilterFunction{FilterByEmptyNote()}, NeedsRecords: true}
+		reporter, err = NewCachedReporter(&track, []string{}, filters, false, util.NoTime, util.NoTime)
+		assert.Nil(t, err, "Error creating reporter")
+		assert.Equal(t, 45*time.Minute, reporter.TotalTime["test"], "Wrong total time with note filter")
+	}
+
+	// Partially covered days are not taken from the cache
+	reporter, err = NewCachedReporter(
+		&track, []string{}, NewFilter([]FilterFunction{}, util.DateTime(2001, 2, 3, 9, 15, 0), util.NoTime),
+		false, util.DateTime(2001, 2, 3, 9, 15, 0), util.NoTime,
+	)
+	assert.Nil(t, err, "Error creating reporter")
+	assert.Equal(t, 4*time.Hour+15*time.Minute, reporter.TotalTime["test"], "Wrong total time for partial range")
+
+	err = track.DeleteReportCache()
+	assert.Nil(t, err, "Error deleting cache")
+	assert.False(t, util.FileExists(track.ReportCachePath()), "Cache file not deleted")
+}
+
+func TestCachedReporterSyntaxChange(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+
+	project := NewProject("test", "", "T", []string{}, 0, 15)
+	assert.Nil(t, track.SaveProject(project, false), "Error saving project")
+
+	record := Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 3, 8, 0, 0),
+		End:     util.DateTime(2001, 2, 3, 9, 0, 0),
+		Note:    "Call +Meeting",
+		Tags:    map[string]string{"Meeting": ""},
+	}
+	assert.Nil(t, track.SaveRecord(&record, false), "Error saving record")
+
+	reporter, err := NewCachedReporter(&track, []string{}, FilterFunctions{}, false, util.NoTime, util.NoTime)
+	assert.Nil(t, err, "Error creating reporter")
+	assert.Equal(t, time.Hour, reporter.TagTime["Meeting"], "Wrong tag time")
+	assert.Equal(t, 1, len(track.loadReportCache().Days), "Wrong number of cached days")
+
+	// Changed rules invalidate the cache
+	track.Config.TagNormalization = TagNormalization{Lowercase: true}
+	reporter, err = NewCachedReporter(&track, []string{}, FilterFunctions{}, false, util.NoTime, util.NoTime)
+	assert.Nil(t, err, "Error creating reporter")
+	assert.Equal(t, time.Hour, reporter.TagTime["meeting"], "Wrong tag time after changed normalization")
+	_, ok := reporter.TagTime["Meeting"]
+	assert.False(t, ok, "Tags from the outdated cache should not be used")
+}
@@ -1,11 +1,15 @@
 package core
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/mlange-42/track/util"
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slog"
 )
 
 // TimeRange represents a time range
@@ -14,6 +18,18 @@ type TimeRange struct {
 	End   time.Time
 }
 
+// GroupBy returns the group of a record for a Reporter, like a client or a note prefix.
+// Records with an empty group are not in any group.
+type GroupBy func(r *Record) string
+
+// Group is the aggregated time of a group of records
+type Group struct {
+	// Time of the group's records, excluding pauses
+	Time time.Duration
+	// Time range of the group's records, clipped to the time range of the report
+	TimeRange TimeRange
+}
+
 // Reporter for generating reports
 type Reporter struct {
 	Track        *Track
@@ -23,25 +39,111 @@ type Reporter struct {
 	TotalTime    map[string]time.Duration
 	AllProjects  map[string]Project
 	ProjectsTree *ProjectTree
-	TimeRange    TimeRange
+	TagTime      map[string]time.Duration
+	TagTotalTime map[string]time.Duration
+	TagsTree     *TagTree
+	// Time per category. Records without a category are under the empty category
+	CategoryTime map[string]time.Duration
+	// Results of custom aggregations registered with Track.SetAggregation,
+	// by name of the aggregation and key
+	Aggregations map[string]map[string]time.Duration
+	// Time and time range per group, for reporters created with NewGroupedReporter
+	Groups    map[string]Group
+	TimeRange TimeRange
+	// Root project of the report, set by Summarize. The workspace root if empty
+	Root string
+	// Errors of record files that could not be read, and were skipped.
+	// Elements are of type *RecordFileError.
+	Errors []error
 }
 
 // NewReporter creates a new Reporter from filters.
 // Arguments `start` and `end` are the exact time boundaries for duration calculations.
+//
+// Records of archived projects are excluded, unless `includeArchived`
+// or the filters' IncludeArchived is set.
+// The Reporter's Records are sorted by the filters' Sort order.
 func NewReporter(
 	t *Track, proj []string,
 	filters FilterFunctions, includeArchived bool,
 	start, end time.Time,
 ) (*Reporter, error) {
+	return newReporter(t, proj, filters, includeArchived, start, end, keepRecords, nil)
+}
+
+// NewGroupedReporter creates a new Reporter from filters, like NewReporter.
+//
+// Additionally, records are grouped by the given function,
+// and the time and time range per group are available in the Reporter's Groups.
+func NewGroupedReporter(
+	t *Track, proj []string,
+	filters FilterFunctions, includeArchived bool,
+	start, end time.Time, groupBy GroupBy,
+) (*Reporter, error) {
+	return newReporter(t, proj, filters, includeArchived, start, end, keepRecords, groupBy)
+}
+
+// NewStreamingReporter creates a new Reporter from filters, like NewReporter.
+//
+// Records are aggregated while they are loaded, and are not retained in the Reporter's Records.
+// Thus, memory usage does not grow with the number of records.
+// Use this for reports that only require aggregated times.
+func NewStreamingReporter(
+	t *Track, proj []string,
+	filters FilterFunctions, includeArchived bool,
+	start, end time.Time,
+) (*Reporter, error) {
+	return newReporter(t, proj, filters, includeArchived, start, end, streamRecords, nil)
+}
+
+// NewCachedReporter creates a new Reporter from filters, like NewStreamingReporter.
+//
+// Aggregated times of past days are cached in a side file of the workspace.
+// The cache entry of a day is invalidated when any of its records changes.
+// Records are not retained in the Reporter's Records.
+//
+// The cache can only be used with filters that depend on project, category, tags and time of records.
+// It is not used if FilterFunctions.NeedsRecords is set,
+// or if there are custom aggregations, see Track.SetAggregation.
+func NewCachedReporter(
+	t *Track, proj []string,
+	filters FilterFunctions, includeArchived bool,
+	start, end time.Time,
+) (*Reporter, error) {
+	return newReporter(t, proj, filters, includeArchived, start, end, cacheRecords, nil)
+}
+
+// reporterMode determines how a Reporter obtains and retains records
+type reporterMode int
+
+const (
+	keepRecords reporterMode = iota
+	streamRecords
+	cacheRecords
+)
+
+func newReporter(
+	t *Track, proj []string,
+	filters FilterFunctions, includeArchived bool,
+	start, end time.Time, mode reporterMode, groupBy GroupBy,
+) (*Reporter, error) {
+	ctx, span := t.startSpan(context.Background(), "track.report")
+	defer span.End()
+
+	includeArchived = includeArchived || filters.IncludeArchived
+
+	// Custom aggregations and some filters require the full records, which are not in the cache
+	if mode == cacheRecords && (len(t.aggregations) > 0 || filters.NeedsRecords) {
+		mode = streamRecords
+	}
 
 	allProjects, err := t.LoadAllProjects()
 	if err != nil {
 		return nil, err
 	}
-	for _, p := range proj {
-		if _, ok := allProjects[p]; !ok {
-			return nil, fmt.Errorf("no project named '%s'", p)
-		}
+	proj, err = ResolveProjectNames(proj, allProjects)
+	if err != nil {
+		return nil, err
 	}
 
 	projectsTree, err := t.ToProjectTree(allProjects)
@@ -79,38 +181,105 @@ func NewReporter(
 		}
 	}
 
-	filters.Functions = append(filters.Functions, FilterByProjects(maps.Keys(projects)))
-	records, err := t.LoadAllRecordsFiltered(filters)
-	if err != nil {
-		return nil, err
+	if log := t.logger(); log.Enabled(slog.LevelDebug) {
+		names := maps.Keys(projects)
+		sort.Strings(names)
+		log.Debug("projects selected for report", "projects", strings.Join(names, ","), "archived", includeArchived)
 	}
 
+	filters.Functions = append(filters.Functions, FilterByProjects(maps.Keys(projects)))
+
 	totals := make(map[string]time.Duration, len(projects)+1)
 	totals[projectsTree.Root.Value.Name] = time.Second * 0.0
 	for _, p := range projects {
 		totals[p.Name] = time.Second * 0.0
 	}
 
+	tagTotals := map[string]time.Duration{}
+	tagRolledUp := map[string]time.Duration{}
+	categoryTotals := map[string]time.Duration{}
+	aggregations := make(map[string]map[string]time.Duration, len(t.aggregations))
+	for _, agg := range t.aggregations {
+		aggregations[agg.name] = map[string]time.Duration{}
+	}
+	now := time.Now()
+	var groups map[string]Group
+	if groupBy != nil {
+		groups = map[string]Group{}
+	}
+
+	var records []Record
+	var fileErrs []error
 	tRange := TimeRange{}
-	for _, rec := range records {
-		dur := rec.Duration(start, end)
+
+	add := func(rec *Record, dur time.Duration) {
 		if dur > 0 {
 			totals[rec.Project] = totals[rec.Project] + dur
 		}
+		for tag := range rec.Tags {
+			tagTotals[tag] += dur
+		}
+		categoryTotals[rec.Category] += dur
+		// Roll up hierarchical tags, counting each record only once per ancestor
+		for tag := range ExpandTagHierarchy(rec.Tags) {
+			tagRolledUp[tag] += dur
+		}
+		if len(t.aggregations) > 0 {
+			clipped := clipRecord(rec, start, end, now)
+			for _, agg := range t.aggregations {
+				if key, value := agg.fn(clipped); key != "" {
+					aggregations[agg.name][key] += value
+				}
+			}
+		}
+
+		if groupBy != nil {
+			if key := groupBy(rec); key != "" {
+				g := groups[key]
+				g.Time += dur
+				g.TimeRange.extend(rec, start, end)
+				groups[key] = g
+			}
+		}
 
 		// TODO should be able to get rid of this; only required for timelines
-		if tRange.Start.IsZero() || rec.Start.Before(tRange.Start) {
-			tRange.Start = rec.Start
+		tRange.extend(rec, start, end)
+	}
+
+	if mode == cacheRecords {
+		// Records from days before the start date are not covered by the day-based cache.
+		// Only the latest of them can reach into the time range, as records don't overlap.
+		if !filters.Start.IsZero() {
+			before, err := t.recordOverlapping(ctx, util.ToDate(filters.Start))
+			if err != nil {
+				return nil, err
+			}
+			if before != nil && Filter(before, filters) {
+				add(before, before.Duration(start, end))
+			}
 		}
-		if rec.End.IsZero() {
-			if tRange.End.IsZero() || rec.Start.After(tRange.End) {
-				tRange.End = rec.Start
+		onError := func(err error) { fileErrs = append(fileErrs, err) }
+		if err := t.aggregateCached(ctx, filters, start, end, add, onError); err != nil {
+			return nil, err
+		}
+	} else {
+		fn, results, _ := t.allRecordsFiltered(ctx, filters, false)
+		go fn()
+
+		for res := range results {
+			if res.Err != nil {
+				if IsRecordFileError(res.Err) {
+					fileErrs = append(fileErrs, res.Err)
+					continue
+				}
+				return nil, res.Err
 			}
-		} else {
-			if tRange.End.IsZero() || rec.End.After(tRange.End) {
-				tRange.End = rec.End
+			if mode == keepRecords {
+				records = append(records, res.Record)
 			}
+			add(&res.Record, res.Record.Duration(start, end))
 		}
+		SortRecords(records, filters.Sort)
 	}
 
 	projectTotals := make(map[string]time.Duration, len(totals))
@@ -123,6 +292,13 @@ func NewReporter(
 		func(a, b time.Duration) time.Duration { return a + b },
 	)
 
+	tagsTree := NewTagTree(maps.Keys(tagRolledUp))
+	for tag := range tagRolledUp {
+		if _, ok := tagTotals[tag]; !ok {
+			tagTotals[tag] = 0
+		}
+	}
+
 	report := Reporter{
 		Track:        t,
 		Records:      records,
@@ -131,7 +307,136 @@ func NewReporter(
 		TotalTime:    totals,
 		AllProjects:  allProjects,
 		ProjectsTree: projectsTree,
+		TagTime:      tagTotals,
+		TagTotalTime: tagRolledUp,
+		TagsTree:     tagsTree,
+		CategoryTime: categoryTotals,
+		Aggregations: aggregations,
+		Groups:       groups,
 		TimeRange:    tRange,
+		Errors:       fileErrs,
 	}
+	span.SetAttribute("projects", len(projects))
+	span.SetAttribute("errors", len(fileErrs))
 	return &report, nil
 }
+
+// extend extends the time range by a record, clipped to start and end.
+// Zero bounds are open. Running records extend the time range to their start only.
+func (r *TimeRange) extend(rec *Record, start, end time.Time) {
+	recStart := rec.Start
+	if !start.IsZero() && recStart.Before(start) {
+		recStart = start
+	}
+	if r.Start.IsZero() || recStart.Before(r.Start) {
+		r.Start = recStart
+	}
+	if rec.End.IsZero() {
+		if r.End.IsZero() || recStart.After(r.End) {
+			r.End = recStart
+		}
+	} else {
+		recEnd := rec.End
+		if !end.IsZero() && recEnd.After(end) {
+			recEnd = end
+		}
+		if r.End.IsZero() || recEnd.After(r.End) {
+			r.End = recEnd
+		}
+	}
+}
+
+// Summarize re-roots the report at a project, and limits the depth of the project tree,
+// for high-level summaries.
+//
+// If root is not empty, Projects are restricted to the root project and its descendants.
+// If depth is positive, projects more than depth levels below the root are removed,
+// and their own time is rolled up into their ancestor at the depth limit.
+// ProjectTime and TotalTime are updated accordingly.
+// Records and times per tag and category are not affected. Use project filters to restrict those.
+func (r *Reporter) Summarize(root string, depth int) error {
+	// Projects may be shared with AllProjects
+	r.Projects = maps.Clone(r.Projects)
+
+	rootNode := r.ProjectsTree.Root
+	if root != "" {
+		name, err := ResolveProjectName(root, r.AllProjects)
+		if err != nil {
+			return err
+		}
+		rootNode = r.ProjectsTree.Nodes[name]
+		r.Root = name
+		if _, ok := r.Projects[name]; !ok {
+			r.Projects[name] = r.AllProjects[name]
+		}
+
+		for name := range r.Projects {
+			if _, ok := r.ProjectsTree.Nodes[name]; !ok || !r.isDescendant(name, rootNode) {
+				r.removeProject(name)
+			}
+		}
+	}
+
+	if depth <= 0 {
+		return nil
+	}
+	var limit func(node *ProjectNode, level int, summary string)
+	limit = func(node *ProjectNode, level int, summary string) {
+		name := node.Value.Name
+		if level == depth {
+			summary = name
+		} else if level > depth {
+			if _, ok := r.Projects[name]; ok {
+				r.ProjectTime[summary] += r.ProjectTime[name]
+				if _, ok := r.Projects[summary]; !ok {
+					r.Projects[summary] = r.AllProjects[summary]
+				}
+				r.removeProject(name)
+			}
+		}
+		for _, child := range node.Children {
+			limit(child, level+1, summary)
+		}
+	}
+	limit(rootNode, 0, "")
+	return nil
+}
+
+// Tree creates a tree of the reporter's projects, rooted at the reporter's Root if it is set
+func (r *Reporter) Tree() (*ProjectTree, error) {
+	tree, err := r.Track.ToProjectTree(r.Projects)
+	if err != nil {
+		return nil, err
+	}
+	if r.Root == "" {
+		return tree, nil
+	}
+	root, ok := tree.Nodes[r.Root]
+	if !ok {
+		return nil, fmt.Errorf("BUG! Project '%s' not in project tree", r.Root)
+	}
+	root.Parent = nil
+	sub := &ProjectTree{Root: root, Nodes: map[string]*ProjectNode{r.Root: root}}
+	desc, _ := tree.Descendants(r.Root)
+	for _, node := range desc {
+		sub.Nodes[node.Value.Name] = node
+	}
+	return sub, nil
+}
+
+// isDescendant checks whether a project is the given node or one of its descendants
+func (r *Reporter) isDescendant(name string, node *ProjectNode) bool {
+	for curr := r.ProjectsTree.Nodes[name]; curr != nil; curr = curr.Parent {
+		if curr == node {
+			return true
+		}
+	}
+	return false
+}
+
+// removeProject removes a project and its times from the report
+func (r *Reporter) removeProject(name string) {
+	delete(r.Projects, name)
+	delete(r.ProjectTime, name)
+	delete(r.TotalTime, name)
+}
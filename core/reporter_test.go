@@ -2,11 +2,13 @@ package core
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/mlange-42/track/util"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/maps"
 )
 
 func TestReporter(t *testing.T) {
@@ -33,11 +35,23 @@ func TestReporter(t *testing.T) {
 	}
 
 	for i := 0; i < 23; i++ {
+		note := "Test note with +key=value and +tag and +foo=bar"
+		tags := map[string]string{"key": "value", "tag": "", "foo": "bar"}
+		if i%2 == 0 {
+			note += " +tag/child"
+			tags["tag/child"] = ""
+		}
+		category := ""
+		if i%3 == 0 {
+			category = "meeting"
+		}
 		record := Record{
-			Project: "child",
-			Start:   util.DateTime(2001, 2, 3, i, 0, 0),
-			End:     util.DateTime(2001, 2, 3, i, 30, 0),
-			Note:    "Test note with +key=value and +tag and +foo=bar",
+			Project:  "child",
+			Start:    util.DateTime(2001, 2, 3, i, 0, 0),
+			End:      util.DateTime(2001, 2, 3, i, 30, 0),
+			Note:     note,
+			Tags:     tags,
+			Category: category,
 		}
 		err = track.SaveRecord(&record, false)
 		if err != nil {
@@ -53,6 +67,13 @@ func TestReporter(t *testing.T) {
 		t.Fatal("error creating reporter")
 	}
 	assert.Equal(t, 11*time.Hour+30*time.Minute, reporter.TotalTime["test"], "Wrong total time")
+	assert.Equal(t, 11*time.Hour+30*time.Minute, reporter.TagTime["tag"], "Wrong tag time")
+	assert.Equal(t, 11*time.Hour+30*time.Minute, reporter.TagTotalTime["tag"], "Wrong rolled-up tag time")
+	assert.Equal(t, 6*time.Hour, reporter.TagTime["tag/child"], "Wrong tag time")
+	assert.Equal(t, 6*time.Hour, reporter.TagTotalTime["tag/child"], "Wrong rolled-up tag time")
+	assert.NotNil(t, reporter.TagsTree.Nodes["tag/child"], "Tag missing in tag tree")
+	assert.Equal(t, 4*time.Hour, reporter.CategoryTime["meeting"], "Wrong category time")
+	assert.Equal(t, 7*time.Hour+30*time.Minute, reporter.CategoryTime[""], "Wrong time without category")
 
 	reporter, err = NewReporter(
 		&track, []string{"test", "child"}, FilterFunctions{},
@@ -62,6 +83,20 @@ func TestReporter(t *testing.T) {
 		t.Fatal("error creating reporter")
 	}
 	assert.Equal(t, 11*time.Hour+30*time.Minute, reporter.TotalTime["test"], "Wrong total time")
+	assert.Equal(t, 23, len(reporter.Records), "Wrong number of records")
+
+	streaming, err := NewStreamingReporter(
+		&track, []string{"test", "child"}, FilterFunctions{},
+		false, util.NoTime, util.NoTime,
+	)
+	if err != nil {
+		t.Fatal("error creating reporter")
+	}
+	assert.Equal(t, reporter.TotalTime, streaming.TotalTime, "Wrong total times")
+	assert.Equal(t, reporter.TagTotalTime, streaming.TagTotalTime, "Wrong tag times")
+	assert.Equal(t, reporter.CategoryTime, streaming.CategoryTime, "Wrong category times")
+	assert.Equal(t, reporter.TimeRange, streaming.TimeRange, "Wrong time range")
+	assert.Nil(t, streaming.Records, "Streaming reporter should not retain records")
 
 	_, err = NewReporter(
 		&track, []string{"foo"}, FilterFunctions{},
@@ -69,3 +104,156 @@ func TestReporter(t *testing.T) {
 	)
 	assert.NotNil(t, err, "expecting error on invalid project")
 }
+
+func TestReporterMultiDayRecord(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	if err != nil {
+		t.Fatal("error creating temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	if err != nil {
+		t.Fatal("error creating Track instance")
+	}
+
+	project := NewProject("test", "", "T", []string{}, 0, 15)
+	err = track.SaveProject(project, false)
+	if err != nil {
+		t.Fatal("error saving project")
+	}
+
+	record := Record{
+		Project: "test",
+		Start:   util.DateTime(2001, 2, 1, 20, 0, 0),
+		End:     util.DateTime(2001, 2, 4, 2, 0, 0),
+	}
+	err = track.SaveRecord(&record, false)
+	if err != nil {
+		t.Fatal("error saving record")
+	}
+
+	start, end := util.Date(2001, 2, 3), util.Date(2001, 2, 4)
+	for _, mode := range []reporterMode{keepRecords, streamRecords, cacheRecords} {
+		reporter, err := newReporter(
+			&track, []string{}, NewFilter([]FilterFunction{}, start, end),
+			false, start, end, mode, nil,
+		)
+		assert.Nil(t, err)
+		assert.Equal(t, 24*time.Hour, reporter.ProjectTime["test"])
+		assert.Equal(t, TimeRange{Start: start, End: end}, reporter.TimeRange)
+	}
+}
+
+func TestGroupedReporter(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err)
+	assert.Nil(t, track.SaveProject(NewProject("test", "", "T", []string{}, 0, 15), false))
+
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 2, 22, 0, 0), End: util.DateTime(2001, 2, 3, 2, 0, 0), Note: "ACME: Deployment"},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 10, 0, 0), Note: "Initech: Meeting"},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 11, 0, 0), End: util.DateTime(2001, 2, 3, 12, 0, 0), Note: "ACME: Review"},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 13, 0, 0), End: util.DateTime(2001, 2, 3, 14, 0, 0), Note: "Lunch"},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false))
+	}
+
+	start, end := util.Date(2001, 2, 3), util.Date(2001, 2, 4)
+	reporter, err := NewGroupedReporter(
+		&track, []string{}, NewFilter([]FilterFunction{}, start, end), false, start, end,
+		func(r *Record) string {
+			client, _, ok := strings.Cut(r.Note, ":")
+			if !ok {
+				return ""
+			}
+			return client
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]Group{
+		"ACME": {
+			Time:      3 * time.Hour,
+			TimeRange: TimeRange{Start: start, End: util.DateTime(2001, 2, 3, 12, 0, 0)},
+		},
+		"Initech": {
+			Time:      2 * time.Hour,
+			TimeRange: TimeRange{Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 10, 0, 0)},
+		},
+	}, reporter.Groups)
+	assert.Equal(t, 4, len(reporter.Records))
+
+	reporter, err = NewReporter(&track, []string{}, NewFilter([]FilterFunction{}, start, end), false, start, end)
+	assert.Nil(t, err)
+	assert.Nil(t, reporter.Groups)
+}
+
+func TestReporterSummarize(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err)
+	for _, p := range []Project{
+		NewProject("a", "", "A", []string{}, 0, 15),
+		NewProject("b", "a", "B", []string{}, 0, 15),
+		NewProject("c", "b", "C", []string{}, 0, 15),
+		NewProject("d", "a", "D", []string{}, 0, 15),
+		NewProject("x", "", "X", []string{}, 0, 15),
+	} {
+		assert.Nil(t, track.SaveProject(p, false))
+	}
+
+	records := []Record{
+		{Project: "c", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0)},
+		{Project: "b", Start: util.DateTime(2001, 2, 3, 9, 0, 0), End: util.DateTime(2001, 2, 3, 11, 0, 0)},
+		{Project: "d", Start: util.DateTime(2001, 2, 3, 11, 0, 0), End: util.DateTime(2001, 2, 3, 11, 30, 0)},
+		{Project: "x", Start: util.DateTime(2001, 2, 3, 12, 0, 0), End: util.DateTime(2001, 2, 3, 13, 0, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false))
+	}
+
+	newReporter := func() *Reporter {
+		reporter, err := NewStreamingReporter(&track, []string{}, NewFilter([]FilterFunction{}, util.NoTime, util.NoTime), true, util.NoTime, util.NoTime)
+		assert.Nil(t, err)
+		return reporter
+	}
+
+	reporter := newReporter()
+	assert.Nil(t, reporter.Summarize("a", 1))
+	assert.Equal(t, "a", reporter.Root)
+	assert.ElementsMatch(t, []string{"a", "b", "d"}, maps.Keys(reporter.Projects))
+	assert.Equal(t, 3*time.Hour, reporter.ProjectTime["b"])
+	assert.Equal(t, 3*time.Hour, reporter.TotalTime["b"])
+	assert.Equal(t, 3*time.Hour+30*time.Minute, reporter.TotalTime["a"])
+	assert.Equal(t, 5, len(reporter.AllProjects))
+
+	tree, err := reporter.Tree()
+	assert.Nil(t, err)
+	assert.Equal(t, "a", tree.Root.Value.Name)
+	assert.Nil(t, tree.Root.Parent)
+	assert.ElementsMatch(t, []string{"b", "d"}, maps.Keys(tree.Root.Children))
+	assert.Equal(t, 3, len(tree.Nodes))
+
+	reporter = newReporter()
+	assert.Nil(t, reporter.Summarize("", 1))
+	assert.ElementsMatch(t, []string{"a", "x"}, maps.Keys(reporter.Projects))
+	assert.Equal(t, 3*time.Hour+30*time.Minute, reporter.ProjectTime["a"])
+
+	tree, err = reporter.Tree()
+	assert.Nil(t, err)
+	assert.Equal(t, track.WorkspaceLabel(), tree.Root.Value.Name)
+
+	reporter = newReporter()
+	assert.Nil(t, reporter.Summarize("b", 0))
+	assert.ElementsMatch(t, []string{"b", "c"}, maps.Keys(reporter.Projects))
+
+	assert.NotNil(t, newReporter().Summarize("foo", 0))
+}
@@ -0,0 +1,200 @@
+package core
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// Retention are rules for deleting and anonymizing old records.
+// Ages are in months, counted from the start of records. Zero disables a rule.
+type Retention struct {
+	// Age after which records are deleted permanently
+	DeleteAfter int `yaml:"deleteAfter"`
+	// Age after which records are anonymized: notes, tags, links and pause notes are removed
+	AnonymizeAfter int `yaml:"anonymizeAfter"`
+	// Age after which notes are stripped, keeping only tags
+	StripNotesAfter int `yaml:"stripNotesAfter"`
+}
+
+// IsEnabled checks if any retention rule is enabled
+func (r *Retention) IsEnabled() bool {
+	return r.DeleteAfter > 0 || r.AnonymizeAfter > 0 || r.StripNotesAfter > 0
+}
+
+// RetentionResult summarizes the changes of applying retention rules
+type RetentionResult struct {
+	// Records deleted
+	Deleted int
+	// Records anonymized
+	Anonymized int
+	// Records with stripped notes
+	Stripped int
+	// Locked records that were left unchanged
+	Locked int
+}
+
+// Changed returns the total number of changed records
+func (r *RetentionResult) Changed() int {
+	return r.Deleted + r.Anonymized + r.Stripped
+}
+
+// ApplyRetention applies the retention rules from the config to all records of the current workspace.
+//
+// Deleted records are not moved to the trash. The history of changed records is deleted,
+// as it contains the original data. Locked records are left unchanged.
+// With dryRun, nothing is saved.
+func (t *Track) ApplyRetention(now time.Time, dryRun bool) (RetentionResult, error) {
+	result := RetentionResult{}
+	rules := t.Config.Retention
+	if !rules.IsEnabled() {
+		return result, nil
+	}
+	if !dryRun {
+		if err := t.CheckWritable(); err != nil {
+			return result, err
+		}
+	}
+
+	cutoff := func(months int) time.Time {
+		if months <= 0 {
+			return util.NoTime
+		}
+		return now.AddDate(0, -months, 0)
+	}
+	deleteBefore := cutoff(rules.DeleteAfter)
+	anonymizeBefore := cutoff(rules.AnonymizeAfter)
+	stripBefore := cutoff(rules.StripNotesAfter)
+	latest := deleteBefore
+	for _, c := range []time.Time{anonymizeBefore, stripBefore} {
+		if c.After(latest) {
+			latest = c
+		}
+	}
+
+	filters := NewFilter([]FilterFunction{}, util.NoTime, latest)
+	filters.IncludeArchived = true
+	records, err := t.LoadAllRecordsFiltered(filters)
+	if err != nil {
+		return result, err
+	}
+
+	for i := range records {
+		rec := &records[i]
+		if !rec.HasEnded() {
+			continue
+		}
+		if !deleteBefore.IsZero() && rec.Start.Before(deleteBefore) {
+			if rec.Locked {
+				result.Locked++
+				continue
+			}
+			result.Deleted++
+			if !dryRun {
+				if err := t.purgeRecord(rec); err != nil {
+					return result, err
+				}
+			}
+			continue
+		}
+
+		var changed Record
+		var counter *int
+		switch {
+		case !anonymizeBefore.IsZero() && rec.Start.Before(anonymizeBefore):
+			changed, counter = anonymizeRecord(rec), &result.Anonymized
+		case !stripBefore.IsZero() && rec.Start.Before(stripBefore):
+			changed, counter = stripRecordNotes(t.Syntax(), rec), &result.Stripped
+		default:
+			continue
+		}
+		if SerializeRecord(&changed, util.NoTime) == SerializeRecord(rec, util.NoTime) {
+			continue
+		}
+		if rec.Locked {
+			result.Locked++
+			continue
+		}
+		*counter++
+		if dryRun {
+			continue
+		}
+		if err := t.replaceRecord(&changed); err != nil {
+			return result, err
+		}
+	}
+	if !dryRun && result.Changed() > 0 {
+		// Cached times may include deleted records
+		if err := t.DeleteReportCache(); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// purgeRecord deletes a record permanently, without moving it to the trash, and deletes its history
+func (t *Track) purgeRecord(rec *Record) error {
+	if err := t.FileSystem().Remove(t.historyPath(rec.Start)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	format, err := t.findRecordFile(rec.Start)
+	if err != nil {
+		return err
+	}
+	if err := t.removeRecordAt(rec.Start, format); err != nil {
+		return err
+	}
+	return t.removeEmptyDayDir(t.RecordDir(rec.Start))
+}
+
+// replaceRecord overwrites a record in its current file format, and deletes its history
+func (t *Track) replaceRecord(rec *Record) error {
+	if err := t.FileSystem().Remove(t.historyPath(rec.Start)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	format, err := t.findRecordFile(rec.Start)
+	if err != nil {
+		return err
+	}
+	return t.writeRecord(rec, format)
+}
+
+// anonymizeRecord returns a copy of a record without note, tags, links and pause notes
+func anonymizeRecord(rec *Record) Record {
+	anon := rec.copy()
+	anon.Note = ""
+	anon.Tags = map[string]string{}
+	anon.Links = nil
+	for i := range anon.Pause {
+		anon.Pause[i].Note = ""
+	}
+	return anon
+}
+
+// stripRecordNotes returns a copy of a record with notes reduced to its tags, and without link and pause notes
+func stripRecordNotes(syntax *Syntax, rec *Record) Record {
+	stripped := rec.copy()
+	keys := make([]string, 0, len(rec.Tags))
+	for k := range rec.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tokens := make([]string, len(keys))
+	for i, k := range keys {
+		tokens[i] = syntax.TagPrefix + k
+		if v := rec.Tags[k]; v != "" {
+			tokens[i] += "=" + v
+		}
+	}
+	stripped.Note = strings.Join(tokens, " ")
+	for i := range stripped.Links {
+		stripped.Links[i].Note = ""
+	}
+	for i := range stripped.Pause {
+		stripped.Pause[i].Note = ""
+	}
+	return stripped
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyRetention(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	track.Config.History = true
+
+	date := func(y, m int) time.Time {
+		return time.Date(y, time.Month(m), 2, 8, 0, 0, 0, time.Local)
+	}
+	now := date(2023, 7)
+	records := []Record{
+		{Project: "test", Start: date(2020, 1), End: date(2020, 1).Add(time.Hour), Note: "old"},
+		{Project: "test", Start: date(2020, 2), End: date(2020, 2).Add(time.Hour), Note: "locked", Locked: true},
+		{
+			Project: "test", Start: date(2021, 6), End: date(2021, 6).Add(time.Hour), Note: "secret +client=acme",
+			Tags:  map[string]string{"client": "acme"},
+			Links: []Link{{Target: "https://example.com", Note: "ticket"}},
+		},
+		{
+			Project: "test", Start: date(2023, 1), End: date(2023, 1).Add(2 * time.Hour), Note: "secret +review",
+			Tags:  map[string]string{"review": ""},
+			Pause: []Pause{{Start: date(2023, 1).Add(time.Hour), End: date(2023, 1).Add(70 * time.Minute), Note: "lunch"}},
+		},
+		{Project: "test", Start: date(2023, 6), End: date(2023, 6).Add(time.Hour), Note: "recent"},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false))
+	}
+
+	result, err := track.ApplyRetention(now, false)
+	assert.Nil(t, err)
+	assert.Equal(t, RetentionResult{}, result, "No rules, no changes")
+
+	track.Config.Retention = Retention{DeleteAfter: 36, AnonymizeAfter: 12, StripNotesAfter: 3}
+
+	result, err = track.ApplyRetention(now, true)
+	assert.Nil(t, err)
+	assert.Equal(t, RetentionResult{Deleted: 1, Anonymized: 1, Stripped: 1, Locked: 1}, result)
+	_, err = track.LoadRecord(records[0].Start)
+	assert.Nil(t, err, "Dry run must not delete records")
+
+	result, err = track.ApplyRetention(now, false)
+	assert.Nil(t, err)
+	assert.Equal(t, RetentionResult{Deleted: 1, Anonymized: 1, Stripped: 1, Locked: 1}, result)
+
+	_, err = track.LoadRecord(records[0].Start)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+	rec, err := track.LoadRecord(records[1].Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "locked", rec.Note)
+
+	rec, err = track.LoadRecord(records[2].Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "", rec.Note)
+	assert.Equal(t, 0, len(rec.Tags))
+	assert.Equal(t, 0, len(rec.Links))
+
+	rec, err = track.LoadRecord(records[3].Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "+review", rec.Note)
+	assert.Equal(t, map[string]string{"review": ""}, rec.Tags)
+	assert.Equal(t, "", rec.Pause[0].Note)
+
+	history, err := track.RecordHistory(records[3].Start)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(history))
+
+	rec, err = track.LoadRecord(records[4].Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "recent", rec.Note)
+
+	result, err = track.ApplyRetention(now, false)
+	assert.Nil(t, err)
+	assert.Equal(t, RetentionResult{Locked: 1}, result, "Rules are idempotent")
+}
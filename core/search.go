@@ -0,0 +1,105 @@
+package core
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SearchResult is a record matching a search query
+type SearchResult struct {
+	Record Record
+	// Score for ranking results. Higher is better.
+	Score int
+	// Lines of the note and pause notes containing any of the search terms
+	Lines []string
+}
+
+// SearchRecords searches for records containing all words of the query
+// in their note or pause notes, case-insensitive.
+//
+// Results are ranked by the number of term occurrences, with a bonus for
+// matching the whole query as a phrase, and by start time (latest first).
+// Argument limit restricts the number of results. Zero or negative means no limit.
+func (t *Track) SearchRecords(query string, filters FilterFunctions, limit int) ([]SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return []SearchResult{}, nil
+	}
+	phrase := strings.ToLower(strings.Join(strings.Fields(query), " "))
+
+	fn, results, _ := t.AllRecordsFiltered(filters, true)
+	go fn()
+
+	found := []SearchResult{}
+	for res := range results {
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		if result, ok := matchRecord(&res.Record, terms, phrase); ok {
+			found = append(found, result)
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Score != found[j].Score {
+			return found[i].Score > found[j].Score
+		}
+		return found[i].Record.Start.After(found[j].Record.Start)
+	})
+
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func matchRecord(record *Record, terms []string, phrase string) (SearchResult, bool) {
+	lines := strings.Split(record.Note, "\n")
+	for _, p := range record.Pause {
+		if p.Note != "" {
+			lines = append(lines, p.Note)
+		}
+	}
+
+	counts := make(map[string]int, len(terms))
+	matchLines := []string{}
+	score := 0
+	for _, line := range lines {
+		words := searchTerms(line)
+		lineMatch := false
+		for _, w := range words {
+			for _, term := range terms {
+				if strings.HasPrefix(w, term) {
+					counts[term]++
+					score++
+					lineMatch = true
+				}
+			}
+		}
+		if len(terms) > 1 && strings.Contains(strings.ToLower(line), phrase) {
+			score += len(terms)
+		}
+		if lineMatch {
+			matchLines = append(matchLines, strings.TrimSpace(line))
+		}
+	}
+
+	for _, term := range terms {
+		if counts[term] == 0 {
+			return SearchResult{}, false
+		}
+	}
+	return SearchResult{
+		Record: *record,
+		Score:  score,
+		Lines:  matchLines,
+	}, true
+}
+
+// searchTerms splits a text into lower-case words, ignoring punctuation and tag prefixes
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
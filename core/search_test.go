@@ -0,0 +1,75 @@
+package core
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchRecords(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	records := []Record{
+		{
+			Project: "test",
+			Start:   util.DateTime(2001, 2, 3, 8, 0, 0),
+			End:     util.DateTime(2001, 2, 3, 9, 0, 0),
+			Note:    "Meeting with the team\nabout the release",
+		},
+		{
+			Project: "test",
+			Start:   util.DateTime(2001, 2, 3, 10, 0, 0),
+			End:     util.DateTime(2001, 2, 3, 11, 0, 0),
+			Note:    "Bugfixing",
+			Pause: []Pause{
+				{
+					Start: util.DateTime(2001, 2, 3, 10, 15, 0),
+					End:   util.DateTime(2001, 2, 3, 10, 30, 0),
+					Note:  "Team meeting",
+				},
+			},
+		},
+		{
+			Project: "test",
+			Start:   util.DateTime(2001, 2, 4, 10, 0, 0),
+			End:     util.DateTime(2001, 2, 4, 11, 0, 0),
+			Note:    "Team meeting, meeting notes",
+		},
+	}
+	for _, rec := range records {
+		err = track.SaveRecord(&rec, false)
+		assert.Nil(t, err, "Error saving record")
+	}
+
+	res, err := track.SearchRecords("meet team", FilterFunctions{}, 0)
+	assert.Nil(t, err, "Error searching records")
+	assert.Equal(t, 3, len(res), "Wrong number of results")
+	assert.Equal(t, records[2].Start, res[0].Record.Start, "Wrong ranking")
+	assert.Equal(t, records[1].Start, res[1].Record.Start, "Wrong ranking")
+	assert.Equal(t, records[0].Start, res[2].Record.Start, "Wrong ranking")
+	assert.Equal(t, []string{"Team meeting"}, res[1].Lines, "Wrong matching lines")
+	assert.Equal(t, []string{"Meeting with the team"}, res[2].Lines, "Wrong matching lines")
+
+	res, err = track.SearchRecords("release", FilterFunctions{}, 0)
+	assert.Nil(t, err, "Error searching records")
+	assert.Equal(t, 1, len(res), "Wrong number of results")
+
+	res, err = track.SearchRecords("team", FilterFunctions{}, 2)
+	assert.Nil(t, err, "Error searching records")
+	assert.Equal(t, 2, len(res), "Wrong number of results")
+
+	res, err = track.SearchRecords("team xyz", FilterFunctions{}, 0)
+	assert.Nil(t, err, "Error searching records")
+	assert.Equal(t, 0, len(res), "Wrong number of results")
+
+	res, err = track.SearchRecords(" ", FilterFunctions{}, 0)
+	assert.Nil(t, err, "Error searching records")
+	assert.Equal(t, 0, len(res), "Wrong number of results")
+}
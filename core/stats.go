@@ -0,0 +1,196 @@
+package core
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// DurationBins are the upper bounds of the histogram bins of a Distribution.
+// The last bin of a histogram is open, for durations of at least the last bound.
+var DurationBins = []time.Duration{
+	15 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	2 * time.Hour,
+	4 * time.Hour,
+}
+
+// Distribution summarizes a set of durations
+type Distribution struct {
+	Count  int
+	Total  time.Duration
+	Min    time.Duration
+	Max    time.Duration
+	Mean   time.Duration
+	Median time.Duration
+	// 90th percentile, exceeded by the longest 10% of durations
+	P90 time.Duration
+	// 99th percentile, exceeded by the longest 1% of durations
+	P99 time.Duration
+	// Counts per bin of DurationBins, with an additional bin for larger durations
+	Histogram []int
+}
+
+// NewDistribution creates a Distribution from durations
+func NewDistribution(durations []time.Duration) Distribution {
+	dist := Distribution{
+		Count:     len(durations),
+		Histogram: make([]int, len(DurationBins)+1),
+	}
+	if len(durations) == 0 {
+		return dist
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	for _, d := range sorted {
+		dist.Total += d
+		bin := sort.Search(len(DurationBins), func(i int) bool { return d < DurationBins[i] })
+		dist.Histogram[bin]++
+	}
+	dist.Min = sorted[0]
+	dist.Max = sorted[len(sorted)-1]
+	dist.Mean = dist.Total / time.Duration(len(sorted))
+	dist.Median = percentile(sorted, 0.5)
+	dist.P90 = percentile(sorted, 0.9)
+	dist.P99 = percentile(sorted, 0.99)
+	return dist
+}
+
+// percentile returns the p-quantile of sorted durations, interpolating linearly between neighbouring durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	pos := p * float64(len(sorted)-1)
+	lower := int(pos)
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lower)
+	return sorted[lower] + time.Duration(math.Round(frac*float64(sorted[lower+1]-sorted[lower])))
+}
+
+// WorkStats are statistics of work patterns
+type WorkStats struct {
+	// Number of days with records
+	Days int
+	// Average time of day of the first record start per day
+	AverageStart time.Duration
+	// Average time of day of the last record end per day, relative to the start of the day.
+	// Can exceed 24 hours for records that end after midnight.
+	AverageEnd time.Duration
+	// Longest period of work without pauses or gaps between records
+	LongestStreak TimeRange
+	// Breaks are pauses, and gaps between records on the same day
+	Breaks Distribution
+	// Record durations per project, excluding pauses
+	Projects map[string]Distribution
+}
+
+// NewWorkStats calculates work pattern statistics from records.
+// Running records and pauses are considered to end at the given time.
+func NewWorkStats(records []Record, now time.Time) WorkStats {
+	stats := WorkStats{Projects: map[string]Distribution{}}
+	if len(records) == 0 {
+		stats.Breaks = NewDistribution(nil)
+		return stats
+	}
+
+	sorted := make([]*Record, len(records))
+	for i := range records {
+		sorted[i] = &records[i]
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	recordEnd := func(r *Record) time.Time {
+		if r.HasEnded() {
+			return r.End
+		}
+		return now
+	}
+
+	// Daily start and end times, relative to the start of the day of the day's first record
+	type dayStats struct {
+		Start time.Duration
+		End   time.Duration
+	}
+	days := []dayStats{}
+	var currentDay time.Time
+	for _, r := range sorted {
+		day := util.ToDate(r.Start)
+		end := recordEnd(r)
+		if len(days) == 0 || !day.Equal(currentDay) {
+			currentDay = day
+			days = append(days, dayStats{Start: r.Start.Sub(day), End: end.Sub(day)})
+			continue
+		}
+		if e := end.Sub(currentDay); e > days[len(days)-1].End {
+			days[len(days)-1].End = e
+		}
+	}
+	stats.Days = len(days)
+	var startSum, endSum time.Duration
+	for _, d := range days {
+		startSum += d.Start
+		endSum += d.End
+	}
+	stats.AverageStart = startSum / time.Duration(len(days))
+	stats.AverageEnd = endSum / time.Duration(len(days))
+
+	breaks := []time.Duration{}
+	durations := map[string][]time.Duration{}
+	var streak TimeRange
+	for i, r := range sorted {
+		end := recordEnd(r)
+		durations[r.Project] = append(durations[r.Project], r.Duration(util.NoTime, end))
+
+		for _, p := range r.Pause {
+			breaks = append(breaks, p.Duration(util.NoTime, end))
+		}
+		if i > 0 {
+			prevEnd := recordEnd(sorted[i-1])
+			if gap := r.Start.Sub(prevEnd); gap > 0 && util.ToDate(prevEnd).Equal(util.ToDate(r.Start)) {
+				breaks = append(breaks, gap)
+			}
+		}
+
+		// Work periods between pauses, joined with the preceding period if there is no gap
+		workStart := r.Start
+		for _, p := range r.Pause {
+			streak = extendStreak(&stats.LongestStreak, streak, TimeRange{Start: workStart, End: p.Start})
+			workStart = p.End
+			if p.End.IsZero() {
+				workStart = end
+			}
+		}
+		streak = extendStreak(&stats.LongestStreak, streak, TimeRange{Start: workStart, End: end})
+	}
+
+	stats.Breaks = NewDistribution(breaks)
+	for project, d := range durations {
+		stats.Projects[project] = NewDistribution(d)
+	}
+	return stats
+}
+
+// extendStreak joins a period of work with the current streak if they are contiguous,
+// or starts a new streak otherwise. Updates the longest streak, and returns the current streak.
+func extendStreak(longest *TimeRange, current TimeRange, work TimeRange) TimeRange {
+	if !work.End.After(work.Start) {
+		return current
+	}
+	if !current.End.IsZero() && !work.Start.After(current.End) {
+		if work.End.After(current.End) {
+			current.End = work.End
+		}
+	} else {
+		current = work
+	}
+	if current.End.Sub(current.Start) > longest.End.Sub(longest.Start) {
+		*longest = current
+	}
+	return current
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDistribution(t *testing.T) {
+	dist := NewDistribution([]time.Duration{
+		time.Hour, 10 * time.Minute, 3 * time.Hour, 20 * time.Minute,
+	})
+
+	assert.Equal(t, 4, dist.Count)
+	assert.Equal(t, 270*time.Minute, dist.Total)
+	assert.Equal(t, 10*time.Minute, dist.Min)
+	assert.Equal(t, 3*time.Hour, dist.Max)
+	assert.Equal(t, 67*time.Minute+30*time.Second, dist.Mean)
+	assert.Equal(t, 40*time.Minute, dist.Median)
+	assert.Equal(t, 2*time.Hour+24*time.Minute, dist.P90)
+	assert.Equal(t, 2*time.Hour+56*time.Minute+24*time.Second, dist.P99)
+	assert.Equal(t, []int{1, 1, 0, 1, 1, 0}, dist.Histogram)
+
+	dist = NewDistribution([]time.Duration{time.Hour})
+	assert.Equal(t, time.Hour, dist.Median)
+	assert.Equal(t, time.Hour, dist.P99)
+
+	dist = NewDistribution(nil)
+	assert.Equal(t, 0, dist.Count)
+	assert.Equal(t, []int{0, 0, 0, 0, 0, 0}, dist.Histogram)
+}
+
+func TestNewWorkStats(t *testing.T) {
+	date := func(d, h, m int) time.Time {
+		return time.Date(2023, 1, d, h, m, 0, 0, time.Local)
+	}
+	records := []Record{
+		{
+			Project: "a", Start: date(2, 8, 0), End: date(2, 12, 0),
+			Pause: []Pause{{Start: date(2, 10, 0), End: date(2, 10, 30)}},
+		},
+		{Project: "b", Start: date(2, 12, 0), End: date(2, 14, 0)},
+		{Project: "a", Start: date(2, 15, 0), End: date(2, 16, 0)},
+		{Project: "a", Start: date(3, 10, 0), End: date(3, 18, 0)},
+	}
+
+	stats := NewWorkStats(records, date(4, 0, 0))
+
+	assert.Equal(t, 2, stats.Days)
+	assert.Equal(t, 9*time.Hour, stats.AverageStart)
+	assert.Equal(t, 17*time.Hour, stats.AverageEnd)
+
+	assert.Equal(t, TimeRange{Start: date(3, 10, 0), End: date(3, 18, 0)}, stats.LongestStreak)
+
+	assert.Equal(t, 2, stats.Breaks.Count)
+	assert.Equal(t, 45*time.Minute, stats.Breaks.Median)
+
+	assert.Equal(t, 3, stats.Projects["a"].Count)
+	assert.Equal(t, 12*time.Hour+30*time.Minute, stats.Projects["a"].Total)
+	assert.Equal(t, 1, stats.Projects["b"].Count)
+	assert.Equal(t, 2*time.Hour, stats.Projects["b"].Median)
+}
+
+func TestNewWorkStatsStreak(t *testing.T) {
+	date := func(h, m int) time.Time {
+		return time.Date(2023, 1, 2, h, m, 0, 0, time.Local)
+	}
+	records := []Record{
+		{
+			Project: "a", Start: date(8, 0), End: date(12, 0),
+			Pause: []Pause{{Start: date(10, 0), End: date(10, 30)}},
+		},
+		{Project: "b", Start: date(12, 0), End: date(14, 0)},
+		{Project: "a", Start: date(14, 0)},
+	}
+
+	stats := NewWorkStats(records, date(15, 0))
+	assert.Equal(t, TimeRange{Start: date(10, 30), End: date(15, 0)}, stats.LongestStreak)
+	assert.Equal(t, 1, stats.Breaks.Count)
+	assert.Equal(t, time.Hour, stats.Projects["a"].Min)
+
+	stats = NewWorkStats(nil, date(15, 0))
+	assert.Equal(t, 0, stats.Days)
+	assert.Equal(t, 0, stats.Breaks.Count)
+}
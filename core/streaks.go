@@ -0,0 +1,120 @@
+package core
+
+import (
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// Streak is a series of consecutive days
+type Streak struct {
+	// First day of the streak
+	Start time.Time
+	// Last day of the streak
+	End time.Time
+	// Number of days
+	Days int
+}
+
+// Streaks are the current and the best streak
+type Streaks struct {
+	Current Streak
+	Best    Streak
+}
+
+// DailyTimes calculates the time worked per day, clipped by day for records spanning midnight.
+// Running records are considered to end at the given time.
+func DailyTimes(records []Record, now time.Time) map[time.Time]time.Duration {
+	daily := map[time.Time]time.Duration{}
+	for i := range records {
+		records[i].forEachDay(util.NoTime, util.NoTime, now, func(day time.Time, dur time.Duration) {
+			daily[day] += dur
+		})
+	}
+	return daily
+}
+
+// forEachDay calls fn with the record's time worked on each day it overlaps, clipped to min and max.
+// A running record is considered to end at the given time.
+func (r *Record) forEachDay(min, max time.Time, now time.Time, fn func(day time.Time, dur time.Duration)) {
+	end := r.End
+	if end.IsZero() {
+		end = now
+	}
+	if !max.IsZero() && end.After(max) {
+		end = max
+	}
+	start := r.Start
+	if !min.IsZero() && start.Before(min) {
+		start = min
+	}
+	for day := util.ToDate(start); day.Before(end); day = day.AddDate(0, 0, 1) {
+		dayStart, dayEnd := day, day.AddDate(0, 0, 1)
+		if dayStart.Before(start) {
+			dayStart = start
+		}
+		if dayEnd.After(end) {
+			dayEnd = end
+		}
+		if dur := r.Duration(dayStart, dayEnd); dur > 0 {
+			fn(day, dur)
+		}
+	}
+}
+
+// NewStreaks determines streaks of consecutive days with tracked time from daily times.
+// Days count for a streak if their time is at least minTime, or above zero if minTime is zero.
+// Days in skip, like full-day absences, neither break nor extend a streak. Argument skip may be nil.
+//
+// The current streak is the streak that includes today or yesterday,
+// so the current streak is not broken before the end of today.
+func NewStreaks(daily map[time.Time]time.Duration, minTime time.Duration, today time.Time, skip map[time.Time]bool) Streaks {
+	counts := func(day time.Time) bool {
+		dur := daily[day]
+		if minTime <= 0 {
+			return dur > 0
+		}
+		return dur >= minTime
+	}
+	// next returns the next counting day in the given direction, if there are only skipped days in between
+	next := func(day time.Time, dir int) (time.Time, bool) {
+		for d := day.AddDate(0, 0, dir); ; d = d.AddDate(0, 0, dir) {
+			if counts(d) {
+				return d, true
+			}
+			if !skip[d] {
+				return d, false
+			}
+		}
+	}
+
+	streaks := Streaks{}
+	visited := map[time.Time]bool{}
+	todayDate := util.ToDate(today)
+	for day := range daily {
+		if visited[day] || !counts(day) {
+			continue
+		}
+		// Walk back to the start of the streak, then forward to its end
+		start := day
+		for prev, ok := next(start, -1); ok; prev, ok = next(start, -1) {
+			start = prev
+		}
+		streak := Streak{Start: start, End: start, Days: 1}
+		visited[start] = true
+		for d, ok := next(start, 1); ok; d, ok = next(d, 1) {
+			visited[d] = true
+			streak.End = d
+			streak.Days++
+		}
+		if streak.Days > streaks.Best.Days || (streak.Days == streaks.Best.Days && streak.End.After(streaks.Best.End)) {
+			streaks.Best = streak
+		}
+		// Current if it does not start after today, and there are only skipped days between its end and yesterday
+		after, _ := next(streak.End, 1)
+		if !streak.Start.After(todayDate) && !after.Before(todayDate) {
+			streaks.Current = streak
+		}
+	}
+	return streaks
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDailyTimes(t *testing.T) {
+	date := func(d, h int) time.Time {
+		return time.Date(2023, 1, d, h, 0, 0, 0, time.Local)
+	}
+	records := []Record{
+		{Project: "a", Start: date(2, 8), End: date(2, 12)},
+		{Project: "a", Start: date(2, 22), End: date(3, 2)},
+		{Project: "a", Start: date(4, 8)},
+	}
+
+	daily := DailyTimes(records, date(4, 11))
+	assert.Equal(t, map[time.Time]time.Duration{
+		date(2, 0): 6 * time.Hour,
+		date(3, 0): 2 * time.Hour,
+		date(4, 0): 3 * time.Hour,
+	}, daily)
+}
+
+func TestNewStreaks(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 0, 0, 0, 0, time.Local)
+	}
+	daily := map[time.Time]time.Duration{
+		day(1):  8 * time.Hour,
+		day(2):  4 * time.Hour,
+		day(3):  9 * time.Hour,
+		day(5):  8 * time.Hour,
+		day(6):  8 * time.Hour,
+		day(9):  time.Hour,
+		day(10): 8 * time.Hour,
+	}
+
+	streaks := NewStreaks(daily, 0, day(10).Add(12*time.Hour), nil)
+	assert.Equal(t, Streak{Start: day(1), End: day(3), Days: 3}, streaks.Best)
+	assert.Equal(t, Streak{Start: day(9), End: day(10), Days: 2}, streaks.Current)
+
+	streaks = NewStreaks(daily, 0, day(11), nil)
+	assert.Equal(t, 2, streaks.Current.Days, "Streak not broken before end of today")
+
+	streaks = NewStreaks(daily, 0, day(12), nil)
+	assert.Equal(t, 0, streaks.Current.Days)
+
+	streaks = NewStreaks(daily, 8*time.Hour, day(7), nil)
+	assert.Equal(t, Streak{Start: day(5), End: day(6), Days: 2}, streaks.Best)
+	assert.Equal(t, Streak{Start: day(5), End: day(6), Days: 2}, streaks.Current)
+
+	skip := map[time.Time]bool{day(4): true, day(11): true}
+	streaks = NewStreaks(daily, 0, day(12), skip)
+	assert.Equal(t, Streak{Start: day(1), End: day(6), Days: 5}, streaks.Best, "Skipped days neither break nor extend streaks")
+	assert.Equal(t, Streak{Start: day(9), End: day(10), Days: 2}, streaks.Current, "Skipped days don't break the current streak")
+
+	streaks = NewStreaks(map[time.Time]time.Duration{}, 0, day(1), nil)
+	assert.Equal(t, Streaks{}, streaks)
+}
+
+func TestNewStreaksFutureDays(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2023, 1, d, 0, 0, 0, 0, time.Local)
+	}
+	daily := map[time.Time]time.Duration{
+		day(1): 8 * time.Hour,
+		day(2): 8 * time.Hour,
+		day(5): 8 * time.Hour,
+		day(6): 8 * time.Hour,
+		day(7): 8 * time.Hour,
+	}
+
+	streaks := NewStreaks(daily, 0, day(3), nil)
+	assert.Equal(t, Streak{Start: day(1), End: day(2), Days: 2}, streaks.Current, "Streak after today is not current")
+
+	streaks = NewStreaks(daily, 0, day(4), nil)
+	assert.Equal(t, 0, streaks.Current.Days, "Streak after today is not current")
+
+	streaks = NewStreaks(daily, 0, day(6), nil)
+	assert.Equal(t, Streak{Start: day(5), End: day(7), Days: 3}, streaks.Current, "Streak including today is current")
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Syntax holds the rules for tags in notes and for comments in record files.
+// Tracks can use different rules, see Track.Syntax.
+type Syntax struct {
+	// Prefix of tags in notes
+	TagPrefix string
+	// Prefix of comments in record files
+	CommentPrefix string
+	// Normalization of tag names
+	TagNormalization TagNormalization
+}
+
+// DefaultSyntax returns the rules of the default config
+func DefaultSyntax() *Syntax {
+	return &Syntax{
+		TagPrefix:     DefaultTagPrefix,
+		CommentPrefix: DefaultCommentPrefix,
+	}
+}
+
+// Syntax returns the rules for tags in notes and for comments in record files, from the Track's config
+func (t *Track) Syntax() *Syntax {
+	return &Syntax{
+		TagPrefix:        t.Config.TagPrefix,
+		CommentPrefix:    t.Config.CommentPrefix,
+		TagNormalization: t.Config.TagNormalization,
+	}
+}
+
+// fingerprint creates a fingerprint of the rules, to detect changes
+func (s *Syntax) fingerprint() string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+	hash := fnv.New64a()
+	hash.Write(data)
+	return fmt.Sprintf("%016x", hash.Sum64())
+}
+
+// NormalizeTag normalizes a tag name, see TagNormalization
+func (s *Syntax) NormalizeTag(tag string) string {
+	return s.TagNormalization.Normalize(tag)
+}
+
+// ErrTagPrefixChanged is an error for a tag prefix that differs from the prefix the existing records were written with
+var ErrTagPrefixChanged = errors.New("tag prefix changed")
+
+// CheckTagPrefix checks that a tag prefix equals the prefix the existing records were written with.
+// Changing the prefix would silently change the tags of all records.
+//
+// The prefix of the records is stored in the root directory by NewTrack.
+// Stores without records accept any prefix.
+func (t *Track) CheckTagPrefix(prefix string) error {
+	stored, ok, err := t.storedTagPrefix()
+	if err != nil {
+		return err
+	}
+	if !ok || stored == prefix {
+		return nil
+	}
+	hasRecords, err := t.hasRecords()
+	if err != nil {
+		return err
+	}
+	if !hasRecords {
+		return nil
+	}
+	return fmt.Errorf("%w: existing records use tag prefix '%s', but config entry tagPrefix is '%s'",
+		ErrTagPrefixChanged, stored, prefix)
+}
+
+// storeTagPrefix checks the tag prefix of the config, and stores it for later checks.
+// Stores without a stored prefix adopt the prefix of the config, as their records were written with it.
+func (t *Track) storeTagPrefix() error {
+	if err := t.CheckTagPrefix(t.Config.TagPrefix); err != nil {
+		return err
+	}
+	stored, ok, err := t.storedTagPrefix()
+	if err != nil {
+		return err
+	}
+	if t.ReadOnly || (ok && stored == t.Config.TagPrefix) {
+		return nil
+	}
+	return t.FileSystem().WriteFile(t.tagPrefixPath(), []byte(t.Config.TagPrefix+"\n"), 0644)
+}
+
+// storedTagPrefix reads the stored tag prefix. Returns false if there is none.
+func (t *Track) storedTagPrefix() (string, bool, error) {
+	data, err := t.FileSystem().ReadFile(t.tagPrefixPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return strings.TrimSpace(string(data)), true, nil
+}
+
+// hasRecords checks whether any workspace contains records
+func (t *Track) hasRecords() (bool, error) {
+	workspaces, err := t.AllWorkspaces()
+	if err != nil {
+		return false, err
+	}
+	for _, ws := range workspaces {
+		dir := filepath.Join(t.WorkspaceDir(ws), recordsDirName)
+		if !t.dirExists(dir) {
+			continue
+		}
+		empty, err := t.dirIsEmpty(dir)
+		if err != nil {
+			return false, err
+		}
+		if !empty {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// tagPrefixPath returns the path of the file with the tag prefix of the records
+func (t *Track) tagPrefixPath() string {
+	return filepath.Join(t.RootDir, tagPrefixFile)
+}
@@ -0,0 +1,122 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TagNormalization are rules for normalizing tag names, so that e.g. "+Meeting" and "+meeting" are the same tag.
+// Rules are applied in the order lowercasing, folding of diacritics, synonyms.
+type TagNormalization struct {
+	// Whether to convert tags to lower case
+	Lowercase bool `yaml:"lowercase"`
+	// Whether to replace letters with diacritics by their base letters, like "é" by "e"
+	FoldDiacritics bool `yaml:"foldDiacritics"`
+	// Canonical tag names for synonyms, like "mtg: meeting"
+	Synonyms map[string]string `yaml:"synonyms,omitempty"`
+}
+
+// IsEnabled checks if any normalization rule is enabled
+func (n *TagNormalization) IsEnabled() bool {
+	return n.Lowercase || n.FoldDiacritics || len(n.Synonyms) > 0
+}
+
+// Normalize normalizes a tag name.
+// Synonyms are looked up by their normalized form, so they match regardless of case and diacritics if these rules are enabled.
+func (n *TagNormalization) Normalize(tag string) string {
+	tag = n.normalizeText(tag)
+	for synonym, canonical := range n.Synonyms {
+		if n.normalizeText(synonym) == tag {
+			return canonical
+		}
+	}
+	return tag
+}
+
+// NormalizeTags normalizes the keys of a tag map.
+// Fails if different tags with different values are normalized to the same tag.
+func (n *TagNormalization) NormalizeTags(tags map[string]string) (map[string]string, error) {
+	if !n.IsEnabled() || len(tags) == 0 {
+		return tags, nil
+	}
+	result := make(map[string]string, len(tags))
+	for tag, value := range tags {
+		key := n.Normalize(tag)
+		if old, ok := result[key]; ok && value != old {
+			return nil, fmt.Errorf("tag '%s' already has value '%s'", key, old)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// Check checks the synonyms for valid tag names
+func (n *TagNormalization) Check() error {
+	for synonym, canonical := range n.Synonyms {
+		for _, tag := range []string{synonym, canonical} {
+			if tag == "" || strings.ContainsAny(tag, " =") {
+				return fmt.Errorf("invalid tag '%s' in synonyms", tag)
+			}
+		}
+	}
+	return nil
+}
+
+func (n *TagNormalization) normalizeText(tag string) string {
+	if n.Lowercase {
+		tag = strings.ToLower(tag)
+	}
+	if n.FoldDiacritics {
+		tag = foldDiacritics(tag)
+	}
+	return tag
+}
+
+// foldDiacritics replaces Latin letters with diacritics by their base letters
+func foldDiacritics(text string) string {
+	sb := strings.Builder{}
+	for _, r := range text {
+		if base, ok := diacriticFolds[r]; ok && r > unicode.MaxASCII {
+			sb.WriteString(base)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// diacriticFolds maps Latin letters with diacritics to their base letters
+var diacriticFolds = newDiacriticFolds()
+
+func newDiacriticFolds() map[rune]string {
+	folds := map[rune]string{}
+	groups := map[string]string{
+		"a": "àáâãäåāăą", "A": "ÀÁÂÃÄÅĀĂĄ",
+		"c": "çćĉċč", "C": "ÇĆĈĊČ",
+		"d": "ďđ", "D": "ĎĐ",
+		"e": "èéêëēĕėęě", "E": "ÈÉÊËĒĔĖĘĚ",
+		"g": "ĝğġģ", "G": "ĜĞĠĢ",
+		"h": "ĥħ", "H": "ĤĦ",
+		"i": "ìíîïĩīĭįı", "I": "ÌÍÎÏĨĪĬĮİ",
+		"j": "ĵ", "J": "Ĵ",
+		"k": "ķ", "K": "Ķ",
+		"l": "ĺļľŀł", "L": "ĹĻĽĿŁ",
+		"n": "ñńņňŉ", "N": "ÑŃŅŇ",
+		"o": "òóôõöøōŏő", "O": "ÒÓÔÕÖØŌŎŐ",
+		"r": "ŕŗř", "R": "ŔŖŘ",
+		"s": "śŝşš", "S": "ŚŜŞŠ",
+		"t": "ţťŧ", "T": "ŢŤŦ",
+		"u": "ùúûüũūŭůűų", "U": "ÙÚÛÜŨŪŬŮŰŲ",
+		"w": "ŵ", "W": "Ŵ",
+		"y": "ýÿŷ", "Y": "ÝŸŶ",
+		"z": "źżž", "Z": "ŹŻŽ",
+		"ss": "ß",
+	}
+	for base, letters := range groups {
+		for _, r := range letters {
+			folds[r] = base
+		}
+	}
+	return folds
+}
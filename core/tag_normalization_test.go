@@ -0,0 +1,71 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagNormalization(t *testing.T) {
+	n := TagNormalization{
+		Lowercase:      true,
+		FoldDiacritics: true,
+		Synonyms:       map[string]string{"Mtg": "meeting"},
+	}
+	assert.Nil(t, n.Check())
+
+	assert.Equal(t, "meeting", n.Normalize("Meeting"))
+	assert.Equal(t, "meeting", n.Normalize("mtg"))
+	assert.Equal(t, "meeting", n.Normalize("MTG"))
+	assert.Equal(t, "cafe/resume", n.Normalize("Café/Résumé"))
+	assert.Equal(t, "strasse", n.Normalize("Straße"))
+
+	tags, err := n.NormalizeTags(map[string]string{"Meeting": "", "mtg": "", "Bug": "1"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"meeting": "", "bug": "1"}, tags)
+
+	_, err = n.NormalizeTags(map[string]string{"Meeting": "a", "meeting": "b"})
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, (&TagNormalization{Synonyms: map[string]string{"a b": "c"}}).Check())
+	assert.NotNil(t, (&TagNormalization{Synonyms: map[string]string{"a": ""}}).Check())
+
+	disabled := TagNormalization{}
+	assert.Equal(t, "Meeting", disabled.Normalize("Meeting"))
+}
+
+func TestTagNormalizationOnLoad(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+
+	start := time.Date(2023, 5, 4, 9, 0, 0, 0, time.Local)
+	for i, format := range []string{FileFormatText, FileFormatYAML, FileFormatJSON} {
+		track.Config.TagNormalization = TagNormalization{}
+		track.Config.RecordFileFormat = format
+		rec := Record{
+			Project: "test",
+			Start:   start.Add(time.Duration(i) * time.Hour),
+			End:     start.Add(time.Duration(i)*time.Hour + 30*time.Minute),
+			Note:    "Call +Meeting",
+			Tags:    map[string]string{"Meeting": ""},
+		}
+		assert.Nil(t, track.SaveRecord(&rec, false), "Error saving record")
+
+		track.Config.TagNormalization = TagNormalization{Lowercase: true}
+		loaded, err := track.LoadRecord(rec.Start)
+		assert.Nil(t, err, "Error loading record")
+		assert.Equal(t, map[string]string{"meeting": ""}, loaded.Tags, "Wrong tags for format %s", format)
+	}
+
+	tags, err := track.Syntax().ExtractTags("Call +MEETING +Bug=1")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"meeting": "", "bug": "1"}, tags)
+
+	// Other tracks keep their own rules
+	other, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+	tags, err = other.Syntax().ExtractTags("Call +MEETING")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"MEETING": ""}, tags)
+}
@@ -0,0 +1,235 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// TagSeparator separates levels of hierarchical tags, like "meeting/standup"
+const TagSeparator = "/"
+
+// TagName is a tag name, implementing the Named interface required for the MapTree
+type TagName string
+
+// GetName implements the Named interface required for the MapTree
+func (t TagName) GetName() string {
+	return string(t)
+}
+
+// TagTree is a tree of hierarchical tags
+type TagTree = util.MapTree[TagName]
+
+// TagNode is a node in a tree of hierarchical tags
+type TagNode = util.MapNode[TagName]
+
+// TagAncestors returns all ancestors of a hierarchical tag, starting with the direct parent.
+// E.g., for "a/b/c", it returns ["a/b", "a"].
+func TagAncestors(tag string) []string {
+	result := []string{}
+	for {
+		idx := strings.LastIndex(tag, TagSeparator)
+		if idx <= 0 {
+			return result
+		}
+		tag = tag[:idx]
+		result = append(result, tag)
+	}
+}
+
+// IsTagOrDescendant checks if a tag is equal to another tag, or a descendant of it.
+func IsTagOrDescendant(tag, ancestor string) bool {
+	return tag == ancestor || strings.HasPrefix(tag, ancestor+TagSeparator)
+}
+
+// ExpandTagHierarchy returns the given tags, extended by all ancestors of hierarchical tags.
+// Ancestors that are not contained in the given tags have an empty value.
+func ExpandTagHierarchy(tags map[string]string) map[string]string {
+	result := make(map[string]string, len(tags))
+	for tag, value := range tags {
+		result[tag] = value
+	}
+	for tag := range tags {
+		for _, anc := range TagAncestors(tag) {
+			if _, ok := result[anc]; !ok {
+				result[anc] = ""
+			}
+		}
+	}
+	return result
+}
+
+// NewTagTree creates a tree of the given hierarchical tags, including all their ancestors.
+// The root node has an empty name.
+func NewTagTree(tags []string) *TagTree {
+	tree := util.NewTree(TagName(""))
+	all := map[string]string{}
+	for _, tag := range tags {
+		all[tag] = ""
+	}
+	all = ExpandTagHierarchy(all)
+
+	names := make([]string, 0, len(all))
+	for tag := range all {
+		names = append(names, tag)
+	}
+	// Sorting guarantees that parents are added before their children
+	sort.Strings(names)
+
+	for _, tag := range names {
+		parent := tree.Root
+		if anc := TagAncestors(tag); len(anc) > 0 {
+			parent = tree.Nodes[anc[0]]
+		}
+		// Can't fail, as names are unique
+		_, _ = tree.Add(parent, TagName(tag))
+	}
+	return tree
+}
+
+// TagStats holds usage statistics of a tag
+type TagStats struct {
+	Name     string
+	Count    int
+	First    time.Time
+	Last     time.Time
+	Duration time.Duration
+	Values   map[string]int
+}
+
+// TagStatistics collects usage statistics for all tags of records matching the given filters.
+//
+// First and Last refer to the start times of the first and last record using the tag.
+// Duration is the total work time of these records, excluding pauses.
+// Values contains the number of records per tag value.
+// The returned slice is sorted by tag name.
+func (t *Track) TagStatistics(filters FilterFunctions) ([]TagStats, error) {
+	fn, results, _ := t.AllRecordsFiltered(filters, false)
+	go fn()
+
+	stats := map[string]*TagStats{}
+	for res := range results {
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		rec := &res.Record
+		for tag, value := range rec.Tags {
+			st, ok := stats[tag]
+			if !ok {
+				st = &TagStats{
+					Name:   tag,
+					First:  rec.Start,
+					Values: map[string]int{},
+				}
+				stats[tag] = st
+			}
+			if rec.Start.Before(st.First) {
+				st.First = rec.Start
+			}
+			if rec.Start.After(st.Last) {
+				st.Last = rec.Start
+			}
+			st.Count++
+			st.Duration += rec.Duration(util.NoTime, util.NoTime)
+			st.Values[value]++
+		}
+	}
+
+	result := make([]TagStats, 0, len(stats))
+	for _, st := range stats {
+		result = append(result, *st)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+// RenameTag renames a tag in all records, including the tag tokens in the records' notes.
+// Values of tags are preserved.
+// Renaming is all-or-nothing, see BulkEdit.
+//
+// Returns the number of records that were changed.
+func (t *Track) RenameTag(oldName, newName string) (int, error) {
+	if err := t.CheckWritable(); err != nil {
+		return 0, err
+	}
+	if err := checkTagName(newName, t.Config.TagPrefix); err != nil {
+		return 0, err
+	}
+	if oldName == newName {
+		return 0, fmt.Errorf("new tag name equals old tag name")
+	}
+
+	filters := NewFilter(
+		[]FilterFunction{
+			FilterByTagsAny([]util.Pair[string, string]{util.NewPair(oldName, "")}),
+		}, util.NoTime, util.NoTime,
+	)
+	filters.IncludeArchived = true
+	return t.BulkEdit(filters, func(r *Record) (bool, error) {
+		if _, ok := r.Tags[oldName]; !ok {
+			return false, nil
+		}
+		return true, renameRecordTag(t.Syntax(), r, oldName, newName)
+	})
+}
+
+// renameRecordTag renames a tag in the tags and in the note of a record.
+// Tag tokens in the note are compared by their normalized name, like the record's tags.
+func renameRecordTag(syntax *Syntax, record *Record, oldName, newName string) error {
+	value, ok := record.Tags[oldName]
+	if !ok {
+		return nil
+	}
+	if newValue, ok := record.Tags[newName]; ok && newValue != value {
+		return fmt.Errorf("tag '%s' already has value '%s'", newName, newValue)
+	}
+
+	lines := strings.Split(record.Note, "\n")
+	for i, line := range lines {
+		tokens := strings.Split(line, " ")
+		for j, token := range tokens {
+			if !strings.HasPrefix(token, syntax.TagPrefix) {
+				continue
+			}
+			key, val := ParseTag(strings.TrimPrefix(token, syntax.TagPrefix))
+			if syntax.NormalizeTag(key) != oldName {
+				continue
+			}
+			if val == "" && !strings.Contains(token, "=") {
+				tokens[j] = syntax.TagPrefix + newName
+			} else {
+				tokens[j] = fmt.Sprintf("%s%s=%s", syntax.TagPrefix, newName, val)
+			}
+		}
+		lines[i] = strings.Join(tokens, " ")
+	}
+	record.Note = strings.Join(lines, "\n")
+
+	delete(record.Tags, oldName)
+	record.Tags[newName] = value
+
+	return nil
+}
+
+// checkTagName checks whether a string is a valid tag name
+func checkTagName(name, tagPrefix string) error {
+	if name == "" {
+		return fmt.Errorf("tag name must not be empty")
+	}
+	if strings.ContainsAny(name, " \t\n\r=") {
+		return fmt.Errorf("tag name must not contain whitespace or '='")
+	}
+	if strings.HasPrefix(name, tagPrefix) {
+		return fmt.Errorf("tag name must not start with '%s'", tagPrefix)
+	}
+	for _, part := range strings.Split(name, TagSeparator) {
+		if part == "" {
+			return fmt.Errorf("hierarchical tag name must not have empty levels")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameTag(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	records := []Record{
+		{
+			Project: "test",
+			Start:   util.DateTime(2001, 2, 3, 8, 0, 0),
+			End:     util.DateTime(2001, 2, 3, 9, 0, 0),
+			Note:    "Note with +foo and +bar\nand +baz",
+			Tags:    map[string]string{"foo": "", "bar": "", "baz": ""},
+		},
+		{
+			Project: "test",
+			Start:   util.DateTime(2001, 2, 3, 10, 0, 0),
+			End:     util.DateTime(2001, 2, 3, 11, 0, 0),
+			Note:    "Note with\n+foo=1 and +foobar",
+			Tags:    map[string]string{"foo": "1", "foobar": ""},
+		},
+		{
+			Project: "test",
+			Start:   util.DateTime(2001, 2, 4, 10, 0, 0),
+			End:     util.DateTime(2001, 2, 4, 11, 0, 0),
+			Note:    "Note with +bar",
+			Tags:    map[string]string{"bar": ""},
+		},
+	}
+	for _, rec := range records {
+		err = track.SaveRecord(&rec, false)
+		assert.Nil(t, err, "Error saving record")
+	}
+
+	_, err = track.RenameTag("foo", "new tag")
+	assert.NotNil(t, err, "Expected error for invalid tag name")
+	_, err = track.RenameTag("foo", "foo")
+	assert.NotNil(t, err, "Expected error for equal tag names")
+
+	count, err := track.RenameTag("foo", "qux")
+	assert.Nil(t, err, "Error renaming tag")
+	assert.Equal(t, 2, count, "Wrong number of changed records")
+
+	all, err := track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+
+	assert.Equal(t, "Note with +qux and +bar\nand +baz", all[0].Note, "Wrong note")
+	assert.Equal(t, map[string]string{"qux": "", "bar": "", "baz": ""}, all[0].Tags, "Wrong tags")
+	assert.Equal(t, "Note with\n+qux=1 and +foobar", all[1].Note, "Wrong note")
+	assert.Equal(t, map[string]string{"qux": "1", "foobar": ""}, all[1].Tags, "Wrong tags")
+	assert.Equal(t, records[2].Note, all[2].Note, "Record should not be changed")
+
+	count, err = track.RenameTag("foo", "xyz")
+	assert.Nil(t, err, "Error renaming tag")
+	assert.Equal(t, 0, count, "Wrong number of changed records")
+}
+
+func TestRenameRecordTagConflict(t *testing.T) {
+	record := Record{
+		Note: "+foo=1 +bar=2",
+		Tags: map[string]string{"foo": "1", "bar": "2"},
+	}
+	err := renameRecordTag(DefaultSyntax(), &record, "foo", "bar")
+	assert.NotNil(t, err, "Expected error for conflicting tag values")
+
+	record = Record{
+		Note: "+foo=1 +bar=1",
+		Tags: map[string]string{"foo": "1", "bar": "1"},
+	}
+	err = renameRecordTag(DefaultSyntax(), &record, "foo", "bar")
+	assert.Nil(t, err, "Error renaming tag")
+	assert.Equal(t, "+bar=1 +bar=1", record.Note, "Wrong note")
+	assert.Equal(t, map[string]string{"bar": "1"}, record.Tags, "Wrong tags")
+
+	record = Record{
+		Note: "Weekly +Meeting",
+		Tags: map[string]string{"meeting": ""},
+	}
+	err = renameRecordTag(&Syntax{TagPrefix: DefaultTagPrefix, CommentPrefix: DefaultCommentPrefix, TagNormalization: TagNormalization{Lowercase: true}}, &record, "meeting", "sync")
+	assert.Nil(t, err, "Error renaming tag")
+	assert.Equal(t, "Weekly +sync", record.Note, "Normalized tag should be renamed in the note")
+	assert.Equal(t, map[string]string{"sync": ""}, record.Tags, "Wrong tags")
+}
+
+func TestTagStatistics(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	records := []Record{
+		{
+			Project: "test",
+			Start:   util.DateTime(2001, 2, 3, 8, 0, 0),
+			End:     util.DateTime(2001, 2, 3, 9, 0, 0),
+			Note:    "+foo +bar=1",
+			Tags:    map[string]string{"foo": "", "bar": "1"},
+		},
+		{
+			Project: "test",
+			Start:   util.DateTime(2001, 2, 5, 10, 0, 0),
+			End:     util.DateTime(2001, 2, 5, 10, 30, 0),
+			Note:    "+bar=2",
+			Tags:    map[string]string{"bar": "2"},
+		},
+		{
+			Project: "test",
+			Start:   util.DateTime(2001, 2, 7, 10, 0, 0),
+			End:     util.DateTime(2001, 2, 7, 12, 0, 0),
+			Note:    "+bar=1",
+			Tags:    map[string]string{"bar": "1"},
+		},
+	}
+	for _, rec := range records {
+		err = track.SaveRecord(&rec, false)
+		assert.Nil(t, err, "Error saving record")
+	}
+
+	stats, err := track.TagStatistics(FilterFunctions{})
+	assert.Nil(t, err, "Error collecting tag statistics")
+
+	assert.Equal(t, []TagStats{
+		{
+			Name:     "bar",
+			Count:    3,
+			First:    records[0].Start,
+			Last:     records[2].Start,
+			Duration: 3*time.Hour + 30*time.Minute,
+			Values:   map[string]int{"1": 2, "2": 1},
+		},
+		{
+			Name:     "foo",
+			Count:    1,
+			First:    records[0].Start,
+			Last:     records[0].Start,
+			Duration: time.Hour,
+			Values:   map[string]int{"": 1},
+		},
+	}, stats, "Wrong tag statistics")
+}
+
+func TestTagHierarchy(t *testing.T) {
+	assert.Equal(t, []string{}, TagAncestors("a"), "Wrong ancestors")
+	assert.Equal(t, []string{"a/b", "a"}, TagAncestors("a/b/c"), "Wrong ancestors")
+
+	assert.True(t, IsTagOrDescendant("a", "a"), "Tag should match itself")
+	assert.True(t, IsTagOrDescendant("a/b", "a"), "Tag should match descendant")
+	assert.False(t, IsTagOrDescendant("ab", "a"), "Tag should not match other tag")
+	assert.False(t, IsTagOrDescendant("a", "a/b"), "Tag should not match ancestor")
+
+	assert.Equal(t,
+		map[string]string{"a/b/c": "1", "a/b": "", "a": "2", "x": ""},
+		ExpandTagHierarchy(map[string]string{"a/b/c": "1", "a": "2", "x": ""}),
+		"Wrong expanded tags",
+	)
+
+	tree := NewTagTree([]string{"x", "a/b/c", "a/d"})
+	assert.Equal(t, 6, len(tree.Nodes), "Wrong number of nodes")
+	assert.Equal(t, 2, len(tree.Root.Children), "Wrong number of root children")
+	assert.Equal(t, TagName("a/b"), tree.Nodes["a/b/c"].Parent.Value, "Wrong parent")
+	assert.Equal(t, 2, len(tree.Nodes["a"].Children), "Wrong number of children")
+
+	assert.NotNil(t, checkTagName("a//b", DefaultTagPrefix), "Expected error for empty tag level")
+	assert.NotNil(t, checkTagName("a/", DefaultTagPrefix), "Expected error for empty tag level")
+	assert.Nil(t, checkTagName("a/b", DefaultTagPrefix), "Unexpected error for hierarchical tag")
+}
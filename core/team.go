@@ -0,0 +1,122 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+// OpenTeam opens the track directories of all team members from config entry Team
+func (t *Track) OpenTeam() (map[string]*Track, error) {
+	if len(t.Config.Team) == 0 {
+		return nil, fmt.Errorf("no team members. Set config entry team")
+	}
+	members := make(map[string]*Track, len(t.Config.Team))
+	for name, dir := range t.Config.Team {
+		member, err := OpenReadOnly(dir)
+		if err != nil {
+			return nil, fmt.Errorf("team member '%s': %s", name, err)
+		}
+		members[name] = member
+	}
+	return members, nil
+}
+
+// TeamReporter combines the reports of the members of a team
+type TeamReporter struct {
+	// Member names, sorted
+	Members []string
+	// Reporters per member. Missing for members that have none of the requested projects
+	Reporters map[string]*Reporter
+	// Time per project and member, excluding sub-projects
+	ProjectTime map[string]map[string]time.Duration
+	// Total time per member
+	MemberTime map[string]time.Duration
+}
+
+// NewTeamReporter creates a TeamReporter from the track directories of team members.
+// Filters and time range are as for NewStreamingReporter.
+//
+// Projects are matched by their exact name. Requested projects that a member does not have are ignored for that member.
+// Fails for requested projects that no member has.
+func NewTeamReporter(
+	members map[string]*Track, proj []string,
+	filters FilterFunctions, includeArchived bool,
+	start, end time.Time,
+) (*TeamReporter, error) {
+	names := maps.Keys(members)
+	sort.Strings(names)
+
+	team := TeamReporter{
+		Members:     names,
+		Reporters:   map[string]*Reporter{},
+		ProjectTime: map[string]map[string]time.Duration{},
+		MemberTime:  map[string]time.Duration{},
+	}
+	memberProjects := map[string][]string{}
+	if len(proj) > 0 {
+		found := map[string]bool{}
+		for _, name := range names {
+			allProjects, err := members[name].LoadAllProjects()
+			if err != nil {
+				return nil, fmt.Errorf("team member '%s': %s", name, err)
+			}
+			memberProjects[name] = []string{}
+			for _, p := range proj {
+				if _, ok := allProjects[p]; ok {
+					memberProjects[name] = append(memberProjects[name], p)
+					found[p] = true
+				}
+			}
+		}
+		for _, p := range proj {
+			if !found[p] {
+				return nil, fmt.Errorf("no team member has project '%s'", p)
+			}
+		}
+	}
+
+	for _, name := range names {
+		member := members[name]
+		team.MemberTime[name] = 0
+
+		memberProj := proj
+		if len(proj) > 0 {
+			memberProj = memberProjects[name]
+			if len(memberProj) == 0 {
+				continue
+			}
+		}
+
+		reporter, err := NewStreamingReporter(member, memberProj, filters, includeArchived, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("team member '%s': %s", name, err)
+		}
+		team.Reporters[name] = reporter
+
+		for project, dur := range reporter.ProjectTime {
+			if dur == 0 {
+				continue
+			}
+			perMember, ok := team.ProjectTime[project]
+			if !ok {
+				perMember = map[string]time.Duration{}
+				team.ProjectTime[project] = perMember
+			}
+			perMember[name] += dur
+			team.MemberTime[name] += dur
+		}
+	}
+	return &team, nil
+}
+
+// Total returns the total time of all members
+func (t *TeamReporter) Total() time.Duration {
+	var total time.Duration
+	for _, dur := range t.MemberTime {
+		total += dur
+	}
+	return total
+}
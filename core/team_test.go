@@ -0,0 +1,79 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamReporter(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	if err != nil {
+		t.Fatal("error creating temporary directory")
+	}
+	defer os.RemoveAll(dir)
+
+	tracks := map[string]*Track{}
+	for _, name := range []string{"alice", "bob"} {
+		path := filepath.Join(dir, name)
+		track, err := NewTrack(&path)
+		if err != nil {
+			t.Fatal("error creating Track instance")
+		}
+		assert.Nil(t, track.SaveProject(NewProject("app", "", "A", []string{}, 0, 15), false))
+		tracks[name] = &track
+	}
+	assert.Nil(t, tracks["alice"].SaveProject(NewProject("ops", "", "O", []string{}, 0, 15), false))
+
+	day := util.Date(2023, 1, 2)
+	records := map[string][]Record{
+		"alice": {
+			{Project: "app", Start: day.Add(9 * time.Hour), End: day.Add(12 * time.Hour)},
+			{Project: "ops", Start: day.Add(13 * time.Hour), End: day.Add(14 * time.Hour)},
+		},
+		"bob": {
+			{Project: "app", Start: day.Add(8 * time.Hour), End: day.Add(10 * time.Hour)},
+		},
+	}
+	for name, recs := range records {
+		for i := range recs {
+			assert.Nil(t, tracks[name].SaveRecord(&recs[i], false))
+		}
+	}
+
+	members := map[string]*Track{}
+	for name := range tracks {
+		member, err := OpenReadOnly(filepath.Join(dir, name))
+		assert.Nil(t, err)
+		assert.True(t, member.ReadOnly)
+		members[name] = member
+	}
+
+	team, err := NewTeamReporter(members, []string{}, NewFilter([]FilterFunction{}, util.NoTime, util.NoTime), false, util.NoTime, util.NoTime)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, team.Members)
+	assert.Equal(t, map[string]time.Duration{"alice": 3 * time.Hour, "bob": 2 * time.Hour}, team.ProjectTime["app"])
+	assert.Equal(t, map[string]time.Duration{"alice": time.Hour}, team.ProjectTime["ops"])
+	assert.Equal(t, 4*time.Hour, team.MemberTime["alice"])
+	assert.Equal(t, 6*time.Hour, team.Total())
+
+	team, err = NewTeamReporter(members, []string{"ops"}, NewFilter([]FilterFunction{}, util.NoTime, util.NoTime), false, util.NoTime, util.NoTime)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), team.MemberTime["bob"])
+	assert.Equal(t, time.Hour, team.Total())
+	_, ok := team.Reporters["bob"]
+	assert.False(t, ok)
+
+	// Projects are matched by exact name only
+	for _, name := range []string{"op", "opps", "unknown"} {
+		_, err = NewTeamReporter(members, []string{name}, NewFilter([]FilterFunction{}, util.NoTime, util.NoTime), false, util.NoTime, util.NoTime)
+		assert.NotNil(t, err, "Expected error for project '%s'", name)
+	}
+
+	_, err = OpenReadOnly(filepath.Join(dir, "carol"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,42 @@
+package core
+
+import "context"
+
+// Tracer creates spans for tracing where time is spent, like in walks over the records directory,
+// in parsing of record files and in report generation.
+//
+// Implement Tracer and Span to forward spans to a tracing system like OpenTelemetry.
+type Tracer interface {
+	// Start starts a span, as a child of the span in ctx if there is one.
+	// Returns a context that contains the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a traced operation, created by a Tracer
+type Span interface {
+	// SetAttribute sets an attribute of the span, like the number of records loaded
+	SetAttribute(key string, value interface{})
+	// End ends the span
+	End()
+}
+
+// noopTracer is used by a Track without a Tracer
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+// noopSpan is a span that does nothing
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+func (noopSpan) End()                                       {}
+
+// startSpan starts a span with the Track's Tracer
+func (t *Track) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if t.Tracer == nil {
+		return noopTracer{}.Start(ctx, name)
+	}
+	return t.Tracer.Start(ctx, name)
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+type testSpan struct {
+	name       string
+	parent     string
+	attributes map[string]interface{}
+	ended      bool
+	tracer     *testTracer
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+	s.attributes[key] = value
+}
+
+func (s *testSpan) End() {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+	s.ended = true
+}
+
+type testTracer struct {
+	mutex sync.Mutex
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	span := &testSpan{name: name, attributes: map[string]interface{}{}, tracer: t}
+	if parent, ok := ctx.Value(spanKey{}).(*testSpan); ok {
+		span.parent = parent.name
+	}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func TestTracing(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	assert.Nil(t, track.SaveProject(NewProject("test", "", "T", []string{}, 0, 0), false), "Error saving project")
+
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 4, 8, 0, 0), End: util.DateTime(2001, 2, 4, 9, 0, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false), "Error saving record")
+	}
+
+	tracer := testTracer{}
+	track.Tracer = &tracer
+
+	_, err = NewReporter(&track, []string{}, FilterFunctions{}, false, util.NoTime, util.NoTime)
+	assert.Nil(t, err, "Error creating reporter")
+
+	spans := map[string][]*testSpan{}
+	for _, span := range tracer.spans {
+		assert.True(t, span.ended, "Span %s not ended", span.name)
+		spans[span.name] = append(spans[span.name], span)
+	}
+	assert.Equal(t, 1, len(spans["track.report"]), "Wrong number of report spans")
+	assert.Equal(t, 1, len(spans["track.walk"]), "Wrong number of walk spans")
+	assert.Equal(t, 2, len(spans["track.readDay"]), "Wrong number of read spans")
+
+	walk := spans["track.walk"][0]
+	assert.Equal(t, "track.report", walk.parent, "Wrong parent span")
+	assert.Equal(t, 2, walk.attributes["days"], "Wrong number of days")
+	assert.Equal(t, 2, walk.attributes["records"], "Wrong number of records")
+
+	read := spans["track.readDay"][0]
+	assert.Equal(t, "track.walk", read.parent, "Wrong parent span")
+	assert.Equal(t, 1, read.attributes["records"], "Wrong number of records")
+}
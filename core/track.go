@@ -1,10 +1,12 @@
 package core
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
-	"github.com/mlange-42/track/util"
+	"golang.org/x/exp/slog"
 )
 
 const (
@@ -12,60 +14,137 @@ const (
 	projectsDirName = "projects"
 	recordsDirName  = "records"
 	configFile      = "config.yml"
+	reportCacheFile = "report-cache.json"
+	journalFile     = "journal.json"
+	deletionsFile   = "deletions.jsonl"
+	invoicesFile    = "invoices.yml"
+	absencesFile    = "absences.yml"
+	breaksFile      = "breaks.yml"
+	tagPrefixFile   = "tag-prefix"
 	trackPathEnvVar = "TRACK_PATH"
+	readOnlyEnvVar  = "TRACK_READ_ONLY"
 )
 
+// ErrReadOnly is an error for attempted changes in read-only mode
+var ErrReadOnly = errors.New("track is in read-only mode")
+
 // Track is a top-level track instance
 type Track struct {
 	RootDir string
 	Config  Config
+	// ReadOnly rejects all changes to records, projects and the config
+	ReadOnly bool
+	// Logger for diagnostics, like the directory walk, parsing of record files and filter decisions.
+	// Nothing is logged if nil.
+	Logger *slog.Logger
+	// Tracer for spans around record loading, parsing and report generation.
+	// Nothing is traced if nil.
+	Tracer Tracer
+	// FS is the storage backend. Uses the OSFileSystem if nil.
+	FS FileSystem
+
+	hooks        []namedHook
+	aggregations []namedAggregation
 }
 
-// NewTrack creates a new Track object
+// NewTrack creates a new Track object.
+// Uses the given root directory, or the data directory from DataDir if root is nil.
 func NewTrack(root *string) (Track, error) {
+	if root != nil {
+		return NewTrackWithFS(*root, OSFileSystem{})
+	}
+	dir, err := DataDir()
+	if err != nil {
+		return Track{}, fmt.Errorf("failed to determine data directory: %s", err)
+	}
+	return NewTrackWithFS(dir, OSFileSystem{})
+}
+
+// NewTrackWithFS creates a new Track object, with its root directory in the given FileSystem
+func NewTrackWithFS(root string, fsys FileSystem) (Track, error) {
 	track := Track{
-		RootDir: getRootDir(root),
+		RootDir:  root,
+		ReadOnly: readOnlyFromEnv(),
+		FS:       fsys,
+	}
+	if err := track.createRootDir(); err != nil {
+		return track, err
 	}
-	track.createRootDir()
 
-	conf, err := LoadConfig(track.ConfigPath())
+	conf, err := track.loadConfig()
 	if err != nil {
 		return track, err
 	}
 
 	track.Config = conf
+	if err := track.storeTagPrefix(); err != nil {
+		return track, err
+	}
 	track.createWorkspaceDirs(track.Config.Workspace)
 
 	return track, nil
 }
 
-func getRootDir(root *string) string {
-	if root != nil {
-		return *root
-	}
-	if path, ok := os.LookupEnv(trackPathEnvVar); ok {
-		return path
+// NewMemoryTrack creates a new Track object with an empty in-memory storage, e.g. for tests.
+// Nothing is read from or written to the file system.
+func NewMemoryTrack() (Track, error) {
+	return NewTrackWithFS(memoryRootDir, NewMemoryFileSystem())
+}
+
+// OpenReadOnly opens another track directory in read-only mode, like the directory of a team member on a network drive.
+// Uses the directory's own config, and thus its current workspace.
+func OpenReadOnly(dir string) (*Track, error) {
+	track := Track{RootDir: dir, ReadOnly: true, FS: ReadOnlyFileSystem{FS: OSFileSystem{}}}
+	if !track.dirExists(dir) {
+		return nil, fmt.Errorf("track directory '%s' not found", dir)
 	}
-	home, err := os.UserHomeDir()
+	conf, err := tryLoadConfig(track.FileSystem(), track.ConfigPath())
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("invalid track directory '%s': %s", dir, err)
 	}
-	return filepath.Join(home, rootDirName)
+	track.Config = conf
+	if !track.dirExists(track.WorkspaceDir(track.Workspace())) {
+		return nil, fmt.Errorf("workspace '%s' not found in track directory '%s'", track.Workspace(), dir)
+	}
+	return &track, nil
 }
 
-func (t *Track) createRootDir() {
-	err := util.CreateDir(t.RootDir)
-	if err != nil {
-		panic(err)
+// readOnlyFromEnv checks if read-only mode is enabled by environment variable TRACK_READ_ONLY
+func readOnlyFromEnv() bool {
+	value, ok := os.LookupEnv(readOnlyEnvVar)
+	if !ok {
+		return false
+	}
+	switch value {
+	case "", "0", "false", "no":
+		return false
+	default:
+		return true
+	}
+}
+
+// CheckWritable returns ErrReadOnly if the Track is in read-only mode
+func (t *Track) CheckWritable() error {
+	if t.ReadOnly {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// createRootDir creates the root directory, and fails if it can't be created or is not a directory
+func (t *Track) createRootDir() error {
+	if err := t.createDir(t.RootDir); err != nil {
+		return fmt.Errorf("failed to create data directory '%s': %s", t.RootDir, err)
 	}
+	return nil
 }
 
 func (t *Track) createWorkspaceDirs(workspace string) {
-	err := util.CreateDir(t.workspaceProjectsDir(workspace))
+	err := t.createDir(t.workspaceProjectsDir(workspace))
 	if err != nil {
 		panic(err)
 	}
-	err = util.CreateDir(t.workspaceRecordsDir(workspace))
+	err = t.createDir(t.workspaceRecordsDir(workspace))
 	if err != nil {
 		panic(err)
 	}
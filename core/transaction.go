@@ -0,0 +1,249 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// Transaction stages saves and deletions of records, and applies them all-or-nothing on Commit.
+// Create a transaction with Track.Begin.
+//
+// Nothing is changed before Commit. A Transaction can't be used after Commit or Rollback.
+type Transaction struct {
+	track   *Track
+	changes []txChange
+	done    bool
+}
+
+// journalEntry is the original state of a record that is affected by a transaction.
+// Format is empty for records that did not exist.
+type journalEntry struct {
+	Record Record `json:"record"`
+	Format string `json:"format"`
+}
+
+// txChange is a staged save or deletion of a record
+type txChange struct {
+	record Record
+	force  bool
+	delete bool
+	// Time of deletion. The time of the commit if zero
+	deleted time.Time
+}
+
+// Begin starts a transaction for compound changes of records, like splitting or replacing records
+func (t *Track) Begin() *Transaction {
+	return &Transaction{track: t}
+}
+
+// Save stages saving a record, like Track.SaveRecord.
+// Argument `force` allows to overwrite an existing record.
+func (tx *Transaction) Save(record *Record, force bool) {
+	tx.changes = append(tx.changes, txChange{record: record.copy(), force: force})
+}
+
+// Delete stages deleting a record, like Track.DeleteRecord
+func (tx *Transaction) Delete(record *Record) {
+	tx.changes = append(tx.changes, txChange{record: record.copy(), delete: true})
+}
+
+// deleteAt stages deleting a record, with the given time of deletion
+func (tx *Transaction) deleteAt(record *Record, deleted time.Time) {
+	tx.changes = append(tx.changes, txChange{record: record.copy(), delete: true, deleted: deleted})
+}
+
+// Len returns the number of staged changes
+func (tx *Transaction) Len() int {
+	return len(tx.changes)
+}
+
+// Rollback discards all staged changes
+func (tx *Transaction) Rollback() {
+	tx.changes = nil
+	tx.done = true
+}
+
+// Commit applies all staged changes, in the order they were staged.
+//
+// All changes are checked before anything is changed.
+// Saving fails for an existing record without `force`, and for a locked record.
+// Deletion fails for a record that does not exist, and for a locked record.
+//
+// Before applying the changes, the affected records are written to a journal file in the workspace.
+// Each record file is written to a temporary file first, and then renamed.
+// If any change fails, all records are restored from the journal.
+// A journal left behind by an interrupted commit is rolled back by the next commit, see Track.RecoverJournal.
+// Record history and trash keep their entries of changes that were rolled back.
+func (tx *Transaction) Commit() error {
+	if tx.done {
+		return fmt.Errorf("transaction already finished")
+	}
+	tx.done = true
+	if len(tx.changes) == 0 {
+		return nil
+	}
+
+	t := tx.track
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	if err := t.RecoverJournal(); err != nil {
+		return err
+	}
+
+	journal, err := tx.check()
+	if err != nil {
+		return err
+	}
+	if err := t.writeJournal(journal); err != nil {
+		return fmt.Errorf("failed to write journal: %s", err)
+	}
+
+	for i := range tx.changes {
+		change := &tx.changes[i]
+		if change.delete {
+			deleted := change.deleted
+			if deleted.IsZero() {
+				deleted = time.Now()
+			}
+			err = t.deleteRecord(&change.record, deleted)
+		} else {
+			err = t.SaveRecord(&change.record, true)
+		}
+		if err != nil {
+			err = fmt.Errorf("record %s: %s", change.record.Start.Format(util.DateTimeFormat), err)
+			if rbErr := t.rollback(journal); rbErr != nil {
+				return fmt.Errorf("%s; rollback failed, journal kept at %s: %s", err, t.JournalPath(), rbErr)
+			}
+			return fmt.Errorf("%s; all changes were rolled back", err)
+		}
+	}
+	return t.FileSystem().Remove(t.JournalPath())
+}
+
+// check checks all staged changes against the stored records, and the changes staged before them.
+// Returns the journal of the original state of all affected records.
+func (tx *Transaction) check() ([]journalEntry, error) {
+	t := tx.track
+	journal := []journalEntry{}
+	// Whether a record exists, after the changes checked so far, by start time in nanoseconds
+	exists := map[int64]bool{}
+	// Stored records, nil for records that are not stored, by start time in nanoseconds
+	stored := map[int64]*Record{}
+
+	for i := range tx.changes {
+		change := &tx.changes[i]
+		start := change.record.Start
+		startStr := start.Format(util.DateTimeFormat)
+		key := start.UnixNano()
+
+		original, ok := stored[key]
+		if !ok {
+			format, err := t.findRecordFile(start)
+			if err != nil {
+				return nil, err
+			}
+			if format == "" {
+				journal = append(journal, journalEntry{Record: Record{Start: start}})
+			} else {
+				rec, err := t.loadRecordAt(start)
+				if err != nil {
+					return nil, err
+				}
+				original = &rec
+				journal = append(journal, journalEntry{Record: rec, Format: format})
+			}
+			stored[key] = original
+			exists[key] = original != nil
+		}
+
+		if change.delete {
+			if !exists[key] {
+				return nil, fmt.Errorf("record %s: record does not exist", startStr)
+			}
+			if original != nil && original.Locked {
+				return nil, fmt.Errorf("record %s: %w", startStr, ErrRecordLocked)
+			}
+			exists[key] = false
+			continue
+		}
+		if exists[key] && !change.force {
+			return nil, fmt.Errorf("record %s: record already exists", startStr)
+		}
+		if original != nil && changesLocked(original, &change.record) {
+			return nil, fmt.Errorf("record %s: %w", startStr, ErrRecordLocked)
+		}
+		exists[key] = true
+	}
+	return journal, nil
+}
+
+// RecoverJournal restores the original records from the journal of an interrupted transaction, if there is one.
+// Does nothing if there is no journal.
+func (t *Track) RecoverJournal() error {
+	data, err := t.FileSystem().ReadFile(t.JournalPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	journal := []journalEntry{}
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return fmt.Errorf("invalid journal %s: %s", t.JournalPath(), err)
+	}
+	if err := t.rollback(journal); err != nil {
+		return fmt.Errorf("failed to roll back journal %s: %s", t.JournalPath(), err)
+	}
+	return nil
+}
+
+// writeJournal writes the original records of a transaction to the journal file.
+// The journal is written to a temporary file first, and then renamed,
+// so that an interrupted write does not leave a torn journal.
+func (t *Track) writeJournal(journal []journalEntry) error {
+	data, err := json.Marshal(journal)
+	if err != nil {
+		return err
+	}
+	tempPath := t.JournalPath() + tempFileExt
+	if err := t.FileSystem().WriteFile(tempPath, data, 0600); err != nil {
+		return err
+	}
+	return t.FileSystem().Rename(tempPath, t.JournalPath())
+}
+
+// rollback restores records from a journal, in their original file format, and removes the journal file.
+// Records that did not exist before are removed.
+func (t *Track) rollback(journal []journalEntry) error {
+	for i := range journal {
+		entry := &journal[i]
+		existing, err := t.findRecordFile(entry.Record.Start)
+		if err != nil {
+			return err
+		}
+		if existing != "" && existing != entry.Format {
+			if err := t.removeRecordAt(entry.Record.Start, existing); err != nil {
+				return err
+			}
+		}
+		if entry.Format == "" {
+			if err := t.removeEmptyDayDir(t.RecordDir(entry.Record.Start)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		if err := t.createDir(t.RecordDir(entry.Record.Start)); err != nil {
+			return err
+		}
+		if err := t.writeRecord(&entry.Record, entry.Format); err != nil {
+			return err
+		}
+	}
+	return t.FileSystem().Remove(t.JournalPath())
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0), Tags: map[string]string{}, Pause: []Pause{}},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 10, 0, 0), End: util.DateTime(2001, 2, 3, 11, 0, 0), Tags: map[string]string{}, Pause: []Pause{}},
+		{Project: "test", Start: util.DateTime(2001, 2, 4, 8, 0, 0), End: util.DateTime(2001, 2, 4, 9, 0, 0), Tags: map[string]string{}, Pause: []Pause{}},
+	}
+	assert.Nil(t, track.SaveRecord(&records[0], false), "Error saving record")
+
+	// Failing checks leave all records unchanged
+	tx := track.Begin()
+	tx.Save(&records[1], false)
+	tx.Save(&records[0], false)
+	assert.NotNil(t, tx.Commit(), "Expected error for existing record")
+	assert.NotNil(t, tx.Commit(), "Expected error for finished transaction")
+
+	tx = track.Begin()
+	tx.Save(&records[1], false)
+	tx.Delete(&records[2])
+	assert.NotNil(t, tx.Commit(), "Expected error for deleting a missing record")
+
+	loaded, err := track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, records[:1], loaded, "Records should be unchanged")
+
+	// Replace a record, like in 'edit day'
+	tx = track.Begin()
+	tx.Delete(&records[0])
+	tx.Save(&records[0], false)
+	tx.Save(&records[1], false)
+	tx.Save(&records[2], false)
+	assert.Equal(t, 4, tx.Len(), "Wrong number of staged changes")
+	assert.Nil(t, tx.Commit(), "Error committing transaction")
+	assert.False(t, util.FileExists(track.JournalPath()), "Journal should be removed")
+
+	loaded, err = track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, records, loaded, "Wrong records")
+
+	tx = track.Begin()
+	tx.Delete(&records[1])
+	tx.Rollback()
+	assert.NotNil(t, tx.Commit(), "Expected error for rolled back transaction")
+}
+
+func TestTransactionRollback(t *testing.T) {
+	track, err := NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+	track.Config.TrashDays = 30
+
+	records := []Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 9, 0, 0), Tags: map[string]string{}, Pause: []Pause{}},
+		{Project: "test", Start: util.DateTime(2001, 2, 4, 8, 0, 0), End: util.DateTime(2001, 2, 4, 9, 0, 0), Tags: map[string]string{}, Pause: []Pause{}},
+	}
+	assert.Nil(t, track.SaveRecord(&records[0], false), "Error saving record")
+
+	// Moving the deleted record to the trash fails, after the first record was saved
+	assert.Nil(t, track.FS.WriteFile(track.TrashDir(), []byte{}, 0600), "Error blocking trash")
+
+	tx := track.Begin()
+	tx.Save(&records[1], false)
+	tx.Delete(&records[0])
+	err = tx.Commit()
+	assert.ErrorContains(t, err, "rolled back")
+
+	loaded, err := track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, records[:1], loaded, "Records should be restored")
+	assert.False(t, util.FileExists(track.RecordDir(records[1].Start)), "Day directory should be removed")
+	assert.False(t, util.FileExists(track.JournalPath()), "Journal should be removed")
+}
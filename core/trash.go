@@ -0,0 +1,165 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	trashDirName    = ".trash"
+	trashFileExt    = ".yml"
+	trashTimeLayout = "2006-01-02_15-04"
+)
+
+// TrashedRecord is a deleted record in the trash of a workspace
+type TrashedRecord struct {
+	// Path of the record's original file, relative to the records directory
+	Path string `yaml:"path"`
+	// Time of deletion
+	Deleted time.Time `yaml:"deleted"`
+	// The deleted record
+	Record Record `yaml:"record"`
+	// Name of the file in the trash
+	file string
+}
+
+// trashRecord moves a copy of a record into the trash, along with the path of its original file
+func (t *Track) trashRecord(record *Record, format string, deleted time.Time) error {
+	if err := t.createDir(t.TrashDir()); err != nil {
+		return err
+	}
+	path, err := filepath.Rel(t.RecordsDir(), t.recordFilePath(record.Start, format))
+	if err != nil {
+		return err
+	}
+	entry := TrashedRecord{
+		Path:    filepath.ToSlash(path),
+		Deleted: deleted,
+		Record:  *record,
+	}
+	data, err := yaml.Marshal(&entry)
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf("%s Deleted record %s\n\n%s", YamlCommentPrefix, record.Start.Format(util.DateTimeFormat), data)
+
+	name := fmt.Sprintf("%s_%d%s", record.Start.Format(trashTimeLayout), deleted.UnixNano(), trashFileExt)
+	return t.FileSystem().WriteFile(filepath.Join(t.TrashDir(), name), []byte(content), 0600)
+}
+
+// DeletedRecords returns all records in the trash of the current workspace, ordered by time of deletion
+func (t *Track) DeletedRecords() ([]TrashedRecord, error) {
+	files, err := t.FileSystem().ReadDir(t.TrashDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []TrashedRecord{}, nil
+		}
+		return nil, err
+	}
+
+	entries := make([]TrashedRecord, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), trashFileExt) {
+			continue
+		}
+		data, err := t.FileSystem().ReadFile(filepath.Join(t.TrashDir(), file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var entry TrashedRecord
+		if err := yaml.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("invalid trash file %s: %s", file.Name(), err)
+		}
+		entry.Record.toLocal()
+		entry.Deleted = entry.Deleted.Local()
+		entry.file = file.Name()
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Deleted.Before(entries[j].Deleted) })
+
+	return entries, nil
+}
+
+// RestoreDeleted restores the most recently deleted record with the given start time from the trash.
+// The record is restored in the format of its original file.
+// Fails if there is a record with the same start time.
+func (t *Track) RestoreDeleted(start time.Time) (Record, error) {
+	if err := t.CheckWritable(); err != nil {
+		return Record{}, err
+	}
+	entries, err := t.DeletedRecords()
+	if err != nil {
+		return Record{}, err
+	}
+
+	var entry *TrashedRecord
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Record.Start.Equal(start) {
+			entry = &entries[i]
+			break
+		}
+	}
+	if entry == nil {
+		return Record{}, ErrRecordNotFound
+	}
+
+	existing, err := t.findRecordFile(start)
+	if err != nil {
+		return Record{}, err
+	}
+	if existing != "" {
+		return Record{}, fmt.Errorf("record already exists")
+	}
+
+	format := recordFileFormat(filepath.Base(entry.Path))
+	if format == "" {
+		format = t.Config.RecordFileFormat
+	}
+	if err := t.createDir(t.RecordDir(start)); err != nil {
+		return Record{}, err
+	}
+	if err := t.writeRecord(&entry.Record, format); err != nil {
+		return Record{}, err
+	}
+	if t.Config.History {
+		if err := t.appendHistory(&entry.Record, false); err != nil {
+			return Record{}, err
+		}
+	}
+
+	return entry.Record, t.FileSystem().Remove(filepath.Join(t.TrashDir(), entry.file))
+}
+
+// PurgeTrash permanently deletes records that were moved to the trash longer than maxAge ago.
+// Deletes all records in the trash if maxAge is zero.
+//
+// Returns the number of purged records.
+func (t *Track) PurgeTrash(maxAge time.Duration) (int, error) {
+	if err := t.CheckWritable(); err != nil {
+		return 0, err
+	}
+	entries, err := t.DeletedRecords()
+	if err != nil {
+		return 0, err
+	}
+
+	limit := time.Now().Add(-maxAge)
+	counter := 0
+	for _, entry := range entries {
+		if maxAge > 0 && entry.Deleted.After(limit) {
+			continue
+		}
+		if err := t.FileSystem().Remove(filepath.Join(t.TrashDir(), entry.file)); err != nil {
+			return counter, err
+		}
+		counter++
+	}
+	return counter, nil
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrash(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	record := Record{
+		Project: "test",
+		Note:    "Note with +tag",
+		Tags:    map[string]string{"tag": ""},
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	assert.Nil(t, track.SaveRecord(&record, false))
+	assert.Nil(t, track.DeleteRecord(&record))
+
+	_, err = track.LoadRecord(record.Start)
+	assert.NotNil(t, err, "Record should be deleted")
+
+	entries, err := track.DeletedRecords()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "2001/02/03/04-05.trk", entries[0].Path)
+	assert.Equal(t, record.Note, entries[0].Record.Note)
+
+	restored, err := track.RestoreDeleted(record.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, record.Project, restored.Project)
+
+	loaded, err := track.LoadRecord(record.Start)
+	assert.Nil(t, err, "Record should be restored")
+	assert.Equal(t, record.Note, loaded.Note)
+	assert.Equal(t, record.End, loaded.End)
+
+	entries, err = track.DeletedRecords()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(entries))
+
+	_, err = track.RestoreDeleted(record.Start)
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+
+	assert.Nil(t, track.DeleteRecord(&record))
+	cnt, err := track.PurgeTrash(time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, cnt)
+	cnt, err = track.PurgeTrash(0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cnt)
+
+	track.Config.TrashDays = 0
+	assert.Nil(t, track.SaveRecord(&record, false))
+	assert.Nil(t, track.DeleteRecord(&record))
+	entries, err = track.DeletedRecords()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(entries))
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// UsageStats are statistics of the data directory of a workspace
+type UsageStats struct {
+	// Number of records
+	Records int
+	// Number of days with records
+	Days int
+	// Number of records per year
+	RecordsPerYear map[int]int
+	// Start of the oldest record, zero if there are no records
+	Oldest time.Time
+	// Start of the newest record, zero if there are no records
+	Newest time.Time
+	// Number of records in the trash
+	Trash int
+	// Number of conflicting copies of record files
+	Conflicts int
+	// Size of the workspace directory in bytes
+	Size int64
+	// Size of the records directory in bytes
+	RecordsSize int64
+	// Size of the trash in bytes
+	TrashSize int64
+	// Size of record histories in bytes
+	HistorySize int64
+	// Size of the report cache in bytes
+	CacheSize int64
+	// Health of the report cache
+	Cache CacheHealth
+}
+
+// CacheHealth is the state of the report cache, relative to the record files
+type CacheHealth struct {
+	// Days with an up-to-date cache entry
+	Valid int
+	// Days with an outdated cache entry, or entries of days without records
+	Stale int
+	// Past days with records, but without a cache entry
+	Missing int
+}
+
+// IsHealthy checks if the report cache has no outdated entries
+func (h *CacheHealth) IsHealthy() bool {
+	return h.Stale == 0
+}
+
+// UsageStats collects statistics of the data directory of the current workspace.
+// Argument now is used to determine past days, which are the only ones cached.
+func (t *Track) UsageStats(now time.Time) (UsageStats, error) {
+	stats := UsageStats{RecordsPerYear: map[int]int{}}
+	cache := t.loadReportCache()
+	today := util.ToDate(now)
+	seen := map[string]bool{}
+
+	var walkErr error
+	err := t.walkDays(FilterFunctions{}, false, func(date time.Time) bool {
+		records, err := t.readDayRecords(date)
+		if err != nil && !errors.Is(err, ErrNoRecords) {
+			walkErr = err
+			return false
+		}
+		stats.Conflicts += t.countConflicts(t.RecordDir(date))
+		if len(records) == 0 {
+			return true
+		}
+
+		stats.Days++
+		stats.Records += len(records)
+		stats.RecordsPerYear[date.Year()] += len(records)
+		if stats.Oldest.IsZero() {
+			stats.Oldest = records[0].Start
+		}
+		stats.Newest = records[len(records)-1].Start
+
+		if !date.Before(today) {
+			return true
+		}
+		key := date.Format(util.DateFormat)
+		seen[key] = true
+		entry, ok := cache.Days[key]
+		if !ok {
+			stats.Cache.Missing++
+			return true
+		}
+		fingerprint, err := t.dayFingerprint(date)
+		if err != nil {
+			walkErr = err
+			return false
+		}
+		if entry.Fingerprint == fingerprint {
+			stats.Cache.Valid++
+		} else {
+			stats.Cache.Stale++
+		}
+		return true
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return stats, err
+	}
+	if walkErr != nil {
+		return stats, walkErr
+	}
+	for key := range cache.Days {
+		if !seen[key] {
+			stats.Cache.Stale++
+		}
+	}
+
+	trashed, err := t.DeletedRecords()
+	if err != nil {
+		return stats, err
+	}
+	stats.Trash = len(trashed)
+
+	if stats.Size, err = t.dirSize(t.WorkspaceDir(t.Workspace())); err != nil {
+		return stats, err
+	}
+	if stats.RecordsSize, err = t.dirSize(t.RecordsDir()); err != nil {
+		return stats, err
+	}
+	if stats.TrashSize, err = t.dirSize(t.TrashDir()); err != nil {
+		return stats, err
+	}
+	if stats.HistorySize, err = t.dirSize(t.HistoryDir()); err != nil {
+		return stats, err
+	}
+	if info, err := t.FileSystem().Stat(t.ReportCachePath()); err == nil {
+		stats.CacheSize = info.Size()
+	}
+
+	return stats, nil
+}
+
+// countConflicts counts the conflicting copies of record files in a day directory
+func (t *Track) countConflicts(dayDir string) int {
+	files, err := t.FileSystem().ReadDir(dayDir)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, file := range files {
+		if _, ok := conflictOriginal(file.Name()); ok && !file.IsDir() {
+			count++
+		}
+	}
+	return count
+}
+
+// dirSize returns the total size of all files in a directory and its sub-directories.
+// Returns zero if the directory does not exist.
+func (t *Track) dirSize(dir string) (int64, error) {
+	var size int64
+	err := t.walkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
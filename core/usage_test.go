@@ -0,0 +1,77 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageStats(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	now := util.DateTime(2002, 1, 1, 12, 0, 0)
+	stats, err := track.UsageStats(now)
+	assert.Nil(t, err, "Error collecting usage stats")
+	assert.Equal(t, 0, stats.Records, "Wrong number of records")
+	assert.True(t, stats.Oldest.IsZero(), "Oldest record should be zero")
+
+	project := NewProject("test", "", "T", []string{}, 0, 15)
+	err = track.SaveProject(project, false)
+	assert.Nil(t, err, "Error saving project")
+
+	starts := []time.Time{
+		util.DateTime(2000, 12, 31, 8, 0, 0),
+		util.DateTime(2001, 2, 3, 8, 0, 0),
+		util.DateTime(2001, 2, 3, 10, 0, 0),
+		util.DateTime(2001, 2, 4, 8, 0, 0),
+	}
+	for _, start := range starts {
+		record := Record{Project: "test", Start: start, End: start.Add(time.Hour)}
+		err = track.SaveRecord(&record, false)
+		assert.Nil(t, err, "Error saving record")
+	}
+
+	conflictPath := filepath.Join(track.RecordDir(starts[0]), "08-00.sync-conflict-20010203-100000-ABCDEFG.trk")
+	err = os.WriteFile(conflictPath, []byte{}, 0600)
+	assert.Nil(t, err, "Error writing conflicting copy")
+
+	_, err = NewCachedReporter(&track, []string{}, FilterFunctions{}, false, util.NoTime, util.NoTime)
+	assert.Nil(t, err, "Error creating reporter")
+
+	record, err := track.LoadRecord(starts[3])
+	assert.Nil(t, err, "Error loading record")
+	err = track.DeleteRecord(&record)
+	assert.Nil(t, err, "Error deleting record")
+
+	record, err = track.LoadRecord(starts[1])
+	assert.Nil(t, err, "Error loading record")
+	record.Note = "Changed"
+	err = track.SaveRecord(&record, true)
+	assert.Nil(t, err, "Error saving record")
+
+	stats, err = track.UsageStats(now)
+	assert.Nil(t, err, "Error collecting usage stats")
+
+	assert.Equal(t, 3, stats.Records, "Wrong number of records")
+	assert.Equal(t, 2, stats.Days, "Wrong number of days")
+	assert.Equal(t, map[int]int{2000: 1, 2001: 2}, stats.RecordsPerYear, "Wrong records per year")
+	assert.Equal(t, starts[0], stats.Oldest, "Wrong oldest record")
+	assert.Equal(t, starts[2], stats.Newest, "Wrong newest record")
+	assert.Equal(t, 1, stats.Trash, "Wrong number of trashed records")
+	assert.Equal(t, 1, stats.Conflicts, "Wrong number of conflicts")
+	assert.Equal(t, CacheHealth{Valid: 1, Stale: 2, Missing: 0}, stats.Cache, "Wrong cache health")
+
+	assert.Greater(t, stats.RecordsSize, int64(0), "Records size should be positive")
+	assert.Greater(t, stats.TrashSize, int64(0), "Trash size should be positive")
+	assert.Greater(t, stats.CacheSize, int64(0), "Cache size should be positive")
+	assert.GreaterOrEqual(t, stats.Size, stats.RecordsSize+stats.TrashSize+stats.CacheSize, "Total size too small")
+}
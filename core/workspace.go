@@ -2,14 +2,14 @@ package core
 
 import (
 	"fmt"
-	"os"
-
-	"github.com/mlange-42/track/util"
 )
 
 // CreateWorkspace creates a new workspace
 func (t *Track) CreateWorkspace(name string) error {
-	if util.DirExists(t.WorkspaceDir(name)) {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	if t.dirExists(t.WorkspaceDir(name)) {
 		return fmt.Errorf("workspace '%s' already exists", name)
 	}
 	t.createWorkspaceDirs(name)
@@ -18,12 +18,15 @@ func (t *Track) CreateWorkspace(name string) error {
 
 // WorkspaceExists returns whether a workspace exists
 func (t *Track) WorkspaceExists(name string) bool {
-	return util.DirExists(t.WorkspaceDir(name))
+	return t.dirExists(t.WorkspaceDir(name))
 }
 
 // SwitchWorkspace switches to another workspace
 func (t *Track) SwitchWorkspace(name string) error {
-	if !util.DirExists(t.WorkspaceDir(name)) {
+	if err := t.CheckWritable(); err != nil {
+		return err
+	}
+	if !t.dirExists(t.WorkspaceDir(name)) {
 		return fmt.Errorf("workspace '%s' does not exist", name)
 	}
 	open, err := t.OpenRecord()
@@ -36,7 +39,7 @@ func (t *Track) SwitchWorkspace(name string) error {
 	t.createWorkspaceDirs(name)
 
 	t.Config.Workspace = name
-	err = t.Config.Save(t.ConfigPath())
+	err = t.SaveConfig()
 	if err != nil {
 		return err
 	}
@@ -52,7 +55,7 @@ func (t *Track) Workspace() string {
 // AllWorkspaces returns a slice of all workspaces
 func (t *Track) AllWorkspaces() ([]string, error) {
 	path := t.RootDir
-	dirs, err := os.ReadDir(path)
+	dirs, err := t.FileSystem().ReadDir(path)
 	if err != nil {
 		return nil, err
 	}
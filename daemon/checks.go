@@ -0,0 +1,207 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+)
+
+// LongRunningCheck warns about records that run longer than the configured maximum duration,
+// which typically means that the user forgot to stop tracking.
+//
+// If auto-stop is enabled in the config, such records are stopped at the cut-off time.
+// Each record is reported only once.
+type LongRunningCheck struct {
+	notified map[time.Time]bool
+}
+
+// NewLongRunningCheck creates a new LongRunningCheck
+func NewLongRunningCheck() *LongRunningCheck {
+	return &LongRunningCheck{
+		notified: map[time.Time]bool{},
+	}
+}
+
+// Run runs the check
+func (c *LongRunningCheck) Run(t *core.Track, now time.Time) (string, error) {
+	record, err := t.LongRunningRecord(now)
+	if err != nil {
+		return "", err
+	}
+	if record == nil {
+		return "", nil
+	}
+
+	if t.Config.AutoStop {
+		stopped, err := t.AutoStopRecord()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(
+			"Stopped record in '%s' at %s, as it was running for more than %s",
+			stopped.Project, stopped.End.Format(util.DateTimeFormat), util.FormatDuration(t.Config.MaxRecordDuration, false),
+		), nil
+	}
+
+	if c.notified[record.Start] {
+		return "", nil
+	}
+	c.notified[record.Start] = true
+
+	return fmt.Sprintf(
+		"Record in '%s' is running since %s (%s). Did you forget to stop it?",
+		record.Project, record.Start.Format(util.DateTimeFormat), util.FormatDuration(now.Sub(record.Start), false),
+	), nil
+}
+
+// LongPauseCheck stops records with an open pause longer than the configured maximum pause duration,
+// at the start of the pause. See core.Track.StopLongPause.
+type LongPauseCheck struct{}
+
+// NewLongPauseCheck creates a new LongPauseCheck
+func NewLongPauseCheck() *LongPauseCheck {
+	return &LongPauseCheck{}
+}
+
+// Run runs the check
+func (c *LongPauseCheck) Run(t *core.Track, now time.Time) (string, error) {
+	stopped, err := t.StopLongPause(now)
+	if err != nil || stopped == nil {
+		return "", err
+	}
+	return fmt.Sprintf(
+		"Stopped record in '%s' at %s, as it was paused for more than %s",
+		stopped.Project, stopped.End.Format(util.DateTimeFormat), util.FormatDuration(t.Config.MaxPauseDuration, false),
+	), nil
+}
+
+// StopReminderCheck reminds about records that are still running past the time of day
+// from config entry StopReminder, to prevent forgotten overnight records.
+//
+// Each record is reported only once.
+type StopReminderCheck struct {
+	notified map[time.Time]bool
+}
+
+// NewStopReminderCheck creates a new StopReminderCheck
+func NewStopReminderCheck() *StopReminderCheck {
+	return &StopReminderCheck{
+		notified: map[time.Time]bool{},
+	}
+}
+
+// Run runs the check
+func (c *StopReminderCheck) Run(t *core.Track, now time.Time) (string, error) {
+	if !t.Config.StopReminder.IsEnabled() {
+		return "", nil
+	}
+	record, err := t.OpenRecord()
+	if err != nil {
+		return "", err
+	}
+	if record == nil || c.notified[record.Start] {
+		return "", nil
+	}
+
+	// The first reminder time after the record's start
+	var due time.Time
+	for day := util.ToDate(record.Start); !day.After(now); day = day.AddDate(0, 0, 1) {
+		if at, ok := t.Config.StopReminder.At(day); ok && at.After(record.Start) {
+			due = at
+			break
+		}
+	}
+	if due.IsZero() || now.Before(due) {
+		return "", nil
+	}
+	c.notified[record.Start] = true
+
+	return fmt.Sprintf(
+		"Record in '%s' is still running since %s. Did you forget to stop it?",
+		record.Project, record.Start.Format(util.DateTimeFormat),
+	), nil
+}
+
+// StartReminderCheck reminds to start tracking if no record was started by the time of day
+// from config entry StartReminder, on working days without full-day absences.
+//
+// Runs at most once per day.
+type StartReminderCheck struct {
+	lastRun time.Time
+}
+
+// NewStartReminderCheck creates a new StartReminderCheck
+func NewStartReminderCheck() *StartReminderCheck {
+	return &StartReminderCheck{}
+}
+
+// Run runs the check
+func (c *StartReminderCheck) Run(t *core.Track, now time.Time) (string, error) {
+	today := util.ToDate(now)
+	if c.lastRun.Equal(today) || !t.Config.IsWorkday(today.Weekday()) {
+		return "", nil
+	}
+	at, ok := t.Config.StartReminder.At(today)
+	if !ok || now.Before(at) {
+		return "", nil
+	}
+
+	absences, err := t.LoadAbsences()
+	if err != nil {
+		return "", err
+	}
+	latest, err := t.LatestRecord()
+	if err != nil {
+		return "", err
+	}
+	c.lastRun = today
+
+	if abs, ok := absences.On(today); ok && abs.IsFullDay() {
+		return "", nil
+	}
+	if latest != nil && (!latest.HasEnded() || !latest.Start.Before(today)) {
+		return "", nil
+	}
+
+	return fmt.Sprintf(
+		"No record started today by %s. Did you forget to start tracking?",
+		at.Format(util.TimeFormat),
+	), nil
+}
+
+// RetentionCheck applies the retention rules from the config once per day.
+// See core.Track.ApplyRetention.
+type RetentionCheck struct {
+	lastRun time.Time
+}
+
+// NewRetentionCheck creates a new RetentionCheck
+func NewRetentionCheck() *RetentionCheck {
+	return &RetentionCheck{}
+}
+
+// Run runs the check
+func (c *RetentionCheck) Run(t *core.Track, now time.Time) (string, error) {
+	if !t.Config.Retention.IsEnabled() {
+		return "", nil
+	}
+	today := util.ToDate(now)
+	if c.lastRun.Equal(today) {
+		return "", nil
+	}
+	c.lastRun = today
+
+	result, err := t.ApplyRetention(now, false)
+	if err != nil {
+		return "", err
+	}
+	if result.Changed() == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf(
+		"Applied retention rules: %d record(s) deleted, %d anonymized, %d with stripped notes",
+		result.Deleted, result.Anonymized, result.Stripped,
+	), nil
+}
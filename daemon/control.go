@@ -0,0 +1,310 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mlange-42/track/core"
+)
+
+const requestTimeout = 30 * time.Second
+
+// Methods of the control API
+const (
+	// MethodStatus returns the running record, if any
+	MethodStatus = "status"
+	// MethodStart starts a record
+	MethodStart = "start"
+	// MethodStop stops the running record
+	MethodStop = "stop"
+	// MethodPause pauses the running record
+	MethodPause = "pause"
+	// MethodResume resumes the paused running record
+	MethodResume = "resume"
+	// MethodSwitch stops the running record, if any, and starts a record
+	MethodSwitch = "switch"
+)
+
+// Request is a request to the control API of the daemon
+type Request struct {
+	Method  string            `json:"method"`
+	Project string            `json:"project,omitempty"`
+	Note    string            `json:"note,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	// Category for MethodStart. Overrides the project's default category
+	Category string `json:"category,omitempty"`
+	// Start time for MethodStart, MethodSwitch and MethodPause, end time for MethodStop and MethodResume
+	Time time.Time `json:"time,omitempty"`
+	// End time for MethodPause. Inserts a finished pause if given, an open pause otherwise
+	End time.Time `json:"end,omitempty"`
+}
+
+// Response is a response of the control API of the daemon
+type Response struct {
+	Record *core.Record `json:"record,omitempty"`
+	// Stopped record of MethodSwitch
+	Stopped *core.Record `json:"stopped,omitempty"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// SocketPath returns the path of the control socket of the daemon.
+//
+// This is a Unix domain socket in the Track's root directory,
+// or a named pipe derived from the root directory on Windows.
+func SocketPath(t *core.Track) string {
+	return socketPath(t.RootDir)
+}
+
+// Listen creates the control socket of the daemon.
+//
+// Fails if another daemon is listening on the socket already. Stale socket files are removed.
+func Listen(t *core.Track) (net.Listener, error) {
+	return listen(SocketPath(t))
+}
+
+// Serve serves the control API on the listener, until the listener is closed.
+//
+// Requests and responses are JSON objects, one per line.
+// Requests are handled one at a time, also in relation to the periodic checks.
+func (d *Daemon) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go d.serveConn(conn)
+	}
+}
+
+func (d *Daemon) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+	for {
+		if err := conn.SetDeadline(time.Now().Add(requestTimeout)); err != nil {
+			return
+		}
+		var req Request
+		if err := decoder.Decode(&req); err != nil {
+			return
+		}
+		if err := encoder.Encode(d.Handle(req)); err != nil {
+			return
+		}
+	}
+}
+
+// Handle handles a request to the control API
+func (d *Daemon) Handle(req Request) Response {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var record *core.Record
+	var err error
+	switch req.Method {
+	case MethodSwitch:
+		var stopped *core.Record
+		stopped, record, err = d.switchRecord(&req)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{Record: record, Stopped: stopped}
+	case MethodStatus:
+		record, err = d.Track.OpenRecord()
+	case MethodStart:
+		record, err = d.start(&req)
+	case MethodStop:
+		record, err = d.Track.StopRecord(req.Time)
+	case MethodPause:
+		record, err = d.pause(&req)
+	case MethodResume:
+		record, err = d.resume(&req)
+	default:
+		err = fmt.Errorf("unknown method '%s'", req.Method)
+	}
+
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	return Response{Record: record}
+}
+
+func (d *Daemon) start(req *Request) (*core.Record, error) {
+	project, err := d.Track.LoadProject(req.Project)
+	if err != nil {
+		return nil, err
+	}
+	open, err := d.Track.OpenRecord()
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, fmt.Errorf("record in '%s' still running", open.Project)
+	}
+	if req.Category != "" {
+		project.Category = req.Category
+	}
+	record, err := d.Track.StartRecord(&project, req.Note, req.Tags, req.Time)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (d *Daemon) switchRecord(req *Request) (*core.Record, *core.Record, error) {
+	project, err := d.Track.LoadProject(req.Project)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.Category != "" {
+		project.Category = req.Category
+	}
+	stopped, record, err := d.Track.SwitchRecord(&project, req.Note, req.Tags, req.Time)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stopped, &record, nil
+}
+
+func (d *Daemon) pause(req *Request) (*core.Record, error) {
+	open, err := d.openRecord()
+	if err != nil {
+		return nil, err
+	}
+	if open.IsPaused() {
+		return nil, fmt.Errorf("record is already paused")
+	}
+	if _, err := open.InsertPause(req.Time, req.End, req.Note); err != nil {
+		return nil, err
+	}
+	if err := d.Track.SaveRecord(open, true); err != nil {
+		return nil, err
+	}
+	if req.End.IsZero() {
+		d.Track.EmitEvent(core.Event{Type: core.EventPause, Time: req.Time, Record: *open})
+	}
+	return open, nil
+}
+
+func (d *Daemon) resume(req *Request) (*core.Record, error) {
+	open, err := d.openRecord()
+	if err != nil {
+		return nil, err
+	}
+	if !open.IsPaused() {
+		return nil, fmt.Errorf("record is not paused")
+	}
+	if _, err := open.EndPause(req.Time); err != nil {
+		return nil, err
+	}
+	if err := d.Track.SaveRecord(open, true); err != nil {
+		return nil, err
+	}
+	d.Track.EmitEvent(core.Event{Type: core.EventResume, Time: req.Time, Record: *open})
+	return open, nil
+}
+
+// openRecord returns the running record, or an error if there is none
+func (d *Daemon) openRecord() (*core.Record, error) {
+	open, err := d.Track.OpenRecord()
+	if err != nil {
+		return nil, err
+	}
+	if open == nil {
+		return nil, core.ErrNoOpenRecord
+	}
+	return open, nil
+}
+
+// Client calls the control API of a running daemon
+type Client struct {
+	path string
+}
+
+// Connect returns a client for the daemon of the Track's root directory,
+// or nil if no daemon is running.
+func Connect(t *core.Track) *Client {
+	path := SocketPath(t)
+	conn, err := dial(path, time.Second)
+	if err != nil {
+		return nil
+	}
+	conn.Close()
+	return &Client{path: path}
+}
+
+// Call sends a request to the daemon, and returns the resulting record
+func (c *Client) Call(req Request) (*core.Record, error) {
+	resp, err := c.call(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Record, nil
+}
+
+// call sends a request to the daemon, and returns the full response
+func (c *Client) call(req Request) (*Response, error) {
+	conn, err := dial(c.path, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(requestTimeout)); err != nil {
+		return nil, err
+	}
+
+	if err := json.NewEncoder(conn).Encode(&req); err != nil {
+		return nil, err
+	}
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("invalid response from daemon: %s", err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// Status returns the running record, or nil if no record is running
+func (c *Client) Status() (*core.Record, error) {
+	return c.Call(Request{Method: MethodStatus})
+}
+
+// Start starts a record
+func (c *Client) Start(project, category, note string, tags map[string]string, start time.Time) (*core.Record, error) {
+	return c.Call(Request{Method: MethodStart, Project: project, Category: category, Note: note, Tags: tags, Time: start})
+}
+
+// Stop stops the running record
+func (c *Client) Stop(end time.Time) (*core.Record, error) {
+	return c.Call(Request{Method: MethodStop, Time: end})
+}
+
+// Switch stops the running record, if any, and starts a record.
+// Returns the stopped record, or nil if there was no running record, and the started record
+func (c *Client) Switch(project, category, note string, tags map[string]string, tm time.Time) (*core.Record, *core.Record, error) {
+	resp, err := c.call(Request{Method: MethodSwitch, Project: project, Category: category, Note: note, Tags: tags, Time: tm})
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Stopped, resp.Record, nil
+}
+
+// Pause pauses the running record.
+// Inserts a finished pause if end is given, an open pause otherwise
+func (c *Client) Pause(note string, start, end time.Time) (*core.Record, error) {
+	return c.Call(Request{Method: MethodPause, Note: note, Time: start, End: end})
+}
+
+// Resume resumes the paused running record
+func (c *Client) Resume(end time.Time) (*core.Record, error) {
+	return c.Call(Request{Method: MethodResume, Time: end})
+}
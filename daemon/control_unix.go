@@ -0,0 +1,34 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const socketFile = "daemon.sock"
+
+func socketPath(rootDir string) string {
+	return filepath.Join(rootDir, socketFile)
+}
+
+func listen(path string) (net.Listener, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := dial(path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("daemon already running on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+func dial(path string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout("unix", path, timeout)
+}
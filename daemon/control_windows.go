@@ -0,0 +1,268 @@
+//go:build windows
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	pipePrefix     = `\\.\pipe\track-`
+	pipeBufferSize = 64 * 1024
+)
+
+// socketPath derives the name of the daemon's named pipe from the root directory,
+// as pipes live in a global namespace rather than the file system
+func socketPath(rootDir string) string {
+	if abs, err := filepath.Abs(rootDir); err == nil {
+		rootDir = abs
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(strings.ToLower(filepath.Clean(rootDir))))
+	return fmt.Sprintf("%s%016x", pipePrefix, hash.Sum64())
+}
+
+func listen(path string) (net.Listener, error) {
+	sa, err := pipeSecurity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pipe security descriptor: %s", err)
+	}
+	l := &pipeListener{path: path, security: sa}
+	handle, err := l.createPipe(true)
+	if err != nil {
+		if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+			return nil, fmt.Errorf("daemon already running on %s", path)
+		}
+		return nil, err
+	}
+	l.next = handle
+	return l, nil
+}
+
+func dial(path string, timeout time.Duration) (net.Conn, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		handle, err := windows.CreateFile(name,
+			windows.GENERIC_READ|windows.GENERIC_WRITE, 0, nil,
+			windows.OPEN_EXISTING, 0, 0)
+		if err == nil {
+			return newPipeConn(handle, path), nil
+		}
+		// All pipe instances are busy until the daemon accepts the next connection
+		if !errors.Is(err, windows.ERROR_PIPE_BUSY) || time.Now().After(deadline) {
+			return nil, &net.OpError{Op: "dial", Net: "pipe", Addr: pipeAddr(path), Err: err}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// pipeSecurity restricts access to the pipe to the current user
+func pipeSecurity() (*windows.SecurityAttributes, error) {
+	user, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return nil, err
+	}
+	sd, err := windows.SecurityDescriptorFromString(fmt.Sprintf("D:P(A;;GA;;;%s)", user.User.Sid.String()))
+	if err != nil {
+		return nil, err
+	}
+	sa := windows.SecurityAttributes{SecurityDescriptor: sd}
+	sa.Length = uint32(unsafe.Sizeof(sa))
+	return &sa, nil
+}
+
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
+
+// pipeListener accepts connections on a named pipe.
+//
+// Each connection gets its own pipe instance.
+// The next instance is created when a connection is accepted,
+// so that clients can connect while the previous one is served.
+type pipeListener struct {
+	path      string
+	security  *windows.SecurityAttributes
+	mutex     sync.Mutex
+	next      windows.Handle
+	accepting bool
+	closed    bool
+}
+
+func (l *pipeListener) createPipe(first bool) (windows.Handle, error) {
+	name, err := windows.UTF16PtrFromString(l.path)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+	flags := uint32(windows.PIPE_ACCESS_DUPLEX)
+	if first {
+		flags |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+	return windows.CreateNamedPipe(name, flags,
+		windows.PIPE_TYPE_BYTE|windows.PIPE_READMODE_BYTE|windows.PIPE_WAIT|windows.PIPE_REJECT_REMOTE_CLIENTS,
+		windows.PIPE_UNLIMITED_INSTANCES, pipeBufferSize, pipeBufferSize, 0, l.security)
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	l.mutex.Lock()
+	if l.closed || l.accepting {
+		l.mutex.Unlock()
+		return nil, net.ErrClosed
+	}
+	handle := l.next
+	l.accepting = true
+	l.mutex.Unlock()
+
+	err := windows.ConnectNamedPipe(handle, nil)
+	if errors.Is(err, windows.ERROR_PIPE_CONNECTED) {
+		err = nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.accepting = false
+	if l.closed {
+		windows.CloseHandle(handle)
+		return nil, net.ErrClosed
+	}
+	if err != nil {
+		windows.CloseHandle(handle)
+		l.next, _ = l.createPipe(false)
+		return nil, &net.OpError{Op: "accept", Net: "pipe", Addr: pipeAddr(l.path), Err: err}
+	}
+	next, err := l.createPipe(false)
+	if err != nil {
+		windows.CloseHandle(handle)
+		return nil, &net.OpError{Op: "accept", Net: "pipe", Addr: pipeAddr(l.path), Err: err}
+	}
+	l.next = next
+	return newPipeConn(handle, l.path), nil
+}
+
+// Close closes the listener. A blocked Accept is woken up by connecting to the pipe.
+func (l *pipeListener) Close() error {
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		return net.ErrClosed
+	}
+	l.closed = true
+	accepting := l.accepting
+	l.mutex.Unlock()
+
+	if !accepting {
+		return windows.CloseHandle(l.next)
+	}
+	if conn, err := dial(l.path, time.Second); err == nil {
+		conn.Close()
+	}
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr(l.path)
+}
+
+// pipeConn is a connection over a pipe instance.
+//
+// Pipes are opened for synchronous I/O, which does not support deadlines.
+// Instead, pending I/O is cancelled when the deadline expires.
+type pipeConn struct {
+	*os.File
+	handle  windows.Handle
+	mutex   sync.Mutex
+	timer   *time.Timer
+	expired bool
+}
+
+func newPipeConn(handle windows.Handle, path string) *pipeConn {
+	return &pipeConn{File: os.NewFile(uintptr(handle), path), handle: handle}
+}
+
+func (c *pipeConn) Read(b []byte) (int, error) {
+	if c.isExpired() {
+		return 0, os.ErrDeadlineExceeded
+	}
+	n, err := c.File.Read(b)
+	if err != nil && c.isExpired() {
+		err = os.ErrDeadlineExceeded
+	}
+	return n, err
+}
+
+func (c *pipeConn) Write(b []byte) (int, error) {
+	if c.isExpired() {
+		return 0, os.ErrDeadlineExceeded
+	}
+	n, err := c.File.Write(b)
+	if err != nil && c.isExpired() {
+		err = os.ErrDeadlineExceeded
+	}
+	return n, err
+}
+
+func (c *pipeConn) Close() error {
+	c.mutex.Lock()
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.mutex.Unlock()
+	return c.File.Close()
+}
+
+func (c *pipeConn) LocalAddr() net.Addr  { return pipeAddr(c.Name()) }
+func (c *pipeConn) RemoteAddr() net.Addr { return pipeAddr(c.Name()) }
+
+// SetDeadline sets the deadline for reads and writes.
+func (c *pipeConn) SetDeadline(t time.Time) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.expired = false
+	if t.IsZero() {
+		return nil
+	}
+	c.timer = time.AfterFunc(time.Until(t), func() {
+		c.mutex.Lock()
+		c.expired = true
+		c.mutex.Unlock()
+		windows.CancelIoEx(c.handle, nil)
+	})
+	return nil
+}
+
+// SetReadDeadline sets the deadline for reads and writes, as they share the pipe's pending I/O.
+func (c *pipeConn) SetReadDeadline(t time.Time) error {
+	return c.SetDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline for reads and writes, as they share the pipe's pending I/O.
+func (c *pipeConn) SetWriteDeadline(t time.Time) error {
+	return c.SetDeadline(t)
+}
+
+func (c *pipeConn) isExpired() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.expired
+}
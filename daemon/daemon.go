@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mlange-42/track/core"
+)
+
+// Check is a periodic check run by the Daemon.
+//
+// Returns a message for the user, or an empty string if there is nothing to report.
+type Check interface {
+	Run(t *core.Track, now time.Time) (string, error)
+}
+
+// Notifier shows messages to the user
+type Notifier = func(title, message string) error
+
+// Daemon periodically runs checks and notifies the user about their results.
+// Further, it serves a control API for the CLI, see Daemon.Serve.
+type Daemon struct {
+	Track    *core.Track
+	Interval time.Duration
+	Checks   []Check
+	Notify   Notifier
+
+	mutex sync.Mutex
+}
+
+// New creates a new Daemon with the default checks
+func New(t *core.Track, interval time.Duration, notify Notifier) *Daemon {
+	return &Daemon{
+		Track:    t,
+		Interval: interval,
+		Checks: []Check{
+			NewLongRunningCheck(),
+			NewLongPauseCheck(),
+			NewStopReminderCheck(),
+			NewStartReminderCheck(),
+			NewRetentionCheck(),
+		},
+		Notify: notify,
+	}
+}
+
+// Run runs the checks in the configured interval, until the stop channel is closed.
+//
+// Errors of individual checks are reported via the notifier, and do not stop the daemon.
+func (d *Daemon) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	d.report(d.RunOnce(time.Now()))
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			d.report(d.RunOnce(now))
+		}
+	}
+}
+
+// RunOnce runs all checks once, and sends their messages to the notifier.
+//
+// Returns the first error that occurred, but runs all checks in any case.
+func (d *Daemon) RunOnce(now time.Time) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	var firstErr error
+	for _, check := range d.Checks {
+		msg, err := check.Run(d.Track, now)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if msg == "" {
+			continue
+		}
+		if err := d.Notify("Track", msg); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (d *Daemon) report(err error) {
+	if err != nil {
+		_ = d.Notify("Track error", err.Error())
+	}
+}
@@ -0,0 +1,268 @@
+package daemon
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaemonLongRunning(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.Remove(dir)
+
+	track, err := core.NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	track.Config.MaxRecordDuration = time.Hour
+
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	start := time.Now().Round(time.Minute).Add(-2 * time.Hour)
+	_, err = track.StartRecord(&project, "", map[string]string{}, start)
+	assert.Nil(t, err, "Error starting record")
+
+	messages := []string{}
+	d := New(&track, time.Minute, func(title, message string) error {
+		messages = append(messages, message)
+		return nil
+	})
+
+	err = d.RunOnce(start.Add(30 * time.Minute))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 0, len(messages), "Expected no message")
+
+	err = d.RunOnce(time.Now())
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 1, len(messages), "Expected a message")
+
+	err = d.RunOnce(time.Now())
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 1, len(messages), "Expected message only once")
+
+	track.Config.AutoStop = true
+	err = d.RunOnce(time.Now())
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 2, len(messages), "Expected a message")
+
+	open, err := track.OpenRecord()
+	assert.Nil(t, err, "Error loading record")
+	assert.Nil(t, open, "Record should be stopped")
+
+	latest, err := track.LatestRecord()
+	assert.Nil(t, err, "Error loading record")
+	assert.Equal(t, start.Add(time.Hour), latest.End, "Wrong end time")
+}
+
+func TestDaemonStopReminder(t *testing.T) {
+	track, err := core.NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+	track.Config.MaxRecordDuration = 0
+	track.Config.StopReminder = core.Reminder{
+		Time:     "18:30",
+		Weekdays: map[string]string{"friday": "16:00"},
+	}
+
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	start := time.Date(2023, 5, 4, 9, 0, 0, 0, time.Local) // A thursday
+	_, err = track.StartRecord(&project, "", map[string]string{}, start)
+	assert.Nil(t, err, "Error starting record")
+
+	messages := []string{}
+	d := New(&track, time.Minute, func(title, message string) error {
+		messages = append(messages, message)
+		return nil
+	})
+
+	err = d.RunOnce(start.Add(9 * time.Hour))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 0, len(messages), "Expected no message before the reminder time")
+
+	err = d.RunOnce(start.Add(10 * time.Hour))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 1, len(messages), "Expected a message")
+
+	err = d.RunOnce(start.Add(11 * time.Hour))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 1, len(messages), "Expected message only once")
+
+	_, err = track.StopRecord(start.Add(12 * time.Hour))
+	assert.Nil(t, err, "Error stopping record")
+
+	start = time.Date(2023, 5, 5, 9, 0, 0, 0, time.Local) // A friday
+	_, err = track.StartRecord(&project, "", map[string]string{}, start)
+	assert.Nil(t, err, "Error starting record")
+
+	err = d.RunOnce(start.Add(7*time.Hour + time.Minute))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 2, len(messages), "Expected a message at the weekday's reminder time")
+}
+
+func TestDaemonStartReminder(t *testing.T) {
+	track, err := core.NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+	track.Config.MaxRecordDuration = 0
+	track.Config.StartReminder = core.Reminder{Time: "08:30"}
+
+	messages := []string{}
+	d := New(&track, time.Minute, func(title, message string) error {
+		messages = append(messages, message)
+		return nil
+	})
+
+	thursday := time.Date(2023, 5, 4, 0, 0, 0, 0, time.Local)
+	err = d.RunOnce(thursday.Add(8 * time.Hour))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 0, len(messages), "Expected no message before the reminder time")
+
+	err = d.RunOnce(thursday.Add(9 * time.Hour))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 1, len(messages), "Expected a message")
+
+	err = d.RunOnce(thursday.Add(10 * time.Hour))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 1, len(messages), "Expected message only once per day")
+
+	friday := thursday.AddDate(0, 0, 1)
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	_, err = track.StartRecord(&project, "", map[string]string{}, friday.Add(8*time.Hour))
+	assert.Nil(t, err, "Error starting record")
+	err = d.RunOnce(friday.Add(9 * time.Hour))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 1, len(messages), "Expected no message with a started record")
+	_, err = track.StopRecord(friday.Add(10 * time.Hour))
+	assert.Nil(t, err, "Error stopping record")
+
+	saturday := friday.AddDate(0, 0, 1)
+	err = d.RunOnce(saturday.Add(9 * time.Hour))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 1, len(messages), "Expected no message on weekends")
+
+	monday := saturday.AddDate(0, 0, 2)
+	vacation, err := core.NewAbsence(core.AbsenceVacation, monday, monday, 0, "")
+	assert.Nil(t, err, "Error creating absence")
+	absences := core.Absences{}
+	assert.Nil(t, absences.Add(vacation))
+	assert.Nil(t, track.SaveAbsences(&absences))
+	err = d.RunOnce(monday.Add(9 * time.Hour))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 1, len(messages), "Expected no message on absences")
+
+	err = d.RunOnce(monday.AddDate(0, 0, 1).Add(9 * time.Hour))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 2, len(messages), "Expected a message")
+}
+
+func TestDaemonRetention(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := core.NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	track.Config.Retention.DeleteAfter = 12
+
+	now := util.ToDate(time.Now()).Add(12 * time.Hour)
+	old := core.Record{Project: "test", Start: now.AddDate(-2, 0, 0).Round(time.Minute)}
+	old.End = old.Start.Add(time.Hour)
+	assert.Nil(t, track.SaveRecord(&old, false))
+
+	messages := []string{}
+	d := New(&track, time.Minute, func(title, message string) error {
+		messages = append(messages, message)
+		return nil
+	})
+
+	err = d.RunOnce(now)
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 1, len(messages), "Expected a message")
+	_, err = track.LoadRecord(old.Start)
+	assert.ErrorIs(t, err, core.ErrRecordNotFound)
+
+	assert.Nil(t, track.SaveRecord(&old, false))
+	err = d.RunOnce(now.Add(time.Minute))
+	assert.Nil(t, err, "Error running checks")
+	assert.Equal(t, 1, len(messages), "Expected retention only once per day")
+}
+
+func TestControl(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := core.NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	assert.Nil(t, track.SaveProject(project, false), "Error saving project")
+
+	assert.Nil(t, Connect(&track), "Expected no client without daemon")
+
+	d := New(&track, time.Minute, func(title, message string) error { return nil })
+	l, err := Listen(&track)
+	assert.Nil(t, err, "Error creating socket")
+	go func() { _ = d.Serve(l) }()
+
+	_, err = Listen(&track)
+	assert.ErrorContains(t, err, "already running")
+
+	client := Connect(&track)
+	assert.NotNil(t, client, "Expected a client")
+
+	rec, err := client.Status()
+	assert.Nil(t, err, "Error getting status")
+	assert.Nil(t, rec, "Expected no running record")
+
+	start := time.Now().Round(time.Minute).Add(-time.Hour)
+	rec, err = client.Start("test", "", "Note +tag", map[string]string{"tag": ""}, start)
+	assert.Nil(t, err, "Error starting record")
+	assert.Equal(t, "test", rec.Project)
+
+	_, err = client.Start("test", "", "", nil, start)
+	assert.ErrorContains(t, err, "still running")
+	_, err = client.Start("unknown", "", "", nil, start)
+	assert.NotNil(t, err, "Expected error for unknown project")
+
+	rec, err = client.Status()
+	assert.Nil(t, err, "Error getting status")
+	assert.NotNil(t, rec, "Expected a running record")
+	assert.Equal(t, "Note +tag", rec.Note)
+
+	rec, err = client.Pause("Lunch", start.Add(10*time.Minute), start.Add(20*time.Minute))
+	assert.Nil(t, err, "Error inserting pause")
+	assert.Equal(t, 1, len(rec.Pause))
+	assert.False(t, rec.IsPaused(), "Expected a finished pause")
+
+	rec, err = client.Stop(start.Add(30 * time.Minute))
+	assert.Nil(t, err, "Error stopping record")
+	assert.True(t, rec.End.Equal(start.Add(30*time.Minute)))
+
+	other := core.NewProject("other", "", "o", []string{}, 15, 0)
+	assert.Nil(t, track.SaveProject(other, false), "Error saving project")
+
+	rec, err = client.Start("test", "", "", nil, start.Add(40*time.Minute))
+	assert.Nil(t, err, "Error starting record")
+	stopped, rec, err := client.Switch("other", "", "", nil, start.Add(50*time.Minute))
+	assert.Nil(t, err, "Error switching record")
+	assert.Equal(t, "test", stopped.Project)
+	assert.True(t, stopped.End.Equal(start.Add(50*time.Minute)))
+	assert.Equal(t, "other", rec.Project)
+
+	_, err = client.Call(Request{Method: "foo"})
+	assert.ErrorContains(t, err, "unknown method")
+
+	assert.Nil(t, l.Close(), "Error closing socket")
+	assert.Nil(t, Connect(&track), "Expected no client after closing the socket")
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	// Stale socket files are replaced
+	assert.Nil(t, os.WriteFile(SocketPath(&track), []byte{}, 0600))
+	l, err = Listen(&track)
+	assert.Nil(t, err, "Error replacing stale socket")
+	assert.Nil(t, l.Close())
+}
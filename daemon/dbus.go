@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/mlange-42/track/core"
+)
+
+const (
+	// DBusName is the bus name of the D-Bus service
+	DBusName = "io.github.mlange42.Track"
+	// DBusInterface is the interface of the D-Bus service
+	DBusInterface = DBusName
+	// DBusPath is the object path of the D-Bus service
+	DBusPath = dbus.ObjectPath("/io/github/mlange42/Track")
+	// DBusSignal is the name of the signal emitted on state changes
+	DBusSignal = "StateChanged"
+)
+
+// Tracking states, as reported by D-Bus method Status and signal StateChanged
+const (
+	StateTracking = "tracking"
+	StatePaused   = "paused"
+	StateIdle     = "idle"
+)
+
+// dbusService is the object exported on D-Bus. All its exported methods are D-Bus methods.
+type dbusService struct {
+	daemon *Daemon
+}
+
+// Start starts a record for a project, with a note that may contain tags
+func (s *dbusService) Start(project, note string) *dbus.Error {
+	tags, err := s.daemon.Track.Syntax().ExtractTags(note)
+	if err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	return s.call(Request{Method: MethodStart, Project: project, Note: note, Tags: tags, Time: time.Now()})
+}
+
+// Stop stops the running record
+func (s *dbusService) Stop() *dbus.Error {
+	return s.call(Request{Method: MethodStop, Time: time.Now()})
+}
+
+// Pause pauses the running record
+func (s *dbusService) Pause(note string) *dbus.Error {
+	return s.call(Request{Method: MethodPause, Note: note, Time: time.Now()})
+}
+
+// Resume resumes the paused running record
+func (s *dbusService) Resume() *dbus.Error {
+	return s.call(Request{Method: MethodResume, Time: time.Now()})
+}
+
+// Status returns the tracking state, and project, note and start time (Unix seconds) of the running record
+func (s *dbusService) Status() (string, string, string, int64, *dbus.Error) {
+	resp := s.daemon.Handle(Request{Method: MethodStatus})
+	if resp.Error != "" {
+		return "", "", "", 0, dbus.MakeFailedError(fmt.Errorf("%s", resp.Error))
+	}
+	if resp.Record == nil {
+		return StateIdle, "", "", 0, nil
+	}
+	return recordState(resp.Record), resp.Record.Project, resp.Record.Note, resp.Record.Start.Unix(), nil
+}
+
+func (s *dbusService) call(req Request) *dbus.Error {
+	if resp := s.daemon.Handle(req); resp.Error != "" {
+		return dbus.MakeFailedError(fmt.Errorf("%s", resp.Error))
+	}
+	return nil
+}
+
+// recordState determines the tracking state of a record
+func recordState(r *core.Record) string {
+	if r == nil || r.HasEnded() {
+		return StateIdle
+	}
+	if r.IsPaused() {
+		return StatePaused
+	}
+	return StateTracking
+}
+
+// ServeDBus exports the control API on a D-Bus connection, under name DBusName.
+//
+// On each tracking event, signal StateChanged is emitted with the new state and the project.
+func (d *Daemon) ServeDBus(conn *dbus.Conn) error {
+	service := &dbusService{daemon: d}
+	if err := conn.Export(service, DBusPath, DBusInterface); err != nil {
+		return err
+	}
+
+	node := &introspect.Node{
+		Name: string(DBusPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			{
+				Name:    DBusInterface,
+				Methods: introspect.Methods(service),
+				Signals: []introspect.Signal{
+					{
+						Name: DBusSignal,
+						Args: []introspect.Arg{
+							{Name: "state", Type: "s"},
+							{Name: "project", Type: "s"},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := conn.Export(introspect.NewIntrospectable(node), DBusPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return err
+	}
+
+	reply, err := conn.RequestName(DBusName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return fmt.Errorf("D-Bus name %s is already taken", DBusName)
+	}
+
+	d.Track.SetEventHook("dbus", func(e core.Event) {
+		_ = conn.Emit(DBusPath, DBusInterface+"."+DBusSignal, eventState(e), e.Record.Project)
+	})
+	return nil
+}
+
+// eventState determines the tracking state after an event
+func eventState(e core.Event) string {
+	switch e.Type {
+	case core.EventStart, core.EventResume:
+		return StateTracking
+	case core.EventPause:
+		return StatePaused
+	}
+	return StateIdle
+}
@@ -0,0 +1,112 @@
+package daemon
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/mlange-42/track/core"
+	"github.com/stretchr/testify/assert"
+)
+
+// startTestBus starts a private D-Bus daemon, and returns its address
+func startTestBus(t *testing.T) string {
+	path, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not available")
+	}
+	cmd := exec.Command(path, "--session", "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	assert.Nil(t, err)
+	if err := cmd.Start(); err != nil {
+		t.Skipf("failed to start dbus-daemon: %s", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+	addr, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Skipf("failed to start dbus-daemon: %s", err)
+	}
+	return strings.TrimSpace(addr)
+}
+
+func connectTestBus(t *testing.T, addr string) *dbus.Conn {
+	conn, err := dbus.Connect(addr)
+	assert.Nil(t, err, "Error connecting to D-Bus")
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestDBus(t *testing.T) {
+	addr := startTestBus(t)
+
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	track, err := core.NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	project := core.NewProject("test", "", "t", []string{}, 15, 0)
+	assert.Nil(t, track.SaveProject(project, false), "Error saving project")
+
+	d := New(&track, time.Minute, func(title, message string) error { return nil })
+	assert.Nil(t, d.ServeDBus(connectTestBus(t, addr)), "Error serving D-Bus")
+
+	client := connectTestBus(t, addr)
+	assert.Nil(t, client.AddMatchSignal(dbus.WithMatchInterface(DBusInterface)))
+	signals := make(chan *dbus.Signal, 10)
+	client.Signal(signals)
+
+	obj := client.Object(DBusName, DBusPath)
+	status := func() (string, string) {
+		var state, project, note string
+		var start int64
+		err := obj.Call(DBusInterface+".Status", 0).Store(&state, &project, &note, &start)
+		assert.Nil(t, err, "Error calling Status")
+		return state, project
+	}
+
+	state, _ := status()
+	assert.Equal(t, StateIdle, state)
+
+	assert.Nil(t, obj.Call(DBusInterface+".Start", 0, "test", "Note +tag").Err)
+	state, proj := status()
+	assert.Equal(t, StateTracking, state)
+	assert.Equal(t, "test", proj)
+
+	assert.NotNil(t, obj.Call(DBusInterface+".Start", 0, "test", "").Err, "Expected error for running record")
+
+	assert.Nil(t, obj.Call(DBusInterface+".Pause", 0, "").Err)
+	state, _ = status()
+	assert.Equal(t, StatePaused, state)
+
+	assert.Nil(t, obj.Call(DBusInterface+".Resume", 0).Err)
+	assert.Nil(t, obj.Call(DBusInterface+".Stop", 0).Err)
+	state, _ = status()
+	assert.Equal(t, StateIdle, state)
+
+	states := []string{}
+	timeout := time.After(5 * time.Second)
+	for len(states) < 4 {
+		select {
+		case sig := <-signals:
+			if sig.Name != DBusInterface+"."+DBusSignal {
+				continue
+			}
+			states = append(states, sig.Body[0].(string))
+		case <-timeout:
+			t.Fatalf("timeout waiting for signals, got %v", states)
+		}
+	}
+	assert.Equal(t, []string{StateTracking, StatePaused, StateTracking, StateIdle}, states)
+
+	// The name can't be taken twice
+	d2 := New(&track, time.Minute, func(title, message string) error { return nil })
+	assert.ErrorContains(t, d2.ServeDBus(connectTestBus(t, addr)), "already taken")
+}
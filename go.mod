@@ -3,11 +3,14 @@ module github.com/mlange-42/track
 go 1.19
 
 require (
+	github.com/godbus/dbus/v5 v5.1.0
 	github.com/gookit/color v1.5.2
 	github.com/nikolaydubina/treemap v1.2.4
 	github.com/spf13/cobra v1.6.1
 	github.com/stretchr/testify v1.8.1
 	golang.org/x/exp v0.0.0-20221217163422-3c43f8badb15
+	golang.org/x/image v0.5.0
+	golang.org/x/sys v0.4.0
 	golang.org/x/term v0.4.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -19,5 +22,4 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/xo/terminfo v0.0.0-20210125001918-ca9a967f8778 // indirect
-	golang.org/x/sys v0.4.0 // indirect
 )
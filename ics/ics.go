@@ -0,0 +1,260 @@
+// Package ics parses events from iCalendar (ICS) files.
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+)
+
+// Event is a calendar event
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Categories  []string
+	Status      string
+	// Start of the event. For all-day events, the first day at 00:00 in the local time zone
+	Start time.Time
+	// End of the event, exclusive. For all-day events, the day after the last day at 00:00 in the local time zone
+	End time.Time
+	// Whether the event is an all-day event
+	AllDay bool
+}
+
+// Days returns the days of the event, from the day of its start to the day of its end.
+// For all-day events, the exclusive end day is not included.
+func (e *Event) Days() []time.Time {
+	start := time.Date(e.Start.Year(), e.Start.Month(), e.Start.Day(), 0, 0, 0, 0, time.Local)
+	end := e.End
+	if end.Before(e.Start) {
+		end = e.Start
+	}
+	days := []time.Time{}
+	for day := start; day.Before(end) || day.Equal(start); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// Parse parses all events from an ICS calendar.
+// Times without time zone are in the local time zone.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []Event{}
+	var event *Event
+	var duration time.Duration
+	hasEnd := false
+	for i, line := range lines {
+		name, params, value, ok := splitProperty(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			event = &Event{}
+			duration = 0
+			hasEnd = false
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if event == nil {
+				return nil, fmt.Errorf("line %d: unexpected end of event", i+1)
+			}
+			if event.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event '%s' without start", i+1, event.Summary)
+			}
+			if !hasEnd {
+				switch {
+				case duration > 0:
+					event.End = event.Start.Add(duration)
+				case event.AllDay:
+					event.End = event.Start.AddDate(0, 0, 1)
+				default:
+					event.End = event.Start
+				}
+			}
+			events = append(events, *event)
+			event = nil
+		case event == nil:
+			continue
+		case name == "UID":
+			event.UID = value
+		case name == "SUMMARY":
+			event.Summary = unescape(value)
+		case name == "DESCRIPTION":
+			event.Description = unescape(value)
+		case name == "LOCATION":
+			event.Location = unescape(value)
+		case name == "STATUS":
+			event.Status = strings.ToUpper(value)
+		case name == "CATEGORIES":
+			for _, c := range splitList(value) {
+				event.Categories = append(event.Categories, unescape(c))
+			}
+		case name == "DTSTART":
+			tm, allDay, err := parseTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", i+1, err)
+			}
+			event.Start, event.AllDay = tm, allDay
+		case name == "DTEND":
+			tm, _, err := parseTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", i+1, err)
+			}
+			event.End = tm
+			hasEnd = true
+		case name == "DURATION":
+			d, err := parseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", i+1, err)
+			}
+			duration = d
+		}
+	}
+	if event != nil {
+		return nil, fmt.Errorf("unterminated event '%s'", event.Summary)
+	}
+	return events, nil
+}
+
+// unfold reads all content lines, joining folded lines
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lines := []string{}
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitProperty splits a content line into the property name, its parameters and its value
+func splitProperty(line string) (string, map[string]string, string, bool) {
+	colon := -1
+	quoted := false
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		}
+		if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+	parts := strings.Split(line[:colon], ";")
+	params := map[string]string{}
+	for _, p := range parts[1:] {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], "\"")
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+// parseTime parses a DATE or DATE-TIME value
+func parseTime(value string, params map[string]string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		tm, err := time.ParseInLocation(dateLayout, value, time.Local)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date '%s'", value)
+		}
+		return tm, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		tm, err := time.Parse(dateTimeLayout, strings.TrimSuffix(value, "Z"))
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time '%s'", value)
+		}
+		return tm.Local(), false, nil
+	}
+	loc := time.Local
+	if tzid, ok := params["TZID"]; ok {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	tm, err := time.ParseInLocation(dateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date-time '%s'", value)
+	}
+	return tm.Local(), false, nil
+}
+
+var durationRegex = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses a DURATION value, like PT1H30M
+func parseDuration(value string) (time.Duration, error) {
+	m := durationRegex.FindStringSubmatch(value)
+	if m == nil || value == "P" || value == "PT" {
+		return 0, fmt.Errorf("invalid duration '%s'", value)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s'", value)
+		}
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// splitList splits a comma-separated list value, respecting escaped commas
+func splitList(value string) []string {
+	parts := []string{}
+	current := strings.Builder{}
+	escaped := false
+	for _, c := range value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == ',':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(c)
+		}
+	}
+	return append(parts, current.String())
+}
+
+var unescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+// unescape reverts the escaping of TEXT values
+func unescape(value string) string {
+	return unescaper.Replace(value)
+}
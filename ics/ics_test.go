@@ -0,0 +1,82 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const calendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:1\r\n" +
+	"DTSTART;VALUE=DATE:20230101\r\n" +
+	"SUMMARY:New Year's Day\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:2\r\n" +
+	"DTSTART;VALUE=DATE:20231224\r\n" +
+	"DTEND;VALUE=DATE:20231227\r\n" +
+	"SUMMARY:Christmas\\, and more\r\n" +
+	"CATEGORIES:Holiday,Family\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:3\r\n" +
+	"DTSTART:20230102T080000Z\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"SUMMARY:Weekly meeting with a very long title that is\r\n" +
+	"  folded\r\n" +
+	"DESCRIPTION:Line 1\\nLine 2\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(calendar))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(events))
+
+	day := func(m time.Month, d int) time.Time {
+		return time.Date(2023, m, d, 0, 0, 0, 0, time.Local)
+	}
+
+	e := events[0]
+	assert.True(t, e.AllDay)
+	assert.Equal(t, "New Year's Day", e.Summary)
+	assert.Equal(t, day(1, 1), e.Start)
+	assert.Equal(t, day(1, 2), e.End)
+	assert.Equal(t, []time.Time{day(1, 1)}, e.Days())
+
+	e = events[1]
+	assert.Equal(t, "Christmas, and more", e.Summary)
+	assert.Equal(t, []string{"Holiday", "Family"}, e.Categories)
+	assert.Equal(t, []time.Time{day(12, 24), day(12, 25), day(12, 26)}, e.Days())
+
+	e = events[2]
+	assert.False(t, e.AllDay)
+	assert.Equal(t, "Weekly meeting with a very long title that is folded", e.Summary)
+	assert.Equal(t, "Line 1\nLine 2", e.Description)
+	assert.True(t, e.Start.Equal(time.Date(2023, 1, 2, 8, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 90*time.Minute, e.End.Sub(e.Start))
+
+	_, err = Parse(strings.NewReader("BEGIN:VEVENT\nSUMMARY:Test\n"))
+	assert.NotNil(t, err, "Error expected for unterminated event")
+	_, err = Parse(strings.NewReader("BEGIN:VEVENT\nDTSTART:2023\nEND:VEVENT\n"))
+	assert.NotNil(t, err, "Error expected for invalid date")
+}
+
+func TestParseDuration(t *testing.T) {
+	d, err := parseDuration("P1DT2H")
+	assert.Nil(t, err)
+	assert.Equal(t, 26*time.Hour, d)
+
+	d, err = parseDuration("-PT15M")
+	assert.Nil(t, err)
+	assert.Equal(t, -15*time.Minute, d)
+
+	_, err = parseDuration("P")
+	assert.NotNil(t, err)
+	_, err = parseDuration("1H")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,195 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+)
+
+const (
+	// ActivityWatchName is the name of the ActivityWatch integration
+	ActivityWatchName = "activitywatch"
+
+	activityWatchDefaultURL   = "http://localhost:5600"
+	activityWatchBucketPrefix = "aw-watcher-window"
+	activityWatchMinDuration  = 5 * time.Minute
+	activityWatchMaxGap       = 2 * time.Minute
+)
+
+// ActivityWatchConfig is the config of the ActivityWatch integration
+type ActivityWatchConfig struct {
+	// Base URL of the ActivityWatch server. Defaults to http://localhost:5600
+	URL string `yaml:"url,omitempty"`
+	// ID of the window watcher bucket. Defaults to the first bucket starting with "aw-watcher-window"
+	Bucket string `yaml:"bucket,omitempty"`
+	// Minimum duration of proposed records. Defaults to 5 minutes
+	MinDuration time.Duration `yaml:"minDuration,omitempty"`
+	// Maximum gap between events of the same project to join them. Defaults to 2 minutes
+	MaxGap time.Duration `yaml:"maxGap,omitempty"`
+	// Rules for mapping window events to projects. The first matching rule applies
+	Rules []ActivityWatchRule `yaml:"rules"`
+}
+
+// ActivityWatchRule maps window events to a project, by regular expressions for the application and window title.
+// Both expressions must match if given.
+type ActivityWatchRule struct {
+	// Target project
+	Project string `yaml:"project"`
+	// Regular expression for the application name, like "(?i)code"
+	App string `yaml:"app,omitempty"`
+	// Regular expression for the window title, like "track - .*"
+	Title string `yaml:"title,omitempty"`
+	// Note for proposed records
+	Note string `yaml:"note,omitempty"`
+
+	app   *regexp.Regexp
+	title *regexp.Regexp
+}
+
+// ActivityWatchEvent is an event of an ActivityWatch window watcher
+type ActivityWatchEvent struct {
+	// Start of the event
+	Timestamp time.Time `json:"timestamp"`
+	// Duration in seconds
+	Duration float64 `json:"duration"`
+	// Application and window title
+	Data struct {
+		App   string `json:"app"`
+		Title string `json:"title"`
+	} `json:"data"`
+}
+
+// End returns the end time of the event
+func (e *ActivityWatchEvent) End() time.Time {
+	return e.Timestamp.Add(time.Duration(e.Duration * float64(time.Second)))
+}
+
+// ActivityWatch reads window events from ActivityWatch, and proposes records for them
+type ActivityWatch struct {
+	config ActivityWatchConfig
+	client *http.Client
+}
+
+// NewActivityWatch creates an ActivityWatch integration from the config file of the current workspace
+func NewActivityWatch(t *core.Track) (*ActivityWatch, error) {
+	conf := ActivityWatchConfig{}
+	if err := LoadConfig(t, ActivityWatchName, &conf); err != nil {
+		return nil, err
+	}
+	if conf.URL == "" {
+		conf.URL = activityWatchDefaultURL
+	}
+	conf.URL = strings.TrimSuffix(conf.URL, "/")
+	if conf.MinDuration <= 0 {
+		conf.MinDuration = activityWatchMinDuration
+	}
+	if conf.MaxGap <= 0 {
+		conf.MaxGap = activityWatchMaxGap
+	}
+	if len(conf.Rules) == 0 {
+		return nil, fmt.Errorf("no rules in %s", ConfigPath(t, ActivityWatchName))
+	}
+	for i := range conf.Rules {
+		if err := conf.Rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("invalid rule %d in %s: %s", i+1, ConfigPath(t, ActivityWatchName), err)
+		}
+	}
+	return &ActivityWatch{
+		config: conf,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// compile compiles the regular expressions of a rule
+func (r *ActivityWatchRule) compile() error {
+	if r.Project == "" {
+		return fmt.Errorf("missing project")
+	}
+	if r.App == "" && r.Title == "" {
+		return fmt.Errorf("rule for '%s' needs an app or title expression", r.Project)
+	}
+	var err error
+	if r.App != "" {
+		if r.app, err = regexp.Compile(r.App); err != nil {
+			return err
+		}
+	}
+	if r.Title != "" {
+		if r.title, err = regexp.Compile(r.Title); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matches checks if a rule matches an event
+func (r *ActivityWatchRule) matches(e *ActivityWatchEvent) bool {
+	return (r.app == nil || r.app.MatchString(e.Data.App)) &&
+		(r.title == nil || r.title.MatchString(e.Data.Title))
+}
+
+// Events fetches the window events between start and end from the ActivityWatch server
+func (aw *ActivityWatch) Events(start, end time.Time) ([]ActivityWatchEvent, error) {
+	bucket := aw.config.Bucket
+	if bucket == "" {
+		buckets := map[string]interface{}{}
+		if err := requestJSON(aw.client, http.MethodGet, aw.config.URL+"/api/0/buckets/", nil, nil, &buckets); err != nil {
+			return nil, err
+		}
+		ids := []string{}
+		for id := range buckets {
+			if strings.HasPrefix(id, activityWatchBucketPrefix) {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no window watcher bucket found")
+		}
+		sort.Strings(ids)
+		bucket = ids[0]
+	}
+
+	query := url.Values{}
+	query.Set("start", start.Format(time.RFC3339))
+	query.Set("end", end.Format(time.RFC3339))
+	query.Set("limit", "-1")
+	events := []ActivityWatchEvent{}
+	path := fmt.Sprintf("%s/api/0/buckets/%s/events?%s", aw.config.URL, url.PathEscape(bucket), query.Encode())
+	if err := requestJSON(aw.client, http.MethodGet, path, nil, nil, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Suggest proposes records for window events, mapped to projects by the rules.
+//
+// Consecutive events of the same project are joined if the gap between them is at most the configured maximum gap.
+// Proposals only fill the gaps between the given existing records,
+// and proposals shorter than the configured minimum duration are dropped.
+// Events are clipped to the time span from start to end.
+func (aw *ActivityWatch) Suggest(events []ActivityWatchEvent, records []core.Record, start, end time.Time) []core.Record {
+	blocks := []core.Record{}
+	for i := range events {
+		e := &events[i]
+		if rule := aw.match(e); rule != nil {
+			blocks = append(blocks, core.Record{Project: rule.Project, Note: rule.Note, Start: e.Timestamp.Local(), End: e.End().Local()})
+		}
+	}
+	return proposeRecords(blocks, records, start, end, aw.config.MinDuration, aw.config.MaxGap)
+}
+
+// match returns the first rule matching an event, or nil
+func (aw *ActivityWatch) match(e *ActivityWatchEvent) *ActivityWatchRule {
+	for i := range aw.config.Rules {
+		if aw.config.Rules[i].matches(e) {
+			return &aw.config.Rules[i]
+		}
+	}
+	return nil
+}
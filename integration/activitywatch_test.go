@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func awEvent(start time.Time, minutes float64, app, title string) ActivityWatchEvent {
+	e := ActivityWatchEvent{Timestamp: start, Duration: minutes * 60}
+	e.Data.App = app
+	e.Data.Title = title
+	return e
+}
+
+func TestActivityWatch(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	events := []ActivityWatchEvent{
+		awEvent(util.DateTime(2001, 2, 3, 9, 0, 0), 20, "Code", "main.go - track"),
+		awEvent(util.DateTime(2001, 2, 3, 9, 21, 0), 9, "Code", "README.md - track"),
+		awEvent(util.DateTime(2001, 2, 3, 9, 30, 0), 3, "Firefox", "Some news"),
+		awEvent(util.DateTime(2001, 2, 3, 9, 33, 0), 30, "Thunderbird", "Inbox"),
+		awEvent(util.DateTime(2001, 2, 3, 10, 3, 0), 2, "Code", "main.go - track"),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/0/buckets/":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"aw-watcher-afk_host":    map[string]string{},
+				"aw-watcher-window_host": map[string]string{},
+			})
+		case "/api/0/buckets/aw-watcher-window_host/events":
+			assert.Equal(t, "-1", r.URL.Query().Get("limit"))
+			_ = json.NewEncoder(w).Encode(events)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	assert.Nil(t, util.CreateDir(Dir(track)))
+	config := `url: ` + server.URL + `/
+rules:
+  - project: track
+    app: Code
+    title: "- track$"
+    note: Development +dev
+  - project: mail
+    app: Thunderbird
+`
+	assert.Nil(t, os.WriteFile(ConfigPath(track, ActivityWatchName), []byte(config), 0600))
+
+	aw, err := NewActivityWatch(track)
+	assert.Nil(t, err)
+
+	start, end := util.Date(2001, 2, 3), util.Date(2001, 2, 4)
+	fetched, err := aw.Events(start, end)
+	assert.Nil(t, err)
+	assert.Equal(t, len(events), len(fetched))
+
+	suggested := aw.Suggest(fetched, []core.Record{}, start, end)
+	assert.Equal(t, 2, len(suggested))
+	assert.Equal(t, "track", suggested[0].Project)
+	assert.Equal(t, "Development +dev", suggested[0].Note)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 9, 0, 0), suggested[0].Start)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 9, 30, 0), suggested[0].End)
+	assert.Equal(t, "mail", suggested[1].Project)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 10, 3, 0), suggested[1].End)
+
+	// Only untracked gaps are filled
+	records := []core.Record{
+		{Project: "other", Start: util.DateTime(2001, 2, 3, 9, 10, 0), End: util.DateTime(2001, 2, 3, 9, 27, 0)},
+		{Project: "other", Start: util.DateTime(2001, 2, 3, 9, 40, 0)},
+	}
+	suggested = aw.Suggest(fetched, records, start, end)
+	assert.Equal(t, 2, len(suggested))
+	assert.Equal(t, util.DateTime(2001, 2, 3, 9, 10, 0), suggested[0].End)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 9, 33, 0), suggested[1].Start)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 9, 40, 0), suggested[1].End)
+
+	assert.Nil(t, os.WriteFile(ConfigPath(track, ActivityWatchName), []byte("rules:\n  - project: track\n"), 0600))
+	_, err = NewActivityWatch(track)
+	assert.NotNil(t, err)
+}
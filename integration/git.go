@@ -0,0 +1,224 @@
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+)
+
+const (
+	// GitName is the name of the git integration
+	GitName = "git"
+
+	gitDefaultIssuePattern = `[A-Z][A-Z0-9]+-[0-9]+`
+)
+
+// GitConfig is the config of the git integration
+type GitConfig struct {
+	// Pattern for commit authors, like an email address. All commits if not specified
+	Author string `yaml:"author,omitempty"`
+	// Regular expression for issue keys in branch names. Defaults to keys like PROJ-123
+	IssuePattern string `yaml:"issuePattern,omitempty"`
+	// Working time assumed before each commit, for proposed records. Defaults to 30 minutes
+	Lead time.Duration `yaml:"lead,omitempty"`
+	// Minimum duration of proposed records. Defaults to 5 minutes
+	MinDuration time.Duration `yaml:"minDuration,omitempty"`
+	// Maximum gap between commits of the same branch to join them. Defaults to 30 minutes
+	MaxGap time.Duration `yaml:"maxGap,omitempty"`
+	// Repositories to scan
+	Repositories []GitRepository `yaml:"repositories"`
+}
+
+// GitRepository is a git repository, with the project its commits belong to
+type GitRepository struct {
+	// Path of the repository
+	Path string `yaml:"path"`
+	// Project of the repository's commits
+	Project string `yaml:"project"`
+}
+
+// GitCommit is a commit in a scanned repository
+type GitCommit struct {
+	// Project of the repository
+	Project string
+	// Commit hash
+	Hash string
+	// Commit time
+	Time time.Time
+	// Branch the commit was found on, like "feature/PROJ-123-login"
+	Branch string
+	// First line of the commit message
+	Subject string
+}
+
+// Git scans git repositories for commits, and annotates or proposes records based on them
+type Git struct {
+	config GitConfig
+	issues *regexp.Regexp
+	syntax *core.Syntax
+}
+
+// NewGit creates a git integration from the config file of the current workspace
+func NewGit(t *core.Track) (*Git, error) {
+	conf := GitConfig{}
+	if err := LoadConfig(t, GitName, &conf); err != nil {
+		return nil, err
+	}
+	if conf.IssuePattern == "" {
+		conf.IssuePattern = gitDefaultIssuePattern
+	}
+	if conf.Lead <= 0 {
+		conf.Lead = 30 * time.Minute
+	}
+	if conf.MinDuration <= 0 {
+		conf.MinDuration = 5 * time.Minute
+	}
+	if conf.MaxGap <= 0 {
+		conf.MaxGap = 30 * time.Minute
+	}
+	if len(conf.Repositories) == 0 {
+		return nil, fmt.Errorf("no repositories in %s", ConfigPath(t, GitName))
+	}
+	for i, repo := range conf.Repositories {
+		if repo.Path == "" || repo.Project == "" {
+			return nil, fmt.Errorf("repository %d in %s needs a path and a project", i+1, ConfigPath(t, GitName))
+		}
+	}
+	issues, err := regexp.Compile(conf.IssuePattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issuePattern in %s: %s", ConfigPath(t, GitName), err)
+	}
+	return &Git{config: conf, issues: issues, syntax: t.Syntax()}, nil
+}
+
+// Commits scans all repositories for commits between start and end, sorted by time.
+// Requires the git command line tool.
+func (g *Git) Commits(start, end time.Time) ([]GitCommit, error) {
+	commits := []GitCommit{}
+	for _, repo := range g.config.Repositories {
+		args := []string{
+			"-C", repo.Path, "log", "--all", "--source",
+			"--since=" + start.Format(time.RFC3339), "--until=" + end.Format(time.RFC3339),
+			"--format=%H%x09%cI%x09%S%x09%s",
+		}
+		if g.config.Author != "" {
+			args = append(args, "--author="+g.config.Author)
+		}
+		cmd := exec.Command("git", args...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan repository %s: %s", repo.Path, strings.TrimSpace(stderr.String()))
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			parts := strings.SplitN(line, "\t", 4)
+			if len(parts) < 4 {
+				continue
+			}
+			tm, err := time.Parse(time.RFC3339, parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan repository %s: %s", repo.Path, err)
+			}
+			commits = append(commits, GitCommit{
+				Project: repo.Project,
+				Hash:    parts[0],
+				Time:    tm.Local(),
+				Branch:  gitBranchName(parts[2]),
+				Subject: parts[3],
+			})
+		}
+	}
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].Time.Before(commits[j].Time) })
+	return commits, nil
+}
+
+// Issues extracts the issue keys from the branch name of a commit
+func (g *Git) Issues(c *GitCommit) []string {
+	return g.issues.FindAllString(c.Branch, -1)
+}
+
+// Annotate adds issue tags from the branches of commits to the records of the same project the commits were made in.
+// Tags are appended to the notes of the records, and only added if the record does not have them already.
+//
+// Returns only the changed records. Locked records are not changed.
+func (g *Git) Annotate(records []core.Record, commits []GitCommit) ([]core.Record, error) {
+	changed := []core.Record{}
+	for _, rec := range records {
+		if rec.Locked {
+			continue
+		}
+		end := rec.End
+		if end.IsZero() {
+			end = time.Now()
+		}
+		note := rec.Note
+		for i := range commits {
+			c := &commits[i]
+			if c.Project != rec.Project || c.Time.Before(rec.Start) || c.Time.After(end) {
+				continue
+			}
+			for _, issue := range g.Issues(c) {
+				tags, err := g.syntax.ExtractTags(note)
+				if err != nil {
+					return nil, err
+				}
+				if _, ok := tags[g.syntax.NormalizeTag(issue)]; ok {
+					continue
+				}
+				note = strings.TrimSpace(note + " " + g.syntax.TagPrefix + issue)
+			}
+		}
+		if note == rec.Note {
+			continue
+		}
+		tags, err := g.syntax.ExtractTags(note)
+		if err != nil {
+			return nil, err
+		}
+		rec.Note, rec.Tags = note, tags
+		changed = append(changed, rec)
+	}
+	return changed, nil
+}
+
+// Suggest proposes records for commits, assuming the configured working time before each commit.
+// Records get the issue tags from the branch names as note.
+//
+// Consecutive commits with the same project and issues are joined if the gap between them is at most the configured maximum gap.
+// Proposals only fill the gaps between the given existing records,
+// and proposals shorter than the configured minimum duration are dropped.
+// Proposals are clipped to the time span from start to end.
+func (g *Git) Suggest(commits []GitCommit, records []core.Record, start, end time.Time) []core.Record {
+	blocks := []core.Record{}
+	for i := range commits {
+		c := &commits[i]
+		issues := g.Issues(c)
+		for j := range issues {
+			issues[j] = g.syntax.TagPrefix + issues[j]
+		}
+		blocks = append(blocks, core.Record{
+			Project: c.Project,
+			Note:    strings.Join(issues, " "),
+			Start:   c.Time.Add(-g.config.Lead),
+			End:     c.Time,
+		})
+	}
+	return proposeRecords(blocks, records, start, end, g.config.MinDuration, g.config.MaxGap)
+}
+
+// gitBranchName shortens a ref name like "refs/heads/main" to the branch name
+func gitBranchName(ref string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/remotes/", "refs/tags/"} {
+		if strings.HasPrefix(ref, prefix) {
+			return strings.TrimPrefix(ref, prefix)
+		}
+	}
+	return ref
+}
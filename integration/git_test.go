@@ -0,0 +1,83 @@
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// runGit runs a git command in a directory, with a fixed author and commit time
+func runGit(t *testing.T, dir string, tm time.Time, args ...string) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	date := tm.Format(time.RFC3339)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE="+date,
+	)
+	output, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(output))
+}
+
+func TestGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	repo := filepath.Join(track.RootDir, "repo")
+	assert.Nil(t, util.CreateDir(repo))
+	runGit(t, repo, time.Now(), "init", "-q", "-b", "main")
+	runGit(t, repo, util.DateTime(2001, 2, 3, 9, 0, 0), "commit", "-q", "--allow-empty", "-m", "Initial commit")
+	runGit(t, repo, time.Now(), "checkout", "-q", "-b", "feature/PROJ-123-login")
+	runGit(t, repo, util.DateTime(2001, 2, 3, 11, 0, 0), "commit", "-q", "--allow-empty", "-m", "Add login")
+	runGit(t, repo, util.DateTime(2001, 2, 3, 11, 40, 0), "commit", "-q", "--allow-empty", "-m", "Fix login")
+
+	assert.Nil(t, util.CreateDir(Dir(track)))
+	config := `repositories:
+  - path: ` + repo + `
+    project: track
+`
+	assert.Nil(t, os.WriteFile(ConfigPath(track, GitName), []byte(config), 0600))
+
+	git, err := NewGit(track)
+	assert.Nil(t, err)
+
+	start, end := util.Date(2001, 2, 3), util.Date(2001, 2, 4)
+	commits, err := git.Commits(start, end)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(commits))
+	assert.Equal(t, "Initial commit", commits[0].Subject)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 11, 0, 0), commits[1].Time)
+	assert.Equal(t, "feature/PROJ-123-login", commits[1].Branch)
+	assert.Equal(t, []string{"PROJ-123"}, git.Issues(&commits[1]))
+
+	records := []core.Record{
+		{Project: "track", Note: "Work", Start: util.DateTime(2001, 2, 3, 10, 0, 0), End: util.DateTime(2001, 2, 3, 11, 15, 0)},
+		{Project: "other", Start: util.DateTime(2001, 2, 3, 11, 30, 0), End: util.DateTime(2001, 2, 3, 11, 45, 0)},
+	}
+	annotated, err := git.Annotate(records, commits)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(annotated))
+	assert.Equal(t, "Work +PROJ-123", annotated[0].Note)
+	assert.Equal(t, map[string]string{"PROJ-123": ""}, annotated[0].Tags)
+
+	annotated, err = git.Annotate(annotated, commits)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(annotated))
+
+	suggested := git.Suggest(commits, records, start, end)
+	assert.Equal(t, 2, len(suggested))
+	assert.Equal(t, util.DateTime(2001, 2, 3, 8, 30, 0), suggested[0].Start)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 9, 0, 0), suggested[0].End)
+	assert.Equal(t, "", suggested[0].Note)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 11, 15, 0), suggested[1].Start)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 11, 30, 0), suggested[1].End)
+	assert.Equal(t, "+PROJ-123", suggested[1].Note)
+}
@@ -0,0 +1,140 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+)
+
+const (
+	// HarvestName is the name of the Harvest integration
+	HarvestName = "harvest"
+	// HarvestTokenEnvVar is the environment variable for the Harvest personal access token
+	HarvestTokenEnvVar = "TRACK_HARVEST_TOKEN"
+
+	harvestURL = "https://api.harvestapp.com/v2"
+)
+
+// HarvestConfig is the config of the Harvest integration, with the mapping of projects to Harvest projects and tasks
+type HarvestConfig struct {
+	// Harvest account ID
+	AccountID string `yaml:"accountId"`
+	// Base URL of the API. Defaults to the Harvest API v2
+	URL string `yaml:"url,omitempty"`
+	// Records with this tag are not pushed. Defaults to "nonbillable"
+	NonBillableTag string `yaml:"nonBillableTag,omitempty"`
+	// Mapping of track projects to Harvest projects and tasks
+	Projects map[string]HarvestProject `yaml:"projects"`
+}
+
+// HarvestProject is the Harvest project and task for a track project
+type HarvestProject struct {
+	ProjectID int64 `yaml:"projectId"`
+	TaskID    int64 `yaml:"taskId"`
+	// Set to false to not push records of the project
+	Billable *bool `yaml:"billable,omitempty"`
+}
+
+// Harvest pushes records as time entries to Harvest
+type Harvest struct {
+	config HarvestConfig
+	token  string
+	client *http.Client
+}
+
+// harvestTimeEntry is a time entry in the Harvest API
+type harvestTimeEntry struct {
+	ID        int64   `json:"id,omitempty"`
+	ProjectID int64   `json:"project_id"`
+	TaskID    int64   `json:"task_id"`
+	SpentDate string  `json:"spent_date"`
+	Hours     float64 `json:"hours"`
+	Notes     string  `json:"notes"`
+}
+
+// NewHarvest creates a Harvest integration from the config file of the current workspace.
+// The access token is read from environment variable TRACK_HARVEST_TOKEN.
+func NewHarvest(t *core.Track) (*Harvest, error) {
+	conf := HarvestConfig{}
+	if err := LoadConfig(t, HarvestName, &conf); err != nil {
+		return nil, err
+	}
+	if conf.AccountID == "" {
+		return nil, fmt.Errorf("missing accountId in %s", ConfigPath(t, HarvestName))
+	}
+	if conf.URL == "" {
+		conf.URL = harvestURL
+	}
+	if conf.NonBillableTag == "" {
+		conf.NonBillableTag = "nonbillable"
+	}
+	token := os.Getenv(HarvestTokenEnvVar)
+	if token == "" {
+		return nil, fmt.Errorf("no Harvest access token; please set environment variable %s", HarvestTokenEnvVar)
+	}
+	return &Harvest{
+		config: conf,
+		token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Name of the integration
+func (h *Harvest) Name() string {
+	return HarvestName
+}
+
+// Accept reports whether a record should be pushed.
+// Records of unmapped or non-billable projects, and records with the non-billable tag are skipped.
+func (h *Harvest) Accept(r *core.Record) string {
+	project, ok := h.config.Projects[r.Project]
+	if !ok {
+		return "no Harvest project mapping"
+	}
+	if project.Billable != nil && !*project.Billable {
+		return "project is not billable"
+	}
+	if _, ok := r.Tags[h.config.NonBillableTag]; ok {
+		return "record is not billable"
+	}
+	return ""
+}
+
+// Push creates or updates a time entry for a record
+func (h *Harvest) Push(r *core.Record, remoteID string) (string, error) {
+	project := h.config.Projects[r.Project]
+	entry := harvestTimeEntry{
+		ProjectID: project.ProjectID,
+		TaskID:    project.TaskID,
+		SpentDate: r.Start.Format(util.DateFormat),
+		Hours:     roundHours(r.Duration(util.NoTime, util.NoTime)),
+		Notes:     strings.TrimSpace(r.Note),
+	}
+	header := map[string]string{
+		"Authorization":      "Bearer " + h.token,
+		"Harvest-Account-Id": h.config.AccountID,
+	}
+
+	var result harvestTimeEntry
+	var err error
+	if remoteID == "" {
+		err = requestJSON(h.client, http.MethodPost, h.config.URL+"/time_entries", header, &entry, &result)
+	} else {
+		err = requestJSON(h.client, http.MethodPatch, h.config.URL+"/time_entries/"+remoteID, header, &entry, &result)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(result.ID, 10), nil
+}
+
+// roundHours converts a duration to hours, rounded to full minutes
+func roundHours(d time.Duration) float64 {
+	return d.Round(time.Minute).Minutes() / 60
+}
@@ -0,0 +1,83 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHarvest(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	entries := map[string]harvestTimeEntry{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "42", r.Header.Get("Harvest-Account-Id"))
+
+		var entry harvestTimeEntry
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&entry))
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/time_entries":
+			entry.ID = 100
+		case r.Method == http.MethodPatch && r.URL.Path == "/time_entries/100":
+			entry.ID = 100
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		entries[r.Method] = entry
+		_ = json.NewEncoder(w).Encode(entry)
+	}))
+	defer server.Close()
+
+	assert.Nil(t, util.CreateDir(Dir(track)))
+	config := `accountId: "42"
+url: ` + server.URL + `
+projects:
+  test:
+    projectId: 1
+    taskId: 2
+  internal:
+    projectId: 3
+    taskId: 4
+    billable: false
+`
+	assert.Nil(t, os.WriteFile(ConfigPath(track, HarvestName), []byte(config), 0600))
+
+	_, err := NewHarvest(track)
+	assert.NotNil(t, err, "Expected error for missing token")
+
+	t.Setenv(HarvestTokenEnvVar, "secret")
+	harvest, err := NewHarvest(track)
+	assert.Nil(t, err)
+
+	record := core.Record{
+		Project: "test",
+		Note:    "Some work",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 35, 0),
+	}
+	assert.Equal(t, "", harvest.Accept(&record))
+	assert.NotEqual(t, "", harvest.Accept(&core.Record{Project: "internal"}))
+	assert.NotEqual(t, "", harvest.Accept(&core.Record{Project: "test", Tags: map[string]string{"nonbillable": ""}}))
+	assert.NotEqual(t, "", harvest.Accept(&core.Record{Project: "unknown"}))
+
+	id, err := harvest.Push(&record, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "100", id)
+	assert.Equal(t, harvestTimeEntry{
+		ID: 100, ProjectID: 1, TaskID: 2, SpentDate: "2001-02-03", Hours: 1.5, Notes: "Some work",
+	}, entries[http.MethodPost])
+
+	id, err = harvest.Push(&record, "100")
+	assert.Nil(t, err)
+	assert.Equal(t, "100", id)
+	assert.Equal(t, 1.5, entries[http.MethodPatch].Hours)
+}
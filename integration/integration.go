@@ -0,0 +1,403 @@
+// Package integration pushes records as time entries to external time booking services,
+// and forwards tracking events to external services.
+package integration
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"gopkg.in/yaml.v3"
+)
+
+const integrationsDirName = "integrations"
+
+// Pusher creates or updates time entries for records in an external service
+type Pusher interface {
+	// Name of the integration, used for its files
+	Name() string
+	// Accept reports whether a finished record should be pushed.
+	// Returns an empty string if it should be pushed, or the reason for skipping it.
+	Accept(r *core.Record) string
+	// Push creates a time entry for a record, or updates the entry with the given remote ID if it is not empty.
+	// Returns the remote ID of the entry.
+	Push(r *core.Record, remoteID string) (string, error)
+}
+
+// EventHandler forwards tracking events to an external service
+type EventHandler interface {
+	// Name of the integration, used for its files
+	Name() string
+	// HandleEvent handles a tracking event
+	HandleEvent(e core.Event) error
+}
+
+// SyncedRecord is the sync state of a record that was pushed to an external service
+type SyncedRecord struct {
+	// ID of the time entry in the external service
+	RemoteID string `json:"remoteId"`
+	// Time of the last push
+	Synced time.Time `json:"synced"`
+	// Checksum of the record when it was pushed
+	Checksum string `json:"checksum"`
+}
+
+// SyncLog stores the sync state of all pushed records of an integration, by record ID
+type SyncLog struct {
+	path    string
+	fs      core.FileSystem
+	Records map[string]SyncedRecord
+}
+
+// PushResult reports the outcome of pushing records
+type PushResult struct {
+	// Records pushed as new time entries
+	Created int
+	// Records that were changed since the last push, and updated in the external service
+	Updated int
+	// Records that were not changed since the last push
+	Unchanged int
+	// Records that were skipped, e.g. because they are still running or not billable
+	Skipped int
+}
+
+// SyncStatus is the sync status of a record
+type SyncStatus string
+
+const (
+	// StatusPending is the status of records that were not pushed yet
+	StatusPending SyncStatus = "pending"
+	// StatusChanged is the status of records that changed since the last push
+	StatusChanged SyncStatus = "changed"
+	// StatusSynced is the status of records that are unchanged since the last push
+	StatusSynced SyncStatus = "synced"
+	// StatusSkipped is the status of records that are not pushed
+	StatusSkipped SyncStatus = "skipped"
+)
+
+// RecordStatus is the sync status of a single record
+type RecordStatus struct {
+	Record core.Record
+	Status SyncStatus
+	// ID of the time entry in the external service, if the record was pushed before
+	RemoteID string
+	// Reason for skipping the record
+	Reason string
+}
+
+// Dir returns the directory for integration files of the current workspace
+func Dir(t *core.Track) string {
+	return filepath.Join(t.WorkspaceDir(t.Workspace()), integrationsDirName)
+}
+
+// ConfigPath returns the path of the config file of an integration, in the current workspace
+func ConfigPath(t *core.Track, name string) string {
+	return filepath.Join(Dir(t), name+".yml")
+}
+
+// HasConfig reports whether an integration has a config file in the current workspace
+func HasConfig(t *core.Track, name string) bool {
+	return util.FileExists(ConfigPath(t, name))
+}
+
+// EventHandlers creates the event handlers of all integrations that are configured in the current workspace
+func EventHandlers(t *core.Track) ([]EventHandler, error) {
+	handlers := []EventHandler{}
+	if HasConfig(t, SlackName) {
+		slack, err := NewSlack(t)
+		if err != nil {
+			return handlers, err
+		}
+		handlers = append(handlers, slack)
+	}
+	if HasConfig(t, MQTTName) {
+		mqtt, err := NewMQTT(t)
+		if err != nil {
+			return handlers, err
+		}
+		handlers = append(handlers, mqtt)
+	}
+	return handlers, nil
+}
+
+// LoadConfig loads the YAML config file of an integration into conf
+func LoadConfig(t *core.Track, name string, conf interface{}) error {
+	path := ConfigPath(t, name)
+	data, err := t.FileSystem().ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no config file for %s integration; please create %s", name, path)
+		}
+		return err
+	}
+	if err := yaml.Unmarshal(data, conf); err != nil {
+		return fmt.Errorf("invalid config file %s: %s", path, err)
+	}
+	return nil
+}
+
+// LoadSyncLog loads the sync log of an integration for the current workspace.
+// Returns an empty log if there is none yet.
+func LoadSyncLog(t *core.Track, name string) (*SyncLog, error) {
+	log := SyncLog{
+		path:    filepath.Join(Dir(t), name+"-synced.json"),
+		fs:      t.FileSystem(),
+		Records: map[string]SyncedRecord{},
+	}
+	data, err := t.FileSystem().ReadFile(log.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &log, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &log.Records); err != nil {
+		return nil, fmt.Errorf("invalid sync log %s: %s", log.path, err)
+	}
+	return &log, nil
+}
+
+// Save saves the sync log
+func (l *SyncLog) Save() error {
+	if err := l.fs.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(l.Records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return l.fs.WriteFile(l.path, data, 0600)
+}
+
+// RecordChecksum calculates a checksum of a record, to detect changes since the last push
+func RecordChecksum(r *core.Record) string {
+	sum := sha256.Sum256([]byte(core.SerializeRecord(r, util.NoTime)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Push pushes all finished records matching the filters with a Pusher.
+// Records that were pushed before are only updated if they changed since.
+// In dry-run mode, nothing is pushed, and the result reports what would be pushed.
+func Push(t *core.Track, p Pusher, filters core.FilterFunctions, dryRun bool) (PushResult, error) {
+	if !dryRun {
+		if err := t.CheckWritable(); err != nil {
+			return PushResult{}, err
+		}
+	}
+	log, err := LoadSyncLog(t, p.Name())
+	if err != nil {
+		return PushResult{}, err
+	}
+
+	fn, results, stop := t.AllRecordsFiltered(filters, false)
+	go fn()
+
+	result := PushResult{}
+	var pushErr error
+	for res := range results {
+		if res.Err != nil {
+			pushErr = res.Err
+			break
+		}
+		record := res.Record
+		if !record.HasEnded() || p.Accept(&record) != "" {
+			result.Skipped++
+			continue
+		}
+		id := core.RecordID(record.Start)
+		checksum := RecordChecksum(&record)
+		synced, ok := log.Records[id]
+		if ok && synced.Checksum == checksum {
+			result.Unchanged++
+			continue
+		}
+		if !dryRun {
+			remoteID, err := p.Push(&record, synced.RemoteID)
+			if err != nil {
+				pushErr = fmt.Errorf("record %s: %s", record.Start.Format(util.DateTimeFormat), err)
+				break
+			}
+			log.Records[id] = SyncedRecord{RemoteID: remoteID, Synced: time.Now(), Checksum: checksum}
+		}
+		if ok {
+			result.Updated++
+		} else {
+			result.Created++
+		}
+	}
+	if pushErr != nil {
+		close(stop)
+	}
+
+	if !dryRun {
+		// Save the log also on errors, to keep track of already pushed records
+		if err := log.Save(); err != nil {
+			return result, err
+		}
+	}
+	return result, pushErr
+}
+
+// Status determines the sync status of all records matching the filters, without pushing anything
+func Status(t *core.Track, p Pusher, filters core.FilterFunctions) ([]RecordStatus, error) {
+	log, err := LoadSyncLog(t, p.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	fn, results, stop := t.AllRecordsFiltered(filters, false)
+	go fn()
+
+	status := []RecordStatus{}
+	for res := range results {
+		if res.Err != nil {
+			close(stop)
+			return nil, res.Err
+		}
+		record := res.Record
+		st := RecordStatus{Record: record}
+		synced, ok := log.Records[core.RecordID(record.Start)]
+		if ok {
+			st.RemoteID = synced.RemoteID
+		}
+
+		if !record.HasEnded() {
+			st.Status, st.Reason = StatusSkipped, "record is running"
+		} else if reason := p.Accept(&record); reason != "" {
+			st.Status, st.Reason = StatusSkipped, reason
+		} else if !ok {
+			st.Status = StatusPending
+		} else if synced.Checksum != RecordChecksum(&record) {
+			st.Status = StatusChanged
+		} else {
+			st.Status = StatusSynced
+		}
+		status = append(status, st)
+	}
+	return status, nil
+}
+
+// proposeRecords joins time blocks, like window events mapped to projects, into proposed records.
+//
+// Blocks are clipped to the time span from start to end.
+// Consecutive blocks with the same project and note are joined if the gap between them is at most maxGap.
+// Proposals only fill the gaps between the given existing records,
+// and proposals shorter than minDuration are dropped.
+func proposeRecords(blocks []core.Record, records []core.Record, start, end time.Time, minDuration, maxGap time.Duration) []core.Record {
+	blocks = append([]core.Record{}, blocks...)
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].Start.Before(blocks[j].Start) })
+
+	joined := []core.Record{}
+	for _, block := range blocks {
+		if block.Start.Before(start) {
+			block.Start = start
+		}
+		if block.End.After(end) {
+			block.End = end
+		}
+		if n := len(joined); n > 0 {
+			last := &joined[n-1]
+			if last.Project == block.Project && last.Note == block.Note && block.Start.Sub(last.End) <= maxGap {
+				if block.End.After(last.End) {
+					last.End = block.End
+				}
+				continue
+			}
+			// Blocks should not overlap, but be safe to not propose overlapping records
+			if block.Start.Before(last.End) {
+				block.Start = last.End
+			}
+		}
+		if block.End.After(block.Start) {
+			joined = append(joined, block)
+		}
+	}
+
+	proposed := []core.Record{}
+	for _, block := range joined {
+		for _, part := range subtractRecords(block, records) {
+			if part.End.Sub(part.Start) >= minDuration {
+				proposed = append(proposed, part)
+			}
+		}
+	}
+	return proposed
+}
+
+// subtractRecords splits a record into the parts that are not covered by any of the given records.
+// Running records are treated as ending after the record.
+func subtractRecords(rec core.Record, records []core.Record) []core.Record {
+	parts := []core.Record{rec}
+	for _, other := range records {
+		otherEnd := other.End
+		if otherEnd.IsZero() {
+			otherEnd = rec.End
+		}
+		next := []core.Record{}
+		for _, part := range parts {
+			if !other.Start.Before(part.End) || !otherEnd.After(part.Start) {
+				next = append(next, part)
+				continue
+			}
+			if other.Start.After(part.Start) {
+				before := part
+				before.End = other.Start
+				next = append(next, before)
+			}
+			if otherEnd.Before(part.End) {
+				after := part
+				after.Start = otherEnd
+				next = append(next, after)
+			}
+		}
+		parts = next
+	}
+	return parts
+}
+
+// requestJSON sends a request with a JSON body, and decodes the JSON response into result if it is not nil
+func requestJSON(client *http.Client, method, url string, header map[string]string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "track (https://github.com/mlange-42/track)")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", method, url, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,133 @@
+package integration
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPusher struct {
+	pushed map[string]int
+}
+
+func (p *testPusher) Name() string { return "test" }
+
+func (p *testPusher) Accept(r *core.Record) string {
+	if r.Project != "test" {
+		return "wrong project"
+	}
+	return ""
+}
+
+func (p *testPusher) Push(r *core.Record, remoteID string) (string, error) {
+	if remoteID == "" {
+		remoteID = fmt.Sprintf("%d", len(p.pushed)+1)
+	}
+	p.pushed[remoteID]++
+	return remoteID, nil
+}
+
+func newTestTrack(t *testing.T) (*core.Track, func()) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	track, err := core.NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	return &track, func() { os.RemoveAll(dir) }
+}
+
+func TestPush(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	records := []core.Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 4, 5, 0), End: util.DateTime(2001, 2, 3, 5, 5, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 6, 5, 0), End: util.DateTime(2001, 2, 3, 7, 5, 0)},
+		{Project: "other", Start: util.DateTime(2001, 2, 3, 8, 5, 0), End: util.DateTime(2001, 2, 3, 9, 5, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 10, 5, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false))
+	}
+
+	pusher := testPusher{pushed: map[string]int{}}
+
+	result, err := Push(track, &pusher, core.FilterFunctions{}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, PushResult{Created: 2, Skipped: 2}, result)
+	assert.Equal(t, 0, len(pusher.pushed))
+
+	result, err = Push(track, &pusher, core.FilterFunctions{}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, PushResult{Created: 2, Skipped: 2}, result)
+	assert.Equal(t, map[string]int{"1": 1, "2": 1}, pusher.pushed)
+
+	records[0].Note = "changed"
+	assert.Nil(t, track.SaveRecord(&records[0], true))
+
+	result, err = Push(track, &pusher, core.FilterFunctions{}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, PushResult{Updated: 1, Unchanged: 1, Skipped: 2}, result)
+	assert.Equal(t, map[string]int{"1": 2, "2": 1}, pusher.pushed)
+
+	log, err := LoadSyncLog(track, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", log.Records["2001/02/03/04-05"].RemoteID)
+}
+
+func TestStatus(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	records := []core.Record{
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 4, 5, 0), End: util.DateTime(2001, 2, 3, 5, 5, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 6, 5, 0), End: util.DateTime(2001, 2, 3, 7, 5, 0)},
+		{Project: "other", Start: util.DateTime(2001, 2, 3, 8, 5, 0), End: util.DateTime(2001, 2, 3, 9, 5, 0)},
+		{Project: "test", Start: util.DateTime(2001, 2, 3, 10, 5, 0)},
+	}
+	for i := range records[:2] {
+		assert.Nil(t, track.SaveRecord(&records[i], false))
+	}
+
+	pusher := testPusher{pushed: map[string]int{}}
+	_, err := Push(track, &pusher, core.FilterFunctions{}, false)
+	assert.Nil(t, err)
+
+	records[1].Note = "changed"
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], true))
+	}
+
+	status, err := Status(track, &pusher, core.FilterFunctions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(status))
+
+	assert.Equal(t, StatusSynced, status[0].Status)
+	assert.Equal(t, "1", status[0].RemoteID)
+	assert.Equal(t, StatusChanged, status[1].Status)
+	assert.Equal(t, "2", status[1].RemoteID)
+	assert.Equal(t, StatusSkipped, status[2].Status)
+	assert.Equal(t, "wrong project", status[2].Reason)
+	assert.Equal(t, StatusSkipped, status[3].Status)
+	assert.Equal(t, "record is running", status[3].Reason)
+}
+
+func TestSyncLogFileSystem(t *testing.T) {
+	track, err := core.NewMemoryTrack()
+	assert.Nil(t, err)
+
+	log, err := LoadSyncLog(&track, "test")
+	assert.Nil(t, err)
+	log.Records["2001/02/03/04-05"] = SyncedRecord{RemoteID: "1"}
+	assert.Nil(t, log.Save())
+
+	_, err = os.Stat(Dir(&track))
+	assert.True(t, os.IsNotExist(err), "Nothing should be written to the file system")
+
+	log, err = LoadSyncLog(&track, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, "1", log.Records["2001/02/03/04-05"].RemoteID)
+}
@@ -0,0 +1,310 @@
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+)
+
+const (
+	// MQTTName is the name of the MQTT integration
+	MQTTName = "mqtt"
+
+	mqttTopic    = "track"
+	mqttClientID = "track"
+	mqttTimeout  = 10 * time.Second
+
+	// Status topic name, in addition to the event types
+	mqttStatus = "status"
+
+	mqttConnect    byte = 0x10
+	mqttConnAck    byte = 0x20
+	mqttPublish    byte = 0x30
+	mqttDisconnect byte = 0xE0
+)
+
+// MQTT states of the status topic
+const (
+	MQTTStateTracking = "tracking"
+	MQTTStatePaused   = "paused"
+	MQTTStateIdle     = "idle"
+)
+
+// MQTTConfig is the config of the MQTT integration
+type MQTTConfig struct {
+	// Broker address, like tcp://localhost:1883. Use scheme tls:// for encrypted connections
+	Broker   string `yaml:"broker"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	// Client ID. Defaults to "track"
+	ClientID string `yaml:"clientId,omitempty"`
+	// Topic prefix. Defaults to "track", resulting in topics like track/start and track/status
+	Topic string `yaml:"topic,omitempty"`
+	// Topics by message type (start, stop, pause, resume, status), overriding the prefix
+	Topics map[string]string `yaml:"topics,omitempty"`
+}
+
+// MQTT publishes tracking events and the tracking status to an MQTT broker
+type MQTT struct {
+	config MQTTConfig
+	addr   string
+	tls    bool
+}
+
+// MQTTEvent is the message published for a tracking event
+type MQTTEvent struct {
+	Event   core.EventType    `json:"event"`
+	Time    time.Time         `json:"time"`
+	Project string            `json:"project"`
+	Note    string            `json:"note"`
+	Tags    map[string]string `json:"tags"`
+}
+
+// MQTTStatus is the retained message published to the status topic after each event
+type MQTTStatus struct {
+	// One of "tracking", "paused" and "idle"
+	State   string    `json:"state"`
+	Project string    `json:"project,omitempty"`
+	Since   time.Time `json:"since"`
+}
+
+// NewMQTT creates an MQTT integration from the config file of the current workspace
+func NewMQTT(t *core.Track) (*MQTT, error) {
+	conf := MQTTConfig{}
+	if err := LoadConfig(t, MQTTName, &conf); err != nil {
+		return nil, err
+	}
+	if conf.Broker == "" {
+		return nil, fmt.Errorf("missing broker in %s", ConfigPath(t, MQTTName))
+	}
+	if conf.ClientID == "" {
+		conf.ClientID = mqttClientID
+	}
+	if conf.Topic == "" {
+		conf.Topic = mqttTopic
+	}
+
+	addr, useTLS, err := parseBroker(conf.Broker)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker in %s: %s", ConfigPath(t, MQTTName), err)
+	}
+	return &MQTT{config: conf, addr: addr, tls: useTLS}, nil
+}
+
+// parseBroker parses a broker address, with optional scheme tcp://, mqtt://, tls:// or ssl://
+func parseBroker(broker string) (string, bool, error) {
+	if !strings.Contains(broker, "://") {
+		return broker, false, nil
+	}
+	u, err := url.Parse(broker)
+	if err != nil {
+		return "", false, err
+	}
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		return u.Host, false, nil
+	case "tls", "ssl", "mqtts":
+		return u.Host, true, nil
+	}
+	return "", false, fmt.Errorf("unsupported scheme '%s'", u.Scheme)
+}
+
+// Name of the integration
+func (m *MQTT) Name() string {
+	return MQTTName
+}
+
+// Topic returns the topic for a message type
+func (m *MQTT) Topic(msgType string) string {
+	if topic, ok := m.config.Topics[msgType]; ok {
+		return topic
+	}
+	return m.config.Topic + "/" + msgType
+}
+
+// HandleEvent publishes the event to its topic, and the resulting status as retained message to the status topic
+func (m *MQTT) HandleEvent(e core.Event) error {
+	event, err := json.Marshal(MQTTEvent{
+		Event:   e.Type,
+		Time:    e.Time,
+		Project: e.Record.Project,
+		Note:    e.Record.Note,
+		Tags:    e.Record.Tags,
+	})
+	if err != nil {
+		return err
+	}
+
+	status := MQTTStatus{Since: e.Time}
+	switch e.Type {
+	case core.EventStart, core.EventResume:
+		status.State, status.Project = MQTTStateTracking, e.Record.Project
+	case core.EventPause:
+		status.State, status.Project = MQTTStatePaused, e.Record.Project
+	default:
+		status.State = MQTTStateIdle
+	}
+	statusMsg, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	return m.publish([]mqttMessage{
+		{Topic: m.Topic(string(e.Type)), Payload: event},
+		{Topic: m.Topic(mqttStatus), Payload: statusMsg, Retain: true},
+	})
+}
+
+// mqttMessage is a message to publish
+type mqttMessage struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+// publish connects to the broker, publishes the messages with QoS 0, and disconnects
+func (m *MQTT) publish(messages []mqttMessage) error {
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: mqttTimeout}
+	if m.tls {
+		conn, err = tls.DialWithDialer(dialer, "tcp", m.addr, &tls.Config{MinVersion: tls.VersionTLS12})
+	} else {
+		conn, err = dialer.Dial("tcp", m.addr)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(mqttTimeout)); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write(m.connectPacket()); err != nil {
+		return err
+	}
+	if err := readConnAck(bufio.NewReader(conn)); err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		if _, err := conn.Write(publishPacket(msg)); err != nil {
+			return err
+		}
+	}
+	_, err = conn.Write([]byte{mqttDisconnect, 0})
+	return err
+}
+
+// connectPacket creates an MQTT 3.1.1 CONNECT packet with a clean session
+func (m *MQTT) connectPacket() []byte {
+	var flags byte = 0x02
+	if m.config.Username != "" {
+		flags |= 0x80
+	}
+	if m.config.Password != "" {
+		flags |= 0x40
+	}
+
+	var body bytes.Buffer
+	writeMQTTString(&body, "MQTT")
+	body.WriteByte(4) // protocol level 3.1.1
+	body.WriteByte(flags)
+	_ = binary.Write(&body, binary.BigEndian, uint16(60)) // keep alive
+	writeMQTTString(&body, m.config.ClientID)
+	if m.config.Username != "" {
+		writeMQTTString(&body, m.config.Username)
+	}
+	if m.config.Password != "" {
+		writeMQTTString(&body, m.config.Password)
+	}
+	return mqttPacket(mqttConnect, body.Bytes())
+}
+
+// publishPacket creates a PUBLISH packet with QoS 0
+func publishPacket(msg mqttMessage) []byte {
+	header := mqttPublish
+	if msg.Retain {
+		header |= 0x01
+	}
+	var body bytes.Buffer
+	writeMQTTString(&body, msg.Topic)
+	body.Write(msg.Payload)
+	return mqttPacket(header, body.Bytes())
+}
+
+// readConnAck reads the CONNACK packet, and checks the return code
+func readConnAck(r *bufio.Reader) error {
+	header, body, err := readMQTTPacket(r)
+	if err != nil {
+		return err
+	}
+	if header&0xF0 != mqttConnAck || len(body) != 2 {
+		return fmt.Errorf("unexpected response from MQTT broker")
+	}
+	if body[1] != 0 {
+		return fmt.Errorf("connection refused by MQTT broker (code %d)", body[1])
+	}
+	return nil
+}
+
+// mqttPacket creates a packet from the fixed header byte and the remaining bytes
+func mqttPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	length := len(body)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+// readMQTTPacket reads a packet, and returns the fixed header byte and the remaining bytes
+func readMQTTPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, fmt.Errorf("malformed MQTT packet length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7F) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// writeMQTTString writes a length-prefixed UTF-8 string
+func writeMQTTString(b *bytes.Buffer, s string) {
+	_ = binary.Write(b, binary.BigEndian, uint16(len(s)))
+	b.WriteString(s)
+}
@@ -0,0 +1,134 @@
+package integration
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type mqttPublished struct {
+	Topic   string
+	Payload string
+	Retain  bool
+}
+
+// runTestBroker accepts one connection, and sends the published messages to the returned channel
+func runTestBroker(t *testing.T, l net.Listener, returnCode byte) <-chan mqttPublished {
+	messages := make(chan mqttPublished, 10)
+	go func() {
+		defer close(messages)
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		header, body, err := readMQTTPacket(r)
+		assert.Nil(t, err)
+		assert.Equal(t, mqttConnect, header)
+		assert.Equal(t, "MQTT", string(body[2:6]))
+
+		_, err = conn.Write([]byte{mqttConnAck, 2, 0, returnCode})
+		assert.Nil(t, err)
+
+		for {
+			header, body, err := readMQTTPacket(r)
+			if err != nil || header == mqttDisconnect {
+				return
+			}
+			topicLen := int(binary.BigEndian.Uint16(body))
+			messages <- mqttPublished{
+				Topic:   string(body[2 : 2+topicLen]),
+				Payload: string(body[2+topicLen:]),
+				Retain:  header&0x01 != 0,
+			}
+		}
+	}()
+	return messages
+}
+
+func TestMQTT(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer l.Close()
+
+	assert.Nil(t, util.CreateDir(Dir(track)))
+	config := `broker: tcp://` + l.Addr().String() + `
+username: user
+password: pass
+topics:
+  status: home/office/track
+`
+	assert.Nil(t, os.WriteFile(ConfigPath(track, MQTTName), []byte(config), 0600))
+
+	handlers, err := EventHandlers(track)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(handlers))
+	mqtt := handlers[0]
+
+	messages := runTestBroker(t, l, 0)
+	record := core.Record{Project: "test", Note: "Note +tag", Tags: map[string]string{"tag": ""}}
+	start := util.DateTime(2001, 2, 3, 4, 5, 0)
+	assert.Nil(t, mqtt.HandleEvent(core.Event{Type: core.EventStart, Time: start, Record: record}))
+
+	published := []mqttPublished{}
+	for msg := range messages {
+		published = append(published, msg)
+	}
+	assert.Equal(t, 2, len(published))
+
+	assert.Equal(t, "track/start", published[0].Topic)
+	assert.False(t, published[0].Retain)
+	event := MQTTEvent{}
+	assert.Nil(t, json.Unmarshal([]byte(published[0].Payload), &event))
+	assert.Equal(t, core.EventStart, event.Event)
+	assert.Equal(t, "test", event.Project)
+	assert.Equal(t, map[string]string{"tag": ""}, event.Tags)
+
+	assert.Equal(t, "home/office/track", published[1].Topic)
+	assert.True(t, published[1].Retain)
+	status := MQTTStatus{}
+	assert.Nil(t, json.Unmarshal([]byte(published[1].Payload), &status))
+	assert.Equal(t, MQTTStateTracking, status.State)
+	assert.Equal(t, "test", status.Project)
+	assert.True(t, start.Equal(status.Since))
+
+	messages = runTestBroker(t, l, 5)
+	err = mqtt.HandleEvent(core.Event{Type: core.EventStop, Time: start, Record: record})
+	assert.ErrorContains(t, err, "code 5")
+	for range messages {
+		t.Fatal("no messages expected after refused connection")
+	}
+}
+
+func TestMQTTPacketLength(t *testing.T) {
+	packet := mqttPacket(mqttPublish, make([]byte, 321))
+	assert.Equal(t, []byte{mqttPublish, 0xC1, 0x02}, packet[:3])
+	assert.Equal(t, 324, len(packet))
+}
+
+func TestParseBroker(t *testing.T) {
+	addr, useTLS, err := parseBroker("localhost:1883")
+	assert.Nil(t, err)
+	assert.Equal(t, "localhost:1883", addr)
+	assert.False(t, useTLS)
+
+	addr, useTLS, err = parseBroker("tls://broker.example.com:8883")
+	assert.Nil(t, err)
+	assert.Equal(t, "broker.example.com:8883", addr)
+	assert.True(t, useTLS)
+
+	_, _, err = parseBroker("ws://localhost:1883")
+	assert.NotNil(t, err)
+}
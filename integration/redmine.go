@@ -0,0 +1,182 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+)
+
+const (
+	// RedmineName is the name of the Redmine integration
+	RedmineName = "redmine"
+	// RedmineKeyEnvVar is the environment variable for the Redmine API key
+	RedmineKeyEnvVar = "TRACK_REDMINE_KEY"
+
+	redmineMaxComment = 255
+)
+
+// RedmineConfig is the config of the Redmine integration
+type RedmineConfig struct {
+	// Base URL of the Redmine instance
+	URL string `yaml:"url"`
+	// Prefix of tags that reference issues, like +rm-1234. Defaults to "rm-"
+	IssuePrefix string `yaml:"issuePrefix,omitempty"`
+	// Tag with the activity name as value, like +activity=dev. Defaults to "activity"
+	ActivityTag string `yaml:"activityTag,omitempty"`
+	// Redmine activity IDs, by activity name
+	Activities map[string]int64 `yaml:"activities,omitempty"`
+	// Activity ID for records without activity tag. Redmine's default activity if not specified
+	DefaultActivity int64 `yaml:"defaultActivity,omitempty"`
+}
+
+// Redmine pushes records as time entries to Redmine
+type Redmine struct {
+	config RedmineConfig
+	key    string
+	client *http.Client
+}
+
+// redmineTimeEntry is a time entry in the Redmine API
+type redmineTimeEntry struct {
+	ID         int64   `json:"id,omitempty"`
+	IssueID    int64   `json:"issue_id"`
+	SpentOn    string  `json:"spent_on"`
+	Hours      float64 `json:"hours"`
+	ActivityID int64   `json:"activity_id,omitempty"`
+	Comments   string  `json:"comments"`
+}
+
+// redmineTimeEntryBody wraps a time entry for requests and responses of the Redmine API
+type redmineTimeEntryBody struct {
+	TimeEntry redmineTimeEntry `json:"time_entry"`
+}
+
+// NewRedmine creates a Redmine integration from the config file of the current workspace.
+// The API key is read from environment variable TRACK_REDMINE_KEY.
+func NewRedmine(t *core.Track) (*Redmine, error) {
+	conf := RedmineConfig{}
+	if err := LoadConfig(t, RedmineName, &conf); err != nil {
+		return nil, err
+	}
+	if conf.URL == "" {
+		return nil, fmt.Errorf("missing url in %s", ConfigPath(t, RedmineName))
+	}
+	conf.URL = strings.TrimSuffix(conf.URL, "/")
+	if conf.IssuePrefix == "" {
+		conf.IssuePrefix = "rm-"
+	}
+	if conf.ActivityTag == "" {
+		conf.ActivityTag = "activity"
+	}
+	key := os.Getenv(RedmineKeyEnvVar)
+	if key == "" {
+		return nil, fmt.Errorf("no Redmine API key; please set environment variable %s", RedmineKeyEnvVar)
+	}
+	return &Redmine{
+		config: conf,
+		key:    key,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Name of the integration
+func (rm *Redmine) Name() string {
+	return RedmineName
+}
+
+// Accept reports whether a record should be pushed.
+// Records without exactly one issue tag, or with an unknown activity, are skipped.
+func (rm *Redmine) Accept(r *core.Record) string {
+	if _, err := rm.issueID(r); err != nil {
+		return err.Error()
+	}
+	if _, err := rm.activityID(r); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// issueID determines the issue of a record from its issue tag
+func (rm *Redmine) issueID(r *core.Record) (int64, error) {
+	var issue int64
+	for tag := range r.Tags {
+		if !strings.HasPrefix(tag, rm.config.IssuePrefix) {
+			continue
+		}
+		id, err := strconv.ParseInt(strings.TrimPrefix(tag, rm.config.IssuePrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		if issue != 0 {
+			return 0, fmt.Errorf("multiple issue tags")
+		}
+		issue = id
+	}
+	if issue == 0 {
+		return 0, fmt.Errorf("no issue tag")
+	}
+	return issue, nil
+}
+
+// activityID determines the activity of a record from its activity tag, or the default activity
+func (rm *Redmine) activityID(r *core.Record) (int64, error) {
+	name, ok := r.Tags[rm.config.ActivityTag]
+	if !ok || name == "" {
+		return rm.config.DefaultActivity, nil
+	}
+	id, ok := rm.config.Activities[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown activity '%s'", name)
+	}
+	return id, nil
+}
+
+// Push creates or updates a time entry for a record
+func (rm *Redmine) Push(r *core.Record, remoteID string) (string, error) {
+	issue, err := rm.issueID(r)
+	if err != nil {
+		return "", err
+	}
+	activity, err := rm.activityID(r)
+	if err != nil {
+		return "", err
+	}
+	comments := strings.TrimSpace(r.Note)
+	if runes := []rune(comments); len(runes) > redmineMaxComment {
+		comments = string(runes[:redmineMaxComment])
+	}
+	entry := redmineTimeEntryBody{
+		TimeEntry: redmineTimeEntry{
+			IssueID:    issue,
+			SpentOn:    r.Start.Format(util.DateFormat),
+			Hours:      roundHours(r.Duration(util.NoTime, util.NoTime)),
+			ActivityID: activity,
+			Comments:   comments,
+		},
+	}
+	header := map[string]string{
+		"X-Redmine-API-Key": rm.key,
+	}
+
+	if remoteID != "" {
+		// Redmine responds to updates without content
+		err = requestJSON(rm.client, http.MethodPut, rm.config.URL+"/time_entries/"+remoteID+".json", header, &entry, nil)
+		if err != nil {
+			return "", err
+		}
+		return remoteID, nil
+	}
+
+	var result redmineTimeEntryBody
+	err = requestJSON(rm.client, http.MethodPost, rm.config.URL+"/time_entries.json", header, &entry, &result)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(result.TimeEntry.ID, 10), nil
+}
@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedmine(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	entries := map[string]redmineTimeEntry{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-Redmine-API-Key"))
+
+		var body redmineTimeEntryBody
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/time_entries.json":
+			body.TimeEntry.ID = 55
+			entries[r.Method] = body.TimeEntry
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(body)
+		case r.Method == http.MethodPut && r.URL.Path == "/time_entries/55.json":
+			entries[r.Method] = body.TimeEntry
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	assert.Nil(t, util.CreateDir(Dir(track)))
+	config := `url: ` + server.URL + `/
+activities:
+  dev: 9
+defaultActivity: 8
+`
+	assert.Nil(t, os.WriteFile(ConfigPath(track, RedmineName), []byte(config), 0600))
+
+	t.Setenv(RedmineKeyEnvVar, "secret")
+	redmine, err := NewRedmine(track)
+	assert.Nil(t, err)
+
+	record := core.Record{
+		Project: "test",
+		Note:    "Some work +rm-1234 +activity=dev",
+		Tags:    map[string]string{"rm-1234": "", "activity": "dev"},
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 35, 0),
+	}
+	assert.Equal(t, "", redmine.Accept(&record))
+	assert.Equal(t, "no issue tag", redmine.Accept(&core.Record{Tags: map[string]string{"rm-abc": ""}}))
+	assert.Equal(t, "multiple issue tags", redmine.Accept(&core.Record{Tags: map[string]string{"rm-1": "", "rm-2": ""}}))
+	assert.Equal(t, "unknown activity 'foo'", redmine.Accept(&core.Record{Tags: map[string]string{"rm-1": "", "activity": "foo"}}))
+
+	id, err := redmine.Push(&record, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "55", id)
+	assert.Equal(t, redmineTimeEntry{
+		ID:         55,
+		IssueID:    1234,
+		SpentOn:    "2001-02-03",
+		Hours:      1.5,
+		ActivityID: 9,
+		Comments:   "Some work +rm-1234 +activity=dev",
+	}, entries[http.MethodPost])
+
+	delete(record.Tags, "activity")
+	id, err = redmine.Push(&record, "55")
+	assert.Nil(t, err)
+	assert.Equal(t, "55", id)
+	assert.Equal(t, int64(8), entries[http.MethodPut].ActivityID)
+}
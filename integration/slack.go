@@ -0,0 +1,135 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+)
+
+const (
+	// SlackName is the name of the Slack integration
+	SlackName = "slack"
+
+	slackURL          = "https://slack.com/api"
+	slackEmoji        = ":hourglass_flowing_sand:"
+	slackMaxStatusLen = 100
+)
+
+// slackEmojiRegex matches emoji codes like :coffee:
+var slackEmojiRegex = regexp.MustCompile(`:[a-z0-9_+\-]+:`)
+
+// SlackConfig is the config of the Slack integration
+type SlackConfig struct {
+	// Slack user token with scope users.profile:write
+	Token string `yaml:"token"`
+	// Base URL of the API. Defaults to the Slack Web API
+	URL string `yaml:"url,omitempty"`
+	// Status emoji for projects without an emoji. Defaults to :hourglass_flowing_sand:
+	Emoji string `yaml:"emoji,omitempty"`
+	// Status emojis, by track project
+	Projects map[string]string `yaml:"projects,omitempty"`
+}
+
+// Slack sets the Slack status of the user while a record is running
+type Slack struct {
+	config SlackConfig
+	client *http.Client
+}
+
+// slackProfile is the status part of a user profile in the Slack API
+type slackProfile struct {
+	StatusText       string `json:"status_text"`
+	StatusEmoji      string `json:"status_emoji"`
+	StatusExpiration int64  `json:"status_expiration"`
+}
+
+// slackResponse is the response of a Slack API method
+type slackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// NewSlack creates a Slack integration from the config file of the current workspace
+func NewSlack(t *core.Track) (*Slack, error) {
+	conf := SlackConfig{}
+	if err := LoadConfig(t, SlackName, &conf); err != nil {
+		return nil, err
+	}
+	if conf.Token == "" {
+		return nil, fmt.Errorf("missing token in %s", ConfigPath(t, SlackName))
+	}
+	if conf.URL == "" {
+		conf.URL = slackURL
+	}
+	if conf.Emoji == "" {
+		conf.Emoji = slackEmoji
+	}
+	return &Slack{
+		config: conf,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name of the integration
+func (s *Slack) Name() string {
+	return SlackName
+}
+
+// HandleEvent sets the status on start and resume, and clears it on stop and pause
+func (s *Slack) HandleEvent(e core.Event) error {
+	switch e.Type {
+	case core.EventStart, core.EventResume:
+		return s.setStatus(s.statusText(&e.Record), s.statusEmoji(&e.Record))
+	case core.EventStop, core.EventPause:
+		return s.setStatus("", "")
+	}
+	return nil
+}
+
+// statusText derives the status text from project and note of a record
+func (s *Slack) statusText(r *core.Record) string {
+	text := r.Project
+	if note := strings.TrimSpace(r.Note); note != "" {
+		text = fmt.Sprintf("%s: %s", text, note)
+	}
+	if runes := []rune(text); len(runes) > slackMaxStatusLen {
+		text = string(runes[:slackMaxStatusLen])
+	}
+	return text
+}
+
+// statusEmoji determines the status emoji of a record.
+// An emoji in the note takes precedence over the project's emoji.
+func (s *Slack) statusEmoji(r *core.Record) string {
+	if emoji := slackEmojiRegex.FindString(r.Note); emoji != "" {
+		return emoji
+	}
+	if emoji, ok := s.config.Projects[r.Project]; ok {
+		return emoji
+	}
+	return s.config.Emoji
+}
+
+// setStatus sets the status of the user. Empty text and emoji clear the status.
+func (s *Slack) setStatus(text, emoji string) error {
+	body := struct {
+		Profile slackProfile `json:"profile"`
+	}{
+		Profile: slackProfile{StatusText: text, StatusEmoji: emoji},
+	}
+	header := map[string]string{
+		"Authorization": "Bearer " + s.config.Token,
+	}
+	var result slackResponse
+	if err := requestJSON(s.client, http.MethodPost, s.config.URL+"/users.profile.set", header, &body, &result); err != nil {
+		return err
+	}
+	if !result.OK {
+		return fmt.Errorf("failed to set Slack status: %s", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlack(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	profiles := []slackProfile{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xoxp-secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "/users.profile.set", r.URL.Path)
+
+		var body struct {
+			Profile slackProfile `json:"profile"`
+		}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		profiles = append(profiles, body.Profile)
+		_ = json.NewEncoder(w).Encode(slackResponse{OK: true})
+	}))
+	defer server.Close()
+
+	assert.Nil(t, util.CreateDir(Dir(track)))
+	config := `token: xoxp-secret
+url: ` + server.URL + `
+projects:
+  test: ":computer:"
+`
+	assert.Nil(t, os.WriteFile(ConfigPath(track, SlackName), []byte(config), 0600))
+
+	handlers, err := EventHandlers(track)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(handlers))
+	slack := handlers[0]
+	assert.Equal(t, SlackName, slack.Name())
+
+	record := core.Record{Project: "test", Note: "Some work"}
+	assert.Nil(t, slack.HandleEvent(core.Event{Type: core.EventStart, Record: record}))
+	assert.Nil(t, slack.HandleEvent(core.Event{Type: core.EventPause, Record: record}))
+	record.Note = "Coffee :coffee:"
+	assert.Nil(t, slack.HandleEvent(core.Event{Type: core.EventResume, Record: record}))
+	record.Project = "other"
+	record.Note = ""
+	assert.Nil(t, slack.HandleEvent(core.Event{Type: core.EventResume, Record: record}))
+	assert.Nil(t, slack.HandleEvent(core.Event{Type: core.EventStop, Record: record}))
+
+	assert.Equal(t, []slackProfile{
+		{StatusText: "test: Some work", StatusEmoji: ":computer:"},
+		{},
+		{StatusText: "test: Coffee :coffee:", StatusEmoji: ":coffee:"},
+		{StatusText: "other", StatusEmoji: slackEmoji},
+		{},
+	}, profiles)
+}
+
+func TestSlackError(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(slackResponse{OK: false, Error: "invalid_auth"})
+	}))
+	defer server.Close()
+
+	assert.Nil(t, util.CreateDir(Dir(track)))
+	config := "token: xoxp-secret\nurl: " + server.URL + "\n"
+	assert.Nil(t, os.WriteFile(ConfigPath(track, SlackName), []byte(config), 0600))
+
+	slack, err := NewSlack(track)
+	assert.Nil(t, err)
+	err = slack.HandleEvent(core.Event{Type: core.EventStop})
+	assert.ErrorContains(t, err, "invalid_auth")
+}
@@ -0,0 +1,161 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+)
+
+const (
+	// TempoName is the name of the Tempo integration
+	TempoName = "tempo"
+	// TempoTokenEnvVar is the environment variable for the Tempo API token
+	TempoTokenEnvVar = "TRACK_TEMPO_TOKEN"
+
+	tempoURL = "https://api.tempo.io/4"
+)
+
+// TempoConfig is the config of the Tempo integration
+type TempoConfig struct {
+	// Atlassian account ID of the worklog author
+	AuthorAccountID string `yaml:"authorAccountId"`
+	// Base URL of the API. Defaults to the Tempo API v4
+	URL string `yaml:"url,omitempty"`
+	// Tag with the Jira issue ID as value, like +issue=10001. Defaults to "issue"
+	IssueTag string `yaml:"issueTag,omitempty"`
+	// Jira issue IDs for records without issue tag, by track project
+	Projects map[string]int64 `yaml:"projects,omitempty"`
+	// Tempo work attribute keys, by tag. Tag values are used as attribute values
+	Attributes map[string]string `yaml:"attributes,omitempty"`
+}
+
+// Tempo pushes records as worklogs to Tempo
+type Tempo struct {
+	config TempoConfig
+	token  string
+	client *http.Client
+}
+
+// tempoWorklog is a worklog in the Tempo API
+type tempoWorklog struct {
+	TempoWorklogID   int64            `json:"tempoWorklogId,omitempty"`
+	IssueID          int64            `json:"issueId"`
+	AuthorAccountID  string           `json:"authorAccountId"`
+	StartDate        string           `json:"startDate"`
+	StartTime        string           `json:"startTime"`
+	TimeSpentSeconds int64            `json:"timeSpentSeconds"`
+	Description      string           `json:"description"`
+	Attributes       []tempoAttribute `json:"attributes,omitempty"`
+}
+
+// tempoAttribute is a work attribute of a worklog
+type tempoAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// NewTempo creates a Tempo integration from the config file of the current workspace.
+// The API token is read from environment variable TRACK_TEMPO_TOKEN.
+func NewTempo(t *core.Track) (*Tempo, error) {
+	conf := TempoConfig{}
+	if err := LoadConfig(t, TempoName, &conf); err != nil {
+		return nil, err
+	}
+	if conf.AuthorAccountID == "" {
+		return nil, fmt.Errorf("missing authorAccountId in %s", ConfigPath(t, TempoName))
+	}
+	if conf.URL == "" {
+		conf.URL = tempoURL
+	}
+	if conf.IssueTag == "" {
+		conf.IssueTag = "issue"
+	}
+	token := os.Getenv(TempoTokenEnvVar)
+	if token == "" {
+		return nil, fmt.Errorf("no Tempo API token; please set environment variable %s", TempoTokenEnvVar)
+	}
+	return &Tempo{
+		config: conf,
+		token:  token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Name of the integration
+func (tp *Tempo) Name() string {
+	return TempoName
+}
+
+// Accept reports whether a record should be pushed.
+// Records without an issue, either by tag or by project, are skipped.
+func (tp *Tempo) Accept(r *core.Record) string {
+	if _, err := tp.issueID(r); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// issueID determines the Jira issue ID of a record, from the issue tag or the project mapping
+func (tp *Tempo) issueID(r *core.Record) (int64, error) {
+	if value, ok := r.Tags[tp.config.IssueTag]; ok {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid issue ID '%s' in tag %s", value, tp.config.IssueTag)
+		}
+		return id, nil
+	}
+	if id, ok := tp.config.Projects[r.Project]; ok {
+		return id, nil
+	}
+	return 0, fmt.Errorf("no issue for record")
+}
+
+// Push creates or updates a worklog for a record
+func (tp *Tempo) Push(r *core.Record, remoteID string) (string, error) {
+	issue, err := tp.issueID(r)
+	if err != nil {
+		return "", err
+	}
+	worklog := tempoWorklog{
+		IssueID:          issue,
+		AuthorAccountID:  tp.config.AuthorAccountID,
+		StartDate:        r.Start.Format(util.DateFormat),
+		StartTime:        r.Start.Format("15:04:05"),
+		TimeSpentSeconds: int64(r.Duration(util.NoTime, util.NoTime).Round(time.Minute).Seconds()),
+		Description:      strings.TrimSpace(r.Note),
+		Attributes:       tp.attributes(r),
+	}
+	header := map[string]string{
+		"Authorization": "Bearer " + tp.token,
+	}
+
+	var result tempoWorklog
+	if remoteID == "" {
+		err = requestJSON(tp.client, http.MethodPost, tp.config.URL+"/worklogs", header, &worklog, &result)
+	} else {
+		err = requestJSON(tp.client, http.MethodPut, tp.config.URL+"/worklogs/"+remoteID, header, &worklog, &result)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(result.TempoWorklogID, 10), nil
+}
+
+// attributes derives the work attributes of a worklog from the tags of a record
+func (tp *Tempo) attributes(r *core.Record) []tempoAttribute {
+	attributes := []tempoAttribute{}
+	for tag, key := range tp.config.Attributes {
+		if value, ok := r.Tags[tag]; ok {
+			attributes = append(attributes, tempoAttribute{Key: key, Value: value})
+		}
+	}
+	sort.Slice(attributes, func(i, j int) bool { return attributes[i].Key < attributes[j].Key })
+	return attributes
+}
@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTempo(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	worklogs := map[string]tempoWorklog{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var worklog tempoWorklog
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&worklog))
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/worklogs":
+			worklog.TempoWorklogID = 7
+		case r.Method == http.MethodPut && r.URL.Path == "/worklogs/7":
+			worklog.TempoWorklogID = 7
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		worklogs[r.Method] = worklog
+		_ = json.NewEncoder(w).Encode(worklog)
+	}))
+	defer server.Close()
+
+	assert.Nil(t, util.CreateDir(Dir(track)))
+	config := `authorAccountId: abc
+url: ` + server.URL + `
+projects:
+  test: 10001
+attributes:
+  account: _Account_
+`
+	assert.Nil(t, os.WriteFile(ConfigPath(track, TempoName), []byte(config), 0600))
+
+	t.Setenv(TempoTokenEnvVar, "secret")
+	tempo, err := NewTempo(track)
+	assert.Nil(t, err)
+
+	record := core.Record{
+		Project: "test",
+		Note:    "Some work +account=ACME",
+		Tags:    map[string]string{"account": "ACME"},
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 35, 0),
+	}
+	assert.Equal(t, "", tempo.Accept(&record))
+	assert.NotEqual(t, "", tempo.Accept(&core.Record{Project: "other"}))
+	assert.Equal(t, "", tempo.Accept(&core.Record{Project: "other", Tags: map[string]string{"issue": "10002"}}))
+	assert.NotEqual(t, "", tempo.Accept(&core.Record{Project: "other", Tags: map[string]string{"issue": "ABC-1"}}))
+
+	id, err := tempo.Push(&record, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "7", id)
+	assert.Equal(t, tempoWorklog{
+		TempoWorklogID:   7,
+		IssueID:          10001,
+		AuthorAccountID:  "abc",
+		StartDate:        "2001-02-03",
+		StartTime:        "04:05:00",
+		TimeSpentSeconds: 5400,
+		Description:      "Some work +account=ACME",
+		Attributes:       []tempoAttribute{{Key: "_Account_", Value: "ACME"}},
+	}, worklogs[http.MethodPost])
+
+	_, err = tempo.Push(&record, "7")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5400), worklogs[http.MethodPut].TimeSpentSeconds)
+}
@@ -0,0 +1,143 @@
+package integration
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+)
+
+const (
+	// WakaTimeName is the name of the WakaTime integration
+	WakaTimeName = "wakatime"
+	// WakaTimeKeyEnvVar is the environment variable for the WakaTime API key
+	WakaTimeKeyEnvVar = "TRACK_WAKATIME_KEY"
+
+	wakaTimeDefaultURL = "https://wakatime.com/api/v1"
+)
+
+// WakaTimeConfig is the config of the WakaTime integration
+type WakaTimeConfig struct {
+	// Base URL of the WakaTime API, or a compatible API like Wakapi. Defaults to https://wakatime.com/api/v1
+	URL string `yaml:"url,omitempty"`
+	// Track projects, by WakaTime project
+	Projects map[string]string `yaml:"projects"`
+	// Project for WakaTime projects without mapping. Such projects are skipped if not specified
+	DefaultProject string `yaml:"defaultProject,omitempty"`
+	// Note for created records, like "Coding +wakatime"
+	Note string `yaml:"note,omitempty"`
+	// Minimum duration of created records. Defaults to 5 minutes
+	MinDuration time.Duration `yaml:"minDuration,omitempty"`
+	// Maximum gap between coding durations of the same project to join them. Defaults to 15 minutes
+	MaxGap time.Duration `yaml:"maxGap,omitempty"`
+}
+
+// WakaTimeDuration is a time span of coding in a project, as calculated by WakaTime from heartbeats
+type WakaTimeDuration struct {
+	// WakaTime project
+	Project string `json:"project"`
+	// Start as Unix time in seconds
+	Time float64 `json:"time"`
+	// Duration in seconds
+	Duration float64 `json:"duration"`
+}
+
+// Start returns the start time of the duration
+func (d *WakaTimeDuration) Start() time.Time {
+	return time.Unix(0, int64(d.Time*float64(time.Second))).Local()
+}
+
+// End returns the end time of the duration
+func (d *WakaTimeDuration) End() time.Time {
+	return d.Start().Add(time.Duration(d.Duration * float64(time.Second)))
+}
+
+// wakaTimeDurations is the response of the durations endpoint of the WakaTime API
+type wakaTimeDurations struct {
+	Data []WakaTimeDuration `json:"data"`
+}
+
+// WakaTime reads coding durations from WakaTime, and creates records for them
+type WakaTime struct {
+	config WakaTimeConfig
+	key    string
+	client *http.Client
+}
+
+// NewWakaTime creates a WakaTime integration from the config file of the current workspace.
+// The API key is read from environment variable TRACK_WAKATIME_KEY.
+func NewWakaTime(t *core.Track) (*WakaTime, error) {
+	conf := WakaTimeConfig{}
+	if err := LoadConfig(t, WakaTimeName, &conf); err != nil {
+		return nil, err
+	}
+	if conf.URL == "" {
+		conf.URL = wakaTimeDefaultURL
+	}
+	conf.URL = strings.TrimSuffix(conf.URL, "/")
+	if conf.MinDuration <= 0 {
+		conf.MinDuration = 5 * time.Minute
+	}
+	if conf.MaxGap <= 0 {
+		conf.MaxGap = 15 * time.Minute
+	}
+	if len(conf.Projects) == 0 && conf.DefaultProject == "" {
+		return nil, fmt.Errorf("no projects or defaultProject in %s", ConfigPath(t, WakaTimeName))
+	}
+	key := os.Getenv(WakaTimeKeyEnvVar)
+	if key == "" {
+		return nil, fmt.Errorf("no WakaTime API key; please set environment variable %s", WakaTimeKeyEnvVar)
+	}
+	return &WakaTime{
+		config: conf,
+		key:    key,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Durations fetches the coding durations of all days from start to end from the WakaTime API
+func (wt *WakaTime) Durations(start, end time.Time) ([]WakaTimeDuration, error) {
+	header := map[string]string{
+		"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(wt.key)),
+	}
+	durations := []WakaTimeDuration{}
+	for day := util.ToDate(start); day.Before(end); day = day.AddDate(0, 0, 1) {
+		query := url.Values{}
+		query.Set("date", day.Format(util.DateFormat))
+		result := wakaTimeDurations{}
+		path := fmt.Sprintf("%s/users/current/durations?%s", wt.config.URL, query.Encode())
+		if err := requestJSON(wt.client, http.MethodGet, path, header, nil, &result); err != nil {
+			return nil, err
+		}
+		durations = append(durations, result.Data...)
+	}
+	return durations, nil
+}
+
+// Suggest proposes records for coding durations, with WakaTime projects mapped to track projects.
+//
+// Consecutive durations of the same project are joined if the gap between them is at most the configured maximum gap.
+// Proposals only fill the gaps between the given existing records,
+// and proposals shorter than the configured minimum duration are dropped.
+// Durations are clipped to the time span from start to end.
+func (wt *WakaTime) Suggest(durations []WakaTimeDuration, records []core.Record, start, end time.Time) []core.Record {
+	blocks := []core.Record{}
+	for i := range durations {
+		d := &durations[i]
+		project, ok := wt.config.Projects[d.Project]
+		if !ok {
+			project = wt.config.DefaultProject
+		}
+		if project == "" {
+			continue
+		}
+		blocks = append(blocks, core.Record{Project: project, Note: wt.config.Note, Start: d.Start(), End: d.End()})
+	}
+	return proposeRecords(blocks, records, start, end, wt.config.MinDuration, wt.config.MaxGap)
+}
@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func wtDuration(project string, start time.Time, minutes float64) WakaTimeDuration {
+	return WakaTimeDuration{
+		Project:  project,
+		Time:     float64(start.Unix()),
+		Duration: minutes * 60,
+	}
+}
+
+func TestWakaTime(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	durations := map[string][]WakaTimeDuration{
+		"2001-02-03": {
+			wtDuration("track-cli", util.DateTime(2001, 2, 3, 9, 0, 0), 20),
+			wtDuration("track-cli", util.DateTime(2001, 2, 3, 9, 30, 0), 30),
+			wtDuration("unknown", util.DateTime(2001, 2, 3, 10, 0, 0), 60),
+		},
+		"2001-02-04": {
+			wtDuration("website", util.DateTime(2001, 2, 4, 14, 0, 0), 3),
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("secret")), r.Header.Get("Authorization"))
+		if r.URL.Path != "/users/current/durations" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": durations[r.URL.Query().Get("date")]})
+	}))
+	defer server.Close()
+
+	assert.Nil(t, util.CreateDir(Dir(track)))
+	config := `url: ` + server.URL + `/
+projects:
+  track-cli: track
+  website: web
+note: Coding +wakatime
+`
+	assert.Nil(t, os.WriteFile(ConfigPath(track, WakaTimeName), []byte(config), 0600))
+
+	t.Setenv(WakaTimeKeyEnvVar, "secret")
+	wt, err := NewWakaTime(track)
+	assert.Nil(t, err)
+
+	start, end := util.Date(2001, 2, 3), util.Date(2001, 2, 5)
+	fetched, err := wt.Durations(start, end)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(fetched))
+
+	suggested := wt.Suggest(fetched, []core.Record{}, start, end)
+	assert.Equal(t, 1, len(suggested))
+	assert.Equal(t, "track", suggested[0].Project)
+	assert.Equal(t, "Coding +wakatime", suggested[0].Note)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 9, 0, 0), suggested[0].Start)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 10, 0, 0), suggested[0].End)
+
+	records := []core.Record{
+		{Project: "other", Start: util.DateTime(2001, 2, 3, 9, 15, 0), End: util.DateTime(2001, 2, 3, 9, 45, 0)},
+	}
+	suggested = wt.Suggest(fetched, records, start, end)
+	assert.Equal(t, 2, len(suggested))
+	assert.Equal(t, util.DateTime(2001, 2, 3, 9, 15, 0), suggested[0].End)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 9, 45, 0), suggested[1].Start)
+
+	t.Setenv(WakaTimeKeyEnvVar, "")
+	_, err = NewWakaTime(track)
+	assert.NotNil(t, err)
+}
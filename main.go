@@ -7,25 +7,58 @@ import (
 	"github.com/mlange-42/track/cli"
 	"github.com/mlange-42/track/core"
 	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/util"
+	"golang.org/x/exp/slog"
 )
 
 const version = "0.3.7"
 
+const (
+	cpuProfileEnvVar = "TRACK_CPU_PROFILE"
+	memProfileEnvVar = "TRACK_MEM_PROFILE"
+	logLevelEnvVar   = "TRACK_LOG_LEVEL"
+)
+
 func main() {
 	if !color.Support256Color() || !isTerminal() {
 		color.Disable()
 	}
 
-	track, err := core.NewTrack(nil)
+	stopProfiling, err := util.StartProfiling(os.Getenv(cpuProfileEnvVar), os.Getenv(memProfileEnvVar))
 	if err != nil {
 		out.Err("%s\n", err.Error())
 		os.Exit(1)
 	}
 
-	if err := cli.RootCommand(&track, version).Execute(); err != nil {
+	code := run()
+
+	if err := stopProfiling(); err != nil {
 		out.Err("%s\n", err.Error())
 		os.Exit(1)
 	}
+	os.Exit(code)
+}
+
+func run() int {
+	track, err := core.NewTrack(nil)
+	if err != nil {
+		out.Err("%s\n", err.Error())
+		return 1
+	}
+	if level, ok := os.LookupEnv(logLevelEnvVar); ok {
+		lvl, err := core.ParseLogLevel(level)
+		if err != nil {
+			out.Err("%s\n", err.Error())
+			return 1
+		}
+		track.Logger = slog.New(slog.HandlerOptions{Level: lvl}.NewTextHandler(out.StdErr))
+	}
+
+	if err := cli.RootCommand(&track, version).Execute(); err != nil {
+		out.Err("%s\n", err.Error())
+		return 1
+	}
+	return 0
 }
 
 func isTerminal() bool {
@@ -1,113 +1,123 @@
 package main
 
 import (
-	"math/rand"
+	"flag"
 	"os"
-	"runtime/pprof"
-	"strings"
 	"time"
 
 	"github.com/mlange-42/track/core"
 	"github.com/mlange-42/track/out"
+	"github.com/mlange-42/track/trackgen"
 	"github.com/mlange-42/track/util"
 )
 
+// Profiling and benchmark harness for loading, filtering and reporting on a large synthetic dataset.
+//
+// Usage:
+//
+//	go run ./profile/main -years 5 -per-day 8 -scenario all -cpuprofile cpu.pprof
 func main() {
+	years := flag.Int("years", 5, "Number of years of generated records")
+	perDay := flag.Int("per-day", 8, "Number of generated records per day")
+	scenario := flag.String("scenario", "all", "Scenario to run: load, filter, report, streaming, cached or all")
+	repeat := flag.Int("repeat", 3, "Number of repetitions of each scenario")
+	seed := flag.Int64("seed", 0, "Seed for the random number generator")
+	cpuProfile := flag.String("cpuprofile", "", "Write a CPU profile to this file")
+	memProfile := flag.String("memprofile", "", "Write a heap profile to this file")
+	flag.Parse()
+
+	scenarios := map[string]func(t *core.Track) error{
+		"load":      loadAll,
+		"filter":    filterTags,
+		"report":    report,
+		"streaming": reportStreaming,
+		"cached":    reportCached,
+	}
+	names := []string{"load", "filter", "report", "streaming", "cached"}
+	if *scenario != "all" {
+		if _, ok := scenarios[*scenario]; !ok {
+			out.Err("unknown scenario '%s'\n", *scenario)
+			os.Exit(1)
+		}
+		names = []string{*scenario}
+	}
+
 	dir, err := os.MkdirTemp("", "track-test")
 	if err != nil {
 		panic(err.Error())
 	}
-	defer os.Remove(dir)
+	defer os.RemoveAll(dir)
 
 	track, err := core.NewTrack(&dir)
 	if err != nil {
 		panic(err.Error())
 	}
+	track.Config.ReportCache = true
 
-	out.Print("Generating dataset\n")
-	generateDataset(
-		&track,
-		util.Date(1990, 1, 1),
-		2*time.Hour,
-		10000,
-	)
+	out.Print("Generating dataset: %d years x %d records/day\n", *years, *perDay)
+	conf := trackgen.DefaultConfig()
+	conf.Seed = *seed
+	conf.Days = *years * 365
+	conf.PerDay = *perDay
+	count, err := trackgen.Generate(&track, &conf)
+	if err != nil {
+		panic(err.Error())
+	}
+	out.Print("Generated %d records\n", count)
 
-	f, err := os.Create("large_dataset.pprof")
+	stop, err := util.StartProfiling(*cpuProfile, *memProfile)
 	if err != nil {
 		panic(err.Error())
 	}
 
-	out.Print("Profiling\n")
-	pprof.StartCPUProfile(f)
-	defer pprof.StopCPUProfile()
+	for _, name := range names {
+		fn := scenarios[name]
+		for i := 0; i < *repeat; i++ {
+			start := time.Now()
+			if err := fn(&track); err != nil {
+				panic(err.Error())
+			}
+			out.Print("%-10s %d: %s\n", name, i+1, time.Since(start).Round(time.Microsecond))
+		}
+	}
 
-	fn, results, _ := track.AllRecords()
-	go fn()
-	for res := range results {
-		_ = res.Record
+	if err := stop(); err != nil {
+		panic(err.Error())
 	}
 }
 
-func generateDataset(t *core.Track, start time.Time, step time.Duration, records int) error {
-	currTime := start
-	for i := 0; i < records; i++ {
-		rec := timedRecord(currTime, step/2, rand.Intn(3), rand.Intn(3))
-		if err := t.SaveRecord(&rec, false); err != nil {
-			return err
+func loadAll(t *core.Track) error {
+	fn, results, _ := t.AllRecords()
+	go fn()
+	for res := range results {
+		if res.Err != nil {
+			return res.Err
 		}
-		currTime = currTime.Add(step)
 	}
 	return nil
 }
 
-var noteLines = strings.Split(
-	`Lorem ipsum dolor sit +amet, consectetur adipiscing elit,
-sed do eiusmod tempor incididunt ut +labore et dolore magna aliqua.
-A diam sollicitudin +tempor id eu nisl nunc mi ipsum.
-Ullamcorper sit amet +risus +nullam eget felis eget.
-Cursus euismod quis viverra nibh. Nunc sed blandit libero volutpat sed.
-At augue eget arcu dictum varius +duis at consectetur lorem.
-Velit euismod in pellentesque +massa placerat duis ultricies.
-Risus nec +feugiat in fermentum posuere urna.
-Id diam vel quam +elementum +pulvinar +etiam non quam.
-Adipiscing bibendum est ultricies +integer quis auctor elit sed.
-Sagittis orci a scelerisque purus +semper eget duis.
-Elementum tempus egestas sed sed risus pretium.
-Sit amet +mattis vulputate enim nulla aliquet porttitor lacus luctus.
-Dignissim +sodales ut eu sem integer vitae justo eget.
-Posuere urna nec tincidunt +praesent semper feugiat nibh sed pulvinar.
-Dignissim cras tincidunt lobortis +feugiat vivamus at augue eget.
-Viverra nam libero justo laoreet sit +amet cursus.
-Venenatis +lectus +magna +fringilla +urna porttitor rhoncus dolor.
-Aliquam id +diam maecenas ultricies.`, "\n")
-
-func timedRecord(start time.Time, duration time.Duration, pauses int, lines int) core.Record {
-	pause := make([]core.Pause, pauses)
-	note := make([]string, lines)
+func filterTags(t *core.Track) error {
+	filters := core.NewFilter(
+		[]core.FilterFunction{
+			core.FilterByTagsAny([]util.Pair[string, string]{util.NewPair("bugfix", "")}),
+		}, util.NoTime, util.NoTime,
+	)
+	_, err := t.LoadAllRecordsFiltered(filters)
+	return err
+}
 
-	if pauses > 0 {
-		step := time.Duration(int(duration) / (pauses + 2))
-		pauseStart := start.Add(step / 2)
-		for i := 0; i < pauses; i++ {
-			pause[i] = core.Pause{
-				Start: pauseStart,
-				End:   pauseStart.Add(step / 2),
-				Note:  "Pause comment",
-			}
-			pauseStart = pauseStart.Add(step)
-		}
-	}
+func report(t *core.Track) error {
+	_, err := core.NewReporter(t, []string{}, core.FilterFunctions{}, false, util.NoTime, util.NoTime)
+	return err
+}
 
-	for i := 0; i < lines; i++ {
-		note[i] = noteLines[rand.Intn(len(noteLines))]
-	}
+func reportStreaming(t *core.Track) error {
+	_, err := core.NewStreamingReporter(t, []string{}, core.FilterFunctions{}, false, util.NoTime, util.NoTime)
+	return err
+}
 
-	return core.Record{
-		Project: "test",
-		Start:   start,
-		End:     start.Add(duration),
-		Pause:   pause,
-		Note:    strings.Join(note, "\n"),
-		Tags:    map[string]string{},
-	}
+func reportCached(t *core.Track) error {
+	_, err := core.NewCachedReporter(t, []string{}, core.FilterFunctions{}, false, util.NoTime, util.NoTime)
+	return err
 }
@@ -0,0 +1,186 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+)
+
+const syncStateFile = "sync-state.json"
+
+// Client exchanges record changes with a Server
+type Client struct {
+	URL   string
+	Token string
+	HTTP  *http.Client
+}
+
+// NewClient creates a new Client for the server at the given base URL
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		URL:   strings.TrimSuffix(baseURL, "/"),
+		Token: token,
+		HTTP:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Pull requests all changes since the given time, in the server's clock
+func (c *Client) Pull(since time.Time) (ChangesResponse, error) {
+	u := c.URL + ChangesPath
+	if !since.IsZero() {
+		u += "?since=" + url.QueryEscape(since.Format(time.RFC3339Nano))
+	}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return ChangesResponse{}, err
+	}
+	var resp ChangesResponse
+	err = c.do(req, &resp)
+	return resp, err
+}
+
+// Push sends changes to the server.
+// Returns the number of changes applied by the server, and the changes rejected by it.
+func (c *Client) Push(changes []core.RecordChange) (PushResponse, error) {
+	body, err := json.Marshal(PushRequest{Changes: changes})
+	if err != nil {
+		return PushResponse{}, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.URL+ChangesPath, bytes.NewReader(body))
+	if err != nil {
+		return PushResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp PushResponse
+	err = c.do(req, &resp)
+	return resp, err
+}
+
+func (c *Client) do(req *http.Request, result interface{}) error {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("server responded with %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// SyncState is the state of synchronization with a server
+type SyncState struct {
+	// Time of the last pull, in the server's clock
+	Pulled time.Time `json:"pulled"`
+	// Time of the last push, in the local clock
+	Pushed time.Time `json:"pushed"`
+}
+
+// SyncResult reports the outcome of a synchronization
+type SyncResult struct {
+	// Number of changes applied locally
+	Pulled int
+	// Number of changes applied by the server
+	Pushed int
+	// Pulled changes that were skipped, as the record's project does not exist locally
+	RejectedLocally []core.RejectedChange
+	// Pushed changes that were skipped, as the record's project does not exist on the server
+	RejectedByServer []core.RejectedChange
+}
+
+// Sync synchronizes the records of the current workspace with the server, in both directions.
+// Only changes since the last synchronization with the server are exchanged.
+// For records changed on both sides, the later change wins.
+//
+// Changes of records in projects that don't exist on the receiving side are skipped, and reported in the result.
+// They are exchanged again by the next synchronization, e.g. after creating the project.
+func Sync(t *core.Track, c *Client) (SyncResult, error) {
+	if err := t.CheckWritable(); err != nil {
+		return SyncResult{}, err
+	}
+	states, err := loadSyncStates(t)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	state := states[c.URL]
+
+	pushTime := time.Now()
+	local, err := t.RecordChanges(state.Pushed)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	remote, err := c.Pull(state.Pulled)
+	if err != nil {
+		return SyncResult{}, fmt.Errorf("pull: %s", err)
+	}
+	pulled, rejectedLocally, err := t.ApplyChanges(remote.Changes)
+	result := SyncResult{Pulled: pulled, RejectedLocally: rejectedLocally}
+	if err != nil {
+		return result, err
+	}
+
+	pushed, err := c.Push(local)
+	if err != nil {
+		return result, fmt.Errorf("push: %s", err)
+	}
+	result.Pushed, result.RejectedByServer = pushed.Applied, pushed.Rejected
+
+	// Keep the previous times if changes were rejected, so that they are exchanged again
+	if len(rejectedLocally) == 0 {
+		state.Pulled = remote.Now
+	}
+	if len(pushed.Rejected) == 0 {
+		state.Pushed = pushTime
+	}
+	states[c.URL] = state
+	if err := saveSyncStates(t, states); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// syncStatePath returns the path of the file with the sync states of the current workspace
+func syncStatePath(t *core.Track) string {
+	return filepath.Join(t.WorkspaceDir(t.Workspace()), syncStateFile)
+}
+
+// loadSyncStates loads the sync states of the current workspace, by server URL
+func loadSyncStates(t *core.Track) (map[string]SyncState, error) {
+	states := map[string]SyncState{}
+	data, err := t.FileSystem().ReadFile(syncStatePath(t))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return states, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("invalid sync state file: %s", err)
+	}
+	return states, nil
+}
+
+// saveSyncStates saves the sync states of the current workspace
+func saveSyncStates(t *core.Track, states map[string]SyncState) error {
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	return t.FileSystem().WriteFile(syncStatePath(t), data, 0600)
+}
@@ -0,0 +1,148 @@
+package remote
+
+import (
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTrack(t *testing.T) (*core.Track, func()) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	track, err := core.NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	return &track, func() { os.RemoveAll(dir) }
+}
+
+func TestSync(t *testing.T) {
+	serverTrack, cleanServer := newTestTrack(t)
+	defer cleanServer()
+	clientTrack, cleanClient := newTestTrack(t)
+	defer cleanClient()
+
+	server := httptest.NewServer(NewServer(serverTrack, "secret"))
+	defer server.Close()
+
+	for _, track := range []*core.Track{serverTrack, clientTrack} {
+		assert.Nil(t, track.SaveProject(core.NewProject("test", "", "t", []string{}, 15, 0), false))
+	}
+
+	serverRecord := core.Record{
+		Project: "test",
+		Note:    "from server",
+		Start:   util.DateTime(2001, 2, 3, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 3, 5, 5, 0),
+	}
+	clientRecord := core.Record{
+		Project: "test",
+		Note:    "from client",
+		Start:   util.DateTime(2001, 2, 4, 4, 5, 0),
+		End:     util.DateTime(2001, 2, 4, 5, 5, 0),
+	}
+	assert.Nil(t, serverTrack.SaveRecord(&serverRecord, false))
+	assert.Nil(t, clientTrack.SaveRecord(&clientRecord, false))
+
+	_, err := Sync(clientTrack, NewClient(server.URL, "wrong"))
+	assert.NotNil(t, err, "Expected error for wrong token")
+
+	client := NewClient(server.URL, "secret")
+	result, err := Sync(clientTrack, client)
+	assert.Nil(t, err)
+	assert.Equal(t, SyncResult{Pulled: 1, Pushed: 1}, result)
+
+	loaded, err := clientTrack.LoadRecord(serverRecord.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "from server", loaded.Note)
+	loaded, err = serverTrack.LoadRecord(clientRecord.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "from client", loaded.Note)
+
+	result, err = Sync(clientTrack, client)
+	assert.Nil(t, err)
+	assert.Equal(t, SyncResult{Pulled: 0, Pushed: 0}, result)
+
+	assert.Nil(t, serverTrack.DeleteRecord(&serverRecord))
+	result, err = Sync(clientTrack, client)
+	assert.Nil(t, err)
+	assert.Equal(t, SyncResult{Pulled: 1, Pushed: 0}, result)
+
+	_, err = clientTrack.LoadRecord(serverRecord.Start)
+	assert.NotNil(t, err, "Record should be deleted on the client")
+
+	// Moving the start of a record changes its ID, and deletes the old one
+	moved := clientRecord
+	moved.Start, moved.End = clientRecord.Start.Add(30*time.Minute), clientRecord.End.Add(30*time.Minute)
+	tx := clientTrack.Begin()
+	tx.Delete(&clientRecord)
+	tx.Save(&moved, false)
+	assert.Nil(t, tx.Commit())
+
+	result, err = Sync(clientTrack, client)
+	assert.Nil(t, err)
+	assert.Equal(t, SyncResult{Pulled: 0, Pushed: 2}, result)
+
+	_, err = serverTrack.LoadRecord(clientRecord.Start)
+	assert.NotNil(t, err, "Old record should be deleted on the server")
+	loaded, err = serverTrack.LoadRecord(moved.Start)
+	assert.Nil(t, err)
+	assert.Equal(t, "from client", loaded.Note)
+
+	invalid := core.Record{Project: "test", Start: util.DateTime(2001, 2, 5, 4, 5, 0), End: util.DateTime(2001, 2, 5, 3, 5, 0)}
+	_, err = client.Push([]core.RecordChange{{ID: core.RecordID(invalid.Start), Modified: time.Now(), Record: &invalid}})
+	assert.NotNil(t, err, "Expected error for invalid change")
+	assert.Contains(t, err.Error(), "400")
+}
+
+func TestSyncUnknownProject(t *testing.T) {
+	serverTrack, cleanServer := newTestTrack(t)
+	defer cleanServer()
+	clientTrack, cleanClient := newTestTrack(t)
+	defer cleanClient()
+
+	server := httptest.NewServer(NewServer(serverTrack, "secret"))
+	defer server.Close()
+
+	for _, track := range []*core.Track{serverTrack, clientTrack} {
+		assert.Nil(t, track.SaveProject(core.NewProject("test", "", "t", []string{}, 15, 0), false))
+	}
+	assert.Nil(t, clientTrack.SaveProject(core.NewProject("client", "", "c", []string{}, 15, 0), false))
+
+	known := core.Record{Project: "test", Start: util.DateTime(2001, 2, 3, 4, 5, 0), End: util.DateTime(2001, 2, 3, 5, 5, 0)}
+	unknown := core.Record{Project: "client", Start: util.DateTime(2001, 2, 4, 4, 5, 0), End: util.DateTime(2001, 2, 4, 5, 5, 0)}
+	assert.Nil(t, clientTrack.SaveRecord(&known, false))
+	assert.Nil(t, clientTrack.SaveRecord(&unknown, false))
+
+	client := NewClient(server.URL, "secret")
+	result, err := Sync(clientTrack, client)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result.Pushed)
+	assert.Equal(t, []core.RejectedChange{{ID: core.RecordID(unknown.Start), Reason: "project 'client' does not exist"}}, result.RejectedByServer)
+
+	_, err = serverTrack.LoadRecord(known.Start)
+	assert.Nil(t, err, "Record of a known project should be applied")
+
+	// Rejected records are pushed again after creating the project
+	assert.Nil(t, serverTrack.SaveProject(core.NewProject("client", "", "c", []string{}, 15, 0), false))
+	result, err = Sync(clientTrack, client)
+	assert.Nil(t, err)
+	assert.Equal(t, SyncResult{Pulled: 0, Pushed: 1}, result)
+
+	_, err = serverTrack.LoadRecord(unknown.Start)
+	assert.Nil(t, err, "Record should be applied after creating the project")
+}
+
+func TestServerWithoutToken(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	server := httptest.NewServer(NewServer(track, ""))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, "").Pull(time.Time{})
+	assert.NotNil(t, err, "Expected requests to be rejected without a server token")
+}
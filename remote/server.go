@@ -0,0 +1,138 @@
+// Package remote provides a server and a client for synchronizing records between devices.
+package remote
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mlange-42/track/core"
+)
+
+// ChangesPath is the path of the endpoint for exchanging record changes
+const ChangesPath = "/v1/changes"
+
+// MaxBodySize is the maximum size of push request bodies, in bytes
+const MaxBodySize = 32 << 20
+
+// ChangesResponse is the response to a request for changes
+type ChangesResponse struct {
+	// Time of the server when the changes were collected
+	Now time.Time `json:"now"`
+	// Changed and deleted records
+	Changes []core.RecordChange `json:"changes"`
+}
+
+// PushRequest is a request to apply changes on the server
+type PushRequest struct {
+	Changes []core.RecordChange `json:"changes"`
+}
+
+// PushResponse is the response to a PushRequest
+type PushResponse struct {
+	// Number of applied changes
+	Applied int `json:"applied"`
+	// Changes that were skipped, as the record's project does not exist on the server
+	Rejected []core.RejectedChange `json:"rejected,omitempty"`
+}
+
+// Server serves the records of the current workspace for synchronization.
+//
+// GET requests to ChangesPath return changes since the time given by query parameter since (RFC 3339).
+// POST requests to ChangesPath apply the changes in the request body (PushRequest).
+// Pushed changes are validated by core.Track.ApplyChanges, and rejected with status 400 if they are invalid.
+// Changes of records in projects that don't exist on the server are skipped, and reported in the PushResponse.
+type Server struct {
+	track *core.Track
+	token string
+	mutex sync.Mutex
+}
+
+// NewServer creates a new Server. Requests must provide the token as bearer token.
+// With an empty token, all requests are rejected.
+func NewServer(t *core.Track, token string) *Server {
+	return &Server{
+		track: t,
+		token: token,
+	}
+}
+
+// ServeHTTP handles a request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != ChangesPath {
+		http.NotFound(w, r)
+		return
+	}
+	auth := r.Header.Get("Authorization")
+	if s.token == "" || subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+s.token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getChanges(w, r)
+	case http.MethodPost:
+		s.pushChanges(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) getChanges(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if param := r.URL.Query().Get("since"); param != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339Nano, param)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid parameter since: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+	now := time.Now()
+	changes, err := s.track.RecordChanges(since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, ChangesResponse{Now: now, Changes: changes})
+}
+
+func (s *Server) pushChanges(w http.ResponseWriter, r *http.Request) {
+	var req PushRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("request body exceeds %d bytes", MaxBodySize), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+		return
+	}
+	applied, rejected, err := s.track.ApplyChanges(req.Changes)
+	if err != nil {
+		if errors.Is(err, core.ErrInvalidChange) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, PushResponse{Applied: applied, Rejected: rejected})
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
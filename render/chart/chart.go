@@ -0,0 +1,326 @@
+package chart
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/mlange-42/track/util"
+)
+
+// Kind is a kind of chart
+type Kind string
+
+// Chart kinds
+const (
+	// Bars is a stacked bar chart of the time per day and project
+	Bars Kind = "bars"
+	// Pie is a pie chart of the total time per project
+	Pie Kind = "pie"
+	// Cumulative is a line chart of the cumulative time per project, and in total
+	Cumulative Kind = "cumulative"
+)
+
+// Kinds are all chart kinds
+var Kinds = []Kind{Bars, Pie, Cumulative}
+
+// Data is the data of a chart
+type Data struct {
+	// Start of each day
+	Dates []time.Time
+	// Projects in drawing order
+	Projects []string
+	// Time per project and day
+	Values map[string][]time.Duration
+	// Colors of projects
+	Colors map[string]color.RGBA
+	// Formatter for dates and durations
+	Format util.Formatter
+}
+
+// Total returns the total time of a project over all days
+func (d *Data) Total(project string) time.Duration {
+	total := time.Duration(0)
+	for _, v := range d.Values[project] {
+		total += v
+	}
+	return total
+}
+
+// Options are options for chart rendering
+type Options struct {
+	Kind   Kind
+	Width  int
+	Height int
+	Title  string
+}
+
+const (
+	fontHeight  = 13.0
+	charWidth   = 7.0
+	margin      = 16.0
+	axisWidth   = 48.0
+	axisHeight  = 24.0
+	legendWidth = 180.0
+	legendRow   = 18.0
+	swatchSize  = 12.0
+)
+
+// Palette are colors for projects without a color of their own
+var Palette = []color.RGBA{
+	{31, 119, 180, 255},
+	{255, 127, 14, 255},
+	{44, 160, 44, 255},
+	{214, 39, 40, 255},
+	{148, 103, 189, 255},
+	{140, 86, 75, 255},
+	{227, 119, 194, 255},
+	{127, 127, 127, 255},
+	{188, 189, 34, 255},
+	{23, 190, 207, 255},
+}
+
+var (
+	black     = color.RGBA{0, 0, 0, 255}
+	grey      = color.RGBA{128, 128, 128, 255}
+	lightGrey = color.RGBA{220, 220, 220, 255}
+	white     = color.RGBA{255, 255, 255, 255}
+)
+
+type point struct {
+	X, Y float64
+}
+
+type textAnchor uint8
+
+const (
+	anchorStart textAnchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// canvas is a drawing surface, implemented for SVG and PNG output
+type canvas interface {
+	rect(x, y, w, h float64, c color.RGBA)
+	polygon(points []point, c color.RGBA)
+	polyline(points []point, width float64, c color.RGBA)
+	// text draws text with its baseline at y
+	text(x, y float64, s string, anchor textAnchor, c color.RGBA)
+}
+
+// draw draws a chart on the canvas
+func draw(cv canvas, data *Data, opt *Options) error {
+	w, h := float64(opt.Width), float64(opt.Height)
+	cv.rect(0, 0, w, h, white)
+
+	top := margin
+	if opt.Title != "" {
+		cv.text(w/2, margin+fontHeight, opt.Title, anchorMiddle, black)
+		top += fontHeight + margin
+	}
+	plot := box{X: margin, Y: top, W: w - 2*margin - legendWidth, H: h - top - margin}
+	if plot.W < 50 || plot.H < 50 {
+		return fmt.Errorf("chart size %dx%d is too small", opt.Width, opt.Height)
+	}
+	legend := box{X: plot.X + plot.W + margin, Y: plot.Y, W: legendWidth - margin, H: plot.H}
+
+	switch opt.Kind {
+	case Bars:
+		drawBars(cv, data, plot)
+	case Pie:
+		drawPie(cv, data, plot)
+	case Cumulative:
+		drawCumulative(cv, data, plot)
+	default:
+		return fmt.Errorf("unknown chart kind '%s'", opt.Kind)
+	}
+	drawLegend(cv, data, legend, opt.Kind == Cumulative)
+	return nil
+}
+
+type box struct {
+	X, Y, W, H float64
+}
+
+// drawBars draws stacked bars of the time per day and project
+func drawBars(cv canvas, data *Data, b box) {
+	totals := make([]time.Duration, len(data.Dates))
+	for _, p := range data.Projects {
+		for i, v := range data.Values[p] {
+			totals[i] += v
+		}
+	}
+	maxHours := 0.0
+	for _, v := range totals {
+		maxHours = math.Max(maxHours, v.Hours())
+	}
+
+	plot := drawAxes(cv, data, b, maxHours)
+	if len(data.Dates) == 0 {
+		return
+	}
+	slot := plot.W / float64(len(data.Dates))
+	barWidth := math.Max(slot*0.8, 1)
+	for i := range data.Dates {
+		x := plot.X + float64(i)*slot + (slot-barWidth)/2
+		y := plot.Y + plot.H
+		for _, p := range data.Projects {
+			height := data.Values[p][i].Hours() / plot.yMax * plot.H
+			if height <= 0 {
+				continue
+			}
+			y -= height
+			cv.rect(x, y, barWidth, height, data.Colors[p])
+		}
+	}
+}
+
+// drawCumulative draws lines of the cumulative time per project, and in total
+func drawCumulative(cv canvas, data *Data, b box) {
+	total := make([]float64, len(data.Dates)+1)
+	lines := make(map[string][]float64, len(data.Projects))
+	for _, p := range data.Projects {
+		line := make([]float64, len(data.Dates)+1)
+		for i, v := range data.Values[p] {
+			line[i+1] = line[i] + v.Hours()
+			total[i+1] += line[i+1] - line[i]
+		}
+		lines[p] = line
+	}
+	for i := 1; i < len(total); i++ {
+		total[i] += total[i-1]
+	}
+
+	plot := drawAxes(cv, data, b, total[len(total)-1])
+	if len(data.Dates) == 0 {
+		return
+	}
+	toPoints := func(values []float64) []point {
+		points := make([]point, len(values))
+		for i, v := range values {
+			points[i] = point{
+				X: plot.X + float64(i)/float64(len(data.Dates))*plot.W,
+				Y: plot.Y + plot.H - v/plot.yMax*plot.H,
+			}
+		}
+		return points
+	}
+	for _, p := range data.Projects {
+		cv.polyline(toPoints(lines[p]), 2, data.Colors[p])
+	}
+	cv.polyline(toPoints(total), 3, black)
+}
+
+// drawPie draws a pie chart of the total time per project
+func drawPie(cv canvas, data *Data, b box) {
+	totals := make([]float64, len(data.Projects))
+	sum := 0.0
+	for i, p := range data.Projects {
+		totals[i] = data.Total(p).Hours()
+		sum += totals[i]
+	}
+	center := point{X: b.X + b.W/2, Y: b.Y + b.H/2}
+	radius := math.Min(b.W, b.H) / 2
+	if sum <= 0 {
+		cv.text(center.X, center.Y, "no data", anchorMiddle, grey)
+		return
+	}
+
+	// Start at 12 o'clock, clockwise
+	angle := -math.Pi / 2
+	for i, p := range data.Projects {
+		if totals[i] <= 0 {
+			continue
+		}
+		sweep := totals[i] / sum * 2 * math.Pi
+		steps := int(math.Ceil(sweep/(math.Pi/90))) + 1
+		points := []point{center}
+		for s := 0; s <= steps; s++ {
+			a := angle + sweep*float64(s)/float64(steps)
+			points = append(points, point{X: center.X + radius*math.Cos(a), Y: center.Y + radius*math.Sin(a)})
+		}
+		cv.polygon(points, data.Colors[p])
+		angle += sweep
+	}
+}
+
+// plotArea is the area inside the axes, with the maximum of the y axis in hours
+type plotArea struct {
+	box
+	yMax float64
+}
+
+// drawAxes draws axes with hour ticks and date labels, and returns the remaining plot area
+func drawAxes(cv canvas, data *Data, b box, maxHours float64) plotArea {
+	step := hourStep(maxHours)
+	yMax := math.Max(math.Ceil(maxHours/step)*step, step)
+	plot := plotArea{
+		box:  box{X: b.X + axisWidth, Y: b.Y + fontHeight/2, W: b.W - axisWidth, H: b.H - axisHeight - fontHeight/2},
+		yMax: yMax,
+	}
+
+	for v := 0.0; v <= yMax+step/2; v += step {
+		y := plot.Y + plot.H - v/yMax*plot.H
+		cv.rect(plot.X, y, plot.W, 1, lightGrey)
+		label := data.Format.Duration(time.Duration(v * float64(time.Hour)))
+		cv.text(plot.X-6, y+fontHeight/3, label, anchorEnd, black)
+	}
+	cv.rect(plot.X, plot.Y, 1, plot.H, grey)
+	cv.rect(plot.X, plot.Y+plot.H, plot.W, 1, grey)
+
+	if len(data.Dates) == 0 {
+		return plot
+	}
+	slot := plot.W / float64(len(data.Dates))
+	labelWidth := float64(len(data.Format.Date(data.Dates[0]))+2) * charWidth
+	every := int(math.Ceil(labelWidth / slot))
+	for i := 0; i < len(data.Dates); i += every {
+		x := plot.X + (float64(i)+0.5)*slot
+		cv.text(x, plot.Y+plot.H+fontHeight+4, data.Format.Date(data.Dates[i]), anchorMiddle, black)
+	}
+	return plot
+}
+
+// hourStep determines the distance between ticks of the hours axis, for at most 8 ticks
+func hourStep(maxHours float64) float64 {
+	step := 1.0
+	for maxHours/step > 8 {
+		step *= 2
+	}
+	return step
+}
+
+// drawLegend draws a legend with the projects and their total time
+func drawLegend(cv canvas, data *Data, b box, withTotal bool) {
+	y := b.Y
+	total := time.Duration(0)
+	for _, p := range data.Projects {
+		if y+legendRow > b.Y+b.H {
+			cv.text(b.X, y+fontHeight, "...", anchorStart, grey)
+			return
+		}
+		dur := data.Total(p)
+		total += dur
+		cv.rect(b.X, y+1, swatchSize, swatchSize, data.Colors[p])
+		cv.text(b.X+swatchSize+6, y+swatchSize, legendLabel(p, data.Format.Duration(dur), b.W-swatchSize-6), anchorStart, black)
+		y += legendRow
+	}
+	if withTotal && y+legendRow <= b.Y+b.H {
+		cv.rect(b.X, y+swatchSize/2, swatchSize, 3, black)
+		cv.text(b.X+swatchSize+6, y+swatchSize, legendLabel("total", data.Format.Duration(total), b.W-swatchSize-6), anchorStart, black)
+	}
+}
+
+// legendLabel formats a project name and a duration, shortening the name to fit the width
+func legendLabel(name, duration string, width float64) string {
+	maxChars := int(width/charWidth) - len(duration) - 1
+	runes := []rune(name)
+	if maxChars < 1 {
+		maxChars = 1
+	}
+	if len(runes) > maxChars {
+		name = string(runes[:maxChars-1]) + "."
+	}
+	return name + " " + duration
+}
@@ -0,0 +1,114 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// PngRenderer renders a chart in PNG format
+type PngRenderer struct {
+	Data    *Data
+	Options Options
+}
+
+// Render renders the chart
+func (r PngRenderer) Render(w io.Writer) error {
+	cv := pngCanvas{img: image.NewRGBA(image.Rect(0, 0, r.Options.Width, r.Options.Height))}
+	if err := draw(&cv, r.Data, &r.Options); err != nil {
+		return err
+	}
+	return png.Encode(w, cv.img)
+}
+
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+func (c *pngCanvas) rect(x, y, w, h float64, col color.RGBA) {
+	r := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+	if r.Dx() == 0 && w > 0 {
+		r.Max.X++
+	}
+	if r.Dy() == 0 && h > 0 {
+		r.Max.Y++
+	}
+	r = r.Intersect(c.img.Bounds())
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			c.img.SetRGBA(px, py, col)
+		}
+	}
+}
+
+// polygon fills a polygon with a scanline algorithm, sampling at pixel centers
+func (c *pngCanvas) polygon(points []point, col color.RGBA) {
+	if len(points) < 3 {
+		return
+	}
+	minY, maxY := points[0].Y, points[0].Y
+	for _, p := range points {
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+	bounds := c.img.Bounds()
+	crossings := []float64{}
+	for py := int(math.Floor(minY)); py <= int(math.Ceil(maxY)); py++ {
+		if py < bounds.Min.Y || py >= bounds.Max.Y {
+			continue
+		}
+		y := float64(py) + 0.5
+		crossings = crossings[:0]
+		for i := range points {
+			a, b := points[i], points[(i+1)%len(points)]
+			if (a.Y <= y) != (b.Y <= y) {
+				crossings = append(crossings, a.X+(y-a.Y)/(b.Y-a.Y)*(b.X-a.X))
+			}
+		}
+		sort.Float64s(crossings)
+		for i := 0; i+1 < len(crossings); i += 2 {
+			from := int(math.Max(math.Round(crossings[i]), float64(bounds.Min.X)))
+			to := int(math.Min(math.Round(crossings[i+1]), float64(bounds.Max.X)))
+			for px := from; px < to; px++ {
+				c.img.SetRGBA(px, py, col)
+			}
+		}
+	}
+}
+
+// polyline draws line segments by stamping squares of the line width along them
+func (c *pngCanvas) polyline(points []point, width float64, col color.RGBA) {
+	for i := 0; i+1 < len(points); i++ {
+		a, b := points[i], points[i+1]
+		steps := int(math.Ceil(math.Hypot(b.X-a.X, b.Y-a.Y)*2)) + 1
+		for s := 0; s <= steps; s++ {
+			f := float64(s) / float64(steps)
+			x, y := a.X+f*(b.X-a.X), a.Y+f*(b.Y-a.Y)
+			c.rect(x-width/2, y-width/2, width, width, col)
+		}
+	}
+}
+
+func (c *pngCanvas) text(x, y float64, s string, anchor textAnchor, col color.RGBA) {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, s)
+	switch anchor {
+	case anchorMiddle:
+		x -= float64(width.Round()) / 2
+	case anchorEnd:
+		x -= float64(width.Round())
+	}
+	d := font.Drawer{
+		Dst:  c.img,
+		Src:  image.NewUniform(col),
+		Face: face,
+		Dot:  fixed.P(int(math.Round(x)), int(math.Round(y))),
+	}
+	d.DrawString(s)
+}
@@ -0,0 +1,66 @@
+package chart
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// SvgRenderer renders a chart in SVG format
+type SvgRenderer struct {
+	Data    *Data
+	Options Options
+}
+
+// Render renders the chart
+func (r SvgRenderer) Render(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	cv := svgCanvas{w: bw}
+	fmt.Fprintf(
+		bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		r.Options.Width, r.Options.Height, r.Options.Width, r.Options.Height,
+	)
+	if err := draw(&cv, r.Data, &r.Options); err != nil {
+		return err
+	}
+	fmt.Fprint(bw, "</svg>\n")
+	return bw.Flush()
+}
+
+type svgCanvas struct {
+	w io.Writer
+}
+
+func (c *svgCanvas) rect(x, y, w, h float64, col color.RGBA) {
+	fmt.Fprintf(c.w, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n", x, y, w, h, svgColor(col))
+}
+
+func (c *svgCanvas) polygon(points []point, col color.RGBA) {
+	fmt.Fprintf(c.w, `<polygon points="%s" fill="%s" stroke="white"/>`+"\n", svgPoints(points), svgColor(col))
+}
+
+func (c *svgCanvas) polyline(points []point, width float64, col color.RGBA) {
+	fmt.Fprintf(c.w, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%.1f"/>`+"\n", svgPoints(points), svgColor(col), width)
+}
+
+func (c *svgCanvas) text(x, y float64, s string, anchor textAnchor, col color.RGBA) {
+	anchors := [...]string{"start", "middle", "end"}
+	sb := strings.Builder{}
+	_ = xml.EscapeText(&sb, []byte(s))
+	fmt.Fprintf(c.w, `<text x="%.1f" y="%.1f" text-anchor="%s" fill="%s">%s</text>`+"\n", x, y, anchors[anchor], svgColor(col), sb.String())
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func svgPoints(points []point) string {
+	parts := make([]string, len(points))
+	for i, p := range points {
+		parts[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+	}
+	return strings.Join(parts, " ")
+}
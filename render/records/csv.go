@@ -12,13 +12,14 @@ import (
 // CsvRenderer renders records for CSV export
 type CsvRenderer struct {
 	Separator string
+	Formatter util.Formatter
 	Results   chan core.FilterResult
 }
 
 func (wr CsvRenderer) writeHeader(w io.Writer) error {
 	_, err := fmt.Fprintf(
 		w, "%s\n",
-		strings.Join([]string{"start", "end", "project", "total", "work", "pause", "note", "tags"}, wr.Separator),
+		strings.Join([]string{"start", "end", "project", "total", "work", "pause", "note", "tags", "links"}, wr.Separator),
 	)
 	return err
 }
@@ -40,7 +41,7 @@ func (wr CsvRenderer) Render(w io.Writer) error {
 		if r.End.IsZero() {
 			endTime = ""
 		} else {
-			endTime = r.End.Format(util.DateTimeFormat)
+			endTime = wr.Formatter.DateTime(r.End)
 		}
 
 		tags := make([]string, len(r.Tags))
@@ -50,17 +51,23 @@ func (wr CsvRenderer) Render(w io.Writer) error {
 			i++
 		}
 
+		links := make([]string, len(r.Links))
+		for i, l := range r.Links {
+			links[i] = l.Target
+		}
+
 		_, err = fmt.Fprintf(
 			w, "%s\n",
 			strings.Join([]string{
-				r.Start.Format(util.DateTimeFormat),
+				wr.Formatter.DateTime(r.Start),
 				endTime,
 				r.Project,
-				util.FormatDuration(r.TotalDuration(util.NoTime, util.NoTime)),
-				util.FormatDuration(r.Duration(util.NoTime, util.NoTime)),
-				util.FormatDuration(r.PauseDuration(util.NoTime, util.NoTime)),
+				wr.Formatter.Duration(r.TotalDuration(util.NoTime, util.NoTime)),
+				wr.Formatter.Duration(r.Duration(util.NoTime, util.NoTime)),
+				wr.Formatter.Duration(r.PauseDuration(util.NoTime, util.NoTime)),
 				fmt.Sprintf("\"%s\"", strings.ReplaceAll(r.Note, "\n", "\\n")),
 				strings.Join(tags, " "),
+				strings.Join(links, " "),
 			}, wr.Separator),
 		)
 	}
@@ -10,14 +10,15 @@ import (
 
 	"github.com/gookit/color"
 	"github.com/mlange-42/track/core"
-	"github.com/mlange-42/track/util"
 	"golang.org/x/exp/maps"
 )
 
 // TextRenderer renders a week or day schedule as colored text
 type TextRenderer struct {
-	Track         *core.Track
-	Reporter      *core.Reporter
+	Track    *core.Track
+	Reporter *core.Reporter
+	// Breaks between records, shown in empty cells
+	Breaks        []core.Break
 	StartDate     time.Time
 	Weekly        bool
 	BlocksPerHour int
@@ -30,6 +31,8 @@ func (r *TextRenderer) Render(w io.Writer) error {
 	spaceSym := []rune(r.Track.Config.EmptyCell)[0]
 	pauseSym := []rune(r.Track.Config.PauseCell)[0]
 	recordSym := []rune(r.Track.Config.RecordCell)[0]
+	breakSym := []rune(r.Track.Config.BreakCell)[0]
+	breakColor := *color.S256(15, 8)
 
 	projects := maps.Keys(r.Reporter.Projects)
 	sort.Strings(projects)
@@ -53,6 +56,19 @@ func (r *TextRenderer) Render(w io.Writer) error {
 	paused := make([]bool, 24*numDays*bph)
 	record := make([]int, 24*numDays*bph)
 
+	breaks := make([]int, 24*numDays*bph)
+	breakStarts := make([]int, len(r.Breaks))
+	for brkIdx, brk := range r.Breaks {
+		startIdx, endIdx, ok := toIndexRange(brk.Start, brk.End, r.StartDate, bph, numDays)
+		if !ok {
+			continue
+		}
+		breakStarts[brkIdx] = startIdx
+		for i := startIdx; i <= endIdx; i++ {
+			breaks[i] = brkIdx + 1
+		}
+	}
+
 	now := time.Now()
 
 	for recIdx, rec := range r.Reporter.Records {
@@ -79,7 +95,7 @@ func (r *TextRenderer) Render(w io.Writer) error {
 	nowIdx := int(now.Sub(r.StartDate).Hours() * float64(bph))
 
 	fmt.Fprintf(w, "      |Day %s : %s/cell\n",
-		r.StartDate.Format(util.DateFormat),
+		r.Track.Config.Formatter().Date(r.StartDate),
 		time.Duration(1e9*(int(time.Hour)/(bph*1e9))).String(),
 	)
 
@@ -135,6 +151,19 @@ func (r *TextRenderer) Render(w io.Writer) error {
 				if pause {
 					sym = pauseSym
 				}
+				if brk := breaks[i]; brk > 0 && pr == 0 {
+					sym = breakSym
+					col = breakColor
+					if !r.Weekly {
+						note := []rune(r.Breaks[brk-1].Note)
+						idx := i - breakStarts[brk-1]
+						if idx == 0 || idx == len(note)+1 {
+							sym = ' '
+						} else if idx <= len(note) {
+							sym = note[idx-1]
+						}
+					}
+				}
 				if !r.Weekly && !pause && pr > 0 {
 					nameLen := len(currName)
 					noteLen := len(currNote)
@@ -186,9 +215,19 @@ func (r *TextRenderer) Render(w io.Writer) error {
 		}
 
 		line1 += col.Sprintf(" %c:%3s ", symbols[indices[p]], p)
-		line2 += col.Sprintf(" %*s ", width+2, util.FormatDuration(r.Reporter.TotalTime[p], false))
+		line2 += col.Sprintf(" %*s ", width+2, r.Track.Config.Formatter().Duration(r.Reporter.TotalTime[p], false))
 		lineWidth += width + 4
 	}
+	if breakTime := r.breakTime(numDays); breakTime > 0 {
+		if lineWidth > 0 && lineWidth+10 > totalWidth {
+			fmt.Fprintln(w, line1)
+			fmt.Fprintln(w, line2)
+			line1 = ""
+			line2 = ""
+		}
+		line1 += breakColor.Sprintf(" %c:%3s ", breakSym, "breaks")
+		line2 += breakColor.Sprintf(" %*s ", 8, r.Track.Config.Formatter().Duration(breakTime, false))
+	}
 	if len(line1) > 0 {
 		fmt.Fprintln(w, line1)
 		fmt.Fprintln(w, line2)
@@ -215,3 +254,9 @@ func toIndexRange(start, end, startDate time.Time, bph int, days int) (int, int,
 	}
 	return startIdx, endIdx, true
 }
+
+// breakTime returns the total time of all breaks in the days of the schedule
+func (r *TextRenderer) breakTime(days int) time.Duration {
+	breaks := core.Breaks{Breaks: r.Breaks}
+	return breaks.TotalTime(r.StartDate, r.StartDate.AddDate(0, 0, days))
+}
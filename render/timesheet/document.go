@@ -0,0 +1,107 @@
+package timesheet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page size in PDF points
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+)
+
+// document is a minimal PDF document writer.
+// It supports multiple pages with text in the standard Helvetica fonts and simple lines.
+type document struct {
+	pages []*bytes.Buffer
+}
+
+// newPage adds a new page to the document and returns it's content stream
+func (d *document) newPage() *bytes.Buffer {
+	page := &bytes.Buffer{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// text writes text at the given position, with y measured from the top of the page
+func text(page *bytes.Buffer, x, y float64, size float64, bold bool, str string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, pageHeight-y, escape(str))
+}
+
+// line draws a line, with y coordinates measured from the top of the page
+func line(page *bytes.Buffer, x1, y1, x2, y2 float64, width float64) {
+	fmt.Fprintf(page, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, pageHeight-y1, x2, pageHeight-y2)
+}
+
+// escape escapes PDF string delimiters and replaces characters not representable in WinAnsiEncoding
+func escape(str string) string {
+	sb := strings.Builder{}
+	for _, r := range str {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case r == '\n' || r == '\r' || r == '\t':
+			sb.WriteRune(' ')
+		case r < 32:
+			continue
+		case r < 128:
+			sb.WriteRune(r)
+		case r >= 160 && r < 256:
+			fmt.Fprintf(&sb, "\\%03o", r)
+		default:
+			sb.WriteRune('?')
+		}
+	}
+	return sb.String()
+}
+
+// write writes the document in PDF format
+func (d *document) write(w io.Writer) error {
+	buf := bytes.Buffer{}
+	offsets := []int{}
+
+	object := func(content string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), content)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1-4 are catalog, page tree and fonts.
+	// Pages and their content streams follow, starting with object 5.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i,
+		))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,151 @@
+package timesheet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"golang.org/x/exp/maps"
+)
+
+var invoiceColumns = []float64{marginLeft, 125, 155, 230}
+
+// InvoicePdfRenderer renders an invoice with one row per day with invoiced work in PDF format
+type InvoicePdfRenderer struct {
+	Invoice  *core.Invoice
+	Records  []core.Record
+	Projects map[string]core.Project
+	// Currency for rates without a currency
+	Currency  string
+	Formatter util.Formatter
+	Name      string
+}
+
+type invoiceDay struct {
+	Date     time.Time
+	Earnings core.Earnings
+}
+
+// Render renders the invoice
+func (r InvoicePdfRenderer) Render(w io.Writer) error {
+	days := r.summarize()
+
+	doc := document{}
+	page := doc.newPage()
+	y := r.header(page)
+
+	total := core.Earnings{}
+	for i := range days {
+		day := &days[i]
+		if y > pageHeight-marginBottom-rowHeight {
+			page = doc.newPage()
+			y = invoiceTableHeader(page, marginTop)
+		}
+		total.Add(&day.Earnings)
+
+		row(page, invoiceColumns, y, false, []string{
+			r.Formatter.Date(day.Date),
+			day.Date.Weekday().String()[:2],
+			r.Formatter.Duration(day.Earnings.Time, false),
+			formatAmounts(day.Earnings.Amounts),
+		})
+		y += rowHeight
+	}
+
+	if y > pageHeight-marginBottom-6*rowHeight {
+		page = doc.newPage()
+		y = marginTop
+	}
+	line(page, marginLeft, y-rowHeight+4, pageWidth-marginRight, y-rowHeight+4, 0.8)
+	row(page, invoiceColumns, y, true, []string{
+		"Total", "",
+		r.Formatter.Duration(total.Time, false),
+		formatAmounts(total.Amounts),
+	})
+	y += rowHeight
+	if total.Unrated > 0 {
+		text(page, marginLeft, y, fontSize, false,
+			fmt.Sprintf("Includes %s without a rate", r.Formatter.Duration(total.Unrated, false)))
+		y += rowHeight
+	}
+	y += 3 * rowHeight
+
+	signatureLine(&doc, page, y, r.Name)
+	return doc.write(w)
+}
+
+func (r *InvoicePdfRenderer) header(page *bytes.Buffer) float64 {
+	y := marginTop
+	text(page, marginLeft, y, 16, true, fmt.Sprintf("Invoice %s", r.Invoice.Number))
+	y += 1.5 * rowHeight
+
+	from, to := r.Invoice.From, r.Invoice.To
+	if from == "" {
+		from = "..."
+	}
+	if to == "" {
+		to = "..."
+	}
+	text(page, marginLeft, y, fontSize+1, false, fmt.Sprintf("Project: %s", r.Invoice.Project))
+	if r.Name != "" {
+		text(page, 300, y, fontSize+1, false, r.Name)
+	}
+	y += rowHeight
+	text(page, marginLeft, y, fontSize+1, false, fmt.Sprintf("Period: %s - %s", from, to))
+	y += rowHeight
+	text(page, marginLeft, y, fontSize+1, false, fmt.Sprintf("Date: %s", r.Formatter.Date(r.Invoice.Created)))
+	y += 2 * rowHeight
+
+	return invoiceTableHeader(page, y)
+}
+
+func invoiceTableHeader(page *bytes.Buffer, y float64) float64 {
+	row(page, invoiceColumns, y, true, []string{"Date", "Day", "Work", "Amount"})
+	line(page, marginLeft, y+4, pageWidth-marginRight, y+4, 0.8)
+	return y + rowHeight
+}
+
+// summarize calculates time and earnings per day, for days with invoiced work
+func (r *InvoicePdfRenderer) summarize() []invoiceDay {
+	days := []invoiceDay{}
+	if len(r.Records) == 0 {
+		return days
+	}
+	first, last := r.Records[0].Start, r.Records[0].End
+	for _, rec := range r.Records {
+		if rec.Start.Before(first) {
+			first = rec.Start
+		}
+		if rec.End.After(last) {
+			last = rec.End
+		}
+	}
+
+	now := time.Now()
+	for date := util.ToDate(first); date.Before(last); date = date.AddDate(0, 0, 1) {
+		day := invoiceDay{Date: date, Earnings: core.Earnings{Amounts: map[string]float64{}}}
+		for _, e := range core.NewEarnings(r.Projects, r.Records, date, date.AddDate(0, 0, 1), now, r.Currency) {
+			day.Earnings.Add(e)
+		}
+		if day.Earnings.Time > 0 {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// formatAmounts formats amounts in multiple currencies, ordered by currency
+func formatAmounts(amounts map[string]float64) string {
+	currencies := maps.Keys(amounts)
+	sort.Strings(currencies)
+	parts := make([]string, len(currencies))
+	for i, c := range currencies {
+		parts[i] = strings.TrimSpace(fmt.Sprintf("%.2f %s", amounts[c], c))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,200 @@
+package timesheet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	marginTop    = 60.0
+	marginBottom = 60.0
+	marginLeft   = 50.0
+	marginRight  = 50.0
+	rowHeight    = 16.0
+	fontSize     = 9.0
+)
+
+var columns = []float64{marginLeft, 125, 155, 200, 245, 295, 350}
+
+// PdfRenderer renders a timesheet with one row per day in PDF format
+type PdfRenderer struct {
+	Reporter  *core.Reporter
+	StartDate time.Time
+	EndDate   time.Time
+	Title     string
+	Name      string
+}
+
+type daySummary struct {
+	Date     time.Time
+	First    time.Time
+	Last     time.Time
+	Work     time.Duration
+	Pause    time.Duration
+	Projects map[string]bool
+}
+
+// Render renders the timesheet
+func (r PdfRenderer) Render(w io.Writer) error {
+	days := r.summarize()
+	format := r.Reporter.Track.Config.Formatter()
+
+	doc := document{}
+	page := doc.newPage()
+	y := r.header(page)
+
+	var totalWork, totalPause time.Duration
+	for _, day := range days {
+		if y > pageHeight-marginBottom-rowHeight {
+			page = doc.newPage()
+			y = tableHeader(page, marginTop)
+		}
+		totalWork += day.Work
+		totalPause += day.Pause
+
+		cells := []string{
+			format.Date(day.Date),
+			day.Date.Weekday().String()[:2],
+			"", "", "", "", "",
+		}
+		if day.Work > 0 {
+			projects := maps.Keys(day.Projects)
+			sort.Strings(projects)
+			cells[2] = format.Time(day.First)
+			cells[3] = format.Time(day.Last)
+			cells[4] = format.Duration(day.Pause, false)
+			cells[5] = format.Duration(day.Work, false)
+			cells[6] = truncate(strings.Join(projects, ", "), 40)
+		}
+		row(page, columns, y, false, cells)
+		y += rowHeight
+	}
+
+	if y > pageHeight-marginBottom-4*rowHeight {
+		page = doc.newPage()
+		y = marginTop
+	}
+	line(page, marginLeft, y-rowHeight+4, pageWidth-marginRight, y-rowHeight+4, 0.8)
+	row(page, columns, y, true, []string{
+		"Total", "", "", "",
+		format.Duration(totalPause, false),
+		format.Duration(totalWork, false),
+		"",
+	})
+	y += 4 * rowHeight
+
+	signatureLine(&doc, page, y, r.Name)
+	return doc.write(w)
+}
+
+// signatureLine draws lines for date and signature, on a new page if there is not enough space left
+func signatureLine(doc *document, page *bytes.Buffer, y float64, name string) {
+	if y > pageHeight-marginBottom {
+		page = doc.newPage()
+		y = marginTop + 2*rowHeight
+	}
+	line(page, marginLeft, y, marginLeft+150, y, 0.5)
+	line(page, 300, y, pageWidth-marginRight, y, 0.5)
+	text(page, marginLeft, y+12, fontSize, false, "Date")
+	signature := "Signature"
+	if name != "" {
+		signature = fmt.Sprintf("Signature (%s)", name)
+	}
+	text(page, 300, y+12, fontSize, false, signature)
+}
+
+func (r *PdfRenderer) header(page *bytes.Buffer) float64 {
+	format := r.Reporter.Track.Config.Formatter()
+	title := r.Title
+	if title == "" {
+		title = "Timesheet"
+	}
+	y := marginTop
+	text(page, marginLeft, y, 16, true, title)
+	y += 1.5 * rowHeight
+
+	period := fmt.Sprintf(
+		"%s - %s",
+		format.Date(r.StartDate),
+		format.Date(r.EndDate.Add(-time.Second)),
+	)
+	text(page, marginLeft, y, fontSize+1, false, period)
+	if r.Name != "" {
+		text(page, 300, y, fontSize+1, false, r.Name)
+	}
+	y += 2 * rowHeight
+
+	return tableHeader(page, y)
+}
+
+func tableHeader(page *bytes.Buffer, y float64) float64 {
+	row(page, columns, y, true, []string{"Date", "Day", "From", "To", "Pause", "Work", "Projects"})
+	line(page, marginLeft, y+4, pageWidth-marginRight, y+4, 0.8)
+	return y + rowHeight
+}
+
+func row(page *bytes.Buffer, columns []float64, y float64, bold bool, cells []string) {
+	for i, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		text(page, columns[i], y, fontSize, bold, cell)
+	}
+}
+
+func (r *PdfRenderer) summarize() []daySummary {
+	days := []daySummary{}
+	for date := util.ToDate(r.StartDate); date.Before(r.EndDate); date = date.AddDate(0, 0, 1) {
+		next := date.AddDate(0, 0, 1)
+		day := daySummary{
+			Date:     date,
+			Projects: map[string]bool{},
+		}
+		for _, rec := range r.Reporter.Records {
+			if _, ok := r.Reporter.Projects[rec.Project]; !ok {
+				continue
+			}
+			work := rec.Duration(date, next)
+			if work <= 0 {
+				continue
+			}
+			start, end := rec.Start, rec.End
+			if end.IsZero() {
+				end = time.Now()
+			}
+			if start.Before(date) {
+				start = date
+			}
+			if end.After(next) {
+				end = next
+			}
+			if day.First.IsZero() || start.Before(day.First) {
+				day.First = start
+			}
+			if end.After(day.Last) {
+				day.Last = end
+			}
+			day.Work += work
+			day.Pause += rec.PauseDuration(date, next)
+			day.Projects[rec.Project] = true
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+func truncate(str string, length int) string {
+	runes := []rune(str)
+	if len(runes) <= length {
+		return str
+	}
+	return string(runes[:length-1]) + "."
+}
@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/mlange-42/track/core"
-	"github.com/mlange-42/track/util"
 )
 
 // CsvRenderer renders a tree in treemap CSV format
@@ -28,9 +27,9 @@ func (r CsvRenderer) Render(w io.Writer) error {
 func (r CsvRenderer) render(t *core.ProjectNode, w io.Writer, path string) error {
 	total := r.Reporter.TotalTime[t.Value.Name]
 	if len(path) == 0 {
-		path = fmt.Sprintf("%s (%s)", t.Value.Name, util.FormatDuration(total))
+		path = fmt.Sprintf("%s (%s)", t.Value.Name, r.Track.Config.Formatter().Duration(total))
 	} else {
-		path = fmt.Sprintf("%s/%s (%s)", path, t.Value.Name, util.FormatDuration(total))
+		path = fmt.Sprintf("%s/%s (%s)", path, t.Value.Name, r.Track.Config.Formatter().Duration(total))
 	}
 
 	totalHours := total.Hours()
@@ -0,0 +1,118 @@
+package vegalite
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"sort"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+// Schema is the Vega-Lite schema URL of generated specs
+const Schema = "https://vega.github.io/schema/vega-lite/v5.json"
+
+// Spec is a Vega-Lite specification with embedded data
+type Spec struct {
+	Schema      string   `json:"$schema"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Width       int      `json:"width,omitempty"`
+	Height      int      `json:"height,omitempty"`
+	Data        Data     `json:"data"`
+	Mark        Mark     `json:"mark"`
+	Encoding    Encoding `json:"encoding"`
+}
+
+// Data holds the inline data values of a spec
+type Data struct {
+	Values []Row `json:"values"`
+}
+
+// Row is a data row
+type Row map[string]interface{}
+
+// Mark is the mark of a spec
+type Mark struct {
+	Type    string `json:"type"`
+	Tooltip bool   `json:"tooltip,omitempty"`
+	Point   bool   `json:"point,omitempty"`
+}
+
+// Encoding is the encoding of a spec
+type Encoding struct {
+	X       *Field  `json:"x,omitempty"`
+	Y       *Field  `json:"y,omitempty"`
+	Color   *Field  `json:"color,omitempty"`
+	Theta   *Field  `json:"theta,omitempty"`
+	Tooltip []Field `json:"tooltip,omitempty"`
+}
+
+// Field is an encoding channel, mapping a data field
+type Field struct {
+	Field     string      `json:"field"`
+	Type      string      `json:"type"`
+	Title     string      `json:"title,omitempty"`
+	Aggregate string      `json:"aggregate,omitempty"`
+	TimeUnit  string      `json:"timeUnit,omitempty"`
+	Format    string      `json:"format,omitempty"`
+	Sort      interface{} `json:"sort,omitempty"`
+	Scale     *Scale      `json:"scale,omitempty"`
+}
+
+// Scale is the scale of an encoding channel
+type Scale struct {
+	Domain []string `json:"domain,omitempty"`
+	Range  []string `json:"range,omitempty"`
+}
+
+// Field types
+const (
+	Quantitative = "quantitative"
+	Nominal      = "nominal"
+	Ordinal      = "ordinal"
+	Temporal     = "temporal"
+)
+
+// New creates a spec with the given title, mark type and data rows
+func New(title string, mark string, values []Row) *Spec {
+	return &Spec{
+		Schema: Schema,
+		Title:  title,
+		Data:   Data{Values: values},
+		Mark:   Mark{Type: mark, Tooltip: true},
+	}
+}
+
+// Render writes the spec as indented JSON
+func (s *Spec) Render(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// Hours converts a duration to hours, rounded to 1/1000
+func Hours(d time.Duration) float64 {
+	return float64(d.Round(time.Hour/1000)) / float64(time.Hour)
+}
+
+// ColorScale creates a scale that maps names to fixed colors, sorted by name
+func ColorScale(colors map[string]color.RGBA) *Scale {
+	names := maps.Keys(colors)
+	sort.Strings(names)
+	scale := Scale{
+		Domain: names,
+		Range:  make([]string, len(names)),
+	}
+	for i, name := range names {
+		c := colors[name]
+		scale.Range[i] = hexColor(c)
+	}
+	return &scale
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
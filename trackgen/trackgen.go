@@ -0,0 +1,229 @@
+// Package trackgen deterministically generates realistic projects and records,
+// for tests, benchmarks and demo screenshots.
+//
+// The same Config, including its Seed, always generates the same data.
+package trackgen
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+)
+
+// Config for generated data
+type Config struct {
+	// Seed of the random number generator
+	Seed int64
+	// First day of generated records
+	Start time.Time
+	// Number of days of generated records
+	Days int
+	// Whether to generate records on saturdays and sundays
+	Weekends bool
+	// Number of records per day
+	PerDay int
+	// Start of the work day, as time since midnight
+	DayStart time.Duration
+	// Length of the work day. Records are distributed over this time span
+	DayLength time.Duration
+	// Fraction of the work day covered by records, between 0 and 1
+	Density float64
+	// Maximum number of pauses per record
+	MaxPauses int
+	// Pool of tags for notes
+	Tags []string
+	// Maximum number of tags per record
+	MaxTags int
+	// Projects, as pairs of name and parent name. Records are distributed over projects without sub-projects
+	Projects []util.Pair[string, string]
+}
+
+// DefaultConfig returns the default config, for one year of records starting at 2000-01-01
+func DefaultConfig() Config {
+	return Config{
+		Seed:      0,
+		Start:     util.Date(2000, 1, 1),
+		Days:      365,
+		Weekends:  false,
+		PerDay:    6,
+		DayStart:  8 * time.Hour,
+		DayLength: 9 * time.Hour,
+		Density:   0.8,
+		MaxPauses: 2,
+		Tags:      []string{"bugfix", "feature", "docs", "call", "planning", "support"},
+		MaxTags:   2,
+		Projects: []util.Pair[string, string]{
+			util.NewPair("work", ""),
+			util.NewPair("coding", "work"),
+			util.NewPair("review", "work"),
+			util.NewPair("meetings", "work"),
+			util.NewPair("admin", ""),
+		},
+	}
+}
+
+// Check checks the config for invalid values
+func (c *Config) Check() error {
+	if c.Days < 0 {
+		return fmt.Errorf("number of days must not be negative")
+	}
+	if c.PerDay < 0 {
+		return fmt.Errorf("records per day must not be negative")
+	}
+	if c.DayLength <= 0 || c.DayStart < 0 || c.DayStart+c.DayLength > 24*time.Hour {
+		return fmt.Errorf("work day must be within a single day")
+	}
+	if c.Density <= 0 || c.Density > 1 {
+		return fmt.Errorf("density must be in range (0, 1]")
+	}
+	if c.MaxPauses < 0 || c.MaxTags < 0 {
+		return fmt.Errorf("maximum number of pauses and tags must not be negative")
+	}
+	if c.MaxTags > 0 && len(c.Tags) == 0 {
+		return fmt.Errorf("no tags given")
+	}
+	if len(c.leafProjects()) == 0 {
+		return fmt.Errorf("no projects given")
+	}
+	return nil
+}
+
+// leafProjects returns the names of all projects without sub-projects
+func (c *Config) leafProjects() []string {
+	parents := map[string]bool{}
+	for _, p := range c.Projects {
+		parents[p.Value] = true
+	}
+	leafs := []string{}
+	for _, p := range c.Projects {
+		if !parents[p.Key] {
+			leafs = append(leafs, p.Key)
+		}
+	}
+	return leafs
+}
+
+// Projects generates the projects of the config, with distinct symbols and colors
+func Projects(conf *Config) []core.Project {
+	projects := make([]core.Project, len(conf.Projects))
+	for i, p := range conf.Projects {
+		projects[i] = core.NewProject(p.Key, p.Value, strings.ToUpper(p.Key[:1]), []string{}, 15, uint8(i%14+1))
+	}
+	return projects
+}
+
+// Records generates records, in chronological order.
+// Tags in notes use the tag prefix of the given syntax, like Track.Syntax.
+func Records(syntax *core.Syntax, conf *Config) ([]core.Record, error) {
+	if err := conf.Check(); err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewSource(conf.Seed))
+	projects := conf.leafProjects()
+
+	records := []core.Record{}
+	if conf.PerDay == 0 {
+		return records, nil
+	}
+	slot := conf.DayLength / time.Duration(conf.PerDay)
+	day := util.ToDate(conf.Start)
+	for i := 0; i < conf.Days; i++ {
+		date := day.AddDate(0, 0, i)
+		if !conf.Weekends && (date.Weekday() == time.Saturday || date.Weekday() == time.Sunday) {
+			continue
+		}
+		for j := 0; j < conf.PerDay; j++ {
+			slotStart := date.Add(conf.DayStart + time.Duration(j)*slot)
+			records = append(records, record(syntax, conf, rng, projects, slotStart, slot))
+		}
+	}
+	return records, nil
+}
+
+// record generates a record within a time slot.
+// Start and end are rounded to minutes, as in stored records.
+func record(syntax *core.Syntax, conf *Config, rng *rand.Rand, projects []string, slotStart time.Time, slot time.Duration) core.Record {
+	length := time.Duration(float64(slot) * conf.Density * (0.75 + 0.25*rng.Float64()))
+	offset := time.Duration(rng.Int63n(int64(slot-length) + 1))
+	start := slotStart.Add(offset).Truncate(time.Minute)
+	end := start.Add(length).Truncate(time.Minute)
+	if !end.After(start) {
+		end = start.Add(time.Minute)
+	}
+
+	pauses := []core.Pause{}
+	if conf.MaxPauses > 0 {
+		count := rng.Intn(conf.MaxPauses + 1)
+		step := end.Sub(start) / time.Duration(count+1)
+		for k := 1; k <= count; k++ {
+			pauseStart := start.Add(time.Duration(k) * step).Truncate(time.Minute)
+			pauseEnd := pauseStart.Add(step / 4).Truncate(time.Minute)
+			if !pauseEnd.After(pauseStart) || !pauseEnd.Before(end) {
+				continue
+			}
+			pauses = append(pauses, core.Pause{Start: pauseStart, End: pauseEnd, Note: "Break"})
+		}
+	}
+
+	words := []string{notePhrases[rng.Intn(len(notePhrases))]}
+	if conf.MaxTags > 0 {
+		count := rng.Intn(conf.MaxTags + 1)
+		perm := rng.Perm(len(conf.Tags))
+		for k := 0; k < count && k < len(perm); k++ {
+			words = append(words, syntax.TagPrefix+conf.Tags[perm[k]])
+		}
+	}
+	note := strings.Join(words, " ")
+	tags, err := syntax.ExtractTags(note)
+	if err != nil {
+		panic(fmt.Sprintf("invalid generated note '%s': %s", note, err))
+	}
+
+	return core.Record{
+		Project: projects[rng.Intn(len(projects))],
+		Start:   start,
+		End:     end,
+		Pause:   pauses,
+		Note:    note,
+		Tags:    tags,
+	}
+}
+
+// Generate generates projects and records, and saves them to a Track.
+// Existing projects with the same names are overwritten. Fails for existing records.
+//
+// Returns the number of generated records.
+func Generate(t *core.Track, conf *Config) (int, error) {
+	records, err := Records(t.Syntax(), conf)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range Projects(conf) {
+		if err := t.SaveProject(p, true); err != nil {
+			return 0, err
+		}
+	}
+	for i := range records {
+		if err := t.SaveRecord(&records[i], false); err != nil {
+			return i, err
+		}
+	}
+	return len(records), nil
+}
+
+var notePhrases = []string{
+	"Lorem ipsum dolor sit amet",
+	"Consectetur adipiscing elit",
+	"Sed do eiusmod tempor incididunt",
+	"Ut labore et dolore magna aliqua",
+	"Ullamcorper sit amet risus nullam",
+	"Cursus euismod quis viverra nibh",
+	"Velit euismod in pellentesque",
+	"Risus nec feugiat in fermentum",
+	"Adipiscing bibendum est ultricies",
+	"Sagittis orci a scelerisque purus",
+}
@@ -0,0 +1,108 @@
+package trackgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecords(t *testing.T) {
+	conf := DefaultConfig()
+	conf.Days = 14
+
+	records, err := Records(core.DefaultSyntax(), &conf)
+	assert.Nil(t, err, "Error generating records")
+	assert.Equal(t, 10*conf.PerDay, len(records), "Wrong number of records")
+
+	again, err := Records(core.DefaultSyntax(), &conf)
+	assert.Nil(t, err, "Error generating records")
+	assert.Equal(t, records, again, "Records should be deterministic")
+
+	conf.Seed = 1
+	other, err := Records(core.DefaultSyntax(), &conf)
+	assert.Nil(t, err, "Error generating records")
+	assert.NotEqual(t, records, other, "Records should depend on the seed")
+
+	leafs := map[string]bool{"coding": true, "review": true, "meetings": true, "admin": true}
+	for i, r := range records {
+		assert.True(t, leafs[r.Project], "Wrong project %s", r.Project)
+		assert.True(t, r.End.After(r.Start), "Record must end after start")
+		assert.Equal(t, r.Start, r.Start.Truncate(time.Minute), "Start must be rounded to minutes")
+		if i > 0 {
+			assert.False(t, r.Start.Before(records[i-1].End), "Records must not overlap")
+		}
+		for _, p := range r.Pause {
+			assert.True(t, p.Start.After(r.Start) && p.End.Before(r.End), "Pause must be within record")
+		}
+		tags, err := core.ExtractTags(r.Note)
+		assert.Nil(t, err, "Error extracting tags")
+		assert.Equal(t, tags, r.Tags, "Tags must match note")
+	}
+
+	conf.Density = 0
+	_, err = Records(core.DefaultSyntax(), &conf)
+	assert.NotNil(t, err, "Expected error for invalid density")
+}
+
+func TestGenerate(t *testing.T) {
+	track, err := core.NewMemoryTrack()
+	assert.Nil(t, err, "Error creating Track instance")
+
+	conf := DefaultConfig()
+	conf.Start = util.Date(2001, 2, 3)
+	conf.Days = 7
+	conf.Weekends = true
+	conf.PerDay = 3
+
+	count, err := Generate(&track, &conf)
+	assert.Nil(t, err, "Error generating records")
+	assert.Equal(t, 21, count, "Wrong number of records")
+
+	projects, err := track.LoadAllProjects()
+	assert.Nil(t, err, "Error loading projects")
+	assert.Equal(t, 5, len(projects), "Wrong number of projects")
+	assert.Equal(t, "work", projects["coding"].Parent, "Wrong parent project")
+
+	records, err := Records(track.Syntax(), &conf)
+	assert.Nil(t, err, "Error generating records")
+	loaded, err := track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	assert.Equal(t, len(records), len(loaded), "Wrong number of records")
+	for i := range records {
+		assert.Equal(t, records[i].Start, loaded[i].Start, "Wrong record start")
+		assert.Equal(t, records[i].End, loaded[i].End, "Wrong record end")
+		assert.Equal(t, records[i].Pause, loaded[i].Pause, "Wrong pauses")
+		assert.Equal(t, records[i].Tags, loaded[i].Tags, "Wrong tags")
+	}
+}
+
+func TestGenerateCustomTagPrefix(t *testing.T) {
+	fsys := core.NewMemoryFileSystem()
+	track, err := core.NewTrackWithFS("/track", fsys)
+	assert.Nil(t, err, "Error creating Track instance")
+	track.Config.TagPrefix, track.Config.CommentPrefix = "#", "//"
+	assert.Nil(t, track.SaveConfig(), "Error saving config")
+	track, err = core.NewTrackWithFS("/track", fsys)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	conf := DefaultConfig()
+	conf.Start = util.Date(2001, 2, 3)
+	conf.Days = 7
+
+	_, err = Generate(&track, &conf)
+	assert.Nil(t, err, "Error generating records")
+
+	loaded, err := track.LoadAllRecords()
+	assert.Nil(t, err, "Error loading records")
+	tagged := 0
+	for _, r := range loaded {
+		assert.NotContains(t, r.Note, core.DefaultTagPrefix, "Note should use the track's tag prefix")
+		_, err := track.Syntax().DeserializeRecordStrict(track.Syntax().SerializeRecord(&r, util.NoTime), util.NoTime)
+		assert.Nil(t, err, "Error parsing generated record strictly")
+		tagged += len(r.Tags)
+	}
+	assert.Greater(t, tagged, 0, "Expected tags with the track's tag prefix")
+}
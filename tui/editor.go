@@ -0,0 +1,490 @@
+// Package tui provides an interactive terminal editor for records.
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"golang.org/x/exp/maps"
+)
+
+// noteNewline represents line breaks of notes in the single-line note field
+const noteNewline = `\n`
+
+type mode uint8
+
+const (
+	modeBrowse mode = iota
+	modeEdit
+	modeInput
+)
+
+// field indices of the edit view. Pause fields follow after fieldNote.
+const (
+	fieldTime = iota
+	fieldProject
+	fieldNote
+	fieldPauses
+)
+
+// Editor is an interactive editor for browsing the records of a day or week, and editing them.
+//
+// The editor is driven by HandleKey, and rendered by View. See Run for running it in a terminal.
+type Editor struct {
+	// DryRun prevents saving changes
+	DryRun bool
+
+	track *core.Track
+	date  time.Time
+	week  bool
+
+	records  []core.Record
+	selected int
+
+	mode     mode
+	original core.Record
+	draft    core.Record
+	field    int
+	input    []rune
+	err      error
+	message  string
+}
+
+// NewEditor creates an Editor, showing the records of the given date
+func NewEditor(t *core.Track, date time.Time) (*Editor, error) {
+	e := &Editor{
+		track: t,
+		date:  util.ToDate(date),
+	}
+	if err := e.load(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// periodStart returns the first day of the current period
+func (e *Editor) periodStart() time.Time {
+	if e.week {
+		return util.WeekStart(e.date, e.track.Config.FirstWeekday())
+	}
+	return e.date
+}
+
+// load loads the records of the current period
+func (e *Editor) load() error {
+	start := e.periodStart()
+	days := 1
+	if e.week {
+		days = 7
+	}
+	records := []core.Record{}
+	for i := 0; i < days; i++ {
+		dayRecords, err := e.track.LoadDateRecords(start.AddDate(0, 0, i))
+		if err != nil && !errors.Is(err, core.ErrNoRecords) {
+			return err
+		}
+		records = append(records, dayRecords...)
+	}
+	e.records = records
+	if e.selected >= len(records) {
+		e.selected = len(records) - 1
+	}
+	if e.selected < 0 {
+		e.selected = 0
+	}
+	return nil
+}
+
+// HandleKey handles a key press. Returns true if the editor should quit.
+func (e *Editor) HandleKey(k Key) bool {
+	e.message = ""
+	switch e.mode {
+	case modeBrowse:
+		return e.handleBrowse(k)
+	case modeEdit:
+		e.handleEdit(k)
+	case modeInput:
+		e.handleInput(k)
+	}
+	return false
+}
+
+func (e *Editor) handleBrowse(k Key) bool {
+	switch {
+	case k.Code == KeyCtrlC || k.Code == KeyEsc || k.Is('q'):
+		return true
+	case k.Code == KeyUp || k.Is('k'):
+		if e.selected > 0 {
+			e.selected--
+		}
+	case k.Code == KeyDown || k.Is('j'):
+		if e.selected < len(e.records)-1 {
+			e.selected++
+		}
+	case k.Code == KeyLeft || k.Is('h'):
+		e.shiftPeriod(-1)
+	case k.Code == KeyRight || k.Is('l'):
+		e.shiftPeriod(1)
+	case k.Is('w'):
+		e.week = !e.week
+		e.selected = 0
+		e.err = e.load()
+	case k.Code == KeyEnter:
+		if len(e.records) == 0 {
+			return false
+		}
+		e.original = e.records[e.selected]
+		e.draft = copyRecord(&e.original)
+		e.field = fieldTime
+		e.mode = modeEdit
+		e.validate()
+	}
+	return false
+}
+
+// shiftPeriod moves to the previous or next day or week
+func (e *Editor) shiftPeriod(dir int) {
+	if e.week {
+		e.date = e.date.AddDate(0, 0, 7*dir)
+	} else {
+		e.date = e.date.AddDate(0, 0, dir)
+	}
+	e.selected = 0
+	e.err = e.load()
+}
+
+func (e *Editor) handleEdit(k Key) {
+	numFields := fieldPauses + len(e.draft.Pause) + 1
+	switch {
+	case k.Code == KeyCtrlC || k.Code == KeyEsc || k.Is('q'):
+		e.mode = modeBrowse
+		e.err = nil
+	case k.Code == KeyUp || k.Is('k'):
+		if e.field > 0 {
+			e.field--
+		}
+	case k.Code == KeyDown || k.Is('j'):
+		if e.field < numFields-1 {
+			e.field++
+		}
+	case k.Code == KeyEnter:
+		e.input = []rune(e.fieldValue(e.field))
+		e.mode = modeInput
+	case k.Is('s'):
+		if err := e.save(); err != nil {
+			e.err = err
+			return
+		}
+		e.message = fmt.Sprintf("Saved record %s", e.draft.Start.Format(util.DateTimeFormat))
+		if e.DryRun {
+			e.message += " - dry-run"
+		}
+		e.mode = modeBrowse
+		e.err = e.load()
+	}
+}
+
+func (e *Editor) handleInput(k Key) {
+	switch k.Code {
+	case KeyCtrlC, KeyEsc:
+		e.mode = modeEdit
+	case KeyEnter:
+		if err := e.setField(e.field, string(e.input)); err != nil {
+			e.err = err
+		} else {
+			e.validate()
+		}
+		e.mode = modeEdit
+	case KeyBackspace:
+		if len(e.input) > 0 {
+			e.input = e.input[:len(e.input)-1]
+		}
+	case KeyRune:
+		e.input = append(e.input, k.Rune)
+	}
+}
+
+// fieldValue returns the text representation of a field of the draft record
+func (e *Editor) fieldValue(field int) string {
+	r := &e.draft
+	switch field {
+	case fieldTime:
+		return fmt.Sprintf("%s - %s", util.FormatTimeWithOffset(r.Start, e.original.Start), util.FormatTimeWithOffset(r.End, e.original.Start))
+	case fieldProject:
+		return r.Project
+	case fieldNote:
+		return strings.ReplaceAll(r.Note, "\n", noteNewline)
+	}
+	idx := field - fieldPauses
+	if idx >= len(r.Pause) {
+		return ""
+	}
+	p := r.Pause[idx]
+	end := "?"
+	if !p.End.IsZero() {
+		end = p.End.Sub(p.Start).Round(time.Second).String()
+	}
+	value := fmt.Sprintf("%s - %s", util.FormatTimeWithOffset(p.Start, e.original.Start), end)
+	if p.Note != "" {
+		value += " / " + p.Note
+	}
+	return value
+}
+
+// setField parses a value into a field of the draft record.
+// An empty value for a pause removes the pause.
+func (e *Editor) setField(field int, value string) error {
+	value = strings.TrimSpace(value)
+	date := util.ToDate(e.original.Start)
+	switch field {
+	case fieldTime:
+		start, end, err := util.ParseTimeRange(value, date)
+		if err != nil {
+			return err
+		}
+		e.draft.Start, e.draft.End = start, end
+		return nil
+	case fieldProject:
+		if !e.track.ProjectExists(value) {
+			return fmt.Errorf("project '%s' does not exist", value)
+		}
+		e.draft.Project = value
+		return nil
+	case fieldNote:
+		note := strings.ReplaceAll(value, noteNewline, "\n")
+		tags, err := e.track.Syntax().ExtractTags(note)
+		if err != nil {
+			return err
+		}
+		e.draft.Note, e.draft.Tags = note, tags
+		return nil
+	}
+
+	idx := field - fieldPauses
+	if value == "" {
+		if idx < len(e.draft.Pause) {
+			e.draft.Pause = append(e.draft.Pause[:idx], e.draft.Pause[idx+1:]...)
+		}
+		return nil
+	}
+	parts := strings.SplitN(value, "/", 2)
+	start, end, err := util.ParseTimeRange(parts[0], date)
+	if err != nil {
+		return err
+	}
+	pause := core.Pause{Start: start, End: end}
+	if len(parts) > 1 {
+		pause.Note = strings.TrimSpace(parts[1])
+	}
+	if idx < len(e.draft.Pause) {
+		e.draft.Pause[idx] = pause
+	} else {
+		e.draft.Pause = append(e.draft.Pause, pause)
+	}
+	return nil
+}
+
+// validate checks the draft record, and sets the editor's error
+func (e *Editor) validate() {
+	e.err = e.check()
+}
+
+// check checks the draft record with Record.Check, and for overlaps with the other records of the period
+func (e *Editor) check() error {
+	project, err := e.track.LoadProject(e.draft.Project)
+	if err != nil {
+		return err
+	}
+	if err := e.draft.Check(&project); err != nil {
+		return err
+	}
+	if !e.draft.End.IsZero() && e.draft.End.After(time.Now()) {
+		return fmt.Errorf("end time is in the future")
+	}
+	if e.draft.End.IsZero() && !e.original.End.IsZero() {
+		return fmt.Errorf("can't open a finished record")
+	}
+	for i := range e.records {
+		other := &e.records[i]
+		if other.Start.Equal(e.original.Start) {
+			continue
+		}
+		if overlaps(&e.draft, other) {
+			return fmt.Errorf("overlaps record %s", other.Start.Format(util.DateTimeFormat))
+		}
+	}
+	return nil
+}
+
+// save saves the draft record, replacing the original record
+func (e *Editor) save() error {
+	if err := e.check(); err != nil {
+		return err
+	}
+	if err := e.checkStored(); err != nil {
+		return err
+	}
+	if e.DryRun {
+		return nil
+	}
+	if err := e.track.CheckWritable(); err != nil {
+		return err
+	}
+	if e.draft.Start.Equal(e.original.Start) {
+		return e.track.SaveRecord(&e.draft, true)
+	}
+	// The start time was moved, so the record is saved to a different file.
+	// Replace it in a single transaction, to not end up with both or none of the records.
+	tx := e.track.Begin()
+	tx.Save(&e.draft, false)
+	tx.Delete(&e.original)
+	return tx.Commit()
+}
+
+// checkStored checks the draft record for overlaps with all stored records,
+// including those outside the period, in case its time was moved beyond the period
+func (e *Editor) checkStored() error {
+	end := e.draft.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if !end.After(e.draft.Start) {
+		return nil
+	}
+	others, err := e.track.RecordsOverlapping(e.draft.Start, end)
+	if err != nil {
+		return err
+	}
+	for i := range others {
+		if !others[i].Start.Equal(e.original.Start) {
+			return fmt.Errorf("overlaps record %s", others[i].Start.Format(util.DateTimeFormat))
+		}
+	}
+	return nil
+}
+
+// overlaps checks if two records overlap. Running records are considered to end now.
+func overlaps(a, b *core.Record) bool {
+	aEnd, bEnd := a.End, b.End
+	now := time.Now()
+	if aEnd.IsZero() {
+		aEnd = now
+	}
+	if bEnd.IsZero() {
+		bEnd = now
+	}
+	return a.Start.Before(bEnd) && b.Start.Before(aEnd)
+}
+
+// copyRecord creates a deep copy of a record, to be edited independently of the original
+func copyRecord(r *core.Record) core.Record {
+	c := *r
+	c.Tags = make(map[string]string, len(r.Tags))
+	for k, v := range r.Tags {
+		c.Tags[k] = v
+	}
+	c.Pause = append([]core.Pause{}, r.Pause...)
+	c.Links = append([]core.Link{}, r.Links...)
+	return c
+}
+
+// View renders the editor
+func (e *Editor) View() string {
+	b := strings.Builder{}
+	if e.mode == modeBrowse {
+		e.viewBrowse(&b)
+	} else {
+		e.viewEdit(&b)
+	}
+	if e.err != nil {
+		fmt.Fprintf(&b, "\n%s\n", color.FgRed.Sprintf("Error: %s", e.err))
+	} else if e.message != "" {
+		fmt.Fprintf(&b, "\n%s\n", color.FgGreen.Sprint(e.message))
+	}
+	return b.String()
+}
+
+func (e *Editor) viewBrowse(b *strings.Builder) {
+	start := e.periodStart()
+	if e.week {
+		end := start.AddDate(0, 0, 6)
+		fmt.Fprintf(b, "Records %s - %s\n\n", start.Format(util.DateFormat), end.Format(util.DateFormat))
+	} else {
+		fmt.Fprintf(b, "Records %s (%s)\n\n", start.Format(util.DateFormat), start.Weekday().String()[:3])
+	}
+
+	if len(e.records) == 0 {
+		b.WriteString("  No records\n")
+	}
+	for i := range e.records {
+		r := &e.records[i]
+		day := ""
+		if e.week {
+			day = r.Start.Weekday().String()[:3] + " "
+		}
+		end := "?    "
+		if !r.End.IsZero() {
+			end = util.FormatTimeWithOffset(r.End, r.Start)
+		}
+		note := strings.SplitN(r.Note, "\n", 2)[0]
+		line := fmt.Sprintf("%s%s - %s %8s  %-16s %s",
+			day, r.Start.Format(util.TimeFormat), end,
+			util.FormatDuration(r.Duration(util.NoTime, util.NoTime)), r.Project, note)
+		writeLine(b, line, i == e.selected)
+	}
+	b.WriteString("\n←/→ previous/next  w day/week  ↑/↓ select  enter edit  q quit\n")
+}
+
+func (e *Editor) viewEdit(b *strings.Builder) {
+	fmt.Fprintf(b, "Edit record %s\n\n", e.original.Start.Format(util.DateTimeFormat))
+
+	numFields := fieldPauses + len(e.draft.Pause) + 1
+	for i := 0; i < numFields; i++ {
+		var label string
+		switch i {
+		case fieldTime:
+			label = "Time"
+		case fieldProject:
+			label = "Project"
+		case fieldNote:
+			label = "Note"
+		default:
+			if i-fieldPauses < len(e.draft.Pause) {
+				label = fmt.Sprintf("Pause %d", i-fieldPauses+1)
+			} else {
+				label = "Pause +"
+			}
+		}
+		value := e.fieldValue(i)
+		if e.mode == modeInput && i == e.field {
+			value = string(e.input) + "_"
+		}
+		writeLine(b, fmt.Sprintf("%-8s %s", label, value), i == e.field)
+	}
+	if len(e.draft.Tags) > 0 {
+		tags := maps.Keys(e.draft.Tags)
+		sort.Strings(tags)
+		fmt.Fprintf(b, "\n  Tags: %s\n", strings.Join(tags, " "))
+	}
+
+	if e.mode == modeInput {
+		b.WriteString("\nenter apply  esc cancel\n")
+	} else {
+		b.WriteString("\n↑/↓ select  enter edit field  s save  esc back\n")
+	}
+}
+
+// writeLine writes a line, highlighted if it is selected
+func writeLine(b *strings.Builder, line string, selected bool) {
+	if selected {
+		fmt.Fprintf(b, "%s\n", color.OpReverse.Sprint("> "+line))
+	} else {
+		fmt.Fprintf(b, "  %s\n", line)
+	}
+}
@@ -0,0 +1,224 @@
+package tui
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupEditor(t *testing.T) (*core.Track, *Editor, func()) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+
+	track, err := core.NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+
+	for _, name := range []string{"test", "other"} {
+		project := core.NewProject(name, "", "t", []string{}, 15, 0)
+		assert.Nil(t, track.SaveProject(project, false), "Error saving project")
+	}
+	records := []core.Record{
+		{Project: "test", Note: "First", Start: util.DateTime(2001, 2, 3, 8, 0, 0), End: util.DateTime(2001, 2, 3, 10, 0, 0)},
+		{Project: "test", Note: "Second", Start: util.DateTime(2001, 2, 3, 11, 0, 0), End: util.DateTime(2001, 2, 3, 12, 0, 0)},
+		{Project: "other", Note: "Next day", Start: util.DateTime(2001, 2, 4, 9, 0, 0), End: util.DateTime(2001, 2, 4, 10, 0, 0)},
+	}
+	for i := range records {
+		assert.Nil(t, track.SaveRecord(&records[i], false), "Error saving record")
+	}
+
+	editor, err := NewEditor(&track, util.Date(2001, 2, 3))
+	assert.Nil(t, err, "Error creating editor")
+
+	return &track, editor, func() { os.RemoveAll(dir) }
+}
+
+// typeText converts text to rune keys
+func typeText(text string) []Key {
+	return ParseKeys([]byte(text))
+}
+
+// sendKeys sends keys to the editor, and returns whether it quit
+func sendKeys(e *Editor, keys ...Key) bool {
+	for _, k := range keys {
+		if e.HandleKey(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// clearInput creates backspace keys to clear the input
+func clearInput(e *Editor) []Key {
+	keys := make([]Key, len(e.input))
+	for i := range keys {
+		keys[i] = Key{Code: KeyBackspace}
+	}
+	return keys
+}
+
+func TestEditorBrowse(t *testing.T) {
+	_, e, clean := setupEditor(t)
+	defer clean()
+
+	assert.Equal(t, 2, len(e.records))
+	assert.Contains(t, e.View(), "First")
+
+	sendKeys(e, Key{Code: KeyDown}, Key{Code: KeyDown})
+	assert.Equal(t, 1, e.selected)
+
+	sendKeys(e, Key{Code: KeyRight})
+	assert.Equal(t, 1, len(e.records))
+	assert.Contains(t, e.View(), "Next day")
+
+	sendKeys(e, typeText("w")...)
+	assert.True(t, e.week)
+	assert.Equal(t, 3, len(e.records))
+
+	assert.True(t, sendKeys(e, typeText("q")...))
+}
+
+func TestEditorEdit(t *testing.T) {
+	track, e, clean := setupEditor(t)
+	defer clean()
+
+	// Edit the note of the first record
+	sendKeys(e, Key{Code: KeyEnter}, Key{Code: KeyDown}, Key{Code: KeyDown}, Key{Code: KeyEnter})
+	assert.Equal(t, modeInput, e.mode)
+	assert.Equal(t, "First", string(e.input))
+	sendKeys(e, clearInput(e)...)
+	sendKeys(e, typeText("Changed +tag")...)
+	sendKeys(e, Key{Code: KeyEnter})
+	assert.Nil(t, e.err)
+	assert.Equal(t, map[string]string{"tag": ""}, e.draft.Tags)
+
+	// Add a pause
+	sendKeys(e, Key{Code: KeyDown}, Key{Code: KeyEnter})
+	sendKeys(e, typeText("09:00 - 15m / coffee")...)
+	sendKeys(e, Key{Code: KeyEnter})
+	assert.Nil(t, e.err)
+	assert.Equal(t, 1, len(e.draft.Pause))
+
+	// An invalid time overlapping the next record is reported, and can't be saved
+	sendKeys(e, Key{Code: KeyUp}, Key{Code: KeyUp}, Key{Code: KeyUp}, Key{Code: KeyEnter})
+	sendKeys(e, clearInput(e)...)
+	sendKeys(e, typeText("08:00 - 11:30")...)
+	sendKeys(e, Key{Code: KeyEnter})
+	assert.ErrorContains(t, e.err, "overlaps record")
+	sendKeys(e, typeText("s")...)
+	assert.Equal(t, modeEdit, e.mode)
+
+	sendKeys(e, Key{Code: KeyEnter})
+	sendKeys(e, clearInput(e)...)
+	sendKeys(e, typeText("08:00 - 10:30")...)
+	sendKeys(e, Key{Code: KeyEnter})
+	assert.Nil(t, e.err)
+
+	sendKeys(e, typeText("s")...)
+	assert.Nil(t, e.err)
+	assert.Equal(t, modeBrowse, e.mode)
+
+	record, err := track.LoadRecord(util.DateTime(2001, 2, 3, 8, 0, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, "Changed +tag", record.Note)
+	assert.Equal(t, util.DateTime(2001, 2, 3, 10, 30, 0), record.End)
+	assert.Equal(t, []core.Pause{{
+		Start: util.DateTime(2001, 2, 3, 9, 0, 0),
+		End:   util.DateTime(2001, 2, 3, 9, 15, 0),
+		Note:  "coffee",
+	}}, record.Pause)
+}
+
+func TestEditorMoveStart(t *testing.T) {
+	track, e, clean := setupEditor(t)
+	defer clean()
+
+	sendKeys(e, Key{Code: KeyDown}, Key{Code: KeyEnter}, Key{Code: KeyEnter})
+	sendKeys(e, clearInput(e)...)
+	sendKeys(e, typeText("10:30 - 12:00")...)
+	sendKeys(e, Key{Code: KeyEnter}, Key{Code: KeyDown}, Key{Code: KeyEnter})
+	sendKeys(e, clearInput(e)...)
+	sendKeys(e, typeText("unknown")...)
+	sendKeys(e, Key{Code: KeyEnter})
+	assert.ErrorContains(t, e.err, "does not exist")
+
+	sendKeys(e, Key{Code: KeyEnter})
+	sendKeys(e, clearInput(e)...)
+	sendKeys(e, typeText("other")...)
+	sendKeys(e, Key{Code: KeyEnter})
+	assert.Nil(t, e.err)
+	sendKeys(e, typeText("s")...)
+	assert.Nil(t, e.err)
+
+	_, err := track.LoadRecord(util.DateTime(2001, 2, 3, 11, 0, 0))
+	assert.NotNil(t, err, "Expected the original record to be removed")
+	record, err := track.LoadRecord(util.DateTime(2001, 2, 3, 10, 30, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, "other", record.Project)
+}
+
+func TestEditorMoveStartRollback(t *testing.T) {
+	track, e, clean := setupEditor(t)
+	defer clean()
+
+	// Moving the original record to the trash fails
+	track.Config.TrashDays = 30
+	assert.Nil(t, os.WriteFile(track.TrashDir(), []byte{}, 0600), "Error blocking trash")
+
+	sendKeys(e, Key{Code: KeyDown}, Key{Code: KeyEnter}, Key{Code: KeyEnter})
+	sendKeys(e, clearInput(e)...)
+	sendKeys(e, typeText("10:30 - 12:00")...)
+	sendKeys(e, Key{Code: KeyEnter})
+	sendKeys(e, typeText("s")...)
+	assert.NotNil(t, e.err, "Expected error for failing to delete the original record")
+
+	_, err := track.LoadRecord(util.DateTime(2001, 2, 3, 11, 0, 0))
+	assert.Nil(t, err, "Expected the original record to be kept")
+	_, err = track.LoadRecord(util.DateTime(2001, 2, 3, 10, 30, 0))
+	assert.NotNil(t, err, "Expected the moved record not to be saved")
+}
+
+func TestEditorDryRun(t *testing.T) {
+	track, e, clean := setupEditor(t)
+	defer clean()
+	e.DryRun = true
+
+	sendKeys(e, Key{Code: KeyEnter}, Key{Code: KeyDown}, Key{Code: KeyEnter})
+	sendKeys(e, clearInput(e)...)
+	sendKeys(e, typeText("other")...)
+	sendKeys(e, Key{Code: KeyEnter})
+	sendKeys(e, typeText("s")...)
+	assert.Contains(t, e.View(), "dry-run")
+
+	record, err := track.LoadRecord(util.DateTime(2001, 2, 3, 8, 0, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, "test", record.Project)
+}
+
+func TestParseKeys(t *testing.T) {
+	keys := ParseKeys([]byte("a\x1b[A\x1b[Bä\r\x7f\x1b\x03"))
+	assert.Equal(t, []Key{
+		{Code: KeyRune, Rune: 'a'},
+		{Code: KeyUp},
+		{Code: KeyDown},
+		{Code: KeyRune, Rune: 'ä'},
+		{Code: KeyEnter},
+		{Code: KeyBackspace},
+		{Code: KeyEsc},
+		{Code: KeyCtrlC},
+	}, keys)
+}
+
+func TestRun(t *testing.T) {
+	_, e, clean := setupEditor(t)
+	defer clean()
+
+	out := bytes.Buffer{}
+	assert.Nil(t, run(e, strings.NewReader("jq"), &out))
+	assert.Contains(t, out.String(), "Second")
+	assert.Equal(t, 1, e.selected)
+}
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// KeyCode identifies a key
+type KeyCode uint8
+
+// Key codes
+const (
+	KeyRune KeyCode = iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyCtrlC
+	KeyUnknown
+)
+
+// Key is a key press
+type Key struct {
+	Code KeyCode
+	// The typed character, for KeyRune
+	Rune rune
+}
+
+// Is checks whether the key is the given character
+func (k Key) Is(r rune) bool {
+	return k.Code == KeyRune && k.Rune == r
+}
+
+// ParseKeys decodes the keys from bytes read from a terminal in raw mode
+func ParseKeys(b []byte) []Key {
+	keys := []Key{}
+	for len(b) > 0 {
+		switch {
+		case len(b) >= 3 && b[0] == 0x1b && (b[1] == '[' || b[1] == 'O'):
+			code := KeyUnknown
+			switch b[2] {
+			case 'A':
+				code = KeyUp
+			case 'B':
+				code = KeyDown
+			case 'C':
+				code = KeyRight
+			case 'D':
+				code = KeyLeft
+			}
+			keys = append(keys, Key{Code: code})
+			b = b[3:]
+			continue
+		case b[0] == 0x1b:
+			keys = append(keys, Key{Code: KeyEsc})
+		case b[0] == '\r' || b[0] == '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case b[0] == 0x7f || b[0] == 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+		case b[0] == 0x03:
+			keys = append(keys, Key{Code: KeyCtrlC})
+		case b[0] < 0x20:
+			keys = append(keys, Key{Code: KeyUnknown})
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, Key{Code: KeyRune, Rune: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// Run runs the editor in the terminal, until the user quits
+func Run(e *Editor) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("the interactive editor requires a terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = term.Restore(fd, state)
+	}()
+
+	// Use the alternate screen buffer, and hide the cursor
+	fmt.Fprint(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(os.Stdout, "\x1b[?25h\x1b[?1049l")
+
+	return run(e, os.Stdin, os.Stdout)
+}
+
+func run(e *Editor, in io.Reader, out io.Writer) error {
+	buf := make([]byte, 64)
+	for {
+		// In raw mode, line breaks need a carriage return
+		view := strings.ReplaceAll(e.View(), "\n", "\r\n")
+		fmt.Fprint(out, "\x1b[H\x1b[2J"+view)
+
+		n, err := in.Read(buf)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		for _, k := range ParseKeys(buf[:n]) {
+			if e.HandleKey(k) {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package util
+
+import (
+	"strings"
+)
+
+// HorizontalBlockRunes are utf8 8th blocks for horizontal bars, from 1/8 to full
+var HorizontalBlockRunes = [8]rune{'▏', '▎', '▍', '▌', '▋', '▊', '▉', '█'}
+
+// Bar renders a horizontal bar for a value relative to a maximum value, with a resolution of 8th blocks.
+// A bar for the maximum value has the given width.
+func Bar(value, max float64, width int) string {
+	if max <= 0 || value <= 0 || width <= 0 {
+		return ""
+	}
+	if value > max {
+		value = max
+	}
+	eighths := int(value / max * float64(width*8))
+	bar := strings.Repeat(string(HorizontalBlockRunes[7]), eighths/8)
+	if rest := eighths % 8; rest > 0 {
+		bar += string(HorizontalBlockRunes[rest-1])
+	}
+	return bar
+}
+
+// Sparkline renders values as a line of vertical 8th blocks, scaled to the maximum value
+func Sparkline(values []float64) string {
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	runes := make([]rune, len(values))
+	for i, v := range values {
+		if max <= 0 {
+			runes[i] = BlockRunes[0]
+			continue
+		}
+		runes[i] = FloatToBlock(v/max, nil)
+	}
+	return string(runes)
+}
@@ -0,0 +1,22 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBar(t *testing.T) {
+	assert.Equal(t, "████", Bar(10, 10, 4))
+	assert.Equal(t, "██▌", Bar(6.25, 10, 4))
+	assert.Equal(t, "▏", Bar(0.4, 10, 4))
+	assert.Equal(t, "████", Bar(20, 10, 4))
+	assert.Equal(t, "", Bar(0, 10, 4))
+	assert.Equal(t, "", Bar(5, 0, 4))
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "·▄█", Sparkline([]float64{0, 5, 10}))
+	assert.Equal(t, "··", Sparkline([]float64{0, 0}))
+	assert.Equal(t, "", Sparkline([]float64{}))
+}
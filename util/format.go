@@ -2,6 +2,7 @@ package util
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 	"time"
@@ -59,23 +60,28 @@ func FormatDuration(d time.Duration, zeroPadHours ...bool) string {
 	return fmt.Sprintf(durationFormatTemplatePad, int(d.Hours()), int(d.Minutes())%60)
 }
 
-// FormatTimeWithOffset formats a time with day offset indicators
+// FormatTimeWithOffset formats a time with day offset indicators.
+// Indicators are repeated for an offset of more than one day.
 func FormatTimeWithOffset(t time.Time, reference time.Time) string {
 	if t.IsZero() {
 		return "?"
 	}
 	timeStr := t.Format(TimeFormat)
-	if ToDate(t).After(reference) {
-		timeStr = timeStr + NextDaySuffix
-		return timeStr
+	days := DaysBetween(ToDate(reference), ToDate(t))
+	if days > 0 {
+		return timeStr + strings.Repeat(NextDaySuffix, days)
 	}
-	if ToDate(reference).After(t) {
-		timeStr = PrevDayPrefix + timeStr
-		return timeStr
+	if days < 0 {
+		return strings.Repeat(PrevDayPrefix, -days) + timeStr
 	}
 	return timeStr
 }
 
+// DaysBetween returns the number of calendar days between two dates
+func DaysBetween(from, to time.Time) int {
+	return int(math.Round(to.Sub(from).Hours() / 24))
+}
+
 // Format formats a string with named placeholders.
 //
 // Example:
@@ -71,6 +71,18 @@ func TestFormatTimeWithOffset(t *testing.T) {
 			ref:      Date(2001, 2, 2),
 			expected: "04:05>",
 		},
+		{
+			title:    "two days later",
+			time:     DateTime(2001, 2, 3, 4, 5, 6),
+			ref:      Date(2001, 2, 1),
+			expected: "04:05>>",
+		},
+		{
+			title:    "two days earlier",
+			time:     DateTime(2001, 2, 3, 4, 5, 6),
+			ref:      Date(2001, 2, 5),
+			expected: "<<04:05",
+		},
 	}
 
 	for _, test := range tt {
@@ -0,0 +1,108 @@
+package util
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// DurationStyle determines how durations are formatted
+type DurationStyle string
+
+const (
+	// DurationClock formats durations as hours and minutes, like 07:15
+	DurationClock DurationStyle = "clock"
+	// DurationDecimal formats durations as decimal hours, like 7.25
+	DurationDecimal DurationStyle = "decimal"
+	// DurationIndustrial formats durations as hours and industrial minutes (hundredths of an hour), like 7:25
+	DurationIndustrial DurationStyle = "industrial"
+)
+
+// DateFormats are the named date format presets
+var DateFormats = map[string]string{
+	"iso": DateFormat,
+	"us":  "01/02/2006",
+	"uk":  "02/01/2006",
+	"eu":  "02.01.2006",
+}
+
+// ClockFormats are the time formats for 24h and 12h clocks
+var ClockFormats = map[string]string{
+	"24h": TimeFormat,
+	"12h": "3:04PM",
+}
+
+// Formatter formats dates, times and durations for display in reports and exports
+type Formatter struct {
+	DateLayout string
+	TimeLayout string
+	Durations  DurationStyle
+}
+
+// DefaultFormatter uses ISO dates, a 24h clock and durations in hours and minutes
+var DefaultFormatter = Formatter{
+	DateLayout: DateFormat,
+	TimeLayout: TimeFormat,
+	Durations:  DurationClock,
+}
+
+// NewFormatter creates a Formatter.
+//
+// Argument date is a preset from DateFormats, or a Go date layout.
+// Argument clock is one of "24h" and "12h".
+// Argument duration is one of the DurationStyle values.
+func NewFormatter(date, clock, duration string) (Formatter, error) {
+	dateLayout, ok := DateFormats[date]
+	if !ok {
+		if !strings.Contains(date, "2006") && !strings.Contains(date, "06") {
+			return Formatter{}, fmt.Errorf("invalid date format '%s'", date)
+		}
+		dateLayout = date
+	}
+	timeLayout, ok := ClockFormats[clock]
+	if !ok {
+		return Formatter{}, fmt.Errorf("invalid clock '%s'", clock)
+	}
+	style := DurationStyle(duration)
+	if style != DurationClock && style != DurationDecimal && style != DurationIndustrial {
+		return Formatter{}, fmt.Errorf("invalid duration format '%s'", duration)
+	}
+	return Formatter{
+		DateLayout: dateLayout,
+		TimeLayout: timeLayout,
+		Durations:  style,
+	}, nil
+}
+
+// Date formats a date
+func (f Formatter) Date(t time.Time) string {
+	return t.Format(f.DateLayout)
+}
+
+// Time formats a time of the day
+func (f Formatter) Time(t time.Time) string {
+	return t.Format(f.TimeLayout)
+}
+
+// DateTime formats a date and time
+func (f Formatter) DateTime(t time.Time) string {
+	return t.Format(f.DateLayout + " " + f.TimeLayout)
+}
+
+// Duration formats a duration.
+// Hours are zero-padded to two digits for clock-like styles, unless zeroPadHours is false.
+func (f Formatter) Duration(d time.Duration, zeroPadHours ...bool) string {
+	switch f.Durations {
+	case DurationDecimal:
+		return fmt.Sprintf("%.2f", d.Hours())
+	case DurationIndustrial:
+		hundredths := int(math.Round(d.Hours() * 100))
+		if len(zeroPadHours) > 0 && !zeroPadHours[0] {
+			return fmt.Sprintf(durationFormatTemplate, hundredths/100, hundredths%100)
+		}
+		return fmt.Sprintf(durationFormatTemplatePad, hundredths/100, hundredths%100)
+	default:
+		return FormatDuration(d, zeroPadHours...)
+	}
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFormatter(t *testing.T) {
+	f, err := NewFormatter("iso", "24h", "clock")
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultFormatter, f)
+
+	f, err = NewFormatter("02 Jan 2006", "12h", "decimal")
+	assert.Nil(t, err)
+	assert.Equal(t, "02 Jan 2006", f.DateLayout)
+
+	_, err = NewFormatter("foo", "24h", "clock")
+	assert.NotNil(t, err)
+	_, err = NewFormatter("iso", "6h", "clock")
+	assert.NotNil(t, err)
+	_, err = NewFormatter("iso", "24h", "minutes")
+	assert.NotNil(t, err)
+}
+
+func TestFormatter(t *testing.T) {
+	tm := DateTime(2001, 2, 3, 16, 5, 0)
+	dur := 7*time.Hour + 15*time.Minute
+
+	f, err := NewFormatter("us", "12h", "decimal")
+	assert.Nil(t, err)
+	assert.Equal(t, "02/03/2001", f.Date(tm))
+	assert.Equal(t, "4:05PM", f.Time(tm))
+	assert.Equal(t, "02/03/2001 4:05PM", f.DateTime(tm))
+	assert.Equal(t, "7.25", f.Duration(dur))
+
+	f, err = NewFormatter("eu", "24h", "industrial")
+	assert.Nil(t, err)
+	assert.Equal(t, "03.02.2001 16:05", f.DateTime(tm))
+	assert.Equal(t, "07:25", f.Duration(dur))
+	assert.Equal(t, "7:25", f.Duration(dur, false))
+
+	assert.Equal(t, "07:15", DefaultFormatter.Duration(dur))
+	assert.Equal(t, "7:15", DefaultFormatter.Duration(dur, false))
+}
@@ -30,3 +30,50 @@ func Unique[T comparable](slice []T) []T {
 	}
 	return unique
 }
+
+// IsSubsequence checks if all runes of sub appear in str, in the same order
+func IsSubsequence(sub, str string) bool {
+	runes := []rune(sub)
+	if len(runes) == 0 {
+		return true
+	}
+	i := 0
+	for _, r := range str {
+		if r == runes[i] {
+			i++
+			if i == len(runes) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Levenshtein calculates the edit distance between two strings
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -26,3 +26,19 @@ func TestUnique(t *testing.T) {
 	uniqueStr := Unique(arrStr)
 	assert.Equal(t, []string{"1", "2", "3", "4"}, uniqueStr, "Wrong unique string values")
 }
+
+func TestIsSubsequence(t *testing.T) {
+	assert.True(t, IsSubsequence("", "abc"))
+	assert.True(t, IsSubsequence("wbst", "website"))
+	assert.True(t, IsSubsequence("website", "website"))
+	assert.False(t, IsSubsequence("wtb", "website"))
+	assert.False(t, IsSubsequence("websites", "website"))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, Levenshtein("abc", "abc"))
+	assert.Equal(t, 3, Levenshtein("", "abc"))
+	assert.Equal(t, 2, Levenshtein("wbesite", "website"))
+	assert.Equal(t, 1, Levenshtein("websit", "website"))
+	assert.Equal(t, 3, Levenshtein("kitten", "sitting"))
+}
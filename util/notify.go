@@ -0,0 +1,32 @@
+package util
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Notify shows a desktop notification, using the notification tool of the platform.
+//
+// Uses notify-send on Linux and BSD, osascript on macOS and PowerShell on Windows.
+func Notify(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		script := fmt.Sprintf(
+			`[reflection.assembly]::loadwithpartialname('System.Windows.Forms') | Out-Null; `+
+				`$n = New-Object System.Windows.Forms.NotifyIcon; `+
+				`$n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true; `+
+				`$n.ShowBalloonTip(10000, '%s', '%s', 'Info')`,
+			strings.ReplaceAll(title, "'", "''"), strings.ReplaceAll(message, "'", "''"),
+		)
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+	default:
+		cmd = exec.Command("notify-send", title, message)
+	}
+	return cmd.Run()
+}
@@ -81,11 +81,11 @@ func ParseTimeWithOffset(text string, date time.Time) (time.Time, error) {
 	dayOffset := 0
 	start := 0
 	end := len(text)
-	if strings.HasPrefix(text, PrevDayPrefix) {
+	for strings.HasPrefix(text[start:end], PrevDayPrefix) {
 		start += len(PrevDayPrefix)
 		dayOffset--
 	}
-	if strings.HasSuffix(text, NextDaySuffix) {
+	for strings.HasSuffix(text[start:end], NextDaySuffix) {
 		end -= len(NextDaySuffix)
 		dayOffset++
 	}
@@ -95,7 +95,7 @@ func ParseTimeWithOffset(text string, date time.Time) (time.Time, error) {
 	}
 	t = DateAndTime(date, t)
 	if dayOffset != 0 {
-		t = t.Add(time.Duration(int64(dayOffset*24) * int64(time.Hour)))
+		t = t.AddDate(0, 0, dayOffset)
 	}
 	return t, nil
 }
@@ -59,6 +59,13 @@ func TestParseTimeRange(t *testing.T) {
 			expEnd:   date.Add(time.Duration(time.Minute * (2*60 + 30))),
 			expErr:   false,
 		},
+		{
+			title:    "End time two days later",
+			text:     "12:30 - 0:30>>",
+			expStart: date.Add(time.Duration(time.Minute * (12*60 + 30))),
+			expEnd:   date.Add(time.Duration(time.Minute * (48*60 + 30))),
+			expErr:   false,
+		},
 		{
 			title:    "End time by duration",
 			text:     "12:30 - 45m",
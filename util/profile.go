@@ -0,0 +1,47 @@
+package util
+
+import (
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// StartProfiling starts CPU profiling to cpuPath, and prepares a heap profile to memPath.
+// Empty paths disable the respective profile.
+//
+// Returns a function that stops profiling and writes the heap profile.
+func StartProfiling(cpuPath, memPath string) (func() error, error) {
+	var cpuFile *os.File
+	if cpuPath != "" {
+		var err error
+		cpuFile, err = os.Create(cpuPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := pprof.StartCPUProfile(cpuFile); err != nil {
+			cpuFile.Close()
+			return nil, err
+		}
+	}
+
+	return func() error {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				return err
+			}
+		}
+		if memPath != "" {
+			memFile, err := os.Create(memPath)
+			if err != nil {
+				return err
+			}
+			defer memFile.Close()
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(memFile); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,30 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartProfiling(t *testing.T) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	defer os.RemoveAll(dir)
+
+	cpuPath := filepath.Join(dir, "cpu.pprof")
+	memPath := filepath.Join(dir, "mem.pprof")
+
+	stop, err := StartProfiling(cpuPath, memPath)
+	assert.Nil(t, err, "Error starting profiling")
+	err = stop()
+	assert.Nil(t, err, "Error stopping profiling")
+
+	assert.True(t, FileExists(cpuPath), "CPU profile not written")
+	assert.True(t, FileExists(memPath), "Heap profile not written")
+
+	stop, err = StartProfiling("", "")
+	assert.Nil(t, err, "Error starting profiling")
+	assert.Nil(t, stop(), "Error stopping profiling")
+}
@@ -1,6 +1,10 @@
 package util
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // NoTime is a zero time
 var NoTime time.Time = time.Time{}
@@ -32,6 +36,42 @@ func DurationClip(start, end, min, max time.Time) time.Duration {
 
 // Monday returns the monday of the week of the given date
 func Monday(date time.Time) time.Time {
-	weekDay := (int(date.Weekday()) + 6) % 7
-	return date.Add(time.Duration(-weekDay * 24 * int(time.Hour)))
+	return WeekStart(date, time.Monday)
+}
+
+// WeekStart returns the first day of the week of the given date,
+// for weeks starting with the given weekday.
+func WeekStart(date time.Time, first time.Weekday) time.Time {
+	weekDay := (int(date.Weekday()) - int(first) + 7) % 7
+	return date.AddDate(0, 0, -weekDay)
+}
+
+// ParseWeekday parses the name of a weekday, like "monday", "Mon" or "mo". Case-insensitive.
+func ParseWeekday(name string) (time.Weekday, error) {
+	lower := strings.ToLower(name)
+	if len(lower) >= 2 {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.HasPrefix(strings.ToLower(day.String()), lower) {
+				return day, nil
+			}
+		}
+	}
+	return time.Sunday, fmt.Errorf("invalid weekday '%s'", name)
+}
+
+// QuarterStart returns the first day of the quarter of the given date.
+// Quarters are aligned to the given first month of the (fiscal) year.
+func QuarterStart(date time.Time, firstMonth time.Month) time.Time {
+	offset := (int(date.Month()) - int(firstMonth) + 12) % 12
+	return Date(date.Year(), date.Month()-time.Month(offset%3), 1)
+}
+
+// FiscalYearStart returns the first day of the fiscal year of the given date,
+// for a fiscal year starting with the given month.
+func FiscalYearStart(date time.Time, firstMonth time.Month) time.Time {
+	year := date.Year()
+	if date.Month() < firstMonth {
+		year--
+	}
+	return Date(year, firstMonth, 1)
 }
@@ -103,3 +103,46 @@ func TestMonday(t *testing.T) {
 		assert.Equal(t, time.Monday, monday.Weekday(), "Weekday should be monday")
 	}
 }
+
+func TestQuarterStart(t *testing.T) {
+	assert.Equal(t, Date(2001, 1, 1), QuarterStart(Date(2001, 3, 31), time.January))
+	assert.Equal(t, Date(2001, 4, 1), QuarterStart(Date(2001, 4, 1), time.January))
+	assert.Equal(t, Date(2001, 10, 1), QuarterStart(Date(2001, 12, 24), time.January))
+
+	assert.Equal(t, Date(2001, 1, 1), QuarterStart(Date(2001, 2, 3), time.April))
+	assert.Equal(t, Date(2000, 12, 1), QuarterStart(Date(2001, 2, 3), time.June))
+	assert.Equal(t, Date(2001, 3, 1), QuarterStart(Date(2001, 5, 3), time.June))
+	assert.Equal(t, Date(2001, 6, 1), QuarterStart(Date(2001, 6, 3), time.June))
+}
+
+func TestFiscalYearStart(t *testing.T) {
+	assert.Equal(t, Date(2001, 1, 1), FiscalYearStart(Date(2001, 12, 31), time.January))
+	assert.Equal(t, Date(2000, 4, 1), FiscalYearStart(Date(2001, 3, 31), time.April))
+	assert.Equal(t, Date(2001, 4, 1), FiscalYearStart(Date(2001, 4, 1), time.April))
+}
+
+func TestWeekStart(t *testing.T) {
+	date := Date(2001, 2, 7)
+	assert.Equal(t, time.Wednesday, date.Weekday())
+
+	assert.Equal(t, Date(2001, 2, 5), WeekStart(date, time.Monday))
+	assert.Equal(t, Date(2001, 2, 4), WeekStart(date, time.Sunday))
+	assert.Equal(t, Date(2001, 2, 7), WeekStart(date, time.Wednesday))
+	assert.Equal(t, Date(2001, 2, 1), WeekStart(date, time.Thursday))
+}
+
+func TestParseWeekday(t *testing.T) {
+	for _, name := range []string{"sunday", "Sunday", "SUN", "su"} {
+		day, err := ParseWeekday(name)
+		assert.Nil(t, err)
+		assert.Equal(t, time.Sunday, day)
+	}
+	day, err := ParseWeekday("mon")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Monday, day)
+
+	_, err = ParseWeekday("s")
+	assert.NotNil(t, err)
+	_, err = ParseWeekday("mondays")
+	assert.NotNil(t, err)
+}
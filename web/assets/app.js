@@ -0,0 +1,127 @@
+"use strict";
+
+const api = "api/v1/";
+
+function token() {
+    return localStorage.getItem("track-token") || "";
+}
+
+async function request(method, endpoint, body) {
+    const options = { method: method, headers: {} };
+    if (token()) {
+        options.headers["Authorization"] = "Bearer " + token();
+    }
+    if (method === "POST") {
+        options.headers["Content-Type"] = "application/json";
+        options.body = JSON.stringify(body === undefined ? {} : body);
+    }
+    const response = await fetch(api + endpoint, options);
+    if (!response.ok) {
+        throw new Error((await response.text()).trim() || response.statusText);
+    }
+    return response.json();
+}
+
+function showError(err) {
+    const element = document.getElementById("error");
+    element.textContent = err ? err.message : "";
+    element.hidden = !err;
+}
+
+function formatDuration(seconds) {
+    const hours = Math.floor(seconds / 3600);
+    const minutes = Math.floor((seconds % 3600) / 60);
+    return hours + ":" + String(minutes).padStart(2, "0");
+}
+
+function formatDate(date) {
+    const offset = date.getTimezoneOffset() * 60000;
+    return new Date(date.getTime() - offset).toISOString().slice(0, 10);
+}
+
+async function loadStatus() {
+    const status = await request("GET", "status");
+    const text = document.getElementById("status-text");
+    const stop = document.getElementById("stop");
+    if (status.active) {
+        const start = new Date(status.record.start).toLocaleTimeString([], { hour: "2-digit", minute: "2-digit" });
+        text.textContent = "Running in '" + status.record.project + "' since " + start +
+            " (" + formatDuration(status.elapsed) + ")" + (status.paused ? ", paused" : "");
+        stop.hidden = false;
+    } else {
+        text.textContent = "No running record";
+        stop.hidden = true;
+    }
+}
+
+async function loadProjects() {
+    const projects = await request("GET", "projects");
+    const select = document.getElementById("project");
+    select.replaceChildren(...projects.map(name => new Option(name, name)));
+}
+
+async function loadReport() {
+    const start = document.getElementById("report-start").value;
+    const end = document.getElementById("report-end").value;
+    const report = await request("GET", "report?start=" + start + "&end=" + end);
+    const rows = Object.entries(report.projects).sort((a, b) => a[0].localeCompare(b[0])).map(([name, seconds]) => {
+        const row = document.createElement("tr");
+        const nameCell = document.createElement("td");
+        const timeCell = document.createElement("td");
+        nameCell.textContent = name;
+        timeCell.textContent = formatDuration(seconds);
+        row.append(nameCell, timeCell);
+        return row;
+    });
+    document.getElementById("report-rows").replaceChildren(...rows);
+    document.getElementById("report-total").textContent = formatDuration(report.total);
+}
+
+async function refresh() {
+    try {
+        await Promise.all([loadStatus(), loadProjects(), loadReport()]);
+        showError(null);
+    } catch (err) {
+        showError(err);
+    }
+}
+
+async function run(action) {
+    try {
+        await action();
+        await refresh();
+    } catch (err) {
+        showError(err);
+    }
+}
+
+document.getElementById("token").value = token();
+document.getElementById("token-form").addEventListener("submit", event => {
+    event.preventDefault();
+    localStorage.setItem("track-token", document.getElementById("token").value);
+    refresh();
+});
+
+document.getElementById("start-form").addEventListener("submit", event => {
+    event.preventDefault();
+    run(() => request("POST", "start", {
+        project: document.getElementById("project").value,
+        note: document.getElementById("note").value,
+    }));
+});
+
+document.getElementById("stop").addEventListener("click", () => run(() => request("POST", "stop")));
+
+document.getElementById("report-form").addEventListener("submit", event => {
+    event.preventDefault();
+    run(loadReport);
+});
+
+const today = new Date();
+const monday = new Date(today);
+monday.setDate(today.getDate() - (today.getDay() + 6) % 7);
+document.getElementById("report-start").value = formatDate(monday);
+document.getElementById("report-end").value = formatDate(today);
+
+refresh();
+setInterval(loadStatus, 60000);
@@ -0,0 +1,616 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This file implements the subset of GraphQL needed for read-only queries:
+// operations of type query, with variables, arguments, aliases and nested selections.
+// Fragments, directives and mutations are not supported.
+// The size, number of fields and nesting depth of query documents are limited.
+
+// Limits of query documents, against queries that are expensive to parse or execute
+const (
+	// maxGraphQLSize is the maximum length of a query document, in characters
+	maxGraphQLSize = 16 * 1024
+	// maxGraphQLDepth is the maximum nesting depth of selection sets, list values and list types
+	maxGraphQLDepth = 12
+	// maxGraphQLFields is the maximum number of fields in a query document
+	maxGraphQLFields = 500
+)
+
+// GraphQLRequest is a GraphQL request
+type GraphQLRequest struct {
+	// Query document
+	Query string `json:"query"`
+	// Values of the query's variables
+	Variables map[string]interface{} `json:"variables,omitempty"`
+	// Name of the operation to execute, if the document contains multiple operations
+	OperationName string `json:"operationName,omitempty"`
+}
+
+// GraphQLResponse is the response to a GraphQLRequest
+type GraphQLResponse struct {
+	Data   *gqlObject     `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// GraphQLError is an error in a GraphQLResponse
+type GraphQLError struct {
+	Message string `json:"message"`
+}
+
+// gqlResolver resolves the fields of an object type of the schema
+type gqlResolver interface {
+	// typeName returns the name of the object's type
+	typeName() string
+	// resolve returns the value of a field, which is a scalar, a gqlResolver, or a slice of these
+	resolve(field string, args map[string]interface{}) (interface{}, error)
+}
+
+// gqlField is a field in a selection set
+type gqlField struct {
+	alias      string
+	name       string
+	args       map[string]gqlValue
+	selections []gqlField
+}
+
+// gqlValue is a literal value or a variable in a query
+type gqlValue struct {
+	variable string
+	value    interface{}
+}
+
+// gqlOperation is an operation of a query document
+type gqlOperation struct {
+	name       string
+	defaults   map[string]interface{}
+	selections []gqlField
+}
+
+// gqlObject is a result object that keeps the order of its fields
+type gqlObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newGQLObject() *gqlObject {
+	return &gqlObject{values: map[string]interface{}{}}
+}
+
+func (o *gqlObject) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// MarshalJSON encodes the object with its fields in query order
+func (o *gqlObject) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// executeGraphQL parses and executes a request against the root resolver
+func executeGraphQL(req *GraphQLRequest, root gqlResolver) GraphQLResponse {
+	operations, err := parseGraphQL(req.Query)
+	if err != nil {
+		return GraphQLResponse{Errors: []GraphQLError{{Message: err.Error()}}}
+	}
+	var op *gqlOperation
+	for i := range operations {
+		if req.OperationName == "" || operations[i].name == req.OperationName {
+			if op != nil {
+				return GraphQLResponse{Errors: []GraphQLError{{Message: "operationName is required for documents with multiple operations"}}}
+			}
+			op = &operations[i]
+		}
+	}
+	if op == nil {
+		return GraphQLResponse{Errors: []GraphQLError{{Message: fmt.Sprintf("unknown operation '%s'", req.OperationName)}}}
+	}
+
+	variables := map[string]interface{}{}
+	for name, value := range op.defaults {
+		variables[name] = value
+	}
+	for name, value := range req.Variables {
+		variables[name] = value
+	}
+	data, err := executeSelections(op.selections, root, variables)
+	if err != nil {
+		return GraphQLResponse{Errors: []GraphQLError{{Message: err.Error()}}}
+	}
+	return GraphQLResponse{Data: data}
+}
+
+// executeSelections resolves the selected fields of an object
+func executeSelections(selections []gqlField, obj gqlResolver, variables map[string]interface{}) (*gqlObject, error) {
+	result := newGQLObject()
+	for _, field := range selections {
+		key := field.alias
+		if key == "" {
+			key = field.name
+		}
+		if field.name == "__typename" {
+			result.set(key, obj.typeName())
+			continue
+		}
+		args := map[string]interface{}{}
+		for name, arg := range field.args {
+			if arg.variable == "" {
+				args[name] = arg.value
+				continue
+			}
+			value, ok := variables[arg.variable]
+			if !ok {
+				return nil, fmt.Errorf("variable '$%s' is not defined", arg.variable)
+			}
+			args[name] = value
+		}
+		value, err := obj.resolve(field.name, args)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %s", obj.typeName(), field.name, err)
+		}
+		value, err = completeValue(&field, value, variables)
+		if err != nil {
+			return nil, err
+		}
+		result.set(key, value)
+	}
+	return result, nil
+}
+
+// completeValue resolves the sub-selections of object values, and checks that scalars have no sub-selections
+func completeValue(field *gqlField, value interface{}, variables map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case gqlResolver:
+		if len(field.selections) == 0 {
+			return nil, fmt.Errorf("field '%s' of type %s must have a selection of sub-fields", field.name, v.typeName())
+		}
+		return executeSelections(field.selections, v, variables)
+	case []gqlResolver:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			obj, err := completeValue(field, item, variables)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = obj
+		}
+		return list, nil
+	default:
+		if len(field.selections) > 0 {
+			return nil, fmt.Errorf("field '%s' is a scalar and can't have a selection of sub-fields", field.name)
+		}
+		return v, nil
+	}
+}
+
+// gqlParser parses GraphQL query documents
+type gqlParser struct {
+	src    []rune
+	pos    int
+	depth  int
+	fields int
+}
+
+// parseGraphQL parses a query document into its operations
+func parseGraphQL(query string) ([]gqlOperation, error) {
+	if len(query) > maxGraphQLSize {
+		return nil, fmt.Errorf("query exceeds the maximum size of %d characters", maxGraphQLSize)
+	}
+	p := gqlParser{src: []rune(query)}
+	operations := []gqlOperation{}
+	for {
+		p.skipIgnored()
+		if p.pos >= len(p.src) {
+			break
+		}
+		op, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, op)
+	}
+	if len(operations) == 0 {
+		return nil, fmt.Errorf("syntax error: empty query")
+	}
+	return operations, nil
+}
+
+func (p *gqlParser) parseOperation() (gqlOperation, error) {
+	op := gqlOperation{defaults: map[string]interface{}{}}
+	if p.peek() != '{' {
+		kind, err := p.parseName()
+		if err != nil {
+			return op, err
+		}
+		if kind == "fragment" {
+			return op, p.errorf("fragments are not supported")
+		}
+		if kind != "query" {
+			return op, p.errorf("only query operations are supported, got '%s'", kind)
+		}
+		if p.peekName() {
+			if op.name, err = p.parseName(); err != nil {
+				return op, err
+			}
+		}
+		if p.peek() == '(' {
+			if err := p.parseVariableDefinitions(op.defaults); err != nil {
+				return op, err
+			}
+		}
+		if p.peek() == '@' {
+			return op, p.errorf("directives are not supported")
+		}
+	}
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return op, err
+	}
+	op.selections = selections
+	return op, nil
+}
+
+// parseVariableDefinitions parses variable definitions, and stores their default values
+func (p *gqlParser) parseVariableDefinitions(defaults map[string]interface{}) error {
+	p.pos++ // (
+	for {
+		if p.peek() == ')' {
+			p.pos++
+			return nil
+		}
+		if err := p.expect('$'); err != nil {
+			return err
+		}
+		name, err := p.parseName()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(':'); err != nil {
+			return err
+		}
+		if err := p.parseType(); err != nil {
+			return err
+		}
+		if p.peek() == '=' {
+			p.pos++
+			value, err := p.parseValue()
+			if err != nil {
+				return err
+			}
+			if value.variable != "" {
+				return p.errorf("default value of '$%s' must not be a variable", name)
+			}
+			defaults[name] = value.value
+		}
+	}
+}
+
+// parseType parses and discards a variable type, like [String!]!
+func (p *gqlParser) parseType() error {
+	if p.peek() == '[' {
+		if err := p.enter(); err != nil {
+			return err
+		}
+		defer p.leave()
+		p.pos++
+		if err := p.parseType(); err != nil {
+			return err
+		}
+		if err := p.expect(']'); err != nil {
+			return err
+		}
+	} else if _, err := p.parseName(); err != nil {
+		return err
+	}
+	if p.peek() == '!' {
+		p.pos++
+	}
+	return nil
+}
+
+func (p *gqlParser) parseSelectionSet() ([]gqlField, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	fields := []gqlField{}
+	for {
+		switch p.peek() {
+		case '}':
+			p.pos++
+			if len(fields) == 0 {
+				return nil, p.errorf("empty selection set")
+			}
+			return fields, nil
+		case '.':
+			return nil, p.errorf("fragments are not supported")
+		}
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+}
+
+func (p *gqlParser) parseField() (gqlField, error) {
+	field := gqlField{}
+	p.fields++
+	if p.fields > maxGraphQLFields {
+		return field, p.errorf("query exceeds the maximum number of %d fields", maxGraphQLFields)
+	}
+	name, err := p.parseName()
+	if err != nil {
+		return field, err
+	}
+	if p.peek() == ':' {
+		p.pos++
+		field.alias = name
+		if name, err = p.parseName(); err != nil {
+			return field, err
+		}
+	}
+	field.name = name
+	if p.peek() == '(' {
+		p.pos++
+		field.args = map[string]gqlValue{}
+		for p.peek() != ')' {
+			arg, err := p.parseName()
+			if err != nil {
+				return field, err
+			}
+			if err := p.expect(':'); err != nil {
+				return field, err
+			}
+			if field.args[arg], err = p.parseValue(); err != nil {
+				return field, err
+			}
+		}
+		p.pos++
+	}
+	if p.peek() == '@' {
+		return field, p.errorf("directives are not supported")
+	}
+	if p.peek() == '{' {
+		if field.selections, err = p.parseSelectionSet(); err != nil {
+			return field, err
+		}
+	}
+	return field, nil
+}
+
+func (p *gqlParser) parseValue() (gqlValue, error) {
+	switch c := p.peek(); {
+	case c == '$':
+		p.pos++
+		name, err := p.parseName()
+		return gqlValue{variable: name}, err
+	case c == '"':
+		str, err := p.parseString()
+		return gqlValue{value: str}, err
+	case c == '[':
+		if err := p.enter(); err != nil {
+			return gqlValue{}, err
+		}
+		defer p.leave()
+		p.pos++
+		list := []interface{}{}
+		for p.peek() != ']' {
+			item, err := p.parseValue()
+			if err != nil {
+				return gqlValue{}, err
+			}
+			if item.variable != "" {
+				return gqlValue{}, p.errorf("variables in lists are not supported")
+			}
+			list = append(list, item.value)
+		}
+		p.pos++
+		return gqlValue{value: list}, nil
+	case c == '-' || unicode.IsDigit(c):
+		return p.parseNumber()
+	case c == '{':
+		return gqlValue{}, p.errorf("input objects are not supported")
+	default:
+		name, err := p.parseName()
+		if err != nil {
+			return gqlValue{}, err
+		}
+		switch name {
+		case "true":
+			return gqlValue{value: true}, nil
+		case "false":
+			return gqlValue{value: false}, nil
+		case "null":
+			return gqlValue{value: nil}, nil
+		}
+		// Enum values are passed as strings
+		return gqlValue{value: name}, nil
+	}
+}
+
+func (p *gqlParser) parseNumber() (gqlValue, error) {
+	start := p.pos
+	for p.pos < len(p.src) && strings.ContainsRune("-+.eE0123456789", p.src[p.pos]) {
+		p.pos++
+	}
+	text := string(p.src[start:p.pos])
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return gqlValue{value: float64(i)}, nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return gqlValue{}, p.errorf("invalid number '%s'", text)
+	}
+	return gqlValue{value: f}, nil
+}
+
+func (p *gqlParser) parseString() (string, error) {
+	p.pos++ // "
+	sb := strings.Builder{}
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\n':
+			return "", p.errorf("unterminated string")
+		case '\\':
+			if p.pos >= len(p.src) {
+				return "", p.errorf("unterminated string")
+			}
+			esc := p.src[p.pos]
+			p.pos++
+			switch esc {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			case 'b':
+				sb.WriteRune('\b')
+			case 'f':
+				sb.WriteRune('\f')
+			case 'u':
+				if p.pos+4 > len(p.src) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(string(p.src[p.pos:p.pos+4]), 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				sb.WriteRune(rune(code))
+				p.pos += 4
+			default:
+				sb.WriteRune(esc)
+			}
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *gqlParser) parseName() (string, error) {
+	p.skipIgnored()
+	start := p.pos
+	for p.pos < len(p.src) && isNameRune(p.src[p.pos], p.pos == start) {
+		p.pos++
+	}
+	if p.pos == start {
+		if p.pos >= len(p.src) {
+			return "", p.errorf("unexpected end of query")
+		}
+		return "", p.errorf("unexpected character '%c'", p.src[p.pos])
+	}
+	return string(p.src[start:p.pos]), nil
+}
+
+// peekName checks if the next token is a name
+func (p *gqlParser) peekName() bool {
+	c := p.peek()
+	return c != 0 && isNameRune(c, true)
+}
+
+// peek skips ignored characters, and returns the next character without consuming it, or 0 at the end
+func (p *gqlParser) peek() rune {
+	p.skipIgnored()
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// expect consumes the given punctuator, or fails
+func (p *gqlParser) expect(c rune) error {
+	if p.peek() != c {
+		if p.pos >= len(p.src) {
+			return p.errorf("expected '%c', got end of query", c)
+		}
+		return p.errorf("expected '%c', got '%c'", c, p.src[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+// skipIgnored skips whitespace, commas and comments
+func (p *gqlParser) skipIgnored() {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+			continue
+		}
+		if !unicode.IsSpace(c) && c != ',' && c != '\uFEFF' {
+			return
+		}
+		p.pos++
+	}
+}
+
+// enter enters a nested selection set, list value or list type, and fails if the maximum depth is exceeded
+func (p *gqlParser) enter() error {
+	p.depth++
+	if p.depth > maxGraphQLDepth {
+		return p.errorf("query exceeds the maximum nesting depth of %d", maxGraphQLDepth)
+	}
+	return nil
+}
+
+// leave leaves a nested selection set, list value or list type
+func (p *gqlParser) leave() {
+	p.depth--
+}
+
+func (p *gqlParser) errorf(format string, a ...interface{}) error {
+	line, col := 1, 1
+	for _, c := range p.src[:p.pos] {
+		if c == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return fmt.Errorf("syntax error at line %d, column %d: %s", line, col, fmt.Sprintf(format, a...))
+}
+
+func isNameRune(c rune, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
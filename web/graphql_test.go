@@ -0,0 +1,204 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGraphQL(t *testing.T) {
+	ops, err := parseGraphQL(`
+		# Comment
+		query Records($start: String = "2001-02-03", $tags: [String!]) {
+			all: records(start: $start, tags: $tags, categories: ["a", "b\n"]) { project note }
+			report(start: "2001-02-03", projects: [], depth: -1.5) { total }
+		}`)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ops))
+	assert.Equal(t, "Records", ops[0].name)
+	assert.Equal(t, map[string]interface{}{"start": "2001-02-03"}, ops[0].defaults)
+	assert.Equal(t, 2, len(ops[0].selections))
+
+	field := ops[0].selections[0]
+	assert.Equal(t, "all", field.alias)
+	assert.Equal(t, "records", field.name)
+	assert.Equal(t, gqlValue{variable: "tags"}, field.args["tags"])
+	assert.Equal(t, []interface{}{"a", "b\n"}, field.args["categories"].value)
+	assert.Equal(t, 2, len(field.selections))
+	assert.Equal(t, -1.5, ops[0].selections[1].args["depth"].value)
+
+	ops, err = parseGraphQL(`{ status { project } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, "status", ops[0].selections[0].name)
+
+	for _, query := range []string{
+		``,
+		`{ status { project }`,
+		`mutation { stop }`,
+		`{ status { ...fields } }`,
+		`{ records(start: "2001-02-03) { project } }`,
+		`{ status @include(if: true) { project } }`,
+	} {
+		_, err = parseGraphQL(query)
+		assert.NotNil(t, err, "Expected error for query %s", query)
+	}
+}
+
+func TestParseGraphQLLimits(t *testing.T) {
+	nested := func(open, inner, close string, depth int) string {
+		return strings.Repeat(open, depth) + inner + strings.Repeat(close, depth)
+	}
+
+	_, err := parseGraphQL(nested("{ a ", "b", " }", maxGraphQLDepth))
+	assert.Nil(t, err)
+
+	for _, query := range []string{
+		nested("{ a ", "b", " }", maxGraphQLDepth+1),
+		nested("{ a ", "b", " }", 100000),
+		"{ a(x: " + nested("[", "1", "]", maxGraphQLDepth) + ") }",
+		"query($x: " + nested("[", "Int", "]", maxGraphQLDepth+1) + ") { a }",
+		"{ " + strings.Repeat("a ", maxGraphQLFields+1) + "}",
+		"{ a }" + strings.Repeat(" ", maxGraphQLSize),
+	} {
+		_, err = parseGraphQL(query)
+		assert.NotNil(t, err, "Expected error for query of length %d", len(query))
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	assert.Nil(t, track.SaveProject(core.NewProject("parent", "", "p", []string{}, 15, 0), false))
+	assert.Nil(t, track.SaveProject(core.NewProject("child", "parent", "c", []string{}, 15, 0), false))
+	archived := core.NewProject("old", "", "o", []string{}, 15, 0)
+	archived.Archived = true
+	assert.Nil(t, track.SaveProject(archived, false))
+
+	day := time.Date(2001, 2, 3, 0, 0, 0, 0, time.Local)
+	for _, r := range []core.Record{
+		{Project: "parent", Start: day.Add(8 * time.Hour), End: day.Add(10 * time.Hour), Note: "Meeting +meet", Tags: map[string]string{"meet": ""}},
+		{Project: "child", Start: day.Add(10 * time.Hour), End: day.Add(11 * time.Hour), Note: "Coding +dev", Tags: map[string]string{"dev": ""}},
+		{Project: "child", Start: day.AddDate(0, 0, 1).Add(10 * time.Hour), End: day.AddDate(0, 0, 1).Add(11 * time.Hour)},
+	} {
+		record := r
+		assert.Nil(t, track.SaveRecord(&record, false))
+	}
+
+	root := &queryResolver{track: track}
+	execute := func(query string, variables map[string]interface{}) (map[string]interface{}, []GraphQLError) {
+		resp := executeGraphQL(&GraphQLRequest{Query: query, Variables: variables}, root)
+		data, err := json.Marshal(resp)
+		assert.Nil(t, err)
+		result := struct {
+			Data   map[string]interface{}
+			Errors []GraphQLError
+		}{}
+		assert.Nil(t, json.Unmarshal(data, &result))
+		return result.Data, result.Errors
+	}
+
+	data, errs := execute(`query($tags: [String]) {
+		records(start: "2001-02-03", end: "2001-02-03", tags: $tags) { project duration tags { name } }
+		all: records { __typename project }
+	}`, map[string]interface{}{"tags": []interface{}{"dev"}})
+	assert.Nil(t, errs)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"project": "child", "duration": 3600.0, "tags": []interface{}{map[string]interface{}{"name": "dev"}}},
+	}, data["records"])
+	assert.Equal(t, 3, len(data["all"].([]interface{})))
+	assert.Equal(t, "Record", data["all"].([]interface{})[0].(map[string]interface{})["__typename"])
+
+	data, errs = execute(`{
+		projects { name }
+		project(name: "child") { parent records(start: "2001-02-04") { start end } }
+		status { project }
+	}`, nil)
+	assert.Nil(t, errs)
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "child"}, map[string]interface{}{"name": "parent"}}, data["projects"])
+	assert.Equal(t, map[string]interface{}{
+		"parent": "parent",
+		"records": []interface{}{map[string]interface{}{
+			"start": day.AddDate(0, 0, 1).Add(10 * time.Hour).Format(time.RFC3339),
+			"end":   day.AddDate(0, 0, 1).Add(11 * time.Hour).Format(time.RFC3339),
+		}},
+	}, data["project"])
+	assert.Nil(t, data["status"])
+
+	data, errs = execute(`{
+		report(start: "2001-02-03", end: "2001-02-03") {
+			start end total
+			projects { project time totalTime }
+			tags { tag time }
+		}
+	}`, nil)
+	assert.Nil(t, errs)
+	assert.Equal(t, map[string]interface{}{
+		"start": "2001-02-03",
+		"end":   "2001-02-03",
+		"total": 10800.0,
+		"projects": []interface{}{
+			map[string]interface{}{"project": "child", "time": 3600.0, "totalTime": 3600.0},
+			map[string]interface{}{"project": "parent", "time": 7200.0, "totalTime": 10800.0},
+		},
+		"tags": []interface{}{
+			map[string]interface{}{"tag": "dev", "time": 3600.0},
+			map[string]interface{}{"tag": "meet", "time": 7200.0},
+		},
+	}, data["report"])
+
+	for _, query := range []string{
+		`{ records { foo } }`,
+		`{ records }`,
+		`{ projects { name { first } } }`,
+		`{ project(name: "foo") { name } }`,
+		`{ records(start: $start) { project } }`,
+		`{ report(start: "2001-02-03", end: "2001-02-01") { total } }`,
+	} {
+		data, errs = execute(query, nil)
+		assert.Nil(t, data, "Expected no data for query %s", query)
+		assert.Equal(t, 1, len(errs), "Expected error for query %s", query)
+	}
+}
+
+func TestServerGraphQL(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	assert.Nil(t, track.SaveProject(core.NewProject("test", "", "t", []string{}, 15, 0), false))
+
+	server := httptest.NewServer(NewServer(track, Options{Token: "secret"}))
+	defer server.Close()
+
+	result := map[string]interface{}{}
+	resp := request(t, http.MethodPost, server.URL+APIPath+"graphql", "secret", GraphQLRequest{Query: "{ projects { name } }"}, &result)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, map[string]interface{}{
+		"data": map[string]interface{}{"projects": []interface{}{map[string]interface{}{"name": "test"}}},
+	}, result)
+
+	result = map[string]interface{}{}
+	query := url.Values{
+		"query":     []string{"query($name: String) { project(name: $name) { symbol } }"},
+		"variables": []string{`{"name": "test"}`},
+	}
+	resp = request(t, http.MethodGet, server.URL+APIPath+"graphql?"+query.Encode(), "secret", nil, &result)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, map[string]interface{}{
+		"data": map[string]interface{}{"project": map[string]interface{}{"symbol": "t"}},
+	}, result)
+
+	resp = request(t, http.MethodPut, server.URL+APIPath+"graphql", "secret", nil, nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, "GET, POST", resp.Header.Get("Allow"))
+
+	resp = request(t, http.MethodPost, server.URL+APIPath+"graphql", "", GraphQLRequest{Query: "{ status { project } }"}, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
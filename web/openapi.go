@@ -0,0 +1,160 @@
+package web
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// OpenAPIFile is the path of the OpenAPI document below APIPath
+const OpenAPIFile = "openapi.json"
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// OpenAPI generates the OpenAPI 3 document of the API, for generating client SDKs
+func OpenAPI() map[string]interface{} {
+	b := schemaBuilder{components: map[string]interface{}{}}
+	paths := map[string]interface{}{}
+
+	for _, e := range endpoints {
+		op := map[string]interface{}{
+			"operationId": strings.ToLower(e.method) + strings.ToUpper(e.path[:1]) + e.path[1:],
+			"summary":     e.summary,
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "Success",
+					"content":     jsonContent(b.schema(reflect.TypeOf(e.response))),
+				},
+				"default": map[string]interface{}{
+					"description": "Error message",
+					"content": map[string]interface{}{
+						"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+					},
+				},
+			},
+		}
+		if len(e.params) > 0 {
+			params := make([]interface{}, len(e.params))
+			for i, p := range e.params {
+				params[i] = map[string]interface{}{
+					"name":        p.name,
+					"in":          "query",
+					"description": p.description,
+					"schema":      map[string]interface{}{"type": "string"},
+				}
+			}
+			op["parameters"] = params
+		}
+		if e.request != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  jsonContent(b.schema(reflect.TypeOf(e.request))),
+			}
+		}
+
+		path := "/" + e.path
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[path] = item
+		}
+		item[strings.ToLower(e.method)] = op
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Track API",
+			"description": "API of the track web server, for starting and stopping records and for reports",
+			"version":     "1",
+		},
+		"servers":  []interface{}{map[string]interface{}{"url": strings.TrimSuffix(APIPath, "/")}},
+		"security": []interface{}{map[string]interface{}{"bearerAuth": []string{}}},
+		"paths":    paths,
+		"components": map[string]interface{}{
+			"schemas": b.components,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": schema},
+	}
+}
+
+// schemaBuilder derives JSON schemas from Go types. Named structs are collected as components
+type schemaBuilder struct {
+	components map[string]interface{}
+}
+
+func (b *schemaBuilder) schema(tp reflect.Type) map[string]interface{} {
+	if tp == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	if tp.Kind() == reflect.Ptr {
+		return b.schema(tp.Elem())
+	}
+	if tp.Implements(marshalerType) || reflect.PtrTo(tp).Implements(marshalerType) {
+		return map[string]interface{}{"type": "object"}
+	}
+
+	switch tp.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.schema(tp.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(tp.Elem())}
+	case reflect.Struct:
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + tp.Name()}
+		if _, ok := b.components[tp.Name()]; ok {
+			return ref
+		}
+		// Register first, for recursive types
+		b.components[tp.Name()] = nil
+
+		properties := map[string]interface{}{}
+		required := []string{}
+		for i := 0; i < tp.NumField(); i++ {
+			field := tp.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = b.schema(field.Type)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		b.components[tp.Name()] = schema
+		return ref
+	}
+	// Interfaces and other types allow any value
+	return map[string]interface{}{}
+}
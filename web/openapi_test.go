@@ -0,0 +1,52 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPI(t *testing.T) {
+	doc := OpenAPI()
+
+	data, err := json.Marshal(doc)
+	assert.Nil(t, err)
+	parsed := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(data, &parsed))
+
+	paths := parsed["paths"].(map[string]interface{})
+	assert.Equal(t, 6, len(paths))
+	graphql := paths["/graphql"].(map[string]interface{})
+	assert.Contains(t, graphql, "get")
+	assert.Contains(t, graphql, "post")
+
+	schemas := parsed["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	assert.Contains(t, schemas, "StatusResponse")
+	assert.Contains(t, schemas, "Pause")
+
+	record := schemas["Record"].(map[string]interface{})
+	properties := record["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "format": "date-time"}, properties["start"])
+	assert.Equal(t, map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}}, properties["tags"])
+	assert.Contains(t, record["required"], "project")
+	assert.NotContains(t, record["required"], "category")
+}
+
+func TestServerOpenAPI(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	server := httptest.NewServer(NewServer(track, Options{Token: "secret"}))
+	defer server.Close()
+
+	doc := map[string]interface{}{}
+	resp := request(t, http.MethodGet, server.URL+APIPath+OpenAPIFile, "", nil, &doc)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "3.0.3", doc["openapi"])
+
+	resp = request(t, http.MethodPost, server.URL+APIPath+OpenAPIFile, "", nil, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
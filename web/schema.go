@@ -0,0 +1,442 @@
+package web
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"golang.org/x/exp/maps"
+)
+
+// GraphQLSchema describes the types and fields of the GraphQL endpoint.
+// Dates are strings like 2023-01-31, times are in RFC 3339 format, and durations are in seconds.
+const GraphQLSchema = `type Query {
+  # Records between dates start and end (inclusive), optionally filtered
+  records(start: String, end: String, projects: [String], tags: [String], categories: [String]): [Record]
+  # The running record
+  status: Record
+  # All projects, archived ones only if archived is true
+  projects(archived: Boolean): [Project]
+  # A project by name
+  project(name: String!): Project
+  # Aggregated times between dates start and end (inclusive), today by default
+  report(start: String, end: String, projects: [String]): Report
+}
+
+type Record {
+  project: String
+  start: String
+  end: String
+  note: String
+  tags: [Tag]
+  category: String
+  duration: Int
+  pause: Int
+  locked: Boolean
+}
+
+type Tag {
+  name: String
+  value: String
+}
+
+type Project {
+  name: String
+  parent: String
+  symbol: String
+  archived: Boolean
+  category: String
+  requiredTags: [String]
+  records(start: String, end: String): [Record]
+}
+
+type Report {
+  start: String
+  end: String
+  total: Int
+  projects: [ProjectTime]
+  tags: [TagTime]
+  categories: [CategoryTime]
+}
+
+type ProjectTime {
+  project: String
+  time: Int
+  totalTime: Int
+}
+
+type TagTime {
+  tag: String
+  time: Int
+}
+
+type CategoryTime {
+  category: String
+  time: Int
+}
+`
+
+// queryResolver resolves the root Query type
+type queryResolver struct {
+	track *core.Track
+}
+
+func (q *queryResolver) typeName() string { return "Query" }
+
+func (q *queryResolver) resolve(field string, args map[string]interface{}) (interface{}, error) {
+	switch field {
+	case "records":
+		filters := []core.FilterFunction{}
+		projects, err := argStrings(args, "projects")
+		if err != nil {
+			return nil, err
+		}
+		if len(projects) > 0 {
+			filters = append(filters, core.FilterByProjects(projects))
+		}
+		tags, err := argStrings(args, "tags")
+		if err != nil {
+			return nil, err
+		}
+		if len(tags) > 0 {
+			pairs := make([]util.Pair[string, string], len(tags))
+			for i, tag := range tags {
+				k, v := core.ParseTag(tag)
+				pairs[i] = util.NewPair(q.track.Syntax().NormalizeTag(k), v)
+			}
+			filters = append(filters, core.FilterByTagsAny(pairs))
+		}
+		categories, err := argStrings(args, "categories")
+		if err != nil {
+			return nil, err
+		}
+		if len(categories) > 0 {
+			filters = append(filters, core.FilterByCategories(categories))
+		}
+		return q.records(args, filters, false)
+	case "status":
+		record, err := q.track.OpenRecord()
+		if err != nil || record == nil {
+			return nil, err
+		}
+		return &recordResolver{record: *record}, nil
+	case "projects":
+		archived, err := argBool(args, "archived")
+		if err != nil {
+			return nil, err
+		}
+		projects, err := q.track.LoadAllProjects()
+		if err != nil {
+			return nil, err
+		}
+		names := []string{}
+		for name, p := range projects {
+			if archived || !p.Archived {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		result := make([]gqlResolver, len(names))
+		for i, name := range names {
+			result[i] = &projectResolver{query: q, project: projects[name]}
+		}
+		return result, nil
+	case "project":
+		name, err := argString(args, "name")
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			return nil, fmt.Errorf("argument 'name' is required")
+		}
+		project, err := q.track.LoadProject(name)
+		if err != nil {
+			return nil, err
+		}
+		return &projectResolver{query: q, project: project}, nil
+	case "report":
+		start, end, err := argPeriod(args, true)
+		if err != nil {
+			return nil, err
+		}
+		projects, err := argStrings(args, "projects")
+		if err != nil {
+			return nil, err
+		}
+		reporter, err := core.NewStreamingReporter(q.track, projects, core.NewFilter([]core.FilterFunction{}, start, end), false, start, end)
+		if err != nil {
+			return nil, err
+		}
+		return &reportResolver{reporter: reporter, start: start, end: end}, nil
+	}
+	return nil, unknownField(q, field)
+}
+
+// records loads the records between the dates in the arguments, with additional filters
+func (q *queryResolver) records(args map[string]interface{}, filters []core.FilterFunction, includeArchived bool) (interface{}, error) {
+	start, end, err := argPeriod(args, false)
+	if err != nil {
+		return nil, err
+	}
+	ff := core.NewFilter(filters, start, end)
+	ff.IncludeArchived = includeArchived
+	records, err := q.track.LoadAllRecordsFiltered(ff)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]gqlResolver, len(records))
+	for i := range records {
+		result[i] = &recordResolver{record: records[i]}
+	}
+	return result, nil
+}
+
+// recordResolver resolves the Record type
+type recordResolver struct {
+	record core.Record
+}
+
+func (r *recordResolver) typeName() string { return "Record" }
+
+func (r *recordResolver) resolve(field string, args map[string]interface{}) (interface{}, error) {
+	switch field {
+	case "project":
+		return r.record.Project, nil
+	case "start":
+		return r.record.Start.Format(time.RFC3339), nil
+	case "end":
+		if !r.record.HasEnded() {
+			return nil, nil
+		}
+		return r.record.End.Format(time.RFC3339), nil
+	case "note":
+		return r.record.Note, nil
+	case "tags":
+		names := make([]string, 0, len(r.record.Tags))
+		for name := range r.record.Tags {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		tags := make([]gqlResolver, len(names))
+		for i, name := range names {
+			tags[i] = &tagResolver{name: name, value: r.record.Tags[name]}
+		}
+		return tags, nil
+	case "category":
+		return r.record.Category, nil
+	case "duration":
+		return seconds(r.record.Duration(util.NoTime, util.NoTime)), nil
+	case "pause":
+		return seconds(r.record.PauseDuration(util.NoTime, util.NoTime)), nil
+	case "locked":
+		return r.record.Locked, nil
+	}
+	return nil, unknownField(r, field)
+}
+
+// tagResolver resolves the Tag type
+type tagResolver struct {
+	name  string
+	value string
+}
+
+func (r *tagResolver) typeName() string { return "Tag" }
+
+func (r *tagResolver) resolve(field string, args map[string]interface{}) (interface{}, error) {
+	switch field {
+	case "name":
+		return r.name, nil
+	case "value":
+		return r.value, nil
+	}
+	return nil, unknownField(r, field)
+}
+
+// projectResolver resolves the Project type
+type projectResolver struct {
+	query   *queryResolver
+	project core.Project
+}
+
+func (r *projectResolver) typeName() string { return "Project" }
+
+func (r *projectResolver) resolve(field string, args map[string]interface{}) (interface{}, error) {
+	switch field {
+	case "name":
+		return r.project.Name, nil
+	case "parent":
+		return r.project.Parent, nil
+	case "symbol":
+		return r.project.Symbol, nil
+	case "archived":
+		return r.project.Archived, nil
+	case "category":
+		return r.project.Category, nil
+	case "requiredTags":
+		return append([]string{}, r.project.RequiredTags...), nil
+	case "records":
+		return r.query.records(args, []core.FilterFunction{core.FilterByProjects([]string{r.project.Name})}, true)
+	}
+	return nil, unknownField(r, field)
+}
+
+// reportResolver resolves the Report type
+type reportResolver struct {
+	reporter *core.Reporter
+	start    time.Time
+	end      time.Time
+}
+
+func (r *reportResolver) typeName() string { return "Report" }
+
+func (r *reportResolver) resolve(field string, args map[string]interface{}) (interface{}, error) {
+	switch field {
+	case "start":
+		return r.start.Format(util.DateFormat), nil
+	case "end":
+		return r.end.AddDate(0, 0, -1).Format(util.DateFormat), nil
+	case "total":
+		var total time.Duration
+		for _, dur := range r.reporter.ProjectTime {
+			total += dur
+		}
+		return seconds(total), nil
+	case "projects":
+		times := maps.Clone(r.reporter.TotalTime)
+		delete(times, r.reporter.ProjectsTree.Root.Value.Name)
+		return durationList(times, func(name string, dur time.Duration) gqlResolver {
+			return &timeResolver{
+				name:   "ProjectTime",
+				fields: map[string]interface{}{"project": name, "time": seconds(r.reporter.ProjectTime[name]), "totalTime": seconds(dur)},
+			}
+		}), nil
+	case "tags":
+		return durationList(r.reporter.TagTime, func(name string, dur time.Duration) gqlResolver {
+			return &timeResolver{name: "TagTime", fields: map[string]interface{}{"tag": name, "time": seconds(dur)}}
+		}), nil
+	case "categories":
+		return durationList(r.reporter.CategoryTime, func(name string, dur time.Duration) gqlResolver {
+			return &timeResolver{name: "CategoryTime", fields: map[string]interface{}{"category": name, "time": seconds(dur)}}
+		}), nil
+	}
+	return nil, unknownField(r, field)
+}
+
+// timeResolver resolves the ProjectTime, TagTime and CategoryTime types
+type timeResolver struct {
+	name   string
+	fields map[string]interface{}
+}
+
+func (r *timeResolver) typeName() string { return r.name }
+
+func (r *timeResolver) resolve(field string, args map[string]interface{}) (interface{}, error) {
+	if value, ok := r.fields[field]; ok {
+		return value, nil
+	}
+	return nil, unknownField(r, field)
+}
+
+// durationList creates resolvers for non-zero durations, sorted by name
+func durationList(times map[string]time.Duration, fn func(name string, dur time.Duration) gqlResolver) []gqlResolver {
+	names := []string{}
+	for name, dur := range times {
+		if dur > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	result := make([]gqlResolver, len(names))
+	for i, name := range names {
+		result[i] = fn(name, times[name])
+	}
+	return result
+}
+
+func unknownField(r gqlResolver, field string) error {
+	return fmt.Errorf("unknown field '%s' on type %s", field, r.typeName())
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
+
+// argPeriod parses the dates of arguments start and end (inclusive) to a time span.
+// With defaultToday, the span defaults to the current day. Otherwise, it is open.
+func argPeriod(args map[string]interface{}, defaultToday bool) (time.Time, time.Time, error) {
+	var start, end time.Time
+	if defaultToday {
+		start = util.ToDate(time.Now())
+		end = start
+	}
+	for _, arg := range []struct {
+		name string
+		date *time.Time
+	}{{"start", &start}, {"end", &end}} {
+		text, err := argString(args, arg.name)
+		if err != nil {
+			return start, end, err
+		}
+		if text == "" {
+			continue
+		}
+		if *arg.date, err = util.ParseDate(text); err != nil {
+			return start, end, fmt.Errorf("invalid argument '%s': %s", arg.name, err)
+		}
+	}
+	if !end.IsZero() {
+		end = end.AddDate(0, 0, 1)
+		if !start.IsZero() && !end.After(start) {
+			return start, end, fmt.Errorf("end must not be before start")
+		}
+	}
+	return start, end, nil
+}
+
+func argString(args map[string]interface{}, name string) (string, error) {
+	value, ok := args[name]
+	if !ok || value == nil {
+		return "", nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("argument '%s' must be a string", name)
+	}
+	return str, nil
+}
+
+func argBool(args map[string]interface{}, name string) (bool, error) {
+	value, ok := args[name]
+	if !ok || value == nil {
+		return false, nil
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("argument '%s' must be a boolean", name)
+	}
+	return b, nil
+}
+
+// argStrings parses a list of strings. A single string is treated as a list with one element
+func argStrings(args map[string]interface{}, name string) ([]string, error) {
+	value, ok := args[name]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	if str, ok := value.(string); ok {
+		return []string{str}, nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("argument '%s' must be a list of strings", name)
+	}
+	result := make([]string, len(list))
+	for i, item := range list {
+		if result[i], ok = item.(string); !ok {
+			return nil, fmt.Errorf("argument '%s' must be a list of strings", name)
+		}
+	}
+	return result, nil
+}
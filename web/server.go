@@ -0,0 +1,391 @@
+// Package web provides a token-authenticated HTTP API with an embedded single-page web UI,
+// for viewing reports and starting and stopping records from a browser.
+package web
+
+import (
+	"crypto/subtle"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mlange-42/track/core"
+	"github.com/mlange-42/track/util"
+	"golang.org/x/exp/slices"
+)
+
+// APIPath is the path prefix of the API endpoints
+const APIPath = "/api/v1/"
+
+// MaxBodySize is the maximum size of API request bodies, in bytes
+const MaxBodySize = 1 << 20
+
+//go:embed assets
+var assets embed.FS
+
+// Options are the options of a Server
+type Options struct {
+	// Token that API requests must provide as bearer token. No authentication if empty
+	Token string
+	// Origins allowed for cross-origin requests, like "http://localhost:3000". "*" allows all origins
+	AllowedOrigins []string
+}
+
+// StatusResponse is the response of the status endpoint
+type StatusResponse struct {
+	// Running record, if any. The stopped record for the stop endpoint
+	Record *core.Record `json:"record"`
+	// Whether the record is running
+	Active bool `json:"active"`
+	// Time of the running record in seconds, excluding pauses
+	Elapsed int64 `json:"elapsed"`
+	// Whether the running record is paused
+	Paused bool `json:"paused"`
+}
+
+// StartRequest is the request body of the start endpoint
+type StartRequest struct {
+	// Project of the record
+	Project string `json:"project"`
+	// Note of the record. Tags are extracted from it
+	Note string `json:"note,omitempty"`
+}
+
+// ReportResponse is the response of the report endpoint
+type ReportResponse struct {
+	// First day of the report
+	Start string `json:"start"`
+	// Last day of the report
+	End string `json:"end"`
+	// Time per project in seconds, including sub-projects
+	Projects map[string]int64 `json:"projects"`
+	// Total time in seconds
+	Total int64 `json:"total"`
+}
+
+// endpoint is an API endpoint below APIPath. Endpoints are used for routing and for the OpenAPI document
+type endpoint struct {
+	path     string
+	method   string
+	summary  string
+	params   []parameter
+	request  interface{}
+	response interface{}
+	handler  func(s *Server, w http.ResponseWriter, r *http.Request)
+}
+
+// parameter is a query parameter of an endpoint
+type parameter struct {
+	name        string
+	description string
+}
+
+var periodParams = []parameter{
+	{"start", "First day, like 2023-01-31. Today by default"},
+	{"end", "Last day (inclusive), like 2023-01-31. Today by default"},
+}
+
+var endpoints = []endpoint{
+	{path: "status", method: http.MethodGet, summary: "Get the running record",
+		response: StatusResponse{}, handler: (*Server).status},
+	{path: "projects", method: http.MethodGet, summary: "List the names of all projects that are not archived",
+		response: []string{}, handler: (*Server).projects},
+	{path: "start", method: http.MethodPost, summary: "Start a record now",
+		request: StartRequest{}, response: StatusResponse{}, handler: (*Server).start},
+	{path: "stop", method: http.MethodPost, summary: "Stop the running record now",
+		response: StatusResponse{}, handler: (*Server).stop},
+	{path: "report", method: http.MethodGet, summary: "Get the time per project",
+		params: periodParams, response: ReportResponse{}, handler: (*Server).report},
+	{path: "graphql", method: http.MethodGet, summary: "Run a GraphQL query",
+		params:   []parameter{{"query", "Query document"}, {"variables", "Variables as JSON object"}, {"operationName", "Name of the operation to execute"}},
+		response: GraphQLResponse{}, handler: (*Server).graphql},
+	{path: "graphql", method: http.MethodPost, summary: "Run a GraphQL query",
+		request: GraphQLRequest{}, response: GraphQLResponse{}, handler: (*Server).graphql},
+}
+
+// Server serves the API and the web UI for the current workspace.
+//
+// API endpoints below APIPath are:
+//
+//   - GET status: the running record (StatusResponse)
+//   - GET projects: names of all projects that are not archived
+//   - POST start: starts a record now (StartRequest)
+//   - POST stop: stops the running record now
+//   - GET report: time per project between query parameters start and end (dates, inclusive), today by default (ReportResponse)
+//   - GET/POST graphql: GraphQL queries over records, projects and reports (GraphQLRequest, see GraphQLSchema)
+//   - GET openapi.json: the OpenAPI document of the API, without authentication
+//
+// Against cross-site request forgery, API requests from other origins than the server's are rejected,
+// unless the origin is allowed by Options.AllowedOrigins, and POST requests must have content type application/json.
+//
+// All other paths serve the static files of the web UI.
+type Server struct {
+	track   *core.Track
+	options Options
+	static  http.Handler
+	mutex   sync.Mutex
+}
+
+// NewServer creates a new Server
+func NewServer(t *core.Track, options Options) *Server {
+	root, err := fs.Sub(assets, "assets")
+	if err != nil {
+		panic(err)
+	}
+	return &Server{
+		track:   t,
+		options: options,
+		static:  http.FileServer(http.FS(root)),
+	}
+}
+
+// ServeHTTP handles a request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, APIPath) {
+		s.static.ServeHTTP(w, r)
+		return
+	}
+
+	if origin := r.Header.Get("Origin"); origin != "" && s.originAllowed(origin) {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Add("Vary", "Origin")
+	}
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.URL.Path == APIPath+OpenAPIFile && r.Method == http.MethodGet {
+		writeJSON(w, OpenAPI())
+		return
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && !s.originAllowed(origin) && !sameOrigin(origin, r) {
+		http.Error(w, "cross-origin request not allowed", http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodPost {
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+	if s.options.Token != "" {
+		auth := r.Header.Get("Authorization")
+		if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+s.options.Token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, APIPath)
+	methods := []string{}
+	for _, e := range endpoints {
+		if e.path != path {
+			continue
+		}
+		if e.method == r.Method {
+			e.handler(s, w, r)
+			return
+		}
+		methods = append(methods, e.method)
+	}
+	if len(methods) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+}
+
+// sameOrigin checks if an origin is the origin of the server, as seen by the request
+func sameOrigin(origin string, r *http.Request) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// originAllowed checks if cross-origin requests from an origin are allowed
+func (s *Server) originAllowed(origin string) bool {
+	return slices.Contains(s.options.AllowedOrigins, "*") || slices.Contains(s.options.AllowedOrigins, origin)
+}
+
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	record, err := s.track.OpenRecord()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, newStatusResponse(record))
+}
+
+func (s *Server) projects(w http.ResponseWriter, r *http.Request) {
+	projects, err := s.track.LoadAllProjects()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	names := []string{}
+	for name, p := range projects {
+		if !p.Archived {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	writeJSON(w, names)
+}
+
+func (s *Server) start(w http.ResponseWriter, r *http.Request) {
+	var req StartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	project, err := s.track.LoadProject(req.Project)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if project.Archived {
+		http.Error(w, fmt.Sprintf("project '%s' is archived", project.Name), http.StatusBadRequest)
+		return
+	}
+	open, err := s.track.OpenRecord()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if open != nil {
+		http.Error(w, fmt.Sprintf("record in '%s' still running", open.Project), http.StatusConflict)
+		return
+	}
+	note := strings.TrimSpace(req.Note)
+	tags, err := s.track.Syntax().ExtractTags(note)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	record, err := s.track.StartRecord(&project, note, tags, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, newStatusResponse(&record))
+}
+
+func (s *Server) stop(w http.ResponseWriter, r *http.Request) {
+	record, err := s.track.StopRecord(time.Now())
+	if err != nil {
+		if errors.Is(err, core.ErrNoOpenRecord) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, newStatusResponse(record))
+}
+
+func (s *Server) report(w http.ResponseWriter, r *http.Request) {
+	today := util.ToDate(time.Now())
+	start, end := today, today
+	var err error
+	if param := r.URL.Query().Get("start"); param != "" {
+		if start, err = util.ParseDate(param); err != nil {
+			http.Error(w, fmt.Sprintf("invalid parameter start: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if param := r.URL.Query().Get("end"); param != "" {
+		if end, err = util.ParseDate(param); err != nil {
+			http.Error(w, fmt.Sprintf("invalid parameter end: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if end.Before(start) {
+		http.Error(w, "end must not be before start", http.StatusBadRequest)
+		return
+	}
+	endTime := end.AddDate(0, 0, 1)
+
+	reporter, err := core.NewStreamingReporter(s.track, []string{}, core.NewFilter([]core.FilterFunction{}, start, endTime), false, start, endTime)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	response := ReportResponse{
+		Start:    start.Format(util.DateFormat),
+		End:      end.Format(util.DateFormat),
+		Projects: map[string]int64{},
+	}
+	for name, dur := range reporter.TotalTime {
+		if dur > 0 {
+			response.Projects[name] = int64(dur / time.Second)
+		}
+	}
+	for _, dur := range reporter.ProjectTime {
+		response.Total += int64(dur / time.Second)
+	}
+	writeJSON(w, response)
+}
+
+func (s *Server) graphql(w http.ResponseWriter, r *http.Request) {
+	var req GraphQLRequest
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if vars := query.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				http.Error(w, fmt.Sprintf("invalid parameter variables: %s", err), http.StatusBadRequest)
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	writeJSON(w, executeGraphQL(&req, &queryResolver{track: s.track}))
+}
+
+// newStatusResponse creates the status response for a record, which is running if it has not ended
+func newStatusResponse(record *core.Record) StatusResponse {
+	if record == nil {
+		return StatusResponse{}
+	}
+	return StatusResponse{
+		Record:  record,
+		Active:  !record.HasEnded(),
+		Elapsed: int64(record.Duration(util.NoTime, util.NoTime) / time.Second),
+		Paused:  record.IsPaused(),
+	}
+}
+
+// writeDecodeError writes the error response for a request body that could not be decoded
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, fmt.Sprintf("request body exceeds %d bytes", MaxBodySize), http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
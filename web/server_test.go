@@ -0,0 +1,158 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mlange-42/track/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTrack(t *testing.T) (*core.Track, func()) {
+	dir, err := os.MkdirTemp("", "track-test")
+	assert.Nil(t, err, "Error creating temporary directory")
+	track, err := core.NewTrack(&dir)
+	assert.Nil(t, err, "Error creating Track instance")
+	return &track, func() { os.RemoveAll(dir) }
+}
+
+func request(t *testing.T, method, url, token string, body interface{}, result interface{}) *http.Response {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		assert.Nil(t, err)
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	assert.Nil(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	if result != nil && resp.StatusCode == http.StatusOK {
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(result))
+	}
+	return resp
+}
+
+func TestServer(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	assert.Nil(t, track.SaveProject(core.NewProject("test", "", "t", []string{}, 15, 0), false))
+
+	server := httptest.NewServer(NewServer(track, Options{Token: "secret", AllowedOrigins: []string{"http://localhost:3000"}}))
+	defer server.Close()
+
+	resp := request(t, http.MethodGet, server.URL+"/", "", nil, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+
+	resp = request(t, http.MethodGet, server.URL+APIPath+"status", "wrong", nil, nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodOptions, server.URL+APIPath+"start", nil)
+	assert.Nil(t, err)
+	req.Header.Set("Origin", "http://localhost:3000")
+	resp, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "http://localhost:3000", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	req.Header.Set("Origin", "http://example.com")
+	resp, err = http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "", resp.Header.Get("Access-Control-Allow-Origin"))
+
+	projects := []string{}
+	resp = request(t, http.MethodGet, server.URL+APIPath+"projects", "secret", nil, &projects)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"test"}, projects)
+
+	resp = request(t, http.MethodGet, server.URL+APIPath+"start", "secret", nil, nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	status := StatusResponse{}
+	resp = request(t, http.MethodPost, server.URL+APIPath+"start", "secret", StartRequest{Project: "test", Note: "Work +dev"}, &status)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, status.Active)
+	assert.Equal(t, map[string]string{"dev": ""}, status.Record.Tags)
+
+	resp = request(t, http.MethodPost, server.URL+APIPath+"start", "secret", StartRequest{Project: "test"}, nil)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	status = StatusResponse{}
+	resp = request(t, http.MethodGet, server.URL+APIPath+"status", "secret", nil, &status)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, status.Active)
+	assert.Equal(t, "test", status.Record.Project)
+
+	status = StatusResponse{}
+	resp = request(t, http.MethodPost, server.URL+APIPath+"stop", "secret", nil, &status)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.False(t, status.Active)
+
+	resp = request(t, http.MethodPost, server.URL+APIPath+"stop", "secret", nil, nil)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	report := ReportResponse{}
+	resp = request(t, http.MethodGet, server.URL+APIPath+"report", "secret", nil, &report)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, report.Start, report.End)
+
+	resp = request(t, http.MethodGet, server.URL+APIPath+"report?start=2001-02-03&end=2001-02-01", "secret", nil, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = request(t, http.MethodPost, server.URL+APIPath+"start", "secret", StartRequest{Project: "test", Note: strings.Repeat("x", MaxBodySize)}, nil)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func TestServerCrossSiteRequests(t *testing.T) {
+	track, clean := newTestTrack(t)
+	defer clean()
+
+	assert.Nil(t, track.SaveProject(core.NewProject("test", "", "t", []string{}, 15, 0), false))
+
+	server := httptest.NewServer(NewServer(track, Options{AllowedOrigins: []string{"http://localhost:3000"}}))
+	defer server.Close()
+
+	post := func(path, body, contentType, origin string) int {
+		req, err := http.NewRequest(http.MethodPost, server.URL+APIPath+path, strings.NewReader(body))
+		assert.Nil(t, err)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, post("start", `{"project": "test"}`, "", ""))
+	assert.Equal(t, http.StatusUnsupportedMediaType, post("start", `{"project": "test"}`, "text/plain", ""))
+	assert.Equal(t, http.StatusUnsupportedMediaType, post("start", `{"project": "test"}`, "application/x-www-form-urlencoded", ""))
+	assert.Equal(t, http.StatusForbidden, post("start", `{"project": "test"}`, "application/json", "http://example.com"))
+
+	open, err := track.OpenRecord()
+	assert.Nil(t, err)
+	assert.Nil(t, open, "Rejected requests should not start a record")
+
+	assert.Equal(t, http.StatusOK, post("start", `{"project": "test"}`, "application/json; charset=utf-8", server.URL))
+	assert.Equal(t, http.StatusOK, post("graphql", `{"query": "{ status { project } }"}`, "application/json", "http://localhost:3000"))
+}